@@ -0,0 +1,121 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/containous/traefik/v2/cmd/snapshot"
+	"github.com/containous/traefik/v2/pkg/cli"
+	"github.com/containous/traefik/v2/pkg/config/diff"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	configsnapshot "github.com/containous/traefik/v2/pkg/config/snapshot"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/provider/file"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// NewCmd builds a new Plan command.
+func NewCmd(traefikConfiguration *static.Configuration, loaders []cli.ResourceLoader) *cli.Command {
+	return &cli.Command{
+		Name: "plan",
+		Description: `Calls Traefik /api/snapshot endpoint (requires api.insecure) to print the changes of the dynamic configuration resulting from a candidate configuration.
+Usage: traefik plan [flags] <candidate file or directory> [text|json]`,
+		Configuration: traefikConfiguration,
+		Run:           runCmd(traefikConfiguration),
+		Resources:     loaders,
+		AllowArg:      true,
+	}
+}
+
+func runCmd(traefikConfiguration *static.Configuration) func(args []string) error {
+	return func(args []string) error {
+		traefikConfiguration.SetEffectiveConfiguration()
+
+		candidate, format, err := parseArgs(args)
+		if err != nil {
+			fmt.Printf("Error calling plan: %s\n", err)
+			os.Exit(1)
+		}
+
+		plan, err := Do(*traefikConfiguration, candidate)
+		if err != nil {
+			fmt.Printf("Error calling plan: %s\n", err)
+			os.Exit(1)
+		}
+
+		if format == formatJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(plan)
+		}
+
+		return plan.WriteText(os.Stdout)
+	}
+}
+
+// parseArgs returns the candidate configuration and the output format from the arguments following the flags,
+// which have to be given as --name=value.
+func parseArgs(args []string) (string, string, error) {
+	var positional []string
+	for _, arg := range args {
+		if len(arg) > 0 && arg[0] != '-' {
+			positional = append(positional, arg)
+		}
+	}
+
+	switch len(positional) {
+	case 1:
+		return positional[0], formatText, nil
+	case 2:
+		if positional[1] != formatText && positional[1] != formatJSON {
+			return "", "", fmt.Errorf("unsupported output format %q: use %q or %q", positional[1], formatText, formatJSON)
+		}
+		return positional[0], positional[1], nil
+	default:
+		return "", "", errors.New("please give the candidate configuration file or directory, and optionally the output format")
+	}
+}
+
+// Do gets the snapshot of the dynamic configuration,
+// and compares it with the candidate configuration, loaded as by the file provider.
+func Do(staticConfiguration static.Configuration, candidate string) (*diff.Plan, error) {
+	data, err := snapshot.Do(staticConfiguration)
+	if err != nil {
+		return nil, err
+	}
+
+	current := &dynamic.Configuration{}
+	if err = yaml.Unmarshal(data, current); err != nil {
+		return nil, fmt.Errorf("failed to decode the snapshot: %w", err)
+	}
+
+	info, err := os.Stat(candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &file.Provider{Filename: candidate}
+	if info.IsDir() {
+		provider = &file.Provider{Directory: candidate}
+	}
+
+	candidateConfiguration, err := provider.BuildConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the candidate configuration: %w", err)
+	}
+
+	// The candidate configuration is normalized as the snapshot, to compare the references between the elements.
+	candidateSnapshot, err := configsnapshot.BuildFromProvider(*candidateConfiguration, "file")
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize the candidate configuration: %w", err)
+	}
+
+	return diff.Compute(current, candidateSnapshot)
+}
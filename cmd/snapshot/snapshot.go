@@ -0,0 +1,70 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/cli"
+	"github.com/containous/traefik/v2/pkg/config/static"
+)
+
+// NewCmd builds a new Snapshot command.
+func NewCmd(traefikConfiguration *static.Configuration, loaders []cli.ResourceLoader) *cli.Command {
+	return &cli.Command{
+		Name:          "snapshot",
+		Description:   `Calls Traefik /api/snapshot endpoint (requires api.insecure) to print the dynamic configuration as a file provider configuration.`,
+		Configuration: traefikConfiguration,
+		Run:           runCmd(traefikConfiguration),
+		Resources:     loaders,
+	}
+}
+
+func runCmd(traefikConfiguration *static.Configuration) func(_ []string) error {
+	return func(_ []string) error {
+		traefikConfiguration.SetEffectiveConfiguration()
+
+		data, err := Do(*traefikConfiguration)
+		if err != nil {
+			fmt.Printf("Error calling snapshot: %s\n", err)
+			os.Exit(1)
+		}
+
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+}
+
+// Do gets the snapshot of the dynamic configuration.
+func Do(staticConfiguration static.Configuration) ([]byte, error) {
+	if staticConfiguration.API == nil || !staticConfiguration.API.Insecure {
+		return nil, errors.New("please enable `api.insecure` to use snapshot")
+	}
+
+	apiEntryPoint, ok := staticConfiguration.EntryPoints["traefik"]
+	if !ok {
+		return nil, errors.New("api: missing traefik entry point")
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	resp, err := client.Get("http://" + apiEntryPoint.GetAddress() + "/api/snapshot")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad snapshot status: %s: %s", resp.Status, data)
+	}
+
+	return data, nil
+}
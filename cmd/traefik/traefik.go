@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	stdlog "log"
 	"net/http"
 	"os"
@@ -11,24 +13,42 @@ import (
 	"strings"
 	"time"
 
+	"github.com/abronan/valkeyrie/store"
 	"github.com/containous/traefik/v2/autogen/genstatic"
 	"github.com/containous/traefik/v2/cmd"
 	"github.com/containous/traefik/v2/cmd/healthcheck"
+	"github.com/containous/traefik/v2/cmd/plan"
+	"github.com/containous/traefik/v2/cmd/snapshot"
 	cmdVersion "github.com/containous/traefik/v2/cmd/version"
+	"github.com/containous/traefik/v2/pkg/api"
 	"github.com/containous/traefik/v2/pkg/cli"
+	"github.com/containous/traefik/v2/pkg/cluster"
 	"github.com/containous/traefik/v2/pkg/collector"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/schema"
 	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/encryption"
+	"github.com/containous/traefik/v2/pkg/gossip"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/memprotect"
 	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/plugins"
+	"github.com/containous/traefik/v2/pkg/privsep"
 	"github.com/containous/traefik/v2/pkg/provider/acme"
+	"github.com/containous/traefik/v2/pkg/provider/acme/kvstore"
+	"github.com/containous/traefik/v2/pkg/provider/acme/secretstore"
 	"github.com/containous/traefik/v2/pkg/provider/aggregator"
+	"github.com/containous/traefik/v2/pkg/provider/kubernetes/ingress"
+	"github.com/containous/traefik/v2/pkg/provider/kv"
 	"github.com/containous/traefik/v2/pkg/provider/traefik"
+	"github.com/containous/traefik/v2/pkg/runas"
 	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/secrets"
 	"github.com/containous/traefik/v2/pkg/server"
 	"github.com/containous/traefik/v2/pkg/server/middleware"
 	"github.com/containous/traefik/v2/pkg/server/service"
+	"github.com/containous/traefik/v2/pkg/spiffe"
 	traefiktls "github.com/containous/traefik/v2/pkg/tls"
 	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/containous/traefik/v2/pkg/version"
@@ -61,6 +81,18 @@ Complete documentation is available at https://traefik.io`,
 		os.Exit(1)
 	}
 
+	err = cmdTraefik.AddCommand(snapshot.NewCmd(&tConfig.Configuration, loaders))
+	if err != nil {
+		stdlog.Println(err)
+		os.Exit(1)
+	}
+
+	err = cmdTraefik.AddCommand(plan.NewCmd(&tConfig.Configuration, loaders))
+	if err != nil {
+		stdlog.Println(err)
+		os.Exit(1)
+	}
+
 	err = cmdTraefik.AddCommand(cmdVersion.NewCmd())
 	if err != nil {
 		stdlog.Println(err)
@@ -90,6 +122,20 @@ func runCmd(staticConfiguration *static.Configuration) error {
 		return err
 	}
 
+	if staticConfiguration.PrivilegeSeparation != nil {
+		if !privsep.IsWorker() {
+			return privsep.RunHelper(staticConfiguration.PrivilegeSeparation)
+		}
+
+		if err := privsep.InitWorker(); err != nil {
+			return fmt.Errorf("unable to initialize the privilege separation: %w", err)
+		}
+	}
+
+	if err := memprotect.Apply(staticConfiguration.KeyProtection); err != nil {
+		return err
+	}
+
 	log.WithoutContext().Infof("Traefik version %s built on %s", version.Version, version.BuildDate)
 
 	jsonConf, err := json.Marshal(staticConfiguration)
@@ -100,6 +146,21 @@ func runCmd(staticConfiguration *static.Configuration) error {
 		log.WithoutContext().Debugf("Static configuration loaded %s", string(jsonConf))
 	}
 
+	secretsResolver, err := secrets.NewResolver(staticConfiguration.Secrets)
+	if err != nil {
+		return fmt.Errorf("unable to create the secrets resolver: %w", err)
+	}
+
+	if err := secretsResolver.ResolveFields(context.Background(), staticConfiguration); err != nil {
+		return fmt.Errorf("unable to resolve the secrets of the static configuration: %w", err)
+	}
+
+	if staticConfiguration.Secrets != nil {
+		if err := secretsResolver.ResolveEnvironment(context.Background(), staticConfiguration.Secrets.Environment); err != nil {
+			return fmt.Errorf("unable to resolve the secrets of the environment: %w", err)
+		}
+	}
+
 	if staticConfiguration.API != nil && staticConfiguration.API.Dashboard {
 		staticConfiguration.API.DashboardAssets = &assetfs.AssetFS{Asset: genstatic.Asset, AssetInfo: genstatic.AssetInfo, AssetDir: genstatic.AssetDir, Prefix: "static"}
 	}
@@ -110,11 +171,22 @@ func runCmd(staticConfiguration *static.Configuration) error {
 
 	stats(staticConfiguration)
 
-	svr, err := setupServer(staticConfiguration)
+	if staticConfiguration.ACMEServer != nil {
+		if err := staticConfiguration.ACMEServer.Init(); err != nil {
+			return fmt.Errorf("unable to initialize the ACME server: %w", err)
+		}
+	}
+
+	svr, err := setupServer(staticConfiguration, secretsResolver)
 	if err != nil {
 		return err
 	}
 
+	// The entry points are listening, the privileges needed to bind their ports can be dropped.
+	if err := runas.Apply(staticConfiguration.RunAs); err != nil {
+		return fmt.Errorf("unable to drop the privileges: %w", err)
+	}
+
 	ctx := cmd.ContextWithSignal(context.Background())
 
 	if staticConfiguration.Ping != nil {
@@ -160,7 +232,7 @@ func runCmd(staticConfiguration *static.Configuration) error {
 	return nil
 }
 
-func setupServer(staticConfiguration *static.Configuration) (*server.Server, error) {
+func setupServer(staticConfiguration *static.Configuration, secretsResolver *secrets.Resolver) (*server.Server, error) {
 	providerAggregator := aggregator.NewProviderAggregator(*staticConfiguration.Providers)
 
 	// adds internal provider
@@ -170,14 +242,60 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	}
 
 	tlsManager := traefiktls.NewManager()
+	tlsManager.SetCRLStorage(staticConfiguration.CRLStorage)
+	tlsManager.SetKVStoreFactory(kv.NewStore)
+	if staticConfiguration.LazyCertificates {
+		tlsManager.EnableLazyCertificates()
+	}
+	if staticConfiguration.CheckSCTs {
+		tlsManager.EnableSCTChecks()
+	}
+	if staticConfiguration.KeyProtection != nil {
+		tlsManager.EnableKeyZeroization(time.Duration(staticConfiguration.KeyProtection.ZeroizeDelay))
+	}
 
-	acmeProviders := initACMEProvider(staticConfiguration, &providerAggregator, tlsManager)
+	metricsRegistry := registerMetricClients(staticConfiguration.Metrics)
+
+	tlsManager.AddCertificatesListener(func(_ traefiktls.CertificatesEvent) {
+		metrics.OnTLSCertificatesUpdate(metricsRegistry, tlsManager.CertificatesInfo())
+	})
+	tlsManager.AddHandshakeErrorsListener(func(reason string) {
+		metricsRegistry.TLSHandshakeErrorsCounter().With("reason", reason).Add(1)
+	})
+
+	storageCipher, err := encryption.New(staticConfiguration.StorageEncryption)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage encryption: %w", err)
+	}
+
+	var election *cluster.Election
+	var leadership acme.Leadership
+	if staticConfiguration.Cluster != nil {
+		election, err = cluster.NewElection(staticConfiguration.Cluster)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cluster configuration: %w", err)
+		}
+		leadership = election
+	}
+
+	var dnsPlugins acme.DNSPlugins
+	if staticConfiguration.Plugins != nil {
+		pluginBuilder, err := plugins.NewBuilder(staticConfiguration.Plugins)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plugins configuration: %w", err)
+		}
+		dnsPlugins = pluginBuilder
+	}
+
+	acmeProviders := initACMEProvider(staticConfiguration, &providerAggregator, tlsManager, metricsRegistry, storageCipher, leadership, dnsPlugins)
 
 	serverEntryPointsTCP, err := server.NewTCPEntryPoints(staticConfiguration.EntryPoints)
 	if err != nil {
 		return nil, err
 	}
 
+	serverEntryPointsTCP.SetMetricsRegistry(metricsRegistry)
+
 	serverEntryPointsUDP, err := server.NewUDPEntryPoints(staticConfiguration.EntryPoints)
 	if err != nil {
 		return nil, err
@@ -186,11 +304,55 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	ctx := context.Background()
 	routinesPool := safe.NewPool(ctx)
 
-	metricsRegistry := registerMetricClients(staticConfiguration.Metrics)
+	if staticConfiguration.KeyProtection != nil {
+		// The keys are zeroized when the routines pool is stopped, once the entry points are shut down.
+		routinesPool.GoCtx(func(ctx context.Context) {
+			<-ctx.Done()
+			tlsManager.ZeroizeKeys()
+		})
+	}
+
+	if election != nil {
+		routinesPool.GoCtx(election.Run)
+	}
+
+	var sharedState *gossip.State
+	if staticConfiguration.Gossip != nil {
+		gossipNode, err := gossip.NewNode(staticConfiguration.Gossip)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gossip configuration: %w", err)
+		}
+
+		sharedState = gossipNode.State()
+		routinesPool.GoCtx(gossipNode.Run)
+	}
+
+	if staticConfiguration.SPIFFE != nil {
+		spiffeSource, err := spiffe.NewSource(staticConfiguration.SPIFFE)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE configuration: %w", err)
+		}
+
+		tlsManager.AddCertificateResolver(spiffeSource)
+		tlsManager.SetSPIFFEBundleSource(spiffeSource)
+		routinesPool.GoCtx(spiffeSource.Run)
+	}
+
 	accessLog := setupAccessLog(staticConfiguration.AccessLog)
 	chainBuilder := middleware.NewChainBuilder(*staticConfiguration, metricsRegistry, accessLog)
-	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, metricsRegistry)
-	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, tlsManager, chainBuilder)
+
+	var acmeResolvers []api.ACMEResolver
+	for _, p := range acmeProviders {
+		acmeResolvers = append(acmeResolvers, p)
+	}
+
+	providerSchemas := map[string]*schema.Schema{
+		"annotations": ingress.AnnotationsSchema(),
+	}
+
+	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, metricsRegistry, acmeResolvers, serverEntryPointsTCP, chainBuilder.PathStatistics(), tlsManager, providerAggregator, providerSchemas, secretsResolver)
+	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, tlsManager, chainBuilder, metricsRegistry)
+	routerFactory.SetSharedState(sharedState)
 
 	var defaultEntryPoints []string
 	for name, cfg := range staticConfiguration.EntryPoints {
@@ -214,6 +376,10 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		defaultEntryPoints,
 	)
 
+	watcher.SetSecretsResolver(secretsResolver)
+	watcher.SetMetricsRegistry(metricsRegistry)
+	routinesPool.GoCtx(secretsResolver.Run)
+
 	watcher.AddListener(func(conf dynamic.Configuration) {
 		ctx := context.Background()
 		tlsManager.UpdateConfigs(ctx, conf.TLS.Stores, conf.TLS.Options, conf.TLS.Certificates)
@@ -227,7 +393,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	watcher.AddListener(switchRouter(routerFactory, acmeProviders, serverEntryPointsTCP, serverEntryPointsUDP))
 
 	watcher.AddListener(func(conf dynamic.Configuration) {
-		if metricsRegistry.IsEpEnabled() || metricsRegistry.IsSvcEnabled() {
+		if metricsRegistry.IsEpEnabled() || metricsRegistry.IsSvcEnabled() || metricsRegistry.IsRouterEnabled() {
 			var eps []string
 			for key := range serverEntryPointsTCP {
 				eps = append(eps, key)
@@ -237,6 +403,17 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		}
 	})
 
+	if pathStatistics := chainBuilder.PathStatistics(); pathStatistics != nil {
+		watcher.AddListener(func(conf dynamic.Configuration) {
+			var routerNames []string
+			for name := range conf.HTTP.Routers {
+				routerNames = append(routerNames, name)
+			}
+
+			pathStatistics.Retain(routerNames)
+		})
+	}
+
 	resolverNames := map[string]struct{}{}
 	for _, p := range acmeProviders {
 		resolverNames[p.ResolverName] = struct{}{}
@@ -275,24 +452,39 @@ func switchRouter(routerFactory *server.RouterFactory, acmeProviders []*acme.Pro
 }
 
 // initACMEProvider creates an acme provider from the ACME part of globalConfiguration
-func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.ProviderAggregator, tlsManager *traefiktls.Manager) []*acme.Provider {
+func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.ProviderAggregator, tlsManager *traefiktls.Manager, metricsRegistry metrics.Registry, storageCipher *encryption.Cipher, leadership acme.Leadership, dnsPlugins acme.DNSPlugins) []*acme.Provider {
 	challengeStore := acme.NewLocalChallengeStore()
 	localStores := map[string]*acme.LocalStore{}
 
 	var resolvers []*acme.Provider
 	for name, resolver := range c.CertificatesResolvers {
 		if resolver.ACME != nil {
-			if localStores[resolver.ACME.Storage] == nil {
-				localStores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage)
+			var acmeStore acme.Store
+			if resolver.ACME.SharedStorage != nil {
+				sharedStore, err := newACMESharedStore(resolver.ACME.SharedStorage, storageCipher)
+				if err != nil {
+					log.WithoutContext().Errorf("The ACME resolver %q is skipped from the resolvers list because: %v", name, err)
+					continue
+				}
+				acmeStore = sharedStore
+			} else {
+				if localStores[resolver.ACME.Storage] == nil {
+					localStores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage, storageCipher)
+				}
+				acmeStore = localStores[resolver.ACME.Storage]
 			}
 
 			p := &acme.Provider{
 				Configuration:  resolver.ACME,
-				Store:          localStores[resolver.ACME.Storage],
+				Store:          acmeStore,
 				ChallengeStore: challengeStore,
 				ResolverName:   name,
+				Leadership:     leadership,
+				DNSPlugins:     dnsPlugins,
 			}
 
+			p.SetMetricsRegistry(metricsRegistry)
+
 			if err := providerAggregator.AddProvider(p); err != nil {
 				log.WithoutContext().Errorf("The ACME resolver %q is skipped from the resolvers list because: %v", name, err)
 				continue
@@ -301,7 +493,11 @@ func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.Pr
 			p.SetTLSManager(tlsManager)
 
 			if p.TLSChallenge != nil {
-				tlsManager.TLSAlpnGetter = p.GetTLSALPNCertificate
+				tlsManager.AddCertificateResolver(p)
+			}
+
+			if p.OnDemand != nil {
+				tlsManager.AddUnknownServerNameListener(p.ObtainOnDemand)
 			}
 
 			p.SetConfigListenerChan(make(chan dynamic.Configuration))
@@ -309,9 +505,34 @@ func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.Pr
 			resolvers = append(resolvers, p)
 		}
 	}
+
+	fallbackResolvers := make(map[string]*acme.Provider, len(resolvers))
+	for _, p := range resolvers {
+		fallbackResolvers[p.ResolverName] = p
+	}
+	for _, p := range resolvers {
+		p.SetFallbackResolvers(fallbackResolvers)
+	}
+
 	return resolvers
 }
 
+// newACMESharedStore creates the store of the backend of the shared storage.
+func newACMESharedStore(config *acme.SharedStorage, storageCipher *encryption.Cipher) (acme.Store, error) {
+	switch {
+	case config.Consul != nil:
+		return kvstore.New(store.CONSUL, config.Consul, storageCipher)
+	case config.Etcd != nil:
+		return kvstore.New(store.ETCDV3, config.Etcd, storageCipher)
+	case config.Redis != nil:
+		return kvstore.New(store.REDIS, config.Redis, storageCipher)
+	case config.Kubernetes != nil:
+		return secretstore.New(config.Kubernetes, storageCipher)
+	default:
+		return nil, errors.New("no shared storage backend")
+	}
+}
+
 func registerMetricClients(metricsConfig *types.Metrics) metrics.Registry {
 	if metricsConfig == nil {
 		return metrics.NewVoidRegistry()
@@ -0,0 +1,647 @@
+package acmeserver
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/gorilla/mux"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+// PathPrefix is the path prefix of the ACME server endpoints.
+const PathPrefix = "/acme"
+
+// DirectoryPath is the path of the ACME server directory.
+const DirectoryPath = PathPrefix + "/directory"
+
+const authorizationLifetime = time.Hour
+
+// statusReady is the status of an order for which all the authorizations are valid.
+const statusReady = "ready"
+
+// Handler is a minimal ACME server issuing certificates from an internal CA,
+// intended for test environments.
+type Handler struct {
+	EntryPoint           string            `description:"EntryPoint" export:"true" json:"entryPoint,omitempty" toml:"entryPoint,omitempty" yaml:"entryPoint,omitempty"`
+	ManualRouting        bool              `description:"Manual routing" json:"manualRouting,omitempty" toml:"manualRouting,omitempty" yaml:"manualRouting,omitempty"`
+	CertFile             tls.FileOrContent `description:"Certificate of the internal CA (generated when not defined)." json:"certFile,omitempty" toml:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile              tls.FileOrContent `description:"Key of the internal CA." json:"keyFile,omitempty" toml:"keyFile,omitempty" yaml:"keyFile,omitempty" secret:"true"`
+	CertificatesDuration types.Duration    `description:"Validity duration of the issued certificates." json:"certificatesDuration,omitempty" toml:"certificatesDuration,omitempty" yaml:"certificatesDuration,omitempty" export:"true"`
+	HTTPPort             int               `description:"Port used to validate the HTTP-01 challenges." json:"httpPort,omitempty" toml:"httpPort,omitempty" yaml:"httpPort,omitempty" export:"true"`
+	TLSPort              int               `description:"Port used to validate the TLS-ALPN-01 challenges." json:"tlsPort,omitempty" toml:"tlsPort,omitempty" yaml:"tlsPort,omitempty" export:"true"`
+	SkipValidation       bool              `description:"Consider all the challenges as valid, without validating them." json:"skipValidation,omitempty" toml:"skipValidation,omitempty" yaml:"skipValidation,omitempty" export:"true"`
+
+	server *server
+}
+
+// SetDefaults sets the default values.
+func (h *Handler) SetDefaults() {
+	h.EntryPoint = "traefik"
+	h.CertificatesDuration = types.Duration(90 * 24 * time.Hour)
+	h.HTTPPort = 80
+	h.TLSPort = 443
+}
+
+// Init loads, or generates, the internal CA.
+func (h *Handler) Init() error {
+	ca, err := newCertificateAuthority(h.CertFile, h.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	if h.CertFile == "" {
+		log.WithoutContext().Warn("No certificate defined for the ACME server: an ephemeral internal CA has been generated")
+	}
+
+	h.server = newServer(h, ca)
+
+	return nil
+}
+
+// CACertificate returns the PEM encoded certificate of the internal CA.
+func (h *Handler) CACertificate() []byte {
+	if h.server == nil {
+		return nil
+	}
+
+	return h.server.ca.certPEM
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if h.server == nil {
+		writeProblem(rw, http.StatusServiceUnavailable, "serverInternal", "the ACME server is not initialized")
+		return
+	}
+
+	h.server.router.ServeHTTP(rw, req)
+}
+
+type account struct {
+	id         string
+	key        *jose.JSONWebKey
+	thumbprint string
+	contact    []string
+}
+
+type order struct {
+	id          string
+	accountID   string
+	status      string
+	expires     time.Time
+	identifiers []acme.Identifier
+	authzIDs    []string
+	certID      string
+}
+
+type authorization struct {
+	id         string
+	accountID  string
+	status     string
+	expires    time.Time
+	identifier acme.Identifier
+	wildcard   bool
+	challenges []*challenge
+}
+
+type challenge struct {
+	id        string
+	authzID   string
+	typ       string
+	token     string
+	status    string
+	validated time.Time
+	err       *acme.ProblemDetails
+}
+
+type server struct {
+	config *Handler
+	ca     *certificateAuthority
+	router *mux.Router
+
+	lock           sync.Mutex
+	nonces         map[string]struct{}
+	accounts       map[string]*account
+	orders         map[string]*order
+	authorizations map[string]*authorization
+	challenges     map[string]*challenge
+	certificates   map[string][]byte
+}
+
+func newServer(config *Handler, ca *certificateAuthority) *server {
+	s := &server{
+		config:         config,
+		ca:             ca,
+		nonces:         make(map[string]struct{}),
+		accounts:       make(map[string]*account),
+		orders:         make(map[string]*order),
+		authorizations: make(map[string]*authorization),
+		challenges:     make(map[string]*challenge),
+		certificates:   make(map[string][]byte),
+	}
+
+	router := mux.NewRouter()
+	router.Methods(http.MethodGet).Path(DirectoryPath).HandlerFunc(s.getDirectory)
+	router.Methods(http.MethodHead, http.MethodGet).Path(PathPrefix + "/new-nonce").HandlerFunc(s.getNonce)
+	router.Methods(http.MethodGet).Path(PathPrefix + "/root").HandlerFunc(s.getRoot)
+	router.Methods(http.MethodPost).Path(PathPrefix + "/new-account").HandlerFunc(s.newAccount)
+	router.Methods(http.MethodPost).Path(PathPrefix + "/account/{id}").HandlerFunc(s.getAccount)
+	router.Methods(http.MethodPost).Path(PathPrefix + "/new-order").HandlerFunc(s.newOrder)
+	router.Methods(http.MethodPost).Path(PathPrefix + "/order/{id}").HandlerFunc(s.getOrder)
+	router.Methods(http.MethodPost).Path(PathPrefix + "/order/{id}/finalize").HandlerFunc(s.finalizeOrder)
+	router.Methods(http.MethodPost).Path(PathPrefix + "/authz/{id}").HandlerFunc(s.getAuthorization)
+	router.Methods(http.MethodPost).Path(PathPrefix + "/chall/{id}").HandlerFunc(s.validateChallenge)
+	router.Methods(http.MethodPost).Path(PathPrefix + "/cert/{id}").HandlerFunc(s.getCertificate)
+	s.router = router
+
+	return s
+}
+
+func (s *server) getDirectory(rw http.ResponseWriter, req *http.Request) {
+	base := baseURL(req)
+
+	writeJSON(rw, http.StatusOK, acme.Directory{
+		NewNonceURL:   base + "/new-nonce",
+		NewAccountURL: base + "/new-account",
+		NewOrderURL:   base + "/new-order",
+	})
+}
+
+func (s *server) getNonce(rw http.ResponseWriter, req *http.Request) {
+	s.setNonce(rw)
+
+	if req.Method == http.MethodHead {
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) getRoot(rw http.ResponseWriter, _ *http.Request) {
+	rw.Header().Set("Content-Type", "application/x-pem-file")
+	_, _ = rw.Write(s.ca.certPEM)
+}
+
+func (s *server) newAccount(rw http.ResponseWriter, req *http.Request) {
+	signed, ok := s.verify(rw, req, true)
+	if !ok {
+		return
+	}
+
+	var msg acme.Account
+	if err := json.Unmarshal(signed.payload, &msg); err != nil {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "invalid account: "+err.Error())
+		return
+	}
+
+	if signed.account != nil {
+		rw.Header().Set("Location", baseURL(req)+"/account/"+signed.account.id)
+		writeJSON(rw, http.StatusOK, signed.account.representation())
+		return
+	}
+
+	thumbprint, err := keyThumbprint(signed.jwk)
+	if err != nil {
+		writeProblem(rw, http.StatusBadRequest, "badPublicKey", err.Error())
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	status := http.StatusOK
+
+	acct := s.accountByThumbprint(thumbprint)
+	if acct == nil {
+		if msg.OnlyReturnExisting {
+			writeProblem(rw, http.StatusBadRequest, "accountDoesNotExist", "no account exists with the provided key")
+			return
+		}
+
+		acct = &account{id: newID(), key: signed.jwk, thumbprint: thumbprint, contact: msg.Contact}
+		s.accounts[acct.id] = acct
+		status = http.StatusCreated
+	}
+
+	rw.Header().Set("Location", baseURL(req)+"/account/"+acct.id)
+	writeJSON(rw, status, acct.representation())
+}
+
+func (s *server) getAccount(rw http.ResponseWriter, req *http.Request) {
+	signed, ok := s.verify(rw, req, false)
+	if !ok {
+		return
+	}
+
+	if signed.account.id != mux.Vars(req)["id"] {
+		writeProblem(rw, http.StatusUnauthorized, "unauthorized", "the account does not match the key")
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, signed.account.representation())
+}
+
+func (s *server) newOrder(rw http.ResponseWriter, req *http.Request) {
+	signed, ok := s.verify(rw, req, false)
+	if !ok {
+		return
+	}
+
+	var msg acme.Order
+	if err := json.Unmarshal(signed.payload, &msg); err != nil {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "invalid order: "+err.Error())
+		return
+	}
+
+	if len(msg.Identifiers) == 0 {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "the order has no identifier")
+		return
+	}
+
+	for _, ident := range msg.Identifiers {
+		if ident.Type != "dns" || ident.Value == "" {
+			writeProblem(rw, http.StatusBadRequest, "rejectedIdentifier", fmt.Sprintf("unsupported identifier %s:%s", ident.Type, ident.Value))
+			return
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	expires := time.Now().Add(authorizationLifetime)
+
+	o := &order{
+		id:          newID(),
+		accountID:   signed.account.id,
+		status:      acme.StatusPending,
+		expires:     expires,
+		identifiers: msg.Identifiers,
+	}
+
+	for _, ident := range msg.Identifiers {
+		authz := s.newAuthorization(signed.account.id, ident.Value, expires)
+		o.authzIDs = append(o.authzIDs, authz.id)
+	}
+
+	s.orders[o.id] = o
+
+	base := baseURL(req)
+	rw.Header().Set("Location", base+"/order/"+o.id)
+	writeJSON(rw, http.StatusCreated, s.orderRepresentation(base, o))
+}
+
+func (s *server) getOrder(rw http.ResponseWriter, req *http.Request) {
+	signed, ok := s.verify(rw, req, false)
+	if !ok {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	o, ok := s.orders[mux.Vars(req)["id"]]
+	if !ok || o.accountID != signed.account.id {
+		writeProblem(rw, http.StatusNotFound, "malformed", "order not found")
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, s.orderRepresentation(baseURL(req), o))
+}
+
+func (s *server) finalizeOrder(rw http.ResponseWriter, req *http.Request) {
+	signed, ok := s.verify(rw, req, false)
+	if !ok {
+		return
+	}
+
+	var msg acme.CSRMessage
+	if err := json.Unmarshal(signed.payload, &msg); err != nil {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "invalid finalization request: "+err.Error())
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	o, ok := s.orders[mux.Vars(req)["id"]]
+	if !ok || o.accountID != signed.account.id {
+		writeProblem(rw, http.StatusNotFound, "malformed", "order not found")
+		return
+	}
+
+	s.updateOrderStatus(o)
+	if o.status != statusReady {
+		writeProblem(rw, http.StatusForbidden, "orderNotReady", fmt.Sprintf("the order is %s", o.status))
+		return
+	}
+
+	csr, err := parseCSR(msg.Csr, o.identifiers)
+	if err != nil {
+		writeProblem(rw, http.StatusBadRequest, "badCSR", err.Error())
+		return
+	}
+
+	chain, err := s.ca.issue(csr, time.Duration(s.config.CertificatesDuration))
+	if err != nil {
+		writeProblem(rw, http.StatusInternalServerError, "serverInternal", err.Error())
+		return
+	}
+
+	o.certID = newID()
+	o.status = acme.StatusValid
+	s.certificates[o.certID] = chain
+
+	base := baseURL(req)
+	rw.Header().Set("Location", base+"/order/"+o.id)
+	writeJSON(rw, http.StatusOK, s.orderRepresentation(base, o))
+}
+
+func (s *server) getAuthorization(rw http.ResponseWriter, req *http.Request) {
+	signed, ok := s.verify(rw, req, false)
+	if !ok {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	authz, ok := s.authorizations[mux.Vars(req)["id"]]
+	if !ok || authz.accountID != signed.account.id {
+		writeProblem(rw, http.StatusNotFound, "malformed", "authorization not found")
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, authz.representation(baseURL(req)))
+}
+
+func (s *server) validateChallenge(rw http.ResponseWriter, req *http.Request) {
+	signed, ok := s.verify(rw, req, false)
+	if !ok {
+		return
+	}
+
+	s.lock.Lock()
+	chlg, ok := s.challenges[mux.Vars(req)["id"]]
+	if !ok || s.authorizations[chlg.authzID].accountID != signed.account.id {
+		s.lock.Unlock()
+		writeProblem(rw, http.StatusNotFound, "malformed", "challenge not found")
+		return
+	}
+
+	authz := s.authorizations[chlg.authzID]
+	mustValidate := chlg.status == acme.StatusPending && authz.status == acme.StatusPending
+	if mustValidate {
+		chlg.status = acme.StatusProcessing
+	}
+	s.lock.Unlock()
+
+	// The validation is done synchronously, without holding the lock, as it may query this server.
+	var err error
+	if mustValidate {
+		err = s.validate(chlg.typ, authz.identifier.Value, chlg.token, chlg.token+"."+signed.account.thumbprint)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if mustValidate {
+		if err != nil {
+			log.WithoutContext().Debugf("Invalid %s challenge for %q: %v", chlg.typ, authz.identifier.Value, err)
+
+			chlg.status = acme.StatusInvalid
+			chlg.err = &acme.ProblemDetails{Type: problemType("incorrectResponse"), Detail: err.Error(), HTTPStatus: http.StatusForbidden}
+			authz.status = acme.StatusInvalid
+		} else {
+			chlg.status = acme.StatusValid
+			chlg.validated = time.Now()
+			authz.status = acme.StatusValid
+		}
+	}
+
+	base := baseURL(req)
+	rw.Header().Add("Link", fmt.Sprintf("<%s/authz/%s>;rel=\"up\"", base, authz.id))
+	writeJSON(rw, http.StatusOK, chlg.representation(base))
+}
+
+func (s *server) getCertificate(rw http.ResponseWriter, req *http.Request) {
+	signed, ok := s.verify(rw, req, false)
+	if !ok {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id := mux.Vars(req)["id"]
+
+	chain, ok := s.certificates[id]
+	if !ok || !s.ownsCertificate(signed.account.id, id) {
+		writeProblem(rw, http.StatusNotFound, "malformed", "certificate not found")
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/pem-certificate-chain")
+	_, _ = rw.Write(chain)
+}
+
+func (s *server) newAuthorization(accountID, domain string, expires time.Time) *authorization {
+	authz := &authorization{
+		id:         newID(),
+		accountID:  accountID,
+		status:     acme.StatusPending,
+		expires:    expires,
+		identifier: acme.Identifier{Type: "dns", Value: domain},
+	}
+
+	// The wildcard domains can only be validated with the DNS-01 challenge.
+	challengeTypes := []string{challengeHTTP01, challengeTLSALPN01, challengeDNS01}
+	if strings.HasPrefix(domain, "*.") {
+		authz.identifier.Value = strings.TrimPrefix(domain, "*.")
+		authz.wildcard = true
+		challengeTypes = []string{challengeDNS01}
+	}
+
+	for _, typ := range challengeTypes {
+		chlg := &challenge{
+			id:      newID(),
+			authzID: authz.id,
+			typ:     typ,
+			token:   newToken(),
+			status:  acme.StatusPending,
+		}
+
+		authz.challenges = append(authz.challenges, chlg)
+		s.challenges[chlg.id] = chlg
+	}
+
+	s.authorizations[authz.id] = authz
+
+	return authz
+}
+
+func (s *server) updateOrderStatus(o *order) {
+	if o.status != acme.StatusPending {
+		return
+	}
+
+	ready := true
+	for _, id := range o.authzIDs {
+		switch s.authorizations[id].status {
+		case acme.StatusValid:
+		case acme.StatusPending:
+			ready = false
+		default:
+			o.status = acme.StatusInvalid
+			return
+		}
+	}
+
+	if ready {
+		o.status = statusReady
+	} else if time.Now().After(o.expires) {
+		o.status = acme.StatusInvalid
+	}
+}
+
+func (s *server) accountByThumbprint(thumbprint string) *account {
+	for _, acct := range s.accounts {
+		if acct.thumbprint == thumbprint {
+			return acct
+		}
+	}
+
+	return nil
+}
+
+func (s *server) ownsCertificate(accountID, certID string) bool {
+	for _, o := range s.orders {
+		if o.certID == certID {
+			return o.accountID == accountID
+		}
+	}
+
+	return false
+}
+
+func (s *server) orderRepresentation(base string, o *order) acme.Order {
+	s.updateOrderStatus(o)
+
+	repr := acme.Order{
+		Status:      o.status,
+		Expires:     o.expires.Format(time.RFC3339),
+		Identifiers: o.identifiers,
+		Finalize:    base + "/order/" + o.id + "/finalize",
+	}
+
+	for _, id := range o.authzIDs {
+		repr.Authorizations = append(repr.Authorizations, base+"/authz/"+id)
+	}
+
+	if o.certID != "" {
+		repr.Certificate = base + "/cert/" + o.certID
+	}
+
+	return repr
+}
+
+func (a *account) representation() acme.Account {
+	return acme.Account{
+		Status:  acme.StatusValid,
+		Contact: a.contact,
+	}
+}
+
+func (a *authorization) representation(base string) acme.Authorization {
+	repr := acme.Authorization{
+		Status:     a.status,
+		Expires:    a.expires,
+		Identifier: a.identifier,
+		Wildcard:   a.wildcard,
+	}
+
+	for _, chlg := range a.challenges {
+		repr.Challenges = append(repr.Challenges, chlg.representation(base))
+	}
+
+	return repr
+}
+
+func (c *challenge) representation(base string) acme.Challenge {
+	return acme.Challenge{
+		Type:      c.typ,
+		URL:       base + "/chall/" + c.id,
+		Status:    c.status,
+		Validated: c.validated,
+		Error:     c.err,
+		Token:     c.token,
+	}
+}
+
+func baseURL(req *http.Request) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + req.Host + PathPrefix
+}
+
+func keyThumbprint(key *jose.JSONWebKey) (string, error) {
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("unable to compute the key thumbprint: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
+
+func newID() string {
+	return randomString(12)
+}
+
+func newToken() string {
+	return randomString(32)
+}
+
+func randomString(size int) string {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJSON(rw http.ResponseWriter, status int, data interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	if err := json.NewEncoder(rw).Encode(data); err != nil {
+		log.WithoutContext().Errorf("Unable to write ACME server response: %v", err)
+	}
+}
+
+func problemType(typ string) string {
+	return "urn:ietf:params:acme:error:" + typ
+}
+
+func writeProblem(rw http.ResponseWriter, status int, typ, detail string) {
+	rw.Header().Set("Content-Type", "application/problem+json")
+	rw.WriteHeader(status)
+
+	problem := acme.ProblemDetails{Type: problemType(typ), Detail: detail, HTTPStatus: status}
+	if err := json.NewEncoder(rw).Encode(problem); err != nil {
+		log.WithoutContext().Errorf("Unable to write ACME server response: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package acmeserver
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/challenge/http01"
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/go-acme/lego/v3/registration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	registration *registration.Resource
+	key          crypto.PrivateKey
+}
+
+func (u *user) GetEmail() string                        { return "test@example.com" }
+func (u *user) GetRegistration() *registration.Resource { return u.registration }
+func (u *user) GetPrivateKey() crypto.PrivateKey        { return u.key }
+
+// challengeProvider serves the HTTP-01 challenges, and accepts any other challenge.
+type challengeProvider struct {
+	lock     sync.Mutex
+	keyAuths map[string]string
+}
+
+func (p *challengeProvider) Present(_, token, keyAuth string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.keyAuths[http01.ChallengePath(token)] = keyAuth
+	return nil
+}
+
+func (p *challengeProvider) CleanUp(_, token, _ string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.keyAuths, http01.ChallengePath(token))
+	return nil
+}
+
+func (p *challengeProvider) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	keyAuth, ok := p.keyAuths[req.URL.Path]
+	if !ok {
+		http.NotFound(rw, req)
+		return
+	}
+
+	_, _ = rw.Write([]byte(keyAuth))
+}
+
+func TestHandler(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		domains        []string
+		skipValidation bool
+		serveHTTP01    bool
+		expectedErr    bool
+	}{
+		{
+			desc:        "HTTP-01 challenge",
+			domains:     []string{"localhost"},
+			serveHTTP01: true,
+		},
+		{
+			desc:        "HTTP-01 challenge not served",
+			domains:     []string{"localhost"},
+			expectedErr: true,
+		},
+		{
+			desc:           "validation skipped",
+			domains:        []string{"example.com", "www.example.com"},
+			skipValidation: true,
+		},
+		{
+			desc:           "wildcard and apex",
+			domains:        []string{"*.example.com", "example.com"},
+			skipValidation: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			provider := &challengeProvider{keyAuths: make(map[string]string)}
+
+			var challengeHandler http.Handler = http.NotFoundHandler()
+			if test.serveHTTP01 {
+				challengeHandler = provider
+			}
+
+			challengeServer := httptest.NewServer(challengeHandler)
+			defer challengeServer.Close()
+
+			_, port, err := net.SplitHostPort(challengeServer.Listener.Addr().String())
+			require.NoError(t, err)
+
+			handler := &Handler{}
+			handler.SetDefaults()
+			handler.HTTPPort, err = strconv.Atoi(port)
+			require.NoError(t, err)
+			handler.SkipValidation = test.skipValidation
+
+			require.NoError(t, handler.Init())
+
+			acmeServer := httptest.NewServer(handler)
+			defer acmeServer.Close()
+
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			require.NoError(t, err)
+
+			u := &user{key: key}
+
+			config := lego.NewConfig(u)
+			config.CADirURL = acmeServer.URL + DirectoryPath
+
+			client, err := lego.NewClient(config)
+			require.NoError(t, err)
+
+			u.registration, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+			require.NoError(t, err)
+
+			err = client.Challenge.SetHTTP01Provider(provider)
+			require.NoError(t, err)
+
+			err = client.Challenge.SetDNS01Provider(provider, dns01.WrapPreCheck(func(_, _, _ string, _ dns01.PreCheckFunc) (bool, error) {
+				return true, nil
+			}))
+			require.NoError(t, err)
+
+			resource, err := client.Certificate.Obtain(certificate.ObtainRequest{Domains: test.domains, Bundle: true})
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			block, _ := pem.Decode(resource.Certificate)
+			require.NotNil(t, block)
+
+			cert, err := x509.ParseCertificate(block.Bytes)
+			require.NoError(t, err)
+
+			assert.ElementsMatch(t, test.domains, cert.DNSNames)
+			assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), cert.NotAfter, time.Minute)
+
+			roots := x509.NewCertPool()
+			require.True(t, roots.AppendCertsFromPEM(handler.CACertificate()))
+
+			_, err = cert.Verify(x509.VerifyOptions{DNSName: test.domains[len(test.domains)-1], Roots: roots})
+			require.NoError(t, err)
+
+			// The account is found again from its key.
+			_, err = client.Registration.ResolveAccountByKey()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHandler_CertificatesDuration(t *testing.T) {
+	handler := &Handler{
+		CertificatesDuration: types.Duration(24 * time.Hour),
+		SkipValidation:       true,
+	}
+	require.NoError(t, handler.Init())
+
+	acmeServer := httptest.NewServer(handler)
+	defer acmeServer.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	u := &user{key: key}
+
+	config := lego.NewConfig(u)
+	config.CADirURL = acmeServer.URL + DirectoryPath
+
+	client, err := lego.NewClient(config)
+	require.NoError(t, err)
+
+	u.registration, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	require.NoError(t, err)
+
+	err = client.Challenge.SetHTTP01Provider(&challengeProvider{keyAuths: make(map[string]string)})
+	require.NoError(t, err)
+
+	resource, err := client.Certificate.Obtain(certificate.ObtainRequest{Domains: []string{"example.com"}})
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(resource.Certificate)
+	require.NotNil(t, block)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), cert.NotAfter, time.Minute)
+}
@@ -0,0 +1,192 @@
+package acmeserver
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/go-acme/lego/v3/acme"
+)
+
+const caDuration = 10 * 365 * 24 * time.Hour
+
+// certificateAuthority is the internal CA issuing the certificates.
+type certificateAuthority struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     crypto.Signer
+}
+
+func newCertificateAuthority(certFile, keyFile traefiktls.FileOrContent) (*certificateAuthority, error) {
+	if certFile == "" && keyFile == "" {
+		return generateCertificateAuthority()
+	}
+
+	certContent, err := certFile.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the CA certificate: %w", err)
+	}
+
+	keyContent, err := keyFile.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the CA key: %w", err)
+	}
+
+	keyPair, err := tls.X509KeyPair(certContent, keyContent)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the CA key pair: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the CA certificate: %w", err)
+	}
+
+	if !cert.IsCA {
+		return nil, errors.New("the certificate of the ACME server is not a CA certificate")
+	}
+
+	key, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported CA key")
+	}
+
+	return &certificateAuthority{
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+		key:     key,
+	}, nil
+}
+
+func generateCertificateAuthority() (*certificateAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate the CA key: %w", err)
+	}
+
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "TRAEFIK ACME SERVER CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(caDuration),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate the CA certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certificateAuthority{
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:     key,
+	}, nil
+}
+
+// issue signs the CSR, and returns the PEM encoded chain of the certificate.
+func (ca *certificateAuthority) issue(csr *x509.CertificateRequest, duration time.Duration) ([]byte, error) {
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	notAfter := time.Now().Add(duration)
+	if notAfter.After(ca.cert.NotAfter) {
+		notAfter = ca.cert.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: csr.Subject.CommonName},
+		DNSNames:              csr.DNSNames,
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign the certificate: %w", err)
+	}
+
+	return append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), ca.certPEM...), nil
+}
+
+// parseCSR decodes the CSR of a finalization request, and checks that it matches the identifiers of the order.
+func parseCSR(encoded string, identifiers []acme.Identifier) (*x509.CertificateRequest, error) {
+	der, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the CSR: %w", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the CSR: %w", err)
+	}
+
+	if err = csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid CSR signature: %w", err)
+	}
+
+	names := make(map[string]struct{})
+	for _, name := range csr.DNSNames {
+		names[strings.ToLower(name)] = struct{}{}
+	}
+	if csr.Subject.CommonName != "" {
+		names[strings.ToLower(csr.Subject.CommonName)] = struct{}{}
+	}
+
+	expected := make(map[string]struct{})
+	for _, ident := range identifiers {
+		expected[strings.ToLower(ident.Value)] = struct{}{}
+	}
+
+	if len(names) != len(expected) {
+		return nil, errors.New("the CSR names do not match the order identifiers")
+	}
+
+	for name := range names {
+		if _, ok := expected[name]; !ok {
+			return nil, fmt.Errorf("the CSR name %q is not an identifier of the order", name)
+		}
+	}
+
+	return csr, nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate a serial number: %w", err)
+	}
+
+	return serial, nil
+}
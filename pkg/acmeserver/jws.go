@@ -0,0 +1,155 @@
+package acmeserver
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+const (
+	maxBodySize = 1024 * 1024
+	maxNonces   = 10000
+)
+
+type signedRequest struct {
+	payload []byte
+	// jwk is the key embedded in the request, only used to create the accounts.
+	jwk *jose.JSONWebKey
+	// account is the account identified by the key ID of the request, if any.
+	account *account
+}
+
+// verify checks the JWS of the request, and writes the error to the response when the request is not valid.
+// The key can only be embedded in the request when embeddedKey is true,
+// and, as lego uses the key ID once the account is known, the key ID is always accepted.
+func (s *server) verify(rw http.ResponseWriter, req *http.Request, embeddedKey bool) (*signedRequest, bool) {
+	s.setNonce(rw)
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, maxBodySize))
+	if err != nil {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "unable to read the request: "+err.Error())
+		return nil, false
+	}
+
+	jws, err := jose.ParseSigned(string(body))
+	if err != nil {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "invalid JWS: "+err.Error())
+		return nil, false
+	}
+
+	if len(jws.Signatures) != 1 {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "the JWS must have exactly one signature")
+		return nil, false
+	}
+
+	header := jws.Signatures[0].Protected
+
+	if !s.consumeNonce(header.Nonce) {
+		writeProblem(rw, http.StatusBadRequest, "badNonce", "invalid nonce")
+		return nil, false
+	}
+
+	if err = checkURL(header.ExtraHeaders["url"], req.URL.Path); err != nil {
+		writeProblem(rw, http.StatusUnauthorized, "unauthorized", err.Error())
+		return nil, false
+	}
+
+	signed := &signedRequest{}
+
+	var key interface{}
+	switch {
+	case embeddedKey && header.JSONWebKey != nil && header.KeyID == "":
+		if !header.JSONWebKey.Valid() {
+			writeProblem(rw, http.StatusBadRequest, "badPublicKey", "invalid JWK")
+			return nil, false
+		}
+
+		signed.jwk = header.JSONWebKey
+		key = header.JSONWebKey
+
+	case header.JSONWebKey == nil && header.KeyID != "":
+		signed.account = s.accountByKeyID(header.KeyID)
+		if signed.account == nil {
+			writeProblem(rw, http.StatusBadRequest, "accountDoesNotExist", "unknown account")
+			return nil, false
+		}
+
+		key = signed.account.key
+
+	default:
+		writeProblem(rw, http.StatusBadRequest, "malformed", `the JWS must use either the "jwk" or the "kid" header`)
+		return nil, false
+	}
+
+	signed.payload, err = jws.Verify(key)
+	if err != nil {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "invalid JWS signature: "+err.Error())
+		return nil, false
+	}
+
+	return signed, true
+}
+
+func (s *server) accountByKeyID(keyID string) *account {
+	u, err := url.Parse(keyID)
+	if err != nil {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.accounts[path.Base(u.Path)]
+}
+
+func (s *server) setNonce(rw http.ResponseWriter) {
+	nonce := randomString(16)
+
+	s.lock.Lock()
+	// The nonces are only kept to prevent replays, so dropping them all when there are too many only causes retries.
+	if len(s.nonces) >= maxNonces {
+		s.nonces = make(map[string]struct{})
+	}
+	s.nonces[nonce] = struct{}{}
+	s.lock.Unlock()
+
+	rw.Header().Set("Replay-Nonce", nonce)
+	rw.Header().Set("Cache-Control", "no-store")
+}
+
+func (s *server) consumeNonce(nonce string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.nonces[nonce]; !ok {
+		return false
+	}
+
+	delete(s.nonces, nonce)
+
+	return true
+}
+
+// checkURL checks that the URL protected by the JWS is the requested one.
+// Only the paths are compared, as the host seen by Traefik can differ from the one used by the client.
+func checkURL(value interface{}, requestPath string) error {
+	raw, ok := value.(string)
+	if !ok {
+		return errors.New(`missing "url" header`)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.New(`invalid "url" header`)
+	}
+
+	if u.Path != requestPath {
+		return errors.New(`the "url" header does not match the request`)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package acmeserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/challenge/http01"
+	"github.com/go-acme/lego/v3/challenge/tlsalpn01"
+)
+
+const (
+	challengeHTTP01    = "http-01"
+	challengeTLSALPN01 = "tls-alpn-01"
+	challengeDNS01     = "dns-01"
+)
+
+const validationTimeout = 10 * time.Second
+
+// idPeAcmeIdentifier is the OID of the extension holding the key authorization of a TLS-ALPN-01 challenge.
+var idPeAcmeIdentifier = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
+// validate checks that the challenge is fulfilled for the domain.
+func (s *server) validate(typ, domain, token, keyAuth string) error {
+	if s.config.SkipValidation {
+		return nil
+	}
+
+	switch typ {
+	case challengeHTTP01:
+		return validateHTTP01(domain, s.config.HTTPPort, token, keyAuth)
+	case challengeTLSALPN01:
+		return validateTLSALPN01(domain, s.config.TLSPort, keyAuth)
+	case challengeDNS01:
+		return validateDNS01(domain, keyAuth)
+	default:
+		return fmt.Errorf("unsupported challenge %s", typ)
+	}
+}
+
+func validateHTTP01(domain string, port int, token, keyAuth string) error {
+	client := &http.Client{Timeout: validationTimeout}
+
+	uri := "http://" + net.JoinHostPort(domain, strconv.Itoa(port)) + http01.ChallengePath(token)
+
+	resp, err := client.Get(uri)
+	if err != nil {
+		return fmt.Errorf("unable to fetch %s: %w", uri, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, uri)
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxBodySize))
+	if err != nil {
+		return fmt.Errorf("unable to read the response from %s: %w", uri, err)
+	}
+
+	if strings.TrimSpace(string(body)) != keyAuth {
+		return fmt.Errorf("invalid key authorization from %s", uri)
+	}
+
+	return nil
+}
+
+func validateTLSALPN01(domain string, port int, keyAuth string) error {
+	dialer := &net.Dialer{Timeout: validationTimeout}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(domain, strconv.Itoa(port)), &tls.Config{
+		ServerName: domain,
+		NextProtos: []string{tlsalpn01.ACMETLS1Protocol},
+		// The certificate is self-signed by design.
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", domain, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	state := conn.ConnectionState()
+	if state.NegotiatedProtocol != tlsalpn01.ACMETLS1Protocol {
+		return fmt.Errorf("the %s protocol has not been negotiated", tlsalpn01.ACMETLS1Protocol)
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("no certificate presented")
+	}
+
+	cert := state.PeerCertificates[0]
+	if err = cert.VerifyHostname(domain); err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256([]byte(keyAuth))
+	expected, err := asn1.Marshal(digest[:])
+	if err != nil {
+		return err
+	}
+
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(idPeAcmeIdentifier) {
+			if !ext.Critical || !bytes.Equal(ext.Value, expected) {
+				return errors.New("invalid acmeIdentifier extension")
+			}
+			return nil
+		}
+	}
+
+	return errors.New("missing acmeIdentifier extension")
+}
+
+func validateDNS01(domain, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+
+	records, err := net.LookupTXT(dns01.UnFqdn(fqdn))
+	if err != nil {
+		return fmt.Errorf("unable to look up the TXT records of %s: %w", fqdn, err)
+	}
+
+	for _, record := range records {
+		if record == value {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no TXT record of %s matches the key authorization", fqdn)
+}
@@ -27,8 +27,9 @@ func TestDo_globalConfiguration(t *testing.T) {
 	config := &static.Configuration{}
 
 	config.Global = &static.Global{
-		CheckNewVersion:    true,
-		SendAnonymousUsage: true,
+		CheckNewVersion:     true,
+		SendAnonymousUsage:  true,
+		AllowFaultInjection: true,
 	}
 
 	config.AccessLog = &types.AccessLog{
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/schema"
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/version"
@@ -44,6 +45,12 @@ type RunTimeRepresentation struct {
 	UDPServices map[string]*runtime.UDPServiceInfo    `json:"udpServices,omitempty"`
 }
 
+// SecretsConcealer conceals the values of the secret references of the configuration.
+type SecretsConcealer interface {
+	// Conceal replaces the values of the secret fields of the element, which were resolved from secret references, by these references.
+	Conceal(element interface{})
+}
+
 // Handler serves the configuration and status of Traefik on API endpoints.
 type Handler struct {
 	dashboard       bool
@@ -53,12 +60,41 @@ type Handler struct {
 
 	// runtimeConfiguration is the data set used to create all the data representations exposed by the API.
 	runtimeConfiguration *runtime.Configuration
+
+	// acmeResolvers provide the certificate status of the domains managed by the ACME resolvers.
+	acmeResolvers []ACMEResolver
+
+	// connectionTables provide the active connections of the entry points.
+	connectionTables ConnectionTables
+
+	// pathStatistics provide the paths using the most bandwidth on the routers.
+	pathStatistics PathStatistics
+
+	// tlsStores provide the certificates of the TLS stores, and the failures of the TLS handshakes.
+	tlsStores TLSStores
+
+	// providerResyncer resyncs the configuration of the providers.
+	providerResyncer ProviderResyncer
+
+	// providerSchemas holds the JSON Schemas of the provider-specific configurations, such as the Ingress annotations.
+	providerSchemas map[string]*schema.Schema
+
+	// secrets conceals the values of the secret references, so that they are never exposed.
+	secrets SecretsConcealer
 }
 
 // NewBuilder returns a http.Handler builder based on runtime.Configuration
-func NewBuilder(staticConfig static.Configuration) func(*runtime.Configuration) http.Handler {
+func NewBuilder(staticConfig static.Configuration, acmeResolvers []ACMEResolver, connectionTables ConnectionTables, pathStatistics PathStatistics, tlsStores TLSStores, providerResyncer ProviderResyncer, providerSchemas map[string]*schema.Schema, secrets SecretsConcealer) func(*runtime.Configuration) http.Handler {
 	return func(configuration *runtime.Configuration) http.Handler {
-		return New(staticConfig, configuration).createRouter()
+		handler := New(staticConfig, configuration)
+		handler.acmeResolvers = acmeResolvers
+		handler.connectionTables = connectionTables
+		handler.pathStatistics = pathStatistics
+		handler.tlsStores = tlsStores
+		handler.providerResyncer = providerResyncer
+		handler.providerSchemas = providerSchemas
+		handler.secrets = secrets
+		return handler.createRouter()
 	}
 }
 
@@ -88,19 +124,30 @@ func (h Handler) createRouter() *mux.Router {
 	}
 
 	router.Methods(http.MethodGet).Path("/api/rawdata").HandlerFunc(h.getRuntimeConfiguration)
+	router.Methods(http.MethodGet).Path("/api/snapshot").HandlerFunc(h.getSnapshot)
+	router.Methods(http.MethodGet).Path("/api/discrepancies").HandlerFunc(h.getDiscrepancies)
+	router.Methods(http.MethodGet).Path("/api/warnings").HandlerFunc(h.getWarnings)
+	router.Methods(http.MethodGet).Path("/api/schemas/{schemaID}").HandlerFunc(h.getSchema)
 
 	// Experimental endpoint
 	router.Methods(http.MethodGet).Path("/api/overview").HandlerFunc(h.getOverview)
 
 	router.Methods(http.MethodGet).Path("/api/entrypoints").HandlerFunc(h.getEntryPoints)
 	router.Methods(http.MethodGet).Path("/api/entrypoints/{entryPointID}").HandlerFunc(h.getEntryPoint)
+	router.Methods(http.MethodGet).Path("/api/entrypoints/{entryPointID}/connections").HandlerFunc(h.getEntryPointConnections)
 
 	router.Methods(http.MethodGet).Path("/api/http/routers").HandlerFunc(h.getRouters)
 	router.Methods(http.MethodGet).Path("/api/http/routers/{routerID}").HandlerFunc(h.getRouter)
+	router.Methods(http.MethodGet).Path("/api/http/routers/{routerID}/paths").HandlerFunc(h.getRouterPaths)
 	router.Methods(http.MethodGet).Path("/api/http/services").HandlerFunc(h.getServices)
 	router.Methods(http.MethodGet).Path("/api/http/services/{serviceID}").HandlerFunc(h.getService)
 	router.Methods(http.MethodGet).Path("/api/http/middlewares").HandlerFunc(h.getMiddlewares)
 	router.Methods(http.MethodGet).Path("/api/http/middlewares/{middlewareID}").HandlerFunc(h.getMiddleware)
+	router.Methods(http.MethodGet).Path("/api/http/middlewares/{middlewareID}/keys").HandlerFunc(h.getAPIKeys)
+	router.Methods(http.MethodPost).Path("/api/http/middlewares/{middlewareID}/keys").HandlerFunc(h.createAPIKey)
+	router.Methods(http.MethodGet).Path("/api/http/middlewares/{middlewareID}/keys/{keyID}").HandlerFunc(h.getAPIKey)
+	router.Methods(http.MethodPut).Path("/api/http/middlewares/{middlewareID}/keys/{keyID}").HandlerFunc(h.updateAPIKey)
+	router.Methods(http.MethodDelete).Path("/api/http/middlewares/{middlewareID}/keys/{keyID}").HandlerFunc(h.deleteAPIKey)
 
 	router.Methods(http.MethodGet).Path("/api/tcp/routers").HandlerFunc(h.getTCPRouters)
 	router.Methods(http.MethodGet).Path("/api/tcp/routers/{routerID}").HandlerFunc(h.getTCPRouter)
@@ -112,6 +159,15 @@ func (h Handler) createRouter() *mux.Router {
 	router.Methods(http.MethodGet).Path("/api/udp/services").HandlerFunc(h.getUDPServices)
 	router.Methods(http.MethodGet).Path("/api/udp/services/{serviceID}").HandlerFunc(h.getUDPService)
 
+	router.Methods(http.MethodGet).Path("/api/acme/domains").HandlerFunc(h.getACMEDomains)
+	router.Methods(http.MethodGet).Path("/api/acme/domains/{domainID}").HandlerFunc(h.getACMEDomain)
+
+	router.Methods(http.MethodGet).Path("/api/tls/certificates").HandlerFunc(h.getTLSCertificates)
+	router.Methods(http.MethodGet).Path("/api/tls/stores/{storeID}/certificate").HandlerFunc(h.getTLSStoreCertificate)
+	router.Methods(http.MethodGet).Path("/api/tls/handshake-errors").HandlerFunc(h.getTLSHandshakeErrors)
+
+	router.Methods(http.MethodPost).Path("/api/providers/{providerID}/resync").HandlerFunc(h.resyncProvider)
+
 	version.Handler{}.Append(router)
 
 	if h.dashboard {
@@ -125,14 +181,19 @@ func (h Handler) getRuntimeConfiguration(rw http.ResponseWriter, request *http.R
 	siRepr := make(map[string]*serviceInfoRepresentation, len(h.runtimeConfiguration.Services))
 	for k, v := range h.runtimeConfiguration.Services {
 		siRepr[k] = &serviceInfoRepresentation{
-			ServiceInfo:  v,
+			ServiceInfo:  h.concealService(v),
 			ServerStatus: v.GetAllStatus(),
 		}
 	}
 
+	miRepr := make(map[string]*runtime.MiddlewareInfo, len(h.runtimeConfiguration.Middlewares))
+	for k, v := range h.runtimeConfiguration.Middlewares {
+		miRepr[k] = h.concealMiddleware(v)
+	}
+
 	result := RunTimeRepresentation{
 		Routers:     h.runtimeConfiguration.Routers,
-		Middlewares: h.runtimeConfiguration.Middlewares,
+		Middlewares: miRepr,
 		Services:    siRepr,
 		TCPRouters:  h.runtimeConfiguration.TCPRouters,
 		TCPServices: h.runtimeConfiguration.TCPServices,
@@ -165,3 +226,36 @@ func extractType(element interface{}) string {
 	}
 	return ""
 }
+
+// concealMiddleware returns a copy of the middleware with the values of its secret references concealed,
+// or the middleware itself when there are no secret references.
+func (h Handler) concealMiddleware(mi *runtime.MiddlewareInfo) *runtime.MiddlewareInfo {
+	if h.secrets == nil || mi.Middleware == nil {
+		return mi
+	}
+
+	concealed := *mi
+	concealed.Middleware = mi.Middleware.DeepCopy()
+	h.secrets.Conceal(concealed.Middleware)
+
+	return &concealed
+}
+
+// concealService returns a copy of the service with the values of its secret references concealed,
+// or the service itself when there are no secret references.
+// The status of the servers is not copied.
+func (h Handler) concealService(si *runtime.ServiceInfo) *runtime.ServiceInfo {
+	if h.secrets == nil || si.Service == nil {
+		return si
+	}
+
+	concealed := &runtime.ServiceInfo{
+		Service: si.Service.DeepCopy(),
+		Err:     si.Err,
+		Status:  si.Status,
+		UsedBy:  si.UsedBy,
+	}
+	h.secrets.Conceal(concealed.Service)
+
+	return concealed
+}
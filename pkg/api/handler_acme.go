@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider/acme"
+	"github.com/gorilla/mux"
+)
+
+// ACMEResolver exposes the certificate status of the domains managed by an ACME resolver.
+type ACMEResolver interface {
+	GetDomainStatuses() []acme.DomainStatus
+}
+
+type acmeDomainRepresentation struct {
+	acme.DomainStatus
+	Name string `json:"name,omitempty"`
+}
+
+func newACMEDomainRepresentation(status acme.DomainStatus) acmeDomainRepresentation {
+	return acmeDomainRepresentation{
+		DomainStatus: status,
+		Name:         status.Name() + "@" + status.Resolver,
+	}
+}
+
+func (h Handler) getACMEDomains(rw http.ResponseWriter, request *http.Request) {
+	results := make([]acmeDomainRepresentation, 0)
+
+	criterion := newSearchCriterion(request.URL.Query())
+
+	for _, resolver := range h.acmeResolvers {
+		for _, status := range resolver.GetDomainStatuses() {
+			domain := newACMEDomainRepresentation(status)
+			if keepACMEDomain(domain, criterion) {
+				results = append(results, domain)
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) getACMEDomain(rw http.ResponseWriter, request *http.Request) {
+	domainID := mux.Vars(request)["domainID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	for _, resolver := range h.acmeResolvers {
+		for _, status := range resolver.GetDomainStatuses() {
+			result := newACMEDomainRepresentation(status)
+			if result.Name != domainID {
+				continue
+			}
+
+			err := json.NewEncoder(rw).Encode(result)
+			if err != nil {
+				log.FromContext(request.Context()).Error(err)
+				writeError(rw, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	writeError(rw, fmt.Sprintf("ACME domain not found: %s", domainID), http.StatusNotFound)
+}
+
+func keepACMEDomain(domain acmeDomainRepresentation, criterion *searchCriterion) bool {
+	if criterion == nil {
+		return true
+	}
+
+	return criterion.withStatus(domain.Status) && criterion.searchIn(domain.Name, domain.Error)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/provider/acme"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type acmeResolverMock []acme.DomainStatus
+
+func (m acmeResolverMock) GetDomainStatuses() []acme.DomainStatus {
+	return m
+}
+
+func TestHandler_ACME(t *testing.T) {
+	type expected struct {
+		statusCode int
+		nextPage   string
+		jsonFile   string
+	}
+
+	lastAttempt := time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+	nextRenewal := notAfter.Add(-30 * 24 * time.Hour)
+
+	resolvers := []ACMEResolver{
+		acmeResolverMock{
+			{
+				Resolver:    "myresolver",
+				Domain:      types.Domain{Main: "foo.example.com", SANs: []string{"www.foo.example.com"}},
+				Status:      acme.DomainStatusValid,
+				NotAfter:    &notAfter,
+				NextRenewal: &nextRenewal,
+				LastAttempt: &lastAttempt,
+			},
+			{
+				Resolver:    "myresolver",
+				Domain:      types.Domain{Main: "bar.example.com"},
+				Status:      acme.DomainStatusError,
+				Error:       "acme: error: 400 :: urn:ietf:params:acme:error:connection :: Timeout during connect",
+				LastAttempt: &lastAttempt,
+			},
+		},
+		acmeResolverMock{
+			{
+				Resolver:    "otherresolver",
+				Domain:      types.Domain{Main: "baz.example.com"},
+				Status:      acme.DomainStatusPending,
+				LastAttempt: &lastAttempt,
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc      string
+		path      string
+		resolvers []ACMEResolver
+		expected  expected
+	}{
+		{
+			desc: "all domains, but no resolver",
+			path: "/api/acme/domains",
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/acmedomains-empty.json",
+			},
+		},
+		{
+			desc:      "all domains",
+			path:      "/api/acme/domains",
+			resolvers: resolvers,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/acmedomains.json",
+			},
+		},
+		{
+			desc:      "domains filtered by status",
+			path:      "/api/acme/domains?status=error",
+			resolvers: resolvers,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/acmedomains-filtered-status.json",
+			},
+		},
+		{
+			desc:      "domains filtered by search",
+			path:      "/api/acme/domains?search=www.foo",
+			resolvers: resolvers,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/acmedomains-filtered-search.json",
+			},
+		},
+		{
+			desc:      "one domain by id",
+			path:      "/api/acme/domains/bar.example.com@myresolver",
+			resolvers: resolvers,
+			expected: expected{
+				statusCode: http.StatusOK,
+				jsonFile:   "testdata/acmedomain-bar.json",
+			},
+		},
+		{
+			desc:      "one domain by id, that does not exist",
+			path:      "/api/acme/domains/bar.example.com@otherresolver",
+			resolvers: resolvers,
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, test.resolvers, nil, nil, nil, nil, nil, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			require.Equal(t, test.expected.statusCode, resp.StatusCode)
+
+			assert.Equal(t, test.expected.nextPage, resp.Header.Get(nextPageHeader))
+
+			if test.expected.jsonFile == "" {
+				return
+			}
+
+			assert.Equal(t, resp.Header.Get("Content-Type"), "application/json")
+			contents, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			if *updateExpected {
+				var results interface{}
+				err := json.Unmarshal(contents, &results)
+				require.NoError(t, err)
+
+				newJSON, err := json.MarshalIndent(results, "", "\t")
+				require.NoError(t, err)
+
+				err = ioutil.WriteFile(test.expected.jsonFile, newJSON, 0644)
+				require.NoError(t, err)
+			}
+
+			data, err := ioutil.ReadFile(test.expected.jsonFile)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(contents))
+		})
+	}
+}
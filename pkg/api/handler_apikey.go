@@ -0,0 +1,275 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares/apikey"
+	"github.com/gorilla/mux"
+)
+
+// apiKeyRepresentation is the representation of a key in the API,
+// which designates the key by its ID, and never discloses its value.
+type apiKeyRepresentation struct {
+	ID       string            `json:"id"`
+	Tenant   string            `json:"tenant,omitempty"`
+	Plan     string            `json:"plan,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func newAPIKeyRepresentation(key apikey.Key) apiKeyRepresentation {
+	return apiKeyRepresentation{
+		ID:       key.ID(),
+		Tenant:   key.Tenant,
+		Plan:     key.Plan,
+		Metadata: key.Metadata,
+	}
+}
+
+// createdAPIKeyRepresentation is the representation of a created key,
+// the only one disclosing its value, so that it can be handed to the client.
+type createdAPIKeyRepresentation struct {
+	apiKeyRepresentation
+	Key string `json:"key"`
+}
+
+// getAPIKeyStore returns the key store of the APIKey middleware of the request path,
+// or writes an error if there is no such middleware.
+// The key management is refused unless it is enabled, and when the API is exposed in insecure mode, as it is then not authenticated.
+func (h Handler) getAPIKeyStore(rw http.ResponseWriter, request *http.Request) (apikey.Store, bool) {
+	if h.staticConfig.API == nil || !h.staticConfig.API.KeyManagement {
+		writeError(rw, "the key management is not enabled", http.StatusForbidden)
+		return nil, false
+	}
+
+	if h.staticConfig.API.Insecure {
+		writeError(rw, "the key management is not available when the API is in insecure mode", http.StatusForbidden)
+		return nil, false
+	}
+
+	middlewareID := mux.Vars(request)["middlewareID"]
+
+	middleware, ok := h.runtimeConfiguration.Middlewares[middlewareID]
+	if !ok || middleware.Middleware == nil || middleware.APIKey == nil {
+		writeError(rw, fmt.Sprintf("APIKey middleware not found: %s", middlewareID), http.StatusNotFound)
+		return nil, false
+	}
+
+	store, err := apikey.GetStore(middlewareID, *middleware.APIKey)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return store, true
+}
+
+// findAPIKey returns the key of the request path, designated by its ID,
+// or writes an error if there is no such key.
+func findAPIKey(rw http.ResponseWriter, request *http.Request, store apikey.Store) (*apikey.Key, bool) {
+	keyID := mux.Vars(request)["keyID"]
+
+	keys, err := store.List()
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	for _, key := range keys {
+		if key.ID() == keyID {
+			key := key
+			return &key, true
+		}
+	}
+
+	writeError(rw, fmt.Sprintf("API key not found: %s", keyID), http.StatusNotFound)
+	return nil, false
+}
+
+func (h Handler) getAPIKeys(rw http.ResponseWriter, request *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	store, ok := h.getAPIKeyStore(rw, request)
+	if !ok {
+		return
+	}
+
+	keys, err := store.List()
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pageInfo, err := pagination(request, len(keys))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	results := make([]apiKeyRepresentation, 0, pageInfo.endIndex-pageInfo.startIndex)
+	for _, key := range keys[pageInfo.startIndex:pageInfo.endIndex] {
+		results = append(results, newAPIKeyRepresentation(key))
+	}
+
+	err = json.NewEncoder(rw).Encode(results)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) getAPIKey(rw http.ResponseWriter, request *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	store, ok := h.getAPIKeyStore(rw, request)
+	if !ok {
+		return
+	}
+
+	key, ok := findAPIKey(rw, request, store)
+	if !ok {
+		return
+	}
+
+	err := json.NewEncoder(rw).Encode(newAPIKeyRepresentation(*key))
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// createAPIKey creates a key, with a generated value if the request does not specify one.
+// Its value is only disclosed in the response.
+func (h Handler) createAPIKey(rw http.ResponseWriter, request *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	store, ok := h.getAPIKeyStore(rw, request)
+	if !ok {
+		return
+	}
+
+	var key apikey.Key
+	if err := json.NewDecoder(request.Body).Decode(&key); err != nil {
+		writeError(rw, fmt.Sprintf("invalid API key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if key.Value == "" {
+		value, err := apikey.GenerateValue()
+		if err != nil {
+			log.FromContext(request.Context()).Error(err)
+			writeError(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		key.Value = value
+	}
+
+	if err := apikey.ValidValue(key.Value); err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err := store.Get(key.Value)
+	if err == nil {
+		writeError(rw, "API key already exists", http.StatusConflict)
+		return
+	}
+	if !errors.Is(err, apikey.ErrKeyNotFound) {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = store.Put(key); err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(rw).Encode(createdAPIKeyRepresentation{
+		apiKeyRepresentation: newAPIKeyRepresentation(key),
+		Key:                  key.Value,
+	})
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+	}
+}
+
+// updateAPIKey replaces the tenant, plan, and metadata of the key of the request path.
+// The value of a key cannot be changed.
+func (h Handler) updateAPIKey(rw http.ResponseWriter, request *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	store, ok := h.getAPIKeyStore(rw, request)
+	if !ok {
+		return
+	}
+
+	var update apikey.Key
+	if err := json.NewDecoder(request.Body).Decode(&update); err != nil {
+		writeError(rw, fmt.Sprintf("invalid API key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if update.Value != "" {
+		writeError(rw, "the value of an API key cannot be changed", http.StatusBadRequest)
+		return
+	}
+
+	key, ok := findAPIKey(rw, request, store)
+	if !ok {
+		return
+	}
+
+	update.Value = key.Value
+
+	if err := store.Put(update); err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err := json.NewEncoder(rw).Encode(newAPIKeyRepresentation(update))
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) deleteAPIKey(rw http.ResponseWriter, request *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	store, ok := h.getAPIKeyStore(rw, request)
+	if !ok {
+		return
+	}
+
+	key, ok := findAPIKey(rw, request, store)
+	if !ok {
+		return
+	}
+
+	err := store.Delete(key.Value)
+	if errors.Is(err, apikey.ErrKeyNotFound) {
+		writeError(rw, fmt.Sprintf("API key not found: %s", key.ID()), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
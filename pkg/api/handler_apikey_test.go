@@ -0,0 +1,273 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/middlewares/apikey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_APIKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik_apikey")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	rtConf := &runtime.Configuration{
+		Middlewares: map[string]*runtime.MiddlewareInfo{
+			"keys@myprovider": {
+				Middleware: &dynamic.Middleware{
+					APIKey: &dynamic.APIKey{File: &dynamic.APIKeyFile{Filename: filepath.Join(dir, "keys.json")}},
+				},
+			},
+			"auth@myprovider": {
+				Middleware: &dynamic.Middleware{
+					BasicAuth: &dynamic.BasicAuth{Users: []string{"admin:admin"}},
+				},
+			},
+		},
+	}
+
+	handler := NewBuilder(static.Configuration{API: &static.API{KeyManagement: true}, Global: &static.Global{}}, nil, nil, nil, nil, nil, nil, nil)(rtConf)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	fooID := (&apikey.Key{Value: "foo"}).ID()
+	barID := (&apikey.Key{Value: "bar"}).ID()
+
+	testCases := []struct {
+		desc           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "no keys",
+			method:         http.MethodGet,
+			path:           "/api/http/middlewares/keys@myprovider/keys",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			desc:           "create a key",
+			method:         http.MethodPost,
+			path:           "/api/http/middlewares/keys@myprovider/keys",
+			body:           `{"key":"foo","tenant":"acme","plan":"gold"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":"` + fooID + `","key":"foo","tenant":"acme","plan":"gold"}`,
+		},
+		{
+			desc:           "create an existing key",
+			method:         http.MethodPost,
+			path:           "/api/http/middlewares/keys@myprovider/keys",
+			body:           `{"key":"foo"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			desc:           "create an invalid key",
+			method:         http.MethodPost,
+			path:           "/api/http/middlewares/keys@myprovider/keys",
+			body:           `{"key":"foo bar"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "create another key",
+			method:         http.MethodPost,
+			path:           "/api/http/middlewares/keys@myprovider/keys",
+			body:           `{"key":"bar"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":"` + barID + `","key":"bar"}`,
+		},
+		{
+			desc:           "update a key",
+			method:         http.MethodPut,
+			path:           "/api/http/middlewares/keys@myprovider/keys/" + barID,
+			body:           `{"tenant":"acme","plan":"silver"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"` + barID + `","tenant":"acme","plan":"silver"}`,
+		},
+		{
+			desc:           "update the value of a key",
+			method:         http.MethodPut,
+			path:           "/api/http/middlewares/keys@myprovider/keys/" + barID,
+			body:           `{"key":"foo"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "update an unknown key",
+			method:         http.MethodPut,
+			path:           "/api/http/middlewares/keys@myprovider/keys/baz",
+			body:           `{"tenant":"acme"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "get a key",
+			method:         http.MethodGet,
+			path:           "/api/http/middlewares/keys@myprovider/keys/" + fooID,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"` + fooID + `","tenant":"acme","plan":"gold"}`,
+		},
+		{
+			desc:           "get a key by value",
+			method:         http.MethodGet,
+			path:           "/api/http/middlewares/keys@myprovider/keys/foo",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "list the keys",
+			method:         http.MethodGet,
+			path:           "/api/http/middlewares/keys@myprovider/keys",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":"` + barID + `","tenant":"acme","plan":"silver"},{"id":"` + fooID + `","tenant":"acme","plan":"gold"}]`,
+		},
+		{
+			desc:           "delete a key",
+			method:         http.MethodDelete,
+			path:           "/api/http/middlewares/keys@myprovider/keys/" + fooID,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			desc:           "get a deleted key",
+			method:         http.MethodGet,
+			path:           "/api/http/middlewares/keys@myprovider/keys/" + fooID,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "delete a deleted key",
+			method:         http.MethodDelete,
+			path:           "/api/http/middlewares/keys@myprovider/keys/" + fooID,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "not an APIKey middleware",
+			method:         http.MethodGet,
+			path:           "/api/http/middlewares/auth@myprovider/keys",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "unknown middleware",
+			method:         http.MethodGet,
+			path:           "/api/http/middlewares/foo@myprovider/keys",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	// The test cases depend on the keys created by the previous ones, so they cannot run in parallel.
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, server.URL+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			contents, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			require.Equal(t, test.expectedStatus, resp.StatusCode, string(contents))
+
+			if test.expectedBody != "" {
+				assert.JSONEq(t, test.expectedBody, string(contents))
+			}
+		})
+	}
+}
+
+func TestHandler_APIKeys_generatedKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik_apikey")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	rtConf := &runtime.Configuration{
+		Middlewares: map[string]*runtime.MiddlewareInfo{
+			"generated@myprovider": {
+				Middleware: &dynamic.Middleware{
+					APIKey: &dynamic.APIKey{File: &dynamic.APIKeyFile{Filename: filepath.Join(dir, "keys.yaml")}},
+				},
+			},
+		},
+	}
+
+	handler := NewBuilder(static.Configuration{API: &static.API{KeyManagement: true}, Global: &static.Global{}}, nil, nil, nil, nil, nil, nil, nil)(rtConf)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/http/middlewares/generated@myprovider/keys", strings.NewReader(`{"tenant":"acme"}`))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	var key struct {
+		Key    string `json:"key"`
+		Tenant string `json:"tenant"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &key))
+
+	assert.Len(t, key.Key, 32)
+	assert.Equal(t, "acme", key.Tenant)
+
+	// The value of the key is only disclosed on creation.
+	req = httptest.NewRequest(http.MethodGet, "/api/http/middlewares/generated@myprovider/keys", nil)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), key.Key)
+}
+
+func TestHandler_APIKeys_disabled(t *testing.T) {
+	rtConf := &runtime.Configuration{
+		Middlewares: map[string]*runtime.MiddlewareInfo{
+			"keys@myprovider": {
+				Middleware: &dynamic.Middleware{
+					APIKey: &dynamic.APIKey{File: &dynamic.APIKeyFile{Filename: "keys.json"}},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc string
+		api  *static.API
+	}{
+		{
+			desc: "not enabled",
+			api:  &static.API{},
+		},
+		{
+			desc: "insecure mode",
+			api:  &static.API{Insecure: true, KeyManagement: true},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: test.api, Global: &static.Global{}}, nil, nil, nil, nil, nil, nil, nil)(rtConf)
+
+			for _, method := range []string{http.MethodGet, http.MethodPost} {
+				req := httptest.NewRequest(method, "/api/http/middlewares/keys@myprovider/keys", strings.NewReader(`{}`))
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			}
+		})
+	}
+}
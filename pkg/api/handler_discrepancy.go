@@ -0,0 +1,287 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// Kinds of the configuration elements.
+const (
+	kindHTTPRouter     = "http.router"
+	kindHTTPMiddleware = "http.middleware"
+	kindHTTPService    = "http.service"
+	kindTCPRouter      = "tcp.router"
+	kindTCPService     = "tcp.service"
+	kindUDPRouter      = "udp.router"
+	kindUDPService     = "udp.service"
+	kindEntryPoint     = "entryPoint"
+	kindTLSOptions     = "tls.options"
+	kindCertResolver   = "certResolver"
+)
+
+// Reasons of the discrepancies.
+const (
+	// reasonMissingReference is used when an element references an element which is not defined.
+	reasonMissingReference = "missingReference"
+	// reasonDisabled is used when an element is not applied because of errors.
+	reasonDisabled = "disabled"
+	// reasonWarning is used when an element is only partially applied because of errors.
+	reasonWarning = "warning"
+)
+
+type referenceRepresentation struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// discrepancyRepresentation describes a difference between the configuration declared by a provider,
+// and the configuration actually applied.
+type discrepancyRepresentation struct {
+	Kind      string                   `json:"kind"`
+	Name      string                   `json:"name"`
+	Provider  string                   `json:"provider,omitempty"`
+	Status    string                   `json:"status"`
+	Reason    string                   `json:"reason"`
+	Reference *referenceRepresentation `json:"reference,omitempty"`
+	Errors    []string                 `json:"errors,omitempty"`
+}
+
+func (h Handler) getDiscrepancies(rw http.ResponseWriter, request *http.Request) {
+	results := h.findDiscrepancies()
+
+	reason := request.URL.Query().Get("reason")
+	if reason != "" {
+		filtered := make([]discrepancyRepresentation, 0, len(results))
+		for _, d := range results {
+			if strings.EqualFold(d.Reason, reason) {
+				filtered = append(filtered, d)
+			}
+		}
+		results = filtered
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// findDiscrepancies returns the discrepancies of the runtime configuration, sorted by kind, name, and reason.
+func (h Handler) findDiscrepancies() []discrepancyRepresentation {
+	f := discrepancyFinder{handler: h, results: make([]discrepancyRepresentation, 0)}
+	rtConf := h.runtimeConfiguration
+
+	for name, rt := range rtConf.Routers {
+		e := f.element(kindHTTPRouter, name, rt.Status, rt.Err)
+		for _, ep := range rt.EntryPoints {
+			e.checkEntryPoint(ep)
+		}
+		for _, middleware := range rt.Middlewares {
+			_, ok := rtConf.Middlewares[e.qualify(middleware)]
+			e.checkReference(kindHTTPMiddleware, e.qualify(middleware), ok)
+		}
+		_, ok := rtConf.Services[e.qualify(rt.Service)]
+		e.checkReference(kindHTTPService, e.qualify(rt.Service), ok)
+		if rt.TLS != nil {
+			e.checkTLS(rt.TLS.Options, append([]string{rt.TLS.CertResolver}, rt.TLS.FallbackCertResolvers...)...)
+		}
+	}
+
+	for name, mi := range rtConf.Middlewares {
+		e := f.element(kindHTTPMiddleware, name, mi.Status, mi.Err)
+		var middlewares []string
+		if mi.Chain != nil {
+			middlewares = append(middlewares, mi.Chain.Middlewares...)
+		}
+		if mi.Shadow != nil {
+			middlewares = append(middlewares, mi.Shadow.Middlewares...)
+		}
+		for _, middleware := range middlewares {
+			_, ok := rtConf.Middlewares[e.qualify(middleware)]
+			e.checkReference(kindHTTPMiddleware, e.qualify(middleware), ok)
+		}
+		if mi.Errors != nil {
+			_, ok := rtConf.Services[e.qualify(mi.Errors.Service)]
+			e.checkReference(kindHTTPService, e.qualify(mi.Errors.Service), ok)
+		}
+	}
+
+	for name, si := range rtConf.Services {
+		e := f.element(kindHTTPService, name, si.Status, si.Err)
+		var services []string
+		if si.Weighted != nil {
+			for _, s := range si.Weighted.Services {
+				services = append(services, s.Name)
+			}
+		}
+		if si.Mirroring != nil {
+			services = append(services, si.Mirroring.Service)
+			for _, m := range si.Mirroring.Mirrors {
+				services = append(services, m.Name)
+			}
+		}
+		if si.Mapped != nil && si.Mapped.Fallback != "" {
+			services = append(services, si.Mapped.Fallback)
+		}
+		for _, service := range services {
+			_, ok := rtConf.Services[e.qualify(service)]
+			e.checkReference(kindHTTPService, e.qualify(service), ok)
+		}
+	}
+
+	for name, rt := range rtConf.TCPRouters {
+		e := f.element(kindTCPRouter, name, rt.Status, rt.Err)
+		for _, ep := range rt.EntryPoints {
+			e.checkEntryPoint(ep)
+		}
+		_, ok := rtConf.TCPServices[e.qualify(rt.Service)]
+		e.checkReference(kindTCPService, e.qualify(rt.Service), ok)
+		if rt.TLS != nil && !rt.TLS.Passthrough {
+			e.checkTLS(rt.TLS.Options, rt.TLS.CertResolver)
+		}
+	}
+
+	for name, si := range rtConf.TCPServices {
+		e := f.element(kindTCPService, name, si.Status, si.Err)
+		if si.Weighted != nil {
+			for _, s := range si.Weighted.Services {
+				_, ok := rtConf.TCPServices[e.qualify(s.Name)]
+				e.checkReference(kindTCPService, e.qualify(s.Name), ok)
+			}
+		}
+	}
+
+	for name, rt := range rtConf.UDPRouters {
+		e := f.element(kindUDPRouter, name, rt.Status, rt.Err)
+		for _, ep := range rt.EntryPoints {
+			e.checkEntryPoint(ep)
+		}
+		_, ok := rtConf.UDPServices[e.qualify(rt.Service)]
+		e.checkReference(kindUDPService, e.qualify(rt.Service), ok)
+	}
+
+	for name, si := range rtConf.UDPServices {
+		e := f.element(kindUDPService, name, si.Status, si.Err)
+		if si.Weighted != nil {
+			for _, s := range si.Weighted.Services {
+				_, ok := rtConf.UDPServices[e.qualify(s.Name)]
+				e.checkReference(kindUDPService, e.qualify(s.Name), ok)
+			}
+		}
+	}
+
+	sort.SliceStable(f.results, func(i, j int) bool {
+		a, b := f.results[i], f.results[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Reason != b.Reason {
+			return a.Reason < b.Reason
+		}
+		if a.Reference != nil && b.Reference != nil {
+			return a.Reference.Kind+a.Reference.Name < b.Reference.Kind+b.Reference.Name
+		}
+		return false
+	})
+
+	return f.results
+}
+
+type discrepancyFinder struct {
+	handler Handler
+	results []discrepancyRepresentation
+}
+
+// element reports the discrepancy of an element in error,
+// and returns the checker of its references.
+func (f *discrepancyFinder) element(kind, name, status string, errs []string) elementChecker {
+	e := elementChecker{finder: f, kind: kind, name: name, provider: getProviderName(name), status: status}
+
+	switch status {
+	case runtime.StatusDisabled:
+		e.report(reasonDisabled, nil, errs)
+	case runtime.StatusWarning:
+		e.report(reasonWarning, nil, errs)
+	}
+
+	return e
+}
+
+type elementChecker struct {
+	finder   *discrepancyFinder
+	kind     string
+	name     string
+	provider string
+	status   string
+}
+
+func (e elementChecker) report(reason string, reference *referenceRepresentation, errs []string) {
+	e.finder.results = append(e.finder.results, discrepancyRepresentation{
+		Kind:      e.kind,
+		Name:      e.name,
+		Provider:  e.provider,
+		Status:    e.status,
+		Reason:    reason,
+		Reference: reference,
+		Errors:    errs,
+	})
+}
+
+// qualify returns the qualified name of an element referenced by this element.
+func (e elementChecker) qualify(name string) string {
+	if name == "" || strings.Contains(name, "@") {
+		return name
+	}
+	return name + "@" + e.provider
+}
+
+func (e elementChecker) checkReference(kind, name string, found bool) {
+	if name == "" || found {
+		return
+	}
+	e.report(reasonMissingReference, &referenceRepresentation{Kind: kind, Name: name}, nil)
+}
+
+func (e elementChecker) checkEntryPoint(name string) {
+	_, ok := e.finder.handler.staticConfig.EntryPoints[name]
+	e.checkReference(kindEntryPoint, name, ok)
+}
+
+func (e elementChecker) checkTLS(options string, certResolvers ...string) {
+	if tlsConf := e.finder.handler.runtimeConfiguration.TLS; tlsConf != nil {
+		name := "default"
+		if options != "" && options != "default" {
+			name = e.qualify(options)
+		}
+
+		_, ok := tlsConf.Options[name]
+		e.checkReference(kindTLSOptions, name, ok)
+	}
+
+	for _, certResolver := range certResolvers {
+		if certResolver != "" {
+			_, ok := e.finder.handler.staticConfig.CertificatesResolvers[certResolver]
+			e.checkReference(kindCertResolver, certResolver, ok)
+		}
+	}
+}
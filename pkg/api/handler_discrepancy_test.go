@@ -0,0 +1,201 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Discrepancies(t *testing.T) {
+	type expected struct {
+		statusCode int
+		nextPage   string
+		jsonFile   string
+	}
+
+	conf := runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"foo@myprovider": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web", "websecure"},
+					Service:     "foo-service",
+					Rule:        "Host(`foo.bar`)",
+					Middlewares: []string{"auth", "missing-middleware"},
+					TLS:         &dynamic.RouterTLSConfig{Options: "modern", CertResolver: "le"},
+				},
+				Err:    []string{`entryPoint "websecure" doesn't exist`},
+				Status: runtime.StatusWarning,
+			},
+			"bar@myprovider": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Service:     "missing-service",
+					Rule:        "Host(`bar.bar`)",
+				},
+				Err:    []string{"the service \"missing-service@myprovider\" does not exist"},
+				Status: runtime.StatusDisabled,
+			},
+			"ok@myprovider": {
+				Router: &dynamic.Router{
+					EntryPoints: []string{"web"},
+					Service:     "api@internal",
+					Rule:        "Host(`ok.bar`)",
+				},
+				Status: runtime.StatusEnabled,
+			},
+		},
+		Middlewares: map[string]*runtime.MiddlewareInfo{
+			"auth@myprovider": {
+				Middleware: &dynamic.Middleware{
+					Chain: &dynamic.Chain{Middlewares: []string{"strip@otherprovider"}},
+				},
+				Status: runtime.StatusEnabled,
+			},
+		},
+		Services: map[string]*runtime.ServiceInfo{
+			"foo-service@myprovider": {
+				Service: &dynamic.Service{
+					LoadBalancer: &dynamic.ServersLoadBalancer{
+						Servers: []dynamic.Server{{URL: "http://127.0.0.1"}},
+					},
+				},
+				Status: runtime.StatusEnabled,
+			},
+			"api@internal": {
+				Service: &dynamic.Service{},
+				Status:  runtime.StatusEnabled,
+			},
+		},
+		TCPRouters: map[string]*runtime.TCPRouterInfo{
+			"tcpfoo@myprovider": {
+				TCPRouter: &dynamic.TCPRouter{
+					EntryPoints: []string{"web"},
+					Service:     "tcpfoo-service",
+					Rule:        "HostSNI(`foo.bar`)",
+					TLS:         &dynamic.RouterTCPTLSConfig{},
+				},
+				Err:    []string{"the service \"tcpfoo-service@myprovider\" does not exist"},
+				Status: runtime.StatusDisabled,
+			},
+		},
+		UDPServices: map[string]*runtime.UDPServiceInfo{
+			"udpfoo-service@myprovider": {
+				UDPService: &dynamic.UDPService{
+					Weighted: &dynamic.UDPWeightedRoundRobin{
+						Services: []dynamic.UDPWRRService{{Name: "udpbar-service"}},
+					},
+				},
+				Status: runtime.StatusEnabled,
+			},
+		},
+		TLS: &dynamic.TLSConfiguration{
+			Options: map[string]tls.Options{
+				"default": {},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		path     string
+		conf     runtime.Configuration
+		expected expected
+	}{
+		{
+			desc: "no discrepancies",
+			path: "/api/discrepancies",
+			conf: runtime.Configuration{},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/discrepancies-empty.json",
+			},
+		},
+		{
+			desc: "all discrepancies",
+			path: "/api/discrepancies",
+			conf: conf,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/discrepancies.json",
+			},
+		},
+		{
+			desc: "discrepancies filtered by reason",
+			path: "/api/discrepancies?reason=disabled",
+			conf: conf,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/discrepancies-filtered-reason.json",
+			},
+		},
+		{
+			desc: "one page of discrepancies",
+			path: "/api/discrepancies?page=2&per_page=1",
+			conf: conf,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "3",
+				jsonFile:   "testdata/discrepancies-page2.json",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			staticConfig := static.Configuration{
+				API:         &static.API{},
+				Global:      &static.Global{},
+				EntryPoints: static.EntryPoints{"web": {}},
+			}
+
+			handler := New(staticConfig, &test.conf)
+			server := httptest.NewServer(handler.createRouter())
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected.nextPage, resp.Header.Get(nextPageHeader))
+
+			require.Equal(t, test.expected.statusCode, resp.StatusCode)
+
+			assert.Equal(t, resp.Header.Get("Content-Type"), "application/json")
+
+			contents, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			if *updateExpected {
+				var results interface{}
+				err := json.Unmarshal(contents, &results)
+				require.NoError(t, err)
+
+				newJSON, err := json.MarshalIndent(results, "", "\t")
+				require.NoError(t, err)
+
+				err = ioutil.WriteFile(test.expected.jsonFile, newJSON, 0644)
+				require.NoError(t, err)
+			}
+
+			data, err := ioutil.ReadFile(test.expected.jsonFile)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(contents))
+		})
+	}
+}
@@ -70,3 +70,57 @@ func (h Handler) getEntryPoint(rw http.ResponseWriter, request *http.Request) {
 		writeError(rw, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// ConnectionTables exposes the active connections of the entry points, by client IP.
+type ConnectionTables interface {
+	GetConnectionsByIP(entryPointName string) (map[string]int, bool)
+}
+
+type connectionsRepresentation struct {
+	IP          string `json:"ip"`
+	Connections int    `json:"connections"`
+}
+
+// getEntryPointConnections lists the client IPs with active connections on an entry point,
+// the top talkers first.
+func (h Handler) getEntryPointConnections(rw http.ResponseWriter, request *http.Request) {
+	entryPointID := mux.Vars(request)["entryPointID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	var connections map[string]int
+	ok := false
+	if h.connectionTables != nil {
+		connections, ok = h.connectionTables.GetConnectionsByIP(entryPointID)
+	}
+	if !ok {
+		writeError(rw, fmt.Sprintf("entry point not found: %s", entryPointID), http.StatusNotFound)
+		return
+	}
+
+	results := make([]connectionsRepresentation, 0, len(connections))
+	for ip, count := range connections {
+		results = append(results, connectionsRepresentation{IP: ip, Connections: count})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Connections == results[j].Connections {
+			return results[i].IP < results[j].IP
+		}
+		return results[i].Connections > results[j].Connections
+	})
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -247,3 +247,125 @@ func generateEntryPoints(nb int) map[string]*static.EntryPoint {
 
 	return eps
 }
+
+type connectionTablesMock map[string]map[string]int
+
+func (m connectionTablesMock) GetConnectionsByIP(entryPointName string) (map[string]int, bool) {
+	connections, ok := m[entryPointName]
+	return connections, ok
+}
+
+func TestHandler_EntryPointConnections(t *testing.T) {
+	type expected struct {
+		statusCode int
+		nextPage   string
+		jsonFile   string
+	}
+
+	connectionTables := connectionTablesMock{
+		"web": {
+			"10.0.0.1": 2,
+			"10.0.0.2": 12,
+			"10.0.0.3": 2,
+			"10.0.0.4": 1,
+		},
+		"websecure": {},
+	}
+
+	testCases := []struct {
+		desc             string
+		path             string
+		connectionTables ConnectionTables
+		expected         expected
+	}{
+		{
+			desc:             "connections of an entry point",
+			path:             "/api/entrypoints/web/connections",
+			connectionTables: connectionTables,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/entrypoint-connections.json",
+			},
+		},
+		{
+			desc:             "connections of an entry point, page 2",
+			path:             "/api/entrypoints/web/connections?page=2&per_page=2",
+			connectionTables: connectionTables,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/entrypoint-connections-page2.json",
+			},
+		},
+		{
+			desc:             "no connections",
+			path:             "/api/entrypoints/websecure/connections",
+			connectionTables: connectionTables,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/entrypoint-connections-empty.json",
+			},
+		},
+		{
+			desc:             "entry point not found",
+			path:             "/api/entrypoints/foo/connections",
+			connectionTables: connectionTables,
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			desc: "no connection tables",
+			path: "/api/entrypoints/web/connections",
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, nil, test.connectionTables, nil, nil, nil, nil, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			require.Equal(t, test.expected.statusCode, resp.StatusCode)
+
+			assert.Equal(t, test.expected.nextPage, resp.Header.Get(nextPageHeader))
+
+			if test.expected.jsonFile == "" {
+				return
+			}
+
+			assert.Equal(t, resp.Header.Get("Content-Type"), "application/json")
+			contents, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			if *updateExpected {
+				var results interface{}
+				err := json.Unmarshal(contents, &results)
+				require.NoError(t, err)
+
+				newJSON, err := json.MarshalIndent(results, "", "\t")
+				require.NoError(t, err)
+
+				err = ioutil.WriteFile(test.expected.jsonFile, newJSON, 0644)
+				require.NoError(t, err)
+			}
+
+			data, err := ioutil.ReadFile(test.expected.jsonFile)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(contents))
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares/pathstats"
 	"github.com/gorilla/mux"
 )
 
@@ -35,9 +36,9 @@ type serviceRepresentation struct {
 	Type         string            `json:"type,omitempty"`
 }
 
-func newServiceRepresentation(name string, si *runtime.ServiceInfo) serviceRepresentation {
+func (h Handler) newServiceRepresentation(name string, si *runtime.ServiceInfo) serviceRepresentation {
 	return serviceRepresentation{
-		ServiceInfo:  si,
+		ServiceInfo:  h.concealService(si),
 		Name:         name,
 		Provider:     getProviderName(name),
 		ServerStatus: si.GetAllStatus(),
@@ -52,9 +53,9 @@ type middlewareRepresentation struct {
 	Type     string `json:"type,omitempty"`
 }
 
-func newMiddlewareRepresentation(name string, mi *runtime.MiddlewareInfo) middlewareRepresentation {
+func (h Handler) newMiddlewareRepresentation(name string, mi *runtime.MiddlewareInfo) middlewareRepresentation {
 	return middlewareRepresentation{
-		MiddlewareInfo: mi,
+		MiddlewareInfo: h.concealMiddleware(mi),
 		Name:           name,
 		Provider:       getProviderName(name),
 		Type:           strings.ToLower(extractType(mi.Middleware)),
@@ -113,6 +114,47 @@ func (h Handler) getRouter(rw http.ResponseWriter, request *http.Request) {
 	}
 }
 
+// PathStatistics provides the statistics of the paths using the most bandwidth on the routers.
+type PathStatistics interface {
+	TopPaths(routerName string) ([]pathstats.PathStats, bool)
+}
+
+// getRouterPaths lists the paths using the most bandwidth on a router, the most used first.
+func (h Handler) getRouterPaths(rw http.ResponseWriter, request *http.Request) {
+	routerID := mux.Vars(request)["routerID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if h.pathStatistics == nil || h.staticConfig.API == nil || h.staticConfig.API.PathStatistics == nil {
+		writeError(rw, "path statistics are disabled", http.StatusNotFound)
+		return
+	}
+
+	if _, ok := h.runtimeConfiguration.Routers[routerID]; !ok {
+		writeError(rw, fmt.Sprintf("router not found: %s", routerID), http.StatusNotFound)
+		return
+	}
+
+	results, _ := h.pathStatistics.TopPaths(routerID)
+	if results == nil {
+		results = make([]pathstats.PathStats, 0)
+	}
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (h Handler) getServices(rw http.ResponseWriter, request *http.Request) {
 	results := make([]serviceRepresentation, 0, len(h.runtimeConfiguration.Services))
 
@@ -120,7 +162,7 @@ func (h Handler) getServices(rw http.ResponseWriter, request *http.Request) {
 
 	for name, si := range h.runtimeConfiguration.Services {
 		if keepService(name, si, criterion) {
-			results = append(results, newServiceRepresentation(name, si))
+			results = append(results, h.newServiceRepresentation(name, si))
 		}
 	}
 
@@ -156,7 +198,7 @@ func (h Handler) getService(rw http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	result := newServiceRepresentation(serviceID, service)
+	result := h.newServiceRepresentation(serviceID, service)
 
 	err := json.NewEncoder(rw).Encode(result)
 	if err != nil {
@@ -172,7 +214,7 @@ func (h Handler) getMiddlewares(rw http.ResponseWriter, request *http.Request) {
 
 	for name, mi := range h.runtimeConfiguration.Middlewares {
 		if keepMiddleware(name, mi, criterion) {
-			results = append(results, newMiddlewareRepresentation(name, mi))
+			results = append(results, h.newMiddlewareRepresentation(name, mi))
 		}
 	}
 
@@ -208,7 +250,7 @@ func (h Handler) getMiddleware(rw http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	result := newMiddlewareRepresentation(middlewareID, middleware)
+	result := h.newMiddlewareRepresentation(middlewareID, middleware)
 
 	err := json.NewEncoder(rw).Encode(result)
 	if err != nil {
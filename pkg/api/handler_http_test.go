@@ -13,6 +13,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/middlewares/pathstats"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -864,3 +865,120 @@ func generateHTTPRouters(nbRouters int) map[string]*runtime.RouterInfo {
 	}
 	return routers
 }
+
+type pathStatisticsMock map[string][]pathstats.PathStats
+
+func (m pathStatisticsMock) TopPaths(routerName string) ([]pathstats.PathStats, bool) {
+	stats, ok := m[routerName]
+	return stats, ok
+}
+
+func TestHandler_RouterPaths(t *testing.T) {
+	type expected struct {
+		statusCode int
+		nextPage   string
+		jsonFile   string
+	}
+
+	pathStatistics := pathStatisticsMock{
+		"foo@myprovider": {
+			{Path: "/download", Requests: 10, RequestBytes: 0, ResponseBytes: 10000},
+			{Path: "/upload", Requests: 2, RequestBytes: 5000, ResponseBytes: 20, Error: 10},
+		},
+	}
+
+	testCases := []struct {
+		desc           string
+		path           string
+		pathStatistics *static.PathStatistics
+		expected       expected
+	}{
+		{
+			desc:           "paths of a router",
+			path:           "/api/http/routers/foo@myprovider/paths",
+			pathStatistics: &static.PathStatistics{MaxPaths: 10},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/router-paths.json",
+			},
+		},
+		{
+			desc:           "paths of a router without traffic",
+			path:           "/api/http/routers/bar@myprovider/paths",
+			pathStatistics: &static.PathStatistics{MaxPaths: 10},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/router-paths-empty.json",
+			},
+		},
+		{
+			desc:           "paths of a missing router",
+			path:           "/api/http/routers/baz@myprovider/paths",
+			pathStatistics: &static.PathStatistics{MaxPaths: 10},
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			desc: "path statistics disabled",
+			path: "/api/http/routers/foo@myprovider/paths",
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rtConf := &runtime.Configuration{
+				Routers: map[string]*runtime.RouterInfo{
+					"foo@myprovider": {Router: &dynamic.Router{Service: "foo-service@myprovider"}},
+					"bar@myprovider": {Router: &dynamic.Router{Service: "foo-service@myprovider"}},
+				},
+			}
+
+			staticConfig := static.Configuration{API: &static.API{PathStatistics: test.pathStatistics}, Global: &static.Global{}}
+			handler := NewBuilder(staticConfig, nil, nil, pathStatistics, nil, nil, nil, nil)(rtConf)
+			server := httptest.NewServer(handler)
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			require.Equal(t, test.expected.statusCode, resp.StatusCode)
+
+			if test.expected.jsonFile == "" {
+				return
+			}
+
+			assert.Equal(t, test.expected.nextPage, resp.Header.Get(nextPageHeader))
+			assert.Equal(t, resp.Header.Get("Content-Type"), "application/json")
+
+			contents, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			if *updateExpected {
+				var results interface{}
+				err := json.Unmarshal(contents, &results)
+				require.NoError(t, err)
+
+				newJSON, err := json.MarshalIndent(results, "", "\t")
+				require.NoError(t, err)
+
+				err = ioutil.WriteFile(test.expected.jsonFile, newJSON, 0644)
+				require.NoError(t, err)
+			}
+
+			data, err := ioutil.ReadFile(test.expected.jsonFile)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(contents))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/gorilla/mux"
+)
+
+// resyncTimeout bounds the time waited for a provider to resync.
+const resyncTimeout = 30 * time.Second
+
+// ProviderResyncer resyncs the whole configuration of the providers.
+type ProviderResyncer interface {
+	Resync(ctx context.Context, providerName string) error
+}
+
+type resyncRepresentation struct {
+	Provider string `json:"provider"`
+	Duration string `json:"duration"`
+}
+
+// resyncProvider forces the provider to read again all its sources, to recover from missed events,
+// and reports the result once the resulting configuration has been sent.
+// It is refused when the API is exposed in insecure mode, as it is then not authenticated.
+func (h Handler) resyncProvider(rw http.ResponseWriter, request *http.Request) {
+	providerID := mux.Vars(request)["providerID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if h.staticConfig.API != nil && h.staticConfig.API.Insecure {
+		writeError(rw, "the resync of the providers is not available when the API is in insecure mode", http.StatusForbidden)
+		return
+	}
+
+	if h.providerResyncer == nil {
+		writeError(rw, fmt.Sprintf("provider not found, or not supporting resync: %s", providerID), http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), resyncTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.providerResyncer.Resync(ctx, providerID)
+	switch {
+	case errors.Is(err, provider.ErrResyncNotSupported):
+		writeError(rw, fmt.Sprintf("provider not found, or not supporting resync: %s", providerID), http.StatusNotFound)
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(rw, fmt.Sprintf("timeout while resyncing the provider %s", providerID), http.StatusGatewayTimeout)
+		return
+	case err != nil:
+		log.FromContext(request.Context()).Errorf("Unable to resync the provider %s: %v", providerID, err)
+		writeError(rw, fmt.Sprintf("unable to resync the provider %s: %v", providerID, err), http.StatusBadGateway)
+		return
+	}
+
+	log.FromContext(request.Context()).Infof("Provider %s resynced", providerID)
+
+	result := resyncRepresentation{
+		Provider: providerID,
+		Duration: time.Since(start).String(),
+	}
+
+	err = json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProviderResyncer map[string]error
+
+func (r fakeProviderResyncer) Resync(_ context.Context, providerName string) error {
+	err, ok := r[providerName]
+	if !ok {
+		return provider.ErrResyncNotSupported
+	}
+
+	return err
+}
+
+func TestHandler_ProviderResync(t *testing.T) {
+	resyncer := fakeProviderResyncer{
+		"file":          nil,
+		"kubernetescrd": errors.New("timed out waiting for controller caches to sync"),
+		"docker":        context.DeadlineExceeded,
+	}
+
+	testCases := []struct {
+		desc       string
+		path       string
+		method     string
+		insecure   bool
+		resyncer   ProviderResyncer
+		statusCode int
+	}{
+		{
+			desc:       "resynced",
+			path:       "/api/providers/file/resync",
+			method:     http.MethodPost,
+			resyncer:   resyncer,
+			statusCode: http.StatusOK,
+		},
+		{
+			desc:       "not a POST request",
+			path:       "/api/providers/file/resync",
+			method:     http.MethodGet,
+			resyncer:   resyncer,
+			statusCode: http.StatusMethodNotAllowed,
+		},
+		{
+			desc:       "insecure API",
+			path:       "/api/providers/file/resync",
+			method:     http.MethodPost,
+			insecure:   true,
+			resyncer:   resyncer,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			desc:       "provider not supporting resync",
+			path:       "/api/providers/marathon/resync",
+			method:     http.MethodPost,
+			resyncer:   resyncer,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			desc:       "no provider resyncer",
+			path:       "/api/providers/file/resync",
+			method:     http.MethodPost,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			desc:       "resync failure",
+			path:       "/api/providers/kubernetescrd/resync",
+			method:     http.MethodPost,
+			resyncer:   resyncer,
+			statusCode: http.StatusBadGateway,
+		},
+		{
+			desc:       "resync timeout",
+			path:       "/api/providers/docker/resync",
+			method:     http.MethodPost,
+			resyncer:   resyncer,
+			statusCode: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			staticConfig := static.Configuration{API: &static.API{Insecure: test.insecure}, Global: &static.Global{}}
+			handler := NewBuilder(staticConfig, nil, nil, nil, nil, test.resyncer, nil, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			req, err := http.NewRequest(test.method, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			require.Equal(t, test.statusCode, resp.StatusCode)
+
+			if test.statusCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			var result resyncRepresentation
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			require.NoError(t, err)
+
+			assert.Equal(t, "file", result.Provider)
+			assert.NotEmpty(t, result.Duration)
+		})
+	}
+}
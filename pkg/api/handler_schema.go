@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/label"
+	"github.com/containous/traefik/v2/pkg/config/schema"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/gorilla/mux"
+)
+
+// getSchema returns the JSON Schema of the configuration flavor, generated on request,
+// as the schemas are seldom requested, and the dynamic ones are large.
+func (h Handler) getSchema(rw http.ResponseWriter, request *http.Request) {
+	schemaID := mux.Vars(request)["schemaID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	var result *schema.Schema
+	switch schemaID {
+	case "static":
+		result = schema.Generate(&static.Configuration{}, "Traefik static configuration")
+	case "dynamic":
+		result = schema.Generate(&dynamic.Configuration{}, "Traefik dynamic configuration")
+	case "labels":
+		result = label.Schema()
+	default:
+		result = h.providerSchemas[schemaID]
+	}
+
+	if result == nil {
+		writeError(rw, fmt.Sprintf("schema not found: %s", schemaID), http.StatusNotFound)
+		return
+	}
+
+	err := json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/schema"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Schemas(t *testing.T) {
+	providerSchemas := map[string]*schema.Schema{
+		"annotations": {Schema: schema.Version, Title: "Annotations", Type: "object"},
+	}
+
+	testCases := []struct {
+		desc          string
+		path          string
+		statusCode    int
+		expectedTitle string
+		expectedProps []string
+	}{
+		{
+			desc:          "static configuration",
+			path:          "/api/schemas/static",
+			statusCode:    http.StatusOK,
+			expectedTitle: "Traefik static configuration",
+			expectedProps: []string{"entryPoints", "providers", "certificatesResolvers"},
+		},
+		{
+			desc:          "dynamic configuration",
+			path:          "/api/schemas/dynamic",
+			statusCode:    http.StatusOK,
+			expectedTitle: "Traefik dynamic configuration",
+			expectedProps: []string{"http", "tcp", "udp", "tls"},
+		},
+		{
+			desc:          "dynamic configuration labels",
+			path:          "/api/schemas/labels",
+			statusCode:    http.StatusOK,
+			expectedTitle: "Traefik dynamic configuration labels",
+		},
+		{
+			desc:          "provider schema",
+			path:          "/api/schemas/annotations",
+			statusCode:    http.StatusOK,
+			expectedTitle: "Annotations",
+		},
+		{
+			desc:       "unknown schema",
+			path:       "/api/schemas/foo",
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, nil, nil, nil, nil, nil, providerSchemas, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, test.statusCode, resp.StatusCode)
+
+			if test.statusCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			var result map[string]interface{}
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			require.NoError(t, err)
+
+			assert.Equal(t, schema.Version, result["$schema"])
+			assert.Equal(t, test.expectedTitle, result["title"])
+
+			for _, name := range test.expectedProps {
+				assert.Contains(t, result["properties"], name)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/snapshot"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+func (h Handler) getSnapshot(rw http.ResponseWriter, request *http.Request) {
+	conf := h.dynamicConfiguration()
+	if h.secrets != nil {
+		conf = *conf.DeepCopy()
+		h.secrets.Conceal(&conf)
+	}
+
+	snap, err := snapshot.Build(conf)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := snapshot.Marshal(snap)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/yaml")
+
+	if _, err = rw.Write(data); err != nil {
+		log.FromContext(request.Context()).Error(err)
+	}
+}
+
+// dynamicConfiguration returns the merged dynamic configuration the runtime configuration was created from.
+func (h Handler) dynamicConfiguration() dynamic.Configuration {
+	conf := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers:     make(map[string]*dynamic.Router),
+			Middlewares: make(map[string]*dynamic.Middleware),
+			Services:    make(map[string]*dynamic.Service),
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers:  make(map[string]*dynamic.TCPRouter),
+			Services: make(map[string]*dynamic.TCPService),
+		},
+		UDP: &dynamic.UDPConfiguration{
+			Routers:  make(map[string]*dynamic.UDPRouter),
+			Services: make(map[string]*dynamic.UDPService),
+		},
+		TLS: h.runtimeConfiguration.TLS,
+	}
+
+	for name, rt := range h.runtimeConfiguration.Routers {
+		conf.HTTP.Routers[name] = rt.Router
+	}
+	for name, mi := range h.runtimeConfiguration.Middlewares {
+		conf.HTTP.Middlewares[name] = mi.Middleware
+	}
+	for name, si := range h.runtimeConfiguration.Services {
+		conf.HTTP.Services[name] = si.Service
+	}
+
+	for name, rt := range h.runtimeConfiguration.TCPRouters {
+		conf.TCP.Routers[name] = rt.TCPRouter
+	}
+	for name, si := range h.runtimeConfiguration.TCPServices {
+		conf.TCP.Services[name] = si.TCPService
+	}
+
+	for name, rt := range h.runtimeConfiguration.UDPRouters {
+		conf.UDP.Routers[name] = rt.UDPRouter
+	}
+	for name, si := range h.runtimeConfiguration.UDPServices {
+		conf.UDP.Services[name] = si.UDPService
+	}
+
+	return conf
+}
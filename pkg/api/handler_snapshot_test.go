@@ -0,0 +1,176 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestHandler_Snapshot(t *testing.T) {
+	rtConf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo@myprovider": {
+					EntryPoints: []string{"web"},
+					Service:     "foo-service",
+					Rule:        "Host(`foo.bar`)",
+					Middlewares: []string{"auth"},
+				},
+				"api@internal": {
+					EntryPoints: []string{"traefik"},
+					Service:     "api@internal",
+					Rule:        "PathPrefix(`/api`)",
+				},
+			},
+			Middlewares: map[string]*dynamic.Middleware{
+				"auth@myprovider": {
+					BasicAuth: &dynamic.BasicAuth{Users: []string{"admin:admin"}},
+				},
+			},
+			Services: map[string]*dynamic.Service{
+				"foo-service@myprovider": {
+					LoadBalancer: &dynamic.ServersLoadBalancer{
+						Servers: []dynamic.Server{{URL: "http://127.0.0.1"}},
+					},
+				},
+				"api@internal": {},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"tcpfoo@myprovider": {
+					EntryPoints: []string{"web"},
+					Service:     "tcpfoo-service",
+					Rule:        "HostSNI(`foo.bar`)",
+				},
+			},
+			Services: map[string]*dynamic.TCPService{
+				"tcpfoo-service@myprovider": {
+					LoadBalancer: &dynamic.TCPServersLoadBalancer{
+						Servers: []dynamic.TCPServer{{Address: "127.0.0.1:8080"}},
+					},
+				},
+			},
+		},
+	})
+
+	handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, rtConf)
+	server := httptest.NewServer(handler.createRouter())
+
+	resp, err := http.DefaultClient.Get(server.URL + "/api/snapshot")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/yaml", resp.Header.Get("Content-Type"))
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	err = resp.Body.Close()
+	require.NoError(t, err)
+
+	var snapshot dynamic.Configuration
+	err = yaml.Unmarshal(contents, &snapshot)
+	require.NoError(t, err)
+
+	expected := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo": {
+					EntryPoints: []string{"web"},
+					Service:     "foo-service",
+					Rule:        "Host(`foo.bar`)",
+					Middlewares: []string{"auth"},
+				},
+			},
+			Middlewares: map[string]*dynamic.Middleware{
+				"auth": {
+					BasicAuth: &dynamic.BasicAuth{Users: []string{"REDACTED"}},
+				},
+			},
+			Services: map[string]*dynamic.Service{
+				"foo-service": {
+					LoadBalancer: &dynamic.ServersLoadBalancer{
+						Servers: []dynamic.Server{{URL: "http://127.0.0.1"}},
+					},
+				},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"tcpfoo": {
+					EntryPoints: []string{"web"},
+					Service:     "tcpfoo-service",
+					Rule:        "HostSNI(`foo.bar`)",
+				},
+			},
+			Services: map[string]*dynamic.TCPService{
+				"tcpfoo-service": {
+					LoadBalancer: &dynamic.TCPServersLoadBalancer{
+						Servers: []dynamic.TCPServer{{Address: "127.0.0.1:8080"}},
+					},
+				},
+			},
+		},
+		UDP: &dynamic.UDPConfiguration{},
+		TLS: &dynamic.TLSConfiguration{},
+	}
+
+	assert.Equal(t, expected, snapshot)
+}
+
+// usersConcealer conceals the users of the basic auth middlewares by the secret reference they were resolved from.
+type usersConcealer struct{}
+
+func (usersConcealer) Conceal(element interface{}) {
+	switch e := element.(type) {
+	case *dynamic.Configuration:
+		for _, middleware := range e.HTTP.Middlewares {
+			usersConcealer{}.Conceal(middleware)
+		}
+	case *dynamic.Middleware:
+		if e.BasicAuth != nil {
+			e.BasicAuth.Users = dynamic.Users{"file:/run/secrets/users"}
+		}
+	}
+}
+
+func TestHandler_concealedSecrets(t *testing.T) {
+	rtConf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"auth@file": {
+					BasicAuth: &dynamic.BasicAuth{Users: []string{"admin:admin"}},
+				},
+			},
+		},
+	})
+
+	handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, nil, nil, nil, nil, nil, nil, usersConcealer{})(rtConf)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	for _, path := range []string{"/api/rawdata", "/api/snapshot", "/api/http/middlewares", "/api/http/middlewares/auth@file"} {
+		resp, err := http.DefaultClient.Get(server.URL + path)
+		require.NoError(t, err)
+
+		contents, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		assert.Contains(t, string(contents), "file:/run/secrets/users", path)
+		assert.NotContains(t, string(contents), "admin:admin", path)
+	}
+
+	// The runtime configuration itself is left untouched.
+	assert.Equal(t, dynamic.Users{"admin:admin"}, rtConf.Middlewares["auth@file"].BasicAuth.Users)
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/tls/certificate"
+	"github.com/gorilla/mux"
+)
+
+// TLSStores exposes the certificates of the TLS stores, and the failures of the TLS handshakes.
+type TLSStores interface {
+	CertificatesInfo() []traefiktls.CertificateInfo
+	ServedCertificate(storeName, serverName string, certType certificate.CertificateType) (traefiktls.ServedCertificate, bool)
+	HandshakeErrors() []traefiktls.HandshakeErrorsInfo
+}
+
+type servedCertificateRepresentation struct {
+	Store         string             `json:"store"`
+	ServerName    string             `json:"serverName"`
+	KeyType       string             `json:"keyType"`
+	SANs          string             `json:"sans,omitempty"`
+	Serial        string             `json:"serial,omitempty"`
+	NotAfter      time.Time          `json:"notAfter"`
+	MatchedDomain string             `json:"matchedDomain,omitempty"`
+	Default       bool               `json:"default,omitempty"`
+	SCT           *sctRepresentation `json:"sct,omitempty"`
+}
+
+type certificateRepresentation struct {
+	Store    string             `json:"store"`
+	SANs     string             `json:"sans"`
+	Serial   string             `json:"serial"`
+	NotAfter time.Time          `json:"notAfter"`
+	SCT      *sctRepresentation `json:"sct,omitempty"`
+}
+
+type sctRepresentation struct {
+	Compliant bool   `json:"compliant"`
+	Count     int    `json:"count"`
+	Required  int    `json:"required"`
+	Error     string `json:"error,omitempty"`
+}
+
+func newSCTRepresentation(status *traefiktls.SCTStatus) *sctRepresentation {
+	if status == nil {
+		return nil
+	}
+
+	return &sctRepresentation{
+		Compliant: status.Compliant(),
+		Count:     status.Count,
+		Required:  status.Required,
+		Error:     status.Error,
+	}
+}
+
+type handshakeErrorsRepresentation struct {
+	Reason  string                               `json:"reason"`
+	Count   uint64                               `json:"count"`
+	Samples []handshakeErrorSampleRepresentation `json:"samples"`
+}
+
+type handshakeErrorSampleRepresentation struct {
+	Time       time.Time `json:"time"`
+	ServerName string    `json:"serverName,omitempty"`
+	RemoteAddr string    `json:"remoteAddr,omitempty"`
+	Error      string    `json:"error"`
+}
+
+// getTLSStoreCertificate reports the certificate served by a TLS store to the server name of the sni query parameter,
+// for the clients supporting ECDSA certificates, or only RSA certificates with the keyType=RSA query parameter.
+func (h Handler) getTLSStoreCertificate(rw http.ResponseWriter, request *http.Request) {
+	storeID := mux.Vars(request)["storeID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	serverName := request.URL.Query().Get("sni")
+	if serverName == "" {
+		writeError(rw, "the sni query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	var certType certificate.CertificateType
+	switch keyType := request.URL.Query().Get("keyType"); keyType {
+	case "", certificate.EC.String():
+		certType = certificate.EC
+	case certificate.RSA.String():
+		certType = certificate.RSA
+	case certificate.Ed25519.String():
+		certType = certificate.Ed25519
+	default:
+		writeError(rw, fmt.Sprintf("invalid keyType: %s", keyType), http.StatusBadRequest)
+		return
+	}
+
+	var served traefiktls.ServedCertificate
+	ok := false
+	if h.tlsStores != nil {
+		served, ok = h.tlsStores.ServedCertificate(storeID, serverName, certType)
+	}
+	if !ok {
+		writeError(rw, fmt.Sprintf("TLS store not found: %s", storeID), http.StatusNotFound)
+		return
+	}
+
+	result := servedCertificateRepresentation{
+		Store:         served.Store,
+		ServerName:    serverName,
+		KeyType:       certType.String(),
+		SANs:          served.SANs,
+		Serial:        served.Serial,
+		NotAfter:      served.NotAfter,
+		MatchedDomain: served.MatchedDomain,
+		Default:       served.Default,
+		SCT:           newSCTRepresentation(served.SCT),
+	}
+
+	err := json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getTLSCertificates lists the certificates of all the TLS stores, along with their Signed Certificate Timestamps when they are checked,
+// or only the certificates not complying with the certificate transparency policy with the sctCompliant=false query parameter.
+func (h Handler) getTLSCertificates(rw http.ResponseWriter, request *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	var onlyNotCompliant bool
+	switch compliant := request.URL.Query().Get("sctCompliant"); compliant {
+	case "":
+	case "false":
+		onlyNotCompliant = true
+	default:
+		writeError(rw, fmt.Sprintf("invalid sctCompliant: %s", compliant), http.StatusBadRequest)
+		return
+	}
+
+	results := make([]certificateRepresentation, 0)
+	if h.tlsStores != nil {
+		for _, info := range h.tlsStores.CertificatesInfo() {
+			if onlyNotCompliant && (info.SCT == nil || info.SCT.Compliant()) {
+				continue
+			}
+
+			results = append(results, certificateRepresentation{
+				Store:    info.Store,
+				SANs:     info.SANs,
+				Serial:   info.Serial,
+				NotAfter: info.NotAfter,
+				SCT:      newSCTRepresentation(info.SCT),
+			})
+		}
+	}
+
+	err := json.NewEncoder(rw).Encode(results)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getTLSHandshakeErrors reports the failures of the TLS handshakes since the start, by reason,
+// along with the most recent failures of each reason, to debug the SniStrict and mTLS issues.
+func (h Handler) getTLSHandshakeErrors(rw http.ResponseWriter, request *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	results := make([]handshakeErrorsRepresentation, 0)
+	if h.tlsStores != nil {
+		for _, info := range h.tlsStores.HandshakeErrors() {
+			result := handshakeErrorsRepresentation{
+				Reason:  info.Reason,
+				Count:   info.Count,
+				Samples: make([]handshakeErrorSampleRepresentation, 0, len(info.Samples)),
+			}
+
+			for _, sample := range info.Samples {
+				result.Samples = append(result.Samples, handshakeErrorSampleRepresentation{
+					Time:       sample.Time,
+					ServerName: sample.ServerName,
+					RemoteAddr: sample.RemoteAddr,
+					Error:      sample.Error,
+				})
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	err := json.NewEncoder(rw).Encode(results)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,355 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/tls/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tlsStores serves the certificates of the TLS stores by store, and by key type.
+type tlsStores map[string]map[certificate.CertificateType]traefiktls.ServedCertificate
+
+func (s tlsStores) CertificatesInfo() []traefiktls.CertificateInfo {
+	return nil
+}
+
+func (s tlsStores) ServedCertificate(storeName, _ string, certType certificate.CertificateType) (traefiktls.ServedCertificate, bool) {
+	served, ok := s[storeName][certType]
+	return served, ok
+}
+
+func (s tlsStores) HandshakeErrors() []traefiktls.HandshakeErrorsInfo {
+	return nil
+}
+
+// handshakeErrors reports the TLS handshake failures, without any TLS store.
+type handshakeErrors []traefiktls.HandshakeErrorsInfo
+
+func (h handshakeErrors) CertificatesInfo() []traefiktls.CertificateInfo {
+	return nil
+}
+
+func (h handshakeErrors) ServedCertificate(_, _ string, _ certificate.CertificateType) (traefiktls.ServedCertificate, bool) {
+	return traefiktls.ServedCertificate{}, false
+}
+
+func (h handshakeErrors) HandshakeErrors() []traefiktls.HandshakeErrorsInfo {
+	return h
+}
+
+// certificatesInfo describes the certificates of the TLS stores, without any served certificate.
+type certificatesInfo []traefiktls.CertificateInfo
+
+func (c certificatesInfo) CertificatesInfo() []traefiktls.CertificateInfo {
+	return c
+}
+
+func (c certificatesInfo) ServedCertificate(_, _ string, _ certificate.CertificateType) (traefiktls.ServedCertificate, bool) {
+	return traefiktls.ServedCertificate{}, false
+}
+
+func (c certificatesInfo) HandshakeErrors() []traefiktls.HandshakeErrorsInfo {
+	return nil
+}
+
+func TestHandler_TLSStoreCertificate(t *testing.T) {
+	notAfter := time.Date(2084, time.January, 29, 16, 0, 0, 0, time.UTC)
+
+	stores := tlsStores{
+		"default": {
+			certificate.EC: {
+				CertificateInfo: traefiktls.CertificateInfo{Store: "default", SANs: "*.foo.bar", Serial: "2a", NotAfter: notAfter},
+				MatchedDomain:   "*.foo.bar",
+			},
+			certificate.RSA: {
+				CertificateInfo: traefiktls.CertificateInfo{Store: "default", SANs: "TRAEFIK DEFAULT CERT", Serial: "2b", NotAfter: notAfter},
+				Default:         true,
+			},
+		},
+	}
+
+	type expected struct {
+		statusCode  int
+		certificate *servedCertificateRepresentation
+	}
+
+	testCases := []struct {
+		desc      string
+		path      string
+		tlsStores TLSStores
+		expected  expected
+	}{
+		{
+			desc:      "certificate served to ECDSA clients",
+			path:      "/api/tls/stores/default/certificate?sni=www.foo.bar",
+			tlsStores: stores,
+			expected: expected{
+				statusCode: http.StatusOK,
+				certificate: &servedCertificateRepresentation{
+					Store:         "default",
+					ServerName:    "www.foo.bar",
+					KeyType:       "EC",
+					SANs:          "*.foo.bar",
+					Serial:        "2a",
+					NotAfter:      notAfter,
+					MatchedDomain: "*.foo.bar",
+				},
+			},
+		},
+		{
+			desc:      "default certificate served to RSA clients",
+			path:      "/api/tls/stores/default/certificate?sni=www.foo.bar&keyType=RSA",
+			tlsStores: stores,
+			expected: expected{
+				statusCode: http.StatusOK,
+				certificate: &servedCertificateRepresentation{
+					Store:      "default",
+					ServerName: "www.foo.bar",
+					KeyType:    "RSA",
+					SANs:       "TRAEFIK DEFAULT CERT",
+					Serial:     "2b",
+					NotAfter:   notAfter,
+					Default:    true,
+				},
+			},
+		},
+		{
+			desc:      "missing server name",
+			path:      "/api/tls/stores/default/certificate",
+			tlsStores: stores,
+			expected: expected{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			desc:      "invalid key type",
+			path:      "/api/tls/stores/default/certificate?sni=www.foo.bar&keyType=DSA",
+			tlsStores: stores,
+			expected: expected{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			desc:      "store not found",
+			path:      "/api/tls/stores/foo/certificate?sni=www.foo.bar",
+			tlsStores: stores,
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			desc: "no TLS stores",
+			path: "/api/tls/stores/default/certificate?sni=www.foo.bar",
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, nil, nil, nil, test.tlsStores, nil, nil, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			require.Equal(t, test.expected.statusCode, resp.StatusCode)
+
+			if test.expected.certificate == nil {
+				return
+			}
+
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			var result servedCertificateRepresentation
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			require.NoError(t, err)
+
+			assert.Equal(t, *test.expected.certificate, result)
+		})
+	}
+}
+
+func TestHandler_TLSCertificates(t *testing.T) {
+	notAfter := time.Date(2084, time.January, 29, 16, 0, 0, 0, time.UTC)
+
+	infos := certificatesInfo{
+		{Store: "default", SANs: "*.foo.bar", Serial: "2a", NotAfter: notAfter, SCT: &traefiktls.SCTStatus{Count: 3, Required: 3}},
+		{Store: "default", SANs: "internal.foo.bar", Serial: "2b", NotAfter: notAfter, SCT: &traefiktls.SCTStatus{Required: 3, Error: "no SCT"}},
+		{Store: "other", SANs: "foo.bar", Serial: "2c", NotAfter: notAfter},
+	}
+
+	compliant := certificateRepresentation{
+		Store:    "default",
+		SANs:     "*.foo.bar",
+		Serial:   "2a",
+		NotAfter: notAfter,
+		SCT:      &sctRepresentation{Compliant: true, Count: 3, Required: 3},
+	}
+	notCompliant := certificateRepresentation{
+		Store:    "default",
+		SANs:     "internal.foo.bar",
+		Serial:   "2b",
+		NotAfter: notAfter,
+		SCT:      &sctRepresentation{Required: 3, Error: "no SCT"},
+	}
+	notChecked := certificateRepresentation{
+		Store:    "other",
+		SANs:     "foo.bar",
+		Serial:   "2c",
+		NotAfter: notAfter,
+	}
+
+	testCases := []struct {
+		desc         string
+		tlsStores    TLSStores
+		query        string
+		statusCode   int
+		certificates []certificateRepresentation
+	}{
+		{
+			desc:         "all certificates",
+			tlsStores:    infos,
+			statusCode:   http.StatusOK,
+			certificates: []certificateRepresentation{compliant, notCompliant, notChecked},
+		},
+		{
+			desc:         "certificates not complying with the certificate transparency policy",
+			tlsStores:    infos,
+			query:        "?sctCompliant=false",
+			statusCode:   http.StatusOK,
+			certificates: []certificateRepresentation{notCompliant},
+		},
+		{
+			desc:       "invalid sctCompliant",
+			tlsStores:  infos,
+			query:      "?sctCompliant=true",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "no TLS stores",
+			statusCode:   http.StatusOK,
+			certificates: []certificateRepresentation{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, nil, nil, nil, test.tlsStores, nil, nil, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			resp, err := http.DefaultClient.Get(server.URL + "/api/tls/certificates" + test.query)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			require.Equal(t, test.statusCode, resp.StatusCode)
+			if test.statusCode != http.StatusOK {
+				return
+			}
+
+			var result []certificateRepresentation
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.certificates, result)
+		})
+	}
+}
+
+func TestHandler_TLSHandshakeErrors(t *testing.T) {
+	now := time.Date(2020, time.April, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc      string
+		tlsStores TLSStores
+		expected  []handshakeErrorsRepresentation
+	}{
+		{
+			desc: "handshake errors",
+			tlsStores: handshakeErrors{
+				{
+					Reason: traefiktls.HandshakeErrorNoCertificate,
+					Count:  3,
+					Samples: []traefiktls.HandshakeErrorSample{
+						{Time: now, ServerName: "foo.bar", RemoteAddr: "10.0.0.1:41234", Error: "strict SNI enabled"},
+					},
+				},
+				{
+					Reason: traefiktls.HandshakeErrorProtocol,
+					Count:  1,
+					Samples: []traefiktls.HandshakeErrorSample{
+						{Time: now, RemoteAddr: "10.0.0.2:41234", Error: "tls: client offered only unsupported versions: [301]"},
+					},
+				},
+			},
+			expected: []handshakeErrorsRepresentation{
+				{
+					Reason: "no_certificate",
+					Count:  3,
+					Samples: []handshakeErrorSampleRepresentation{
+						{Time: now, ServerName: "foo.bar", RemoteAddr: "10.0.0.1:41234", Error: "strict SNI enabled"},
+					},
+				},
+				{
+					Reason: "protocol",
+					Count:  1,
+					Samples: []handshakeErrorSampleRepresentation{
+						{Time: now, RemoteAddr: "10.0.0.2:41234", Error: "tls: client offered only unsupported versions: [301]"},
+					},
+				},
+			},
+		},
+		{
+			desc:      "no handshake errors",
+			tlsStores: handshakeErrors{},
+			expected:  []handshakeErrorsRepresentation{},
+		},
+		{
+			desc:     "no TLS stores",
+			expected: []handshakeErrorsRepresentation{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, nil, nil, nil, test.tlsStores, nil, nil, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			resp, err := http.DefaultClient.Get(server.URL + "/api/tls/handshake-errors")
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			var result []handshakeErrorsRepresentation
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
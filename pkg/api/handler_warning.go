@@ -0,0 +1,38 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+func (h Handler) getWarnings(rw http.ResponseWriter, request *http.Request) {
+	results := make([]runtime.Warning, 0, len(h.runtimeConfiguration.Warnings))
+
+	class := request.URL.Query().Get("class")
+	for _, warning := range h.runtimeConfiguration.Warnings {
+		if class == "" || strings.EqualFold(warning.Class, class) {
+			results = append(results, warning)
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
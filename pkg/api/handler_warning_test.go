@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Warnings(t *testing.T) {
+	type expected struct {
+		statusCode int
+		nextPage   string
+		jsonFile   string
+	}
+
+	conf := runtime.Configuration{
+		Warnings: []runtime.Warning{
+			{
+				Class:   "insecure",
+				Kind:    "http.middleware",
+				Name:    "auth@myprovider",
+				Field:   "forwardAuth.tls.insecureSkipVerify",
+				Message: "the certificate of the server is not verified",
+			},
+			{
+				Class:   "deprecated",
+				Kind:    "http.middleware",
+				Name:    "cors@myprovider",
+				Field:   "headers.accessControlAllowOrigin",
+				Message: "use accessControlAllowOriginList instead",
+			},
+			{
+				Class:   "insecure",
+				Kind:    "tls.options",
+				Name:    "default",
+				Field:   "minVersion",
+				Message: "TLS 1.0 is enabled",
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		path     string
+		conf     runtime.Configuration
+		expected expected
+	}{
+		{
+			desc: "no warnings",
+			path: "/api/warnings",
+			conf: runtime.Configuration{},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/warnings-empty.json",
+			},
+		},
+		{
+			desc: "all warnings",
+			path: "/api/warnings",
+			conf: conf,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/warnings.json",
+			},
+		},
+		{
+			desc: "warnings filtered by class",
+			path: "/api/warnings?class=insecure",
+			conf: conf,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/warnings-filtered-class.json",
+			},
+		},
+		{
+			desc: "one page of warnings",
+			path: "/api/warnings?page=2&per_page=1",
+			conf: conf,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "3",
+				jsonFile:   "testdata/warnings-page2.json",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, &test.conf)
+			server := httptest.NewServer(handler.createRouter())
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected.nextPage, resp.Header.Get(nextPageHeader))
+
+			require.Equal(t, test.expected.statusCode, resp.StatusCode)
+
+			assert.Equal(t, resp.Header.Get("Content-Type"), "application/json")
+
+			contents, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			if *updateExpected {
+				var results interface{}
+				err := json.Unmarshal(contents, &results)
+				require.NoError(t, err)
+
+				newJSON, err := json.MarshalIndent(results, "", "\t")
+				require.NoError(t, err)
+
+				err = ioutil.WriteFile(test.expected.jsonFile, newJSON, 0644)
+				require.NoError(t, err)
+			}
+
+			data, err := ioutil.ReadFile(test.expected.jsonFile)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(contents))
+		})
+	}
+}
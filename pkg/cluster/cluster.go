@@ -0,0 +1,244 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider/kv"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+const (
+	retryDelay     = 5 * time.Second
+	releaseTimeout = 5 * time.Second
+)
+
+// Configuration holds the configuration of the coordination of the Traefik instances of a cluster,
+// which elect a leader running the tasks to be run by a single instance.
+type Configuration struct {
+	Consul *KVStore `description:"Elect the leader in Consul." json:"consul,omitempty" toml:"consul,omitempty" yaml:"consul,omitempty" export:"true"`
+	Etcd   *KVStore `description:"Elect the leader in etcd." json:"etcd,omitempty" toml:"etcd,omitempty" yaml:"etcd,omitempty" export:"true"`
+	Redis  *KVStore `description:"Elect the leader in Redis." json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" export:"true"`
+
+	NodeName      string         `description:"Name of the instance in the cluster, defaults to the hostname." json:"nodeName,omitempty" toml:"nodeName,omitempty" yaml:"nodeName,omitempty" export:"true"`
+	LeaseDuration types.Duration `description:"Duration after which the leadership of an instance which stopped renewing it is lost." json:"leaseDuration,omitempty" toml:"leaseDuration,omitempty" yaml:"leaseDuration,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Configuration) SetDefaults() {
+	c.LeaseDuration = types.Duration(15 * time.Second)
+}
+
+// KVStore holds the configuration of the KV store holding the leader key.
+type KVStore struct {
+	Endpoints []string         `description:"KV store endpoints." json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	Key       string           `description:"Key locked by the leader." json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty" export:"true"`
+	Username  string           `description:"KV Username." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password  string           `description:"KV Password." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" secret:"true"`
+	TLS       *types.ClientTLS `description:"Enable TLS support." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (s *KVStore) SetDefaults() {
+	s.Key = "traefik/leader"
+}
+
+// Election runs the instance for the leadership of the cluster,
+// held by the instance locking the leader key of the KV store, and renewed as long as the instance runs.
+type Election struct {
+	client store.Store
+	key    string
+	node   string
+	ttl    time.Duration
+
+	retryDelay time.Duration
+
+	leader int32
+
+	listenersMu sync.Mutex
+	listeners   []func(leader bool)
+}
+
+// NewElection creates an Election in the KV store of the configuration.
+func NewElection(config *Configuration) (*Election, error) {
+	backend, storeConfig, err := config.backend()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(storeConfig.Endpoints) == 0 {
+		return nil, errors.New("no KV store endpoint")
+	}
+
+	if config.LeaseDuration <= 0 {
+		return nil, errors.New("leaseDuration must be positive")
+	}
+
+	node := config.NodeName
+	if node == "" {
+		node, err = os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the hostname as node name: %w", err)
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if storeConfig.TLS != nil {
+		tlsConfig, err = storeConfig.TLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("unable to create the TLS configuration: %w", err)
+		}
+	}
+
+	client, err := kv.NewStore(backend, storeConfig.Endpoints, storeConfig.Username, storeConfig.Password, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the KV store: %w", err)
+	}
+
+	return newElection(client, storeConfig.Key, node, time.Duration(config.LeaseDuration)), nil
+}
+
+func newElection(client store.Store, key, node string, ttl time.Duration) *Election {
+	return &Election{client: client, key: key, node: node, ttl: ttl, retryDelay: retryDelay}
+}
+
+func (c *Configuration) backend() (store.Backend, *KVStore, error) {
+	var backends int
+	for _, backend := range []bool{c.Consul != nil, c.Etcd != nil, c.Redis != nil} {
+		if backend {
+			backends++
+		}
+	}
+
+	if backends != 1 {
+		return "", nil, errors.New("exactly one of consul, etcd, or redis is required")
+	}
+
+	switch {
+	case c.Consul != nil:
+		return store.CONSUL, c.Consul, nil
+	case c.Etcd != nil:
+		return store.ETCDV3, c.Etcd, nil
+	default:
+		return store.REDIS, c.Redis, nil
+	}
+}
+
+// Node returns the name of the instance in the cluster.
+func (e *Election) Node() string {
+	return e.node
+}
+
+// IsLeader returns whether the instance is currently the leader of the cluster.
+func (e *Election) IsLeader() bool {
+	return atomic.LoadInt32(&e.leader) == 1
+}
+
+// AddListener adds a listener called whenever the instance becomes, or stops being, the leader.
+// The listeners are called sequentially by the election, and must not block.
+func (e *Election) AddListener(listener func(leader bool)) {
+	e.listenersMu.Lock()
+	defer e.listenersMu.Unlock()
+
+	e.listeners = append(e.listeners, listener)
+}
+
+// Run runs for the leadership until the context is done, and then releases the leadership if it holds it.
+func (e *Election) Run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	for {
+		if err := e.campaign(ctx); err != nil {
+			logger.Errorf("Leader election of the node %s: %v", e.node, err)
+		}
+
+		select {
+		case <-time.After(e.retryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// campaign waits for the leader key to be locked by the instance, and then holds it until it is lost, or until the context is done.
+func (e *Election) campaign(ctx context.Context) error {
+	locker, err := e.client.NewLock(e.key, &store.LockOptions{Value: []byte(e.node), TTL: e.ttl})
+	if err != nil {
+		return fmt.Errorf("unable to create the lock: %w", err)
+	}
+
+	stop := make(chan struct{})
+	locked := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			close(stop)
+		case <-locked:
+		}
+	}()
+
+	lost, err := locker.Lock(stop)
+	close(locked)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("unable to lock the leader key: %w", err)
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Infof("The node %s is the leader of the cluster", e.node)
+	e.setLeader(true)
+	defer e.setLeader(false)
+
+	select {
+	case <-lost:
+		logger.Warnf("The node %s lost the leadership of the cluster", e.node)
+		return nil
+	case <-ctx.Done():
+		return e.release(locker)
+	}
+}
+
+// release unlocks the leader key, without waiting longer than the release timeout for an unreachable KV store,
+// in which case the leadership is released on the expiry of its lease.
+func (e *Election) release(locker store.Locker) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- locker.Unlock()
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("unable to release the leadership: %w", err)
+		}
+		return nil
+	case <-time.After(releaseTimeout):
+		return errors.New("unable to release the leadership: timeout")
+	}
+}
+
+func (e *Election) setLeader(leader bool) {
+	var value int32
+	if leader {
+		value = 1
+	}
+	atomic.StoreInt32(&e.leader, value)
+
+	e.listenersMu.Lock()
+	listeners := append([]func(bool){}, e.listeners...)
+	e.listenersMu.Unlock()
+
+	for _, listener := range listeners {
+		listener(leader)
+	}
+}
@@ -0,0 +1,211 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfiguration_backend(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          Configuration
+		expectedBackend store.Backend
+		expectedErr     bool
+	}{
+		{
+			desc:        "no backend",
+			expectedErr: true,
+		},
+		{
+			desc:            "consul",
+			config:          Configuration{Consul: &KVStore{}},
+			expectedBackend: store.CONSUL,
+		},
+		{
+			desc:            "etcd",
+			config:          Configuration{Etcd: &KVStore{}},
+			expectedBackend: store.ETCDV3,
+		},
+		{
+			desc:            "redis",
+			config:          Configuration{Redis: &KVStore{}},
+			expectedBackend: store.REDIS,
+		},
+		{
+			desc:        "several backends",
+			config:      Configuration{Consul: &KVStore{}, Redis: &KVStore{}},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backend, _, err := test.config.backend()
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedBackend, backend)
+		})
+	}
+}
+
+func TestElection(t *testing.T) {
+	client := &memoryKV{}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+
+	node1 := newElection(client, "traefik/leader", "node1", time.Second)
+	node2 := newElection(client, "traefik/leader", "node2", time.Second)
+
+	events1 := make(chan bool, 10)
+	node1.AddListener(func(leader bool) { events1 <- leader })
+	events2 := make(chan bool, 10)
+	node2.AddListener(func(leader bool) { events2 <- leader })
+
+	done1 := make(chan struct{})
+	go func() {
+		node1.Run(ctx1)
+		close(done1)
+	}()
+
+	assert.True(t, <-events1)
+	assert.True(t, node1.IsLeader())
+	assert.Equal(t, "node1", client.value())
+
+	go node2.Run(ctx2)
+
+	// The leader steps down when it stops, and the other node is elected.
+	cancel1()
+	assert.False(t, <-events1)
+	<-done1
+	assert.False(t, node1.IsLeader())
+
+	assert.True(t, <-events2)
+	assert.True(t, node2.IsLeader())
+	assert.Equal(t, "node2", client.value())
+}
+
+func TestElection_lostLeadership(t *testing.T) {
+	client := &memoryKV{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	election := newElection(client, "traefik/leader", "node1", time.Second)
+	election.retryDelay = 10 * time.Millisecond
+
+	events := make(chan bool, 10)
+	election.AddListener(func(leader bool) { events <- leader })
+
+	go election.Run(ctx)
+
+	assert.True(t, <-events)
+
+	// The lease expires, e.g. when the KV store is unreachable, and the node runs again for the leadership.
+	client.expire()
+	assert.False(t, <-events)
+	assert.True(t, <-events)
+	assert.True(t, election.IsLeader())
+}
+
+// memoryKV is an in-memory KV store holding a single lock, which can be expired.
+type memoryKV struct {
+	store.Store
+
+	mu     sync.Mutex
+	held   bool
+	holder []byte
+	lost   chan struct{}
+	freed  chan struct{}
+}
+
+func (m *memoryKV) NewLock(_ string, options *store.LockOptions) (store.Locker, error) {
+	return &memoryLock{kv: m, value: options.Value}, nil
+}
+
+func (m *memoryKV) value() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return string(m.holder)
+}
+
+// tryLock locks the key if it is free, or returns a channel closed once it is freed.
+func (m *memoryKV) tryLock(value []byte) (<-chan struct{}, <-chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.held {
+		return nil, m.freed
+	}
+
+	m.held = true
+	m.holder = value
+	m.lost = make(chan struct{})
+	m.freed = make(chan struct{})
+
+	return m.lost, nil
+}
+
+func (m *memoryKV) free() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.held {
+		return
+	}
+
+	m.held = false
+	m.holder = nil
+	close(m.freed)
+}
+
+func (m *memoryKV) expire() {
+	m.mu.Lock()
+	lost := m.lost
+	m.mu.Unlock()
+
+	m.free()
+	close(lost)
+}
+
+type memoryLock struct {
+	kv    *memoryKV
+	value []byte
+}
+
+func (l *memoryLock) Lock(stop chan struct{}) (<-chan struct{}, error) {
+	for {
+		lost, freed := l.kv.tryLock(l.value)
+		if lost != nil {
+			return lost, nil
+		}
+
+		select {
+		case <-freed:
+		case <-stop:
+			return nil, errors.New("lock aborted")
+		}
+	}
+}
+
+func (l *memoryLock) Unlock() error {
+	l.kv.free()
+	return nil
+}
@@ -0,0 +1,486 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/tls"
+)
+
+// Action is the action applied to an element of the dynamic configuration.
+type Action string
+
+// Actions applied to the elements of the dynamic configuration.
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+// Plan describes the changes of the dynamic configuration resulting from a candidate configuration.
+type Plan struct {
+	Changes []Change `json:"changes"`
+	Summary Summary  `json:"summary"`
+}
+
+// Summary counts the changes of a plan by action.
+type Summary struct {
+	Create int `json:"create"`
+	Update int `json:"update"`
+	Delete int `json:"delete"`
+}
+
+// Change describes the change of an element, e.g. the HTTP router named "foo".
+type Change struct {
+	// Type is the type of the element, e.g. "http.routers".
+	Type   string        `json:"type"`
+	Name   string        `json:"name"`
+	Action Action        `json:"action"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange describes the change of a field of an element.
+// Before is not set for a field which is added, and After for a field which is removed.
+type FieldChange struct {
+	// Path is the path of the field in the element, e.g. "loadBalancer.servers[0].url".
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+type field struct {
+	path  string
+	value interface{}
+}
+
+var elementTypes = []string{
+	"http.routers", "http.middlewares", "http.services",
+	"tcp.routers", "tcp.services",
+	"udp.routers", "udp.services",
+	"tls.options", "tls.stores",
+}
+
+const certificatesType = "tls.certificates"
+
+// Compute returns the plan of the changes from the current dynamic configuration to the candidate one.
+// The elements are compared by name, and the certificates by content, regardless of their private key,
+// which is redacted from the snapshots of the dynamic configuration.
+func Compute(current, candidate *dynamic.Configuration) (*Plan, error) {
+	plan := &Plan{Changes: []Change{}}
+
+	currentElements := elements(current)
+	candidateElements := elements(candidate)
+
+	for _, elementType := range elementTypes {
+		changes, err := compareElements(elementType, currentElements[elementType], candidateElements[elementType])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", elementType, err)
+		}
+
+		plan.Changes = append(plan.Changes, changes...)
+	}
+
+	plan.Changes = append(plan.Changes, compareCertificates(certificates(current), certificates(candidate))...)
+
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case Create:
+			plan.Summary.Create++
+		case Update:
+			plan.Summary.Update++
+		case Delete:
+			plan.Summary.Delete++
+		}
+	}
+
+	return plan, nil
+}
+
+// HasChanges returns whether applying the candidate configuration changes the current one.
+func (p *Plan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// WriteText writes the human-readable representation of the plan.
+func (p *Plan) WriteText(w io.Writer) error {
+	if !p.HasChanges() {
+		_, err := fmt.Fprintln(w, "No changes. The candidate configuration matches the current configuration.")
+		return err
+	}
+
+	var b strings.Builder
+	for _, change := range p.Changes {
+		fmt.Fprintf(&b, "  %s %s %q\n", actionSymbol(change.Action), change.Type, change.Name)
+
+		for _, f := range change.Fields {
+			switch {
+			case f.Before == nil:
+				fmt.Fprintf(&b, "      + %s: %s\n", f.Path, formatValue(f.After))
+			case f.After == nil:
+				fmt.Fprintf(&b, "      - %s: %s\n", f.Path, formatValue(f.Before))
+			default:
+				fmt.Fprintf(&b, "      ~ %s: %s -> %s\n", f.Path, formatValue(f.Before), formatValue(f.After))
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to delete.\n", p.Summary.Create, p.Summary.Update, p.Summary.Delete)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func actionSymbol(action Action) string {
+	switch action {
+	case Create:
+		return "+"
+	case Delete:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func formatValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// elements returns the maps of elements of the configuration, by element type.
+func elements(conf *dynamic.Configuration) map[string]interface{} {
+	elts := make(map[string]interface{})
+	if conf == nil {
+		return elts
+	}
+
+	if conf.HTTP != nil {
+		elts["http.routers"] = conf.HTTP.Routers
+		elts["http.middlewares"] = conf.HTTP.Middlewares
+		elts["http.services"] = conf.HTTP.Services
+	}
+
+	if conf.TCP != nil {
+		elts["tcp.routers"] = conf.TCP.Routers
+		elts["tcp.services"] = conf.TCP.Services
+	}
+
+	if conf.UDP != nil {
+		elts["udp.routers"] = conf.UDP.Routers
+		elts["udp.services"] = conf.UDP.Services
+	}
+
+	if conf.TLS != nil {
+		elts["tls.options"] = conf.TLS.Options
+		elts["tls.stores"] = conf.TLS.Stores
+	}
+
+	return elts
+}
+
+func compareElements(elementType string, current, candidate interface{}) ([]Change, error) {
+	currentFields, err := flattenElements(current)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateFields, err := flattenElements(candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, name := range sortedNames(currentFields, candidateFields) {
+		before, inCurrent := currentFields[name]
+		after, inCandidate := candidateFields[name]
+
+		change := Change{Type: elementType, Name: name}
+
+		switch {
+		case !inCurrent:
+			change.Action = Create
+			for _, f := range after {
+				change.Fields = append(change.Fields, FieldChange{Path: f.path, After: f.value})
+			}
+		case !inCandidate:
+			change.Action = Delete
+			for _, f := range before {
+				change.Fields = append(change.Fields, FieldChange{Path: f.path, Before: f.value})
+			}
+		default:
+			change.Action = Update
+			change.Fields = compareFields(before, after)
+			if len(change.Fields) == 0 {
+				continue
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func sortedNames(current, candidate map[string][]field) []string {
+	var names []string
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range candidate {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func compareFields(before, after []field) []FieldChange {
+	afterValues := make(map[string]interface{}, len(after))
+	for _, f := range after {
+		afterValues[f.path] = f.value
+	}
+
+	beforeValues := make(map[string]interface{}, len(before))
+
+	var changes []FieldChange
+	for _, f := range before {
+		beforeValues[f.path] = f.value
+
+		value, ok := afterValues[f.path]
+		if !ok {
+			changes = append(changes, FieldChange{Path: f.path, Before: f.value})
+			continue
+		}
+
+		if !reflect.DeepEqual(f.value, value) {
+			changes = append(changes, FieldChange{Path: f.path, Before: f.value, After: value})
+		}
+	}
+
+	for _, f := range after {
+		if _, ok := beforeValues[f.path]; !ok {
+			changes = append(changes, FieldChange{Path: f.path, After: f.value})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// flattenElements returns the fields of each element of the map of elements.
+func flattenElements(elts interface{}) (map[string][]field, error) {
+	fields := make(map[string][]field)
+
+	if elts == nil {
+		return fields, nil
+	}
+
+	value := reflect.ValueOf(elts)
+	if value.Kind() != reflect.Map {
+		return nil, fmt.Errorf("unsupported type: %T", elts)
+	}
+
+	iter := value.MapRange()
+	for iter.Next() {
+		f, err := flatten(iter.Value().Interface())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", iter.Key().String(), err)
+		}
+
+		fields[iter.Key().String()] = f
+	}
+
+	return fields, nil
+}
+
+// flatten returns the leaf fields of the JSON representation of the element, in order.
+// The empty objects and arrays are leaves, as an empty object enables an option, e.g. the TLS of a router.
+func flatten(element interface{}) ([]field, error) {
+	data, err := json.Marshal(element)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	var fields []field
+	flattenValue("", value, &fields)
+
+	return fields, nil
+}
+
+func flattenValue(path string, value interface{}, fields *[]field) {
+	switch v := value.(type) {
+	case nil:
+		return
+
+	case map[string]interface{}:
+		if len(v) == 0 {
+			break
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, v[key], fields)
+		}
+		return
+
+	case []interface{}:
+		if len(v) == 0 {
+			break
+		}
+
+		for i, item := range v {
+			flattenValue(path+"["+strconv.Itoa(i)+"]", item, fields)
+		}
+		return
+	}
+
+	*fields = append(*fields, field{path: path, value: value})
+}
+
+func certificates(conf *dynamic.Configuration) []*tls.CertAndStores {
+	if conf == nil || conf.TLS == nil {
+		return nil
+	}
+
+	return conf.TLS.Certificates
+}
+
+func compareCertificates(current, candidate []*tls.CertAndStores) []Change {
+	currentCerts := certificatesByFingerprint(current)
+	candidateCerts := certificatesByFingerprint(candidate)
+
+	var fingerprints []string
+	for fingerprint := range currentCerts {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	for fingerprint := range candidateCerts {
+		if _, ok := currentCerts[fingerprint]; !ok {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+
+	sort.Slice(fingerprints, func(i, j int) bool {
+		nameI := certificateName(fingerprints[i], currentCerts, candidateCerts)
+		nameJ := certificateName(fingerprints[j], currentCerts, candidateCerts)
+		if nameI == nameJ {
+			return fingerprints[i] < fingerprints[j]
+		}
+		return nameI < nameJ
+	})
+
+	var changes []Change
+	for _, fingerprint := range fingerprints {
+		before, inCurrent := currentCerts[fingerprint]
+		after, inCandidate := candidateCerts[fingerprint]
+
+		change := Change{Type: certificatesType, Name: certificateName(fingerprint, currentCerts, candidateCerts)}
+
+		switch {
+		case !inCurrent:
+			change.Action = Create
+			if len(after.Stores) > 0 {
+				change.Fields = []FieldChange{{Path: "stores", After: after.Stores}}
+			}
+		case !inCandidate:
+			change.Action = Delete
+			if len(before.Stores) > 0 {
+				change.Fields = []FieldChange{{Path: "stores", Before: before.Stores}}
+			}
+		case !sameStores(before.Stores, after.Stores):
+			change.Action = Update
+			change.Fields = []FieldChange{{Path: "stores", Before: before.Stores, After: after.Stores}}
+		default:
+			continue
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func certificatesByFingerprint(certs []*tls.CertAndStores) map[string]*tls.CertAndStores {
+	byFingerprint := make(map[string]*tls.CertAndStores, len(certs))
+	for _, cert := range certs {
+		hash := sha256.Sum256([]byte(cert.CertFile))
+		byFingerprint[hex.EncodeToString(hash[:])] = cert
+	}
+
+	return byFingerprint
+}
+
+// certificateName names a certificate after its domains, along with the beginning of its fingerprint,
+// or after its path when it is not given inline.
+func certificateName(fingerprint string, current, candidate map[string]*tls.CertAndStores) string {
+	cert, ok := current[fingerprint]
+	if !ok {
+		cert = candidate[fingerprint]
+	}
+
+	if cert.CertFile.IsPath() {
+		return cert.CertFile.String()
+	}
+
+	name := "sha256:" + fingerprint[:16]
+
+	block, _ := pem.Decode([]byte(cert.CertFile))
+	if block == nil {
+		return name
+	}
+
+	x509Cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return name
+	}
+
+	domains := x509Cert.DNSNames
+	if len(domains) == 0 && x509Cert.Subject.CommonName != "" {
+		domains = []string{x509Cert.Subject.CommonName}
+	}
+	if len(domains) == 0 {
+		return name
+	}
+
+	return strings.Join(domains, ",") + " (" + name + ")"
+}
+
+func sameStores(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,362 @@
+package diff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		current   *dynamic.Configuration
+		candidate *dynamic.Configuration
+		expected  *Plan
+	}{
+		{
+			desc:      "empty configurations",
+			current:   &dynamic.Configuration{},
+			candidate: &dynamic.Configuration{},
+			expected:  &Plan{Changes: []Change{}},
+		},
+		{
+			desc: "same configurations",
+			current: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"foo": {Rule: "Host(`foo.com`)", Service: "foo"},
+					},
+				},
+			},
+			candidate: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"foo": {Rule: "Host(`foo.com`)", Service: "foo"},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+				},
+			},
+			expected: &Plan{Changes: []Change{}},
+		},
+		{
+			desc: "HTTP changes",
+			current: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"foo": {Rule: "Host(`foo.com`)", Service: "foo", Middlewares: []string{"auth", "strip"}},
+						"bar": {Rule: "Host(`bar.com`)", Service: "bar"},
+					},
+					Services: map[string]*dynamic.Service{
+						"foo": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{{URL: "http://10.0.0.1"}},
+							},
+						},
+					},
+				},
+			},
+			candidate: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"foo": {Rule: "Host(`foo.com`)", Service: "foo", Middlewares: []string{"auth"}, TLS: &dynamic.RouterTLSConfig{}},
+						"baz": {Rule: "Host(`baz.com`)", Service: "foo"},
+					},
+					Services: map[string]*dynamic.Service{
+						"foo": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{{URL: "http://10.0.0.2"}},
+							},
+						},
+					},
+				},
+			},
+			expected: &Plan{
+				Changes: []Change{
+					{
+						Type:   "http.routers",
+						Name:   "bar",
+						Action: Delete,
+						Fields: []FieldChange{
+							{Path: "rule", Before: "Host(`bar.com`)"},
+							{Path: "service", Before: "bar"},
+						},
+					},
+					{
+						Type:   "http.routers",
+						Name:   "baz",
+						Action: Create,
+						Fields: []FieldChange{
+							{Path: "rule", After: "Host(`baz.com`)"},
+							{Path: "service", After: "foo"},
+						},
+					},
+					{
+						Type:   "http.routers",
+						Name:   "foo",
+						Action: Update,
+						Fields: []FieldChange{
+							{Path: "middlewares[1]", Before: "strip"},
+							{Path: "tls", After: map[string]interface{}{}},
+						},
+					},
+					{
+						Type:   "http.services",
+						Name:   "foo",
+						Action: Update,
+						Fields: []FieldChange{
+							{Path: "loadBalancer.servers[0].url", Before: "http://10.0.0.1", After: "http://10.0.0.2"},
+						},
+					},
+				},
+				Summary: Summary{Create: 1, Update: 2, Delete: 1},
+			},
+		},
+		{
+			desc: "TCP, UDP and TLS changes",
+			current: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Services: map[string]*dynamic.TCPService{
+						"foo": {LoadBalancer: &dynamic.TCPServersLoadBalancer{Servers: []dynamic.TCPServer{{Address: "10.0.0.1:80"}}}},
+					},
+				},
+				TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{
+						"default": {MinVersion: "VersionTLS12"},
+					},
+				},
+			},
+			candidate: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers: map[string]*dynamic.UDPRouter{
+						"dns": {EntryPoints: []string{"dns"}, Service: "dns"},
+					},
+				},
+				TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{
+						"default": {MinVersion: "VersionTLS13"},
+					},
+				},
+			},
+			expected: &Plan{
+				Changes: []Change{
+					{
+						Type:   "tcp.services",
+						Name:   "foo",
+						Action: Delete,
+						Fields: []FieldChange{
+							{Path: "loadBalancer.servers[0].address", Before: "10.0.0.1:80"},
+						},
+					},
+					{
+						Type:   "udp.routers",
+						Name:   "dns",
+						Action: Create,
+						Fields: []FieldChange{
+							{Path: "entryPoints[0]", After: "dns"},
+							{Path: "service", After: "dns"},
+						},
+					},
+					{
+						Type:   "tls.options",
+						Name:   "default",
+						Action: Update,
+						Fields: []FieldChange{
+							{Path: "minVersion", Before: "VersionTLS12", After: "VersionTLS13"},
+						},
+					},
+				},
+				Summary: Summary{Create: 1, Update: 1, Delete: 1},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			plan, err := Compute(test.current, test.candidate)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, plan)
+		})
+	}
+}
+
+func TestCompute_certificates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-plan")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	for _, name := range []string{"foo.crt", "bar.crt", "old.crt", "new.crt"} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0600)
+		require.NoError(t, err)
+	}
+
+	certFile := func(name string) tls.FileOrContent {
+		return tls.FileOrContent(filepath.Join(dir, name))
+	}
+
+	current := &dynamic.Configuration{
+		TLS: &dynamic.TLSConfiguration{
+			Certificates: []*tls.CertAndStores{
+				{Certificate: tls.Certificate{CertFile: certFile("foo.crt"), KeyFile: "REDACTED"}},
+				{Certificate: tls.Certificate{CertFile: certFile("bar.crt"), KeyFile: "REDACTED"}, Stores: []string{"default"}},
+				{Certificate: tls.Certificate{CertFile: certFile("old.crt"), KeyFile: "REDACTED"}},
+			},
+		},
+	}
+
+	candidate := &dynamic.Configuration{
+		TLS: &dynamic.TLSConfiguration{
+			Certificates: []*tls.CertAndStores{
+				{Certificate: tls.Certificate{CertFile: certFile("foo.crt"), KeyFile: "/certs/foo.key"}},
+				{Certificate: tls.Certificate{CertFile: certFile("bar.crt"), KeyFile: "/certs/bar.key"}, Stores: []string{"other"}},
+				{Certificate: tls.Certificate{CertFile: certFile("new.crt"), KeyFile: "/certs/new.key"}, Stores: []string{"default"}},
+			},
+		},
+	}
+
+	expected := &Plan{
+		Changes: []Change{
+			{
+				Type:   "tls.certificates",
+				Name:   filepath.Join(dir, "bar.crt"),
+				Action: Update,
+				Fields: []FieldChange{
+					{Path: "stores", Before: []string{"default"}, After: []string{"other"}},
+				},
+			},
+			{
+				Type:   "tls.certificates",
+				Name:   filepath.Join(dir, "new.crt"),
+				Action: Create,
+				Fields: []FieldChange{
+					{Path: "stores", After: []string{"default"}},
+				},
+			},
+			{
+				Type:   "tls.certificates",
+				Name:   filepath.Join(dir, "old.crt"),
+				Action: Delete,
+			},
+		},
+		Summary: Summary{Create: 1, Update: 1, Delete: 1},
+	}
+
+	plan, err := Compute(current, candidate)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, plan)
+}
+
+func TestCompute_certificateName(t *testing.T) {
+	candidate := &dynamic.Configuration{
+		TLS: &dynamic.TLSConfiguration{
+			Certificates: []*tls.CertAndStores{
+				{Certificate: tls.Certificate{CertFile: tls.FileOrContent(exampleCert)}},
+			},
+		},
+	}
+
+	plan, err := Compute(&dynamic.Configuration{}, candidate)
+	require.NoError(t, err)
+
+	require.Len(t, plan.Changes, 1)
+	assert.Regexp(t, `^example\.com \(sha256:[0-9a-f]{16}\)$`, plan.Changes[0].Name)
+}
+
+func TestPlan_WriteText(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		plan     *Plan
+		expected string
+	}{
+		{
+			desc:     "no changes",
+			plan:     &Plan{Changes: []Change{}},
+			expected: "No changes. The candidate configuration matches the current configuration.\n",
+		},
+		{
+			desc: "changes",
+			plan: &Plan{
+				Changes: []Change{
+					{
+						Type:   "http.routers",
+						Name:   "baz",
+						Action: Create,
+						Fields: []FieldChange{
+							{Path: "rule", After: "Host(`baz.com`)"},
+							{Path: "priority", After: float64(10)},
+						},
+					},
+					{
+						Type:   "http.routers",
+						Name:   "foo",
+						Action: Update,
+						Fields: []FieldChange{
+							{Path: "middlewares[1]", Before: "strip"},
+							{Path: "tls", After: map[string]interface{}{}},
+							{Path: "service", Before: "foo", After: "bar"},
+						},
+					},
+					{
+						Type:   "http.services",
+						Name:   "bar",
+						Action: Delete,
+						Fields: []FieldChange{
+							{Path: "loadBalancer.passHostHeader", Before: false},
+						},
+					},
+				},
+				Summary: Summary{Create: 1, Update: 1, Delete: 1},
+			},
+			expected: `  + http.routers "baz"
+      + rule: "Host(` + "`baz.com`" + `)"
+      + priority: 10
+  ~ http.routers "foo"
+      - middlewares[1]: "strip"
+      + tls: {}
+      ~ service: "foo" -> "bar"
+  - http.services "bar"
+      - loadBalancer.passHostHeader: false
+
+Plan: 1 to create, 1 to update, 1 to delete.
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := test.plan.WriteText(&buf)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, buf.String())
+		})
+	}
+}
+
+// exampleCert is a self-signed certificate for example.com.
+var exampleCert = `-----BEGIN CERTIFICATE-----
+MIIBmzCCAUGgAwIBAgIUdSb2mnefZ87zNi4pfB7kHUSYzpwwCgYIKoZIzj0EAwIw
+FjEUMBIGA1UEAwwLZXhhbXBsZS5jb20wIBcNMjYxMDE2MjA0MjQwWhgPMjEyNjA5
+MjIyMDQyNDBaMBYxFDASBgNVBAMMC2V4YW1wbGUuY29tMFkwEwYHKoZIzj0CAQYI
+KoZIzj0DAQcDQgAEMQ2CvzPH5DpfLjMKSFRWHKdbGNFJYcylMB/WY2zWYqamVQll
+VdjKZ1T4l3qSEm1tUFN3pdbBAJT8sqle2XEiUKNrMGkwHQYDVR0OBBYEFGXybePE
+y4bAwoiu1x7yiLfzZTUuMB8GA1UdIwQYMBaAFGXybePEy4bAwoiu1x7yiLfzZTUu
+MA8GA1UdEwEB/wQFMAMBAf8wFgYDVR0RBA8wDYILZXhhbXBsZS5jb20wCgYIKoZI
+zj0EAwIDSAAwRQIgQ4fhxJuECeNJKENibB4iSqrFDTUvGBuHTzkPBw8hWcsCIQDs
+kSMyX4rZ7n2WkZaosjHMDQNfsvGFG6OqzelWj8nsug==
+-----END CERTIFICATE-----
+`
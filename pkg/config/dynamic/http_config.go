@@ -2,6 +2,7 @@ package dynamic
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/types"
 )
@@ -14,6 +15,8 @@ type HTTPConfiguration struct {
 	Services    map[string]*Service    `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
 	Middlewares map[string]*Middleware `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
 	Models      map[string]*Model      `json:"models,omitempty" toml:"models,omitempty" yaml:"models,omitempty"`
+	// Applications are expanded into the routers, services, and middlewares of the configuration.
+	Applications map[string]*Application `json:"applications,omitempty" toml:"applications,omitempty" yaml:"applications,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -26,23 +29,81 @@ type Model struct {
 
 // +k8s:deepcopy-gen=true
 
+// Application is a higher-level description of an application,
+// expanded into a router, a service, and the middlewares it requires, with sane defaults.
+type Application struct {
+	EntryPoints []string `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty"`
+	// Hosts are the domains of the application, matched by the rule of its router.
+	Hosts []string `json:"hosts,omitempty" toml:"hosts,omitempty" yaml:"hosts,omitempty"`
+	// PathPrefix, if not empty, restricts the rule of the router to the paths with this prefix.
+	PathPrefix string `json:"pathPrefix,omitempty" toml:"pathPrefix,omitempty" yaml:"pathPrefix,omitempty"`
+	// Servers are the URLs of the backend servers of the application, load balanced by its service.
+	Servers     []string         `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty"`
+	HealthCheck *HealthCheck     `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+	Auth        *ApplicationAuth `json:"auth,omitempty" toml:"auth,omitempty" yaml:"auth,omitempty"`
+	TLS         *ApplicationTLS  `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty"`
+	// Middlewares are the middlewares applied after the authentication of the application.
+	Middlewares []string `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	Priority    int      `json:"priority,omitempty" toml:"priority,omitempty,omitzero" yaml:"priority,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ApplicationAuth holds the authentication of an application (can only be of one type at the same time).
+type ApplicationAuth struct {
+	BasicAuth   *BasicAuth   `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
+	ForwardAuth *ForwardAuth `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ApplicationTLS holds the TLS configuration of an application.
+type ApplicationTLS struct {
+	// Profile is the profile of the TLS options of the application: intermediate, or modern (default: the default TLS options).
+	Profile      string `json:"profile,omitempty" toml:"profile,omitempty" yaml:"profile,omitempty"`
+	CertResolver string `json:"certResolver,omitempty" toml:"certResolver,omitempty" yaml:"certResolver,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Service holds a service configuration (can only be of one type at the same time).
 type Service struct {
 	LoadBalancer *ServersLoadBalancer `json:"loadBalancer,omitempty" toml:"loadBalancer,omitempty" yaml:"loadBalancer,omitempty"`
 	Weighted     *WeightedRoundRobin  `json:"weighted,omitempty" toml:"weighted,omitempty" yaml:"weighted,omitempty" label:"-"`
 	Mirroring    *Mirroring           `json:"mirroring,omitempty" toml:"mirroring,omitempty" yaml:"mirroring,omitempty" label:"-"`
+	Mapped       *Mapped              `json:"mapped,omitempty" toml:"mapped,omitempty" yaml:"mapped,omitempty" label:"-"`
+	// Internal marks the service as internal: the guarded routers cannot expose it on the entry points not marked as internal.
+	Internal bool `json:"internal,omitempty" toml:"internal,omitempty" yaml:"internal,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
 
 // Router holds the router configuration.
 type Router struct {
-	EntryPoints []string         `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty"`
-	Middlewares []string         `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
-	Service     string           `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty"`
-	Rule        string           `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty"`
-	Priority    int              `json:"priority,omitempty" toml:"priority,omitempty,omitzero" yaml:"priority,omitempty"`
-	TLS         *RouterTLSConfig `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty"`
+	EntryPoints       []string           `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty"`
+	Middlewares       []string           `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	Service           string             `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty"`
+	Rule              string             `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty"`
+	Priority          int                `json:"priority,omitempty" toml:"priority,omitempty,omitzero" yaml:"priority,omitempty"`
+	TLS               *RouterTLSConfig   `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty"`
+	PathNormalization *PathNormalization `json:"pathNormalization,omitempty" toml:"pathNormalization,omitempty" yaml:"pathNormalization,omitempty"`
+	// EntryPointOverrides are the middlewares and TLS options of the router specific to some of its entry points, keyed by entry point name.
+	EntryPointOverrides map[string]*RouterEntryPointOverride `json:"entryPointOverrides,omitempty" toml:"entryPointOverrides,omitempty" yaml:"entryPointOverrides,omitempty"`
+	// GuardInternalServices rejects the router if it exposes an internal service on an entry point not marked as internal,
+	// even if the guard is not enabled globally.
+	GuardInternalServices bool `json:"guardInternalServices,omitempty" toml:"guardInternalServices,omitempty" yaml:"guardInternalServices,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RouterEntryPointOverride holds the configuration of a router specific to one of its entry points.
+type RouterEntryPointOverride struct {
+	// Middlewares, if not empty, replace the middlewares of the router on the entry point.
+	Middlewares []string `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	// ExcludedMiddlewares are removed from the middlewares of the router on the entry point.
+	ExcludedMiddlewares []string `json:"excludedMiddlewares,omitempty" toml:"excludedMiddlewares,omitempty" yaml:"excludedMiddlewares,omitempty"`
+	// TLSOptions, if not empty, replace the TLS options of the router on the entry point.
+	TLSOptions string `json:"tlsOptions,omitempty" toml:"tlsOptions,omitempty" yaml:"tlsOptions,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -52,6 +113,24 @@ type RouterTLSConfig struct {
 	Options      string         `json:"options,omitempty" toml:"options,omitempty" yaml:"options,omitempty"`
 	CertResolver string         `json:"certResolver,omitempty" toml:"certResolver,omitempty" yaml:"certResolver,omitempty"`
 	Domains      []types.Domain `json:"domains,omitempty" toml:"domains,omitempty" yaml:"domains,omitempty"`
+	// FallbackCertResolvers are the resolvers obtaining the certificates, in priority order, when the certResolver fails.
+	FallbackCertResolvers []string `json:"fallbackCertResolvers,omitempty" toml:"fallbackCertResolvers,omitempty" yaml:"fallbackCertResolvers,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// PathNormalization holds the normalization of the request paths of a router.
+type PathNormalization struct {
+	// MergeSlashes merges the consecutive slashes.
+	MergeSlashes bool `json:"mergeSlashes,omitempty" toml:"mergeSlashes,omitempty" yaml:"mergeSlashes,omitempty"`
+	// EncodedSlashes is the policy of the encoded slashes (%2F): keep (default), decode, or reject.
+	EncodedSlashes string `json:"encodedSlashes,omitempty" toml:"encodedSlashes,omitempty" yaml:"encodedSlashes,omitempty"`
+	// ResolveDotSegments removes the "." segments, and the ".." segments along with their parent segment.
+	ResolveDotSegments bool `json:"resolveDotSegments,omitempty" toml:"resolveDotSegments,omitempty" yaml:"resolveDotSegments,omitempty"`
+	// Lowercase lowercases the paths, for the backends whose paths are case-insensitive.
+	Lowercase bool `json:"lowercase,omitempty" toml:"lowercase,omitempty" yaml:"lowercase,omitempty"`
+	// Strict rejects the ambiguous paths, instead of normalizing them.
+	Strict bool `json:"strict,omitempty" toml:"strict,omitempty" yaml:"strict,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -79,6 +158,82 @@ type MirrorService struct {
 
 // +k8s:deepcopy-gen=true
 
+// Mapped holds the Mapped service configuration.
+// The requests are forwarded to the server mapped to their host, looked up at request time,
+// so that a single router can serve a large number of hosts.
+type Mapped struct {
+	// Entries are the servers of the hosts declared in the dynamic configuration.
+	Entries []MappedEntry `json:"entries,omitempty" toml:"entries,omitempty" yaml:"entries,omitempty"`
+
+	// KV and HTTP are the sources looking up the hosts missing from the entries, only one of them can be defined.
+	KV   *MappedKV   `json:"kv,omitempty" toml:"kv,omitempty" yaml:"kv,omitempty"`
+	HTTP *MappedHTTP `json:"http,omitempty" toml:"http,omitempty" yaml:"http,omitempty"`
+
+	// CacheTTL is the duration the servers looked up in the source are cached for,
+	// and NegativeCacheTTL the one of the hosts unknown to the source.
+	CacheTTL         types.Duration `json:"cacheTTL,omitempty" toml:"cacheTTL,omitempty" yaml:"cacheTTL,omitempty"`
+	NegativeCacheTTL types.Duration `json:"negativeCacheTTL,omitempty" toml:"negativeCacheTTL,omitempty" yaml:"negativeCacheTTL,omitempty"`
+	// CacheSize is the maximum number of hosts cached, the least recently used ones being evicted first.
+	CacheSize int `json:"cacheSize,omitempty" toml:"cacheSize,omitempty" yaml:"cacheSize,omitempty"`
+
+	// Fallback is the service of the requests whose host is not mapped, which are answered with a 404 status code without one.
+	Fallback       string `json:"fallback,omitempty" toml:"fallback,omitempty" yaml:"fallback,omitempty"`
+	PassHostHeader *bool  `json:"passHostHeader,omitempty" toml:"passHostHeader,omitempty" yaml:"passHostHeader,omitempty"`
+}
+
+// SetDefaults sets the default values on a Mapped service.
+func (m *Mapped) SetDefaults() {
+	defaultPassHostHeader := true
+	m.PassHostHeader = &defaultPassHostHeader
+	m.CacheTTL = types.Duration(time.Minute)
+	m.NegativeCacheTTL = types.Duration(10 * time.Second)
+	m.CacheSize = 100000
+}
+
+// +k8s:deepcopy-gen=true
+
+// MappedEntry maps a host to a server.
+type MappedEntry struct {
+	Host string `json:"host,omitempty" toml:"host,omitempty" yaml:"host,omitempty"`
+	URL  string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// MappedKV is a Mapped source backed by a KV store, holding the URL of the server of each host under the root key.
+type MappedKV struct {
+	// Backend is the type of KV store: consul, etcd, zookeeper, or redis.
+	Backend   string     `json:"backend,omitempty" toml:"backend,omitempty" yaml:"backend,omitempty"`
+	Endpoints []string   `json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	RootKey   string     `json:"rootKey,omitempty" toml:"rootKey,omitempty" yaml:"rootKey,omitempty"`
+	Username  string     `json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password  string     `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" secret:"true"`
+	TLS       *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+// SetDefaults sets the default values on a MappedKV.
+func (m *MappedKV) SetDefaults() {
+	m.RootKey = "traefik/hosts"
+}
+
+// +k8s:deepcopy-gen=true
+
+// MappedHTTP is a Mapped source backed by an HTTP endpoint,
+// answering the URL of the server of the host given in the host query parameter, or a 404 status code for an unknown host.
+type MappedHTTP struct {
+	Endpoint string            `json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	TLS      *ClientTLS        `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+	Timeout  types.Duration    `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// SetDefaults sets the default values on a MappedHTTP.
+func (m *MappedHTTP) SetDefaults() {
+	m.Timeout = types.Duration(5 * time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
 // WeightedRoundRobin is a weighted round robin load-balancer of services.
 type WeightedRoundRobin struct {
 	Services []WRRService `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
@@ -101,6 +256,20 @@ func (w *WRRService) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// Pinning holds the configuration of the pinning of the requests to a server, with a signed header sent by trusted clients.
+type Pinning struct {
+	HeaderName  string   `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty"`
+	Secret      string   `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty" secret:"true"`
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+}
+
+// SetDefaults Default values for a Pinning.
+func (p *Pinning) SetDefaults() {
+	p.HeaderName = "X-Traefik-Pin"
+}
+
+// +k8s:deepcopy-gen=true
+
 // Sticky holds the sticky configuration.
 type Sticky struct {
 	Cookie *Cookie `json:"cookie,omitempty" toml:"cookie,omitempty" yaml:"cookie,omitempty" label:"allowEmpty"`
@@ -125,6 +294,8 @@ type ServersLoadBalancer struct {
 	HealthCheck        *HealthCheck        `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
 	PassHostHeader     *bool               `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader"`
 	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty"`
+	Resolver           string              `json:"resolver,omitempty" toml:"resolver,omitempty" yaml:"resolver,omitempty"`
+	Pinning            *Pinning            `json:"pinning,omitempty" toml:"pinning,omitempty" yaml:"pinning,omitempty"`
 }
 
 // Mergeable tells if the given service is mergeable.
@@ -154,7 +325,10 @@ func (l *ServersLoadBalancer) SetDefaults() {
 
 // ResponseForwarding holds configuration for the forward of the response.
 type ResponseForwarding struct {
-	FlushInterval string `json:"flushInterval,omitempty" toml:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
+	FlushInterval    string `json:"flushInterval,omitempty" toml:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
+	BufferSize       int    `json:"bufferSize,omitempty" toml:"bufferSize,omitempty" yaml:"bufferSize,omitempty"`
+	DisableBuffering bool   `json:"disableBuffering,omitempty" toml:"disableBuffering,omitempty" yaml:"disableBuffering,omitempty"`
+	Strict           bool   `json:"strict,omitempty" toml:"strict,omitempty" yaml:"strict,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
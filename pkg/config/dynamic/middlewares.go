@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"time"
 
@@ -16,28 +17,48 @@ import (
 
 // Middleware holds the Middleware configuration.
 type Middleware struct {
-	AddPrefix         *AddPrefix         `json:"addPrefix,omitempty" toml:"addPrefix,omitempty" yaml:"addPrefix,omitempty"`
-	StripPrefix       *StripPrefix       `json:"stripPrefix,omitempty" toml:"stripPrefix,omitempty" yaml:"stripPrefix,omitempty"`
-	StripPrefixRegex  *StripPrefixRegex  `json:"stripPrefixRegex,omitempty" toml:"stripPrefixRegex,omitempty" yaml:"stripPrefixRegex,omitempty"`
-	ReplacePath       *ReplacePath       `json:"replacePath,omitempty" toml:"replacePath,omitempty" yaml:"replacePath,omitempty"`
-	ReplacePathRegex  *ReplacePathRegex  `json:"replacePathRegex,omitempty" toml:"replacePathRegex,omitempty" yaml:"replacePathRegex,omitempty"`
-	Chain             *Chain             `json:"chain,omitempty" toml:"chain,omitempty" yaml:"chain,omitempty"`
-	IPWhiteList       *IPWhiteList       `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty"`
-	Headers           *Headers           `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
-	Errors            *ErrorPage         `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty"`
-	RateLimit         *RateLimit         `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
-	RedirectRegex     *RedirectRegex     `json:"redirectRegex,omitempty" toml:"redirectRegex,omitempty" yaml:"redirectRegex,omitempty"`
-	RedirectScheme    *RedirectScheme    `json:"redirectScheme,omitempty" toml:"redirectScheme,omitempty" yaml:"redirectScheme,omitempty"`
-	BasicAuth         *BasicAuth         `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
-	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty"`
-	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty"`
-	InFlightReq       *InFlightReq       `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty"`
-	Buffering         *Buffering         `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty"`
-	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
-	Compress          *Compress          `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty"`
-	PassTLSClientCert *PassTLSClientCert `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty"`
-	Retry             *Retry             `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty"`
-	ContentType       *ContentType       `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty"`
+	AddPrefix               *AddPrefix               `json:"addPrefix,omitempty" toml:"addPrefix,omitempty" yaml:"addPrefix,omitempty"`
+	StripPrefix             *StripPrefix             `json:"stripPrefix,omitempty" toml:"stripPrefix,omitempty" yaml:"stripPrefix,omitempty"`
+	StripPrefixRegex        *StripPrefixRegex        `json:"stripPrefixRegex,omitempty" toml:"stripPrefixRegex,omitempty" yaml:"stripPrefixRegex,omitempty"`
+	ReplacePath             *ReplacePath             `json:"replacePath,omitempty" toml:"replacePath,omitempty" yaml:"replacePath,omitempty"`
+	ReplacePathRegex        *ReplacePathRegex        `json:"replacePathRegex,omitempty" toml:"replacePathRegex,omitempty" yaml:"replacePathRegex,omitempty"`
+	Chain                   *Chain                   `json:"chain,omitempty" toml:"chain,omitempty" yaml:"chain,omitempty"`
+	IPWhiteList             *IPWhiteList             `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty"`
+	Headers                 *Headers                 `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	Errors                  *ErrorPage               `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty"`
+	RateLimit               *RateLimit               `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	RedirectRegex           *RedirectRegex           `json:"redirectRegex,omitempty" toml:"redirectRegex,omitempty" yaml:"redirectRegex,omitempty"`
+	RedirectScheme          *RedirectScheme          `json:"redirectScheme,omitempty" toml:"redirectScheme,omitempty" yaml:"redirectScheme,omitempty"`
+	BasicAuth               *BasicAuth               `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
+	DigestAuth              *DigestAuth              `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty"`
+	ForwardAuth             *ForwardAuth             `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty"`
+	InFlightReq             *InFlightReq             `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty"`
+	Buffering               *Buffering               `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty"`
+	CircuitBreaker          *CircuitBreaker          `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+	Compress                *Compress                `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty"`
+	PassTLSClientCert       *PassTLSClientCert       `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty"`
+	Retry                   *Retry                   `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty"`
+	ContentType             *ContentType             `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty"`
+	EarlyHints              *EarlyHints              `json:"earlyHints,omitempty" toml:"earlyHints,omitempty" yaml:"earlyHints,omitempty"`
+	AltSvc                  *AltSvc                  `json:"altSvc,omitempty" toml:"altSvc,omitempty" yaml:"altSvc,omitempty"`
+	Anomaly                 *Anomaly                 `json:"anomaly,omitempty" toml:"anomaly,omitempty" yaml:"anomaly,omitempty"`
+	Schedule                *Schedule                `json:"schedule,omitempty" toml:"schedule,omitempty" yaml:"schedule,omitempty"`
+	Honeypot                *Honeypot                `json:"honeypot,omitempty" toml:"honeypot,omitempty" yaml:"honeypot,omitempty"`
+	BotManagement           *BotManagement           `json:"botManagement,omitempty" toml:"botManagement,omitempty" yaml:"botManagement,omitempty"`
+	WellKnown               *WellKnown               `json:"wellKnown,omitempty" toml:"wellKnown,omitempty" yaml:"wellKnown,omitempty"`
+	AdaptiveConcurrency     *AdaptiveConcurrency     `json:"adaptiveConcurrency,omitempty" toml:"adaptiveConcurrency,omitempty" yaml:"adaptiveConcurrency,omitempty"`
+	AdmissionControl        *AdmissionControl        `json:"admissionControl,omitempty" toml:"admissionControl,omitempty" yaml:"admissionControl,omitempty"`
+	APIKey                  *APIKey                  `json:"apiKey,omitempty" toml:"apiKey,omitempty" yaml:"apiKey,omitempty"`
+	CSPNonce                *CSPNonce                `json:"cspNonce,omitempty" toml:"cspNonce,omitempty" yaml:"cspNonce,omitempty"`
+	Experiment              *Experiment              `json:"experiment,omitempty" toml:"experiment,omitempty" yaml:"experiment,omitempty"`
+	FaultInjection          *FaultInjection          `json:"faultInjection,omitempty" toml:"faultInjection,omitempty" yaml:"faultInjection,omitempty"`
+	Shadow                  *Shadow                  `json:"shadow,omitempty" toml:"shadow,omitempty" yaml:"shadow,omitempty"`
+	Authorization           *Authorization           `json:"authorization,omitempty" toml:"authorization,omitempty" yaml:"authorization,omitempty"`
+	AWSSigV4                *AWSSigV4                `json:"awsSigV4,omitempty" toml:"awsSigV4,omitempty" yaml:"awsSigV4,omitempty"`
+	OAuth2ClientCredentials *OAuth2ClientCredentials `json:"oauth2ClientCredentials,omitempty" toml:"oauth2ClientCredentials,omitempty" yaml:"oauth2ClientCredentials,omitempty"`
+	RedirectMap             *RedirectMap             `json:"redirectMap,omitempty" toml:"redirectMap,omitempty" yaml:"redirectMap,omitempty"`
+	BandwidthLimit          *BandwidthLimit          `json:"bandwidthLimit,omitempty" toml:"bandwidthLimit,omitempty" yaml:"bandwidthLimit,omitempty"`
+	MethodOverride          *MethodOverride          `json:"methodOverride,omitempty" toml:"methodOverride,omitempty" yaml:"methodOverride,omitempty" label:"allowEmpty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -64,6 +85,268 @@ type AddPrefix struct {
 
 // +k8s:deepcopy-gen=true
 
+// AdaptiveConcurrency limits the number of requests being processed and served concurrently,
+// with a limit continuously adapted to the latency of the responses.
+type AdaptiveConcurrency struct {
+	// Algorithm is the algorithm adapting the limit: gradient (default), or aimd.
+	Algorithm string `json:"algorithm,omitempty" toml:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+
+	// InitialLimit is the limit before the latency of the service has been observed. It defaults to 20.
+	InitialLimit int64 `json:"initialLimit,omitempty" toml:"initialLimit,omitempty" yaml:"initialLimit,omitempty"`
+
+	// MinLimit is the lowest limit. It defaults to 1.
+	MinLimit int64 `json:"minLimit,omitempty" toml:"minLimit,omitempty" yaml:"minLimit,omitempty"`
+
+	// MaxLimit is the highest limit. It defaults to 1000.
+	MaxLimit int64 `json:"maxLimit,omitempty" toml:"maxLimit,omitempty" yaml:"maxLimit,omitempty"`
+
+	// Tolerance is, for the gradient algorithm, how many times the latency can exceed its long-term average
+	// before the limit is decreased. It defaults to 1.5.
+	Tolerance float64 `json:"tolerance,omitempty" toml:"tolerance,omitempty" yaml:"tolerance,omitempty"`
+
+	// LatencyThreshold is, for the aimd algorithm, the latency above which the limit is decreased. It defaults to a second.
+	LatencyThreshold types.Duration `json:"latencyThreshold,omitempty" toml:"latencyThreshold,omitempty" yaml:"latencyThreshold,omitempty"`
+
+	// BackoffRatio is, for the aimd algorithm, the ratio applied to the limit when a request fails,
+	// or exceeds the latency threshold. It defaults to 0.9.
+	BackoffRatio float64 `json:"backoffRatio,omitempty" toml:"backoffRatio,omitempty" yaml:"backoffRatio,omitempty"`
+}
+
+// SetDefaults sets the default values on an AdaptiveConcurrency.
+func (a *AdaptiveConcurrency) SetDefaults() {
+	a.Algorithm = "gradient"
+	a.InitialLimit = 20
+	a.MinLimit = 1
+	a.MaxLimit = 1000
+	a.Tolerance = 1.5
+	a.LatencyThreshold = types.Duration(time.Second)
+	a.BackoffRatio = 0.9
+}
+
+// +k8s:deepcopy-gen=true
+
+// AdmissionControl limits the number of requests being processed and served concurrently,
+// and queues the other ones, admitting the requests of the highest priority first.
+type AdmissionControl struct {
+	// Queue is the name of the admission queue.
+	// The AdmissionControl middlewares with the same queue name share their limit and their queue,
+	// so that a priority can be given to the requests of each router. It defaults to the middleware name.
+	Queue string `json:"queue,omitempty" toml:"queue,omitempty" yaml:"queue,omitempty"`
+
+	// Amount is the maximum number of requests being processed and served concurrently.
+	Amount int64 `json:"amount,omitempty" toml:"amount,omitempty" yaml:"amount,omitempty"`
+
+	// MaxQueueSize is the maximum number of requests waiting to be admitted. It defaults to 100.
+	MaxQueueSize int64 `json:"maxQueueSize,omitempty" toml:"maxQueueSize,omitempty" yaml:"maxQueueSize,omitempty"`
+
+	// MaxWait is the maximum duration a request waits to be admitted. It defaults to 10 seconds.
+	MaxWait types.Duration `json:"maxWait,omitempty" toml:"maxWait,omitempty" yaml:"maxWait,omitempty"`
+
+	// PriorityHeader is the name of the request header holding the priority class of the request.
+	PriorityHeader string `json:"priorityHeader,omitempty" toml:"priorityHeader,omitempty" yaml:"priorityHeader,omitempty"`
+
+	// PriorityClasses maps the priority classes to their priority. The higher the priority, the sooner the request is admitted.
+	PriorityClasses map[string]int64 `json:"priorityClasses,omitempty" toml:"priorityClasses,omitempty" yaml:"priorityClasses,omitempty"`
+
+	// DefaultPriority is the priority of the requests without a known priority class. It defaults to 0.
+	DefaultPriority int64 `json:"defaultPriority,omitempty" toml:"defaultPriority,omitempty" yaml:"defaultPriority,omitempty"`
+
+	// MaxClientShare is, between 0 and 1, the maximum share of the amount a single client can take while the amount is reached.
+	// The requests of a client exceeding its share are then shed, and the queued requests of the other clients are admitted first.
+	// There is no limit per client by default.
+	MaxClientShare float64 `json:"maxClientShare,omitempty" toml:"maxClientShare,omitempty" yaml:"maxClientShare,omitempty"`
+
+	// SourceCriterion defines what identifies the client of a request, for the MaxClientShare. It defaults to the client IP.
+	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty"`
+}
+
+// SetDefaults sets the default values on an AdmissionControl.
+func (a *AdmissionControl) SetDefaults() {
+	a.MaxQueueSize = 100
+	a.MaxWait = types.Duration(10 * time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// AltSvc holds the Alt-Svc advertisement configuration.
+type AltSvc struct {
+	Protocol string `json:"protocol,omitempty" toml:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Port     string `json:"port,omitempty" toml:"port,omitempty" yaml:"port,omitempty"`
+	MaxAge   int    `json:"maxAge,omitempty" toml:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+	Clear    bool   `json:"clear,omitempty" toml:"clear,omitempty" yaml:"clear,omitempty"`
+}
+
+// SetDefaults sets the default values on an AltSvc.
+func (a *AltSvc) SetDefaults() {
+	a.Protocol = "h2"
+	a.MaxAge = 86400
+}
+
+// +k8s:deepcopy-gen=true
+
+// Anomaly holds the request rate spike detection configuration.
+type Anomaly struct {
+	// Factor is how many times the request rate of a source has to exceed its recent baseline
+	// for the source to be blocked. It defaults to 10.
+	Factor float64 `json:"factor,omitempty" toml:"factor,omitempty" yaml:"factor,omitempty"`
+
+	// Period is the duration over which the request rate of a source is measured.
+	// The baseline is a moving average of the rates measured over the previous periods.
+	// It defaults to a second.
+	Period types.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty"`
+
+	// MinRequests is the number of requests, within a period, below which a source is never blocked.
+	// It defaults to 10.
+	MinRequests int64 `json:"minRequests,omitempty" toml:"minRequests,omitempty" yaml:"minRequests,omitempty"`
+
+	// BlockDuration is how long a source is blocked once its request rate has been detected as anomalous.
+	// It defaults to a minute.
+	BlockDuration types.Duration `json:"blockDuration,omitempty" toml:"blockDuration,omitempty" yaml:"blockDuration,omitempty"`
+
+	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty"`
+}
+
+// SetDefaults sets the default values on an Anomaly.
+func (a *Anomaly) SetDefaults() {
+	a.Factor = 10
+	a.Period = types.Duration(time.Second)
+	a.MinRequests = 10
+	a.BlockDuration = types.Duration(time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
+// APIKey holds the API key authentication configuration.
+// The keys are looked up in a store, which holds the metadata attached to the requests authenticated with each key.
+type APIKey struct {
+	// HeaderName is the name of the request header holding the API key. It defaults to X-API-Key.
+	HeaderName string `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty"`
+
+	// QueryParameterName is the name of the query parameter holding the API key, when the header is missing.
+	QueryParameterName string `json:"queryParameterName,omitempty" toml:"queryParameterName,omitempty" yaml:"queryParameterName,omitempty"`
+
+	// RemoveHeader removes the header holding the API key from the forwarded request.
+	RemoveHeader bool `json:"removeHeader,omitempty" toml:"removeHeader,omitempty" yaml:"removeHeader,omitempty"`
+
+	// File, KV, and Redis are the key stores, only one of them can be defined.
+	File  *APIKeyFile  `json:"file,omitempty" toml:"file,omitempty" yaml:"file,omitempty"`
+	KV    *APIKeyKV    `json:"kv,omitempty" toml:"kv,omitempty" yaml:"kv,omitempty"`
+	Redis *APIKeyRedis `json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty"`
+}
+
+// SetDefaults sets the default values on an APIKey.
+func (a *APIKey) SetDefaults() {
+	a.HeaderName = "X-API-Key"
+}
+
+// +k8s:deepcopy-gen=true
+
+// APIKeyFile is an API key store backed by a JSON or YAML file.
+type APIKeyFile struct {
+	Filename string `json:"filename,omitempty" toml:"filename,omitempty" yaml:"filename,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// APIKeyKV is an API key store backed by a KV store.
+type APIKeyKV struct {
+	// Backend is the type of KV store: consul, etcd, or zookeeper.
+	Backend   string     `json:"backend,omitempty" toml:"backend,omitempty" yaml:"backend,omitempty"`
+	Endpoints []string   `json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	RootKey   string     `json:"rootKey,omitempty" toml:"rootKey,omitempty" yaml:"rootKey,omitempty"`
+	Username  string     `json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password  string     `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" secret:"true"`
+	TLS       *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+// SetDefaults sets the default values on an APIKeyKV.
+func (a *APIKeyKV) SetDefaults() {
+	a.RootKey = "traefik/apikeys"
+}
+
+// +k8s:deepcopy-gen=true
+
+// APIKeyRedis is an API key store backed by Redis.
+type APIKeyRedis struct {
+	Endpoints []string   `json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	RootKey   string     `json:"rootKey,omitempty" toml:"rootKey,omitempty" yaml:"rootKey,omitempty"`
+	Password  string     `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" secret:"true"`
+	TLS       *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+// SetDefaults sets the default values on an APIKeyRedis.
+func (a *APIKeyRedis) SetDefaults() {
+	a.RootKey = "traefik/apikeys"
+}
+
+// +k8s:deepcopy-gen=true
+
+// Authorization holds the authorization configuration.
+// The requests are allowed according to a policy, mapping the identities of the clients to the allowed methods and paths.
+type Authorization struct {
+	// Identity is the source of the identity of the clients: tlsClientCert, jwt, or apiKey.
+	Identity string `json:"identity,omitempty" toml:"identity,omitempty" yaml:"identity,omitempty"`
+
+	// JWT holds the verification of the JWT, and the claim holding the identity, when the identity source is jwt.
+	JWT *AuthorizationJWT `json:"jwt,omitempty" toml:"jwt,omitempty" yaml:"jwt,omitempty"`
+
+	// APIKeyField is the field of the API key holding the identity (key, tenant, or plan), when the identity source is apiKey.
+	APIKeyField string `json:"apiKeyField,omitempty" toml:"apiKeyField,omitempty" yaml:"apiKeyField,omitempty"`
+
+	// Policy is the list of the rules allowing the requests, each one in the "<identities> <methods> <path>" format.
+	Policy []string `json:"policy,omitempty" toml:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// SetDefaults sets the default values on an Authorization.
+func (a *Authorization) SetDefaults() {
+	a.APIKeyField = "tenant"
+}
+
+// +k8s:deepcopy-gen=true
+
+// AuthorizationJWT holds the verification of the JWT identifying the clients.
+// The JWT is signed with a secret (HMAC), or with the private key of the given public key (RSA, ECDSA).
+type AuthorizationJWT struct {
+	HeaderName    string `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty"`
+	Claim         string `json:"claim,omitempty" toml:"claim,omitempty" yaml:"claim,omitempty"`
+	Secret        string `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty" secret:"true"`
+	PublicKeyFile string `json:"publicKeyFile,omitempty" toml:"publicKeyFile,omitempty" yaml:"publicKeyFile,omitempty"`
+	Issuer        string `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Audience      string `json:"audience,omitempty" toml:"audience,omitempty" yaml:"audience,omitempty"`
+}
+
+// SetDefaults sets the default values on an AuthorizationJWT.
+func (a *AuthorizationJWT) SetDefaults() {
+	a.HeaderName = "Authorization"
+	a.Claim = "sub"
+}
+
+// +k8s:deepcopy-gen=true
+
+// AWSSigV4 holds the AWS Signature Version 4 configuration, signing the requests forwarded to AWS services.
+// The credentials are the static ones, or the ones of the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN environment variables,
+// or the ones of the web identity of AWS_WEB_IDENTITY_TOKEN_FILE and AWS_ROLE_ARN (IAM roles for service accounts),
+// and they are used to assume the RoleARN role when it is defined.
+type AWSSigV4 struct {
+	Service         string `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty"`
+	Region          string `json:"region,omitempty" toml:"region,omitempty" yaml:"region,omitempty"`
+	Host            string `json:"host,omitempty" toml:"host,omitempty" yaml:"host,omitempty"`
+	UnsignedPayload bool   `json:"unsignedPayload,omitempty" toml:"unsignedPayload,omitempty" yaml:"unsignedPayload,omitempty"`
+	AccessKeyID     string `json:"accessKeyID,omitempty" toml:"accessKeyID,omitempty" yaml:"accessKeyID,omitempty" secret:"true"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty" toml:"secretAccessKey,omitempty" yaml:"secretAccessKey,omitempty" secret:"true"`
+	SessionToken    string `json:"sessionToken,omitempty" toml:"sessionToken,omitempty" yaml:"sessionToken,omitempty" secret:"true"`
+	RoleARN         string `json:"roleARN,omitempty" toml:"roleARN,omitempty" yaml:"roleARN,omitempty"`
+	RoleSessionName string `json:"roleSessionName,omitempty" toml:"roleSessionName,omitempty" yaml:"roleSessionName,omitempty"`
+	STSEndpoint     string `json:"stsEndpoint,omitempty" toml:"stsEndpoint,omitempty" yaml:"stsEndpoint,omitempty"`
+}
+
+// SetDefaults sets the default values on an AWSSigV4.
+func (a *AWSSigV4) SetDefaults() {
+	a.RoleSessionName = "traefik"
+}
+
+// +k8s:deepcopy-gen=true
+
 // Auth holds the authentication configuration (BASIC, DIGEST, users).
 type Auth struct {
 	Basic   *BasicAuth   `json:"basic,omitempty" toml:"basic,omitempty" yaml:"basic,omitempty" export:"true"`
@@ -73,9 +356,24 @@ type Auth struct {
 
 // +k8s:deepcopy-gen=true
 
+// BandwidthLimit holds the bandwidth limit configuration.
+// This middleware limits the aggregate throughput of the response bodies of a router,
+// queueing the writes exceeding it, and trimming the responses waiting too long.
+type BandwidthLimit struct {
+	// Rate is the maximum aggregate throughput of the response bodies, in bytes per second.
+	Rate int64 `json:"rate,omitempty" toml:"rate,omitempty" yaml:"rate,omitempty"`
+	// Burst is the maximum number of bytes written at once above the rate. It defaults to the rate.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty"`
+	// MaxDelay is the maximum duration a write waits for bandwidth, beyond which the response is trimmed.
+	// It defaults to 0, which means that the writes wait as long as needed.
+	MaxDelay types.Duration `json:"maxDelay,omitempty" toml:"maxDelay,omitempty" yaml:"maxDelay,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // BasicAuth holds the HTTP basic authentication configuration.
 type BasicAuth struct {
-	Users        Users  `json:"users,omitempty" toml:"users,omitempty" yaml:"users,omitempty"`
+	Users        Users  `json:"users,omitempty" toml:"users,omitempty" yaml:"users,omitempty" secret:"true"`
 	UsersFile    string `json:"usersFile,omitempty" toml:"usersFile,omitempty" yaml:"usersFile,omitempty"`
 	Realm        string `json:"realm,omitempty" toml:"realm,omitempty" yaml:"realm,omitempty"`
 	RemoveHeader bool   `json:"removeHeader,omitempty" toml:"removeHeader,omitempty" yaml:"removeHeader,omitempty"`
@@ -84,6 +382,61 @@ type BasicAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// BotManagement holds the bot management configuration.
+type BotManagement struct {
+	// Threshold is the score from which a request is considered as coming from a bot. It defaults to 50.
+	Threshold int `json:"threshold,omitempty" toml:"threshold,omitempty" yaml:"threshold,omitempty"`
+
+	// UserAgents is a list of regular expressions matching the user agents of bots, in addition to the built-in ones.
+	UserAgents []string `json:"userAgents,omitempty" toml:"userAgents,omitempty" yaml:"userAgents,omitempty"`
+
+	// AllowedUserAgents is a list of regular expressions matching the user agents which are never considered as bots.
+	AllowedUserAgents []string `json:"allowedUserAgents,omitempty" toml:"allowedUserAgents,omitempty" yaml:"allowedUserAgents,omitempty"`
+
+	// Action is what is done with the requests coming from bots: allow, block, throttle, or tag. It defaults to block.
+	Action string `json:"action,omitempty" toml:"action,omitempty" yaml:"action,omitempty"`
+
+	// Header is the name of the request header holding the score, set by the tag action. It defaults to X-Bot-Score.
+	Header string `json:"header,omitempty" toml:"header,omitempty" yaml:"header,omitempty"`
+
+	// ThrottleDelay is how long the requests are held by the throttle action. It defaults to a second.
+	ThrottleDelay types.Duration `json:"throttleDelay,omitempty" toml:"throttleDelay,omitempty" yaml:"throttleDelay,omitempty"`
+
+	Challenge  *BotChallenge `json:"challenge,omitempty" toml:"challenge,omitempty" yaml:"challenge,omitempty" label:"allowEmpty"`
+	IPStrategy *IPStrategy   `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty"`
+}
+
+// SetDefaults sets the default values on a BotManagement.
+func (b *BotManagement) SetDefaults() {
+	b.Threshold = 50
+	b.Action = "block"
+	b.Header = "X-Bot-Score"
+	b.ThrottleDelay = types.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// BotChallenge holds the JavaScript challenge configuration of the bot management.
+type BotChallenge struct {
+	// Secret is the key used to sign the challenge cookies.
+	// If empty, a random one is generated, and the cookies do not survive the configuration reloads.
+	Secret string `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty" secret:"true"`
+
+	// CookieName is the name of the challenge cookie. It defaults to _traefik_bot.
+	CookieName string `json:"cookieName,omitempty" toml:"cookieName,omitempty" yaml:"cookieName,omitempty"`
+
+	// MaxAge is how long a solved challenge is valid. It defaults to an hour.
+	MaxAge types.Duration `json:"maxAge,omitempty" toml:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+}
+
+// SetDefaults sets the default values on a BotChallenge.
+func (b *BotChallenge) SetDefaults() {
+	b.CookieName = "_traefik_bot"
+	b.MaxAge = types.Duration(time.Hour)
+}
+
+// +k8s:deepcopy-gen=true
+
 // Buffering holds the request/response buffering configuration.
 type Buffering struct {
 	MaxRequestBodyBytes  int64  `json:"maxRequestBodyBytes,omitempty" toml:"maxRequestBodyBytes,omitempty" yaml:"maxRequestBodyBytes,omitempty"`
@@ -116,9 +469,29 @@ type Compress struct {
 
 // +k8s:deepcopy-gen=true
 
+// CSPNonce holds the CSP nonce configuration.
+type CSPNonce struct {
+	// Policy is the Content-Security-Policy of the responses,
+	// where the {nonce} placeholders are replaced with the nonce generated for the request.
+	Policy string `json:"policy,omitempty" toml:"policy,omitempty" yaml:"policy,omitempty"`
+	// ReportOnly sends the policy in the Content-Security-Policy-Report-Only header instead of enforcing it.
+	ReportOnly bool `json:"reportOnly,omitempty" toml:"reportOnly,omitempty" yaml:"reportOnly,omitempty"`
+	// HeaderName is the request header forwarding the nonce to the backend.
+	HeaderName string `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty"`
+	// InjectScripts adds the nonce to the script tags of the HTML responses carrying the data-csp-nonce placeholder attribute.
+	InjectScripts bool `json:"injectScripts,omitempty" toml:"injectScripts,omitempty" yaml:"injectScripts,omitempty"`
+}
+
+// SetDefaults sets the default values.
+func (c *CSPNonce) SetDefaults() {
+	c.HeaderName = "X-CSP-Nonce"
+}
+
+// +k8s:deepcopy-gen=true
+
 // DigestAuth holds the Digest HTTP authentication configuration.
 type DigestAuth struct {
-	Users        Users  `json:"users,omitempty" toml:"users,omitempty" yaml:"users,omitempty"`
+	Users        Users  `json:"users,omitempty" toml:"users,omitempty" yaml:"users,omitempty" secret:"true"`
 	UsersFile    string `json:"usersFile,omitempty" toml:"usersFile,omitempty" yaml:"usersFile,omitempty"`
 	RemoveHeader bool   `json:"removeHeader,omitempty" toml:"removeHeader,omitempty" yaml:"removeHeader,omitempty"`
 	Realm        string `json:"realm,omitempty" toml:"realm,omitempty" yaml:"realm,omitempty"`
@@ -127,6 +500,13 @@ type DigestAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// EarlyHints holds the early hints configuration.
+type EarlyHints struct {
+	Links []string `json:"links,omitempty" toml:"links,omitempty" yaml:"links,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // ErrorPage holds the custom error page configuration.
 type ErrorPage struct {
 	Status  []string `json:"status,omitempty" toml:"status,omitempty" yaml:"status,omitempty"`
@@ -136,12 +516,115 @@ type ErrorPage struct {
 
 // +k8s:deepcopy-gen=true
 
+// Experiment holds the A/B testing experiment configuration.
+type Experiment struct {
+	// Name is the name of the experiment, which salts the bucketing of the users and labels the metrics.
+	// It defaults to the middleware name.
+	Name     string              `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
+	Variants []ExperimentVariant `json:"variants,omitempty" toml:"variants,omitempty" yaml:"variants,omitempty"`
+	// HeaderName is the request header holding the user ID. If absent from the request, the cookie is used.
+	HeaderName string `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty"`
+	// CookieName is the cookie holding the user ID, which is generated and set when the request has none.
+	CookieName string `json:"cookieName,omitempty" toml:"cookieName,omitempty" yaml:"cookieName,omitempty"`
+	// VariantHeaderName is the request header carrying the variant assigned to the user to the backend.
+	VariantHeaderName string `json:"variantHeaderName,omitempty" toml:"variantHeaderName,omitempty" yaml:"variantHeaderName,omitempty"`
+	// ExposeVariant adds the variant header to the response too.
+	ExposeVariant bool `json:"exposeVariant,omitempty" toml:"exposeVariant,omitempty" yaml:"exposeVariant,omitempty"`
+}
+
+// SetDefaults sets the default values.
+func (e *Experiment) SetDefaults() {
+	e.CookieName = "traefik_experiment_id"
+	e.VariantHeaderName = "X-Experiment-Variant"
+}
+
+// +k8s:deepcopy-gen=true
+
+// ExperimentVariant holds a variant of an experiment.
+type ExperimentVariant struct {
+	Name string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
+	// Weight is the share of the users assigned to the variant, relatively to the weights of the other variants.
+	Weight *int `json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// SetDefaults sets the default values.
+func (e *ExperimentVariant) SetDefaults() {
+	defaultWeight := 1
+	e.Weight = &defaultWeight
+}
+
+// +k8s:deepcopy-gen=true
+
+// FaultInjection holds the fault injection configuration.
+// The faults are only injected when allowed in the static configuration.
+type FaultInjection struct {
+	Delay *FaultDelay `json:"delay,omitempty" toml:"delay,omitempty" yaml:"delay,omitempty"`
+	Abort *FaultAbort `json:"abort,omitempty" toml:"abort,omitempty" yaml:"abort,omitempty"`
+	Reset *FaultReset `json:"reset,omitempty" toml:"reset,omitempty" yaml:"reset,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// FaultDelay holds the configuration of the delay added before forwarding the requests.
+type FaultDelay struct {
+	Duration types.Duration `json:"duration,omitempty" toml:"duration,omitempty" yaml:"duration,omitempty"`
+	// Percentage is the share of the requests delayed, from 0 to 100.
+	Percentage float64 `json:"percentage,omitempty" toml:"percentage,omitempty" yaml:"percentage,omitempty"`
+}
+
+// SetDefaults sets the default values on a FaultDelay.
+func (f *FaultDelay) SetDefaults() {
+	f.Percentage = 100
+}
+
+// +k8s:deepcopy-gen=true
+
+// FaultAbort holds the configuration of the requests answered with an error status instead of being forwarded.
+type FaultAbort struct {
+	StatusCode int `json:"statusCode,omitempty" toml:"statusCode,omitempty" yaml:"statusCode,omitempty"`
+	// Percentage is the share of the requests aborted, from 0 to 100.
+	Percentage float64 `json:"percentage,omitempty" toml:"percentage,omitempty" yaml:"percentage,omitempty"`
+}
+
+// SetDefaults sets the default values on a FaultAbort.
+func (f *FaultAbort) SetDefaults() {
+	f.StatusCode = http.StatusServiceUnavailable
+	f.Percentage = 100
+}
+
+// +k8s:deepcopy-gen=true
+
+// FaultReset holds the configuration of the requests whose connection is reset instead of being forwarded.
+type FaultReset struct {
+	// Percentage is the share of the requests reset, from 0 to 100.
+	Percentage float64 `json:"percentage,omitempty" toml:"percentage,omitempty" yaml:"percentage,omitempty"`
+}
+
+// SetDefaults sets the default values on a FaultReset.
+func (f *FaultReset) SetDefaults() {
+	f.Percentage = 100
+}
+
+// +k8s:deepcopy-gen=true
+
 // ForwardAuth holds the http forward authentication configuration.
 type ForwardAuth struct {
 	Address             string     `json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
 	TLS                 *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
 	TrustForwardHeader  bool       `json:"trustForwardHeader,omitempty" toml:"trustForwardHeader,omitempty" yaml:"trustForwardHeader,omitempty" export:"true"`
 	AuthResponseHeaders []string   `json:"authResponseHeaders,omitempty" toml:"authResponseHeaders,omitempty" yaml:"authResponseHeaders,omitempty"`
+
+	// Protocol is the protocol of the authentication server: http, or grpc for the Envoy external authorization API (ext_authz).
+	Protocol string `json:"protocol,omitempty" toml:"protocol,omitempty" yaml:"protocol,omitempty" export:"true"`
+	// ContextExtensions are sent to the external authorization server in the context extensions of the check requests.
+	ContextExtensions map[string]string `json:"contextExtensions,omitempty" toml:"contextExtensions,omitempty" yaml:"contextExtensions,omitempty"`
+
+	// ClientResponseHeaders is the list of the headers to copy from the authentication server response to the response sent to the client.
+	ClientResponseHeaders []string `json:"clientResponseHeaders,omitempty" toml:"clientResponseHeaders,omitempty" yaml:"clientResponseHeaders,omitempty"`
+	// RequestHeaderTemplates are the headers set on the request, with values built from the headers of the authentication server response.
+	RequestHeaderTemplates map[string]string `json:"requestHeaderTemplates,omitempty" toml:"requestHeaderTemplates,omitempty" yaml:"requestHeaderTemplates,omitempty"`
+	// ResponseHeaderTemplates are the headers added to the response sent to the client, with values built from the headers of the authentication server response.
+	ResponseHeaderTemplates map[string]string `json:"responseHeaderTemplates,omitempty" toml:"responseHeaderTemplates,omitempty" yaml:"responseHeaderTemplates,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -189,6 +672,10 @@ type Headers struct {
 	ReferrerPolicy          string            `json:"referrerPolicy,omitempty" toml:"referrerPolicy,omitempty" yaml:"referrerPolicy,omitempty"`
 	FeaturePolicy           string            `json:"featurePolicy,omitempty" toml:"featurePolicy,omitempty" yaml:"featurePolicy,omitempty"`
 	IsDevelopment           bool              `json:"isDevelopment,omitempty" toml:"isDevelopment,omitempty" yaml:"isDevelopment,omitempty"`
+
+	// SecurityPreset is the name of a curated set of security headers (basic or strict),
+	// applied to the options which are not set.
+	SecurityPreset string `json:"securityPreset,omitempty" toml:"securityPreset,omitempty" yaml:"securityPreset,omitempty"`
 }
 
 // HasCustomHeadersDefined checks to see if any of the custom header elements have been set
@@ -235,6 +722,37 @@ func (h *Headers) HasSecureHeadersDefined() bool {
 
 // +k8s:deepcopy-gen=true
 
+// Honeypot holds the honeypot configuration.
+type Honeypot struct {
+	// Patterns is the list of regular expressions matched against the request path to detect the exploit attempts.
+	// If empty, a built-in list of common exploit paths is used.
+	Patterns []string `json:"patterns,omitempty" toml:"patterns,omitempty" yaml:"patterns,omitempty"`
+
+	// Delay is how long the response to a matching request is held (tarpit). It defaults to 10 seconds.
+	Delay types.Duration `json:"delay,omitempty" toml:"delay,omitempty" yaml:"delay,omitempty"`
+
+	// StatusCode is the status code of the fake response. It defaults to 404.
+	StatusCode int `json:"statusCode,omitempty" toml:"statusCode,omitempty" yaml:"statusCode,omitempty"`
+
+	// Body is the body of the fake response.
+	Body string `json:"body,omitempty" toml:"body,omitempty" yaml:"body,omitempty"`
+
+	// BlockDuration is how long a client having requested a matching path is tagged,
+	// during which all of its requests are handled as exploit attempts. It defaults to 10 minutes.
+	BlockDuration types.Duration `json:"blockDuration,omitempty" toml:"blockDuration,omitempty" yaml:"blockDuration,omitempty"`
+
+	IPStrategy *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty"`
+}
+
+// SetDefaults sets the default values on a Honeypot.
+func (h *Honeypot) SetDefaults() {
+	h.Delay = types.Duration(10 * time.Second)
+	h.StatusCode = http.StatusNotFound
+	h.BlockDuration = types.Duration(10 * time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
 // IPStrategy holds the ip strategy configuration.
 type IPStrategy struct {
 	Depth       int      `json:"depth,omitempty" toml:"depth,omitempty" yaml:"depth,omitempty" export:"true"`
@@ -288,6 +806,54 @@ type InFlightReq struct {
 
 // +k8s:deepcopy-gen=true
 
+// MethodOverride holds the method override configuration.
+// This middleware honors or strips the method override headers of the requests,
+// and optionally answers the HEAD requests from the GET responses of the backend.
+type MethodOverride struct {
+	// Policy is what is done with the method override headers of the POST requests:
+	// strip (default) removes them, and honor overrides the method of the requests with their value before removing them.
+	Policy string `json:"policy,omitempty" toml:"policy,omitempty" yaml:"policy,omitempty" export:"true"`
+	// Headers are the method override headers.
+	// They default to X-HTTP-Method-Override, X-HTTP-Method, and X-Method-Override.
+	Headers []string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	// AllowedMethods are the methods a request can be overridden to. They default to DELETE, PATCH, and PUT.
+	AllowedMethods []string `json:"allowedMethods,omitempty" toml:"allowedMethods,omitempty" yaml:"allowedMethods,omitempty"`
+	// SynthesizeHead forwards the HEAD requests as GET requests, and discards the bodies of the responses.
+	SynthesizeHead bool `json:"synthesizeHead,omitempty" toml:"synthesizeHead,omitempty" yaml:"synthesizeHead,omitempty" export:"true"`
+}
+
+// OAuth2ClientCredentials holds the OAuth2 client credentials configuration,
+// injecting the access token obtained by the client into the requests forwarded to the backend.
+type OAuth2ClientCredentials struct {
+	TokenURL     string     `json:"tokenURL,omitempty" toml:"tokenURL,omitempty" yaml:"tokenURL,omitempty"`
+	ClientID     string     `json:"clientID,omitempty" toml:"clientID,omitempty" yaml:"clientID,omitempty"`
+	ClientSecret string     `json:"clientSecret,omitempty" toml:"clientSecret,omitempty" yaml:"clientSecret,omitempty" secret:"true"`
+	Scopes       []string   `json:"scopes,omitempty" toml:"scopes,omitempty" yaml:"scopes,omitempty"`
+	TLS          *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+
+	// EndpointParams are the additional parameters of the token requests, such as the audience.
+	EndpointParams map[string]string `json:"endpointParams,omitempty" toml:"endpointParams,omitempty" yaml:"endpointParams,omitempty"`
+
+	// AuthStyle is how the client authenticates to the token endpoint:
+	// header for the HTTP basic authentication (default), or params for the client_id and client_secret parameters.
+	AuthStyle string `json:"authStyle,omitempty" toml:"authStyle,omitempty" yaml:"authStyle,omitempty" export:"true"`
+
+	// HeaderName is the request header holding the token. It defaults to Authorization, with the Bearer prefix.
+	HeaderName string `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty" export:"true"`
+
+	// RefreshBefore is how long before its expiration the token is renewed. It defaults to 1 minute.
+	RefreshBefore types.Duration `json:"refreshBefore,omitempty" toml:"refreshBefore,omitempty" yaml:"refreshBefore,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an OAuth2ClientCredentials.
+func (o *OAuth2ClientCredentials) SetDefaults() {
+	o.AuthStyle = "header"
+	o.HeaderName = "Authorization"
+	o.RefreshBefore = types.Duration(time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
 // PassTLSClientCert holds the TLS client cert headers configuration.
 type PassTLSClientCert struct {
 	PEM  bool                      `json:"pem,omitempty" toml:"pem,omitempty" yaml:"pem,omitempty"`
@@ -303,6 +869,9 @@ type SourceCriterion struct {
 	IPStrategy        *IPStrategy `json:"ipStrategy" toml:"ipStrategy, omitempty"`
 	RequestHeaderName string      `json:"requestHeaderName,omitempty" toml:"requestHeaderName,omitempty" yaml:"requestHeaderName,omitempty"`
 	RequestHost       bool        `json:"requestHost,omitempty" toml:"requestHost,omitempty" yaml:"requestHost,omitempty"`
+	// APIKey groups the requests by the key authenticated by an APIKey middleware (key),
+	// or by the tenant (tenant) or the plan (plan) of the key.
+	APIKey string `json:"apiKey,omitempty" toml:"apiKey,omitempty" yaml:"apiKey,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -334,6 +903,70 @@ func (r *RateLimit) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// RedirectMap holds the redirect map configuration.
+// The requests matching an entry of the map are redirected to its target, or answered as gone, without reaching the service.
+type RedirectMap struct {
+	// StatusCode is the status code of the entries without one: 301, 302, 307, 308, or 410.
+	StatusCode int `json:"statusCode,omitempty" toml:"statusCode,omitempty" yaml:"statusCode,omitempty"`
+
+	// Entries are the entries of the map declared in the dynamic configuration.
+	Entries []RedirectMapEntry `json:"entries,omitempty" toml:"entries,omitempty" yaml:"entries,omitempty"`
+
+	// File and KV are the sources of the large maps, reloaded when they change, only one of them can be defined.
+	File *RedirectMapFile `json:"file,omitempty" toml:"file,omitempty" yaml:"file,omitempty"`
+	KV   *RedirectMapKV   `json:"kv,omitempty" toml:"kv,omitempty" yaml:"kv,omitempty"`
+}
+
+// SetDefaults sets the default values on a RedirectMap.
+func (r *RedirectMap) SetDefaults() {
+	r.StatusCode = http.StatusMovedPermanently
+}
+
+// +k8s:deepcopy-gen=true
+
+// RedirectMapEntry is an entry of a redirect map.
+type RedirectMapEntry struct {
+	// Host is the host matched by the entry, any host when empty.
+	Host string `json:"host,omitempty" toml:"host,omitempty" yaml:"host,omitempty"`
+	// Path is the path matched by the entry.
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`
+	// Prefix makes the entry match the paths under Path as well, whose remainder is appended to the target.
+	Prefix bool `json:"prefix,omitempty" toml:"prefix,omitempty" yaml:"prefix,omitempty"`
+	// Target is the URL, or the path, the requests are redirected to. It is ignored with the status code 410.
+	Target     string `json:"target,omitempty" toml:"target,omitempty" yaml:"target,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty" toml:"statusCode,omitempty" yaml:"statusCode,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RedirectMapFile is a redirect map source backed by a JSON, YAML, or CSV file.
+type RedirectMapFile struct {
+	Filename string `json:"filename,omitempty" toml:"filename,omitempty" yaml:"filename,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RedirectMapKV is a redirect map source backed by a KV store.
+type RedirectMapKV struct {
+	// Backend is the type of KV store: consul, etcd, zookeeper, or redis.
+	Backend   string     `json:"backend,omitempty" toml:"backend,omitempty" yaml:"backend,omitempty"`
+	Endpoints []string   `json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	RootKey   string     `json:"rootKey,omitempty" toml:"rootKey,omitempty" yaml:"rootKey,omitempty"`
+	Username  string     `json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password  string     `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" secret:"true"`
+	TLS       *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+	// RefreshInterval is the interval between two loads of the entries.
+	RefreshInterval types.Duration `json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty"`
+}
+
+// SetDefaults sets the default values on a RedirectMapKV.
+func (r *RedirectMapKV) SetDefaults() {
+	r.RootKey = "traefik/redirects"
+	r.RefreshInterval = types.Duration(30 * time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
 // RedirectRegex holds the redirection configuration.
 type RedirectRegex struct {
 	Regex       string `json:"regex,omitempty" toml:"regex,omitempty" yaml:"regex,omitempty"`
@@ -374,6 +1007,35 @@ type Retry struct {
 
 // +k8s:deepcopy-gen=true
 
+// Schedule holds the time of day access control configuration.
+type Schedule struct {
+	// Timezone is the IANA name of the time zone in which the windows are expressed.
+	// It defaults to UTC.
+	Timezone string `json:"timezone,omitempty" toml:"timezone,omitempty" yaml:"timezone,omitempty"`
+
+	// Allow is the list of windows during which the requests are allowed.
+	// A window has the form "[days] HH:MM-HH:MM", e.g. "Mon-Fri 08:00-18:00" or "Sat,Sun 22:00-02:00".
+	// If empty, the requests are allowed at any time outside of the Deny windows.
+	Allow []string `json:"allow,omitempty" toml:"allow,omitempty" yaml:"allow,omitempty"`
+
+	// Deny is the list of windows during which the requests are denied.
+	// It takes precedence over Allow.
+	Deny []string `json:"deny,omitempty" toml:"deny,omitempty" yaml:"deny,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Shadow holds the shadow evaluation configuration.
+type Shadow struct {
+	// Middlewares are evaluated on a copy of each request, and what they would have done is only reported.
+	Middlewares []string `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	// MaxBodySize is the maximum size of the request bodies copied for the shadowed middlewares.
+	// The larger bodies, as well as all the bodies when it is zero, are not given to the shadowed middlewares.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // StripPrefix holds the StripPrefix configuration.
 type StripPrefix struct {
 	Prefixes   []string `json:"prefixes,omitempty" toml:"prefixes,omitempty" yaml:"prefixes,omitempty"`
@@ -425,13 +1087,39 @@ type Users []string
 
 // +k8s:deepcopy-gen=true
 
+// WellKnown holds the configuration of the well-known files (robots.txt, security.txt, ...) served by the proxy.
+type WellKnown struct {
+	// RobotsTxt is the content of /robots.txt.
+	RobotsTxt string `json:"robotsTxt,omitempty" toml:"robotsTxt,omitempty" yaml:"robotsTxt,omitempty"`
+
+	// SecurityTxt is the content of /.well-known/security.txt (and /security.txt).
+	SecurityTxt string `json:"securityTxt,omitempty" toml:"securityTxt,omitempty" yaml:"securityTxt,omitempty"`
+
+	Files []WellKnownFile `json:"files,omitempty" toml:"files,omitempty" yaml:"files,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// WellKnownFile holds the definition of a file served by the WellKnown middleware.
+type WellKnownFile struct {
+	// Path is the path of the file, e.g. /.well-known/assetlinks.json.
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`
+
+	Content string `json:"content,omitempty" toml:"content,omitempty" yaml:"content,omitempty"`
+
+	// ContentType is the media type of the file. It defaults to the one associated with the path extension, or text/plain.
+	ContentType string `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // ClientTLS holds the TLS specific configurations as client
 // CA, Cert and Key can be either path or file contents.
 type ClientTLS struct {
 	CA                 string `json:"ca,omitempty" toml:"ca,omitempty" yaml:"ca,omitempty"`
 	CAOptional         bool   `json:"caOptional,omitempty" toml:"caOptional,omitempty" yaml:"caOptional,omitempty"`
 	Cert               string `json:"cert,omitempty" toml:"cert,omitempty" yaml:"cert,omitempty"`
-	Key                string `json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty"`
+	Key                string `json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty" secret:"true"`
 	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
 }
 
@@ -27,6 +27,12 @@ type TCPService struct {
 // TCPWeightedRoundRobin is a weighted round robin tcp load-balancer of services.
 type TCPWeightedRoundRobin struct {
 	Services []TCPWRRService `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
+	// DrainTimeout is the duration after which the connections are closed once the services or their weights change,
+	// so that they are balanced with the current weights (default: 0, the connections are not closed).
+	DrainTimeout types.Duration `json:"drainTimeout,omitempty" toml:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty"`
+	// DrainIdleTimeout is the duration after which the idle connections are closed once the services or their weights change,
+	// before the DrainTimeout (default: 0, the connections are closed at the DrainTimeout).
+	DrainIdleTimeout types.Duration `json:"drainIdleTimeout,omitempty" toml:"drainIdleTimeout,omitempty" yaml:"drainIdleTimeout,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -74,6 +80,18 @@ type TCPServersLoadBalancer struct {
 	// means an infinite deadline (i.e. the reading capability is never closed).
 	TerminationDelay *int        `json:"terminationDelay,omitempty" toml:"terminationDelay,omitempty" yaml:"terminationDelay,omitempty"`
 	Servers          []TCPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server"`
+	// IdleTimeout is the duration after which the connections without any bytes read or written are closed (default: 0, no timeout).
+	IdleTimeout types.Duration `json:"idleTimeout,omitempty" toml:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty"`
+	// MaxLifetime is the duration after which the connections are closed, whatever their activity (default: 0, no limit).
+	MaxLifetime types.Duration `json:"maxLifetime,omitempty" toml:"maxLifetime,omitempty" yaml:"maxLifetime,omitempty"`
+	// MaxConnections is the maximum number of concurrent connections, beyond which the new connections are closed (default: 0, no limit).
+	MaxConnections int `json:"maxConnections,omitempty" toml:"maxConnections,omitempty" yaml:"maxConnections,omitempty"`
+	// DrainTimeout is the duration after which the connections are closed once the service configuration changes,
+	// so that they do not stay on the previous servers (default: 0, the connections are not closed).
+	DrainTimeout types.Duration `json:"drainTimeout,omitempty" toml:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty"`
+	// DrainIdleTimeout is the duration after which the idle connections are closed once the service configuration changes,
+	// before the DrainTimeout (default: 0, the connections are closed at the DrainTimeout).
+	DrainIdleTimeout types.Duration `json:"drainIdleTimeout,omitempty" toml:"drainIdleTimeout,omitempty" yaml:"drainIdleTimeout,omitempty"`
 }
 
 // SetDefaults Default values for a TCPServersLoadBalancer
@@ -4,8 +4,12 @@ package label
 import (
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/parser"
+	"github.com/containous/traefik/v2/pkg/config/schema"
 )
 
+// filters are the prefixes of the labels holding the configuration.
+var filters = []string{"traefik.http", "traefik.tcp", "traefik.udp"}
+
 // DecodeConfiguration converts the labels to a configuration.
 func DecodeConfiguration(labels map[string]string) (*dynamic.Configuration, error) {
 	conf := &dynamic.Configuration{
@@ -14,7 +18,7 @@ func DecodeConfiguration(labels map[string]string) (*dynamic.Configuration, erro
 		UDP:  &dynamic.UDPConfiguration{},
 	}
 
-	err := parser.Decode(labels, conf, parser.DefaultRootName, "traefik.http", "traefik.tcp", "traefik.udp")
+	err := parser.Decode(labels, conf, parser.DefaultRootName, filters...)
 	if err != nil {
 		return nil, err
 	}
@@ -27,6 +31,14 @@ func EncodeConfiguration(conf *dynamic.Configuration) (map[string]string, error)
 	return parser.Encode(conf, parser.DefaultRootName)
 }
 
+// Schema returns the JSON Schema of the labels holding the configuration.
+func Schema() *schema.Schema {
+	return schema.GenerateLabels(&dynamic.Configuration{}, "Traefik dynamic configuration labels", schema.LabelOpts{
+		Prefix:  parser.DefaultRootName + ".",
+		Filters: filters,
+	})
+}
+
 // Decode converts the labels to an element.
 // labels -> [ node -> node + metadata (type) ] -> element (node)
 func Decode(labels map[string]string, element interface{}, filters ...string) error {
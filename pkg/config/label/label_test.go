@@ -66,6 +66,7 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.middlewares.Middleware8.headers.isdevelopment":                               "true",
 		"traefik.http.middlewares.Middleware8.headers.publickey":                                   "foobar",
 		"traefik.http.middlewares.Middleware8.headers.referrerpolicy":                              "foobar",
+		"traefik.http.middlewares.Middleware8.headers.securitypreset":                              "foobar",
 		"traefik.http.middlewares.Middleware8.headers.featurepolicy":                               "foobar",
 		"traefik.http.middlewares.Middleware8.headers.sslforcehost":                                "true",
 		"traefik.http.middlewares.Middleware8.headers.sslhost":                                     "foobar",
@@ -136,50 +137,63 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.routers.Router1.rule":        "foobar",
 		"traefik.http.routers.Router1.service":     "foobar",
 
-		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name0":        "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name1":        "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.hostname":             "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.interval":             "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.path":                 "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.port":                 "42",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.scheme":               "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.timeout":              "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.followredirects":      "true",
-		"traefik.http.services.Service0.loadbalancer.passhostheader":                   "true",
-		"traefik.http.services.Service0.loadbalancer.responseforwarding.flushinterval": "foobar",
-		"traefik.http.services.Service0.loadbalancer.server.scheme":                    "foobar",
-		"traefik.http.services.Service0.loadbalancer.server.port":                      "8080",
-		"traefik.http.services.Service0.loadbalancer.sticky.cookie.name":               "foobar",
-		"traefik.http.services.Service0.loadbalancer.sticky.cookie.secure":             "true",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name0":        "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name1":        "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.hostname":             "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.interval":             "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.path":                 "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.port":                 "42",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.scheme":               "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.timeout":              "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.followredirects":      "true",
-		"traefik.http.services.Service1.loadbalancer.passhostheader":                   "true",
-		"traefik.http.services.Service1.loadbalancer.responseforwarding.flushinterval": "foobar",
-		"traefik.http.services.Service1.loadbalancer.server.scheme":                    "foobar",
-		"traefik.http.services.Service1.loadbalancer.server.port":                      "8080",
-		"traefik.http.services.Service1.loadbalancer.sticky":                           "false",
-		"traefik.http.services.Service1.loadbalancer.sticky.cookie.name":               "fui",
-		"traefik.tcp.routers.Router0.rule":                                             "foobar",
-		"traefik.tcp.routers.Router0.entrypoints":                                      "foobar, fiibar",
-		"traefik.tcp.routers.Router0.service":                                          "foobar",
-		"traefik.tcp.routers.Router0.tls.passthrough":                                  "false",
-		"traefik.tcp.routers.Router0.tls.options":                                      "foo",
-		"traefik.tcp.routers.Router1.rule":                                             "foobar",
-		"traefik.tcp.routers.Router1.entrypoints":                                      "foobar, fiibar",
-		"traefik.tcp.routers.Router1.service":                                          "foobar",
-		"traefik.tcp.routers.Router1.tls.options":                                      "foo",
-		"traefik.tcp.routers.Router1.tls.passthrough":                                  "false",
-		"traefik.tcp.services.Service0.loadbalancer.server.Port":                       "42",
-		"traefik.tcp.services.Service0.loadbalancer.TerminationDelay":                  "42",
-		"traefik.tcp.services.Service1.loadbalancer.server.Port":                       "42",
-		"traefik.tcp.services.Service1.loadbalancer.TerminationDelay":                  "42",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name0":           "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name1":           "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.hostname":                "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.interval":                "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.path":                    "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.port":                    "42",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.scheme":                  "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.timeout":                 "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.followredirects":         "true",
+		"traefik.http.services.Service0.loadbalancer.passhostheader":                      "true",
+		"traefik.http.services.Service0.loadbalancer.responseforwarding.flushinterval":    "foobar",
+		"traefik.http.services.Service0.loadbalancer.responseforwarding.buffersize":       "42",
+		"traefik.http.services.Service0.loadbalancer.responseforwarding.disablebuffering": "true",
+		"traefik.http.services.Service0.loadbalancer.responseforwarding.strict":           "true",
+		"traefik.http.services.Service0.loadbalancer.server.scheme":                       "foobar",
+		"traefik.http.services.Service0.loadbalancer.server.port":                         "8080",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.name":                  "foobar",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.secure":                "true",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name0":           "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name1":           "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.hostname":                "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.interval":                "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.path":                    "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.port":                    "42",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.scheme":                  "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.timeout":                 "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.followredirects":         "true",
+		"traefik.http.services.Service1.loadbalancer.passhostheader":                      "true",
+		"traefik.http.services.Service1.loadbalancer.responseforwarding.flushinterval":    "foobar",
+		"traefik.http.services.Service1.loadbalancer.server.scheme":                       "foobar",
+		"traefik.http.services.Service1.loadbalancer.server.port":                         "8080",
+		"traefik.http.services.Service1.loadbalancer.sticky":                              "false",
+		"traefik.http.services.Service1.loadbalancer.sticky.cookie.name":                  "fui",
+		"traefik.tcp.routers.Router0.rule":                                                "foobar",
+		"traefik.tcp.routers.Router0.entrypoints":                                         "foobar, fiibar",
+		"traefik.tcp.routers.Router0.service":                                             "foobar",
+		"traefik.tcp.routers.Router0.tls.passthrough":                                     "false",
+		"traefik.tcp.routers.Router0.tls.options":                                         "foo",
+		"traefik.tcp.routers.Router1.rule":                                                "foobar",
+		"traefik.tcp.routers.Router1.entrypoints":                                         "foobar, fiibar",
+		"traefik.tcp.routers.Router1.service":                                             "foobar",
+		"traefik.tcp.routers.Router1.tls.options":                                         "foo",
+		"traefik.tcp.routers.Router1.tls.passthrough":                                     "false",
+		"traefik.tcp.services.Service0.loadbalancer.server.Port":                          "42",
+		"traefik.tcp.services.Service0.loadbalancer.TerminationDelay":                     "42",
+		"traefik.tcp.services.Service0.loadbalancer.IdleTimeout":                          "42s",
+		"traefik.tcp.services.Service0.loadbalancer.MaxLifetime":                          "42s",
+		"traefik.tcp.services.Service0.loadbalancer.MaxConnections":                       "42",
+		"traefik.tcp.services.Service0.loadbalancer.DrainTimeout":                         "42s",
+		"traefik.tcp.services.Service0.loadbalancer.DrainIdleTimeout":                     "42s",
+		"traefik.tcp.services.Service1.loadbalancer.server.Port":                          "42",
+		"traefik.tcp.services.Service1.loadbalancer.TerminationDelay":                     "42",
+		"traefik.tcp.services.Service1.loadbalancer.IdleTimeout":                          "42s",
+		"traefik.tcp.services.Service1.loadbalancer.MaxLifetime":                          "42s",
+		"traefik.tcp.services.Service1.loadbalancer.MaxConnections":                       "42",
+		"traefik.tcp.services.Service1.loadbalancer.DrainTimeout":                         "42s",
+		"traefik.tcp.services.Service1.loadbalancer.DrainIdleTimeout":                     "42s",
 
 		"traefik.udp.routers.Router0.entrypoints":                "foobar, fiibar",
 		"traefik.udp.routers.Router0.service":                    "foobar",
@@ -229,6 +243,11 @@ func TestDecodeConfiguration(t *testing.T) {
 							},
 						},
 						TerminationDelay: func(i int) *int { return &i }(42),
+						IdleTimeout:      types.Duration(42 * time.Second),
+						MaxLifetime:      types.Duration(42 * time.Second),
+						MaxConnections:   42,
+						DrainTimeout:     types.Duration(42 * time.Second),
+						DrainIdleTimeout: types.Duration(42 * time.Second),
 					},
 				},
 				"Service1": {
@@ -239,6 +258,11 @@ func TestDecodeConfiguration(t *testing.T) {
 							},
 						},
 						TerminationDelay: func(i int) *int { return &i }(42),
+						IdleTimeout:      types.Duration(42 * time.Second),
+						MaxLifetime:      types.Duration(42 * time.Second),
+						MaxConnections:   42,
+						DrainTimeout:     types.Duration(42 * time.Second),
+						DrainIdleTimeout: types.Duration(42 * time.Second),
 					},
 				},
 			},
@@ -557,6 +581,7 @@ func TestDecodeConfiguration(t *testing.T) {
 						ReferrerPolicy:          "foobar",
 						FeaturePolicy:           "foobar",
 						IsDevelopment:           true,
+						SecurityPreset:          "foobar",
 					},
 				},
 				"Middleware9": {
@@ -606,7 +631,10 @@ func TestDecodeConfiguration(t *testing.T) {
 						},
 						PassHostHeader: func(v bool) *bool { return &v }(true),
 						ResponseForwarding: &dynamic.ResponseForwarding{
-							FlushInterval: "foobar",
+							FlushInterval:    "foobar",
+							BufferSize:       42,
+							DisableBuffering: true,
+							Strict:           true,
 						},
 					},
 				},
@@ -682,6 +710,11 @@ func TestEncodeConfiguration(t *testing.T) {
 							},
 						},
 						TerminationDelay: func(i int) *int { return &i }(42),
+						IdleTimeout:      types.Duration(42 * time.Second),
+						MaxLifetime:      types.Duration(42 * time.Second),
+						MaxConnections:   42,
+						DrainTimeout:     types.Duration(42 * time.Second),
+						DrainIdleTimeout: types.Duration(42 * time.Second),
 					},
 				},
 				"Service1": {
@@ -692,6 +725,11 @@ func TestEncodeConfiguration(t *testing.T) {
 							},
 						},
 						TerminationDelay: func(i int) *int { return &i }(42),
+						IdleTimeout:      types.Duration(42 * time.Second),
+						MaxLifetime:      types.Duration(42 * time.Second),
+						MaxConnections:   42,
+						DrainTimeout:     types.Duration(42 * time.Second),
+						DrainIdleTimeout: types.Duration(42 * time.Second),
 					},
 				},
 			},
@@ -1009,6 +1047,7 @@ func TestEncodeConfiguration(t *testing.T) {
 						ReferrerPolicy:          "foobar",
 						FeaturePolicy:           "foobar",
 						IsDevelopment:           true,
+						SecurityPreset:          "foobar",
 					},
 				},
 				"Middleware9": {
@@ -1056,7 +1095,10 @@ func TestEncodeConfiguration(t *testing.T) {
 						},
 						PassHostHeader: func(v bool) *bool { return &v }(true),
 						ResponseForwarding: &dynamic.ResponseForwarding{
-							FlushInterval: "foobar",
+							FlushInterval:    "foobar",
+							BufferSize:       42,
+							DisableBuffering: true,
+							Strict:           true,
 						},
 					},
 				},
@@ -1145,6 +1187,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware8.Headers.FrameDeny":                                   "true",
 		"traefik.HTTP.Middlewares.Middleware8.Headers.HostsProxyHeaders":                           "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware8.Headers.IsDevelopment":                               "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.SecurityPreset":                              "foobar",
 		"traefik.HTTP.Middlewares.Middleware8.Headers.PublicKey":                                   "foobar",
 		"traefik.HTTP.Middlewares.Middleware8.Headers.ReferrerPolicy":                              "foobar",
 		"traefik.HTTP.Middlewares.Middleware8.Headers.FeaturePolicy":                               "foobar",
@@ -1206,45 +1249,55 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware18.StripPrefixRegex.Regex":                             "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware19.Compress":                                           "true",
 
-		"traefik.HTTP.Routers.Router0.EntryPoints": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router0.Middlewares": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router0.Priority":    "42",
-		"traefik.HTTP.Routers.Router0.Rule":        "foobar",
-		"traefik.HTTP.Routers.Router0.Service":     "foobar",
-		"traefik.HTTP.Routers.Router0.TLS":         "true",
-		"traefik.HTTP.Routers.Router1.EntryPoints": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router1.Middlewares": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router1.Priority":    "42",
-		"traefik.HTTP.Routers.Router1.Rule":        "foobar",
-		"traefik.HTTP.Routers.Router1.Service":     "foobar",
+		"traefik.HTTP.Routers.Router0.EntryPoints":           "foobar, fiibar",
+		"traefik.HTTP.Routers.Router0.GuardInternalServices": "false",
+		"traefik.HTTP.Routers.Router0.Middlewares":           "foobar, fiibar",
+		"traefik.HTTP.Routers.Router0.Priority":              "42",
+		"traefik.HTTP.Routers.Router0.Rule":                  "foobar",
+		"traefik.HTTP.Routers.Router0.Service":               "foobar",
+		"traefik.HTTP.Routers.Router0.TLS":                   "true",
+		"traefik.HTTP.Routers.Router1.EntryPoints":           "foobar, fiibar",
+		"traefik.HTTP.Routers.Router1.GuardInternalServices": "false",
+		"traefik.HTTP.Routers.Router1.Middlewares":           "foobar, fiibar",
+		"traefik.HTTP.Routers.Router1.Priority":              "42",
+		"traefik.HTTP.Routers.Router1.Rule":                  "foobar",
+		"traefik.HTTP.Routers.Router1.Service":               "foobar",
 
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Hostname":             "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Interval":             "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Path":                 "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Port":                 "42",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Scheme":               "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Timeout":              "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.PassHostHeader":                   "true",
-		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.server.Port":                      "8080",
-		"traefik.HTTP.Services.Service0.LoadBalancer.server.Scheme":                    "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Name":               "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.HTTPOnly":           "true",
-		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Secure":             "false",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name0":        "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Hostname":             "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Interval":             "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Path":                 "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Port":                 "42",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Scheme":               "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Timeout":              "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.PassHostHeader":                   "true",
-		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
-		"traefik.HTTP.Services.Service1.LoadBalancer.server.Port":                      "8080",
-		"traefik.HTTP.Services.Service1.LoadBalancer.server.Scheme":                    "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name0":        "foobar",
+		"traefik.HTTP.Services.Service0.Internal":                                         "false",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name1":           "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Hostname":                "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Interval":                "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Path":                    "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Port":                    "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Scheme":                  "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Timeout":                 "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.PassHostHeader":                      "true",
+		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.FlushInterval":    "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.BufferSize":       "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.DisableBuffering": "true",
+		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.Strict":           "true",
+		"traefik.HTTP.Services.Service0.LoadBalancer.server.Port":                         "8080",
+		"traefik.HTTP.Services.Service0.LoadBalancer.server.Scheme":                       "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Name":                  "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.HTTPOnly":              "true",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Secure":                "false",
+		"traefik.HTTP.Services.Service1.Internal":                                         "false",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name0":           "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name1":           "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Hostname":                "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Interval":                "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Path":                    "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Port":                    "42",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Scheme":                  "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Timeout":                 "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.PassHostHeader":                      "true",
+		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.FlushInterval":    "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.BufferSize":       "0",
+		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.DisableBuffering": "false",
+		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.Strict":           "false",
+		"traefik.HTTP.Services.Service1.LoadBalancer.server.Port":                         "8080",
+		"traefik.HTTP.Services.Service1.LoadBalancer.server.Scheme":                       "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name0":           "foobar",
 
 		"traefik.TCP.Routers.Router0.Rule":                            "foobar",
 		"traefik.TCP.Routers.Router0.EntryPoints":                     "foobar, fiibar",
@@ -1258,8 +1311,18 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.TCP.Routers.Router1.TLS.Options":                     "foo",
 		"traefik.TCP.Services.Service0.LoadBalancer.server.Port":      "42",
 		"traefik.TCP.Services.Service0.LoadBalancer.TerminationDelay": "42",
+		"traefik.TCP.Services.Service0.LoadBalancer.IdleTimeout":      "42000000000",
+		"traefik.TCP.Services.Service0.LoadBalancer.MaxLifetime":      "42000000000",
+		"traefik.TCP.Services.Service0.LoadBalancer.MaxConnections":   "42",
+		"traefik.TCP.Services.Service0.LoadBalancer.DrainTimeout":     "42000000000",
+		"traefik.TCP.Services.Service0.LoadBalancer.DrainIdleTimeout": "42000000000",
 		"traefik.TCP.Services.Service1.LoadBalancer.server.Port":      "42",
 		"traefik.TCP.Services.Service1.LoadBalancer.TerminationDelay": "42",
+		"traefik.TCP.Services.Service1.LoadBalancer.IdleTimeout":      "42000000000",
+		"traefik.TCP.Services.Service1.LoadBalancer.MaxLifetime":      "42000000000",
+		"traefik.TCP.Services.Service1.LoadBalancer.MaxConnections":   "42",
+		"traefik.TCP.Services.Service1.LoadBalancer.DrainTimeout":     "42000000000",
+		"traefik.TCP.Services.Service1.LoadBalancer.DrainIdleTimeout": "42000000000",
 
 		"traefik.UDP.Routers.Router0.EntryPoints":                "foobar, fiibar",
 		"traefik.UDP.Routers.Router0.Service":                    "foobar",
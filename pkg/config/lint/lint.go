@@ -0,0 +1,172 @@
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/tls"
+)
+
+// Classes of the warnings.
+const (
+	ClassDeprecated = "deprecated"
+	ClassInsecure   = "insecure"
+)
+
+// Kinds of the configuration elements.
+const (
+	kindHTTPMiddleware   = "http.middleware"
+	kindTLSOptions       = "tls.options"
+	kindServersTransport = "serversTransport"
+)
+
+// Linter checks the applied configurations for deprecated fields, and insecure combinations of options.
+type Linter struct {
+	insecureServersTransport bool
+}
+
+// NewLinter creates a Linter, checking the dynamic configurations along with the static configuration.
+func NewLinter(staticConfiguration static.Configuration) *Linter {
+	return &Linter{
+		insecureServersTransport: staticConfiguration.ServersTransport != nil && staticConfiguration.ServersTransport.InsecureSkipVerify,
+	}
+}
+
+// Lint returns the warnings of the configuration, sorted by kind, name, and field.
+func (l *Linter) Lint(conf *runtime.Configuration) []runtime.Warning {
+	var warnings []runtime.Warning
+
+	if l.insecureServersTransport {
+		warnings = append(warnings, runtime.Warning{
+			Class:   ClassInsecure,
+			Kind:    kindServersTransport,
+			Name:    "serversTransport",
+			Field:   "insecureSkipVerify",
+			Message: "the certificates of the backend servers are not verified, which allows man-in-the-middle attacks; add their CAs to rootCAs instead",
+		})
+	}
+
+	for name, mi := range conf.Middlewares {
+		if mi.Middleware != nil {
+			warnings = append(warnings, lintMiddleware(name, mi.Middleware)...)
+		}
+	}
+
+	if conf.TLS != nil {
+		for name, options := range conf.TLS.Options {
+			warnings = append(warnings, lintTLSOptions(name, options)...)
+		}
+	}
+
+	sort.Slice(warnings, func(i, j int) bool {
+		a, b := warnings[i], warnings[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Field < b.Field
+	})
+
+	return warnings
+}
+
+func lintMiddleware(name string, middleware *dynamic.Middleware) []runtime.Warning {
+	var warnings []runtime.Warning
+
+	if middleware.Headers != nil && middleware.Headers.AccessControlAllowOrigin != "" {
+		warnings = append(warnings, runtime.Warning{
+			Class:   ClassDeprecated,
+			Kind:    kindHTTPMiddleware,
+			Name:    name,
+			Field:   "headers.accessControlAllowOrigin",
+			Message: "accessControlAllowOrigin will be removed in future 2.x releases; use accessControlAllowOriginList instead",
+		})
+	}
+
+	for _, field := range insecureClientTLS(reflect.ValueOf(middleware), "") {
+		warnings = append(warnings, runtime.Warning{
+			Class:   ClassInsecure,
+			Kind:    kindHTTPMiddleware,
+			Name:    name,
+			Field:   field,
+			Message: "the certificate of the server is not verified, which allows man-in-the-middle attacks; set the ca option instead",
+		})
+	}
+
+	return warnings
+}
+
+var clientTLSType = reflect.TypeOf(dynamic.ClientTLS{})
+
+// insecureClientTLS returns the paths of the insecureSkipVerify fields enabled in the client TLS configurations of the value,
+// whatever their depth.
+func insecureClientTLS(value reflect.Value, path string) []string {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		return insecureClientTLS(value.Elem(), path)
+
+	case reflect.Struct:
+		if value.Type() == clientTLSType {
+			if value.Interface().(dynamic.ClientTLS).InsecureSkipVerify {
+				return []string{path + ".insecureSkipVerify"}
+			}
+			return nil
+		}
+
+		var paths []string
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+
+			if path != "" {
+				name = path + "." + name
+			}
+			paths = append(paths, insecureClientTLS(value.Field(i), name)...)
+		}
+		return paths
+	}
+
+	return nil
+}
+
+func lintTLSOptions(name string, options tls.Options) []runtime.Warning {
+	var warnings []runtime.Warning
+
+	// Without minVersion, the minimum version accepted by the servers is TLS 1.0.
+	if options.MinVersion == "" || options.MinVersion == "VersionTLS10" {
+		warnings = append(warnings, runtime.Warning{
+			Class:   ClassInsecure,
+			Kind:    kindTLSOptions,
+			Name:    name,
+			Field:   "minVersion",
+			Message: "TLS 1.0 is enabled; set minVersion to VersionTLS12",
+		})
+	}
+
+	clientAuthType := options.ClientAuth.ClientAuthType
+	if clientAuthType != "" && clientAuthType != "NoClientCert" && !options.SniStrict {
+		warnings = append(warnings, runtime.Warning{
+			Class: ClassInsecure,
+			Kind:  kindTLSOptions,
+			Name:  name,
+			Field: "sniStrict",
+			Message: fmt.Sprintf("the client authentication (%s) is enabled without sniStrict; "+
+				"enable sniStrict to reject the connections without a known server name", clientAuthType),
+		})
+	}
+
+	return warnings
+}
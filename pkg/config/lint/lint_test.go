@@ -0,0 +1,134 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinter_Lint(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		staticConfig static.Configuration
+		conf         *runtime.Configuration
+		expected     []runtime.Warning
+	}{
+		{
+			desc: "no warning",
+			conf: &runtime.Configuration{
+				Middlewares: map[string]*runtime.MiddlewareInfo{
+					"auth@file": {Middleware: &dynamic.Middleware{
+						ForwardAuth: &dynamic.ForwardAuth{Address: "https://auth", TLS: &dynamic.ClientTLS{CA: "ca.pem"}},
+					}},
+				},
+				TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{
+						"default": {MinVersion: "VersionTLS12"},
+						"mtls@file": {
+							MinVersion: "VersionTLS12",
+							ClientAuth: tls.ClientAuth{ClientAuthType: "RequireAndVerifyClientCert"},
+							SniStrict:  true,
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:         "insecure servers transport",
+			staticConfig: static.Configuration{ServersTransport: &static.ServersTransport{InsecureSkipVerify: true}},
+			conf:         &runtime.Configuration{},
+			expected: []runtime.Warning{
+				{
+					Class:   ClassInsecure,
+					Kind:    kindServersTransport,
+					Name:    "serversTransport",
+					Field:   "insecureSkipVerify",
+					Message: "the certificates of the backend servers are not verified, which allows man-in-the-middle attacks; add their CAs to rootCAs instead",
+				},
+			},
+		},
+		{
+			desc: "deprecated and insecure middlewares",
+			conf: &runtime.Configuration{
+				Middlewares: map[string]*runtime.MiddlewareInfo{
+					"cors@file": {Middleware: &dynamic.Middleware{
+						Headers: &dynamic.Headers{AccessControlAllowOrigin: "*"},
+					}},
+					"auth@file": {Middleware: &dynamic.Middleware{
+						ForwardAuth: &dynamic.ForwardAuth{Address: "https://auth", TLS: &dynamic.ClientTLS{InsecureSkipVerify: true}},
+					}},
+					"keys@file": {Middleware: &dynamic.Middleware{
+						APIKey: &dynamic.APIKey{Redis: &dynamic.APIKeyRedis{TLS: &dynamic.ClientTLS{InsecureSkipVerify: true}}},
+					}},
+				},
+			},
+			expected: []runtime.Warning{
+				{
+					Class:   ClassInsecure,
+					Kind:    kindHTTPMiddleware,
+					Name:    "auth@file",
+					Field:   "forwardAuth.tls.insecureSkipVerify",
+					Message: "the certificate of the server is not verified, which allows man-in-the-middle attacks; set the ca option instead",
+				},
+				{
+					Class:   ClassDeprecated,
+					Kind:    kindHTTPMiddleware,
+					Name:    "cors@file",
+					Field:   "headers.accessControlAllowOrigin",
+					Message: "accessControlAllowOrigin will be removed in future 2.x releases; use accessControlAllowOriginList instead",
+				},
+				{
+					Class:   ClassInsecure,
+					Kind:    kindHTTPMiddleware,
+					Name:    "keys@file",
+					Field:   "apiKey.redis.tls.insecureSkipVerify",
+					Message: "the certificate of the server is not verified, which allows man-in-the-middle attacks; set the ca option instead",
+				},
+			},
+		},
+		{
+			desc: "insecure TLS options",
+			conf: &runtime.Configuration{
+				TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{
+						"default": {},
+						"mtls@file": {
+							MinVersion: "VersionTLS12",
+							ClientAuth: tls.ClientAuth{ClientAuthType: "RequireAndVerifyClientCert"},
+						},
+					},
+				},
+			},
+			expected: []runtime.Warning{
+				{
+					Class:   ClassInsecure,
+					Kind:    kindTLSOptions,
+					Name:    "default",
+					Field:   "minVersion",
+					Message: "TLS 1.0 is enabled; set minVersion to VersionTLS12",
+				},
+				{
+					Class:   ClassInsecure,
+					Kind:    kindTLSOptions,
+					Name:    "mtls@file",
+					Field:   "sniStrict",
+					Message: "the client authentication (RequireAndVerifyClientCert) is enabled without sniStrict; enable sniStrict to reject the connections without a known server name",
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			warnings := NewLinter(test.staticConfig).Lint(test.conf)
+			assert.Equal(t, test.expected, warnings)
+		})
+	}
+}
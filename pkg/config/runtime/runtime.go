@@ -24,15 +24,35 @@ type Configuration struct {
 	TCPServices map[string]*TCPServiceInfo `json:"tcpServices,omitempty"`
 	UDPRouters  map[string]*UDPRouterInfo  `json:"udpRouters,omitempty"`
 	UDPServices map[string]*UDPServiceInfo `json:"updServices,omitempty"`
+
+	// TLS is the TLS configuration, only kept for the configuration snapshots.
+	TLS *dynamic.TLSConfiguration `json:"-"`
+
+	// Warnings are the warnings about the deprecated or insecure parts of the configuration, which are applied nonetheless.
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// Warning describes a part of the configuration which is applied, but deprecated, or insecure.
+type Warning struct {
+	// Class is the class of the warning: deprecated, or insecure.
+	Class string `json:"class"`
+	// Kind is the kind of the configuration element, such as http.middleware, or tls.options.
+	Kind string `json:"kind"`
+	// Name is the qualified name of the configuration element.
+	Name string `json:"name"`
+	// Field is the path of the field of the element causing the warning, if any.
+	Field string `json:"field,omitempty"`
+	// Message explains the warning, and how to address it.
+	Message string `json:"message"`
 }
 
 // NewConfig returns a Configuration initialized with the given conf. It never returns nil.
 func NewConfig(conf dynamic.Configuration) *Configuration {
 	if conf.HTTP == nil && conf.TCP == nil && conf.UDP == nil {
-		return &Configuration{}
+		return &Configuration{TLS: conf.TLS}
 	}
 
-	runtimeConfig := &Configuration{}
+	runtimeConfig := &Configuration{TLS: conf.TLS}
 
 	if conf.HTTP != nil {
 		routers := conf.HTTP.Routers
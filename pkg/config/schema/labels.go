@@ -0,0 +1,226 @@
+package schema
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/containous/traefik/v2/pkg/config/parser"
+)
+
+// Patterns of the label values, as parsed by the parser.
+const (
+	boolPattern     = `^(1|t|T|TRUE|true|True|0|f|F|FALSE|false|False)$`
+	intPattern      = `^[-+]?\d+$`
+	uintPattern     = `^\+?\d+$`
+	floatPattern    = `^[-+]?(\d+(\.\d*)?|\.\d+)([eE][-+]?\d+)?$`
+	durationPattern = `^([-+]?\d+|[-+]?((\d+(\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h))+)$`
+)
+
+// LabelOpts holds the options of the schemas of the labels.
+type LabelOpts struct {
+	// Prefix is the prefix of the keys, followed by the path of the fields (e.g. "traefik.").
+	Prefix string
+	// Filters restricts the keys to the ones starting with one of the filters, when not empty.
+	Filters []string
+	// DotIndex allows the slice indexes to be written as path elements (e.g. "domains.0.main"), as in the annotations.
+	DotIndex bool
+}
+
+// GenerateLabels generates the schema of the labels decoded into the element,
+// as a map of the keys, matched by patterns in which the field names are case-insensitive, to their string values.
+// The keys starting with one of the filters, or with the prefix when there are no filters, must match one of the patterns,
+// while the other keys, used for other purposes, are left unchecked.
+func GenerateLabels(element interface{}, title string, opts LabelOpts) *Schema {
+	g := &labelGenerator{
+		LabelOpts: opts,
+		parents:   make(map[reflect.Type]bool),
+		keys:      make(map[string]*Schema),
+	}
+
+	g.walk(reflect.TypeOf(element), regexp.QuoteMeta(opts.Prefix), strings.ToLower(opts.Prefix), "", "")
+
+	scope := []string{regexp.QuoteMeta(opts.Prefix)}
+	if len(opts.Filters) > 0 {
+		scope = nil
+		for _, filter := range opts.Filters {
+			scope = append(scope, caseInsensitive(filter))
+		}
+	}
+
+	names := []*Schema{{Not: &Schema{Pattern: "^(" + strings.Join(scope, "|") + ")"}}}
+	for key := range g.keys {
+		names = append(names, &Schema{Pattern: key})
+	}
+
+	return &Schema{
+		Schema:            Version,
+		Title:             title,
+		Type:              "object",
+		PatternProperties: g.keys,
+		PropertyNames:     &Schema{AnyOf: names},
+	}
+}
+
+type labelGenerator struct {
+	LabelOpts
+
+	// parents holds the struct types being generated, to stop on the recursive types.
+	parents map[reflect.Type]bool
+	// keys holds the schemas of the values, by key pattern.
+	keys map[string]*Schema
+}
+
+// walk adds the keys of the type, where pattern is the pattern of the path of the type,
+// and name its lowercased name, used to apply the filters.
+func (g *labelGenerator) walk(rType reflect.Type, pattern, name string, tag reflect.StructTag, description string) {
+	switch rType.Kind() {
+	case reflect.Ptr:
+		if rType.Elem().Kind() == reflect.Struct && tag.Get(parser.TagLabel) == parser.TagLabelAllowEmpty {
+			// The struct is enabled by its key alone.
+			g.addKey(pattern, name, &Schema{Type: "string", Pattern: boolPattern, Description: description})
+		}
+
+		g.walk(rType.Elem(), pattern, name, tag, description)
+
+	case reflect.Struct:
+		if g.parents[rType] {
+			return
+		}
+
+		g.parents[rType] = true
+		defer delete(g.parents, rType)
+
+		g.walkFields(rType, pattern, name)
+
+	case reflect.Map:
+		keyPattern, keyName := g.join(pattern, name, `[^.]+`, parser.MapNamePlaceholder)
+		g.walk(rType.Elem(), keyPattern, keyName, "", description)
+
+	case reflect.Slice:
+		elem := rType.Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+
+		if elem.Kind() != reflect.Struct {
+			// The values are separated by commas.
+			g.addKey(pattern, name, &Schema{Type: "string", Description: description})
+			return
+		}
+
+		if tag.Get(parser.TagLabelSliceAsStruct) != "" {
+			// The slice holds the single struct of the key.
+			g.walk(elem, pattern, name, "", description)
+			return
+		}
+
+		index := `\[\d+\]`
+		if g.DotIndex {
+			index = `(\[\d+\]|\.\d+)`
+		}
+
+		g.walk(elem, pattern+index, name+"[n]", "", description)
+
+	case reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		// Not supported by the parser.
+
+	default:
+		g.addKey(pattern, name, &Schema{Type: "string", Pattern: valuePattern(rType), Description: description})
+	}
+}
+
+func (g *labelGenerator) walkFields(rType reflect.Type, pattern, name string) {
+	for i := 0; i < rType.NumField(); i++ {
+		field := rType.Field(i)
+		if !parser.IsExported(field) || field.Tag.Get(parser.TagLabel) == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			g.walkFields(field.Type, pattern, name)
+			continue
+		}
+
+		labelName := field.Name
+		if sliceName := field.Tag.Get(parser.TagLabelSliceAsStruct); sliceName != "" {
+			labelName = sliceName
+		}
+
+		description := field.Tag.Get(parser.TagDescription)
+		if description == "-" {
+			description = ""
+		}
+
+		fieldPattern, fieldPath := g.join(pattern, name, caseInsensitive(labelName), strings.ToLower(labelName))
+		g.walk(field.Type, fieldPattern, fieldPath, field.Tag, description)
+	}
+}
+
+// join appends the element to the path, which is the prefix alone for the root fields.
+func (g *labelGenerator) join(pattern, name, elementPattern, elementName string) (string, string) {
+	if name != strings.ToLower(g.Prefix) {
+		pattern += `\.`
+		name += "."
+	}
+
+	return pattern + elementPattern, name + elementName
+}
+
+func (g *labelGenerator) addKey(pattern, name string, schema *Schema) {
+	if !g.matchFilters(name) {
+		return
+	}
+
+	g.keys["^"+pattern+"$"] = schema
+}
+
+func (g *labelGenerator) matchFilters(name string) bool {
+	if len(g.Filters) == 0 {
+		return true
+	}
+
+	for _, filter := range g.Filters {
+		if strings.HasPrefix(name, strings.ToLower(filter)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func valuePattern(rType reflect.Type) string {
+	if rType == durationType || rType == timeDurationType {
+		return durationPattern
+	}
+
+	switch rType.Kind() {
+	case reflect.Bool:
+		return boolPattern
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intPattern
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uintPattern
+	case reflect.Float32, reflect.Float64:
+		return floatPattern
+	default:
+		return ""
+	}
+}
+
+// caseInsensitive returns the pattern matching the value, whatever its case.
+func caseInsensitive(value string) string {
+	var pattern strings.Builder
+	for _, r := range value {
+		lower, upper := unicode.ToLower(r), unicode.ToUpper(r)
+		if lower == upper {
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+			continue
+		}
+
+		pattern.WriteString("[" + string(lower) + string(upper) + "]")
+	}
+
+	return pattern.String()
+}
@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type labelServer struct {
+	URL string `json:"url,omitempty"`
+}
+
+type labelService struct {
+	Servers []labelServer `json:"servers,omitempty" label-slice-as-struct:"server"`
+}
+
+type labelSticky struct {
+	Secure bool `json:"secure,omitempty"`
+}
+
+type labelDomain struct {
+	Main string `json:"main,omitempty"`
+}
+
+type labelRouter struct {
+	Rule        string         `json:"rule,omitempty"`
+	EntryPoints []string       `json:"entryPoints,omitempty"`
+	Priority    int            `json:"priority,omitempty"`
+	Timeout     types.Duration `json:"timeout,omitempty"`
+	Sticky      *labelSticky   `json:"sticky,omitempty" label:"allowEmpty"`
+	Domains     []labelDomain  `json:"domains,omitempty"`
+	Internal    string         `json:"internal,omitempty" label:"-"`
+}
+
+type labelConfig struct {
+	Routers  map[string]*labelRouter  `json:"routers,omitempty"`
+	Services map[string]*labelService `json:"services,omitempty"`
+}
+
+func TestGenerateLabels(t *testing.T) {
+	schema := GenerateLabels(&labelConfig{}, "Labels", LabelOpts{Prefix: "traefik."})
+
+	assert.Equal(t, Version, schema.Schema)
+	assert.Equal(t, "object", schema.Type)
+
+	testCases := []struct {
+		key      string
+		value    string
+		expected bool
+	}{
+		{key: "traefik.routers.foo.rule", value: "Host(`example.com`)", expected: true},
+		{key: "traefik.Routers.foo.RULE", value: "Host(`example.com`)", expected: true},
+		{key: "traefik.routers.foo.entrypoints", value: "web, websecure", expected: true},
+		{key: "traefik.routers.foo.priority", value: "42", expected: true},
+		{key: "traefik.routers.foo.priority", value: "high", expected: false},
+		{key: "traefik.routers.foo.timeout", value: "42", expected: true},
+		{key: "traefik.routers.foo.timeout", value: "1m30s", expected: true},
+		{key: "traefik.routers.foo.timeout", value: "soon", expected: false},
+		{key: "traefik.routers.foo.sticky", value: "true", expected: true},
+		{key: "traefik.routers.foo.sticky.secure", value: "false", expected: true},
+		{key: "traefik.routers.foo.sticky.secure", value: "maybe", expected: false},
+		{key: "traefik.routers.foo.domains[0].main", value: "example.com", expected: true},
+		{key: "traefik.routers.foo.domains.0.main", value: "example.com", expected: false},
+		{key: "traefik.routers.foo.internal", value: "foo", expected: false},
+		{key: "traefik.routers.foo.bar.rule", value: "foo", expected: false},
+		{key: "traefik.services.bar.server.url", value: "http://127.0.0.1", expected: true},
+		{key: "traefik.services.bar.servers[0].url", value: "http://127.0.0.1", expected: false},
+		{key: "com.example.label", value: "foo", expected: true},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, validLabel(t, schema, test.key, test.value), "%s=%s", test.key, test.value)
+	}
+}
+
+func TestGenerateLabels_dotIndex(t *testing.T) {
+	schema := GenerateLabels(&labelConfig{}, "Annotations", LabelOpts{Prefix: "traefik.ingress.kubernetes.io/", DotIndex: true})
+
+	assert.True(t, validLabel(t, schema, "traefik.ingress.kubernetes.io/routers.foo.domains.0.main", "example.com"))
+	assert.True(t, validLabel(t, schema, "traefik.ingress.kubernetes.io/routers.foo.domains[0].main", "example.com"))
+	assert.False(t, validLabel(t, schema, "traefik.ingress.kubernetes.io/routers.foo.unknown", "foo"))
+}
+
+func TestGenerateLabels_filters(t *testing.T) {
+	schema := GenerateLabels(&dynamic.Configuration{}, "Labels", LabelOpts{
+		Prefix:  "traefik.",
+		Filters: []string{"traefik.http", "traefik.tcp", "traefik.udp"},
+	})
+
+	assert.True(t, validLabel(t, schema, "traefik.http.routers.foo.rule", "Host(`example.com`)"))
+	assert.True(t, validLabel(t, schema, "traefik.http.services.foo.loadbalancer.server.port", "8080"))
+	assert.True(t, validLabel(t, schema, "traefik.tcp.routers.foo.tls.passthrough", "true"))
+	assert.True(t, validLabel(t, schema, "traefik.udp.services.foo.loadbalancer.server.port", "53"))
+	assert.False(t, validLabel(t, schema, "traefik.http.routers.foo.unknown", "foo"))
+
+	// The TLS configuration is not read from the labels, which are left to the providers.
+	assert.True(t, validLabel(t, schema, "traefik.enable", "true"))
+	assert.True(t, validLabel(t, schema, "traefik.tls.stores.default.defaultcertificate.certfile", "foo"))
+	for key := range schema.PatternProperties {
+		assert.NotContains(t, key, caseInsensitive("tls")+`\.`+caseInsensitive("stores"))
+	}
+}
+
+// validLabel validates the label against the schema, as a JSON Schema validator would.
+func validLabel(t *testing.T, schema *Schema, key, value string) bool {
+	t.Helper()
+
+	validName := false
+	for _, name := range schema.PropertyNames.AnyOf {
+		if name.Not != nil {
+			if !regexp.MustCompile(name.Not.Pattern).MatchString(key) {
+				validName = true
+			}
+			continue
+		}
+
+		if regexp.MustCompile(name.Pattern).MatchString(key) {
+			validName = true
+		}
+	}
+
+	if !validName {
+		return false
+	}
+
+	for pattern, valueSchema := range schema.PatternProperties {
+		if !regexp.MustCompile(pattern).MatchString(key) {
+			continue
+		}
+
+		require.Equal(t, "string", valueSchema.Type)
+		if valueSchema.Pattern != "" && !regexp.MustCompile(valueSchema.Pattern).MatchString(value) {
+			return false
+		}
+	}
+
+	return true
+}
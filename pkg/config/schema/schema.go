@@ -0,0 +1,143 @@
+// Package schema implements the generation of the JSON Schemas of the configuration, from its Go types.
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/parser"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// Version is the JSON Schema version of the generated schemas.
+const Version = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema, restricted to the keywords used to describe the configuration.
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Type is either a single type, or a list of types.
+	Type                 interface{}        `json:"type,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	PatternProperties    map[string]*Schema `json:"patternProperties,omitempty"`
+	PropertyNames        *Schema            `json:"propertyNames,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Not                  *Schema            `json:"not,omitempty"`
+}
+
+var (
+	durationType     = reflect.TypeOf(types.Duration(0))
+	timeDurationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Generate generates the schema of the element, as written in the JSON, YAML, and TOML files,
+// where the fields are named after their json tag.
+func Generate(element interface{}, title string) *Schema {
+	schema := newGenerator().typeSchema(reflect.TypeOf(element))
+	schema.Schema = Version
+	schema.Title = title
+
+	return schema
+}
+
+type generator struct {
+	// parents holds the struct types being generated, to stop on the recursive types.
+	parents map[reflect.Type]bool
+}
+
+func newGenerator() *generator {
+	return &generator{parents: make(map[reflect.Type]bool)}
+}
+
+func (g *generator) typeSchema(rType reflect.Type) *Schema {
+	if rType == durationType || rType == timeDurationType {
+		// Durations are written as Go durations, or as a number of seconds.
+		return &Schema{Type: []string{"string", "integer"}}
+	}
+
+	switch rType.Kind() {
+	case reflect.Ptr:
+		return g.typeSchema(rType.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if rType.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are written as base64 strings.
+			return &Schema{Type: "string"}
+		}
+
+		return &Schema{Type: "array", Items: g.typeSchema(rType.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(rType.Elem())}
+	case reflect.Struct:
+		if g.parents[rType] {
+			return &Schema{}
+		}
+
+		g.parents[rType] = true
+		defer delete(g.parents, rType)
+
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		g.addFields(schema, rType)
+
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *generator) addFields(schema *Schema, rType reflect.Type) {
+	for i := 0; i < rType.NumField(); i++ {
+		field := rType.Field(i)
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			fType := field.Type
+			if fType.Kind() == reflect.Ptr {
+				fType = fType.Elem()
+			}
+
+			// The fields of the embedded structs are promoted, even when the struct is not exported.
+			if fType.Kind() == reflect.Struct {
+				g.addFields(schema, fType)
+				continue
+			}
+		}
+
+		if !parser.IsExported(field) {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := g.typeSchema(field.Type)
+		if description := field.Tag.Get(parser.TagDescription); description != "-" {
+			fieldSchema.Description = description
+		}
+
+		schema.Properties[name] = fieldSchema
+	}
+}
+
+// fieldName returns the name of the field in its json tag.
+func fieldName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
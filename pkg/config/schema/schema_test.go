@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type embedded struct {
+	Inline string `json:"inline,omitempty"`
+}
+
+type child struct {
+	Name   string `description:"Child name." json:"name,omitempty"`
+	Parent *node  `json:"parent,omitempty"`
+}
+
+type node struct {
+	embedded
+
+	Enabled  bool              `description:"Enable the node." json:"enabled,omitempty"`
+	Count    int               `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio,omitempty"`
+	Timeout  types.Duration    `json:"timeout,omitempty"`
+	Names    []string          `json:"names,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Children []child           `json:"children,omitempty"`
+	Child    *child            `json:"child,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Ignored  string            `json:"-"`
+	Hidden   string            `description:"-" json:"hidden,omitempty"`
+}
+
+func TestGenerate(t *testing.T) {
+	childSchema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":   {Description: "Child name.", Type: "string"},
+			"parent": {},
+		},
+		AdditionalProperties: false,
+	}
+
+	expected := &Schema{
+		Schema: Version,
+		Title:  "Node",
+		Type:   "object",
+		Properties: map[string]*Schema{
+			"inline":   {Type: "string"},
+			"enabled":  {Description: "Enable the node.", Type: "boolean"},
+			"count":    {Type: "integer"},
+			"ratio":    {Type: "number"},
+			"timeout":  {Type: []string{"string", "integer"}},
+			"names":    {Type: "array", Items: &Schema{Type: "string"}},
+			"headers":  {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+			"children": {Type: "array", Items: childSchema},
+			"child":    childSchema,
+			"data":     {Type: "string"},
+			"hidden":   {Type: "string"},
+		},
+		AdditionalProperties: false,
+	}
+
+	assert.Equal(t, expected, Generate(&node{}, "Node"))
+}
@@ -0,0 +1,485 @@
+package snapshot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/secrets"
+	"github.com/containous/traefik/v2/pkg/tls"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	internalProvider = "internal"
+	defaultTLSName   = "default"
+	redacted         = "REDACTED"
+)
+
+// Build returns the normalized snapshot of a merged dynamic configuration, which can be loaded by the file provider.
+// The elements of the internal provider are left out.
+// The other elements are renamed without their provider,
+// or with their provider as a suffix when several providers define elements with the same name,
+// and the references between the elements are resolved and renamed accordingly.
+// The references to the elements of the internal provider, or to missing elements, are kept fully qualified.
+func Build(conf dynamic.Configuration) (*dynamic.Configuration, error) {
+	conf = *conf.DeepCopy()
+
+	snapshot := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{},
+		TCP:  &dynamic.TCPConfiguration{},
+		UDP:  &dynamic.UDPConfiguration{},
+		TLS:  &dynamic.TLSConfiguration{},
+	}
+
+	tlsOptionsNames, err := buildTLS(conf.TLS, snapshot.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := buildHTTP(conf.HTTP, snapshot.HTTP, tlsOptionsNames); err != nil {
+		return nil, err
+	}
+
+	if err := buildTCP(conf.TCP, snapshot.TCP, tlsOptionsNames); err != nil {
+		return nil, err
+	}
+
+	if err := buildUDP(conf.UDP, snapshot.UDP); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// BuildFromProvider returns the normalized snapshot of the dynamic configuration of a single provider,
+// e.g. of a configuration to be loaded by the file provider,
+// as the snapshot of a merged dynamic configuration only made of this provider configuration.
+func BuildFromProvider(conf dynamic.Configuration, providerName string) (*dynamic.Configuration, error) {
+	conf = *conf.DeepCopy()
+
+	if conf.HTTP != nil {
+		qualify(conf.HTTP.Routers, providerName)
+		qualify(conf.HTTP.Middlewares, providerName)
+		qualify(conf.HTTP.Services, providerName)
+	}
+
+	if conf.TCP != nil {
+		qualify(conf.TCP.Routers, providerName)
+		qualify(conf.TCP.Services, providerName)
+	}
+
+	if conf.UDP != nil {
+		qualify(conf.UDP.Routers, providerName)
+		qualify(conf.UDP.Services, providerName)
+	}
+
+	if conf.TLS != nil {
+		qualify(conf.TLS.Options, providerName)
+		qualify(conf.TLS.Stores, providerName)
+	}
+
+	return Build(conf)
+}
+
+// Marshal returns the YAML representation of the snapshot.
+// The values of the fields tagged with secret:"true", such as the passwords and the private keys given inline, are redacted,
+// unlike the secret references and the paths of the key files.
+func Marshal(snapshot *dynamic.Configuration) ([]byte, error) {
+	snapshot = snapshot.DeepCopy()
+	redact(reflect.ValueOf(snapshot), false)
+
+	return yaml.Marshal(snapshot)
+}
+
+// redact replaces the values of the secret fields of the element.
+func redact(v reflect.Value, secret bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			redact(v.Elem(), secret)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath == "" {
+				redact(v.Field(i), field.Tag.Get("secret") == "true")
+			}
+		}
+
+	case reflect.String:
+		if !secret || !v.CanSet() || v.String() == "" || secrets.IsReference(v.String()) {
+			return
+		}
+
+		if file, ok := v.Interface().(interface{ IsPath() bool }); ok && file.IsPath() {
+			return
+		}
+
+		v.SetString(redacted)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			redact(v.Index(i), secret)
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// The values of a map are not addressable, so they are redacted in a copy, set back in the map.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			redact(elem, secret)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
+
+func buildHTTP(conf *dynamic.HTTPConfiguration, snapshot *dynamic.HTTPConfiguration, tlsOptionsNames names) error {
+	if conf == nil {
+		return nil
+	}
+
+	routerNames, err := newNames(keys(conf.Routers))
+	if err != nil {
+		return fmt.Errorf("HTTP routers: %w", err)
+	}
+
+	middlewareNames, err := newNames(keys(conf.Middlewares))
+	if err != nil {
+		return fmt.Errorf("HTTP middlewares: %w", err)
+	}
+
+	serviceNames, err := newNames(keys(conf.Services))
+	if err != nil {
+		return fmt.Errorf("HTTP services: %w", err)
+	}
+
+	for qualifiedName, router := range conf.Routers {
+		name, ok := routerNames[qualifiedName]
+		if !ok {
+			continue
+		}
+		providerName := getProviderName(qualifiedName)
+
+		for i, middleware := range router.Middlewares {
+			router.Middlewares[i] = middlewareNames.resolve(providerName, middleware)
+		}
+		router.Service = serviceNames.resolve(providerName, router.Service)
+		if router.TLS != nil {
+			router.TLS.Options = tlsOptionsNames.resolve(providerName, router.TLS.Options)
+		}
+
+		if snapshot.Routers == nil {
+			snapshot.Routers = make(map[string]*dynamic.Router)
+		}
+		snapshot.Routers[name] = router
+	}
+
+	for qualifiedName, middleware := range conf.Middlewares {
+		name, ok := middlewareNames[qualifiedName]
+		if !ok {
+			continue
+		}
+		providerName := getProviderName(qualifiedName)
+
+		if middleware.Chain != nil {
+			for i, m := range middleware.Chain.Middlewares {
+				middleware.Chain.Middlewares[i] = middlewareNames.resolve(providerName, m)
+			}
+		}
+		if middleware.Shadow != nil {
+			for i, m := range middleware.Shadow.Middlewares {
+				middleware.Shadow.Middlewares[i] = middlewareNames.resolve(providerName, m)
+			}
+		}
+		if middleware.Errors != nil {
+			middleware.Errors.Service = serviceNames.resolve(providerName, middleware.Errors.Service)
+		}
+
+		if snapshot.Middlewares == nil {
+			snapshot.Middlewares = make(map[string]*dynamic.Middleware)
+		}
+		snapshot.Middlewares[name] = middleware
+	}
+
+	for qualifiedName, service := range conf.Services {
+		name, ok := serviceNames[qualifiedName]
+		if !ok {
+			continue
+		}
+		providerName := getProviderName(qualifiedName)
+
+		if service.Weighted != nil {
+			for i, s := range service.Weighted.Services {
+				service.Weighted.Services[i].Name = serviceNames.resolve(providerName, s.Name)
+			}
+		}
+		if service.Mirroring != nil {
+			service.Mirroring.Service = serviceNames.resolve(providerName, service.Mirroring.Service)
+			for i, m := range service.Mirroring.Mirrors {
+				service.Mirroring.Mirrors[i].Name = serviceNames.resolve(providerName, m.Name)
+			}
+		}
+		if service.Mapped != nil && service.Mapped.Fallback != "" {
+			service.Mapped.Fallback = serviceNames.resolve(providerName, service.Mapped.Fallback)
+		}
+
+		if snapshot.Services == nil {
+			snapshot.Services = make(map[string]*dynamic.Service)
+		}
+		snapshot.Services[name] = service
+	}
+
+	return nil
+}
+
+func buildTCP(conf *dynamic.TCPConfiguration, snapshot *dynamic.TCPConfiguration, tlsOptionsNames names) error {
+	if conf == nil {
+		return nil
+	}
+
+	routerNames, err := newNames(keys(conf.Routers))
+	if err != nil {
+		return fmt.Errorf("TCP routers: %w", err)
+	}
+
+	serviceNames, err := newNames(keys(conf.Services))
+	if err != nil {
+		return fmt.Errorf("TCP services: %w", err)
+	}
+
+	for qualifiedName, router := range conf.Routers {
+		name, ok := routerNames[qualifiedName]
+		if !ok {
+			continue
+		}
+
+		providerName := getProviderName(qualifiedName)
+
+		router.Service = serviceNames.resolve(providerName, router.Service)
+		if router.TLS != nil {
+			router.TLS.Options = tlsOptionsNames.resolve(providerName, router.TLS.Options)
+		}
+
+		if snapshot.Routers == nil {
+			snapshot.Routers = make(map[string]*dynamic.TCPRouter)
+		}
+		snapshot.Routers[name] = router
+	}
+
+	for qualifiedName, service := range conf.Services {
+		name, ok := serviceNames[qualifiedName]
+		if !ok {
+			continue
+		}
+
+		if service.Weighted != nil {
+			for i, s := range service.Weighted.Services {
+				service.Weighted.Services[i].Name = serviceNames.resolve(getProviderName(qualifiedName), s.Name)
+			}
+		}
+
+		if snapshot.Services == nil {
+			snapshot.Services = make(map[string]*dynamic.TCPService)
+		}
+		snapshot.Services[name] = service
+	}
+
+	return nil
+}
+
+func buildUDP(conf *dynamic.UDPConfiguration, snapshot *dynamic.UDPConfiguration) error {
+	if conf == nil {
+		return nil
+	}
+
+	routerNames, err := newNames(keys(conf.Routers))
+	if err != nil {
+		return fmt.Errorf("UDP routers: %w", err)
+	}
+
+	serviceNames, err := newNames(keys(conf.Services))
+	if err != nil {
+		return fmt.Errorf("UDP services: %w", err)
+	}
+
+	for qualifiedName, router := range conf.Routers {
+		name, ok := routerNames[qualifiedName]
+		if !ok {
+			continue
+		}
+
+		router.Service = serviceNames.resolve(getProviderName(qualifiedName), router.Service)
+
+		if snapshot.Routers == nil {
+			snapshot.Routers = make(map[string]*dynamic.UDPRouter)
+		}
+		snapshot.Routers[name] = router
+	}
+
+	for qualifiedName, service := range conf.Services {
+		name, ok := serviceNames[qualifiedName]
+		if !ok {
+			continue
+		}
+
+		if service.Weighted != nil {
+			for i, s := range service.Weighted.Services {
+				service.Weighted.Services[i].Name = serviceNames.resolve(getProviderName(qualifiedName), s.Name)
+			}
+		}
+
+		if snapshot.Services == nil {
+			snapshot.Services = make(map[string]*dynamic.UDPService)
+		}
+		snapshot.Services[name] = service
+	}
+
+	return nil
+}
+
+// buildTLS adds the TLS configuration to the snapshot, and returns the names of the TLS options in the snapshot.
+func buildTLS(conf *dynamic.TLSConfiguration, snapshot *dynamic.TLSConfiguration) (names, error) {
+	if conf == nil {
+		return names{}, nil
+	}
+
+	snapshot.Certificates = conf.Certificates
+
+	optionsNames, err := newNames(keys(conf.Options))
+	if err != nil {
+		return nil, fmt.Errorf("TLS options: %w", err)
+	}
+
+	for qualifiedName, options := range conf.Options {
+		if name, ok := optionsNames[qualifiedName]; ok {
+			if snapshot.Options == nil {
+				snapshot.Options = make(map[string]tls.Options)
+			}
+			snapshot.Options[name] = options
+		}
+	}
+
+	storesNames, err := newNames(keys(conf.Stores))
+	if err != nil {
+		return nil, fmt.Errorf("TLS stores: %w", err)
+	}
+
+	for qualifiedName, store := range conf.Stores {
+		if name, ok := storesNames[qualifiedName]; ok {
+			if snapshot.Stores == nil {
+				snapshot.Stores = make(map[string]tls.Store)
+			}
+			snapshot.Stores[name] = store
+		}
+	}
+
+	return optionsNames, nil
+}
+
+// names maps the qualified names of the elements of a kind to their names in the snapshot.
+type names map[string]string
+
+// newNames computes the names in the snapshot of the elements with the given qualified names.
+func newNames(qualifiedNames []string) (names, error) {
+	byName := make(map[string][]string)
+	for _, qualifiedName := range qualifiedNames {
+		// The default TLS options and stores are not qualified.
+		if qualifiedName == defaultTLSName {
+			byName[defaultTLSName] = append(byName[defaultTLSName], qualifiedName)
+			continue
+		}
+
+		providerName := getProviderName(qualifiedName)
+		if providerName == internalProvider {
+			continue
+		}
+
+		name := strings.TrimSuffix(qualifiedName, "@"+providerName)
+		byName[name] = append(byName[name], qualifiedName)
+	}
+
+	result := make(names)
+	taken := make(map[string]string)
+	for name, qualified := range byName {
+		if len(qualified) == 1 {
+			result[qualified[0]] = name
+			taken[name] = qualified[0]
+		}
+	}
+
+	for name, qualified := range byName {
+		if len(qualified) == 1 {
+			continue
+		}
+
+		for _, qualifiedName := range qualified {
+			newName := name + "-" + getProviderName(qualifiedName)
+			if other, ok := taken[newName]; ok {
+				return nil, fmt.Errorf("cannot rename %s to %s, already used by %s", qualifiedName, newName, other)
+			}
+
+			result[qualifiedName] = newName
+			taken[newName] = qualifiedName
+		}
+	}
+
+	return result, nil
+}
+
+// resolve returns the name in the snapshot of the element referenced by an element of the given provider.
+func (n names) resolve(providerName, reference string) string {
+	if reference == "" {
+		return ""
+	}
+
+	// The default TLS options are referenced without provider.
+	if name, ok := n[reference]; ok {
+		return name
+	}
+
+	qualifiedName := reference
+	if !strings.Contains(reference, "@") {
+		qualifiedName = reference + "@" + providerName
+	}
+
+	if name, ok := n[qualifiedName]; ok {
+		return name
+	}
+
+	return qualifiedName
+}
+
+// keys returns the keys of a map of elements keyed by their qualified names.
+func keys(elements interface{}) []string {
+	var qualifiedNames []string
+	for _, key := range reflect.ValueOf(elements).MapKeys() {
+		qualifiedNames = append(qualifiedNames, key.String())
+	}
+	return qualifiedNames
+}
+
+// qualify renames the elements of the map with their provider, except the default TLS options and stores.
+func qualify(elements interface{}, providerName string) {
+	value := reflect.ValueOf(elements)
+
+	for _, key := range value.MapKeys() {
+		if key.String() == defaultTLSName {
+			continue
+		}
+
+		element := value.MapIndex(key)
+		value.SetMapIndex(key, reflect.Value{})
+		value.SetMapIndex(reflect.ValueOf(key.String()+"@"+providerName).Convert(key.Type()), element)
+	}
+}
+
+func getProviderName(qualifiedName string) string {
+	parts := strings.Split(qualifiedName, "@")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
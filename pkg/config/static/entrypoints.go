@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/types"
 )
@@ -15,6 +16,9 @@ type EntryPoint struct {
 	ProxyProtocol    *ProxyProtocol        `description:"Proxy-Protocol configuration." json:"proxyProtocol,omitempty" toml:"proxyProtocol,omitempty" yaml:"proxyProtocol,omitempty" label:"allowEmpty"`
 	ForwardedHeaders *ForwardedHeaders     `description:"Trust client forwarding headers." json:"forwardedHeaders,omitempty" toml:"forwardedHeaders,omitempty" yaml:"forwardedHeaders,omitempty"`
 	HTTP             HTTPConfig            `description:"HTTP configuration." json:"http,omitempty" toml:"http,omitempty" yaml:"http,omitempty"`
+	ConnectionLimit  *ConnectionLimit      `description:"Limits the number of active connections per client IP." json:"connectionLimit,omitempty" toml:"connectionLimit,omitempty" yaml:"connectionLimit,omitempty" export:"true"`
+	ClientHello      *ClientHello          `description:"Peeking of the TLS ClientHello of the connections, to route them by server name." json:"clientHello,omitempty" toml:"clientHello,omitempty" yaml:"clientHello,omitempty" export:"true"`
+	Internal         bool                  `description:"Marks the entry point as internal: the routers can expose the internal services on it." json:"internal,omitempty" toml:"internal,omitempty" yaml:"internal,omitempty" export:"true"`
 }
 
 // GetAddress strips any potential protocol part of the address field of the
@@ -45,13 +49,28 @@ func (ep *EntryPoint) SetDefaults() {
 	ep.Transport = &EntryPointsTransport{}
 	ep.Transport.SetDefaults()
 	ep.ForwardedHeaders = &ForwardedHeaders{}
+	ep.ClientHello = &ClientHello{}
+	ep.ClientHello.SetDefaults()
 }
 
 // HTTPConfig is the HTTP configuration of an entry point.
 type HTTPConfig struct {
-	Redirections *Redirections `description:"Set of redirection" json:"redirections,omitempty" toml:"redirections,omitempty" yaml:"redirections,omitempty"`
-	Middlewares  []string      `description:"Default middlewares for the routers linked to the entry point." json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
-	TLS          *TLSConfig    `description:"Default TLS configuration for the routers linked to the entry point." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty"`
+	Redirections     *Redirections `description:"Set of redirection" json:"redirections,omitempty" toml:"redirections,omitempty" yaml:"redirections,omitempty"`
+	Middlewares      []string      `description:"Default middlewares for the routers linked to the entry point." json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	TLS              *TLSConfig    `description:"Default TLS configuration for the routers linked to the entry point." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty"`
+	AltSvc           *AltSvc       `description:"Alt-Svc advertisement for the routers linked to the entry point." json:"altSvc,omitempty" toml:"altSvc,omitempty" yaml:"altSvc,omitempty" label:"allowEmpty"`
+	RejectCoalescing bool          `description:"Rejects with a 421 the requests whose host is served with other TLS options than the server name of their connection." json:"rejectCoalescing,omitempty" toml:"rejectCoalescing,omitempty" yaml:"rejectCoalescing,omitempty" export:"true"`
+}
+
+// AltSvc is the definition of an Alt-Svc advertisement for an entry point.
+type AltSvc struct {
+	EntryPoint string `description:"Targeted entry point of the advertisement." json:"entryPoint,omitempty" toml:"entryPoint,omitempty" yaml:"entryPoint,omitempty"`
+	MaxAge     int    `description:"Time, in seconds, during which the advertisement is considered fresh." json:"maxAge,omitempty" toml:"maxAge,omitempty" yaml:"maxAge,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (a *AltSvc) SetDefaults() {
+	a.MaxAge = 86400
 }
 
 // Redirections is a set of redirection for an entry point.
@@ -93,6 +112,51 @@ type ProxyProtocol struct {
 	TrustedIPs []string `description:"Trust only selected IPs." json:"trustedIPs,omitempty" toml:"trustedIPs,omitempty" yaml:"trustedIPs,omitempty"`
 }
 
+// Overflow actions of a connection limit.
+const (
+	OverflowReject = "reject"
+	OverflowQueue  = "queue"
+)
+
+// ConnectionLimit limits the number of active connections per client IP on an entry point.
+type ConnectionLimit struct {
+	MaxPerIP     int            `description:"Maximum number of active connections per client IP (0 means no limit)." json:"maxPerIP,omitempty" toml:"maxPerIP,omitempty" yaml:"maxPerIP,omitempty" export:"true"`
+	Overflow     string         `description:"Action on the connections over the limit: reject (closes them) or queue (holds them until a connection of the client IP is closed)." json:"overflow,omitempty" toml:"overflow,omitempty" yaml:"overflow,omitempty" export:"true"`
+	QueueTimeout types.Duration `description:"Maximum duration a connection is queued before being closed." json:"queueTimeout,omitempty" toml:"queueTimeout,omitempty" yaml:"queueTimeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *ConnectionLimit) SetDefaults() {
+	c.Overflow = OverflowReject
+	c.QueueTimeout = types.Duration(10 * time.Second)
+}
+
+// Fallback actions of the connections whose TLS ClientHello is malformed or not received in time.
+const (
+	ClientHelloFallbackClose = "close"
+	ClientHelloFallbackNoTLS = "noTLS"
+)
+
+// Protocols negotiating TLS before the TLS ClientHello.
+const (
+	StartTLSPostgres = "postgres"
+)
+
+// ClientHello configures the peeking of the TLS ClientHello of the connections of an entry point.
+type ClientHello struct {
+	Timeout  types.Duration `description:"Maximum duration to receive the TLS ClientHello, or the first bytes of a non-TLS connection (0 means no timeout)." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	MaxBytes int            `description:"Maximum size of the TLS record holding the ClientHello, in bytes." json:"maxBytes,omitempty" toml:"maxBytes,omitempty" yaml:"maxBytes,omitempty" export:"true"`
+	Fallback string         `description:"Action on the connections whose ClientHello is malformed or not received in time: close, or noTLS (routes them as non-TLS connections)." json:"fallback,omitempty" toml:"fallback,omitempty" yaml:"fallback,omitempty" export:"true"`
+	StartTLS []string       `description:"Protocols negotiating TLS before the ClientHello, whose connections are also routed by server name: postgres, and one of smtp, imap, or pop3." json:"startTLS,omitempty" toml:"startTLS,omitempty" yaml:"startTLS,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *ClientHello) SetDefaults() {
+	c.Timeout = types.Duration(10 * time.Second)
+	c.MaxBytes = 16384
+	c.Fallback = ClientHelloFallbackClose
+}
+
 // EntryPoints holds the HTTP entry point list.
 type EntryPoints map[string]*EntryPoint
 
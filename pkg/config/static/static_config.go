@@ -1,13 +1,23 @@
 package static
 
 import (
+	"errors"
 	"fmt"
 	stdlog "log"
+	"net"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/acmeserver"
+	"github.com/containous/traefik/v2/pkg/cluster"
+	"github.com/containous/traefik/v2/pkg/encryption"
+	"github.com/containous/traefik/v2/pkg/gossip"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/memprotect"
 	"github.com/containous/traefik/v2/pkg/ping"
+	"github.com/containous/traefik/v2/pkg/plugins"
+	"github.com/containous/traefik/v2/pkg/privsep"
 	acmeprovider "github.com/containous/traefik/v2/pkg/provider/acme"
 	"github.com/containous/traefik/v2/pkg/provider/consulcatalog"
 	"github.com/containous/traefik/v2/pkg/provider/docker"
@@ -21,6 +31,10 @@ import (
 	"github.com/containous/traefik/v2/pkg/provider/marathon"
 	"github.com/containous/traefik/v2/pkg/provider/rancher"
 	"github.com/containous/traefik/v2/pkg/provider/rest"
+	"github.com/containous/traefik/v2/pkg/provider/vault"
+	"github.com/containous/traefik/v2/pkg/runas"
+	"github.com/containous/traefik/v2/pkg/secrets"
+	"github.com/containous/traefik/v2/pkg/spiffe"
 	"github.com/containous/traefik/v2/pkg/tls"
 	"github.com/containous/traefik/v2/pkg/tracing/datadog"
 	"github.com/containous/traefik/v2/pkg/tracing/elastic"
@@ -61,6 +75,8 @@ type Configuration struct {
 	Metrics *types.Metrics `description:"Enable a metrics exporter." json:"metrics,omitempty" toml:"metrics,omitempty" yaml:"metrics,omitempty" export:"true"`
 	Ping    *ping.Handler  `description:"Enable ping." json:"ping,omitempty" toml:"ping,omitempty" yaml:"ping,omitempty" label:"allowEmpty" export:"true"`
 
+	ACMEServer *acmeserver.Handler `description:"Enable the built-in ACME server, for test environments." json:"acmeServer,omitempty" toml:"acmeServer,omitempty" yaml:"acmeServer,omitempty" label:"allowEmpty" export:"true"`
+
 	Log       *types.TraefikLog `description:"Traefik log settings." json:"log,omitempty" toml:"log,omitempty" yaml:"log,omitempty" label:"allowEmpty" export:"true"`
 	AccessLog *types.AccessLog  `description:"Access log settings." json:"accessLog,omitempty" toml:"accessLog,omitempty" yaml:"accessLog,omitempty" label:"allowEmpty" export:"true"`
 	Tracing   *Tracing          `description:"OpenTracing configuration." json:"tracing,omitempty" toml:"tracing,omitempty" yaml:"tracing,omitempty" label:"allowEmpty" export:"true"`
@@ -68,6 +84,30 @@ type Configuration struct {
 	HostResolver *types.HostResolverConfig `description:"Enable CNAME Flattening." json:"hostResolver,omitempty" toml:"hostResolver,omitempty" yaml:"hostResolver,omitempty" label:"allowEmpty" export:"true"`
 
 	CertificatesResolvers map[string]CertificateResolver `description:"Certificates resolvers configuration." json:"certificatesResolvers,omitempty" toml:"certificatesResolvers,omitempty" yaml:"certificatesResolvers,omitempty" export:"true"`
+
+	Secrets *secrets.Configuration `description:"Resolution of the secret references (env:, file:, vault:, k8s-secret:) of the configuration." json:"secrets,omitempty" toml:"secrets,omitempty" yaml:"secrets,omitempty" label:"allowEmpty" export:"true"`
+
+	StorageEncryption *encryption.Configuration `description:"Encryption at rest of the local state files, such as the ACME storage." json:"storageEncryption,omitempty" toml:"storageEncryption,omitempty" yaml:"storageEncryption,omitempty" export:"true"`
+
+	KeyProtection *memprotect.Configuration `description:"Protection of the private keys held in memory." json:"keyProtection,omitempty" toml:"keyProtection,omitempty" yaml:"keyProtection,omitempty" label:"allowEmpty" export:"true"`
+
+	PrivilegeSeparation *privsep.Configuration `description:"Run as an unprivileged worker process, reading the certificates through a privileged helper process." json:"privilegeSeparation,omitempty" toml:"privilegeSeparation,omitempty" yaml:"privilegeSeparation,omitempty" export:"true"`
+
+	RunAs *runas.Configuration `description:"Drop the privileges of the process once the entry points are listening." json:"runAs,omitempty" toml:"runAs,omitempty" yaml:"runAs,omitempty" label:"allowEmpty" export:"true"`
+
+	CRLStorage string `description:"Directory where the downloaded certificate revocation lists of the client authentication are saved, to be used after a restart." json:"crlStorage,omitempty" toml:"crlStorage,omitempty" yaml:"crlStorage,omitempty" export:"true"`
+
+	LazyCertificates bool `description:"Parse the private keys of the dynamic certificates on their first use, instead of on each configuration update." json:"lazyCertificates,omitempty" toml:"lazyCertificates,omitempty" yaml:"lazyCertificates,omitempty" export:"true"`
+
+	CheckSCTs bool `description:"Check that the certificates of the TLS stores carry the Signed Certificate Timestamps required by the certificate transparency policy of the browsers, logging the certificates which do not." json:"checkSCTs,omitempty" toml:"checkSCTs,omitempty" yaml:"checkSCTs,omitempty" export:"true"`
+
+	SPIFFE *spiffe.Configuration `description:"SPIFFE Workload API providing the X.509-SVID of Traefik, and the trust bundles verifying the client X.509-SVIDs." json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty" label:"allowEmpty" export:"true"`
+
+	Cluster *cluster.Configuration `description:"Coordination of the Traefik instances of a cluster, electing the leader which alone obtains and renews the ACME certificates." json:"cluster,omitempty" toml:"cluster,omitempty" yaml:"cluster,omitempty" export:"true"`
+
+	Plugins *plugins.Configuration `description:"Plugins of Traefik, interpreted from their Go sources, such as the DNS providers of the ACME resolvers." json:"plugins,omitempty" toml:"plugins,omitempty" yaml:"plugins,omitempty" export:"true"`
+
+	Gossip *gossip.Configuration `description:"Gossip between the Traefik instances sharing, with eventual consistency, the rate limiter counters, the clients tagged by the honeypots, and the tripped circuit breakers." json:"gossip,omitempty" toml:"gossip,omitempty" yaml:"gossip,omitempty" label:"allowEmpty" export:"true"`
 }
 
 // CertificateResolver contains the configuration for the different types of certificates resolver.
@@ -77,23 +117,58 @@ type CertificateResolver struct {
 
 // Global holds the global configuration.
 type Global struct {
-	CheckNewVersion    bool `description:"Periodically check if a new version has been released." json:"checkNewVersion,omitempty" toml:"checkNewVersion,omitempty" yaml:"checkNewVersion,omitempty" label:"allowEmpty" export:"true"`
-	SendAnonymousUsage bool `description:"Periodically send anonymous usage statistics. If the option is not specified, it will be enabled by default." json:"sendAnonymousUsage,omitempty" toml:"sendAnonymousUsage,omitempty" yaml:"sendAnonymousUsage,omitempty" label:"allowEmpty" export:"true"`
+	CheckNewVersion       bool `description:"Periodically check if a new version has been released." json:"checkNewVersion,omitempty" toml:"checkNewVersion,omitempty" yaml:"checkNewVersion,omitempty" label:"allowEmpty" export:"true"`
+	SendAnonymousUsage    bool `description:"Periodically send anonymous usage statistics. If the option is not specified, it will be enabled by default." json:"sendAnonymousUsage,omitempty" toml:"sendAnonymousUsage,omitempty" yaml:"sendAnonymousUsage,omitempty" label:"allowEmpty" export:"true"`
+	AllowFaultInjection   bool `description:"Allow the faultInjection middlewares, for chaos testing. They are rejected otherwise." json:"allowFaultInjection,omitempty" toml:"allowFaultInjection,omitempty" yaml:"allowFaultInjection,omitempty" label:"allowEmpty" export:"true"`
+	GuardInternalServices bool `description:"Reject the routers exposing internal services on the entry points not marked as internal." json:"guardInternalServices,omitempty" toml:"guardInternalServices,omitempty" yaml:"guardInternalServices,omitempty" label:"allowEmpty" export:"true"`
 }
 
 // ServersTransport options to configure communication between Traefik and the servers
 type ServersTransport struct {
-	InsecureSkipVerify  bool                `description:"Disable SSL certificate verification." json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" export:"true"`
-	RootCAs             []tls.FileOrContent `description:"Add cert file for self-signed certificate." json:"rootCAs,omitempty" toml:"rootCAs,omitempty" yaml:"rootCAs,omitempty"`
-	MaxIdleConnsPerHost int                 `description:"If non-zero, controls the maximum idle (keep-alive) to keep per-host. If zero, DefaultMaxIdleConnsPerHost is used" json:"maxIdleConnsPerHost,omitempty" toml:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty" export:"true"`
-	ForwardingTimeouts  *ForwardingTimeouts `description:"Timeouts for requests forwarded to the backend servers." json:"forwardingTimeouts,omitempty" toml:"forwardingTimeouts,omitempty" yaml:"forwardingTimeouts,omitempty" export:"true"`
+	InsecureSkipVerify  bool                        `description:"Disable SSL certificate verification." json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" export:"true"`
+	RootCAs             []tls.FileOrContent         `description:"Add cert file for self-signed certificate." json:"rootCAs,omitempty" toml:"rootCAs,omitempty" yaml:"rootCAs,omitempty"`
+	MaxIdleConnsPerHost int                         `description:"If non-zero, controls the maximum idle (keep-alive) to keep per-host. If zero, DefaultMaxIdleConnsPerHost is used" json:"maxIdleConnsPerHost,omitempty" toml:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty" export:"true"`
+	ForwardingTimeouts  *ForwardingTimeouts         `description:"Timeouts for requests forwarded to the backend servers." json:"forwardingTimeouts,omitempty" toml:"forwardingTimeouts,omitempty" yaml:"forwardingTimeouts,omitempty" export:"true"`
+	AddressFamily       string                      `description:"Address family used to dial the backend servers: ipv4, ipv6, preferIPv4, or preferIPv6. If empty, the order of the resolved addresses is used." json:"addressFamily,omitempty" toml:"addressFamily,omitempty" yaml:"addressFamily,omitempty" export:"true"`
+	FallbackDelay       types.Duration              `description:"The amount of time to wait for a connection to the preferred address family before also dialing the other one. If zero, 300ms is used." json:"fallbackDelay,omitempty" toml:"fallbackDelay,omitempty" yaml:"fallbackDelay,omitempty" export:"true"`
+	LocalAddress        string                      `description:"Local IP address used as the source address of the connections to the backend servers." json:"localAddress,omitempty" toml:"localAddress,omitempty" yaml:"localAddress,omitempty" export:"true"`
+	Interface           string                      `description:"Network interface whose address is used as the source address of the connections to the backend servers." json:"interface,omitempty" toml:"interface,omitempty" yaml:"interface,omitempty" export:"true"`
+	Proxy               *ServersProxy               `description:"Proxy used to reach the backend servers." json:"proxy,omitempty" toml:"proxy,omitempty" yaml:"proxy,omitempty" export:"true"`
+	Resolver            string                      `description:"Name of the resolver used to resolve the host names of the backend servers. If empty, the system resolver is used." json:"resolver,omitempty" toml:"resolver,omitempty" yaml:"resolver,omitempty" export:"true"`
+	Resolvers           map[string]*ServersResolver `description:"Resolvers which can be used to resolve the host names of the backend servers, by name." json:"resolvers,omitempty" toml:"resolvers,omitempty" yaml:"resolvers,omitempty" export:"true"`
 }
 
+// ServersResolver holds the configuration of a DNS resolver of the backend servers host names.
+type ServersResolver struct {
+	Servers     []string       `description:"DNS servers, tried in order: host:port or udp://host:port for DNS over UDP, tcp://host:port for DNS over TCP, tls://host:port for DNS over TLS, or the https URL of a DNS over HTTPS server." json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" export:"true"`
+	Timeout     types.Duration `description:"Timeout of the DNS queries. If zero, 5s is used." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	NegativeTTL types.Duration `description:"Duration during which the host names without address are cached. If zero, they are not cached." json:"negativeTTL,omitempty" toml:"negativeTTL,omitempty" yaml:"negativeTTL,omitempty" export:"true"`
+}
+
+// ServersProxy holds the configuration of the proxy used to reach the backend servers.
+type ServersProxy struct {
+	URL      string `description:"URL of the proxy, with the http or https scheme for an HTTP CONNECT proxy, or with the socks5 scheme." json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty" export:"true"`
+	Username string `description:"Username used to authenticate to the proxy." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password string `description:"Password used to authenticate to the proxy." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" secret:"true"`
+}
+
+// Address families of the servers transport.
+const (
+	AddressFamilyIPv4       = "ipv4"
+	AddressFamilyIPv6       = "ipv6"
+	AddressFamilyPreferIPv4 = "preferIPv4"
+	AddressFamilyPreferIPv6 = "preferIPv6"
+)
+
 // API holds the API configuration
 type API struct {
 	Insecure  bool `description:"Activate API directly on the entryPoint named traefik." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
 	Dashboard bool `description:"Activate dashboard." json:"dashboard,omitempty" toml:"dashboard,omitempty" yaml:"dashboard,omitempty" export:"true"`
 	Debug     bool `description:"Enable additional endpoints for debugging and profiling." json:"debug,omitempty" toml:"debug,omitempty" yaml:"debug,omitempty" export:"true"`
+
+	KeyManagement bool `description:"Enable the endpoints managing the keys of the APIKey middlewares. Not available in insecure mode." json:"keyManagement,omitempty" toml:"keyManagement,omitempty" yaml:"keyManagement,omitempty" export:"true"`
+
+	PathStatistics *PathStatistics `description:"Enable the statistics of the paths using the most bandwidth on each router." json:"pathStatistics,omitempty" toml:"pathStatistics,omitempty" yaml:"pathStatistics,omitempty" export:"true" label:"allowEmpty"`
 	// TODO: Re-enable statistics
 	// Statistics      *types.Statistics `description:"Enable more detailed statistics." json:"statistics,omitempty" toml:"statistics,omitempty" yaml:"statistics,omitempty" export:"true" label:"allowEmpty"`
 	DashboardAssets *assetfs.AssetFS `json:"-" toml:"-" yaml:"-" label:"-"`
@@ -104,6 +179,16 @@ func (a *API) SetDefaults() {
 	a.Dashboard = true
 }
 
+// PathStatistics holds the configuration of the path statistics of the API.
+type PathStatistics struct {
+	MaxPaths int `description:"Maximum number of paths tracked on each router." json:"maxPaths,omitempty" toml:"maxPaths,omitempty" yaml:"maxPaths,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (p *PathStatistics) SetDefaults() {
+	p.MaxPaths = 100
+}
+
 // RespondingTimeouts contains timeout configurations for incoming requests to the Traefik instance.
 type RespondingTimeouts struct {
 	ReadTimeout  types.Duration `description:"ReadTimeout is the maximum duration for reading the entire request, including the body. If zero, no timeout is set." json:"readTimeout,omitempty" toml:"readTimeout,omitempty" yaml:"readTimeout,omitempty" export:"true"`
@@ -175,6 +260,8 @@ type Providers struct {
 	Etcd      *etcd.Provider   `description:"Enable Etcd backend with default settings." json:"etcd,omitempty" toml:"etcd,omitempty" yaml:"etcd,omitempty" export:"true" label:"allowEmpty"`
 	ZooKeeper *zk.Provider     `description:"Enable ZooKeeper backend with default settings." json:"zooKeeper,omitempty" toml:"zooKeeper,omitempty" yaml:"zooKeeper,omitempty" export:"true" label:"allowEmpty"`
 	Redis     *redis.Provider  `description:"Enable Redis backend with default settings." json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" export:"true" label:"allowEmpty"`
+
+	Vault *vault.Provider `description:"Enable the certificates of the Vault PKI secrets engine." json:"vault,omitempty" toml:"vault,omitempty" yaml:"vault,omitempty" export:"true"`
 }
 
 // SetEffectiveConfiguration adds missing configuration parameters derived from existing ones.
@@ -191,6 +278,7 @@ func (c *Configuration) SetEffectiveConfiguration() {
 	if (c.API != nil && c.API.Insecure) ||
 		(c.Ping != nil && !c.Ping.ManualRouting && c.Ping.EntryPoint == DefaultInternalEntryPointName) ||
 		(c.Metrics != nil && c.Metrics.Prometheus != nil && !c.Metrics.Prometheus.ManualRouting && c.Metrics.Prometheus.EntryPoint == DefaultInternalEntryPointName) ||
+		(c.ACMEServer != nil && !c.ACMEServer.ManualRouting && c.ACMEServer.EntryPoint == DefaultInternalEntryPointName) ||
 		(c.Providers != nil && c.Providers.Rest != nil && c.Providers.Rest.Insecure) {
 		if _, ok := c.EntryPoints[DefaultInternalEntryPointName]; !ok {
 			ep := &EntryPoint{Address: ":8080"}
@@ -211,6 +299,10 @@ func (c *Configuration) SetEffectiveConfiguration() {
 		}
 	}
 
+	if c.CRLStorage == "" {
+		c.CRLStorage = "crls"
+	}
+
 	c.initACMEProvider()
 }
 
@@ -236,12 +328,71 @@ func (c *Configuration) ValidateConfiguration() error {
 			return fmt.Errorf("unable to initialize certificates resolver %q with no storage location for the certificates", name)
 		}
 
+		if c.Cluster != nil && resolver.ACME.SharedStorage == nil {
+			return fmt.Errorf("unable to initialize certificates resolver %q in cluster mode with no shared storage, the certificates being obtained by the leader only", name)
+		}
+
+		if dnsChallenge := resolver.ACME.DNSChallenge; dnsChallenge != nil && dnsChallenge.Plugin != nil {
+			var known bool
+			if c.Plugins != nil {
+				_, known = c.Plugins.Local[dnsChallenge.Plugin.Name]
+			}
+			if !known {
+				return fmt.Errorf("unable to initialize certificates resolver %q with the unknown DNS provider plugin %q", name, dnsChallenge.Plugin.Name)
+			}
+		}
+
 		if acmeEmail != "" && resolver.ACME.Email != acmeEmail {
 			return fmt.Errorf("unable to initialize certificates resolver %q, all the acme resolvers must use the same email", name)
 		}
 		acmeEmail = resolver.ACME.Email
 	}
 
+	if c.RunAs != nil && c.PrivilegeSeparation != nil {
+		return errors.New("the run as and privilege separation options cannot be both defined")
+	}
+
+	if c.ServersTransport != nil {
+		switch c.ServersTransport.AddressFamily {
+		case "", AddressFamilyIPv4, AddressFamilyIPv6, AddressFamilyPreferIPv4, AddressFamilyPreferIPv6:
+		default:
+			return fmt.Errorf("unknown servers transport address family %q", c.ServersTransport.AddressFamily)
+		}
+
+		if c.ServersTransport.LocalAddress != "" && c.ServersTransport.Interface != "" {
+			return errors.New("the servers transport local address and interface cannot be both defined")
+		}
+
+		if c.ServersTransport.LocalAddress != "" && net.ParseIP(c.ServersTransport.LocalAddress) == nil {
+			return fmt.Errorf("invalid servers transport local address %q", c.ServersTransport.LocalAddress)
+		}
+
+		if c.ServersTransport.Resolver != "" {
+			if _, ok := c.ServersTransport.Resolvers[c.ServersTransport.Resolver]; !ok {
+				return fmt.Errorf("unknown servers transport resolver %q", c.ServersTransport.Resolver)
+			}
+		}
+
+		for name, resolver := range c.ServersTransport.Resolvers {
+			if resolver == nil || len(resolver.Servers) == 0 {
+				return fmt.Errorf("no DNS server defined for servers transport resolver %q", name)
+			}
+		}
+
+		if c.ServersTransport.Proxy != nil {
+			proxyURL, err := url.Parse(c.ServersTransport.Proxy.URL)
+			if err != nil {
+				return fmt.Errorf("invalid servers transport proxy URL: %w", err)
+			}
+
+			switch proxyURL.Scheme {
+			case "http", "https", "socks5":
+			default:
+				return fmt.Errorf("unsupported servers transport proxy scheme %q", proxyURL.Scheme)
+			}
+		}
+	}
+
 	return nil
 }
 
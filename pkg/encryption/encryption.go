@@ -0,0 +1,131 @@
+// Package encryption encrypts the local state files at rest, such as the ACME storage,
+// so that the private keys they hold are not readable from a copy of the disk.
+//
+// The files are encrypted with AES-GCM, and prefixed with a header identifying them as encrypted,
+// so that the plaintext files written by the previous versions are read as they are, and encrypted when written again.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// header prefixes the encrypted files.
+var header = []byte("traefik-encrypted:v1:")
+
+// Configuration holds the configuration of the encryption at rest of the local state files.
+type Configuration struct {
+	Key          string   `description:"Base64 encoded AES key (16, 24, or 32 bytes) encrypting the local state files, which can be a secret reference." json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty" secret:"true"`
+	PreviousKeys []string `description:"Base64 encoded AES keys, only used to decrypt the files encrypted before a key rotation, which can be secret references." json:"previousKeys,omitempty" toml:"previousKeys,omitempty" yaml:"previousKeys,omitempty" secret:"true"`
+}
+
+// Cipher encrypts and decrypts the local state files.
+// A nil Cipher reads and writes the files in plaintext.
+type Cipher struct {
+	aead     cipher.AEAD
+	previous []cipher.AEAD
+}
+
+// New creates a Cipher from the configuration, or returns nil when the configuration is nil.
+func New(config *Configuration) (*Cipher, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	if config.Key == "" {
+		return nil, errors.New("the encryption key must be defined")
+	}
+
+	aead, err := newAEAD(config.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+
+	c := &Cipher{aead: aead}
+	for i, key := range config.PreviousKeys {
+		previous, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous encryption key %d: %w", i, err)
+		}
+		c.previous = append(c.previous, previous)
+	}
+
+	return c, nil
+}
+
+func newAEAD(key string) (cipher.AEAD, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// IsEncrypted returns whether the data is encrypted.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+// Encrypt encrypts the data with the current key.
+// When the Cipher is nil, the data is returned as it is.
+func (c *Cipher) Encrypt(data []byte) ([]byte, error) {
+	if c == nil {
+		return data, nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, data, header)
+
+	encrypted := make([]byte, len(header)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(encrypted, header)
+	base64.StdEncoding.Encode(encrypted[len(header):], sealed)
+
+	return encrypted, nil
+}
+
+// Decrypt decrypts the data, with the current key or one of the previous keys.
+// The data which is not encrypted is returned as it is.
+// It also returns whether the data should be written again, to be encrypted with the current key.
+func (c *Cipher) Decrypt(data []byte) ([]byte, bool, error) {
+	if !IsEncrypted(data) {
+		return data, c != nil, nil
+	}
+
+	if c == nil {
+		return nil, false, errors.New("the data is encrypted, but no encryption key is defined")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data[len(header):])))
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid encrypted data: %w", err)
+	}
+
+	for i, aead := range append([]cipher.AEAD{c.aead}, c.previous...) {
+		if len(sealed) < aead.NonceSize() {
+			return nil, false, errors.New("invalid encrypted data: too short")
+		}
+
+		plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], header)
+		if err == nil {
+			return plaintext, i > 0, nil
+		}
+	}
+
+	return nil, false, errors.New("unable to decrypt the data with the encryption keys")
+}
@@ -0,0 +1,126 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	key      = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	otherKey = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  *Configuration
+		wantNil bool
+		wantErr bool
+	}{
+		{
+			desc:    "no configuration",
+			wantNil: true,
+		},
+		{
+			desc:   "valid keys",
+			config: &Configuration{Key: key, PreviousKeys: []string{otherKey}},
+		},
+		{
+			desc:    "missing key",
+			config:  &Configuration{},
+			wantErr: true,
+		},
+		{
+			desc:    "key not base64 encoded",
+			config:  &Configuration{Key: "not a key!"},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid key size",
+			config:  &Configuration{Key: base64.StdEncoding.EncodeToString([]byte("short"))},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid previous key",
+			config:  &Configuration{Key: key, PreviousKeys: []string{"short"}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := New(test.config)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.wantNil, c == nil)
+		})
+	}
+}
+
+func TestCipher(t *testing.T) {
+	c, err := New(&Configuration{Key: key})
+	require.NoError(t, err)
+
+	plaintext := []byte(`{"default":{"Account":null}}`)
+
+	encrypted, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "Account")
+
+	decrypted, rewrite, err := c.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.False(t, rewrite)
+
+	// The plaintext data is read as it is, and must be written again to be encrypted.
+	decrypted, rewrite, err = c.Decrypt(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.True(t, rewrite)
+
+	// The data encrypted with a previous key is decrypted, and must be written again with the current key.
+	rotated, err := New(&Configuration{Key: otherKey, PreviousKeys: []string{key}})
+	require.NoError(t, err)
+
+	decrypted, rewrite, err = rotated.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.True(t, rewrite)
+
+	// The data cannot be decrypted with an unknown key, nor without key.
+	other, err := New(&Configuration{Key: otherKey})
+	require.NoError(t, err)
+
+	_, _, err = other.Decrypt(encrypted)
+	assert.Error(t, err)
+
+	var noCipher *Cipher
+	_, _, err = noCipher.Decrypt(encrypted)
+	assert.Error(t, err)
+}
+
+func TestCipher_nil(t *testing.T) {
+	var c *Cipher
+
+	plaintext := []byte(`{"default":{"Account":null}}`)
+
+	encrypted, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, encrypted)
+
+	decrypted, rewrite, err := c.Decrypt(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+	assert.False(t, rewrite)
+}
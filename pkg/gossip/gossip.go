@@ -0,0 +1,290 @@
+// Package gossip shares the state of the middlewares, such as the rate limiter counters, the banned clients, and the tripped circuit breakers,
+// between the Traefik instances, with a peer to peer gossip protocol.
+package gossip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdlog "log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/hashicorp/memberlist"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// flushInterval is the interval of the broadcast of the local changes of the state.
+	flushInterval = 200 * time.Millisecond
+	pruneInterval = time.Minute
+	retryDelay    = 5 * time.Second
+	leaveTimeout  = 5 * time.Second
+)
+
+// Configuration holds the configuration of the gossip sharing the state of the middlewares between the Traefik instances.
+type Configuration struct {
+	BindAddr      string         `description:"Address, in TCP and UDP, the gossip listens on." json:"bindAddr,omitempty" toml:"bindAddr,omitempty" yaml:"bindAddr,omitempty" export:"true"`
+	AdvertiseAddr string         `description:"Address advertised to the other instances, defaults to the bind address." json:"advertiseAddr,omitempty" toml:"advertiseAddr,omitempty" yaml:"advertiseAddr,omitempty" export:"true"`
+	Join          []string       `description:"Addresses of the instances joined on startup." json:"join,omitempty" toml:"join,omitempty" yaml:"join,omitempty"`
+	NodeName      string         `description:"Name of the instance, defaults to the hostname." json:"nodeName,omitempty" toml:"nodeName,omitempty" yaml:"nodeName,omitempty" export:"true"`
+	SecretKey     string         `description:"Key encrypting the gossip, of 16, 24, or 32 bytes, required unless bindAddr is a loopback address." json:"secretKey,omitempty" toml:"secretKey,omitempty" yaml:"secretKey,omitempty" secret:"true"`
+	SyncInterval  types.Duration `description:"Interval of the full synchronization of the state with a random instance." json:"syncInterval,omitempty" toml:"syncInterval,omitempty" yaml:"syncInterval,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Configuration) SetDefaults() {
+	c.BindAddr = ":7946"
+	c.SyncInterval = types.Duration(30 * time.Second)
+}
+
+// Node is the member of the gossip of an instance.
+type Node struct {
+	state *State
+	queue *memberlist.TransmitLimitedQueue
+	join  []string
+
+	// list is set once the gossip is started, while the delegate may already be called.
+	listMu sync.RWMutex
+	list   *memberlist.Memberlist
+}
+
+// NewNode creates the member of the gossip, which starts listening to the other instances.
+func NewNode(config *Configuration) (*Node, error) {
+	if config.SyncInterval <= 0 {
+		return nil, errors.New("syncInterval must be positive")
+	}
+
+	name := config.NodeName
+	if name == "" {
+		var err error
+		name, err = os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the hostname as node name: %w", err)
+		}
+	}
+
+	listConfig := memberlist.DefaultLANConfig()
+	listConfig.Name = name
+	listConfig.PushPullInterval = time.Duration(config.SyncInterval)
+	listConfig.Logger = stdlog.New(log.WithoutContext().WriterLevel(logrus.DebugLevel), "memberlist: ", 0)
+
+	host, port, err := splitAddr(config.BindAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bindAddr: %w", err)
+	}
+	if host == "" {
+		host = "0.0.0.0"
+	}
+	listConfig.BindAddr = host
+	listConfig.BindPort = port
+
+	if config.AdvertiseAddr != "" {
+		host, port, err = splitAddr(config.AdvertiseAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid advertiseAddr: %w", err)
+		}
+		listConfig.AdvertiseAddr = host
+		listConfig.AdvertisePort = port
+	}
+
+	// Any peer reaching the gossip can change the state of the middlewares of all the instances,
+	// so the gossip must be encrypted, hence authenticated, unless it is only reachable locally.
+	switch len(config.SecretKey) {
+	case 0:
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return nil, errors.New("secretKey is required unless bindAddr is a loopback address")
+		}
+	case 16, 24, 32:
+		listConfig.SecretKey = []byte(config.SecretKey)
+	default:
+		return nil, errors.New("secretKey must be of 16, 24, or 32 bytes")
+	}
+
+	node := &Node{
+		state: NewState(name),
+		join:  config.Join,
+	}
+
+	node.queue = &memberlist.TransmitLimitedQueue{
+		NumNodes:       node.numMembers,
+		RetransmitMult: listConfig.RetransmitMult,
+	}
+
+	listConfig.Delegate = &delegate{node: node}
+
+	list, err := memberlist.Create(listConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the gossip: %w", err)
+	}
+
+	node.listMu.Lock()
+	node.list = list
+	node.listMu.Unlock()
+
+	return node, nil
+}
+
+func splitAddr(addr string) (string, int, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q: %w", port, err)
+	}
+
+	return host, portNumber, nil
+}
+
+func (n *Node) numMembers() int {
+	n.listMu.RLock()
+	defer n.listMu.RUnlock()
+
+	if n.list == nil {
+		return 1
+	}
+	return n.list.NumMembers()
+}
+
+// State returns the state shared by the node.
+func (n *Node) State() *State {
+	return n.state
+}
+
+// Run joins the other instances, and broadcasts the local changes of the state,
+// until the context is done, and then leaves the gossip.
+func (n *Node) Run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	if len(n.join) > 0 {
+		n.joinPeers(ctx)
+	}
+
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-flush.C:
+			n.broadcast(ctx, n.state.flush())
+		case <-prune.C:
+			n.state.prune()
+		case <-ctx.Done():
+			if err := n.list.Leave(leaveTimeout); err != nil {
+				logger.Errorf("Unable to leave the gossip: %v", err)
+			}
+			if err := n.list.Shutdown(); err != nil {
+				logger.Errorf("Unable to stop the gossip: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// joinPeers joins at least one of the instances to join, retrying until it does, or until the context is done.
+func (n *Node) joinPeers(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	for {
+		joined, err := n.list.Join(n.join)
+		if joined > 0 {
+			logger.Infof("Joined %d instance(s) of the gossip", joined)
+			return
+		}
+
+		logger.Errorf("Unable to join the gossip: %v", err)
+
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// broadcast queues the changes of the state to be gossiped to the other instances.
+func (n *Node) broadcast(ctx context.Context, u update) {
+	if u.empty() {
+		return
+	}
+
+	// The messages are sent along with the gossip of the members, in a UDP packet.
+	messages, err := split(u, 1024)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to encode the gossip: %v", err)
+		return
+	}
+
+	for _, message := range messages {
+		n.queue.QueueBroadcast(broadcastMessage(message))
+	}
+}
+
+// broadcastMessage is a message gossiped to the other instances.
+// The changes are idempotent, so that a message never invalidates another one.
+type broadcastMessage []byte
+
+func (b broadcastMessage) Invalidates(memberlist.Broadcast) bool {
+	return false
+}
+
+func (b broadcastMessage) Message() []byte {
+	return b
+}
+
+func (b broadcastMessage) Finished() {}
+
+// delegate merges the messages and the states of the other instances into the state of the node.
+type delegate struct {
+	node *Node
+}
+
+func (d *delegate) NodeMeta(int) []byte {
+	return nil
+}
+
+func (d *delegate) NotifyMsg(msg []byte) {
+	var u update
+	if err := json.Unmarshal(msg, &u); err != nil {
+		log.WithoutContext().Errorf("Unable to decode the gossip: %v", err)
+		return
+	}
+
+	// The changes unknown so far are gossiped in turn, to reach all the instances.
+	d.node.broadcast(context.Background(), d.node.state.merge(u))
+}
+
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return d.node.queue.GetBroadcasts(overhead, limit)
+}
+
+func (d *delegate) LocalState(bool) []byte {
+	data, err := json.Marshal(d.node.state.snapshot())
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to encode the gossip state: %v", err)
+		return nil
+	}
+	return data
+}
+
+func (d *delegate) MergeRemoteState(buf []byte, _ bool) {
+	var u update
+	if err := json.Unmarshal(buf, &u); err != nil {
+		log.WithoutContext().Errorf("Unable to decode the gossip state: %v", err)
+		return
+	}
+
+	d.node.state.merge(u)
+}
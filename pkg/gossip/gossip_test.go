@@ -0,0 +1,51 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNode_invalid(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config Configuration
+	}{
+		{
+			desc:   "no secret key on all the addresses",
+			config: Configuration{BindAddr: ":0"},
+		},
+		{
+			desc:   "no secret key on a non-loopback address",
+			config: Configuration{BindAddr: "10.0.0.1:0"},
+		},
+		{
+			desc:   "no secret key on a host name",
+			config: Configuration{BindAddr: "localhost:0"},
+		},
+		{
+			desc:   "invalid secret key length",
+			config: Configuration{BindAddr: "127.0.0.1:0", SecretKey: "foo"},
+		},
+		{
+			desc:   "invalid bind address",
+			config: Configuration{BindAddr: "foo", SecretKey: "0123456789abcdef"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := test.config
+			config.NodeName = "foo"
+			config.SyncInterval = types.Duration(time.Second)
+
+			_, err := NewNode(&config)
+			assert.Error(t, err)
+		})
+	}
+}
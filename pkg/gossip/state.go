@@ -0,0 +1,326 @@
+package gossip
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// counterTTL is how long the counter of a key is kept once it is no longer incremented.
+const counterTTL = 10 * time.Minute
+
+// State is the state shared by the instances, converging eventually whatever the order and the duplication of the updates:
+// the counters are the sum of a counter per instance, only incremented by the instance,
+// and the bans and trips are merged by keeping their latest expiry.
+type State struct {
+	node string
+
+	mu       sync.Mutex
+	counters map[string]map[string]counter
+	bans     map[string]time.Time
+	trips    map[string]time.Time
+	// pending holds the local changes not broadcast yet.
+	pending update
+
+	now func() time.Time
+}
+
+// NewState creates the shared state of the instance named node.
+func NewState(node string) *State {
+	return &State{
+		node:     node,
+		counters: make(map[string]map[string]counter),
+		bans:     make(map[string]time.Time),
+		trips:    make(map[string]time.Time),
+		pending:  newUpdate(),
+		now:      time.Now,
+	}
+}
+
+type counter struct {
+	Value   int64     `json:"value"`
+	Updated time.Time `json:"updated"`
+}
+
+// update is a set of changes of the state, merged into the state of the other instances.
+type update struct {
+	// Counters holds the counters of each key by instance.
+	Counters map[string]map[string]counter `json:"counters,omitempty"`
+	Bans     map[string]time.Time          `json:"bans,omitempty"`
+	Trips    map[string]time.Time          `json:"trips,omitempty"`
+}
+
+func newUpdate() update {
+	return update{
+		Counters: make(map[string]map[string]counter),
+		Bans:     make(map[string]time.Time),
+		Trips:    make(map[string]time.Time),
+	}
+}
+
+func (u update) empty() bool {
+	return len(u.Counters) == 0 && len(u.Bans) == 0 && len(u.Trips) == 0
+}
+
+// AddHits adds hits to the counter of the key of the instance.
+func (s *State) AddHits(key string, hits int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes, ok := s.counters[key]
+	if !ok {
+		nodes = make(map[string]counter)
+		s.counters[key] = nodes
+	}
+
+	c := nodes[s.node]
+	c.Value += hits
+	c.Updated = s.now()
+	nodes[s.node] = c
+
+	s.pending.Counters[key] = map[string]counter{s.node: c}
+}
+
+// RemoteHits returns the hits counted by the other instances for the key.
+func (s *State) RemoteHits(key string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var hits int64
+	for node, c := range s.counters[key] {
+		if node != s.node {
+			hits += c.Value
+		}
+	}
+	return hits
+}
+
+// Ban bans the key until the given time.
+func (s *State) Ban(key string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if mergeExpiry(s.bans, key, until, s.now()) {
+		s.pending.Bans[key] = until
+	}
+}
+
+// Banned returns whether the key is banned.
+func (s *State) Banned(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.now().Before(s.bans[key])
+}
+
+// Trip trips the key, e.g. a circuit breaker, until the given time.
+func (s *State) Trip(key string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if mergeExpiry(s.trips, key, until, s.now()) {
+		s.pending.Trips[key] = until
+	}
+}
+
+// Tripped returns whether the key is tripped.
+func (s *State) Tripped(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.now().Before(s.trips[key])
+}
+
+// mergeExpiry sets the expiry of the key when it is later than the current one, and returns whether it did.
+func mergeExpiry(expiries map[string]time.Time, key string, until, now time.Time) bool {
+	if !until.After(now) || !until.After(expiries[key]) {
+		return false
+	}
+
+	expiries[key] = until
+	return true
+}
+
+// merge merges the update into the state, and returns the changes it made.
+func (s *State) merge(u update) update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	changes := newUpdate()
+
+	for key, nodes := range u.Counters {
+		for node, c := range nodes {
+			if now.Sub(c.Updated) >= counterTTL {
+				continue
+			}
+
+			current, ok := s.counters[key]
+			if !ok {
+				current = make(map[string]counter)
+				s.counters[key] = current
+			}
+
+			if c.Value <= current[node].Value {
+				continue
+			}
+
+			current[node] = c
+
+			if _, ok := changes.Counters[key]; !ok {
+				changes.Counters[key] = make(map[string]counter)
+			}
+			changes.Counters[key][node] = c
+		}
+	}
+
+	for key, until := range u.Bans {
+		if mergeExpiry(s.bans, key, until, now) {
+			changes.Bans[key] = until
+		}
+	}
+
+	for key, until := range u.Trips {
+		if mergeExpiry(s.trips, key, until, now) {
+			changes.Trips[key] = until
+		}
+	}
+
+	return changes
+}
+
+// flush returns the local changes not broadcast yet.
+func (s *State) flush() update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pending
+	s.pending = newUpdate()
+	return pending
+}
+
+// snapshot returns the whole state, as an update.
+func (s *State) snapshot() update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := newUpdate()
+	for key, nodes := range s.counters {
+		u.Counters[key] = make(map[string]counter, len(nodes))
+		for node, c := range nodes {
+			u.Counters[key][node] = c
+		}
+	}
+	for key, until := range s.bans {
+		u.Bans[key] = until
+	}
+	for key, until := range s.trips {
+		u.Trips[key] = until
+	}
+	return u
+}
+
+// prune removes the expired bans and trips, and the counters no longer incremented.
+func (s *State) prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	for key, nodes := range s.counters {
+		for node, c := range nodes {
+			if now.Sub(c.Updated) >= counterTTL {
+				delete(nodes, node)
+			}
+		}
+		if len(nodes) == 0 {
+			delete(s.counters, key)
+		}
+	}
+
+	for _, expiries := range []map[string]time.Time{s.bans, s.trips} {
+		for key, until := range expiries {
+			if !now.Before(until) {
+				delete(expiries, key)
+			}
+		}
+	}
+}
+
+// split splits the update into messages of at most limit bytes, as far as a single change fits in limit bytes.
+func split(u update, limit int) ([][]byte, error) {
+	var messages [][]byte
+
+	current := newUpdate()
+	size := 0
+
+	add := func(change update) error {
+		data, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+
+		if size > 0 && size+len(data) > limit {
+			message, err := json.Marshal(current)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, message)
+
+			current = newUpdate()
+			size = 0
+		}
+
+		for key, nodes := range change.Counters {
+			if _, ok := current.Counters[key]; !ok {
+				current.Counters[key] = make(map[string]counter)
+			}
+			for node, c := range nodes {
+				current.Counters[key][node] = c
+			}
+		}
+		for key, until := range change.Bans {
+			current.Bans[key] = until
+		}
+		for key, until := range change.Trips {
+			current.Trips[key] = until
+		}
+		size += len(data)
+
+		return nil
+	}
+
+	for key, nodes := range u.Counters {
+		for node, c := range nodes {
+			change := newUpdate()
+			change.Counters[key] = map[string]counter{node: c}
+			if err := add(change); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for key, until := range u.Bans {
+		change := newUpdate()
+		change.Bans[key] = until
+		if err := add(change); err != nil {
+			return nil, err
+		}
+	}
+	for key, until := range u.Trips {
+		change := newUpdate()
+		change.Trips[key] = until
+		if err := add(change); err != nil {
+			return nil, err
+		}
+	}
+
+	if !current.empty() {
+		message, err := json.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
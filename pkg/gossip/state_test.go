@@ -0,0 +1,143 @@
+package gossip
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_counters(t *testing.T) {
+	node1 := NewState("node1")
+	node2 := NewState("node2")
+
+	node1.AddHits("foo", 3)
+	node2.merge(node1.flush())
+
+	assert.Equal(t, int64(0), node1.RemoteHits("foo"))
+	assert.Equal(t, int64(3), node2.RemoteHits("foo"))
+
+	node2.AddHits("foo", 2)
+	node1.merge(node2.snapshot())
+
+	assert.Equal(t, int64(2), node1.RemoteHits("foo"))
+	assert.Equal(t, int64(3), node2.RemoteHits("foo"))
+
+	// The updates are idempotent, and the outdated ones are ignored.
+	outdated := node1.snapshot()
+	node1.AddHits("foo", 1)
+	node2.merge(node1.flush())
+	node2.merge(node1.snapshot())
+	node2.merge(outdated)
+
+	assert.Equal(t, int64(4), node2.RemoteHits("foo"))
+	assert.Equal(t, int64(0), node2.RemoteHits("bar"))
+}
+
+func TestState_expiries(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	node1 := NewState("node1")
+	node1.now = func() time.Time { return now }
+	node2 := NewState("node2")
+	node2.now = func() time.Time { return now }
+
+	node1.Ban("foo", now.Add(time.Minute))
+	node1.Trip("bar", now.Add(10*time.Second))
+	// The expired bans are ignored.
+	node1.Ban("baz", now.Add(-time.Minute))
+
+	node2.Ban("foo", now.Add(2*time.Minute))
+	node2.merge(node1.flush())
+
+	assert.True(t, node2.Banned("foo"))
+	assert.True(t, node2.Tripped("bar"))
+	assert.False(t, node2.Banned("bar"))
+	assert.False(t, node2.Banned("baz"))
+
+	// The latest expiry wins.
+	node1.merge(node2.flush())
+	now = now.Add(90 * time.Second)
+
+	assert.True(t, node1.Banned("foo"))
+	assert.False(t, node1.Tripped("bar"))
+}
+
+func TestState_merge_changes(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	node1 := NewState("node1")
+	node1.now = func() time.Time { return now }
+	node2 := NewState("node2")
+	node2.now = func() time.Time { return now }
+
+	node1.AddHits("foo", 1)
+	node1.Ban("bar", now.Add(time.Minute))
+	u := node1.flush()
+
+	// Only the changes unknown so far are gossiped in turn.
+	changes := node2.merge(u)
+	assert.Equal(t, u, changes)
+
+	changes = node2.merge(u)
+	assert.True(t, changes.empty())
+}
+
+func TestState_prune(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	state := NewState("node1")
+	state.now = func() time.Time { return now }
+
+	state.AddHits("foo", 1)
+	state.Ban("bar", now.Add(time.Minute))
+	state.Trip("baz", now.Add(time.Hour))
+
+	now = now.Add(counterTTL)
+	state.prune()
+
+	assert.Equal(t, update{
+		Counters: map[string]map[string]counter{},
+		Bans:     map[string]time.Time{},
+		Trips:    map[string]time.Time{"baz": now.Add(time.Hour - counterTTL)},
+	}, state.snapshot())
+}
+
+func TestSplit(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	u := newUpdate()
+	for i := 0; i < 50; i++ {
+		u.Counters[fmt.Sprintf("ratelimit@file/10.0.0.%d", i)] = map[string]counter{"node1": {Value: int64(i), Updated: now}}
+		u.Bans[fmt.Sprintf("honeypot@file/10.0.1.%d", i)] = now
+	}
+	u.Trips["circuitbreaker@file"] = now
+
+	messages, err := split(u, 512)
+	require.NoError(t, err)
+	require.Greater(t, len(messages), 1)
+
+	merged := newUpdate()
+	for _, message := range messages {
+		assert.LessOrEqual(t, len(message), 512)
+
+		var part update
+		err = json.Unmarshal(message, &part)
+		require.NoError(t, err)
+
+		for key, nodes := range part.Counters {
+			merged.Counters[key] = nodes
+		}
+		for key, until := range part.Bans {
+			merged.Bans[key] = until
+		}
+		for key, until := range part.Trips {
+			merged.Trips[key] = until
+		}
+	}
+
+	assert.Equal(t, u, merged)
+}
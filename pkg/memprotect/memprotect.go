@@ -0,0 +1,66 @@
+// Package memprotect protects the key material held in memory,
+// by locking the memory of the process so that it is never swapped to disk, by disabling its core dumps,
+// and by zeroizing the private keys which are not used anymore.
+package memprotect
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// errUnsupported is returned by the protections which are not supported by the platform.
+var errUnsupported = errors.New("not supported on this platform")
+
+// Configuration holds the configuration of the protection of the key material in memory.
+type Configuration struct {
+	LockMemory      bool           `description:"Lock the memory of the process, so that the keys are never swapped to disk." json:"lockMemory,omitempty" toml:"lockMemory,omitempty" yaml:"lockMemory,omitempty" export:"true"`
+	DisableCoreDump bool           `description:"Disable the core dumps of the process, and its inspection by the other unprivileged processes." json:"disableCoreDump,omitempty" toml:"disableCoreDump,omitempty" yaml:"disableCoreDump,omitempty" export:"true"`
+	ZeroizeDelay    types.Duration `description:"Delay before zeroizing the private keys of the replaced certificates, letting the TLS handshakes in progress complete." json:"zeroizeDelay,omitempty" toml:"zeroizeDelay,omitempty" yaml:"zeroizeDelay,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Configuration) SetDefaults() {
+	c.LockMemory = true
+	c.DisableCoreDump = true
+	c.ZeroizeDelay = types.Duration(time.Minute)
+}
+
+// Apply applies the protections of the configuration to the process.
+// The protections which are not supported by the platform are skipped with a warning.
+func Apply(config *Configuration) error {
+	if config == nil {
+		return nil
+	}
+
+	logger := log.WithoutContext()
+
+	if config.LockMemory {
+		err := lockMemory()
+		switch {
+		case errors.Is(err, errUnsupported):
+			logger.Warnf("Unable to lock the memory: %v", err)
+		case err != nil:
+			return fmt.Errorf("unable to lock the memory (the CAP_IPC_LOCK capability, or a sufficient RLIMIT_MEMLOCK, is required): %w", err)
+		default:
+			logger.Info("The memory is locked")
+		}
+	}
+
+	if config.DisableCoreDump {
+		err := disableCoreDump()
+		switch {
+		case errors.Is(err, errUnsupported):
+			logger.Warnf("Unable to disable the core dumps: %v", err)
+		case err != nil:
+			return fmt.Errorf("unable to disable the core dumps: %w", err)
+		default:
+			logger.Info("The core dumps are disabled")
+		}
+	}
+
+	return nil
+}
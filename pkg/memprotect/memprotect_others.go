@@ -0,0 +1,11 @@
+// +build !linux
+
+package memprotect
+
+func lockMemory() error {
+	return errUnsupported
+}
+
+func disableCoreDump() error {
+	return errUnsupported
+}
@@ -0,0 +1,76 @@
+package memprotect
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"math/big"
+)
+
+// ZeroizeCertificates zeroizes the private keys of the certificates.
+func ZeroizeCertificates(certs []*tls.Certificate) {
+	for _, cert := range certs {
+		if cert != nil {
+			ZeroizeKey(cert.PrivateKey)
+		}
+	}
+}
+
+// Zeroizer is implemented by the private keys zeroizing themselves, such as the keys parsed on their first use.
+type Zeroizer interface {
+	Zeroize()
+}
+
+// ZeroizeKey zeroizes the RSA, ECDSA, and Ed25519 private keys, and the keys implementing Zeroizer.
+// The copies of the key made by the standard library, if any, are not reachable, and thus not zeroized.
+func ZeroizeKey(key crypto.PrivateKey) {
+	switch k := key.(type) {
+	case Zeroizer:
+		k.Zeroize()
+
+	case *rsa.PrivateKey:
+		zeroizeInt(k.D)
+		for _, prime := range k.Primes {
+			zeroizeInt(prime)
+		}
+		zeroizeInt(k.Precomputed.Dp)
+		zeroizeInt(k.Precomputed.Dq)
+		zeroizeInt(k.Precomputed.Qinv)
+		for _, value := range k.Precomputed.CRTValues {
+			zeroizeInt(value.Exp)
+			zeroizeInt(value.Coeff)
+			zeroizeInt(value.R)
+		}
+
+	case *ecdsa.PrivateKey:
+		zeroizeInt(k.D)
+
+	case ed25519.PrivateKey:
+		zeroizeBytes(k)
+
+	case *ed25519.PrivateKey:
+		if k != nil {
+			zeroizeBytes(*k)
+		}
+	}
+}
+
+func zeroizeInt(i *big.Int) {
+	if i == nil {
+		return
+	}
+
+	words := i.Bits()
+	for j := range words {
+		words[j] = 0
+	}
+	i.SetInt64(0)
+}
+
+func zeroizeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,58 @@
+package memprotect
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroizeCertificates(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	zeroizer := &fakeZeroizer{}
+
+	ZeroizeCertificates([]*tls.Certificate{
+		{PrivateKey: rsaKey},
+		{PrivateKey: ecdsaKey},
+		{PrivateKey: ed25519Key},
+		{PrivateKey: zeroizer},
+		{},
+		nil,
+	})
+
+	assert.Equal(t, 0, rsaKey.D.Sign())
+	for _, prime := range rsaKey.Primes {
+		assert.Equal(t, 0, prime.Sign())
+	}
+	assert.Equal(t, 0, rsaKey.Precomputed.Dp.Sign())
+	assert.Equal(t, 0, rsaKey.Precomputed.Dq.Sign())
+	assert.Equal(t, 0, rsaKey.Precomputed.Qinv.Sign())
+
+	assert.Equal(t, 0, ecdsaKey.D.Sign())
+
+	assert.Equal(t, make([]byte, ed25519.PrivateKeySize), []byte(ed25519Key))
+
+	assert.True(t, zeroizer.zeroized)
+}
+
+type fakeZeroizer struct {
+	zeroized bool
+}
+
+func (z *fakeZeroizer) Zeroize() {
+	z.zeroized = true
+}
@@ -32,6 +32,10 @@ const (
 	ddEntryPointOpenConnsName     = "entrypoint.connections.open"
 	ddOpenConnsName               = "service.connections.open"
 	ddServerUpName                = "service.server.up"
+	ddGRPCActiveStreamsName       = "service.grpc.streams.active"
+	ddGRPCMessagesName            = "service.grpc.messages.total"
+	ddGRPCStreamDurationName      = "service.grpc.stream.duration"
+	ddGRPCStreamsName             = "service.grpc.streams.total"
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -61,6 +65,10 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 		registry.serviceRetriesCounter = datadogClient.NewCounter(ddRetriesTotalName, 1.0)
 		registry.serviceOpenConnsGauge = datadogClient.NewGauge(ddOpenConnsName)
 		registry.serviceServerUpGauge = datadogClient.NewGauge(ddServerUpName)
+		registry.serviceGRPCActiveStreamsGauge = datadogClient.NewGauge(ddGRPCActiveStreamsName)
+		registry.serviceGRPCMessagesCounter = datadogClient.NewCounter(ddGRPCMessagesName, 1.0)
+		registry.serviceGRPCStreamDurationHistogram, _ = NewHistogramWithScale(datadogClient.NewHistogram(ddGRPCStreamDurationName, 1.0), time.Second)
+		registry.serviceGRPCStreamsCounter = datadogClient.NewCounter(ddGRPCStreamsName, 1.0)
 	}
 
 	return registry
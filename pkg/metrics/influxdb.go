@@ -38,6 +38,10 @@ const (
 	influxDBEntryPointOpenConnsName     = "traefik.entrypoint.connections.open"
 	influxDBOpenConnsName               = "traefik.service.connections.open"
 	influxDBServerUpName                = "traefik.service.server.up"
+	influxDBGRPCActiveStreamsName       = "traefik.service.grpc.streams.active"
+	influxDBGRPCMessagesName            = "traefik.service.grpc.messages.total"
+	influxDBGRPCStreamDurationName      = "traefik.service.grpc.stream.duration"
+	influxDBGRPCStreamsName             = "traefik.service.grpc.streams.total"
 )
 
 const (
@@ -75,6 +79,10 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 		registry.serviceRetriesCounter = influxDBClient.NewCounter(influxDBRetriesTotalName)
 		registry.serviceOpenConnsGauge = influxDBClient.NewGauge(influxDBOpenConnsName)
 		registry.serviceServerUpGauge = influxDBClient.NewGauge(influxDBServerUpName)
+		registry.serviceGRPCActiveStreamsGauge = influxDBClient.NewGauge(influxDBGRPCActiveStreamsName)
+		registry.serviceGRPCMessagesCounter = influxDBClient.NewCounter(influxDBGRPCMessagesName)
+		registry.serviceGRPCStreamDurationHistogram, _ = NewHistogramWithScale(influxDBClient.NewHistogram(influxDBGRPCStreamDurationName), time.Second)
+		registry.serviceGRPCStreamsCounter = influxDBClient.NewCounter(influxDBGRPCStreamsName)
 	}
 
 	return registry
@@ -14,18 +14,29 @@ type Registry interface {
 	IsEpEnabled() bool
 	// IsSvcEnabled shows whether metrics instrumentation is enabled on services.
 	IsSvcEnabled() bool
+	// IsRouterEnabled shows whether metrics instrumentation is enabled on routers.
+	IsRouterEnabled() bool
 
 	// server metrics
 	ConfigReloadsCounter() metrics.Counter
 	ConfigReloadsFailureCounter() metrics.Counter
 	LastConfigReloadSuccessGauge() metrics.Gauge
 	LastConfigReloadFailureGauge() metrics.Gauge
+	ConfigQueueDepthGauge() metrics.Gauge
+	ConfigMessagesDroppedCounter() metrics.Counter
+	ConfigApplyDurationHistogram() metrics.Histogram
+	ConfigWarningsCounter() metrics.Counter
 
 	// entry point metrics
 	EntryPointReqsCounter() metrics.Counter
 	EntryPointReqsTLSCounter() metrics.Counter
 	EntryPointReqDurationHistogram() ScalableHistogram
 	EntryPointOpenConnsGauge() metrics.Gauge
+	EntryPointClientHelloFailuresCounter() metrics.Counter
+
+	// router metrics
+	RouterReqSizeHistogram() metrics.Histogram
+	RouterRespSizeHistogram() metrics.Histogram
 
 	// service metrics
 	ServiceReqsCounter() metrics.Counter
@@ -34,6 +45,31 @@ type Registry interface {
 	ServiceOpenConnsGauge() metrics.Gauge
 	ServiceRetriesCounter() metrics.Counter
 	ServiceServerUpGauge() metrics.Gauge
+	ServiceTCPDrainedConnsCounter() metrics.Counter
+
+	// gRPC metrics
+	ServiceGRPCActiveStreamsGauge() metrics.Gauge
+	ServiceGRPCMessagesCounter() metrics.Counter
+	ServiceGRPCStreamDurationHistogram() ScalableHistogram
+	ServiceGRPCStreamsCounter() metrics.Counter
+
+	// ACME metrics
+	ACMECertificateStatusGauge() metrics.Gauge
+	ACMECertificateNotAfterGauge() metrics.Gauge
+	ACMEChallengeRequestsCounter() metrics.Counter
+
+	// TLS metrics
+	TLSCertsNotAfterGauge() metrics.Gauge
+	TLSHandshakeErrorsCounter() metrics.Counter
+
+	// experiment metrics
+	ExperimentExposuresCounter() metrics.Counter
+
+	// shadow metrics
+	ShadowVerdictsCounter() metrics.Counter
+
+	// redirect map metrics
+	RedirectMapHitsCounter() metrics.Counter
 }
 
 // NewVoidRegistry is a noop implementation of metrics.Registry.
@@ -50,16 +86,36 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var configReloadsFailureCounter []metrics.Counter
 	var lastConfigReloadSuccessGauge []metrics.Gauge
 	var lastConfigReloadFailureGauge []metrics.Gauge
+	var configQueueDepthGauge []metrics.Gauge
+	var configMessagesDroppedCounter []metrics.Counter
+	var configApplyDurationHistogram []metrics.Histogram
+	var configWarningsCounter []metrics.Counter
 	var entryPointReqsCounter []metrics.Counter
 	var entryPointReqsTLSCounter []metrics.Counter
 	var entryPointReqDurationHistogram []ScalableHistogram
 	var entryPointOpenConnsGauge []metrics.Gauge
+	var entryPointHelloFailuresCounter []metrics.Counter
+	var routerReqSizeHistogram []metrics.Histogram
+	var routerRespSizeHistogram []metrics.Histogram
 	var serviceReqsCounter []metrics.Counter
 	var serviceReqsTLSCounter []metrics.Counter
 	var serviceReqDurationHistogram []ScalableHistogram
 	var serviceOpenConnsGauge []metrics.Gauge
 	var serviceRetriesCounter []metrics.Counter
 	var serviceServerUpGauge []metrics.Gauge
+	var serviceTCPDrainedConnsCounter []metrics.Counter
+	var serviceGRPCActiveStreamsGauge []metrics.Gauge
+	var serviceGRPCMessagesCounter []metrics.Counter
+	var serviceGRPCStreamDurationHistogram []ScalableHistogram
+	var serviceGRPCStreamsCounter []metrics.Counter
+	var acmeCertificateStatusGauge []metrics.Gauge
+	var acmeCertificateNotAfterGauge []metrics.Gauge
+	var acmeChallengeRequestsCounter []metrics.Counter
+	var tlsCertsNotAfterGauge []metrics.Gauge
+	var tlsHandshakeErrorsCounter []metrics.Counter
+	var experimentExposuresCounter []metrics.Counter
+	var shadowVerdictsCounter []metrics.Counter
+	var redirectMapHitsCounter []metrics.Counter
 
 	for _, r := range registries {
 		if r.ConfigReloadsCounter() != nil {
@@ -74,6 +130,18 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.LastConfigReloadFailureGauge() != nil {
 			lastConfigReloadFailureGauge = append(lastConfigReloadFailureGauge, r.LastConfigReloadFailureGauge())
 		}
+		if r.ConfigQueueDepthGauge() != nil {
+			configQueueDepthGauge = append(configQueueDepthGauge, r.ConfigQueueDepthGauge())
+		}
+		if r.ConfigMessagesDroppedCounter() != nil {
+			configMessagesDroppedCounter = append(configMessagesDroppedCounter, r.ConfigMessagesDroppedCounter())
+		}
+		if r.ConfigApplyDurationHistogram() != nil {
+			configApplyDurationHistogram = append(configApplyDurationHistogram, r.ConfigApplyDurationHistogram())
+		}
+		if r.ConfigWarningsCounter() != nil {
+			configWarningsCounter = append(configWarningsCounter, r.ConfigWarningsCounter())
+		}
 		if r.EntryPointReqsCounter() != nil {
 			entryPointReqsCounter = append(entryPointReqsCounter, r.EntryPointReqsCounter())
 		}
@@ -86,6 +154,15 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.EntryPointOpenConnsGauge() != nil {
 			entryPointOpenConnsGauge = append(entryPointOpenConnsGauge, r.EntryPointOpenConnsGauge())
 		}
+		if r.EntryPointClientHelloFailuresCounter() != nil {
+			entryPointHelloFailuresCounter = append(entryPointHelloFailuresCounter, r.EntryPointClientHelloFailuresCounter())
+		}
+		if r.RouterReqSizeHistogram() != nil {
+			routerReqSizeHistogram = append(routerReqSizeHistogram, r.RouterReqSizeHistogram())
+		}
+		if r.RouterRespSizeHistogram() != nil {
+			routerRespSizeHistogram = append(routerRespSizeHistogram, r.RouterRespSizeHistogram())
+		}
 		if r.ServiceReqsCounter() != nil {
 			serviceReqsCounter = append(serviceReqsCounter, r.ServiceReqsCounter())
 		}
@@ -104,45 +181,126 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.ServiceServerUpGauge() != nil {
 			serviceServerUpGauge = append(serviceServerUpGauge, r.ServiceServerUpGauge())
 		}
+		if r.ServiceTCPDrainedConnsCounter() != nil {
+			serviceTCPDrainedConnsCounter = append(serviceTCPDrainedConnsCounter, r.ServiceTCPDrainedConnsCounter())
+		}
+		if r.ServiceGRPCActiveStreamsGauge() != nil {
+			serviceGRPCActiveStreamsGauge = append(serviceGRPCActiveStreamsGauge, r.ServiceGRPCActiveStreamsGauge())
+		}
+		if r.ServiceGRPCMessagesCounter() != nil {
+			serviceGRPCMessagesCounter = append(serviceGRPCMessagesCounter, r.ServiceGRPCMessagesCounter())
+		}
+		if r.ServiceGRPCStreamDurationHistogram() != nil {
+			serviceGRPCStreamDurationHistogram = append(serviceGRPCStreamDurationHistogram, r.ServiceGRPCStreamDurationHistogram())
+		}
+		if r.ServiceGRPCStreamsCounter() != nil {
+			serviceGRPCStreamsCounter = append(serviceGRPCStreamsCounter, r.ServiceGRPCStreamsCounter())
+		}
+		if r.ACMECertificateStatusGauge() != nil {
+			acmeCertificateStatusGauge = append(acmeCertificateStatusGauge, r.ACMECertificateStatusGauge())
+		}
+		if r.ACMECertificateNotAfterGauge() != nil {
+			acmeCertificateNotAfterGauge = append(acmeCertificateNotAfterGauge, r.ACMECertificateNotAfterGauge())
+		}
+		if r.ACMEChallengeRequestsCounter() != nil {
+			acmeChallengeRequestsCounter = append(acmeChallengeRequestsCounter, r.ACMEChallengeRequestsCounter())
+		}
+		if r.TLSCertsNotAfterGauge() != nil {
+			tlsCertsNotAfterGauge = append(tlsCertsNotAfterGauge, r.TLSCertsNotAfterGauge())
+		}
+		if r.TLSHandshakeErrorsCounter() != nil {
+			tlsHandshakeErrorsCounter = append(tlsHandshakeErrorsCounter, r.TLSHandshakeErrorsCounter())
+		}
+		if r.ExperimentExposuresCounter() != nil {
+			experimentExposuresCounter = append(experimentExposuresCounter, r.ExperimentExposuresCounter())
+		}
+		if r.ShadowVerdictsCounter() != nil {
+			shadowVerdictsCounter = append(shadowVerdictsCounter, r.ShadowVerdictsCounter())
+		}
+		if r.RedirectMapHitsCounter() != nil {
+			redirectMapHitsCounter = append(redirectMapHitsCounter, r.RedirectMapHitsCounter())
+		}
 	}
 
 	return &standardRegistry{
-		epEnabled:                      len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0 || len(entryPointOpenConnsGauge) > 0,
-		svcEnabled:                     len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceOpenConnsGauge) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		configReloadsFailureCounter:    multi.NewCounter(configReloadsFailureCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		lastConfigReloadFailureGauge:   multi.NewGauge(lastConfigReloadFailureGauge...),
-		entryPointReqsCounter:          multi.NewCounter(entryPointReqsCounter...),
-		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
-		entryPointReqDurationHistogram: NewMultiHistogram(entryPointReqDurationHistogram...),
-		entryPointOpenConnsGauge:       multi.NewGauge(entryPointOpenConnsGauge...),
-		serviceReqsCounter:             multi.NewCounter(serviceReqsCounter...),
-		serviceReqsTLSCounter:          multi.NewCounter(serviceReqsTLSCounter...),
-		serviceReqDurationHistogram:    NewMultiHistogram(serviceReqDurationHistogram...),
-		serviceOpenConnsGauge:          multi.NewGauge(serviceOpenConnsGauge...),
-		serviceRetriesCounter:          multi.NewCounter(serviceRetriesCounter...),
-		serviceServerUpGauge:           multi.NewGauge(serviceServerUpGauge...),
+		epEnabled:                          len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0 || len(entryPointOpenConnsGauge) > 0,
+		svcEnabled:                         len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceOpenConnsGauge) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
+		routerEnabled:                      len(routerReqSizeHistogram) > 0 || len(routerRespSizeHistogram) > 0,
+		configReloadsCounter:               multi.NewCounter(configReloadsCounter...),
+		configReloadsFailureCounter:        multi.NewCounter(configReloadsFailureCounter...),
+		lastConfigReloadSuccessGauge:       multi.NewGauge(lastConfigReloadSuccessGauge...),
+		lastConfigReloadFailureGauge:       multi.NewGauge(lastConfigReloadFailureGauge...),
+		configQueueDepthGauge:              multi.NewGauge(configQueueDepthGauge...),
+		configMessagesDroppedCounter:       multi.NewCounter(configMessagesDroppedCounter...),
+		configApplyDurationHistogram:       multi.NewHistogram(configApplyDurationHistogram...),
+		configWarningsCounter:              multi.NewCounter(configWarningsCounter...),
+		entryPointReqsCounter:              multi.NewCounter(entryPointReqsCounter...),
+		entryPointReqsTLSCounter:           multi.NewCounter(entryPointReqsTLSCounter...),
+		entryPointReqDurationHistogram:     NewMultiHistogram(entryPointReqDurationHistogram...),
+		entryPointOpenConnsGauge:           multi.NewGauge(entryPointOpenConnsGauge...),
+		entryPointHelloFailuresCounter:     multi.NewCounter(entryPointHelloFailuresCounter...),
+		routerReqSizeHistogram:             multi.NewHistogram(routerReqSizeHistogram...),
+		routerRespSizeHistogram:            multi.NewHistogram(routerRespSizeHistogram...),
+		serviceReqsCounter:                 multi.NewCounter(serviceReqsCounter...),
+		serviceReqsTLSCounter:              multi.NewCounter(serviceReqsTLSCounter...),
+		serviceReqDurationHistogram:        NewMultiHistogram(serviceReqDurationHistogram...),
+		serviceOpenConnsGauge:              multi.NewGauge(serviceOpenConnsGauge...),
+		serviceRetriesCounter:              multi.NewCounter(serviceRetriesCounter...),
+		serviceServerUpGauge:               multi.NewGauge(serviceServerUpGauge...),
+		serviceTCPDrainedConnsCounter:      multi.NewCounter(serviceTCPDrainedConnsCounter...),
+		serviceGRPCActiveStreamsGauge:      multi.NewGauge(serviceGRPCActiveStreamsGauge...),
+		serviceGRPCMessagesCounter:         multi.NewCounter(serviceGRPCMessagesCounter...),
+		serviceGRPCStreamDurationHistogram: NewMultiHistogram(serviceGRPCStreamDurationHistogram...),
+		serviceGRPCStreamsCounter:          multi.NewCounter(serviceGRPCStreamsCounter...),
+		acmeCertificateStatusGauge:         multi.NewGauge(acmeCertificateStatusGauge...),
+		acmeCertificateNotAfterGauge:       multi.NewGauge(acmeCertificateNotAfterGauge...),
+		acmeChallengeRequestsCounter:       multi.NewCounter(acmeChallengeRequestsCounter...),
+		tlsCertsNotAfterGauge:              multi.NewGauge(tlsCertsNotAfterGauge...),
+		tlsHandshakeErrorsCounter:          multi.NewCounter(tlsHandshakeErrorsCounter...),
+		experimentExposuresCounter:         multi.NewCounter(experimentExposuresCounter...),
+		shadowVerdictsCounter:              multi.NewCounter(shadowVerdictsCounter...),
+		redirectMapHitsCounter:             multi.NewCounter(redirectMapHitsCounter...),
 	}
 }
 
 type standardRegistry struct {
-	epEnabled                      bool
-	svcEnabled                     bool
-	configReloadsCounter           metrics.Counter
-	configReloadsFailureCounter    metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	lastConfigReloadFailureGauge   metrics.Gauge
-	entryPointReqsCounter          metrics.Counter
-	entryPointReqsTLSCounter       metrics.Counter
-	entryPointReqDurationHistogram ScalableHistogram
-	entryPointOpenConnsGauge       metrics.Gauge
-	serviceReqsCounter             metrics.Counter
-	serviceReqsTLSCounter          metrics.Counter
-	serviceReqDurationHistogram    ScalableHistogram
-	serviceOpenConnsGauge          metrics.Gauge
-	serviceRetriesCounter          metrics.Counter
-	serviceServerUpGauge           metrics.Gauge
+	epEnabled                          bool
+	svcEnabled                         bool
+	routerEnabled                      bool
+	configReloadsCounter               metrics.Counter
+	configReloadsFailureCounter        metrics.Counter
+	lastConfigReloadSuccessGauge       metrics.Gauge
+	lastConfigReloadFailureGauge       metrics.Gauge
+	configQueueDepthGauge              metrics.Gauge
+	configMessagesDroppedCounter       metrics.Counter
+	configApplyDurationHistogram       metrics.Histogram
+	configWarningsCounter              metrics.Counter
+	entryPointReqsCounter              metrics.Counter
+	entryPointReqsTLSCounter           metrics.Counter
+	entryPointReqDurationHistogram     ScalableHistogram
+	entryPointOpenConnsGauge           metrics.Gauge
+	entryPointHelloFailuresCounter     metrics.Counter
+	routerReqSizeHistogram             metrics.Histogram
+	routerRespSizeHistogram            metrics.Histogram
+	serviceReqsCounter                 metrics.Counter
+	serviceReqsTLSCounter              metrics.Counter
+	serviceReqDurationHistogram        ScalableHistogram
+	serviceOpenConnsGauge              metrics.Gauge
+	serviceRetriesCounter              metrics.Counter
+	serviceServerUpGauge               metrics.Gauge
+	serviceTCPDrainedConnsCounter      metrics.Counter
+	serviceGRPCActiveStreamsGauge      metrics.Gauge
+	serviceGRPCMessagesCounter         metrics.Counter
+	serviceGRPCStreamDurationHistogram ScalableHistogram
+	serviceGRPCStreamsCounter          metrics.Counter
+	acmeCertificateStatusGauge         metrics.Gauge
+	acmeCertificateNotAfterGauge       metrics.Gauge
+	acmeChallengeRequestsCounter       metrics.Counter
+	tlsCertsNotAfterGauge              metrics.Gauge
+	tlsHandshakeErrorsCounter          metrics.Counter
+	experimentExposuresCounter         metrics.Counter
+	shadowVerdictsCounter              metrics.Counter
+	redirectMapHitsCounter             metrics.Counter
 }
 
 func (r *standardRegistry) IsEpEnabled() bool {
@@ -153,6 +311,10 @@ func (r *standardRegistry) IsSvcEnabled() bool {
 	return r.svcEnabled
 }
 
+func (r *standardRegistry) IsRouterEnabled() bool {
+	return r.routerEnabled
+}
+
 func (r *standardRegistry) ConfigReloadsCounter() metrics.Counter {
 	return r.configReloadsCounter
 }
@@ -169,6 +331,22 @@ func (r *standardRegistry) LastConfigReloadFailureGauge() metrics.Gauge {
 	return r.lastConfigReloadFailureGauge
 }
 
+func (r *standardRegistry) ConfigQueueDepthGauge() metrics.Gauge {
+	return r.configQueueDepthGauge
+}
+
+func (r *standardRegistry) ConfigMessagesDroppedCounter() metrics.Counter {
+	return r.configMessagesDroppedCounter
+}
+
+func (r *standardRegistry) ConfigApplyDurationHistogram() metrics.Histogram {
+	return r.configApplyDurationHistogram
+}
+
+func (r *standardRegistry) ConfigWarningsCounter() metrics.Counter {
+	return r.configWarningsCounter
+}
+
 func (r *standardRegistry) EntryPointReqsCounter() metrics.Counter {
 	return r.entryPointReqsCounter
 }
@@ -185,6 +363,18 @@ func (r *standardRegistry) EntryPointOpenConnsGauge() metrics.Gauge {
 	return r.entryPointOpenConnsGauge
 }
 
+func (r *standardRegistry) EntryPointClientHelloFailuresCounter() metrics.Counter {
+	return r.entryPointHelloFailuresCounter
+}
+
+func (r *standardRegistry) RouterReqSizeHistogram() metrics.Histogram {
+	return r.routerReqSizeHistogram
+}
+
+func (r *standardRegistry) RouterRespSizeHistogram() metrics.Histogram {
+	return r.routerRespSizeHistogram
+}
+
 func (r *standardRegistry) ServiceReqsCounter() metrics.Counter {
 	return r.serviceReqsCounter
 }
@@ -209,6 +399,58 @@ func (r *standardRegistry) ServiceServerUpGauge() metrics.Gauge {
 	return r.serviceServerUpGauge
 }
 
+func (r *standardRegistry) ServiceTCPDrainedConnsCounter() metrics.Counter {
+	return r.serviceTCPDrainedConnsCounter
+}
+
+func (r *standardRegistry) ServiceGRPCActiveStreamsGauge() metrics.Gauge {
+	return r.serviceGRPCActiveStreamsGauge
+}
+
+func (r *standardRegistry) ServiceGRPCMessagesCounter() metrics.Counter {
+	return r.serviceGRPCMessagesCounter
+}
+
+func (r *standardRegistry) ServiceGRPCStreamDurationHistogram() ScalableHistogram {
+	return r.serviceGRPCStreamDurationHistogram
+}
+
+func (r *standardRegistry) ServiceGRPCStreamsCounter() metrics.Counter {
+	return r.serviceGRPCStreamsCounter
+}
+
+func (r *standardRegistry) ACMECertificateStatusGauge() metrics.Gauge {
+	return r.acmeCertificateStatusGauge
+}
+
+func (r *standardRegistry) ACMECertificateNotAfterGauge() metrics.Gauge {
+	return r.acmeCertificateNotAfterGauge
+}
+
+func (r *standardRegistry) ACMEChallengeRequestsCounter() metrics.Counter {
+	return r.acmeChallengeRequestsCounter
+}
+
+func (r *standardRegistry) TLSCertsNotAfterGauge() metrics.Gauge {
+	return r.tlsCertsNotAfterGauge
+}
+
+func (r *standardRegistry) TLSHandshakeErrorsCounter() metrics.Counter {
+	return r.tlsHandshakeErrorsCounter
+}
+
+func (r *standardRegistry) ExperimentExposuresCounter() metrics.Counter {
+	return r.experimentExposuresCounter
+}
+
+func (r *standardRegistry) ShadowVerdictsCounter() metrics.Counter {
+	return r.shadowVerdictsCounter
+}
+
+func (r *standardRegistry) RedirectMapHitsCounter() metrics.Counter {
+	return r.redirectMapHitsCounter
+}
+
 // ScalableHistogram is a Histogram with a predefined time unit,
 // used when producing observations without explicitly setting the observed value.
 type ScalableHistogram interface {
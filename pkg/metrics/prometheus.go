@@ -11,6 +11,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/safe"
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
 	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/go-kit/kit/metrics"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -27,13 +28,23 @@ const (
 	configReloadsFailuresTotalName = metricConfigPrefix + "reloads_failure_total"
 	configLastReloadSuccessName    = metricConfigPrefix + "last_reload_success"
 	configLastReloadFailureName    = metricConfigPrefix + "last_reload_failure"
+	configQueueDepthName           = metricConfigPrefix + "queue_depth"
+	configMessagesDroppedTotalName = metricConfigPrefix + "messages_dropped_total"
+	configApplyDurationName        = metricConfigPrefix + "apply_duration_seconds"
+	configWarningsTotalName        = metricConfigPrefix + "warnings_total"
 
 	// entry point
-	metricEntryPointPrefix     = MetricNamePrefix + "entrypoint_"
-	entryPointReqsTotalName    = metricEntryPointPrefix + "requests_total"
-	entryPointReqsTLSTotalName = metricEntryPointPrefix + "requests_tls_total"
-	entryPointReqDurationName  = metricEntryPointPrefix + "request_duration_seconds"
-	entryPointOpenConnsName    = metricEntryPointPrefix + "open_connections"
+	metricEntryPointPrefix                 = MetricNamePrefix + "entrypoint_"
+	entryPointReqsTotalName                = metricEntryPointPrefix + "requests_total"
+	entryPointReqsTLSTotalName             = metricEntryPointPrefix + "requests_tls_total"
+	entryPointReqDurationName              = metricEntryPointPrefix + "request_duration_seconds"
+	entryPointOpenConnsName                = metricEntryPointPrefix + "open_connections"
+	entryPointClientHelloFailuresTotalName = metricEntryPointPrefix + "client_hello_failures_total"
+
+	// router level
+	metricRouterPrefix = MetricNamePrefix + "router_"
+	routerReqSizeName  = metricRouterPrefix + "request_bytes"
+	routerRespSizeName = metricRouterPrefix + "response_bytes"
 
 	// service level.
 
@@ -45,6 +56,36 @@ const (
 	serviceOpenConnsName    = MetricServicePrefix + "open_connections"
 	serviceRetriesTotalName = MetricServicePrefix + "retries_total"
 	serviceServerUpName     = MetricServicePrefix + "server_up"
+
+	serviceTCPDrainedConnsTotalName = MetricServicePrefix + "tcp_drained_connections_total"
+
+	serviceGRPCActiveStreamsName  = MetricServicePrefix + "grpc_active_streams"
+	serviceGRPCMessagesTotalName  = MetricServicePrefix + "grpc_messages_total"
+	serviceGRPCStreamDurationName = MetricServicePrefix + "grpc_stream_duration_seconds"
+	serviceGRPCStreamsTotalName   = MetricServicePrefix + "grpc_streams_total"
+
+	// ACME
+	metricACMEPrefix               = MetricNamePrefix + "acme_"
+	acmeCertificateStatusName      = metricACMEPrefix + "certificate_status"
+	acmeCertificateNotAfterName    = metricACMEPrefix + "certificate_not_after"
+	acmeChallengeRequestsTotalName = metricACMEPrefix + "challenge_requests_total"
+
+	// TLS
+	metricTLSPrefix             = MetricNamePrefix + "tls_"
+	tlsCertsNotAfterName        = metricTLSPrefix + "certs_not_after"
+	tlsHandshakeErrorsTotalName = metricTLSPrefix + "handshake_errors_total"
+
+	// experiment
+	metricExperimentPrefix       = MetricNamePrefix + "experiment_"
+	experimentExposuresTotalName = metricExperimentPrefix + "exposures_total"
+
+	// shadow
+	metricShadowPrefix      = MetricNamePrefix + "shadow_"
+	shadowVerdictsTotalName = metricShadowPrefix + "verdicts_total"
+
+	// redirect map
+	metricRedirectMapPrefix  = MetricNamePrefix + "redirect_map_"
+	redirectMapHitsTotalName = metricRedirectMapPrefix + "hits_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -61,6 +102,9 @@ const (
 // doesn't exist anymore.
 var promState = newPrometheusState()
 
+// sizeBuckets are the buckets of the size metrics, from 64 bytes to 16 megabytes.
+var sizeBuckets = stdprometheus.ExponentialBuckets(64, 4, 10)
+
 var promRegistry = stdprometheus.NewRegistry()
 
 // PrometheusHandler exposes Prometheus routes.
@@ -117,21 +161,95 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: configLastReloadFailureName,
 		Help: "Last config reload failure",
 	}, []string{})
+	configQueueDepth := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: configQueueDepthName,
+		Help: "How many configuration messages are waiting to be processed, partitioned by queue (providers or validated).",
+	}, []string{"queue"})
+	configMessagesDropped := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: configMessagesDroppedTotalName,
+		Help: "How many configuration messages of the providers were not applied, partitioned by provider and reason (empty, unchanged or merged).",
+	}, []string{"provider", "reason"})
+	configApplyDuration := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		Name:    configApplyDurationName,
+		Help:    "How long it took to apply a configuration, in seconds.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5},
+	}, []string{})
+	configWarnings := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: configWarningsTotalName,
+		Help: "How many warnings were raised by the applied configurations, partitioned by class (deprecated or insecure).",
+	}, []string{"class"})
+	acmeCertificateStatus := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: acmeCertificateStatusName,
+		Help: "Certificate status of a domain managed by an ACME resolver, set to 1 for the current status (pending, valid or error).",
+	}, []string{"resolver", "domain", "status"})
+	acmeCertificateNotAfter := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: acmeCertificateNotAfterName,
+		Help: "Expiration date of the certificate of a domain managed by an ACME resolver, as a Unix timestamp.",
+	}, []string{"resolver", "domain"})
+	acmeChallengeRequests := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: acmeChallengeRequestsTotalName,
+		Help: "How many ACME challenge validation requests were answered, partitioned by challenge type and domain.",
+	}, []string{"type", "domain"})
+	tlsCertsNotAfter := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: tlsCertsNotAfterName,
+		Help: "Expiration date of a certificate of a TLS store, as a Unix timestamp.",
+	}, []string{"store", "sans", "serial"})
+	tlsHandshakeErrors := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: tlsHandshakeErrorsTotalName,
+		Help: "How many TLS handshakes failed, partitioned by reason (no_certificate, client_certificate, protocol or other).",
+	}, []string{"reason"})
+	experimentExposures := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: experimentExposuresTotalName,
+		Help: "How many requests were exposed to an experiment variant, partitioned by experiment and variant.",
+	}, []string{"experiment", "variant"})
+	shadowVerdicts := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: shadowVerdictsTotalName,
+		Help: "How many requests were evaluated by shadowed middlewares, partitioned by middleware and verdict.",
+	}, []string{"middleware", "verdict"})
+	redirectMapHits := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: redirectMapHitsTotalName,
+		Help: "How many requests were answered by a redirect map, partitioned by middleware and status code.",
+	}, []string{"middleware", "code"})
 
 	promState.describers = []func(chan<- *stdprometheus.Desc){
 		configReloads.cv.Describe,
 		configReloadsFailures.cv.Describe,
 		lastConfigReloadSuccess.gv.Describe,
 		lastConfigReloadFailure.gv.Describe,
+		configQueueDepth.gv.Describe,
+		configMessagesDropped.cv.Describe,
+		configApplyDuration.hv.Describe,
+		configWarnings.cv.Describe,
+		acmeCertificateStatus.gv.Describe,
+		acmeCertificateNotAfter.gv.Describe,
+		acmeChallengeRequests.cv.Describe,
+		tlsCertsNotAfter.gv.Describe,
+		tlsHandshakeErrors.cv.Describe,
+		experimentExposures.cv.Describe,
+		shadowVerdicts.cv.Describe,
+		redirectMapHits.cv.Describe,
 	}
 
 	reg := &standardRegistry{
 		epEnabled:                    config.AddEntryPointsLabels,
 		svcEnabled:                   config.AddServicesLabels,
+		routerEnabled:                config.AddRoutersLabels,
 		configReloadsCounter:         configReloads,
 		configReloadsFailureCounter:  configReloadsFailures,
 		lastConfigReloadSuccessGauge: lastConfigReloadSuccess,
 		lastConfigReloadFailureGauge: lastConfigReloadFailure,
+		configQueueDepthGauge:        configQueueDepth,
+		configMessagesDroppedCounter: configMessagesDropped,
+		configApplyDurationHistogram: configApplyDuration,
+		configWarningsCounter:        configWarnings,
+		acmeCertificateStatusGauge:   acmeCertificateStatus,
+		acmeCertificateNotAfterGauge: acmeCertificateNotAfter,
+		acmeChallengeRequestsCounter: acmeChallengeRequests,
+		tlsCertsNotAfterGauge:        tlsCertsNotAfter,
+		tlsHandshakeErrorsCounter:    tlsHandshakeErrors,
+		experimentExposuresCounter:   experimentExposures,
+		shadowVerdictsCounter:        shadowVerdicts,
+		redirectMapHitsCounter:       redirectMapHits,
 	}
 
 	if config.AddEntryPointsLabels {
@@ -152,17 +270,42 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			Name: entryPointOpenConnsName,
 			Help: "How many open connections exist on an entrypoint, partitioned by method and protocol.",
 		}, []string{"method", "protocol", "entrypoint"})
+		entryPointClientHelloFailures := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entryPointClientHelloFailuresTotalName,
+			Help: "How many connections of an entrypoint whose TLS ClientHello was malformed or not received in time, partitioned by reason.",
+		}, []string{"reason", "entrypoint"})
 
 		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
 			entryPointReqs.cv.Describe,
 			entryPointReqsTLS.cv.Describe,
 			entryPointReqDurations.hv.Describe,
 			entryPointOpenConns.gv.Describe,
+			entryPointClientHelloFailures.cv.Describe,
 		}...)
 		reg.entryPointReqsCounter = entryPointReqs
 		reg.entryPointReqsTLSCounter = entryPointReqsTLS
 		reg.entryPointReqDurationHistogram, _ = NewHistogramWithScale(entryPointReqDurations, time.Second)
 		reg.entryPointOpenConnsGauge = entryPointOpenConns
+		reg.entryPointHelloFailuresCounter = entryPointClientHelloFailures
+	}
+	if config.AddRoutersLabels {
+		routerReqSizes := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+			Name:    routerReqSizeName,
+			Help:    "Size of the bodies of the requests processed on a router, in bytes.",
+			Buckets: sizeBuckets,
+		}, []string{"router"})
+		routerRespSizes := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+			Name:    routerRespSizeName,
+			Help:    "Size of the bodies of the responses sent on a router, in bytes.",
+			Buckets: sizeBuckets,
+		}, []string{"router"})
+
+		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
+			routerReqSizes.hv.Describe,
+			routerRespSizes.hv.Describe,
+		}...)
+		reg.routerReqSizeHistogram = routerReqSizes
+		reg.routerRespSizeHistogram = routerRespSizes
 	}
 	if config.AddServicesLabels {
 		serviceReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
@@ -190,6 +333,27 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			Name: serviceServerUpName,
 			Help: "service server is up, described by gauge value of 0 or 1.",
 		}, []string{"service", "url"})
+		serviceTCPDrainedConns := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: serviceTCPDrainedConnsTotalName,
+			Help: "How many connections of a TCP service were closed once its configuration changed, partitioned by result (drained once idle, or killed).",
+		}, []string{"result", "service"})
+		serviceGRPCActiveStreams := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+			Name: serviceGRPCActiveStreamsName,
+			Help: "How many gRPC streams are active on a service, partitioned by gRPC method.",
+		}, []string{"grpc_method", "service"})
+		serviceGRPCMessages := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: serviceGRPCMessagesTotalName,
+			Help: "How many gRPC messages went through a service, partitioned by gRPC method and direction.",
+		}, []string{"grpc_method", "direction", "service"})
+		serviceGRPCStreamDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+			Name:    serviceGRPCStreamDurationName,
+			Help:    "How long the gRPC streams lasted on a service, partitioned by gRPC method and gRPC status code.",
+			Buckets: buckets,
+		}, []string{"grpc_method", "grpc_code", "service"})
+		serviceGRPCStreams := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: serviceGRPCStreamsTotalName,
+			Help: "How many gRPC streams completed on a service, partitioned by gRPC method and gRPC status code.",
+		}, []string{"grpc_method", "grpc_code", "service"})
 
 		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
 			serviceReqs.cv.Describe,
@@ -198,6 +362,11 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			serviceOpenConns.gv.Describe,
 			serviceRetries.cv.Describe,
 			serviceServerUp.gv.Describe,
+			serviceTCPDrainedConns.cv.Describe,
+			serviceGRPCActiveStreams.gv.Describe,
+			serviceGRPCMessages.cv.Describe,
+			serviceGRPCStreamDurations.hv.Describe,
+			serviceGRPCStreams.cv.Describe,
 		}...)
 
 		reg.serviceReqsCounter = serviceReqs
@@ -206,6 +375,11 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		reg.serviceOpenConnsGauge = serviceOpenConns
 		reg.serviceRetriesCounter = serviceRetries
 		reg.serviceServerUpGauge = serviceServerUp
+		reg.serviceTCPDrainedConnsCounter = serviceTCPDrainedConns
+		reg.serviceGRPCActiveStreamsGauge = serviceGRPCActiveStreams
+		reg.serviceGRPCMessagesCounter = serviceGRPCMessages
+		reg.serviceGRPCStreamDurationHistogram, _ = NewHistogramWithScale(serviceGRPCStreamDurations, time.Second)
+		reg.serviceGRPCStreamsCounter = serviceGRPCStreams
 	}
 
 	return reg
@@ -249,6 +423,26 @@ func OnConfigurationUpdate(conf dynamic.Configuration, entryPoints []string) {
 	promState.SetDynamicConfig(dynamicConfig)
 }
 
+// OnTLSCertificatesUpdate sets the expiration dates of the certificates of the TLS stores.
+// The metrics of the certificates which are not in the stores anymore are removed after their next scrape.
+func OnTLSCertificatesUpdate(registry Registry, certs []traefiktls.CertificateInfo) {
+	current := make(map[string]bool, len(certs))
+	for _, cert := range certs {
+		current[tlsCertificateID(cert.Store, cert.Serial)] = true
+	}
+
+	// The certificates are set before their metrics, which would otherwise be removed as outdated.
+	promState.SetTLSCertificates(current)
+
+	for _, cert := range certs {
+		registry.TLSCertsNotAfterGauge().With("store", cert.Store, "sans", cert.SANs, "serial", cert.Serial).Set(float64(cert.NotAfter.Unix()))
+	}
+}
+
+func tlsCertificateID(store, serial string) string {
+	return store + "|" + serial
+}
+
 func newPrometheusState() *prometheusState {
 	return &prometheusState{
 		collectors:    make(chan *collector),
@@ -263,7 +457,9 @@ type prometheusState struct {
 
 	mtx           sync.Mutex
 	dynamicConfig *dynamicConfig
-	state         map[string]*collector
+	// tlsCertificates holds the certificates of the TLS stores, keyed by store and serial number.
+	tlsCertificates map[string]bool
+	state           map[string]*collector
 }
 
 func (ps *prometheusState) SetDynamicConfig(dynamicConfig *dynamicConfig) {
@@ -272,6 +468,12 @@ func (ps *prometheusState) SetDynamicConfig(dynamicConfig *dynamicConfig) {
 	ps.dynamicConfig = dynamicConfig
 }
 
+func (ps *prometheusState) SetTLSCertificates(tlsCertificates map[string]bool) {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	ps.tlsCertificates = tlsCertificates
+}
+
 func (ps *prometheusState) ListenValueUpdates() {
 	for collector := range ps.collectors {
 		ps.mtx.Lock()
@@ -321,6 +523,10 @@ func (ps *prometheusState) isOutdated(collector *collector) bool {
 		return true
 	}
 
+	if routerName, ok := labels["router"]; ok && !ps.dynamicConfig.hasRouter(routerName) {
+		return true
+	}
+
 	if serviceName, ok := labels["service"]; ok {
 		if !ps.dynamicConfig.hasService(serviceName) {
 			return true
@@ -330,6 +536,10 @@ func (ps *prometheusState) isOutdated(collector *collector) bool {
 		}
 	}
 
+	if serial, ok := labels["serial"]; ok && !ps.tlsCertificates[tlsCertificateID(labels["store"], serial)] {
+		return true
+	}
+
 	return false
 }
 
@@ -356,6 +566,11 @@ func (d *dynamicConfig) hasEntryPoint(entrypointName string) bool {
 	return ok
 }
 
+func (d *dynamicConfig) hasRouter(routerName string) bool {
+	_, ok := d.routers[routerName]
+	return ok
+}
+
 func (d *dynamicConfig) hasService(serviceName string) bool {
 	_, ok := d.services[serviceName]
 	return ok
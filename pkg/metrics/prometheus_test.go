@@ -10,6 +10,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	th "github.com/containous/traefik/v2/pkg/testhelpers"
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
 	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -95,6 +96,7 @@ func (ps *prometheusState) reset() {
 	ps.collectors = make(chan *collector)
 	ps.describers = []func(ch chan<- *prometheus.Desc){}
 	ps.dynamicConfig = newDynamicConfig()
+	ps.tlsCertificates = nil
 	ps.state = make(map[string]*collector)
 }
 
@@ -104,10 +106,10 @@ func TestPrometheus(t *testing.T) {
 	// Reset state of global promState.
 	defer promState.reset()
 
-	prometheusRegistry := RegisterPrometheus(context.Background(), &types.Prometheus{AddEntryPointsLabels: true, AddServicesLabels: true})
+	prometheusRegistry := RegisterPrometheus(context.Background(), &types.Prometheus{AddEntryPointsLabels: true, AddServicesLabels: true, AddRoutersLabels: true})
 	defer promRegistry.Unregister(promState)
 
-	if !prometheusRegistry.IsEpEnabled() || !prometheusRegistry.IsSvcEnabled() {
+	if !prometheusRegistry.IsEpEnabled() || !prometheusRegistry.IsSvcEnabled() || !prometheusRegistry.IsRouterEnabled() {
 		t.Errorf("PrometheusRegistry should return true for IsEnabled()")
 	}
 
@@ -128,6 +130,19 @@ func TestPrometheus(t *testing.T) {
 		EntryPointOpenConnsGauge().
 		With("method", http.MethodGet, "protocol", "http", "entrypoint", "http").
 		Set(1)
+	prometheusRegistry.
+		EntryPointClientHelloFailuresCounter().
+		With("reason", "timeout", "entrypoint", "http").
+		Add(1)
+
+	prometheusRegistry.
+		RouterReqSizeHistogram().
+		With("router", "router1").
+		Observe(100)
+	prometheusRegistry.
+		RouterRespSizeHistogram().
+		With("router", "router1").
+		Observe(10000)
 
 	prometheusRegistry.
 		ServiceReqsCounter().
@@ -149,6 +164,75 @@ func TestPrometheus(t *testing.T) {
 		ServiceServerUpGauge().
 		With("service", "service1", "url", "http://127.0.0.10:80").
 		Set(1)
+	prometheusRegistry.
+		ServiceTCPDrainedConnsCounter().
+		With("service", "service1", "result", "killed").
+		Add(1)
+
+	prometheusRegistry.
+		ServiceGRPCActiveStreamsGauge().
+		With("service", "service1", "grpc_method", "/helloworld.Greeter/SayHello").
+		Set(1)
+	prometheusRegistry.
+		ServiceGRPCMessagesCounter().
+		With("service", "service1", "grpc_method", "/helloworld.Greeter/SayHello", "direction", "response").
+		Add(2)
+	prometheusRegistry.
+		ServiceGRPCStreamDurationHistogram().
+		With("service", "service1", "grpc_method", "/helloworld.Greeter/SayHello", "grpc_code", "Unavailable").
+		Observe(1)
+	prometheusRegistry.
+		ServiceGRPCStreamsCounter().
+		With("service", "service1", "grpc_method", "/helloworld.Greeter/SayHello", "grpc_code", "Unavailable").
+		Add(1)
+
+	prometheusRegistry.
+		ACMECertificateStatusGauge().
+		With("resolver", "myresolver", "domain", "example.com", "status", "valid").
+		Set(1)
+	prometheusRegistry.
+		ACMECertificateNotAfterGauge().
+		With("resolver", "myresolver", "domain", "example.com").
+		Set(float64(time.Now().Unix()))
+	prometheusRegistry.
+		ACMEChallengeRequestsCounter().
+		With("type", "tls-alpn-01", "domain", "example.com").
+		Add(1)
+	prometheusRegistry.
+		TLSCertsNotAfterGauge().
+		With("store", "default", "sans", "example.com", "serial", "1f").
+		Set(float64(time.Now().Unix()))
+	prometheusRegistry.
+		TLSHandshakeErrorsCounter().
+		With("reason", "no_certificate").
+		Add(1)
+	prometheusRegistry.
+		ExperimentExposuresCounter().
+		With("experiment", "checkout", "variant", "b").
+		Add(1)
+	prometheusRegistry.
+		ShadowVerdictsCounter().
+		With("middleware", "waf@file", "verdict", "blocked").
+		Add(1)
+	prometheusRegistry.
+		RedirectMapHitsCounter().
+		With("middleware", "migration@file", "code", "301").
+		Add(1)
+	prometheusRegistry.
+		ConfigQueueDepthGauge().
+		With("queue", "providers").
+		Set(3)
+	prometheusRegistry.
+		ConfigMessagesDroppedCounter().
+		With("provider", "file", "reason", "merged").
+		Add(1)
+	prometheusRegistry.
+		ConfigApplyDurationHistogram().
+		Observe(0.2)
+	prometheusRegistry.
+		ConfigWarningsCounter().
+		With("class", "insecure").
+		Add(1)
 
 	delayForTrackingCompletion()
 
@@ -175,6 +259,32 @@ func TestPrometheus(t *testing.T) {
 			name:   configLastReloadFailureName,
 			assert: buildTimestampAssert(t, configLastReloadFailureName),
 		},
+		{
+			name: configQueueDepthName,
+			labels: map[string]string{
+				"queue": "providers",
+			},
+			assert: buildGaugeAssert(t, configQueueDepthName, 3),
+		},
+		{
+			name: configMessagesDroppedTotalName,
+			labels: map[string]string{
+				"provider": "file",
+				"reason":   "merged",
+			},
+			assert: buildCounterAssert(t, configMessagesDroppedTotalName, 1),
+		},
+		{
+			name:   configApplyDurationName,
+			assert: buildHistogramAssert(t, configApplyDurationName, 1),
+		},
+		{
+			name: configWarningsTotalName,
+			labels: map[string]string{
+				"class": "insecure",
+			},
+			assert: buildCounterAssert(t, configWarningsTotalName, 1),
+		},
 		{
 			name: entryPointReqsTotalName,
 			labels: map[string]string{
@@ -204,6 +314,14 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, entryPointOpenConnsName, 1),
 		},
+		{
+			name: entryPointClientHelloFailuresTotalName,
+			labels: map[string]string{
+				"reason":     "timeout",
+				"entrypoint": "http",
+			},
+			assert: buildCounterAssert(t, entryPointClientHelloFailuresTotalName, 1),
+		},
 		{
 			name: serviceReqsTotalName,
 			labels: map[string]string{
@@ -248,6 +366,122 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, serviceServerUpName, 1),
 		},
+		{
+			name: serviceTCPDrainedConnsTotalName,
+			labels: map[string]string{
+				"service": "service1",
+				"result":  "killed",
+			},
+			assert: buildCounterAssert(t, serviceTCPDrainedConnsTotalName, 1),
+		},
+		{
+			name: serviceGRPCActiveStreamsName,
+			labels: map[string]string{
+				"service":     "service1",
+				"grpc_method": "/helloworld.Greeter/SayHello",
+			},
+			assert: buildGaugeAssert(t, serviceGRPCActiveStreamsName, 1),
+		},
+		{
+			name: serviceGRPCMessagesTotalName,
+			labels: map[string]string{
+				"service":     "service1",
+				"grpc_method": "/helloworld.Greeter/SayHello",
+				"direction":   "response",
+			},
+			assert: buildCounterAssert(t, serviceGRPCMessagesTotalName, 2),
+		},
+		{
+			name: serviceGRPCStreamDurationName,
+			labels: map[string]string{
+				"service":     "service1",
+				"grpc_method": "/helloworld.Greeter/SayHello",
+				"grpc_code":   "Unavailable",
+			},
+			assert: buildHistogramAssert(t, serviceGRPCStreamDurationName, 1),
+		},
+		{
+			name: serviceGRPCStreamsTotalName,
+			labels: map[string]string{
+				"service":     "service1",
+				"grpc_method": "/helloworld.Greeter/SayHello",
+				"grpc_code":   "Unavailable",
+			},
+			assert: buildCounterAssert(t, serviceGRPCStreamsTotalName, 1),
+		},
+		{
+			name: acmeCertificateStatusName,
+			labels: map[string]string{
+				"resolver": "myresolver",
+				"domain":   "example.com",
+				"status":   "valid",
+			},
+			assert: buildGaugeAssert(t, acmeCertificateStatusName, 1),
+		},
+		{
+			name: acmeCertificateNotAfterName,
+			labels: map[string]string{
+				"resolver": "myresolver",
+				"domain":   "example.com",
+			},
+			assert: buildTimestampAssert(t, acmeCertificateNotAfterName),
+		},
+		{
+			name: acmeChallengeRequestsTotalName,
+			labels: map[string]string{
+				"type":   "tls-alpn-01",
+				"domain": "example.com",
+			},
+			assert: buildCounterAssert(t, acmeChallengeRequestsTotalName, 1),
+		},
+		{
+			name: tlsCertsNotAfterName,
+			labels: map[string]string{
+				"store":  "default",
+				"sans":   "example.com",
+				"serial": "1f",
+			},
+			assert: buildTimestampAssert(t, tlsCertsNotAfterName),
+		},
+		{
+			name:   tlsHandshakeErrorsTotalName,
+			labels: map[string]string{"reason": "no_certificate"},
+			assert: buildCounterAssert(t, tlsHandshakeErrorsTotalName, 1),
+		},
+		{
+			name: experimentExposuresTotalName,
+			labels: map[string]string{
+				"experiment": "checkout",
+				"variant":    "b",
+			},
+			assert: buildCounterAssert(t, experimentExposuresTotalName, 1),
+		},
+		{
+			name:   routerReqSizeName,
+			labels: map[string]string{"router": "router1"},
+			assert: buildHistogramAssert(t, routerReqSizeName, 1),
+		},
+		{
+			name:   routerRespSizeName,
+			labels: map[string]string{"router": "router1"},
+			assert: buildHistogramAssert(t, routerRespSizeName, 1),
+		},
+		{
+			name: shadowVerdictsTotalName,
+			labels: map[string]string{
+				"middleware": "waf@file",
+				"verdict":    "blocked",
+			},
+			assert: buildCounterAssert(t, shadowVerdictsTotalName, 1),
+		},
+		{
+			name: redirectMapHitsTotalName,
+			labels: map[string]string{
+				"middleware": "migration@file",
+				"code":       "301",
+			},
+			assert: buildCounterAssert(t, redirectMapHitsTotalName, 1),
+		},
 	}
 
 	for _, test := range testCases {
@@ -334,6 +568,47 @@ func TestPrometheusMetricRemoval(t *testing.T) {
 	assertMetricsExist(t, mustScrape(), entryPointReqsTotalName)
 }
 
+func TestPrometheusTLSCertificatesRemoval(t *testing.T) {
+	promState = newPrometheusState()
+	promRegistry = prometheus.NewRegistry()
+	// Reset state of global promState.
+	defer promState.reset()
+
+	prometheusRegistry := RegisterPrometheus(context.Background(), &types.Prometheus{})
+	defer promRegistry.Unregister(promState)
+
+	notAfter := time.Now().Add(time.Hour)
+
+	OnTLSCertificatesUpdate(prometheusRegistry, []traefiktls.CertificateInfo{
+		{Store: "default", SANs: "example.com", Serial: "1", NotAfter: notAfter},
+		{Store: "default", SANs: "example.org", Serial: "2", NotAfter: notAfter},
+	})
+
+	delayForTrackingCompletion()
+
+	families := mustScrape()
+	assertGaugeValue(t, float64(notAfter.Unix()), findMetricFamily(tlsCertsNotAfterName, families), "store", "default", "sans", "example.com", "serial", "1")
+	assertGaugeValue(t, float64(notAfter.Unix()), findMetricFamily(tlsCertsNotAfterName, families), "store", "default", "sans", "example.org", "serial", "2")
+
+	// The renewed certificate replaces the previous one, whose metric is removed after its next scrape.
+	OnTLSCertificatesUpdate(prometheusRegistry, []traefiktls.CertificateInfo{
+		{Store: "default", SANs: "example.com", Serial: "3", NotAfter: notAfter.Add(time.Hour)},
+		{Store: "default", SANs: "example.org", Serial: "2", NotAfter: notAfter},
+	})
+
+	delayForTrackingCompletion()
+
+	mustScrape()
+	families = mustScrape()
+
+	family := findMetricFamily(tlsCertsNotAfterName, families)
+	if assert.NotNil(t, family) {
+		assert.Len(t, family.Metric, 2)
+	}
+	assertGaugeValue(t, float64(notAfter.Add(time.Hour).Unix()), family, "store", "default", "sans", "example.com", "serial", "3")
+	assertGaugeValue(t, float64(notAfter.Unix()), family, "store", "default", "sans", "example.org", "serial", "2")
+}
+
 func TestPrometheusRemovedMetricsReset(t *testing.T) {
 	// Reset state of global promState.
 	defer promState.reset()
@@ -471,6 +746,25 @@ func assertCounterValue(t *testing.T, want float64, family *dto.MetricFamily, la
 	}
 }
 
+func assertGaugeValue(t *testing.T, want float64, family *dto.MetricFamily, labelNamesValues ...string) {
+	t.Helper()
+
+	metric := findMetricByLabelNamesValues(family, labelNamesValues...)
+
+	if metric == nil {
+		t.Error("metric must not be nil")
+		return
+	}
+	if metric.Gauge == nil {
+		t.Errorf("metric %s must be a gauge", family.GetName())
+		return
+	}
+
+	if gv := metric.Gauge.GetValue(); gv != want {
+		t.Errorf("metric %s has value %v, want %v", family.GetName(), gv, want)
+	}
+}
+
 func buildCounterAssert(t *testing.T, metricName string, expectedValue int) func(family *dto.MetricFamily) {
 	return func(family *dto.MetricFamily) {
 		if cv := int(family.Metric[0].Counter.GetValue()); cv != expectedValue {
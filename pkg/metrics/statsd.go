@@ -27,6 +27,10 @@ const (
 	statsdEntryPointOpenConnsName     = "entrypoint.connections.open"
 	statsdOpenConnsName               = "service.connections.open"
 	statsdServerUpName                = "service.server.up"
+	statsdGRPCActiveStreamsName       = "service.grpc.streams.active"
+	statsdGRPCMessagesName            = "service.grpc.messages.total"
+	statsdGRPCStreamDurationName      = "service.grpc.stream.duration"
+	statsdGRPCStreamsName             = "service.grpc.streams.total"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -66,6 +70,10 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 		registry.serviceRetriesCounter = statsdClient.NewCounter(statsdRetriesTotalName, 1.0)
 		registry.serviceOpenConnsGauge = statsdClient.NewGauge(statsdOpenConnsName)
 		registry.serviceServerUpGauge = statsdClient.NewGauge(statsdServerUpName)
+		registry.serviceGRPCActiveStreamsGauge = statsdClient.NewGauge(statsdGRPCActiveStreamsName)
+		registry.serviceGRPCMessagesCounter = statsdClient.NewCounter(statsdGRPCMessagesName, 1.0)
+		registry.serviceGRPCStreamDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdGRPCStreamDurationName, 1.0), time.Millisecond)
+		registry.serviceGRPCStreamsCounter = statsdClient.NewCounter(statsdGRPCStreamsName, 1.0)
 	}
 
 	return registry
@@ -60,6 +60,9 @@ func (crw *captureResponseWriter) Write(b []byte) (int, error) {
 
 func (crw *captureResponseWriter) WriteHeader(s int) {
 	crw.rw.WriteHeader(s)
+	if middlewares.IsInformational(s) {
+		return
+	}
 	crw.status = s
 }
 
@@ -48,3 +48,13 @@ func TestCloseNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestCaptureInformationalResponses(t *testing.T) {
+	rw := newCaptureResponseWriter(httptest.NewRecorder())
+
+	rw.WriteHeader(http.StatusEarlyHints)
+	assert.Equal(t, 0, rw.Status())
+
+	rw.WriteHeader(http.StatusNotFound)
+	assert.Equal(t, http.StatusNotFound, rw.Status())
+}
@@ -0,0 +1,262 @@
+// Package adaptiveconcurrency implements a middleware limiting the number of requests in flight,
+// with a limit adapted to the latency observed on the service.
+package adaptiveconcurrency
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "AdaptiveConcurrency"
+
+	algorithmGradient = "gradient"
+	algorithmAIMD     = "aimd"
+)
+
+// algorithm computes the new concurrency limit after each request.
+type algorithm interface {
+	// update returns the new limit, given the latency of a request,
+	// whether it failed, and the number of requests in flight when it was sent.
+	update(limit float64, latency time.Duration, failed bool, inFlight int64) float64
+}
+
+// adaptiveConcurrency is a middleware that rejects the requests exceeding a concurrency limit,
+// which is continuously adapted to the latency of the responses.
+type adaptiveConcurrency struct {
+	name      string
+	next      http.Handler
+	algorithm algorithm
+	minLimit  float64
+	maxLimit  float64
+	now       func() time.Time
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int64
+}
+
+// New creates an adaptive concurrency limiting middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.AdaptiveConcurrency, name string) (http.Handler, error) {
+	ctxLog := log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
+	log.FromContext(ctxLog).Debug("Creating middleware")
+
+	minLimit := config.MinLimit
+	if minLimit <= 0 {
+		minLimit = 1
+	}
+
+	maxLimit := config.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = 1000
+	}
+
+	if maxLimit < minLimit {
+		return nil, fmt.Errorf("maxLimit (%d) must be greater than or equal to minLimit (%d)", maxLimit, minLimit)
+	}
+
+	initialLimit := config.InitialLimit
+	if initialLimit <= 0 {
+		initialLimit = 20
+	}
+
+	var alg algorithm
+	switch config.Algorithm {
+	case algorithmGradient, "":
+		tolerance := config.Tolerance
+		if tolerance <= 0 {
+			tolerance = 1.5
+		}
+		if tolerance < 1 {
+			return nil, fmt.Errorf("tolerance must be greater than or equal to 1, got %v", tolerance)
+		}
+
+		alg = &gradient{tolerance: tolerance}
+
+	case algorithmAIMD:
+		latencyThreshold := time.Duration(config.LatencyThreshold)
+		if latencyThreshold <= 0 {
+			latencyThreshold = time.Second
+		}
+
+		backoffRatio := config.BackoffRatio
+		if backoffRatio == 0 {
+			backoffRatio = 0.9
+		}
+		if backoffRatio <= 0 || backoffRatio >= 1 {
+			return nil, fmt.Errorf("backoffRatio must be between 0 and 1, got %v", backoffRatio)
+		}
+
+		alg = &aimd{latencyThreshold: latencyThreshold, backoffRatio: backoffRatio}
+
+	default:
+		return nil, fmt.Errorf("unknown algorithm %q", config.Algorithm)
+	}
+
+	a := &adaptiveConcurrency{
+		name:      name,
+		next:      next,
+		algorithm: alg,
+		minLimit:  float64(minLimit),
+		maxLimit:  float64(maxLimit),
+		now:       time.Now,
+	}
+	a.limit = a.clamp(float64(initialLimit))
+
+	return a, nil
+}
+
+func (a *adaptiveConcurrency) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *adaptiveConcurrency) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	a.mu.Lock()
+	if a.inFlight >= int64(a.limit) {
+		limit := int64(a.limit)
+		a.mu.Unlock()
+
+		ctx := middlewares.GetLoggerCtx(req.Context(), a.name, typeName)
+		log.FromContext(ctx).Debugf("Concurrency limit (%d) reached", limit)
+
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	a.inFlight++
+	inFlight := a.inFlight
+	a.mu.Unlock()
+
+	recorder := &responseRecorder{ResponseWriter: rw, statusCode: http.StatusOK}
+	start := a.now()
+
+	defer func() {
+		latency := a.now().Sub(start)
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		a.inFlight--
+		a.limit = a.clamp(a.algorithm.update(a.limit, latency, recorder.statusCode >= http.StatusInternalServerError, inFlight))
+	}()
+
+	a.next.ServeHTTP(recorder, req)
+}
+
+func (a *adaptiveConcurrency) clamp(limit float64) float64 {
+	return math.Max(a.minLimit, math.Min(a.maxLimit, limit))
+}
+
+const (
+	// longLatencyWindow is the number of requests over which the long-term latency is averaged.
+	longLatencyWindow = 600
+	// smoothing is the weight of the new limit, computed from the last request, in the actual limit.
+	smoothing = 0.2
+)
+
+// gradient adapts the limit to the ratio between the long-term average latency and the latency of the last request:
+// the limit decreases as soon as the latency exceeds the average by more than the tolerance,
+// and grows, by the square root of the limit, as long as the latency stays stable.
+type gradient struct {
+	tolerance   float64
+	longLatency float64
+}
+
+func (g *gradient) update(limit float64, latency time.Duration, _ bool, inFlight int64) float64 {
+	shortLatency := math.Max(float64(latency), 1)
+
+	if g.longLatency == 0 {
+		g.longLatency = shortLatency
+	} else {
+		g.longLatency += (shortLatency - g.longLatency) / longLatencyWindow
+	}
+
+	// Makes the long-term latency recover faster when the service becomes faster, e.g. after a scale up.
+	if g.longLatency/shortLatency > 2 {
+		g.longLatency *= 0.95
+	}
+
+	// The latency does not reflect the capacity of the service while the limit is far from being reached.
+	if float64(inFlight) < limit/2 {
+		return limit
+	}
+
+	grad := math.Max(0.5, math.Min(1, g.tolerance*g.longLatency/shortLatency))
+	newLimit := limit*grad + math.Sqrt(limit)
+
+	return limit*(1-smoothing) + newLimit*smoothing
+}
+
+// aimd increases the limit by one while the requests succeed within the latency threshold,
+// and multiplies it by the backoff ratio otherwise.
+type aimd struct {
+	latencyThreshold time.Duration
+	backoffRatio     float64
+}
+
+func (l *aimd) update(limit float64, latency time.Duration, failed bool, inFlight int64) float64 {
+	if failed || latency > l.latencyThreshold {
+		return limit * l.backoffRatio
+	}
+
+	// The limit is only increased when it is used, to prevent it from growing indefinitely.
+	if float64(inFlight) >= limit/2 {
+		return limit + 1
+	}
+
+	return limit
+}
+
+// responseRecorder records the status code of the response, to detect the failed requests.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader && !middlewares.IsInformational(code) {
+		r.wroteHeader = true
+		r.statusCode = code
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(buf []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(buf)
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (r *responseRecorder) CloseNotify() <-chan bool {
+	return r.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
@@ -0,0 +1,215 @@
+package adaptiveconcurrency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdaptiveConcurrency(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	testCases := []struct {
+		desc        string
+		config      dynamic.AdaptiveConcurrency
+		expectedErr bool
+	}{
+		{
+			desc:   "empty configuration",
+			config: dynamic.AdaptiveConcurrency{},
+		},
+		{
+			desc:   "aimd",
+			config: dynamic.AdaptiveConcurrency{Algorithm: "aimd"},
+		},
+		{
+			desc:        "unknown algorithm",
+			config:      dynamic.AdaptiveConcurrency{Algorithm: "vegas"},
+			expectedErr: true,
+		},
+		{
+			desc:        "max limit lower than min limit",
+			config:      dynamic.AdaptiveConcurrency{MinLimit: 10, MaxLimit: 5},
+			expectedErr: true,
+		},
+		{
+			desc:        "tolerance lower than 1",
+			config:      dynamic.AdaptiveConcurrency{Tolerance: 0.5},
+			expectedErr: true,
+		},
+		{
+			desc:        "backoff ratio greater than 1",
+			config:      dynamic.AdaptiveConcurrency{Algorithm: "aimd", BackoffRatio: 1.5},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), next, test.config, "foo-adaptive")
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	config := dynamic.AdaptiveConcurrency{}
+	config.SetDefaults()
+
+	handler, err := New(context.Background(), next, config, "foo-adaptive")
+	require.NoError(t, err)
+
+	a := handler.(*adaptiveConcurrency)
+	assert.Equal(t, 20.0, a.limit)
+	assert.Equal(t, 1.0, a.minLimit)
+	assert.Equal(t, 1000.0, a.maxLimit)
+	assert.Equal(t, &gradient{tolerance: 1.5}, a.algorithm)
+}
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	var started sync.WaitGroup
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		started.Done()
+		<-release
+	})
+
+	// The limit cannot move, as the minimum and the maximum are the same.
+	config := dynamic.AdaptiveConcurrency{MinLimit: 2, MaxLimit: 2}
+
+	handler, err := New(context.Background(), next, config, "foo-adaptive")
+	require.NoError(t, err)
+
+	var done sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		started.Add(1)
+		done.Add(1)
+
+		go func() {
+			defer done.Done()
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+		}()
+	}
+
+	started.Wait()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	close(release)
+	done.Wait()
+
+	// The requests in flight are released.
+	started.Add(1)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestAdaptiveConcurrency_failures(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	config := dynamic.AdaptiveConcurrency{
+		Algorithm:        "aimd",
+		InitialLimit:     10,
+		MinLimit:         2,
+		LatencyThreshold: types.Duration(time.Second),
+		BackoffRatio:     0.5,
+	}
+
+	handler, err := New(context.Background(), next, config, "foo-adaptive")
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	}
+
+	assert.Equal(t, 2.0, handler.(*adaptiveConcurrency).limit)
+}
+
+func TestGradient(t *testing.T) {
+	g := &gradient{tolerance: 1.5}
+
+	limit := 20.0
+
+	// A stable latency, with the limit in use, lets the limit grow.
+	for i := 0; i < 10; i++ {
+		limit = g.update(limit, 100*time.Millisecond, false, int64(limit))
+	}
+	assert.Greater(t, limit, 20.0)
+
+	// The limit is not changed while it is far from being reached.
+	assert.Equal(t, limit, g.update(limit, 100*time.Millisecond, false, 1))
+
+	// A latency far above the long-term one makes the limit decrease.
+	previous := limit
+	limit = g.update(limit, time.Second, false, int64(limit))
+	assert.Less(t, limit, previous)
+}
+
+func TestAIMD(t *testing.T) {
+	l := &aimd{latencyThreshold: time.Second, backoffRatio: 0.5}
+
+	testCases := []struct {
+		desc     string
+		latency  time.Duration
+		failed   bool
+		inFlight int64
+		expected float64
+	}{
+		{
+			desc:     "limit in use",
+			latency:  100 * time.Millisecond,
+			inFlight: 10,
+			expected: 21,
+		},
+		{
+			desc:     "limit not in use",
+			latency:  100 * time.Millisecond,
+			inFlight: 2,
+			expected: 20,
+		},
+		{
+			desc:     "latency above the threshold",
+			latency:  2 * time.Second,
+			inFlight: 10,
+			expected: 10,
+		},
+		{
+			desc:     "failed request",
+			latency:  100 * time.Millisecond,
+			failed:   true,
+			inFlight: 10,
+			expected: 10,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, l.update(20, test.latency, test.failed, test.inFlight))
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Package admissioncontrol implements a middleware limiting the number of requests in flight,
+// and queuing the other requests, so that the requests of the highest priority are admitted first.
+package admissioncontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/vulcand/oxy/utils"
+)
+
+const typeName = "AdmissionControl"
+
+// admissionControl is a middleware admitting the requests through a shared priority queue.
+type admissionControl struct {
+	name            string
+	next            http.Handler
+	queue           *queue
+	maxWait         time.Duration
+	priorityHeader  string
+	priorityClasses map[string]int64
+	defaultPriority int64
+	// sourceMatcher identifies the client of the requests, when their share is limited.
+	sourceMatcher utils.SourceExtractor
+}
+
+// New creates an admission control middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.AdmissionControl, name string) (http.Handler, error) {
+	ctxLog := log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
+	log.FromContext(ctxLog).Debug("Creating middleware")
+
+	if config.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0, got %d", config.Amount)
+	}
+
+	maxQueueSize := config.MaxQueueSize
+	if maxQueueSize < 0 {
+		return nil, fmt.Errorf("maxQueueSize must be greater than or equal to 0, got %d", maxQueueSize)
+	}
+	if maxQueueSize == 0 {
+		maxQueueSize = 100
+	}
+
+	maxWait := time.Duration(config.MaxWait)
+	if maxWait < 0 {
+		return nil, fmt.Errorf("maxWait must be greater than or equal to 0, got %s", maxWait)
+	}
+	if maxWait == 0 {
+		maxWait = 10 * time.Second
+	}
+
+	if len(config.PriorityClasses) > 0 && config.PriorityHeader == "" {
+		return nil, errors.New("priorityClasses requires a priorityHeader")
+	}
+
+	if config.MaxClientShare < 0 || config.MaxClientShare > 1 {
+		return nil, fmt.Errorf("maxClientShare must be between 0 and 1, got %v", config.MaxClientShare)
+	}
+
+	var sourceMatcher utils.SourceExtractor
+	if config.MaxClientShare > 0 {
+		var err error
+		sourceMatcher, err = middlewares.GetSourceExtractor(ctxLog, config.SourceCriterion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	queueName := config.Queue
+	if queueName == "" {
+		queueName = name
+	}
+
+	return &admissionControl{
+		name:            name,
+		next:            next,
+		queue:           getQueue(queueName, config.Amount, int(maxQueueSize), config.MaxClientShare),
+		maxWait:         maxWait,
+		priorityHeader:  config.PriorityHeader,
+		priorityClasses: config.PriorityClasses,
+		defaultPriority: config.DefaultPriority,
+		sourceMatcher:   sourceMatcher,
+	}, nil
+}
+
+func (a *admissionControl) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *admissionControl) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), a.name, typeName)
+	logger := log.FromContext(ctx)
+
+	var client string
+	if a.sourceMatcher != nil {
+		var err error
+		client, _, err = a.sourceMatcher.Extract(req)
+		if err != nil {
+			logger.Errorf("could not extract source of request: %v", err)
+			http.Error(rw, "could not extract source of request", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	priority := a.priority(req)
+
+	if err := a.queue.acquire(req.Context(), client, priority, a.maxWait); err != nil {
+		logger.Debugf("Request of priority %d not admitted: %v", priority, err)
+
+		// The client is expected to retry once the requests currently queued have been served.
+		rw.Header().Set("Retry-After", fmt.Sprintf("%.0f", math.Max(1, math.Ceil(a.maxWait.Seconds()))))
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+	defer a.queue.release(client)
+
+	a.next.ServeHTTP(rw, req)
+}
+
+// priority returns the priority of the request, from the priority class in its priority header.
+func (a *admissionControl) priority(req *http.Request) int64 {
+	if a.priorityHeader == "" {
+		return a.defaultPriority
+	}
+
+	if priority, ok := a.priorityClasses[req.Header.Get(a.priorityHeader)]; ok {
+		return priority
+	}
+
+	return a.defaultPriority
+}
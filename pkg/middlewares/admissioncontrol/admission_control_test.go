@@ -0,0 +1,317 @@
+package admissioncontrol
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdmissionControl(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	testCases := []struct {
+		desc        string
+		config      dynamic.AdmissionControl
+		expectedErr bool
+	}{
+		{
+			desc:   "amount only",
+			config: dynamic.AdmissionControl{Amount: 10},
+		},
+		{
+			desc: "priority classes",
+			config: dynamic.AdmissionControl{
+				Amount:          10,
+				PriorityHeader:  "X-Priority",
+				PriorityClasses: map[string]int64{"high": 10},
+			},
+		},
+		{
+			desc: "client share",
+			config: dynamic.AdmissionControl{
+				Amount:          10,
+				MaxClientShare:  0.2,
+				SourceCriterion: &dynamic.SourceCriterion{RequestHeaderName: "X-Api-Key"},
+			},
+		},
+		{
+			desc:        "no amount",
+			config:      dynamic.AdmissionControl{},
+			expectedErr: true,
+		},
+		{
+			desc:        "negative queue size",
+			config:      dynamic.AdmissionControl{Amount: 10, MaxQueueSize: -1},
+			expectedErr: true,
+		},
+		{
+			desc:        "client share greater than 1",
+			config:      dynamic.AdmissionControl{Amount: 10, MaxClientShare: 1.5},
+			expectedErr: true,
+		},
+		{
+			desc: "invalid source criterion",
+			config: dynamic.AdmissionControl{
+				Amount:          10,
+				MaxClientShare:  0.2,
+				SourceCriterion: &dynamic.SourceCriterion{RequestHeaderName: "X-Api-Key", RequestHost: true},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:        "priority classes without header",
+			config:      dynamic.AdmissionControl{Amount: 10, PriorityClasses: map[string]int64{"high": 10}},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), next, test.config, "new-"+test.desc)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAdmissionControl_priority(t *testing.T) {
+	release := make(chan struct{})
+	admitted := make(chan string, 10)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		admitted <- req.Header.Get("X-Priority")
+		<-release
+	})
+
+	config := dynamic.AdmissionControl{
+		Amount:          1,
+		MaxQueueSize:    2,
+		MaxWait:         types.Duration(5 * time.Second),
+		PriorityHeader:  "X-Priority",
+		PriorityClasses: map[string]int64{"high": 10, "low": -10},
+	}
+
+	handler, err := New(context.Background(), next, config, "priority")
+	require.NoError(t, err)
+
+	q := handler.(*admissionControl).queue
+
+	codes := make(map[string]int)
+	var mu sync.Mutex
+	var done sync.WaitGroup
+
+	serve := func(class string) {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Set("X-Priority", class)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			mu.Lock()
+			codes[class] = recorder.Code
+			mu.Unlock()
+		}()
+	}
+
+	// The first request takes the only slot.
+	serve("first")
+	assert.Equal(t, "first", <-admitted)
+
+	serve("low")
+	waitForWaiters(t, q, 1)
+	serve("")
+	waitForWaiters(t, q, 2)
+
+	// The queue is full: the request of high priority evicts the one of low priority.
+	serve("high")
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return codes["low"] == http.StatusTooManyRequests
+	}, time.Second, 10*time.Millisecond)
+
+	// Once the slot is freed, the request of high priority is admitted before the one of default priority.
+	close(release)
+	assert.Equal(t, "high", <-admitted)
+	assert.Equal(t, "", <-admitted)
+
+	done.Wait()
+
+	assert.Equal(t, http.StatusOK, codes["first"])
+	assert.Equal(t, http.StatusTooManyRequests, codes["low"])
+	assert.Equal(t, http.StatusOK, codes["high"])
+	assert.Equal(t, http.StatusOK, codes[""])
+}
+
+func TestAdmissionControl_shed(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{})
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-release
+	})
+
+	config := dynamic.AdmissionControl{
+		Amount:       1,
+		MaxQueueSize: 1,
+		MaxWait:      types.Duration(1500 * time.Millisecond),
+	}
+
+	handler, err := New(context.Background(), next, config, "shed")
+	require.NoError(t, err)
+
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	<-started
+
+	// The queued request is shed once the maximum wait is reached.
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+}
+
+func TestAdmissionControl_sharedQueue(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	high, err := New(context.Background(), next, dynamic.AdmissionControl{Queue: "shared", Amount: 1, DefaultPriority: 10}, "high")
+	require.NoError(t, err)
+
+	low, err := New(context.Background(), next, dynamic.AdmissionControl{Queue: "shared", Amount: 2}, "low")
+	require.NoError(t, err)
+
+	assert.Same(t, high.(*admissionControl).queue, low.(*admissionControl).queue)
+	assert.Equal(t, int64(2), high.(*admissionControl).queue.amount)
+
+	assert.Equal(t, int64(10), high.(*admissionControl).priority(httptest.NewRequest(http.MethodGet, "http://localhost", nil)))
+	assert.Equal(t, int64(0), low.(*admissionControl).priority(httptest.NewRequest(http.MethodGet, "http://localhost", nil)))
+}
+
+func TestQueue_canceled(t *testing.T) {
+	q := newQueue()
+	q.setLimits(1, 10, 0)
+
+	require.NoError(t, q.acquire(context.Background(), "", 0, time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, q.acquire(ctx, "", 0, time.Second))
+	assert.Empty(t, q.waiters)
+
+	q.release("")
+	assert.Equal(t, int64(0), q.inFlight)
+}
+
+func waitForWaiters(t *testing.T, q *queue, expected int) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		return len(q.waiters) == expected
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestQueue_clientShare(t *testing.T) {
+	q := newQueue()
+	// Each client can take one of the two slots while they are all taken.
+	q.setLimits(2, 10, 0.5)
+
+	require.NoError(t, q.acquire(context.Background(), "a", 0, time.Second))
+	require.NoError(t, q.acquire(context.Background(), "b", 0, time.Second))
+
+	// The client has reached its share.
+	assert.Equal(t, errOverShare, q.acquire(context.Background(), "a", 0, time.Second))
+
+	admitted := make(chan string, 3)
+	enqueue := func(client string, priority int64, expectedWaiters int) {
+		go func() {
+			if q.acquire(context.Background(), client, priority, 5*time.Second) == nil {
+				admitted <- client
+			}
+		}()
+		waitForWaiters(t, q, expectedWaiters)
+	}
+
+	enqueue("c", 10, 1)
+	enqueue("c", 10, 2)
+	enqueue("d", 0, 3)
+
+	q.release("a")
+	assert.Equal(t, "c", <-admitted)
+
+	// The second request of c has a higher priority, but c has reached its share.
+	q.release("b")
+	assert.Equal(t, "d", <-admitted)
+
+	// Only the requests of clients over their share are waiting.
+	q.release("d")
+	assert.Equal(t, "c", <-admitted)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	assert.Equal(t, map[string]int64{"c": 2}, q.clients)
+}
+
+func TestAdmissionControl_clientShare(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{}, 2)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+
+	config := dynamic.AdmissionControl{
+		Amount:          2,
+		MaxClientShare:  0.5,
+		SourceCriterion: &dynamic.SourceCriterion{RequestHeaderName: "X-Api-Key"},
+	}
+
+	handler, err := New(context.Background(), next, config, "client-share")
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Set("X-Api-Key", "heavy")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+		<-started
+	}
+
+	// The amount is reached, and the heavy client takes more than its share.
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set("X-Api-Key", "heavy")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "10", recorder.Header().Get("Retry-After"))
+}
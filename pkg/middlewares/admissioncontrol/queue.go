@@ -0,0 +1,261 @@
+package admissioncontrol
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	errQueueFull = errors.New("admission queue full")
+	errEvicted   = errors.New("evicted by a request of higher priority")
+	errTimeout   = errors.New("timed out waiting for admission")
+	errOverShare = errors.New("client over its share")
+)
+
+var (
+	queuesMu sync.Mutex
+	queues   = make(map[string]*queue)
+)
+
+// getQueue returns the queue with the given name, creating it if needed.
+// As the queue outlives the configuration reloads, its limits are updated with the latest configuration.
+func getQueue(name string, amount int64, maxSize int, maxClientShare float64) *queue {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+
+	q, ok := queues[name]
+	if !ok {
+		q = newQueue()
+		queues[name] = q
+	}
+
+	q.setLimits(amount, maxSize, maxClientShare)
+
+	return q
+}
+
+// queue admits up to amount requests concurrently,
+// and makes the other requests wait, the ones of the highest priority being admitted first.
+// While the amount is reached, a client cannot take more than clientLimit requests in flight,
+// so that a single client cannot starve the other ones.
+type queue struct {
+	mu          sync.Mutex
+	amount      int64
+	maxSize     int
+	clientLimit int64
+	inFlight    int64
+	clients     map[string]int64
+	waiters     waiters
+	seq         uint64
+}
+
+func newQueue() *queue {
+	return &queue{clients: make(map[string]int64)}
+}
+
+func (q *queue) setLimits(amount int64, maxSize int, maxClientShare float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.amount = amount
+	q.maxSize = maxSize
+
+	q.clientLimit = 0
+	if maxClientShare > 0 {
+		q.clientLimit = int64(math.Max(1, math.Floor(maxClientShare*float64(amount))))
+	}
+
+	// The amount may have been increased.
+	q.dispatch()
+}
+
+// acquire waits for the request of the client to be admitted, during maxWait at most.
+// When the queue is full, the request of the lowest priority, the latest one among equals, is rejected.
+func (q *queue) acquire(ctx context.Context, client string, priority int64, maxWait time.Duration) error {
+	q.mu.Lock()
+
+	if q.inFlight < q.amount {
+		q.admit(client)
+		q.mu.Unlock()
+		return nil
+	}
+
+	if q.overShare(client) {
+		q.mu.Unlock()
+		return errOverShare
+	}
+
+	if len(q.waiters) >= q.maxSize {
+		lowest := q.waiters.lowest()
+		if lowest == nil || lowest.priority >= priority {
+			q.mu.Unlock()
+			return errQueueFull
+		}
+
+		heap.Remove(&q.waiters, lowest.index)
+		lowest.err = errEvicted
+		close(lowest.ready)
+	}
+
+	q.seq++
+	w := &waiter{client: client, priority: priority, seq: q.seq, ready: make(chan struct{})}
+	heap.Push(&q.waiters, w)
+	q.mu.Unlock()
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		return w.err
+	case <-timer.C:
+		err = errTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		// The request has been admitted, or evicted, in the meantime.
+		return w.err
+	default:
+	}
+
+	heap.Remove(&q.waiters, w.index)
+
+	return err
+}
+
+// release frees the slot of an admitted request of the client, and hands it to the next waiting request.
+func (q *queue) release(client string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.inFlight--
+
+	if client != "" {
+		q.clients[client]--
+		if q.clients[client] <= 0 {
+			delete(q.clients, client)
+		}
+	}
+
+	q.dispatch()
+}
+
+// dispatch admits the waiting requests while there are free slots. It must be called with the lock held.
+func (q *queue) dispatch() {
+	for q.inFlight < q.amount && len(q.waiters) > 0 {
+		w := q.next()
+		q.admit(w.client)
+		close(w.ready)
+	}
+}
+
+// next removes from the queue, and returns, the waiting request of the highest priority
+// whose client is not over its share. It must be called with the lock held.
+func (q *queue) next() *waiter {
+	if q.clientLimit == 0 {
+		return heap.Pop(&q.waiters).(*waiter)
+	}
+
+	var next *waiter
+	var skipped []*waiter
+	for len(q.waiters) > 0 {
+		w := heap.Pop(&q.waiters).(*waiter)
+		if !q.overShare(w.client) {
+			next = w
+			break
+		}
+		skipped = append(skipped, w)
+	}
+
+	if next == nil {
+		// Only clients over their share are waiting, so the slot would be wasted otherwise.
+		next, skipped = skipped[0], skipped[1:]
+	}
+
+	for _, w := range skipped {
+		heap.Push(&q.waiters, w)
+	}
+
+	return next
+}
+
+// admit takes a slot for a request of the client. It must be called with the lock held.
+func (q *queue) admit(client string) {
+	q.inFlight++
+
+	if client != "" {
+		q.clients[client]++
+	}
+}
+
+// overShare returns whether the client has reached its share of the amount. It must be called with the lock held.
+func (q *queue) overShare(client string) bool {
+	return q.clientLimit > 0 && client != "" && q.clients[client] >= q.clientLimit
+}
+
+// waiter is a request waiting to be admitted.
+type waiter struct {
+	client   string
+	priority int64
+	seq      uint64
+	index    int
+	// ready is closed once the request is admitted, or evicted, in which case err is set.
+	ready chan struct{}
+	err   error
+}
+
+// waiters is a heap of waiters, the one of the highest priority, and then the oldest one, being on top.
+type waiters []*waiter
+
+func (w waiters) Len() int { return len(w) }
+
+func (w waiters) Less(i, j int) bool {
+	if w[i].priority != w[j].priority {
+		return w[i].priority > w[j].priority
+	}
+	return w[i].seq < w[j].seq
+}
+
+func (w waiters) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+	w[i].index = i
+	w[j].index = j
+}
+
+func (w *waiters) Push(x interface{}) {
+	item := x.(*waiter)
+	item.index = len(*w)
+	*w = append(*w, item)
+}
+
+func (w *waiters) Pop() interface{} {
+	old := *w
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*w = old[:n-1]
+	return item
+}
+
+// lowest returns the waiter of the lowest priority, and then the latest one.
+func (w waiters) lowest() *waiter {
+	var lowest *waiter
+	for _, item := range w {
+		if lowest == nil || item.priority < lowest.priority ||
+			item.priority == lowest.priority && item.seq > lowest.seq {
+			lowest = item
+		}
+	}
+	return lowest
+}
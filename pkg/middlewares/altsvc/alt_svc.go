@@ -0,0 +1,125 @@
+package altsvc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "AltSvc"
+)
+
+// altSvc is a middleware that advertises an alternative service through the Alt-Svc response header.
+type altSvc struct {
+	next  http.Handler
+	value string
+	name  string
+}
+
+// New creates a new Alt-Svc middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.AltSvc, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	value, err := headerValue(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &altSvc{
+		next:  next,
+		value: value,
+		name:  name,
+	}, nil
+}
+
+func headerValue(config dynamic.AltSvc) (string, error) {
+	if config.Clear {
+		return "clear", nil
+	}
+
+	if config.Protocol == "" {
+		return "", errors.New("protocol cannot be empty")
+	}
+
+	if config.Port == "" {
+		return "", errors.New("port cannot be empty")
+	}
+
+	value := fmt.Sprintf(`%s=":%s"`, config.Protocol, config.Port)
+	if config.MaxAge > 0 {
+		value += fmt.Sprintf("; ma=%d", config.MaxAge)
+	}
+
+	return value, nil
+}
+
+func (a *altSvc) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *altSvc) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	a.next.ServeHTTP(&responseWriter{ResponseWriter: rw, value: a.value}, req)
+}
+
+// responseWriter adds the Alt-Svc header to the final response,
+// unless it has already been set by the service or an inner middleware.
+type responseWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (r *responseWriter) WriteHeader(code int) {
+	if !r.wroteHeader && !middlewares.IsInformational(code) {
+		r.wroteHeader = true
+
+		if _, ok := r.Header()["Alt-Svc"]; !ok {
+			r.Header().Set("Alt-Svc", r.value)
+		}
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseWriter) Write(buf []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	return r.ResponseWriter.Write(buf)
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseWriter) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (r *responseWriter) CloseNotify() <-chan bool {
+	return r.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
@@ -0,0 +1,81 @@
+package altsvc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAltSvc(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.AltSvc
+		serviceHeader string
+		expected      string
+		expectedError bool
+	}{
+		{
+			desc:     "protocol and port",
+			config:   dynamic.AltSvc{Protocol: "h2", Port: "443"},
+			expected: `h2=":443"`,
+		},
+		{
+			desc:     "with max age",
+			config:   dynamic.AltSvc{Protocol: "h2", Port: "8443", MaxAge: 3600},
+			expected: `h2=":8443"; ma=3600`,
+		},
+		{
+			desc:     "clear",
+			config:   dynamic.AltSvc{Protocol: "h2", Port: "443", Clear: true},
+			expected: "clear",
+		},
+		{
+			desc:          "service header is kept",
+			config:        dynamic.AltSvc{Protocol: "h2", Port: "443"},
+			serviceHeader: `h2="alt.example.com:443"`,
+			expected:      `h2="alt.example.com:443"`,
+		},
+		{
+			desc:          "missing port",
+			config:        dynamic.AltSvc{Protocol: "h2"},
+			expectedError: true,
+		},
+		{
+			desc:          "missing protocol",
+			config:        dynamic.AltSvc{Port: "443"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if test.serviceHeader != "" {
+					rw.Header().Set("Alt-Svc", test.serviceHeader)
+				}
+				_, _ = rw.Write([]byte("foo"))
+			})
+
+			handler, err := New(context.Background(), next, test.config, "foo-alt-svc")
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, []string{test.expected}, recorder.Header().Values("Alt-Svc"))
+		})
+	}
+}
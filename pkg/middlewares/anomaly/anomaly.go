@@ -0,0 +1,222 @@
+// Package anomaly implements a middleware temporarily blocking the sources whose request rate spikes above their recent baseline.
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/mailgun/ttlmap"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/vulcand/oxy/utils"
+)
+
+const (
+	typeName   = "Anomaly"
+	maxSources = 65536
+
+	// smoothing is the weight of the last period in the baseline (exponentially weighted moving average).
+	smoothing = 0.1
+	// maxIdlePeriods is the number of idle periods after which the baseline of a source has decayed enough to be considered reset.
+	maxIdlePeriods = 50
+)
+
+// source holds the request rate statistics of a traffic source.
+type source struct {
+	mu sync.Mutex
+	// periodStart is the start of the current measurement period.
+	periodStart time.Time
+	// count is the number of requests received during the current period.
+	count int64
+	// baseline is the moving average of the number of requests per period, over the previous periods.
+	// It is only meaningful once measured is true.
+	baseline float64
+	measured bool
+	// blockedUntil is the time until which the source is blocked.
+	blockedUntil time.Time
+}
+
+// anomaly is a middleware that blocks, for a while, the sources whose request rate exceeds their baseline by a given factor.
+type anomaly struct {
+	name          string
+	next          http.Handler
+	factor        float64
+	period        time.Duration
+	minRequests   int64
+	blockDuration time.Duration
+	sourceMatcher utils.SourceExtractor
+	now           func() time.Time
+
+	sources *ttlmap.TtlMap // keyed by source.
+}
+
+// New returns an anomaly detection middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Anomaly, name string) (http.Handler, error) {
+	ctxLog := log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
+	log.FromContext(ctxLog).Debug("Creating middleware")
+
+	if config.SourceCriterion == nil ||
+		config.SourceCriterion.IPStrategy == nil &&
+			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost &&
+			config.SourceCriterion.APIKey == "" {
+		config.SourceCriterion = &dynamic.SourceCriterion{
+			IPStrategy: &dynamic.IPStrategy{},
+		}
+	}
+
+	sourceMatcher, err := middlewares.GetSourceExtractor(ctxLog, config.SourceCriterion)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := ttlmap.NewConcurrent(maxSources)
+	if err != nil {
+		return nil, err
+	}
+
+	factor := config.Factor
+	if factor <= 1 {
+		factor = 10
+	}
+
+	period := time.Duration(config.Period)
+	if period <= 0 {
+		period = time.Second
+	}
+
+	blockDuration := time.Duration(config.BlockDuration)
+	if blockDuration <= 0 {
+		blockDuration = time.Minute
+	}
+
+	return &anomaly{
+		name:          name,
+		next:          next,
+		factor:        factor,
+		period:        period,
+		minRequests:   config.MinRequests,
+		blockDuration: blockDuration,
+		sourceMatcher: sourceMatcher,
+		now:           time.Now,
+		sources:       sources,
+	}, nil
+}
+
+func (a *anomaly) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *anomaly) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), a.name, typeName)
+	logger := log.FromContext(ctx)
+
+	key, _, err := a.sourceMatcher.Extract(req)
+	if err != nil {
+		logger.Errorf("could not extract source of request: %v", err)
+		http.Error(rw, "could not extract source of request", http.StatusInternalServerError)
+		return
+	}
+
+	now := a.now()
+
+	var src *source
+	if value, exists := a.sources.Get(key); exists {
+		src = value.(*source)
+	} else {
+		src = &source{periodStart: now}
+	}
+
+	retryAfter := src.hit(now, a)
+
+	// Refreshes the expiry of the source statistics.
+	if err := a.sources.Set(key, src, a.ttl()); err != nil {
+		logger.Errorf("could not insert source: %v", err)
+		http.Error(rw, "could not insert source", http.StatusInternalServerError)
+		return
+	}
+
+	if retryAfter > 0 {
+		logMessage := fmt.Sprintf("rejecting request %+v: anomalous request rate from source %q", req, key)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+		a.reject(ctx, rw, retryAfter)
+		return
+	}
+
+	a.next.ServeHTTP(rw, req)
+}
+
+// ttl returns the time, in seconds, during which the statistics of an idle source are kept.
+func (a *anomaly) ttl() int {
+	ttl := a.blockDuration
+	if idle := a.period * maxIdlePeriods; idle > ttl {
+		ttl = idle
+	}
+	return int(math.Ceil(ttl.Seconds()))
+}
+
+// hit records a request from the source at the given time,
+// and returns how long the source is blocked for, or zero if the request is allowed.
+func (s *source) hit(now time.Time, a *anomaly) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Before(s.blockedUntil) {
+		return s.blockedUntil.Sub(now)
+	}
+
+	if elapsed := int64(now.Sub(s.periodStart) / a.period); elapsed > 0 {
+		s.record(s.count)
+
+		idle := elapsed - 1
+		if idle > maxIdlePeriods {
+			idle = maxIdlePeriods
+		}
+		for i := int64(0); i < idle; i++ {
+			s.record(0)
+		}
+
+		s.count = 0
+		s.periodStart = s.periodStart.Add(time.Duration(elapsed) * a.period)
+	}
+
+	s.count++
+
+	if !s.measured || s.count < a.minRequests || float64(s.count) <= a.factor*math.Max(s.baseline, 1) {
+		return 0
+	}
+
+	// The requests of the anomalous period are not recorded, so that they do not inflate the baseline.
+	s.count = 0
+	s.blockedUntil = now.Add(a.blockDuration)
+	s.periodStart = s.blockedUntil
+
+	return a.blockDuration
+}
+
+// record folds the number of requests of a completed period into the baseline.
+func (s *source) record(count int64) {
+	if !s.measured {
+		s.baseline = float64(count)
+		s.measured = true
+		return
+	}
+
+	s.baseline = smoothing*float64(count) + (1-smoothing)*s.baseline
+}
+
+func (a *anomaly) reject(ctx context.Context, rw http.ResponseWriter, retryAfter time.Duration) {
+	rw.Header().Set("Retry-After", fmt.Sprintf("%.0f", math.Ceil(retryAfter.Seconds())))
+	rw.WriteHeader(http.StatusTooManyRequests)
+
+	if _, err := rw.Write([]byte(http.StatusText(http.StatusTooManyRequests))); err != nil {
+		log.FromContext(ctx).Errorf("could not serve 429: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package anomaly
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAnomaly(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, dynamic.Anomaly{Factor: 1}, "foo-anomaly")
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, handler.(*anomaly).factor)
+
+	config := dynamic.Anomaly{}
+	config.SetDefaults()
+
+	handler, err = New(context.Background(), next, config, "foo-anomaly")
+	require.NoError(t, err)
+
+	a := handler.(*anomaly)
+	assert.Equal(t, 10.0, a.factor)
+	assert.Equal(t, time.Second, a.period)
+	assert.Equal(t, int64(10), a.minRequests)
+	assert.Equal(t, time.Minute, a.blockDuration)
+}
+
+func TestAnomaly(t *testing.T) {
+	config := dynamic.Anomaly{
+		Factor:        3,
+		Period:        types.Duration(time.Second),
+		MinRequests:   5,
+		BlockDuration: types.Duration(10 * time.Second),
+		SourceCriterion: &dynamic.SourceCriterion{
+			RequestHeaderName: "X-Source",
+		},
+	}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, config, "foo-anomaly")
+	require.NoError(t, err)
+
+	now := time.Date(2020, time.March, 2, 10, 0, 0, 0, time.UTC)
+	handler.(*anomaly).now = func() time.Time { return now }
+
+	serve := func(source string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.Header.Set("X-Source", source)
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		return rw
+	}
+
+	// Steady rate of 2 requests per second.
+	for p := 0; p < 10; p++ {
+		assert.Equal(t, http.StatusOK, serve("foo").Code)
+		assert.Equal(t, http.StatusOK, serve("foo").Code)
+		now = now.Add(time.Second)
+	}
+
+	// Without a baseline, a burst is allowed.
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, http.StatusOK, serve("baz").Code)
+	}
+
+	// A spike above 3 times the baseline gets the source blocked.
+	var codes []int
+	for i := 0; i < 20; i++ {
+		codes = append(codes, serve("foo").Code)
+	}
+	assert.Contains(t, codes, http.StatusTooManyRequests)
+
+	rw := serve("foo")
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+	assert.Equal(t, "10", rw.Header().Get("Retry-After"))
+
+	// Other sources are not affected.
+	assert.Equal(t, http.StatusOK, serve("bar").Code)
+
+	// The block expires.
+	now = now.Add(11 * time.Second)
+	assert.Equal(t, http.StatusOK, serve("foo").Code)
+}
+
+func TestAnomalyMinRequests(t *testing.T) {
+	config := dynamic.Anomaly{
+		Factor:        2,
+		Period:        types.Duration(time.Second),
+		MinRequests:   10,
+		BlockDuration: types.Duration(time.Minute),
+	}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, config, "foo-anomaly")
+	require.NoError(t, err)
+
+	now := time.Date(2020, time.March, 2, 10, 0, 0, 0, time.UTC)
+	handler.(*anomaly).now = func() time.Time { return now }
+
+	// One request per second.
+	for p := 0; p < 5; p++ {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		now = now.Add(time.Second)
+	}
+
+	// Nine times the baseline, but below the minimum number of requests.
+	for i := 0; i < 9; i++ {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		assert.Equal(t, http.StatusOK, rw.Code)
+	}
+}
@@ -0,0 +1,98 @@
+// Package apikey implements a middleware authenticating the requests with API keys,
+// looked up in a file, a KV store, or Redis.
+package apikey
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName          = "APIKey"
+	defaultHeaderName = "X-API-Key"
+)
+
+type apiKey struct {
+	next               http.Handler
+	name               string
+	store              Store
+	headerName         string
+	queryParameterName string
+	removeHeader       bool
+}
+
+// New creates an APIKey middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.APIKey, name string) (http.Handler, error) {
+	log.FromContext(loggerCtx(ctx, name)).Debug("Creating middleware")
+
+	store, err := GetStore(name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	headerName := config.HeaderName
+	if headerName == "" {
+		headerName = defaultHeaderName
+	}
+
+	return &apiKey{
+		next:               next,
+		name:               name,
+		store:              store,
+		headerName:         headerName,
+		queryParameterName: config.QueryParameterName,
+		removeHeader:       config.RemoveHeader,
+	}, nil
+}
+
+func (a *apiKey) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *apiKey) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(loggerCtx(req.Context(), a.name))
+
+	value := req.Header.Get(a.headerName)
+	if value == "" && a.queryParameterName != "" {
+		value = req.URL.Query().Get(a.queryParameterName)
+	}
+
+	if value == "" {
+		logger.Debug("Missing API key")
+		tracing.SetErrorWithEvent(req, "Missing API key")
+		http.Error(rw, "Missing API key", http.StatusUnauthorized)
+		return
+	}
+
+	key, err := a.store.Get(value)
+	if errors.Is(err, ErrKeyNotFound) {
+		logger.Debug("Invalid API key")
+		tracing.SetErrorWithEvent(req, "Invalid API key")
+		http.Error(rw, "Invalid API key", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		logger.Errorf("Error while getting the API key: %v", err)
+		tracing.SetErrorWithEvent(req, "Error while getting the API key")
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	logger.Debugf("Authenticated API key of tenant %q", key.Tenant)
+
+	if a.removeHeader {
+		req.Header.Del(a.headerName)
+	}
+
+	a.next.ServeHTTP(rw, req.WithContext(WithKey(req.Context(), key)))
+}
+
+func loggerCtx(ctx context.Context, name string) context.Context {
+	return log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
+}
@@ -0,0 +1,211 @@
+package apikey
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKey(t *testing.T) {
+	dir := createTempDir(t)
+
+	filename := filepath.Join(dir, "keys.yaml")
+
+	store, err := GetStore("test-apikey", dynamic.APIKey{File: &dynamic.APIKeyFile{Filename: filename}})
+	require.NoError(t, err)
+
+	err = store.Put(Key{Value: "secret", Tenant: "acme", Plan: "gold"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc               string
+		config             dynamic.APIKey
+		url                string
+		header             string
+		expectedStatusCode int
+		expectedTenant     string
+		expectedHeader     string
+	}{
+		{
+			desc:               "valid key",
+			url:                "http://localhost/",
+			header:             "secret",
+			expectedStatusCode: http.StatusOK,
+			expectedTenant:     "acme",
+			expectedHeader:     "secret",
+		},
+		{
+			desc:               "missing key",
+			url:                "http://localhost/",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			desc:               "invalid key",
+			url:                "http://localhost/",
+			header:             "foo",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			desc:               "key in the query",
+			config:             dynamic.APIKey{QueryParameterName: "api_key"},
+			url:                "http://localhost/?api_key=secret",
+			expectedStatusCode: http.StatusOK,
+			expectedTenant:     "acme",
+		},
+		{
+			desc:               "key in the query, without query parameter",
+			url:                "http://localhost/?api_key=secret",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			desc:               "header removed",
+			config:             dynamic.APIKey{RemoveHeader: true},
+			url:                "http://localhost/",
+			header:             "secret",
+			expectedStatusCode: http.StatusOK,
+			expectedTenant:     "acme",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				key, ok := FromContext(req.Context())
+				require.True(t, ok)
+
+				assert.Equal(t, test.expectedTenant, key.Tenant)
+				assert.Equal(t, test.expectedHeader, req.Header.Get(defaultHeaderName))
+			})
+
+			config := test.config
+			config.File = &dynamic.APIKeyFile{Filename: filename}
+
+			handler, err := New(context.Background(), next, config, "test-apikey")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			if test.header != "" {
+				req.Header.Set(defaultHeaderName, test.header)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func TestAPIKey_dotSegments(t *testing.T) {
+	client := newMemoryKV()
+	// The parent of the root key, and the root key itself, which must not be looked up as keys.
+	client.pairs["traefik"] = []byte(`{"tenant":"acme"}`)
+	client.pairs["traefik/apikeys"] = []byte(`{"tenant":"acme"}`)
+
+	handler := &apiKey{
+		next: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			t.Error("unexpected request forwarded")
+		}),
+		name:       "test-apikey",
+		store:      &kvStore{client: client, rootKey: rootKey("")},
+		headerName: defaultHeaderName,
+	}
+
+	for _, value := range []string{"..", "."} {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+		req.Header.Set(defaultHeaderName, value)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, value)
+	}
+}
+
+func TestGetStore(t *testing.T) {
+	dir := createTempDir(t)
+
+	config := dynamic.APIKey{HeaderName: "X-Foo", File: &dynamic.APIKeyFile{Filename: filepath.Join(dir, "keys.yaml")}}
+
+	store, err := GetStore("test-getstore", config)
+	require.NoError(t, err)
+
+	// The store is kept when only the other options of the middleware change.
+	config.HeaderName = "X-Bar"
+	sameStore, err := GetStore("test-getstore", config)
+	require.NoError(t, err)
+	assert.Same(t, store, sameStore)
+
+	config.File = &dynamic.APIKeyFile{Filename: filepath.Join(dir, "other.yaml")}
+	otherStore, err := GetStore("test-getstore", config)
+	require.NoError(t, err)
+	assert.NotSame(t, store, otherStore)
+
+	_, err = GetStore("test-getstore-none", dynamic.APIKey{})
+	assert.Error(t, err)
+
+	_, err = GetStore("test-getstore-many", dynamic.APIKey{
+		File:  &dynamic.APIKeyFile{Filename: filepath.Join(dir, "keys.yaml")},
+		Redis: &dynamic.APIKeyRedis{},
+	})
+	assert.Error(t, err)
+}
+
+func TestKey_Field(t *testing.T) {
+	key := &Key{Value: "secret", Tenant: "acme", Plan: "gold"}
+
+	for field, expected := range map[string]string{"key": "secret", "tenant": "acme", "plan": "gold"} {
+		value, err := key.Field(field)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value)
+	}
+
+	_, err := key.Field("foo")
+	assert.Error(t, err)
+}
+
+func TestValidValue(t *testing.T) {
+	assert.NoError(t, ValidValue("aZ09-_.~"))
+	assert.Error(t, ValidValue(""))
+	assert.Error(t, ValidValue("foo/bar"))
+	assert.Error(t, ValidValue("foo bar"))
+	assert.Error(t, ValidValue("."))
+	assert.Error(t, ValidValue(".."))
+	assert.NoError(t, ValidValue("..."))
+
+	value, err := GenerateValue()
+	require.NoError(t, err)
+	assert.NoError(t, ValidValue(value))
+	assert.Len(t, value, 32)
+}
+
+func TestKey_ID(t *testing.T) {
+	foo := &Key{Value: "foo"}
+
+	assert.Len(t, foo.ID(), 16)
+	assert.Equal(t, foo.ID(), (&Key{Value: "foo", Tenant: "acme"}).ID())
+	assert.NotEqual(t, foo.ID(), (&Key{Value: "bar"}).ID())
+	assert.NotContains(t, foo.ID(), "foo")
+}
+
+func createTempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "traefik_apikey")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return dir
+}
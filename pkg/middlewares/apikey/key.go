@@ -0,0 +1,84 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+)
+
+// Key is an API key, with the metadata attached to the requests authenticated with it.
+type Key struct {
+	Value    string            `json:"key" yaml:"key"`
+	Tenant   string            `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	Plan     string            `json:"plan,omitempty" yaml:"plan,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// Field returns the value of a field of the key: key, tenant, or plan.
+func (k *Key) Field(name string) (string, error) {
+	switch name {
+	case "key":
+		return k.Value, nil
+	case "tenant":
+		return k.Tenant, nil
+	case "plan":
+		return k.Plan, nil
+	default:
+		return "", fmt.Errorf("unknown API key field: %s", name)
+	}
+}
+
+// ID returns the identifier of the key, derived from its value,
+// which designates the key in the API without disclosing its value.
+func (k *Key) ID() string {
+	sum := sha256.Sum256([]byte(k.Value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ValidValue checks that a key value can be used in the paths of the KV stores and the API.
+func ValidValue(value string) error {
+	if value == "" {
+		return errors.New("empty API key")
+	}
+
+	for _, c := range value {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '_' && c != '.' && c != '~' {
+			return fmt.Errorf("invalid character %q in API key", c)
+		}
+	}
+
+	// The dot segments would designate the root key, or its parent, in the KV stores.
+	if value == "." || value == ".." || path.Clean(value) != value {
+		return fmt.Errorf("invalid API key %q", value)
+	}
+
+	return nil
+}
+
+// GenerateValue generates a random key value.
+func GenerateValue() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+type contextKey struct{}
+
+// WithKey returns a copy of the context holding the authenticated key.
+func WithKey(ctx context.Context, key *Key) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// FromContext returns the key authenticated by an APIKey middleware, if any.
+func FromContext(ctx context.Context) (*Key, bool) {
+	key, ok := ctx.Value(contextKey{}).(*Key)
+	return key, ok
+}
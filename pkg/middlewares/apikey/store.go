@@ -0,0 +1,84 @@
+package apikey
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+// ErrKeyNotFound is returned by the stores when a key does not exist.
+var ErrKeyNotFound = errors.New("API key not found")
+
+// Store holds the API keys of an APIKey middleware.
+type Store interface {
+	// Get returns the key with the given value, or ErrKeyNotFound.
+	Get(value string) (*Key, error)
+	// List returns all the keys.
+	List() ([]Key, error)
+	// Put creates or replaces a key.
+	Put(key Key) error
+	// Delete removes the key with the given value, or returns ErrKeyNotFound.
+	Delete(value string) error
+}
+
+// stores holds the key stores of the APIKey middlewares, by middleware name,
+// so that they are shared by the routers using a middleware, kept across the configuration reloads,
+// and reachable from the key management API.
+var stores = storeRegistry{stores: make(map[string]*registeredStore)}
+
+type storeRegistry struct {
+	mu     sync.Mutex
+	stores map[string]*registeredStore
+}
+
+type registeredStore struct {
+	config dynamic.APIKey
+	store  Store
+}
+
+// GetStore returns the key store of an APIKey middleware,
+// which is created on the first call, and when the store configuration of the middleware changes.
+func GetStore(middlewareName string, config dynamic.APIKey) (Store, error) {
+	// Only the store configuration matters.
+	config = dynamic.APIKey{File: config.File, KV: config.KV, Redis: config.Redis}
+
+	stores.mu.Lock()
+	defer stores.mu.Unlock()
+
+	if registered, ok := stores.stores[middlewareName]; ok && reflect.DeepEqual(registered.config, config) {
+		return registered.store, nil
+	}
+
+	store, err := newStore(config)
+	if err != nil {
+		return nil, err
+	}
+
+	stores.stores[middlewareName] = &registeredStore{config: *config.DeepCopy(), store: store}
+
+	return store, nil
+}
+
+func newStore(config dynamic.APIKey) (Store, error) {
+	var defined int
+	for _, store := range []bool{config.File != nil, config.KV != nil, config.Redis != nil} {
+		if store {
+			defined++
+		}
+	}
+
+	switch {
+	case defined == 0:
+		return nil, errors.New("no API key store defined")
+	case defined > 1:
+		return nil, errors.New("file, kv, and redis are mutually exclusive")
+	case config.File != nil:
+		return newFileStore(config.File.Filename)
+	case config.KV != nil:
+		return newKVStore(*config.KV)
+	default:
+		return newRedisStore(*config.Redis)
+	}
+}
@@ -0,0 +1,228 @@
+package apikey
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// fileCheckInterval is the minimum duration between two checks of the modification of the key file.
+const fileCheckInterval = time.Second
+
+// fileStore holds the keys in a JSON or YAML file, depending on its extension.
+// The file is reloaded when it is modified, and rewritten when the keys are modified through the store.
+type fileStore struct {
+	filename string
+
+	mu        sync.RWMutex
+	keys      map[string]Key
+	modTime   time.Time
+	checkTime time.Time
+}
+
+func newFileStore(filename string) (*fileStore, error) {
+	s := &fileStore{filename: filename, keys: make(map[string]Key)}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *fileStore) Get(value string) (*Key, error) {
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[value]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return &key, nil
+}
+
+func (s *fileStore) List() ([]Key, error) {
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedKeys(), nil
+}
+
+func (s *fileStore) Put(key Key) error {
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.Value] = key
+
+	return s.save()
+}
+
+func (s *fileStore) Delete(value string) error {
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[value]; !ok {
+		return ErrKeyNotFound
+	}
+
+	delete(s.keys, value)
+
+	return s.save()
+}
+
+// reload loads the file again if it has been modified since the last load.
+func (s *fileStore) reload() error {
+	s.mu.RLock()
+	checked := time.Since(s.checkTime) < fileCheckInterval
+	s.mu.RUnlock()
+
+	if checked {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkTime = time.Now()
+
+	info, err := os.Stat(s.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	return s.loadLocked()
+}
+
+func (s *fileStore) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkTime = time.Now()
+
+	return s.loadLocked()
+}
+
+func (s *fileStore) loadLocked() error {
+	info, err := os.Stat(s.filename)
+	if err != nil {
+		// The file is created on the first modification of the keys.
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	content, err := ioutil.ReadFile(s.filename)
+	if err != nil {
+		return err
+	}
+
+	var keys []Key
+	if s.isJSON() {
+		err = json.Unmarshal(content, &keys)
+	} else {
+		err = yaml.Unmarshal(content, &keys)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.keys = make(map[string]Key, len(keys))
+	for _, key := range keys {
+		s.keys[key.Value] = key
+	}
+	s.modTime = info.ModTime()
+
+	return nil
+}
+
+func (s *fileStore) save() error {
+	keys := s.sortedKeys()
+
+	var content []byte
+	var err error
+	if s.isJSON() {
+		content, err = json.MarshalIndent(keys, "", "  ")
+	} else {
+		content, err = yaml.Marshal(keys)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The keys are written to a temporary file first, so that the file is never read partially written.
+	tmpFile, err := ioutil.TempFile(filepath.Dir(s.filename), filepath.Base(s.filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	if _, err = tmpFile.Write(content); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmpFile.Name(), s.filename); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(s.filename)
+	if err != nil {
+		return err
+	}
+	s.modTime = info.ModTime()
+
+	return nil
+}
+
+func (s *fileStore) sortedKeys() []Key {
+	keys := make([]Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Value < keys[j].Value
+	})
+
+	return keys
+}
+
+func (s *fileStore) isJSON() bool {
+	return strings.EqualFold(filepath.Ext(s.filename), ".json")
+}
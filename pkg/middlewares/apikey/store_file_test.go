@@ -0,0 +1,104 @@
+package apikey
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filename string
+		expected string
+	}{
+		{
+			desc:     "YAML",
+			filename: "keys.yaml",
+			expected: "- key: bar\n  tenant: acme\n- key: foo\n  tenant: acme\n  plan: gold\n  metadata:\n    owner: jane\n",
+		},
+		{
+			desc:     "JSON",
+			filename: "keys.json",
+			expected: `[{"key":"bar","tenant":"acme"},{"key":"foo","tenant":"acme","plan":"gold","metadata":{"owner":"jane"}}]`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.Join(createTempDir(t), test.filename)
+
+			store, err := newFileStore(filename)
+			require.NoError(t, err)
+
+			keys, err := store.List()
+			require.NoError(t, err)
+			assert.Empty(t, keys)
+
+			require.NoError(t, store.Put(Key{Value: "foo", Tenant: "acme", Plan: "gold", Metadata: map[string]string{"owner": "jane"}}))
+			require.NoError(t, store.Put(Key{Value: "bar", Tenant: "acme"}))
+			require.NoError(t, store.Put(Key{Value: "baz"}))
+			require.NoError(t, store.Delete("baz"))
+			assert.Equal(t, ErrKeyNotFound, store.Delete("baz"))
+
+			content, err := ioutil.ReadFile(filename)
+			require.NoError(t, err)
+			if test.filename == "keys.json" {
+				assert.JSONEq(t, test.expected, string(content))
+			} else {
+				assert.Equal(t, test.expected, string(content))
+			}
+
+			// The keys are loaded from the file by a new store.
+			store, err = newFileStore(filename)
+			require.NoError(t, err)
+
+			key, err := store.Get("foo")
+			require.NoError(t, err)
+			assert.Equal(t, &Key{Value: "foo", Tenant: "acme", Plan: "gold", Metadata: map[string]string{"owner": "jane"}}, key)
+
+			_, err = store.Get("baz")
+			assert.Equal(t, ErrKeyNotFound, err)
+		})
+	}
+}
+
+func TestFileStore_reload(t *testing.T) {
+	filename := filepath.Join(createTempDir(t), "keys.yaml")
+
+	err := ioutil.WriteFile(filename, []byte("- key: foo\n"), 0600)
+	require.NoError(t, err)
+
+	store, err := newFileStore(filename)
+	require.NoError(t, err)
+
+	_, err = store.Get("foo")
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filename, []byte("- key: bar\n"), 0600)
+	require.NoError(t, err)
+
+	// Makes sure that the modification time changes, whatever the resolution of the file system.
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filename, modTime, modTime))
+
+	// The modification is not checked before the check interval.
+	_, err = store.Get("foo")
+	require.NoError(t, err)
+
+	store.checkTime = time.Time{}
+
+	_, err = store.Get("foo")
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	_, err = store.Get("bar")
+	assert.NoError(t, err)
+}
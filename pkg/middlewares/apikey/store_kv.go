@@ -0,0 +1,149 @@
+package apikey
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/provider/kv"
+)
+
+const defaultRootKey = "traefik/apikeys"
+
+// kvStore holds the keys in a KV store, as JSON values under the root key.
+type kvStore struct {
+	client  store.Store
+	rootKey string
+}
+
+func newKVStore(config dynamic.APIKeyKV) (*kvStore, error) {
+	var backend store.Backend
+	switch config.Backend {
+	case "consul":
+		backend = store.CONSUL
+	case "etcd":
+		backend = store.ETCDV3
+	case "zookeeper":
+		backend = store.ZK
+	default:
+		return nil, fmt.Errorf("unsupported KV backend: %q", config.Backend)
+	}
+
+	tlsConfig, err := config.TLS.CreateTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kv.NewStore(backend, config.Endpoints, config.Username, config.Password, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kvStore{client: client, rootKey: rootKey(config.RootKey)}, nil
+}
+
+func newRedisStore(config dynamic.APIKeyRedis) (*kvStore, error) {
+	tlsConfig, err := config.TLS.CreateTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kv.NewStore(store.REDIS, config.Endpoints, "", config.Password, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kvStore{client: client, rootKey: rootKey(config.RootKey)}, nil
+}
+
+func (s *kvStore) Get(value string) (*Key, error) {
+	if err := ValidValue(value); err != nil {
+		return nil, ErrKeyNotFound
+	}
+
+	pair, err := s.client.Get(path.Join(s.rootKey, value), nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{}
+	if err := json.Unmarshal(pair.Value, key); err != nil {
+		return nil, fmt.Errorf("invalid API key: %w", err)
+	}
+	key.Value = value
+
+	return key, nil
+}
+
+func (s *kvStore) List() ([]Key, error) {
+	pairs, err := s.client.List(s.rootKey, nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return []Key{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]Key, 0, len(pairs))
+	for _, pair := range pairs {
+		// Some backends list the root key itself.
+		if path.Dir(pair.Key) != s.rootKey {
+			continue
+		}
+
+		var key Key
+		if err := json.Unmarshal(pair.Value, &key); err != nil {
+			return nil, fmt.Errorf("invalid API key %s: %w", pair.Key, err)
+		}
+		key.Value = path.Base(pair.Key)
+
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Value < keys[j].Value
+	})
+
+	return keys, nil
+}
+
+func (s *kvStore) Put(key Key) error {
+	if err := ValidValue(key.Value); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Put(path.Join(s.rootKey, key.Value), value, nil)
+}
+
+func (s *kvStore) Delete(value string) error {
+	if err := ValidValue(value); err != nil {
+		return ErrKeyNotFound
+	}
+
+	err := s.client.Delete(path.Join(s.rootKey, value))
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return ErrKeyNotFound
+	}
+
+	return err
+}
+
+func rootKey(key string) string {
+	if key == "" {
+		return defaultRootKey
+	}
+
+	return path.Clean(key)
+}
@@ -0,0 +1,123 @@
+package apikey
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVStore(t *testing.T) {
+	client := newMemoryKV()
+	kvStore := &kvStore{client: client, rootKey: rootKey("")}
+
+	keys, err := kvStore.List()
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, kvStore.Put(Key{Value: "foo", Tenant: "acme", Plan: "gold"}))
+	require.NoError(t, kvStore.Put(Key{Value: "bar", Tenant: "acme"}))
+	assert.Error(t, kvStore.Put(Key{Value: "foo/bar"}))
+
+	assert.Equal(t, `{"key":"foo","tenant":"acme","plan":"gold"}`, string(client.pairs["traefik/apikeys/foo"]))
+
+	key, err := kvStore.Get("foo")
+	require.NoError(t, err)
+	assert.Equal(t, &Key{Value: "foo", Tenant: "acme", Plan: "gold"}, key)
+
+	_, err = kvStore.Get("baz")
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	_, err = kvStore.Get("../foo")
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	keys, err = kvStore.List()
+	require.NoError(t, err)
+	assert.Equal(t, []Key{{Value: "bar", Tenant: "acme"}, {Value: "foo", Tenant: "acme", Plan: "gold"}}, keys)
+
+	require.NoError(t, kvStore.Delete("foo"))
+	assert.Equal(t, ErrKeyNotFound, kvStore.Delete("foo"))
+
+	keys, err = kvStore.List()
+	require.NoError(t, err)
+	assert.Equal(t, []Key{{Value: "bar", Tenant: "acme"}}, keys)
+}
+
+func TestNewKVStore_unsupportedBackend(t *testing.T) {
+	_, err := GetStore("test-kv-backend", dynamic.APIKey{KV: &dynamic.APIKeyKV{Backend: "boltdb"}})
+	assert.Error(t, err)
+}
+
+// memoryKV is an in-memory KV store, behaving like the Consul one.
+type memoryKV struct {
+	store.Store
+
+	mu    sync.Mutex
+	pairs map[string][]byte
+}
+
+func newMemoryKV() *memoryKV {
+	return &memoryKV{pairs: make(map[string][]byte)}
+}
+
+func (m *memoryKV) Get(key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.pairs[key]
+	if !ok {
+		return nil, store.ErrKeyNotFound
+	}
+
+	return &store.KVPair{Key: key, Value: value}, nil
+}
+
+func (m *memoryKV) List(directory string, _ *store.ReadOptions) ([]*store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pairs []*store.KVPair
+	for key, value := range m.pairs {
+		if strings.HasPrefix(key, directory+"/") {
+			pairs = append(pairs, &store.KVPair{Key: key, Value: value})
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+
+	// The root key itself is listed, as with Consul.
+	pairs = append(pairs, &store.KVPair{Key: directory})
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Key < pairs[j].Key
+	})
+
+	return pairs, nil
+}
+
+func (m *memoryKV) Put(key string, value []byte, _ *store.WriteOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pairs[key] = value
+	return nil
+}
+
+func (m *memoryKV) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.pairs[key]; !ok {
+		return store.ErrKeyNotFound
+	}
+
+	delete(m.pairs, key)
+	return nil
+}
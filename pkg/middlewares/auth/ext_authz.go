@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// The messages of the Envoy external authorization gRPC API (envoy.service.auth.v3),
+// limited to the fields used by the forward auth middleware.
+// They are wire compatible with the messages of the Envoy API, whose other fields are ignored,
+// and whose oneof fields are plain optional fields.
+
+const extAuthzCheckMethod = "/envoy.service.auth.v3.Authorization/Check"
+
+// checkRequest is envoy.service.auth.v3.CheckRequest.
+type checkRequest struct {
+	Attributes *attributeContext `protobuf:"bytes,1,opt,name=attributes,proto3"`
+}
+
+func (m *checkRequest) Reset()         { *m = checkRequest{} }
+func (m *checkRequest) String() string { return proto.CompactTextString(m) }
+func (*checkRequest) ProtoMessage()    {}
+
+// attributeContext is envoy.service.auth.v3.AttributeContext.
+type attributeContext struct {
+	Source            *peer             `protobuf:"bytes,1,opt,name=source,proto3"`
+	Destination       *peer             `protobuf:"bytes,2,opt,name=destination,proto3"`
+	Request           *request          `protobuf:"bytes,4,opt,name=request,proto3"`
+	ContextExtensions map[string]string `protobuf:"bytes,10,rep,name=context_extensions,json=contextExtensions,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *attributeContext) Reset()         { *m = attributeContext{} }
+func (m *attributeContext) String() string { return proto.CompactTextString(m) }
+func (*attributeContext) ProtoMessage()    {}
+
+// peer is envoy.service.auth.v3.AttributeContext.Peer.
+type peer struct {
+	Address   *address `protobuf:"bytes,1,opt,name=address,proto3"`
+	Principal string   `protobuf:"bytes,4,opt,name=principal,proto3"`
+}
+
+func (m *peer) Reset()         { *m = peer{} }
+func (m *peer) String() string { return proto.CompactTextString(m) }
+func (*peer) ProtoMessage()    {}
+
+// address is envoy.config.core.v3.Address.
+type address struct {
+	SocketAddress *socketAddress `protobuf:"bytes,1,opt,name=socket_address,json=socketAddress,proto3"`
+}
+
+func (m *address) Reset()         { *m = address{} }
+func (m *address) String() string { return proto.CompactTextString(m) }
+func (*address) ProtoMessage()    {}
+
+// socketAddress is envoy.config.core.v3.SocketAddress.
+type socketAddress struct {
+	Address   string `protobuf:"bytes,2,opt,name=address,proto3"`
+	PortValue uint32 `protobuf:"varint,3,opt,name=port_value,json=portValue,proto3"`
+}
+
+func (m *socketAddress) Reset()         { *m = socketAddress{} }
+func (m *socketAddress) String() string { return proto.CompactTextString(m) }
+func (*socketAddress) ProtoMessage()    {}
+
+// request is envoy.service.auth.v3.AttributeContext.Request.
+type request struct {
+	Time *timestamp   `protobuf:"bytes,1,opt,name=time,proto3"`
+	HTTP *httpRequest `protobuf:"bytes,2,opt,name=http,proto3"`
+}
+
+func (m *request) Reset()         { *m = request{} }
+func (m *request) String() string { return proto.CompactTextString(m) }
+func (*request) ProtoMessage()    {}
+
+// timestamp is google.protobuf.Timestamp.
+type timestamp struct {
+	Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3"`
+	Nanos   int32 `protobuf:"varint,2,opt,name=nanos,proto3"`
+}
+
+func (m *timestamp) Reset()         { *m = timestamp{} }
+func (m *timestamp) String() string { return proto.CompactTextString(m) }
+func (*timestamp) ProtoMessage()    {}
+
+// httpRequest is envoy.service.auth.v3.AttributeContext.HttpRequest.
+type httpRequest struct {
+	ID       string            `protobuf:"bytes,1,opt,name=id,proto3"`
+	Method   string            `protobuf:"bytes,2,opt,name=method,proto3"`
+	Headers  map[string]string `protobuf:"bytes,3,rep,name=headers,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Path     string            `protobuf:"bytes,4,opt,name=path,proto3"`
+	Host     string            `protobuf:"bytes,5,opt,name=host,proto3"`
+	Scheme   string            `protobuf:"bytes,6,opt,name=scheme,proto3"`
+	Size     int64             `protobuf:"varint,9,opt,name=size,proto3"`
+	Protocol string            `protobuf:"bytes,10,opt,name=protocol,proto3"`
+}
+
+func (m *httpRequest) Reset()         { *m = httpRequest{} }
+func (m *httpRequest) String() string { return proto.CompactTextString(m) }
+func (*httpRequest) ProtoMessage()    {}
+
+// checkResponse is envoy.service.auth.v3.CheckResponse.
+type checkResponse struct {
+	Status         *rpcStatus          `protobuf:"bytes,1,opt,name=status,proto3"`
+	DeniedResponse *deniedHTTPResponse `protobuf:"bytes,2,opt,name=denied_response,json=deniedResponse,proto3"`
+	OkResponse     *okHTTPResponse     `protobuf:"bytes,3,opt,name=ok_response,json=okResponse,proto3"`
+}
+
+func (m *checkResponse) Reset()         { *m = checkResponse{} }
+func (m *checkResponse) String() string { return proto.CompactTextString(m) }
+func (*checkResponse) ProtoMessage()    {}
+
+// rpcStatus is google.rpc.Status.
+type rpcStatus struct {
+	Code    int32  `protobuf:"varint,1,opt,name=code,proto3"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3"`
+}
+
+func (m *rpcStatus) Reset()         { *m = rpcStatus{} }
+func (m *rpcStatus) String() string { return proto.CompactTextString(m) }
+func (*rpcStatus) ProtoMessage()    {}
+
+// deniedHTTPResponse is envoy.service.auth.v3.DeniedHttpResponse.
+type deniedHTTPResponse struct {
+	Status  *httpStatus          `protobuf:"bytes,1,opt,name=status,proto3"`
+	Headers []*headerValueOption `protobuf:"bytes,2,rep,name=headers,proto3"`
+	Body    string               `protobuf:"bytes,3,opt,name=body,proto3"`
+}
+
+func (m *deniedHTTPResponse) Reset()         { *m = deniedHTTPResponse{} }
+func (m *deniedHTTPResponse) String() string { return proto.CompactTextString(m) }
+func (*deniedHTTPResponse) ProtoMessage()    {}
+
+// httpStatus is envoy.type.v3.HttpStatus.
+type httpStatus struct {
+	Code int32 `protobuf:"varint,1,opt,name=code,proto3"`
+}
+
+func (m *httpStatus) Reset()         { *m = httpStatus{} }
+func (m *httpStatus) String() string { return proto.CompactTextString(m) }
+func (*httpStatus) ProtoMessage()    {}
+
+// okHTTPResponse is envoy.service.auth.v3.OkHttpResponse.
+type okHTTPResponse struct {
+	Headers              []*headerValueOption `protobuf:"bytes,2,rep,name=headers,proto3"`
+	HeadersToRemove      []string             `protobuf:"bytes,5,rep,name=headers_to_remove,json=headersToRemove,proto3"`
+	ResponseHeadersToAdd []*headerValueOption `protobuf:"bytes,6,rep,name=response_headers_to_add,json=responseHeadersToAdd,proto3"`
+}
+
+func (m *okHTTPResponse) Reset()         { *m = okHTTPResponse{} }
+func (m *okHTTPResponse) String() string { return proto.CompactTextString(m) }
+func (*okHTTPResponse) ProtoMessage()    {}
+
+// headerValueOption is envoy.config.core.v3.HeaderValueOption.
+type headerValueOption struct {
+	Header *headerValue `protobuf:"bytes,1,opt,name=header,proto3"`
+	Append *boolValue   `protobuf:"bytes,2,opt,name=append,proto3"`
+}
+
+func (m *headerValueOption) Reset()         { *m = headerValueOption{} }
+func (m *headerValueOption) String() string { return proto.CompactTextString(m) }
+func (*headerValueOption) ProtoMessage()    {}
+
+// headerValue is envoy.config.core.v3.HeaderValue.
+type headerValue struct {
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *headerValue) Reset()         { *m = headerValue{} }
+func (m *headerValue) String() string { return proto.CompactTextString(m) }
+func (*headerValue) ProtoMessage()    {}
+
+// boolValue is google.protobuf.BoolValue.
+type boolValue struct {
+	Value bool `protobuf:"varint,1,opt,name=value,proto3"`
+}
+
+func (m *boolValue) Reset()         { *m = boolValue{} }
+func (m *boolValue) String() string { return proto.CompactTextString(m) }
+func (*boolValue) ProtoMessage()    {}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// field returns the encoding of a field with the given number and wire type, as defined by the protobuf specification,
+// for payloads shorter than 128 bytes.
+func field(number, wireType byte, payload ...byte) []byte {
+	encoded := []byte{number<<3 | wireType}
+	if wireType == 2 {
+		encoded = append(encoded, byte(len(payload)))
+	}
+
+	return append(encoded, payload...)
+}
+
+func concat(fields ...[]byte) []byte {
+	var encoded []byte
+	for _, f := range fields {
+		encoded = append(encoded, f...)
+	}
+
+	return encoded
+}
+
+// TestExtAuthzMessages_wireFormat checks the field numbers of the messages against the ones of
+// envoy/service/auth/v3/external_auth.proto and envoy/service/auth/v3/attribute_context.proto.
+func TestExtAuthzMessages_wireFormat(t *testing.T) {
+	const (
+		varint = 0
+		bytes  = 2
+	)
+
+	headerValueOptionBytes := field(1, bytes, concat(field(1, bytes, 'k'), field(2, bytes, 'v'))...)
+	option := &headerValueOption{Header: &headerValue{Key: "k", Value: "v"}}
+
+	testCases := []struct {
+		desc     string
+		message  proto.Message
+		expected []byte
+	}{
+		{
+			desc: "CheckRequest",
+			message: &checkRequest{Attributes: &attributeContext{
+				Source:            &peer{Principal: "s"},
+				Destination:       &peer{Address: &address{SocketAddress: &socketAddress{Address: "a", PortValue: 80}}},
+				Request:           &request{Time: &timestamp{Seconds: 1, Nanos: 2}},
+				ContextExtensions: map[string]string{"k": "v"},
+			}},
+			expected: field(1, bytes, concat(
+				field(1, bytes, field(4, bytes, 's')...),
+				field(2, bytes, field(1, bytes, field(1, bytes, concat(field(2, bytes, 'a'), field(3, varint, 80))...)...)...),
+				field(4, bytes, field(1, bytes, concat(field(1, varint, 1), field(2, varint, 2))...)...),
+				field(10, bytes, concat(field(1, bytes, 'k'), field(2, bytes, 'v'))...),
+			)...),
+		},
+		{
+			desc: "HttpRequest",
+			message: &httpRequest{
+				ID:       "i",
+				Method:   "m",
+				Headers:  map[string]string{"k": "v"},
+				Path:     "p",
+				Host:     "h",
+				Scheme:   "s",
+				Size:     3,
+				Protocol: "x",
+			},
+			expected: concat(
+				field(1, bytes, 'i'),
+				field(2, bytes, 'm'),
+				field(3, bytes, concat(field(1, bytes, 'k'), field(2, bytes, 'v'))...),
+				field(4, bytes, 'p'),
+				field(5, bytes, 'h'),
+				field(6, bytes, 's'),
+				field(9, varint, 3),
+				field(10, bytes, 'x'),
+			),
+		},
+		{
+			desc: "CheckResponse with a denied response",
+			message: &checkResponse{
+				Status: &rpcStatus{Code: 7, Message: "m"},
+				DeniedResponse: &deniedHTTPResponse{
+					Status:  &httpStatus{Code: 3},
+					Headers: []*headerValueOption{option},
+					Body:    "b",
+				},
+			},
+			expected: concat(
+				field(1, bytes, concat(field(1, varint, 7), field(2, bytes, 'm'))...),
+				field(2, bytes, concat(
+					field(1, bytes, field(1, varint, 3)...),
+					field(2, bytes, headerValueOptionBytes...),
+					field(3, bytes, 'b'),
+				)...),
+			),
+		},
+		{
+			desc: "CheckResponse with an ok response",
+			message: &checkResponse{
+				OkResponse: &okHTTPResponse{
+					Headers:              []*headerValueOption{option},
+					HeadersToRemove:      []string{"r"},
+					ResponseHeadersToAdd: []*headerValueOption{option},
+				},
+			},
+			expected: field(3, bytes, concat(
+				field(2, bytes, headerValueOptionBytes...),
+				field(5, bytes, 'r'),
+				field(6, bytes, headerValueOptionBytes...),
+			)...),
+		},
+		{
+			desc:     "HeaderValueOption",
+			message:  &headerValueOption{Header: &headerValue{Key: "k", Value: "v"}, Append: &boolValue{Value: true}},
+			expected: concat(headerValueOptionBytes, field(2, bytes, field(1, varint, 1)...)),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			encoded, err := proto.Marshal(test.message)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, encoded)
+		})
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/Masterminds/sprig"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares"
@@ -25,24 +27,56 @@ const (
 )
 
 type forwardAuth struct {
-	address             string
-	authResponseHeaders []string
-	next                http.Handler
-	name                string
-	client              http.Client
-	trustForwardHeader  bool
+	address                 string
+	authResponseHeaders     []string
+	clientResponseHeaders   []string
+	requestHeaderTemplates  map[string]*template.Template
+	responseHeaderTemplates map[string]*template.Template
+	next                    http.Handler
+	name                    string
+	client                  http.Client
+	trustForwardHeader      bool
+}
+
+// headerTemplateData is the data of the header templates.
+type headerTemplateData struct {
+	// Request holds the headers of the request.
+	Request http.Header
+	// Response holds the headers of the authentication server response.
+	Response http.Header
 }
 
 // NewForward creates a forward auth middleware.
 func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAuth, name string) (http.Handler, error) {
 	log.FromContext(middlewares.GetLoggerCtx(ctx, name, forwardedTypeName)).Debug("Creating middleware")
 
+	switch config.Protocol {
+	case "", "http":
+	case "grpc":
+		return newGRPCForward(next, config, name)
+	default:
+		return nil, fmt.Errorf("unknown forward auth protocol %q", config.Protocol)
+	}
+
+	requestHeaderTemplates, err := parseHeaderTemplates(config.RequestHeaderTemplates)
+	if err != nil {
+		return nil, err
+	}
+
+	responseHeaderTemplates, err := parseHeaderTemplates(config.ResponseHeaderTemplates)
+	if err != nil {
+		return nil, err
+	}
+
 	fa := &forwardAuth{
-		address:             config.Address,
-		authResponseHeaders: config.AuthResponseHeaders,
-		next:                next,
-		name:                name,
-		trustForwardHeader:  config.TrustForwardHeader,
+		address:                 config.Address,
+		authResponseHeaders:     config.AuthResponseHeaders,
+		clientResponseHeaders:   config.ClientResponseHeaders,
+		requestHeaderTemplates:  requestHeaderTemplates,
+		responseHeaderTemplates: responseHeaderTemplates,
+		next:                    next,
+		name:                    name,
+		trustForwardHeader:      config.TrustForwardHeader,
 	}
 
 	// Ensure our request client does not follow redirects
@@ -146,6 +180,28 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	data := headerTemplateData{Request: req.Header.Clone(), Response: forwardResponse.Header}
+
+	requestHeaders, err := executeHeaderTemplates(fa.requestHeaderTemplates, data)
+	if err != nil {
+		logMessage := fmt.Sprintf("Error building the request headers from the response of %s. Cause: %s", fa.address, err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	responseHeaders, err := executeHeaderTemplates(fa.responseHeaderTemplates, data)
+	if err != nil {
+		logMessage := fmt.Sprintf("Error building the response headers from the response of %s. Cause: %s", fa.address, err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	for _, headerName := range fa.authResponseHeaders {
 		headerKey := http.CanonicalHeaderKey(headerName)
 		req.Header.Del(headerKey)
@@ -154,10 +210,62 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	// The templated headers are removed from the request even when their value is empty,
+	// so that a client cannot supply them itself.
+	for name := range fa.requestHeaderTemplates {
+		req.Header.Del(name)
+	}
+
+	for name, value := range requestHeaders {
+		req.Header.Set(name, value)
+	}
+
+	for _, headerName := range fa.clientResponseHeaders {
+		headerKey := http.CanonicalHeaderKey(headerName)
+		for _, value := range forwardResponse.Header[headerKey] {
+			rw.Header().Add(headerKey, value)
+		}
+	}
+
+	for name, value := range responseHeaders {
+		rw.Header().Add(name, value)
+	}
+
 	req.RequestURI = req.URL.RequestURI()
 	fa.next.ServeHTTP(rw, req)
 }
 
+func parseHeaderTemplates(templates map[string]string) (map[string]*template.Template, error) {
+	parsed := make(map[string]*template.Template, len(templates))
+	for name, text := range templates {
+		// Only the repeatable functions are available, so that the templates cannot read the environment of Traefik.
+		tmpl, err := template.New(name).Funcs(sprig.HermeticTxtFuncMap()).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template of the %s header: %w", name, err)
+		}
+		parsed[name] = tmpl
+	}
+
+	return parsed, nil
+}
+
+// executeHeaderTemplates returns the values of the headers, the headers with an empty value being skipped.
+func executeHeaderTemplates(templates map[string]*template.Template, data headerTemplateData) (map[string]string, error) {
+	values := make(map[string]string, len(templates))
+	for name, tmpl := range templates {
+		var value strings.Builder
+		if err := tmpl.Execute(&value, data); err != nil {
+			return nil, fmt.Errorf("%s header: %w", name, err)
+		}
+
+		if value.Len() > 0 {
+			values[name] = value.String()
+		}
+	}
+
+	return values, nil
+}
+
 func writeHeader(req *http.Request, forwardReq *http.Request, trustForwardHeader bool) {
 	utils.CopyHeaders(forwardReq.Header, req.Header)
 	utils.RemoveHeaders(forwardReq.Header, forward.HopHeaders...)
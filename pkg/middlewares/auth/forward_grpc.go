@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/vulcand/oxy/forward"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+const forwardGRPCTimeout = 30 * time.Second
+
+// grpcForwardAuth checks the requests with an external authorization server speaking the Envoy ext_authz gRPC API.
+type grpcForwardAuth struct {
+	address           string
+	conn              *grpc.ClientConn
+	contextExtensions map[string]string
+	next              http.Handler
+	name              string
+}
+
+func newGRPCForward(next http.Handler, config dynamic.ForwardAuth, name string) (http.Handler, error) {
+	conn, err := grpcConns.get(config.Address, config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcForwardAuth{
+		address:           config.Address,
+		conn:              conn,
+		contextExtensions: config.ContextExtensions,
+		next:              next,
+		name:              name,
+	}, nil
+}
+
+func (fa *grpcForwardAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return fa.name, ext.SpanKindRPCClientEnum
+}
+
+func (fa *grpcForwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), fa.name, forwardedTypeName))
+
+	// Ensure tracing headers are in the request before we send its headers in the check request.
+	tracing.InjectRequestHeaders(req)
+
+	ctx, cancel := context.WithTimeout(req.Context(), forwardGRPCTimeout)
+	defer cancel()
+
+	resp := &checkResponse{}
+	if err := fa.conn.Invoke(ctx, extAuthzCheckMethod, fa.checkRequest(req), resp); err != nil {
+		logMessage := fmt.Sprintf("Error calling %s. Cause: %s", fa.address, err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if resp.Status != nil && codes.Code(resp.Status.Code) != codes.OK {
+		statusCode := http.StatusForbidden
+		var body string
+		if denied := resp.DeniedResponse; denied != nil {
+			if denied.Status != nil && denied.Status.Code != 0 {
+				statusCode = int(denied.Status.Code)
+			}
+			applyHeaders(rw.Header(), denied.Headers)
+			body = denied.Body
+		}
+
+		logger.Debugf("Remote error %s. Status: %s, %s", fa.address, codes.Code(resp.Status.Code), resp.Status.Message)
+
+		tracing.LogResponseCode(tracing.GetSpan(req), statusCode)
+		rw.WriteHeader(statusCode)
+
+		if _, err := rw.Write([]byte(body)); err != nil {
+			logger.Error(err)
+		}
+		return
+	}
+
+	if ok := resp.OkResponse; ok != nil {
+		applyHeaders(req.Header, ok.Headers)
+		for _, name := range ok.HeadersToRemove {
+			req.Header.Del(name)
+		}
+		applyHeaders(rw.Header(), ok.ResponseHeadersToAdd)
+	}
+
+	req.RequestURI = req.URL.RequestURI()
+	fa.next.ServeHTTP(rw, req)
+}
+
+// checkRequest returns the check request holding the attributes of the request.
+func (fa *grpcForwardAuth) checkRequest(req *http.Request) *checkRequest {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	headers := make(map[string]string, len(req.Header)+4)
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	for _, name := range forward.HopHeaders {
+		delete(headers, strings.ToLower(name))
+	}
+
+	headers[":authority"] = req.Host
+	headers[":method"] = req.Method
+	headers[":path"] = req.URL.RequestURI()
+	headers[":scheme"] = scheme
+
+	now := time.Now()
+
+	attributes := &attributeContext{
+		Source: &peer{Address: socketAddressOf(req.RemoteAddr)},
+		Request: &request{
+			Time: &timestamp{Seconds: now.Unix(), Nanos: int32(now.Nanosecond())},
+			HTTP: &httpRequest{
+				ID:       req.Header.Get("X-Request-Id"),
+				Method:   req.Method,
+				Headers:  headers,
+				Path:     req.URL.RequestURI(),
+				Host:     req.Host,
+				Scheme:   scheme,
+				Size:     req.ContentLength,
+				Protocol: req.Proto,
+			},
+		},
+		ContextExtensions: fa.contextExtensions,
+	}
+
+	if localAddr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		attributes.Destination = &peer{Address: socketAddressOf(localAddr.String())}
+	}
+
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		attributes.Source.Principal = req.TLS.PeerCertificates[0].Subject.String()
+	}
+
+	return &checkRequest{Attributes: attributes}
+}
+
+func socketAddressOf(addr string) *address {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return &address{SocketAddress: &socketAddress{Address: addr}}
+	}
+
+	portValue, _ := strconv.ParseUint(port, 10, 32)
+
+	return &address{SocketAddress: &socketAddress{Address: host, PortValue: uint32(portValue)}}
+}
+
+// applyHeaders sets the headers, or adds them when they are appended.
+func applyHeaders(header http.Header, options []*headerValueOption) {
+	for _, option := range options {
+		if option.Header == nil || option.Header.Key == "" {
+			continue
+		}
+
+		if option.Append != nil && option.Append.Value {
+			header.Add(option.Header.Key, option.Header.Value)
+		} else {
+			header.Set(option.Header.Key, option.Header.Value)
+		}
+	}
+}
+
+var grpcConns = &grpcConnPool{conns: make(map[string]*grpc.ClientConn)}
+
+// grpcConnPool shares the connections to the external authorization servers between the middlewares,
+// as the middlewares are created again on each configuration change.
+type grpcConnPool struct {
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func (p *grpcConnPool) get(address string, clientTLS *dynamic.ClientTLS) (*grpc.ClientConn, error) {
+	key := address
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+
+	if clientTLS != nil {
+		key = fmt.Sprintf("%s|%+v", address, *clientTLS)
+
+		tlsConfig, err := clientTLS.CreateTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if conn, ok := p.conns[key]; ok {
+		return conn, nil
+	}
+
+	conn, err := grpc.Dial(address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the authorization server %s: %w", address, err)
+	}
+
+	p.conns[key] = conn
+
+	return conn, nil
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// startExtAuthzServer starts an external authorization server answering the check requests with the given function.
+func startExtAuthzServer(t *testing.T, check func(*checkRequest) *checkResponse) (string, func()) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "envoy.service.auth.v3.Authorization",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Check",
+			Handler: func(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := &checkRequest{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				return check(req), nil
+			},
+		}},
+	}, struct{}{})
+
+	go func() { _ = server.Serve(listener) }()
+
+	return listener.Addr().String(), server.Stop
+}
+
+func TestGRPCForwardAuth(t *testing.T) {
+	checks := make(chan *checkRequest, 1)
+
+	address, stop := startExtAuthzServer(t, func(req *checkRequest) *checkResponse {
+		checks <- req
+
+		if req.Attributes.Request.HTTP.Headers["authorization"] != "Bearer token" {
+			return &checkResponse{
+				Status: &rpcStatus{Code: int32(codes.PermissionDenied)},
+				DeniedResponse: &deniedHTTPResponse{
+					Status:  &httpStatus{Code: http.StatusUnauthorized},
+					Headers: []*headerValueOption{{Header: &headerValue{Key: "WWW-Authenticate", Value: "Bearer"}}},
+					Body:    "invalid token",
+				},
+			}
+		}
+
+		return &checkResponse{
+			Status: &rpcStatus{Code: int32(codes.OK)},
+			OkResponse: &okHTTPResponse{
+				Headers: []*headerValueOption{
+					{Header: &headerValue{Key: "X-Auth-User", Value: "alice"}},
+					{Header: &headerValue{Key: "X-Groups", Value: "admins"}, Append: &boolValue{Value: true}},
+				},
+				HeadersToRemove:      []string{"Authorization"},
+				ResponseHeadersToAdd: []*headerValueOption{{Header: &headerValue{Key: "X-Auth-Checked", Value: "true"}}},
+			},
+		}
+	})
+	defer stop()
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "alice", req.Header.Get("X-Auth-User"))
+		assert.Equal(t, []string{"users", "admins"}, req.Header["X-Groups"])
+		assert.Empty(t, req.Header.Get("Authorization"))
+		_, _ = rw.Write([]byte("traefik"))
+	})
+
+	middleware, err := NewForward(context.Background(), next, dynamic.ForwardAuth{
+		Address:           address,
+		Protocol:          "grpc",
+		ContextExtensions: map[string]string{"route": "api"},
+	}, "authTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	defer ts.Close()
+
+	req := testhelpers.MustNewRequest(http.MethodPost, ts.URL+"/api/users?page=2", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Add("X-Groups", "users")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "traefik", string(body))
+	assert.Equal(t, "true", res.Header.Get("X-Auth-Checked"))
+
+	check := <-checks
+	assert.Equal(t, "127.0.0.1", check.Attributes.Source.Address.SocketAddress.Address)
+	assert.NotZero(t, check.Attributes.Source.Address.SocketAddress.PortValue)
+	assert.NotNil(t, check.Attributes.Destination)
+	assert.Equal(t, map[string]string{"route": "api"}, check.Attributes.ContextExtensions)
+
+	httpReq := check.Attributes.Request.HTTP
+	assert.Equal(t, http.MethodPost, httpReq.Method)
+	assert.Equal(t, "/api/users?page=2", httpReq.Path)
+	assert.Equal(t, "http", httpReq.Scheme)
+	assert.Equal(t, "HTTP/1.1", httpReq.Protocol)
+	assert.Equal(t, "/api/users?page=2", httpReq.Headers[":path"])
+	assert.Equal(t, httpReq.Host, httpReq.Headers[":authority"])
+	assert.Equal(t, "users", httpReq.Headers["x-groups"])
+
+	req = testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	body, err = ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(t, "Bearer", res.Header.Get("WWW-Authenticate"))
+	assert.Equal(t, "invalid token", string(body))
+	<-checks
+}
+
+func TestGRPCForwardAuth_deniedWithoutResponse(t *testing.T) {
+	address, stop := startExtAuthzServer(t, func(*checkRequest) *checkResponse {
+		return &checkResponse{Status: &rpcStatus{Code: int32(codes.PermissionDenied)}}
+	})
+	defer stop()
+
+	middleware, err := NewForward(context.Background(), http.NotFoundHandler(), dynamic.ForwardAuth{
+		Address:  address,
+		Protocol: "grpc",
+	}, "authTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	middleware.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestGRPCForwardAuth_unavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	middleware, err := NewForward(context.Background(), http.NotFoundHandler(), dynamic.ForwardAuth{
+		Address:  address,
+		Protocol: "grpc",
+	}, "authTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	middleware.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestNewForward_unknownProtocol(t *testing.T) {
+	_, err := NewForward(context.Background(), http.NotFoundHandler(), dynamic.ForwardAuth{
+		Address:  "127.0.0.1:9001",
+		Protocol: "thrift",
+	}, "authTest")
+	assert.Error(t, err)
+}
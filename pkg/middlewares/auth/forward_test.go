@@ -91,6 +91,85 @@ func TestForwardAuthSuccess(t *testing.T) {
 	assert.Equal(t, "traefik\n", string(body))
 }
 
+func TestForwardAuthSuccessResponseRewriting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "sso=abc; Path=/")
+		w.Header().Add("Set-Cookie", "theme=dark; Path=/")
+		w.Header().Set("X-Session-Id", "42")
+		w.Header().Set("X-Internal", "secret")
+		fmt.Fprintln(w, "Success")
+	}))
+	defer server.Close()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "legacy_session=42", r.Header.Get("Cookie"))
+		assert.Equal(t, "NDI=", r.Header.Get("X-Session"))
+		assert.Empty(t, r.Header.Values("X-Missing"))
+		fmt.Fprintln(w, "traefik")
+	})
+
+	auth := dynamic.ForwardAuth{
+		Address:               server.URL,
+		ClientResponseHeaders: []string{"Set-Cookie"},
+		RequestHeaderTemplates: map[string]string{
+			"Cookie":    `legacy_session={{ .Response.Get "X-Session-Id" }}`,
+			"X-Session": `{{ .Response.Get "X-Session-Id" | b64enc }}`,
+			"X-Missing": `{{ .Response.Get "X-Missing" }}`,
+		},
+		ResponseHeaderTemplates: map[string]string{
+			"Set-Cookie": `legacy_session={{ .Response.Get "X-Session-Id" }}; Path=/; HttpOnly`,
+			"X-User":     `{{ .Request.Get "X-User" }}`,
+		},
+	}
+	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	defer ts.Close()
+
+	req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set("Cookie", "sso=abc")
+	req.Header.Set("X-User", "alice")
+	req.Header.Set("X-Missing", "spoofed")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	assert.ElementsMatch(t, []string{"sso=abc; Path=/", "theme=dark; Path=/", "legacy_session=42; Path=/; HttpOnly"}, res.Header["Set-Cookie"])
+	assert.Equal(t, "alice", res.Header.Get("X-User"))
+	assert.Empty(t, res.Header.Get("X-Internal"))
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	err = res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "traefik\n", string(body))
+}
+
+func TestNewForward_invalidHeaderTemplate(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		template string
+	}{
+		{desc: "syntax error", template: "{{ .Response.Get "},
+		{desc: "environment function", template: `{{ env "HOME" }}`},
+		{desc: "network function", template: `{{ getHostByName "localhost" }}`},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewForward(context.Background(), http.NotFoundHandler(), dynamic.ForwardAuth{
+				Address:                "http://127.0.0.1",
+				RequestHeaderTemplates: map[string]string{"Cookie": test.template},
+			}, "authTest")
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestForwardAuthRedirect(t *testing.T) {
 	authTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "http://example.com/redirect-test", http.StatusFound)
@@ -0,0 +1,81 @@
+// Package authorization implements a middleware allowing the requests according to a policy,
+// mapping the identities of the clients (TLS client certificate, JWT claim, or API key) to the allowed methods and paths.
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const typeName = "Authorization"
+
+type authorization struct {
+	next     http.Handler
+	name     string
+	identify identifier
+	policy   *policy
+}
+
+// New creates an Authorization middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Authorization, name string) (http.Handler, error) {
+	log.FromContext(loggerCtx(ctx, name)).Debug("Creating middleware")
+
+	identify, err := newIdentifier(config)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := compilePolicy(config.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization policy: %w", err)
+	}
+
+	return &authorization{
+		next:     next,
+		name:     name,
+		identify: identify,
+		policy:   p,
+	}, nil
+}
+
+func (a *authorization) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *authorization) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(loggerCtx(req.Context(), a.name))
+
+	identities, err := a.identify(req)
+	if err != nil {
+		logger.Debugf("Unable to identify the client: %v", err)
+		tracing.SetErrorWithEvent(req, "Invalid client identity")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if a.policy.allow(identities, req) {
+		a.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if len(identities) == 0 {
+		logger.Debugf("Unidentified client not allowed to %s %s", req.Method, req.URL.Path)
+		tracing.SetErrorWithEvent(req, "Unidentified client")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	logger.Debugf("Client %q not allowed to %s %s", identities, req.Method, req.URL.Path)
+	tracing.SetErrorWithEvent(req, "Client not allowed")
+	http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+func loggerCtx(ctx context.Context, name string) context.Context {
+	return log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
+}
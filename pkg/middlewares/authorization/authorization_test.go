@@ -0,0 +1,233 @@
+package authorization
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares/apikey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+var testPolicy = []string{"* GET /public", "alice GET /api/**", "admins * /**"}
+
+func signToken(t *testing.T, algorithm jose.SignatureAlgorithm, key interface{}, claims map[string]interface{}) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: algorithm, Key: key}, nil)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestAuthorization_tlsClientCert(t *testing.T) {
+	handler, err := New(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.Authorization{
+		Identity: "tlsClientCert",
+		Policy:   testPolicy,
+	}, "authorization")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc       string
+		commonName string
+		unverified bool
+		path       string
+		expected   int
+	}{
+		{desc: "no certificate", path: "/public", expected: http.StatusOK},
+		{desc: "no certificate, private path", path: "/api/users", expected: http.StatusUnauthorized},
+		{desc: "allowed", commonName: "alice", path: "/api/users", expected: http.StatusOK},
+		{desc: "not allowed", commonName: "alice", path: "/admin", expected: http.StatusForbidden},
+		{desc: "unverified certificate", commonName: "alice", unverified: true, path: "/api/users", expected: http.StatusUnauthorized},
+		{desc: "unverified certificate, public path", commonName: "alice", unverified: true, path: "/public", expected: http.StatusUnauthorized},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost"+test.path, nil)
+			if test.commonName != "" {
+				cert := &x509.Certificate{Subject: pkix.Name{CommonName: test.commonName}}
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+				if !test.unverified {
+					req.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+				}
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
+
+func TestAuthorization_jwt(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	publicKeyFile, err := ioutil.TempFile("", "public-key")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(publicKeyFile.Name()) }()
+
+	require.NoError(t, pem.Encode(publicKeyFile, &pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	require.NoError(t, publicKeyFile.Close())
+
+	now := time.Now().Unix()
+
+	testCases := []struct {
+		desc     string
+		config   dynamic.AuthorizationJWT
+		token    string
+		path     string
+		expected int
+	}{
+		{
+			desc:     "HMAC",
+			config:   dynamic.AuthorizationJWT{Secret: "secret"},
+			token:    signToken(t, jose.HS256, []byte("secret"), map[string]interface{}{"sub": "alice"}),
+			path:     "/api/users",
+			expected: http.StatusOK,
+		},
+		{
+			desc:     "HMAC, invalid signature",
+			config:   dynamic.AuthorizationJWT{Secret: "secret"},
+			token:    signToken(t, jose.HS256, []byte("other"), map[string]interface{}{"sub": "alice"}),
+			path:     "/api/users",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			desc:     "RSA",
+			config:   dynamic.AuthorizationJWT{PublicKeyFile: publicKeyFile.Name()},
+			token:    signToken(t, jose.RS256, privateKey, map[string]interface{}{"sub": "alice"}),
+			path:     "/api/users",
+			expected: http.StatusOK,
+		},
+		{
+			desc:     "RSA, HMAC signed with the public key",
+			config:   dynamic.AuthorizationJWT{PublicKeyFile: publicKeyFile.Name()},
+			token:    signToken(t, jose.HS256, publicKey, map[string]interface{}{"sub": "alice"}),
+			path:     "/api/users",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			desc:     "claim list",
+			config:   dynamic.AuthorizationJWT{Secret: "secret", Claim: "groups"},
+			token:    signToken(t, jose.HS256, []byte("secret"), map[string]interface{}{"sub": "bob", "groups": []string{"users", "admins"}}),
+			path:     "/admin",
+			expected: http.StatusOK,
+		},
+		{
+			desc:     "not allowed",
+			config:   dynamic.AuthorizationJWT{Secret: "secret"},
+			token:    signToken(t, jose.HS256, []byte("secret"), map[string]interface{}{"sub": "alice"}),
+			path:     "/admin",
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "expired",
+			config:   dynamic.AuthorizationJWT{Secret: "secret"},
+			token:    signToken(t, jose.HS256, []byte("secret"), map[string]interface{}{"sub": "alice", "exp": now - 3600}),
+			path:     "/api/users",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			desc:     "issuer and audience",
+			config:   dynamic.AuthorizationJWT{Secret: "secret", Issuer: "idp", Audience: "traefik"},
+			token:    signToken(t, jose.HS256, []byte("secret"), map[string]interface{}{"sub": "alice", "iss": "idp", "aud": "traefik"}),
+			path:     "/api/users",
+			expected: http.StatusOK,
+		},
+		{
+			desc:     "wrong audience",
+			config:   dynamic.AuthorizationJWT{Secret: "secret", Issuer: "idp", Audience: "traefik"},
+			token:    signToken(t, jose.HS256, []byte("secret"), map[string]interface{}{"sub": "alice", "iss": "idp", "aud": "other"}),
+			path:     "/api/users",
+			expected: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			handler, err := New(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.Authorization{
+				Identity: "jwt",
+				JWT:      &test.config,
+				Policy:   testPolicy,
+			}, "authorization")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost"+test.path, nil)
+			req.Header.Set("Authorization", "Bearer "+test.token)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
+
+func TestAuthorization_apiKey(t *testing.T) {
+	handler, err := New(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.Authorization{
+		Identity:    "apiKey",
+		APIKeyField: "plan",
+		Policy:      []string{"premium GET /api/**"},
+	}, "authorization")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/api/users", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	req = req.WithContext(apikey.WithKey(req.Context(), &apikey.Key{Value: "key", Tenant: "acme", Plan: "premium"}))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.Authorization
+	}{
+		{desc: "unknown identity", config: dynamic.Authorization{Identity: "cookie", Policy: testPolicy}},
+		{desc: "missing JWT verification", config: dynamic.Authorization{Identity: "jwt", Policy: testPolicy}},
+		{desc: "missing JWT key", config: dynamic.Authorization{Identity: "jwt", JWT: &dynamic.AuthorizationJWT{}, Policy: testPolicy}},
+		{desc: "unknown API key field", config: dynamic.Authorization{Identity: "apiKey", APIKeyField: "owner", Policy: testPolicy}},
+		{desc: "invalid policy", config: dynamic.Authorization{Identity: "tlsClientCert", Policy: []string{"alice /api"}}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "authorization")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package authorization
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares/apikey"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	identityTLSClientCert = "tlsClientCert"
+	identityJWT           = "jwt"
+	identityAPIKey        = "apiKey"
+)
+
+// identifier returns the identities of the client sending the request, if any.
+type identifier func(req *http.Request) ([]string, error)
+
+func newIdentifier(config dynamic.Authorization) (identifier, error) {
+	switch config.Identity {
+	case identityTLSClientCert:
+		return tlsClientCertIdentity, nil
+
+	case identityJWT:
+		if config.JWT == nil {
+			return nil, errors.New("the JWT verification is required for the jwt identity")
+		}
+		return newJWTIdentifier(*config.JWT)
+
+	case identityAPIKey:
+		field := config.APIKeyField
+		if field == "" {
+			field = "tenant"
+		}
+
+		if _, err := (&apikey.Key{}).Field(field); err != nil {
+			return nil, err
+		}
+
+		return func(req *http.Request) ([]string, error) {
+			key, ok := apikey.FromContext(req.Context())
+			if !ok {
+				return nil, nil
+			}
+
+			value, err := key.Field(field)
+			if err != nil || value == "" {
+				return nil, err
+			}
+
+			return []string{value}, nil
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown identity source %q", config.Identity)
+	}
+}
+
+// tlsClientCertIdentity returns the common name of the subject of the verified client certificate.
+// A client certificate which has not been verified is refused, as its subject could be forged.
+func tlsClientCertIdentity(req *http.Request) ([]string, error) {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return nil, nil
+	}
+
+	if len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return nil, errors.New("the client certificate has not been verified")
+	}
+
+	commonName := req.TLS.VerifiedChains[0][0].Subject.CommonName
+	if commonName == "" {
+		return nil, nil
+	}
+
+	return []string{commonName}, nil
+}
+
+// newJWTIdentifier returns an identifier verifying the JWT of the request,
+// and returning the values of its identity claim.
+func newJWTIdentifier(config dynamic.AuthorizationJWT) (identifier, error) {
+	var key interface{}
+	switch {
+	case config.Secret != "" && config.PublicKeyFile != "":
+		return nil, errors.New("the JWT secret and public key file are mutually exclusive")
+	case config.Secret != "":
+		key = []byte(config.Secret)
+	case config.PublicKeyFile != "":
+		publicKey, err := loadPublicKey(config.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key = publicKey
+	default:
+		return nil, errors.New("a JWT secret or public key file is required")
+	}
+
+	headerName := config.HeaderName
+	if headerName == "" {
+		headerName = "Authorization"
+	}
+
+	claim := config.Claim
+	if claim == "" {
+		claim = "sub"
+	}
+
+	expected := jwt.Expected{Issuer: config.Issuer}
+	if config.Audience != "" {
+		expected.Audience = jwt.Audience{config.Audience}
+	}
+
+	return func(req *http.Request) ([]string, error) {
+		raw := req.Header.Get(headerName)
+		if len(raw) > 7 && strings.EqualFold(raw[:7], "Bearer ") {
+			raw = raw[7:]
+		}
+
+		if raw == "" {
+			return nil, nil
+		}
+
+		token, err := jwt.ParseSigned(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT: %w", err)
+		}
+
+		var registered jwt.Claims
+		var claims map[string]interface{}
+		if err = token.Claims(key, &registered, &claims); err != nil {
+			return nil, fmt.Errorf("invalid JWT: %w", err)
+		}
+
+		exp := expected
+		exp.Time = time.Now()
+		if err = registered.Validate(exp); err != nil {
+			return nil, fmt.Errorf("invalid JWT: %w", err)
+		}
+
+		switch value := claims[claim].(type) {
+		case string:
+			return []string{value}, nil
+		case []interface{}:
+			var identities []string
+			for _, v := range value {
+				if s, ok := v.(string); ok {
+					identities = append(identities, s)
+				}
+			}
+			return identities, nil
+		default:
+			return nil, nil
+		}
+	}, nil
+}
+
+// loadPublicKey loads a PEM encoded public key, or the public key of a PEM encoded certificate.
+func loadPublicKey(filename string) (interface{}, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", filename)
+	}
+
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
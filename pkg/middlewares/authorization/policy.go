@@ -0,0 +1,169 @@
+package authorization
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	// wildcard matches any identity, any method, or any path segment.
+	wildcard = "*"
+	// rest matches the remaining path segments, if any.
+	rest = "**"
+)
+
+// policy is the compiled form of the rules: a tree of the path segments for each identity.
+type policy struct {
+	identities map[string]*node
+}
+
+// node is a path segment of the rules, holding the methods allowed for the paths ending there.
+type node struct {
+	children map[string]*node
+	any      *node
+	methods  methods
+	rest     methods
+}
+
+// methods is a set of methods, holding the wildcard when all the methods are allowed.
+type methods map[string]struct{}
+
+func (m methods) allow(method string) bool {
+	if _, ok := m[wildcard]; ok {
+		return true
+	}
+
+	_, ok := m[method]
+	return ok
+}
+
+// compilePolicy compiles the rules, each one in the "<identities> <methods> <path>" format,
+// where the identities and methods are separated by "|".
+func compilePolicy(rules []string) (*policy, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("empty policy")
+	}
+
+	p := &policy{identities: make(map[string]*node)}
+
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid rule %q: expected \"<identities> <methods> <path>\"", rule)
+		}
+
+		if !strings.HasPrefix(fields[2], "/") {
+			return nil, fmt.Errorf("invalid rule %q: the path must start with /", rule)
+		}
+
+		segments := splitPath(fields[2])
+		for i, segment := range segments {
+			if segment == rest && i != len(segments)-1 {
+				return nil, fmt.Errorf("invalid rule %q: %s must be the last path segment", rule, rest)
+			}
+		}
+
+		var allowed []string
+		for _, method := range strings.Split(fields[1], "|") {
+			if method == "" {
+				return nil, fmt.Errorf("invalid rule %q: empty method", rule)
+			}
+			allowed = append(allowed, strings.ToUpper(method))
+		}
+
+		for _, identity := range strings.Split(fields[0], "|") {
+			if identity == "" {
+				return nil, fmt.Errorf("invalid rule %q: empty identity", rule)
+			}
+
+			root, ok := p.identities[identity]
+			if !ok {
+				root = &node{}
+				p.identities[identity] = root
+			}
+
+			root.add(segments, allowed)
+		}
+	}
+
+	return p, nil
+}
+
+func (n *node) add(segments []string, allowed []string) {
+	current := n
+	for _, segment := range segments {
+		switch segment {
+		case rest:
+			if current.rest == nil {
+				current.rest = make(methods)
+			}
+			for _, method := range allowed {
+				current.rest[method] = struct{}{}
+			}
+			return
+
+		case wildcard:
+			if current.any == nil {
+				current.any = &node{}
+			}
+			current = current.any
+
+		default:
+			if current.children == nil {
+				current.children = make(map[string]*node)
+			}
+			child, ok := current.children[segment]
+			if !ok {
+				child = &node{}
+				current.children[segment] = child
+			}
+			current = child
+		}
+	}
+
+	if current.methods == nil {
+		current.methods = make(methods)
+	}
+	for _, method := range allowed {
+		current.methods[method] = struct{}{}
+	}
+}
+
+func (n *node) match(segments []string, method string) bool {
+	if n.rest.allow(method) {
+		return true
+	}
+
+	if len(segments) == 0 {
+		return n.methods.allow(method)
+	}
+
+	if child, ok := n.children[segments[0]]; ok && child.match(segments[1:], method) {
+		return true
+	}
+
+	return n.any != nil && n.any.match(segments[1:], method)
+}
+
+// allow checks whether one of the identities, or the wildcard identity, is allowed to send the request.
+func (p *policy) allow(identities []string, req *http.Request) bool {
+	segments := splitPath(path.Clean("/" + req.URL.Path))
+
+	if root, ok := p.identities[wildcard]; ok && root.match(segments, req.Method) {
+		return true
+	}
+
+	for _, identity := range identities {
+		if root, ok := p.identities[identity]; ok && root.match(segments, req.Method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitPath(p string) []string {
+	return strings.Split(strings.TrimPrefix(p, "/"), "/")
+}
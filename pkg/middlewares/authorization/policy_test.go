@@ -0,0 +1,81 @@
+package authorization
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilePolicy_invalid(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		rules []string
+	}{
+		{desc: "empty policy"},
+		{desc: "missing path", rules: []string{"alice GET"}},
+		{desc: "relative path", rules: []string{"alice GET api"}},
+		{desc: "rest not last", rules: []string{"alice GET /api/**/users"}},
+		{desc: "empty method", rules: []string{"alice GET| /api"}},
+		{desc: "empty identity", rules: []string{"|alice GET /api"}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := compilePolicy(test.rules)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPolicy_allow(t *testing.T) {
+	p, err := compilePolicy([]string{
+		"* GET /health",
+		"alice|bob GET|HEAD /api/users/*",
+		"alice * /api/users/*/keys",
+		"admin * /**",
+		"ops post /jobs/**",
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc       string
+		identities []string
+		method     string
+		path       string
+		expected   bool
+	}{
+		{desc: "anyone", method: http.MethodGet, path: "/health", expected: true},
+		{desc: "anyone, other method", method: http.MethodPost, path: "/health"},
+		{desc: "segment wildcard", identities: []string{"bob"}, method: http.MethodHead, path: "/api/users/42", expected: true},
+		{desc: "segment wildcard, no segment", identities: []string{"bob"}, method: http.MethodGet, path: "/api/users"},
+		{desc: "segment wildcard, more segments", identities: []string{"bob"}, method: http.MethodGet, path: "/api/users/42/keys"},
+		{desc: "method wildcard", identities: []string{"alice"}, method: http.MethodDelete, path: "/api/users/42/keys", expected: true},
+		{desc: "method not allowed", identities: []string{"bob"}, method: http.MethodDelete, path: "/api/users/42"},
+		{desc: "rest", identities: []string{"admin"}, method: http.MethodPut, path: "/any/path", expected: true},
+		{desc: "rest, root", identities: []string{"admin"}, method: http.MethodGet, path: "/", expected: true},
+		{desc: "rest, lower case method", identities: []string{"ops"}, method: http.MethodPost, path: "/jobs/1/run", expected: true},
+		{desc: "rest, no remaining segment", identities: []string{"ops"}, method: http.MethodPost, path: "/jobs", expected: true},
+		{desc: "one of the identities", identities: []string{"guest", "ops"}, method: http.MethodPost, path: "/jobs/1", expected: true},
+		{desc: "unknown identity", identities: []string{"guest"}, method: http.MethodGet, path: "/api/users/42"},
+		{desc: "dot segments", identities: []string{"bob"}, method: http.MethodGet, path: "/api/users/42/../../admin"},
+		{desc: "cleaned path", identities: []string{"bob"}, method: http.MethodGet, path: "/api//users/./42", expected: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(test.method, "http://localhost", nil)
+			req.URL.Path = test.path
+
+			assert.Equal(t, test.expected, p.allow(test.identities, req))
+		})
+	}
+}
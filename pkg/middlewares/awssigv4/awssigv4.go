@@ -0,0 +1,99 @@
+// Package awssigv4 implements a middleware signing the requests with the AWS Signature Version 4,
+// so that AWS services such as S3, OpenSearch, or API Gateway can be used as backends.
+package awssigv4
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const typeName = "AWSSigV4"
+
+type awsSigV4 struct {
+	next            http.Handler
+	name            string
+	service         string
+	region          string
+	host            string
+	unsignedPayload bool
+	credentials     credentialsProvider
+}
+
+// New creates an AWSSigV4 middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.AWSSigV4, name string) (http.Handler, error) {
+	log.FromContext(loggerCtx(ctx, name)).Debug("Creating middleware")
+
+	if config.Service == "" || config.Region == "" {
+		return nil, errors.New("both the service and the region must be defined")
+	}
+
+	provider, err := newCredentialsProvider(config, http.DefaultClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &awsSigV4{
+		next:            next,
+		name:            name,
+		service:         config.Service,
+		region:          config.Region,
+		host:            config.Host,
+		unsignedPayload: config.UnsignedPayload,
+		credentials:     provider,
+	}, nil
+}
+
+func (a *awsSigV4) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *awsSigV4) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(loggerCtx(req.Context(), a.name))
+
+	creds, err := a.credentials.retrieve(req.Context())
+	if err != nil {
+		logger.Errorf("Unable to retrieve the AWS credentials: %v", err)
+		tracing.SetErrorWithEvent(req, "Unable to retrieve the AWS credentials")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	payloadHash := unsignedPayload
+	if !a.unsignedPayload {
+		var body []byte
+		if req.Body != nil {
+			body, err = ioutil.ReadAll(req.Body)
+			if err != nil {
+				logger.Debugf("Unable to read the request body: %v", err)
+				http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+		}
+		payloadHash = hashHex(body)
+	}
+
+	if a.host != "" {
+		req.Host = a.host
+	}
+
+	req.Header.Set(headerAmzContentSHA256, payloadHash)
+	signRequest(req, creds, a.region, a.service, payloadHash, time.Now())
+	req.RequestURI = req.URL.RequestURI()
+
+	a.next.ServeHTTP(rw, req)
+}
+
+func loggerCtx(ctx context.Context, name string) context.Context {
+	return log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
+}
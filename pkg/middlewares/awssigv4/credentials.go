@@ -0,0 +1,265 @@
+package awssigv4
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+const (
+	stsVersion = "2011-06-15"
+	stsTimeout = 10 * time.Second
+
+	// credentialsExpiryWindow is the time before their expiration when the temporary credentials are renewed.
+	credentialsExpiryWindow = 5 * time.Minute
+)
+
+// credentials are AWS credentials, either long-term ones or temporary ones expiring at Expiration.
+type credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time
+}
+
+func (c credentials) expired(now time.Time) bool {
+	return !c.Expiration.IsZero() && now.Add(credentialsExpiryWindow).After(c.Expiration)
+}
+
+type credentialsProvider interface {
+	retrieve(ctx context.Context) (credentials, error)
+}
+
+// newCredentialsProvider returns the provider of the credentials of the configuration,
+// the static ones, the ones of the environment, or the ones of the web identity,
+// which are used to assume the role of the configuration when it is defined.
+func newCredentialsProvider(config dynamic.AWSSigV4, client *http.Client) (credentialsProvider, error) {
+	sts := &stsClient{
+		endpoint: config.STSEndpoint,
+		client:   client,
+	}
+	if sts.endpoint == "" {
+		sts.endpoint = fmt.Sprintf("https://sts.%s.amazonaws.com", config.Region)
+	}
+
+	var provider credentialsProvider
+	switch {
+	case config.AccessKeyID != "" || config.SecretAccessKey != "":
+		if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+			return nil, errors.New("both the access key ID and the secret access key must be defined")
+		}
+		provider = staticProvider{credentials{
+			AccessKeyID:     config.AccessKeyID,
+			SecretAccessKey: config.SecretAccessKey,
+			SessionToken:    config.SessionToken,
+		}}
+
+	case os.Getenv("AWS_ACCESS_KEY_ID") != "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != "":
+		provider = staticProvider{credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}}
+
+	case os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "" && os.Getenv("AWS_ROLE_ARN") != "":
+		sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+		if sessionName == "" {
+			sessionName = config.RoleSessionName
+		}
+		provider = &cachingProvider{provider: webIdentityProvider{
+			sts:         sts,
+			tokenFile:   os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"),
+			roleARN:     os.Getenv("AWS_ROLE_ARN"),
+			sessionName: sessionName,
+		}}
+
+	default:
+		return nil, errors.New("no AWS credentials found in the configuration or in the environment")
+	}
+
+	if config.RoleARN == "" {
+		return provider, nil
+	}
+
+	return &cachingProvider{provider: assumeRoleProvider{
+		sts:         sts,
+		region:      config.Region,
+		base:        provider,
+		roleARN:     config.RoleARN,
+		sessionName: config.RoleSessionName,
+	}}, nil
+}
+
+// staticProvider provides credentials which never expire.
+type staticProvider struct {
+	creds credentials
+}
+
+func (p staticProvider) retrieve(_ context.Context) (credentials, error) {
+	return p.creds, nil
+}
+
+// cachingProvider caches the temporary credentials of a provider until they are about to expire.
+type cachingProvider struct {
+	provider credentialsProvider
+
+	mu    sync.Mutex
+	creds *credentials
+}
+
+func (p *cachingProvider) retrieve(ctx context.Context) (credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.creds != nil && !p.creds.expired(time.Now()) {
+		return *p.creds, nil
+	}
+
+	creds, err := p.provider.retrieve(ctx)
+	if err != nil {
+		return credentials{}, err
+	}
+
+	p.creds = &creds
+
+	return creds, nil
+}
+
+// webIdentityProvider exchanges a web identity token, such as the one of a Kubernetes service account, for temporary credentials.
+type webIdentityProvider struct {
+	sts         *stsClient
+	tokenFile   string
+	roleARN     string
+	sessionName string
+}
+
+func (p webIdentityProvider) retrieve(ctx context.Context) (credentials, error) {
+	token, err := ioutil.ReadFile(p.tokenFile)
+	if err != nil {
+		return credentials{}, fmt.Errorf("unable to read the web identity token: %w", err)
+	}
+
+	return p.sts.call(ctx, url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"RoleArn":          {p.roleARN},
+		"RoleSessionName":  {p.sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}, nil, "")
+}
+
+// assumeRoleProvider assumes a role with the credentials of a base provider.
+type assumeRoleProvider struct {
+	sts         *stsClient
+	region      string
+	base        credentialsProvider
+	roleARN     string
+	sessionName string
+}
+
+func (p assumeRoleProvider) retrieve(ctx context.Context) (credentials, error) {
+	base, err := p.base.retrieve(ctx)
+	if err != nil {
+		return credentials{}, err
+	}
+
+	return p.sts.call(ctx, url.Values{
+		"Action":          {"AssumeRole"},
+		"RoleArn":         {p.roleARN},
+		"RoleSessionName": {p.sessionName},
+	}, &base, p.region)
+}
+
+// stsClient calls the actions of the AWS Security Token Service returning temporary credentials.
+type stsClient struct {
+	endpoint string
+	client   *http.Client
+}
+
+type stsResponse struct {
+	AssumeRoleResult                *stsResult `xml:"AssumeRoleResult"`
+	AssumeRoleWithWebIdentityResult *stsResult `xml:"AssumeRoleWithWebIdentityResult"`
+}
+
+type stsResult struct {
+	Credentials struct {
+		AccessKeyID     string    `xml:"AccessKeyId"`
+		SecretAccessKey string    `xml:"SecretAccessKey"`
+		SessionToken    string    `xml:"SessionToken"`
+		Expiration      time.Time `xml:"Expiration"`
+	} `xml:"Credentials"`
+}
+
+type stsErrorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+// call calls the action of the parameters, signing the call with the credentials when they are defined.
+func (c *stsClient) call(ctx context.Context, params url.Values, creds *credentials, region string) (credentials, error) {
+	params.Set("Version", stsVersion)
+	action := params.Get("Action")
+
+	ctx, cancel := context.WithTimeout(ctx, stsTimeout)
+	defer cancel()
+
+	body := params.Encode()
+	req, err := http.NewRequest(http.MethodPost, c.endpoint+"/", strings.NewReader(body))
+	if err != nil {
+		return credentials{}, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	if creds != nil {
+		signRequest(req, *creds, region, "sts", hashHex([]byte(body)), time.Now())
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return credentials{}, fmt.Errorf("unable to call %s: %w", action, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return credentials{}, fmt.Errorf("unable to read the %s response: %w", action, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		stsErr := stsErrorResponse{}
+		if xml.Unmarshal(respBody, &stsErr) == nil && stsErr.Code != "" {
+			return credentials{}, fmt.Errorf("%s failed: %s: %s", action, stsErr.Code, stsErr.Message)
+		}
+		return credentials{}, fmt.Errorf("%s failed with the status code %d", action, resp.StatusCode)
+	}
+
+	stsResp := stsResponse{}
+	if err := xml.Unmarshal(respBody, &stsResp); err != nil {
+		return credentials{}, fmt.Errorf("unable to parse the %s response: %w", action, err)
+	}
+
+	result := stsResp.AssumeRoleResult
+	if result == nil {
+		result = stsResp.AssumeRoleWithWebIdentityResult
+	}
+	if result == nil || result.Credentials.AccessKeyID == "" {
+		return credentials{}, fmt.Errorf("no credentials in the %s response", action)
+	}
+
+	return credentials{
+		AccessKeyID:     result.Credentials.AccessKeyID,
+		SecretAccessKey: result.Credentials.SecretAccessKey,
+		SessionToken:    result.Credentials.SessionToken,
+		Expiration:      result.Credentials.Expiration,
+	}, nil
+}
@@ -0,0 +1,197 @@
+package awssigv4
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setEnv(t *testing.T, env map[string]string) func() {
+	t.Helper()
+
+	previous := make(map[string]*string)
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ROLE_SESSION_NAME"} {
+		if value, ok := os.LookupEnv(name); ok {
+			previous[name] = &value
+		} else {
+			previous[name] = nil
+		}
+		require.NoError(t, os.Unsetenv(name))
+	}
+
+	for name, value := range env {
+		require.NoError(t, os.Setenv(name, value))
+	}
+
+	return func() {
+		for name, value := range previous {
+			if value == nil {
+				_ = os.Unsetenv(name)
+			} else {
+				_ = os.Setenv(name, *value)
+			}
+		}
+	}
+}
+
+// startSTS starts a fake STS returning the credentials of the actions, and recording the calls.
+func startSTS(t *testing.T, calls chan<- *http.Request) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm())
+		calls <- req
+
+		if req.Form.Get("Action") == "AssumeRole" && req.Header.Get("Authorization") == "" {
+			rw.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(rw, `<ErrorResponse><Error><Code>MissingAuthenticationToken</Code><Message>Request is missing Authentication Token</Message></Error></ErrorResponse>`)
+			return
+		}
+
+		action := req.Form.Get("Action")
+		expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		_, _ = fmt.Fprintf(rw, `<%[1]sResponse><%[1]sResult><Credentials>
+<AccessKeyId>AKID-%[2]s</AccessKeyId>
+<SecretAccessKey>secret-%[2]s</SecretAccessKey>
+<SessionToken>token-%[2]s</SessionToken>
+<Expiration>%[3]s</Expiration>
+</Credentials></%[1]sResult><ResponseMetadata><RequestId>42</RequestId></ResponseMetadata></%[1]sResponse>`,
+			action, req.Form.Get("RoleSessionName"), expiration)
+	}))
+}
+
+func TestNewCredentialsProvider_static(t *testing.T) {
+	defer setEnv(t, map[string]string{"AWS_ACCESS_KEY_ID": "AKID-env", "AWS_SECRET_ACCESS_KEY": "secret-env"})()
+
+	provider, err := newCredentialsProvider(dynamic.AWSSigV4{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	}, http.DefaultClient)
+	require.NoError(t, err)
+
+	creds, err := provider.retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, creds)
+
+	_, err = newCredentialsProvider(dynamic.AWSSigV4{Region: "us-east-1", AccessKeyID: "AKID"}, http.DefaultClient)
+	assert.Error(t, err)
+}
+
+func TestNewCredentialsProvider_environment(t *testing.T) {
+	defer setEnv(t, map[string]string{
+		"AWS_ACCESS_KEY_ID":     "AKID-env",
+		"AWS_SECRET_ACCESS_KEY": "secret-env",
+		"AWS_SESSION_TOKEN":     "token-env",
+	})()
+
+	provider, err := newCredentialsProvider(dynamic.AWSSigV4{Region: "us-east-1"}, http.DefaultClient)
+	require.NoError(t, err)
+
+	creds, err := provider.retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, credentials{AccessKeyID: "AKID-env", SecretAccessKey: "secret-env", SessionToken: "token-env"}, creds)
+}
+
+func TestNewCredentialsProvider_none(t *testing.T) {
+	defer setEnv(t, nil)()
+
+	_, err := newCredentialsProvider(dynamic.AWSSigV4{Region: "us-east-1"}, http.DefaultClient)
+	assert.Error(t, err)
+}
+
+func TestNewCredentialsProvider_webIdentity(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "awssigv4")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	tokenFile := filepath.Join(tempDir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("web-token\n"), 0600))
+
+	defer setEnv(t, map[string]string{
+		"AWS_WEB_IDENTITY_TOKEN_FILE": tokenFile,
+		"AWS_ROLE_ARN":                "arn:aws:iam::123456789012:role/pod",
+		"AWS_ROLE_SESSION_NAME":       "pod",
+	})()
+
+	calls := make(chan *http.Request, 2)
+	sts := startSTS(t, calls)
+	defer sts.Close()
+
+	config := dynamic.AWSSigV4{Region: "eu-west-1", STSEndpoint: sts.URL}
+	config.SetDefaults()
+
+	provider, err := newCredentialsProvider(config, http.DefaultClient)
+	require.NoError(t, err)
+
+	creds, err := provider.retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKID-pod", creds.AccessKeyID)
+	assert.Equal(t, "secret-pod", creds.SecretAccessKey)
+	assert.Equal(t, "token-pod", creds.SessionToken)
+	assert.False(t, creds.Expiration.IsZero())
+
+	call := <-calls
+	assert.Equal(t, "AssumeRoleWithWebIdentity", call.Form.Get("Action"))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/pod", call.Form.Get("RoleArn"))
+	assert.Equal(t, "web-token", call.Form.Get("WebIdentityToken"))
+	assert.Equal(t, stsVersion, call.Form.Get("Version"))
+
+	// The credentials are cached until they expire.
+	_, err = provider.retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, calls)
+}
+
+func TestNewCredentialsProvider_assumeRole(t *testing.T) {
+	defer setEnv(t, nil)()
+
+	calls := make(chan *http.Request, 2)
+	sts := startSTS(t, calls)
+	defer sts.Close()
+
+	config := dynamic.AWSSigV4{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		RoleARN:         "arn:aws:iam::123456789012:role/backend",
+		STSEndpoint:     sts.URL,
+	}
+	config.SetDefaults()
+
+	provider, err := newCredentialsProvider(config, http.DefaultClient)
+	require.NoError(t, err)
+
+	creds, err := provider.retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKID-traefik", creds.AccessKeyID)
+
+	call := <-calls
+	assert.Equal(t, "AssumeRole", call.Form.Get("Action"))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/backend", call.Form.Get("RoleArn"))
+	assert.True(t, strings.HasPrefix(call.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+	assert.Contains(t, call.Header.Get("Authorization"), "/eu-west-1/sts/aws4_request")
+}
+
+func TestSTSClient_error(t *testing.T) {
+	calls := make(chan *http.Request, 1)
+	sts := startSTS(t, calls)
+	defer sts.Close()
+
+	client := &stsClient{endpoint: sts.URL, client: http.DefaultClient}
+
+	_, err := client.call(context.Background(), map[string][]string{"Action": {"AssumeRole"}}, nil, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MissingAuthenticationToken")
+}
@@ -0,0 +1,166 @@
+package awssigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+
+	headerAmzDate          = "X-Amz-Date"
+	headerAmzContentSHA256 = "X-Amz-Content-Sha256"
+	headerAmzSecurityToken = "X-Amz-Security-Token"
+)
+
+// signRequest signs the request with the Signature Version 4 of the credentials,
+// and sets its X-Amz-Date, X-Amz-Security-Token, and Authorization headers.
+// The signed headers are the Host header, and all the X-Amz-* headers of the request.
+// The path and query of the request URL are rewritten with the AWS URI encoding,
+// so that they are sent as they are signed.
+func signRequest(req *http.Request, creds credentials, region, service, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	scope := strings.Join([]string{now.Format("20060102"), region, service, "aws4_request"}, "/")
+
+	req.Header.Del("Authorization")
+	req.Header.Set(headerAmzDate, amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set(headerAmzSecurityToken, creds.SessionToken)
+	} else {
+		req.Header.Del(headerAmzSecurityToken)
+	}
+
+	req.URL.RawPath = uriEncode(req.URL.Path, false)
+	req.URL.RawQuery = canonicalQuery(req.URL.Query())
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL.RawPath, service != "s3"),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalHeaders returns the list of the signed headers, and their canonical form.
+func canonicalHeaders(req *http.Request) (string, string) {
+	values := map[string]string{"host": strings.TrimSpace(req.Host)}
+	for name, value := range req.Header {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+
+		trimmed := make([]string, len(value))
+		for i, v := range value {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		values[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name)
+		canonical.WriteByte(':')
+		canonical.WriteString(values[name])
+		canonical.WriteByte('\n')
+	}
+
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// canonicalPath returns the canonical form of the URI encoded path,
+// which is encoded a second time for all the services but S3.
+func canonicalPath(encodedPath string, doubleEncode bool) string {
+	if encodedPath == "" {
+		return "/"
+	}
+
+	if doubleEncode {
+		return uriEncode(encodedPath, false)
+	}
+
+	return encodedPath
+}
+
+// canonicalQuery returns the URI encoded query parameters, sorted by name and value.
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(params)
+
+	return strings.Join(params, "&")
+}
+
+// uriEncode encodes all the characters but the unreserved ones (and the slashes, when they are not encoded),
+// as AWS expects it.
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			encoded.WriteByte('%')
+			encoded.WriteByte(hexDigits[c>>4])
+			encoded.WriteByte(hexDigits[c&15])
+		}
+	}
+
+	return encoded.String()
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
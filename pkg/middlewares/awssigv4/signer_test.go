@@ -0,0 +1,114 @@
+package awssigv4
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The test cases of the AWS Signature Version 4 test suite.
+func TestSignRequest(t *testing.T) {
+	creds := credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		method   string
+		target   string
+		expected string
+	}{
+		{
+			desc:     "get-vanilla",
+			method:   http.MethodGet,
+			target:   "/",
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			desc:     "get-vanilla-query-order-key-case",
+			method:   http.MethodGet,
+			target:   "/?Param2=value2&Param1=value1",
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			desc:     "post-vanilla",
+			method:   http.MethodPost,
+			target:   "/",
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(test.method, "http://example.amazonaws.com"+test.target, nil)
+
+			signRequest(req, creds, "us-east-1", "service", hashHex(nil), now)
+
+			assert.Equal(t, test.expected, req.Header.Get("Authorization"))
+			assert.Equal(t, "20150830T123600Z", req.Header.Get(headerAmzDate))
+		})
+	}
+}
+
+func TestSignRequest_sessionToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.amazonaws.com/", nil)
+	req.Header.Set(headerAmzSecurityToken, "client")
+
+	signRequest(req, credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, "us-east-1", "service", hashHex(nil), time.Now())
+	assert.Empty(t, req.Header.Get(headerAmzSecurityToken))
+
+	signRequest(req, credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}, "us-east-1", "service", hashHex(nil), time.Now())
+	assert.Equal(t, "token", req.Header.Get(headerAmzSecurityToken))
+	assert.True(t, strings.Contains(req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token,"))
+}
+
+func TestSignRequest_encoding(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		service           string
+		target            string
+		expectedPath      string
+		expectedQuery     string
+		expectedCanonical string
+	}{
+		{
+			desc:              "S3",
+			service:           "s3",
+			target:            "/my%20bucket/a+b/%C3%A9t%C3%A9?prefix=a+b&delimiter=%2F&acl",
+			expectedPath:      "/my%20bucket/a%2Bb/%C3%A9t%C3%A9",
+			expectedQuery:     "acl=&delimiter=%2F&prefix=a%20b",
+			expectedCanonical: "/my%20bucket/a%2Bb/%C3%A9t%C3%A9",
+		},
+		{
+			desc:              "other service",
+			service:           "es",
+			target:            "/my%20index/_doc/1",
+			expectedPath:      "/my%20index/_doc/1",
+			expectedCanonical: "/my%2520index/_doc/1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.amazonaws.com"+test.target, nil)
+
+			signRequest(req, credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, "us-east-1", test.service, hashHex(nil), time.Now())
+
+			assert.Equal(t, test.expectedPath, req.URL.EscapedPath())
+			assert.Equal(t, test.expectedQuery, req.URL.RawQuery)
+			assert.Equal(t, test.expectedCanonical, canonicalPath(req.URL.EscapedPath(), test.service != "s3"))
+		})
+	}
+}
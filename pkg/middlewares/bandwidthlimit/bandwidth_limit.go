@@ -0,0 +1,157 @@
+// Package bandwidthlimit implements a middleware limiting the aggregate throughput of the responses with a token bucket.
+package bandwidthlimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/time/rate"
+)
+
+const (
+	typeName = "BandwidthLimit"
+)
+
+// errResponseTrimmed is the error of the writes waiting longer than the maximum delay for bandwidth.
+var errResponseTrimmed = errors.New("response trimmed: bandwidth limit exceeded")
+
+// bandwidthLimit limits the aggregate throughput of the response bodies with a token bucket of bytes,
+// shared by all the responses of the router.
+type bandwidthLimit struct {
+	name     string
+	next     http.Handler
+	limiter  *rate.Limiter
+	burst    int
+	maxDelay time.Duration
+}
+
+// New creates a bandwidth limit middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.BandwidthLimit, name string) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	if config.Rate <= 0 {
+		return nil, fmt.Errorf("invalid rate: %d bytes/s, must be positive", config.Rate)
+	}
+
+	burst := config.Burst
+	if burst <= 0 {
+		burst = config.Rate
+	}
+
+	logger.Debugf("Setting up bandwidth limit: %d bytes/s, burst of %d bytes, max delay of %s", config.Rate, burst, time.Duration(config.MaxDelay))
+
+	return &bandwidthLimit{
+		name:     name,
+		next:     next,
+		limiter:  rate.NewLimiter(rate.Limit(config.Rate), int(burst)),
+		burst:    int(burst),
+		maxDelay: time.Duration(config.MaxDelay),
+	}, nil
+}
+
+func (b *bandwidthLimit) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return b.name, tracing.SpanKindNoneEnum
+}
+
+func (b *bandwidthLimit) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	b.next.ServeHTTP(&limitedResponseWriter{ResponseWriter: rw, ctx: req.Context(), limit: b}, req)
+}
+
+// wait waits until the bucket holds n bytes, or fails if it would wait longer than the maximum delay.
+func (b *bandwidthLimit) wait(ctx context.Context, n int) error {
+	reservation := b.limiter.ReserveN(time.Now(), n)
+	if !reservation.OK() {
+		return errResponseTrimmed
+	}
+
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+
+	if b.maxDelay > 0 && delay > b.maxDelay {
+		reservation.Cancel()
+		return errResponseTrimmed
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	}
+}
+
+// limitedResponseWriter writes the response body at the pace of the bandwidth limit, in chunks of at most the burst.
+type limitedResponseWriter struct {
+	http.ResponseWriter
+	ctx   context.Context
+	limit *bandwidthLimit
+}
+
+func (w *limitedResponseWriter) Write(b []byte) (int, error) {
+	var written int
+	for len(b) > 0 {
+		n := len(b)
+		if n > w.limit.burst {
+			n = w.limit.burst
+		}
+
+		if err := w.limit.wait(w.ctx, n); err != nil {
+			if errors.Is(err, errResponseTrimmed) {
+				log.FromContext(middlewares.GetLoggerCtx(w.ctx, w.limit.name, typeName)).Debug(err)
+			}
+			return written, err
+		}
+
+		m, err := w.ResponseWriter.Write(b[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+
+		b = b[n:]
+	}
+
+	return written, nil
+}
+
+// Hijack hijacks the connection, whose throughput is then not limited anymore.
+func (w *limitedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+	}
+	return h.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (w *limitedResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (w *limitedResponseWriter) CloseNotify() <-chan bool {
+	if c, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return c.CloseNotify()
+	}
+	return make(<-chan bool)
+}
@@ -0,0 +1,127 @@
+package bandwidthlimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBandwidthLimit(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          dynamic.BandwidthLimit
+		requests        int
+		bodySize        int
+		expectedMinTime time.Duration
+		expectedWritten int
+		expectedTrimmed bool
+	}{
+		{
+			desc:            "within the burst",
+			config:          dynamic.BandwidthLimit{Rate: 1000, Burst: 500},
+			requests:        1,
+			bodySize:        400,
+			expectedWritten: 400,
+		},
+		{
+			desc:            "queued beyond the burst",
+			config:          dynamic.BandwidthLimit{Rate: 1000, Burst: 100},
+			requests:        1,
+			bodySize:        300,
+			expectedMinTime: 150 * time.Millisecond,
+			expectedWritten: 300,
+		},
+		{
+			desc:            "aggregate throughput",
+			config:          dynamic.BandwidthLimit{Rate: 1000, Burst: 100},
+			requests:        3,
+			bodySize:        100,
+			expectedMinTime: 150 * time.Millisecond,
+			expectedWritten: 100,
+		},
+		{
+			desc:            "trimmed beyond the max delay",
+			config:          dynamic.BandwidthLimit{Rate: 100, Burst: 100, MaxDelay: types.Duration(10 * time.Millisecond)},
+			requests:        1,
+			bodySize:        300,
+			expectedWritten: 100,
+			expectedTrimmed: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				n, err := rw.Write([]byte(strings.Repeat("a", test.bodySize)))
+				assert.Equal(t, test.expectedWritten, n)
+				if test.expectedTrimmed {
+					assert.Equal(t, errResponseTrimmed, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			})
+
+			handler, err := New(context.Background(), next, test.config, "test")
+			require.NoError(t, err)
+
+			start := time.Now()
+
+			var wg sync.WaitGroup
+			for i := 0; i < test.requests; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+					assert.Equal(t, test.expectedWritten, recorder.Body.Len())
+				}()
+			}
+			wg.Wait()
+
+			assert.GreaterOrEqual(t, int64(time.Since(start)), int64(test.expectedMinTime))
+		})
+	}
+}
+
+func TestBandwidthLimit_canceledRequest(t *testing.T) {
+	written := make(chan int, 1)
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n, _ := rw.Write([]byte(strings.Repeat("a", 300)))
+		written <- n
+	})
+
+	handler, err := New(context.Background(), next, dynamic.BandwidthLimit{Rate: 10, Burst: 100}, "test")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil).WithContext(ctx)
+
+	go handler.ServeHTTP(httptest.NewRecorder(), req)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case n := <-written:
+		assert.Equal(t, 100, n)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the write is still waiting for bandwidth")
+	}
+}
+
+func TestNew_invalidRate(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.BandwidthLimit{}, "test")
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+// Package botmanagement implements a middleware scoring the requests to detect the bots, and handling the detected ones.
+package botmanagement
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "BotManagement"
+)
+
+// Actions applied to the requests coming from bots.
+const (
+	actionAllow    = "allow"
+	actionBlock    = "block"
+	actionThrottle = "throttle"
+	actionTag      = "tag"
+)
+
+// botManagement is a middleware that scores the requests with heuristics, and applies an action on the ones coming from bots.
+type botManagement struct {
+	next              http.Handler
+	name              string
+	threshold         int
+	userAgents        []*regexp.Regexp
+	allowedUserAgents []*regexp.Regexp
+	action            string
+	header            string
+	throttleDelay     time.Duration
+	challenge         *challenge
+	strategy          ip.Strategy
+}
+
+// New builds a new BotManagement middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.BotManagement, name string) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	action := config.Action
+	switch action {
+	case "":
+		action = actionBlock
+	case actionAllow, actionBlock, actionThrottle, actionTag:
+	default:
+		return nil, fmt.Errorf("unknown action %q", action)
+	}
+
+	userAgents, err := compile(append([]string{defaultUserAgents}, config.UserAgents...))
+	if err != nil {
+		return nil, err
+	}
+
+	allowedUserAgents, err := compile(config.AllowedUserAgents)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := config.Threshold
+	if threshold <= 0 {
+		threshold = 50
+	}
+
+	header := config.Header
+	if header == "" {
+		header = "X-Bot-Score"
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	var chal *challenge
+	if config.Challenge != nil {
+		chal, err = newChallenge(*config.Challenge)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &botManagement{
+		next:              next,
+		name:              name,
+		threshold:         threshold,
+		userAgents:        userAgents,
+		allowedUserAgents: allowedUserAgents,
+		action:            action,
+		header:            header,
+		throttleDelay:     time.Duration(config.ThrottleDelay),
+		challenge:         chal,
+		strategy:          strategy,
+	}, nil
+}
+
+func compile(patterns []string) ([]*regexp.Regexp, error) {
+	var exps []*regexp.Regexp
+	for _, pattern := range patterns {
+		exp, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling regular expression %s: %v", pattern, err)
+		}
+		exps = append(exps, exp)
+	}
+	return exps, nil
+}
+
+func (b *botManagement) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return b.name, tracing.SpanKindNoneEnum
+}
+
+func (b *botManagement) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), b.name, typeName)
+	logger := log.FromContext(ctx)
+
+	clientIP := b.strategy.GetIP(req)
+
+	score := 0
+	if b.challenge == nil || !b.challenge.isSolved(req, clientIP) {
+		score = b.score(req)
+	}
+
+	// The score header is never trusted from the client, whatever the action.
+	req.Header.Del(b.header)
+	if b.action == actionTag {
+		req.Header.Set(b.header, strconv.Itoa(score))
+	}
+
+	if score < b.threshold {
+		b.next.ServeHTTP(rw, req)
+		return
+	}
+
+	logger.Debugf("Bot detected from %s with score %d: %+v", clientIP, score, req)
+
+	if b.challenge != nil && b.challenge.accepts(req) {
+		b.challenge.serve(ctx, rw, clientIP)
+		return
+	}
+
+	switch b.action {
+	case actionBlock:
+		logMessage := fmt.Sprintf("rejecting request %+v: bot score %d", req, score)
+		tracing.SetErrorWithEvent(req, logMessage)
+		reject(ctx, rw)
+		return
+
+	case actionThrottle:
+		timer := time.NewTimer(b.throttleDelay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	b.next.ServeHTTP(rw, req)
+}
+
+func reject(ctx context.Context, rw http.ResponseWriter) {
+	statusCode := http.StatusForbidden
+
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
@@ -0,0 +1,250 @@
+package botmanagement
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func browserRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64; rv:75.0) Gecko/20100101 Firefox/75.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	return req
+}
+
+func TestNewBotManagement(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.BotManagement{Action: "foo"}, "foo-bot")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.BotManagement{UserAgents: []string{"("}}, "foo-bot")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.BotManagement{}, "foo-bot")
+	assert.NoError(t, err)
+}
+
+func TestScore(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		request  func() *http.Request
+		expected int
+	}{
+		{
+			desc:     "browser",
+			request:  browserRequest,
+			expected: 0,
+		},
+		{
+			desc: "curl",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+				req.Header.Set("User-Agent", "curl/7.68.0")
+				req.Header.Set("Accept", "*/*")
+				return req
+			},
+			expected: weightBotUserAgent + weightNoAcceptLanguage + weightNoAcceptEncoding,
+		},
+		{
+			desc: "no headers",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+				req.Proto, req.ProtoMinor = "HTTP/1.0", 0
+				return req
+			},
+			expected: weightNoUserAgent + weightNoAccept + weightNoAcceptLanguage + weightNoAcceptEncoding + weightLegacyHTTPVersion,
+		},
+		{
+			desc: "custom bot user agent",
+			request: func() *http.Request {
+				req := browserRequest()
+				req.Header.Set("User-Agent", "Mozilla/5.0 FooScanner/1.0")
+				return req
+			},
+			expected: weightBotUserAgent,
+		},
+		{
+			desc: "allowed user agent",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+				req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)")
+				return req
+			},
+			expected: 0,
+		},
+	}
+
+	b := &botManagement{
+		userAgents:        []*regexp.Regexp{regexp.MustCompile(defaultUserAgents), regexp.MustCompile("FooScanner")},
+		allowedUserAgents: []*regexp.Regexp{regexp.MustCompile("Googlebot")},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, b.score(test.request()))
+		})
+	}
+}
+
+func TestBotManagementActions(t *testing.T) {
+	botRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+		req.Header.Set("User-Agent", "python-requests/2.23.0")
+		return req
+	}
+
+	testCases := []struct {
+		desc               string
+		config             dynamic.BotManagement
+		request            func() *http.Request
+		expectedStatusCode int
+		expectedScore      string
+	}{
+		{
+			desc:               "block a bot",
+			config:             dynamic.BotManagement{Action: "block"},
+			request:            botRequest,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "block ignores a browser",
+			config:             dynamic.BotManagement{Action: "block"},
+			request:            browserRequest,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "allow a bot",
+			config:             dynamic.BotManagement{Action: "allow"},
+			request:            botRequest,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "throttle a bot",
+			config:             dynamic.BotManagement{Action: "throttle", ThrottleDelay: types.Duration(time.Millisecond)},
+			request:            botRequest,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "tag a bot",
+			config:             dynamic.BotManagement{Action: "tag", Header: "X-Score"},
+			request:            botRequest,
+			expectedStatusCode: http.StatusOK,
+			expectedScore:      "160",
+		},
+		{
+			desc:   "tag a browser overrides the client header",
+			config: dynamic.BotManagement{Action: "tag", Header: "X-Score"},
+			request: func() *http.Request {
+				req := browserRequest()
+				req.Header.Set("X-Score", "-1000")
+				return req
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedScore:      "0",
+		},
+		{
+			desc:   "allow a browser strips the client header",
+			config: dynamic.BotManagement{Action: "allow", Header: "X-Score"},
+			request: func() *http.Request {
+				req := browserRequest()
+				req.Header.Set("X-Score", "-1000")
+				return req
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "higher threshold",
+			config:             dynamic.BotManagement{Action: "block", Threshold: 200},
+			request:            botRequest,
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var score string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				score = req.Header.Get("X-Score")
+			})
+
+			handler, err := New(context.Background(), next, test.config, "foo-bot")
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, test.request())
+
+			assert.Equal(t, test.expectedStatusCode, rw.Code)
+			assert.Equal(t, test.expectedScore, score)
+		})
+	}
+}
+
+func TestBotManagementChallenge(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	config := dynamic.BotManagement{
+		Action:    "block",
+		Challenge: &dynamic.BotChallenge{Secret: "secret", CookieName: "bot", MaxAge: types.Duration(time.Hour)},
+	}
+
+	handler, err := New(context.Background(), next, config, "foo-bot")
+	require.NoError(t, err)
+
+	// A headless browser gets the challenge.
+	req := browserRequest()
+	req.Header.Set("User-Agent", "Mozilla/5.0 HeadlessChrome/80.0.3987.0")
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rw.Header().Get("Content-Type"))
+	assert.Contains(t, rw.Body.String(), "document.cookie")
+
+	c := handler.(*botManagement).challenge
+	token := c.token("192.0.2.1")
+
+	// Once solved, the requests are forwarded.
+	req.AddCookie(&http.Cookie{Name: "bot", Value: token})
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	// The cookie is bound to the client IP.
+	req.RemoteAddr = "192.0.2.2:1234"
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	// The cookie expires.
+	c.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	req = browserRequest()
+	req.Header.Set("User-Agent", "Mozilla/5.0 HeadlessChrome/80.0.3987.0")
+	req.AddCookie(&http.Cookie{Name: "bot", Value: token})
+	assert.False(t, c.isSolved(req, "192.0.2.1"))
+
+	// Non-browser clients cannot solve the challenge, and are blocked.
+	req = httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+	req.Header.Set("User-Agent", "curl/7.68.0")
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+	assert.Equal(t, http.StatusText(http.StatusForbidden), rw.Body.String())
+}
@@ -0,0 +1,127 @@
+package botmanagement
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+var challengeTemplate = template.Must(template.New("challenge").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Checking your browser</title></head>
+<body>
+<noscript>Please enable JavaScript and cookies to continue.</noscript>
+<script>
+document.cookie = {{.Cookie}};
+location.reload();
+</script>
+</body>
+</html>
+`))
+
+// challenge is a JavaScript challenge: a page setting a signed cookie, bound to the client IP, and reloading itself.
+// Only the clients running JavaScript and keeping the cookies, i.e. browsers, solve it.
+type challenge struct {
+	secret     []byte
+	cookieName string
+	maxAge     time.Duration
+	now        func() time.Time
+}
+
+func newChallenge(config dynamic.BotChallenge) (*challenge, error) {
+	secret := []byte(config.Secret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+
+	cookieName := config.CookieName
+	if cookieName == "" {
+		cookieName = "_traefik_bot"
+	}
+
+	maxAge := time.Duration(config.MaxAge)
+	if maxAge <= 0 {
+		maxAge = time.Hour
+	}
+
+	return &challenge{
+		secret:     secret,
+		cookieName: cookieName,
+		maxAge:     maxAge,
+		now:        time.Now,
+	}, nil
+}
+
+// accepts reports whether the challenge can be served in response to the request, i.e. whether it comes from a would-be browser.
+func (c *challenge) accepts(req *http.Request) bool {
+	return (req.Method == http.MethodGet || req.Method == http.MethodHead) &&
+		strings.Contains(req.Header.Get("Accept"), "text/html")
+}
+
+// isSolved reports whether the request holds a valid challenge cookie for the client IP.
+func (c *challenge) isSolved(req *http.Request, clientIP string) bool {
+	cookie, err := req.Cookie(c.cookieName)
+	if err != nil {
+		return false
+	}
+
+	parts := strings.SplitN(cookie.Value, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	expiry, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || c.now().Unix() >= expiry {
+		return false
+	}
+
+	signature, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(signature, c.sign(clientIP, expiry))
+}
+
+func (c *challenge) token(clientIP string) string {
+	expiry := c.now().Add(c.maxAge).Unix()
+	return strconv.FormatInt(expiry, 10) + "." + hex.EncodeToString(c.sign(clientIP, expiry))
+}
+
+func (c *challenge) sign(clientIP string, expiry int64) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	_, _ = mac.Write([]byte(clientIP + "|" + strconv.FormatInt(expiry, 10)))
+	return mac.Sum(nil)
+}
+
+func (c *challenge) serve(ctx context.Context, rw http.ResponseWriter, clientIP string) {
+	cookie := &http.Cookie{
+		Name:     c.cookieName,
+		Value:    c.token(clientIP),
+		Path:     "/",
+		MaxAge:   int(c.maxAge.Seconds()),
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(http.StatusForbidden)
+
+	err := challengeTemplate.Execute(rw, struct{ Cookie string }{Cookie: cookie.String()})
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
@@ -0,0 +1,59 @@
+package botmanagement
+
+import (
+	"net/http"
+)
+
+// defaultUserAgents matches the user agents of the common crawlers, HTTP libraries, and headless browsers.
+const defaultUserAgents = `(?i)(bot|crawl|spider|scrap|curl|wget|python-requests|python-urllib|aiohttp|httpx|go-http-client|java/|okhttp|libwww-perl|headlesschrome|phantomjs|selenium|puppeteer|playwright)`
+
+// Weights of the heuristics in the score of a request.
+const (
+	weightBotUserAgent      = 100
+	weightNoUserAgent       = 60
+	weightNoAccept          = 20
+	weightNoAcceptLanguage  = 20
+	weightNoAcceptEncoding  = 20
+	weightLegacyHTTPVersion = 10
+)
+
+// score returns the likeliness of the request coming from a bot, based on its user agent and headers.
+// A request with a user agent matching one of the allowed ones always scores 0.
+func (b *botManagement) score(req *http.Request) int {
+	score := 0
+
+	userAgent := req.UserAgent()
+	if userAgent == "" {
+		score += weightNoUserAgent
+	} else {
+		for _, exp := range b.allowedUserAgents {
+			if exp.MatchString(userAgent) {
+				return 0
+			}
+		}
+
+		for _, exp := range b.userAgents {
+			if exp.MatchString(userAgent) {
+				score += weightBotUserAgent
+				break
+			}
+		}
+	}
+
+	// Browsers always send these headers.
+	if req.Header.Get("Accept") == "" {
+		score += weightNoAccept
+	}
+	if req.Header.Get("Accept-Language") == "" {
+		score += weightNoAcceptLanguage
+	}
+	if req.Header.Get("Accept-Encoding") == "" {
+		score += weightNoAcceptEncoding
+	}
+
+	if !req.ProtoAtLeast(1, 1) {
+		score += weightLegacyHTTPVersion
+	}
+
+	return score
+}
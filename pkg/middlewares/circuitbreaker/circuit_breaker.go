@@ -3,8 +3,10 @@ package circuitbreaker
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/gossip"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/tracing"
@@ -14,41 +16,71 @@ import (
 
 const (
 	typeName = "CircuitBreaker"
+
+	// fallbackDuration is how long a tripped circuit breaker serves the fallback.
+	fallbackDuration = 10 * time.Second
 )
 
 type circuitBreaker struct {
 	circuitBreaker *cbreaker.CircuitBreaker
+	fallback       http.Handler
 	name           string
+	// shared is the state shared with the other instances, whose circuit breakers trip those of all the instances.
+	shared *gossip.State
 }
 
 // New creates a new circuit breaker middleware.
-func New(ctx context.Context, next http.Handler, confCircuitBreaker dynamic.CircuitBreaker, name string) (http.Handler, error) {
+// When the state is shared with the other instances, the circuit breaker trips along with the ones of the other instances.
+func New(ctx context.Context, next http.Handler, confCircuitBreaker dynamic.CircuitBreaker, name string, shared *gossip.State) (http.Handler, error) {
 	expression := confCircuitBreaker.Expression
 
 	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
 	logger.Debug("Creating middleware")
 	logger.Debug("Setting up with expression: %s", expression)
 
-	oxyCircuitBreaker, err := cbreaker.New(next, expression, createCircuitBreakerOptions(expression))
+	fallback := createFallback(expression)
+
+	options := []cbreaker.CircuitBreakerOption{
+		cbreaker.Fallback(fallback),
+		cbreaker.FallbackDuration(fallbackDuration),
+	}
+	if shared != nil {
+		options = append(options, cbreaker.OnTripped(&sharedTrip{shared: shared, name: name}))
+	}
+
+	oxyCircuitBreaker, err := cbreaker.New(next, expression, options...)
 	if err != nil {
 		return nil, err
 	}
 	return &circuitBreaker{
 		circuitBreaker: oxyCircuitBreaker,
+		fallback:       fallback,
 		name:           name,
+		shared:         shared,
 	}, nil
 }
 
-// NewCircuitBreakerOptions returns a new CircuitBreakerOption
-func createCircuitBreakerOptions(expression string) cbreaker.CircuitBreakerOption {
-	return cbreaker.Fallback(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+// createFallback returns the handler of the requests blocked by the circuit breaker.
+func createFallback(expression string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		tracing.SetErrorWithEvent(req, "blocked by circuit-breaker (%q)", expression)
 		rw.WriteHeader(http.StatusServiceUnavailable)
 
 		if _, err := rw.Write([]byte(http.StatusText(http.StatusServiceUnavailable))); err != nil {
 			log.FromContext(req.Context()).Error(err)
 		}
-	}))
+	})
+}
+
+// sharedTrip trips the circuit breaker in the state shared with the other instances.
+type sharedTrip struct {
+	shared *gossip.State
+	name   string
+}
+
+func (s *sharedTrip) Exec() error {
+	s.shared.Trip(s.name, time.Now().Add(fallbackDuration))
+	return nil
 }
 
 func (c *circuitBreaker) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -56,5 +88,10 @@ func (c *circuitBreaker) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (c *circuitBreaker) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if c.shared != nil && c.shared.Tripped(c.name) {
+		c.fallback.ServeHTTP(rw, req)
+		return
+	}
+
 	c.circuitBreaker.ServeHTTP(rw, req)
 }
@@ -1,9 +1,12 @@
 package compress
 
 import (
+	"bufio"
 	"compress/gzip"
 	"context"
+	"fmt"
 	"mime"
+	"net"
 	"net/http"
 
 	"github.com/NYTimes/gziphandler"
@@ -52,7 +55,10 @@ func (c *compress) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		c.next.ServeHTTP(rw, req)
 	} else {
 		ctx := middlewares.GetLoggerCtx(req.Context(), c.name, typeName)
-		gzipHandler(ctx, c.next).ServeHTTP(rw, req)
+		next := http.HandlerFunc(func(gzw http.ResponseWriter, r *http.Request) {
+			c.next.ServeHTTP(&informationalWriter{ResponseWriter: gzw, rw: rw}, r)
+		})
+		gzipHandler(ctx, next).ServeHTTP(rw, req)
 	}
 }
 
@@ -71,6 +77,36 @@ func gzipHandler(ctx context.Context, h http.Handler) http.Handler {
 	return wrapper(h)
 }
 
+// informationalWriter sends the interim responses straight to the client,
+// as the gzip writer would otherwise take them for the status code of the final response.
+type informationalWriter struct {
+	http.ResponseWriter
+	rw http.ResponseWriter
+}
+
+func (w *informationalWriter) WriteHeader(code int) {
+	if middlewares.IsInformational(code) {
+		w.rw.WriteHeader(code)
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush sends any buffered data to the client.
+func (w *informationalWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (w *informationalWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+}
+
 func contains(values []string, val string) bool {
 	for _, v := range values {
 		if v == val {
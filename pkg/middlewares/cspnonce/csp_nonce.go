@@ -0,0 +1,221 @@
+package cspnonce
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName          = "CSPNonce"
+	defaultHeaderName = "X-CSP-Nonce"
+	noncePlaceholder  = "{nonce}"
+)
+
+// cspNonce is a middleware generating a nonce for each request,
+// which is added to the Content-Security-Policy of the response, forwarded to the backend,
+// and optionally added to the marked script tags of the HTML responses.
+type cspNonce struct {
+	next          http.Handler
+	name          string
+	policy        string
+	policyHeader  string
+	headerName    string
+	injectScripts bool
+}
+
+// New creates a new CSP nonce middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.CSPNonce, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if !strings.Contains(config.Policy, noncePlaceholder) {
+		return nil, fmt.Errorf("the policy must contain the %s placeholder", noncePlaceholder)
+	}
+
+	policyHeader := "Content-Security-Policy"
+	if config.ReportOnly {
+		policyHeader = "Content-Security-Policy-Report-Only"
+	}
+
+	headerName := config.HeaderName
+	if headerName == "" {
+		headerName = defaultHeaderName
+	}
+
+	return &cspNonce{
+		next:          next,
+		name:          name,
+		policy:        config.Policy,
+		policyHeader:  policyHeader,
+		headerName:    headerName,
+		injectScripts: config.InjectScripts,
+	}, nil
+}
+
+func (c *cspNonce) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *cspNonce) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	nonce, err := generateNonce()
+	if err != nil {
+		log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName)).Errorf("Error while generating the nonce: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	req.Header.Set(c.headerName, nonce)
+
+	if c.injectScripts {
+		// The responses are rewritten, so they must not be compressed by the backend.
+		req.Header.Del("Accept-Encoding")
+	}
+
+	nrw := &responseWriter{
+		ResponseWriter: rw,
+		policyHeader:   c.policyHeader,
+		policy:         strings.ReplaceAll(c.policy, noncePlaceholder, nonce),
+		nonce:          nonce,
+		injectScripts:  c.injectScripts,
+	}
+
+	c.next.ServeHTTP(nrw, req)
+
+	if err := nrw.close(); err != nil {
+		log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName)).Debugf("Error while writing the response: %v", err)
+	}
+}
+
+// generateNonce returns 128 random bits, encoded in base64 as expected in the CSP nonce sources.
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+
+	policyHeader  string
+	policy        string
+	nonce         string
+	injectScripts bool
+
+	wroteHeader bool
+	hijacked    bool
+	injector    *injector
+}
+
+func (r *responseWriter) WriteHeader(code int) {
+	if middlewares.IsInformational(code) {
+		r.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+
+	header := r.ResponseWriter.Header()
+	header.Set(r.policyHeader, r.policy)
+
+	if r.injectScripts && isHTML(header) && !isEncoded(header) && bodyAllowed(code) {
+		r.injector = newInjector(r.nonce)
+		header.Del("Content-Length")
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseWriter) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if r.injector == nil {
+		return r.ResponseWriter.Write(p)
+	}
+
+	if _, err := r.ResponseWriter.Write(r.injector.inject(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Flush sends any buffered data to the client,
+// except the end of the data which could be the start of a script tag.
+func (r *responseWriter) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+
+	r.hijacked = true
+
+	return hijacker.Hijack()
+}
+
+// close writes the headers if the backend did not write anything, and the end of the data kept by the injector.
+func (r *responseWriter) close() error {
+	if !r.wroteHeader && !r.hijacked {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if r.injector == nil {
+		return nil
+	}
+
+	pending := r.injector.flush()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	_, err := r.ResponseWriter.Write(pending)
+	return err
+}
+
+func isHTML(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+func isEncoded(header http.Header) bool {
+	encoding := header.Get("Content-Encoding")
+	return encoding != "" && !strings.EqualFold(encoding, "identity")
+}
+
+func bodyAllowed(code int) bool {
+	return code != http.StatusNoContent && code != http.StatusNotModified
+}
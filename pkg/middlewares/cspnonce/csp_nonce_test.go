@@ -0,0 +1,148 @@
+package cspnonce
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCSPNonce(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.CSPNonce{Policy: "script-src 'self'"}, "foo-csp-nonce")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.CSPNonce{Policy: "script-src 'nonce-{nonce}'"}, "foo-csp-nonce")
+	assert.NoError(t, err)
+}
+
+func TestCSPNonce(t *testing.T) {
+	const page = `<html><head><script src="/app.js" data-csp-nonce></script></head><body><script data-csp-nonce>foo()</script><script>bar()</script></body></html>`
+
+	testCases := []struct {
+		desc           string
+		config         dynamic.CSPNonce
+		contentType    string
+		contentEncode  string
+		expectedHeader string
+		expectedBody   string
+	}{
+		{
+			desc:           "policy only",
+			config:         dynamic.CSPNonce{Policy: "script-src 'nonce-{nonce}'"},
+			contentType:    "text/html; charset=utf-8",
+			expectedHeader: "Content-Security-Policy",
+			expectedBody:   page,
+		},
+		{
+			desc:           "report only",
+			config:         dynamic.CSPNonce{Policy: "script-src 'nonce-{nonce}'", ReportOnly: true},
+			contentType:    "text/html; charset=utf-8",
+			expectedHeader: "Content-Security-Policy-Report-Only",
+			expectedBody:   page,
+		},
+		{
+			desc:           "inject scripts",
+			config:         dynamic.CSPNonce{Policy: "script-src 'nonce-{nonce}'", InjectScripts: true},
+			contentType:    "text/html; charset=utf-8",
+			expectedHeader: "Content-Security-Policy",
+			expectedBody:   `<html><head><script src="/app.js" nonce="{nonce}"></script></head><body><script nonce="{nonce}">foo()</script><script>bar()</script></body></html>`,
+		},
+		{
+			desc:           "inject scripts, not HTML",
+			config:         dynamic.CSPNonce{Policy: "script-src 'nonce-{nonce}'", InjectScripts: true},
+			contentType:    "text/plain",
+			expectedHeader: "Content-Security-Policy",
+			expectedBody:   page,
+		},
+		{
+			desc:           "inject scripts, encoded",
+			config:         dynamic.CSPNonce{Policy: "script-src 'nonce-{nonce}'", InjectScripts: true},
+			contentType:    "text/html",
+			contentEncode:  "br",
+			expectedHeader: "Content-Security-Policy",
+			expectedBody:   page,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var nonce string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				nonce = req.Header.Get("X-CSP-Nonce")
+				if test.config.InjectScripts {
+					assert.Empty(t, req.Header.Get("Accept-Encoding"))
+				}
+
+				rw.Header().Set("Content-Type", test.contentType)
+				if test.contentEncode != "" {
+					rw.Header().Set("Content-Encoding", test.contentEncode)
+				}
+				rw.Header().Set("Content-Length", "42")
+				rw.WriteHeader(http.StatusOK)
+
+				// The page is written in chunks, to exercise the tags split across writes.
+				for i := 0; i < len(page); i += 10 {
+					end := i + 10
+					if end > len(page) {
+						end = len(page)
+					}
+					_, err := rw.Write([]byte(page[i:end]))
+					require.NoError(t, err)
+				}
+			})
+
+			handler, err := New(context.Background(), next, test.config, "foo-csp-nonce")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo.com", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			req.Header.Set("X-CSP-Nonce", "forged")
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, req)
+
+			require.Regexp(t, regexp.MustCompile(`^[A-Za-z0-9+/]{22}==$`), nonce)
+
+			assert.Equal(t, "script-src 'nonce-"+nonce+"'", recorder.Header().Get(test.expectedHeader))
+
+			body, err := ioutil.ReadAll(recorder.Body)
+			require.NoError(t, err)
+			assert.Equal(t, strings.ReplaceAll(test.expectedBody, "{nonce}", nonce), string(body))
+
+			if test.expectedBody != page {
+				assert.Empty(t, recorder.Header().Get("Content-Length"))
+			}
+		})
+	}
+}
+
+func TestCSPNonce_uniquePerRequest(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, dynamic.CSPNonce{Policy: "script-src 'nonce-{nonce}'"}, "foo-csp-nonce")
+	require.NoError(t, err)
+
+	policies := make(map[string]struct{})
+	for i := 0; i < 10; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com", nil))
+
+		policy := recorder.Header().Get("Content-Security-Policy")
+		require.NotEmpty(t, policy)
+		policies[policy] = struct{}{}
+	}
+
+	assert.Len(t, policies, 10)
+}
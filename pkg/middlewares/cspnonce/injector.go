@@ -0,0 +1,176 @@
+package cspnonce
+
+import (
+	"bytes"
+	"strings"
+)
+
+const (
+	scriptTag = "<script"
+	// placeholderAttribute marks the script tags the nonce is added to.
+	placeholderAttribute = "data-csp-nonce"
+	// maxTagLen is the length beyond which a script tag is left untouched.
+	maxTagLen = 4096
+)
+
+// injector adds a nonce attribute to the script tags of a streamed HTML document carrying the placeholder attribute.
+// The other script tags, e.g. the ones injected by a cross-site scripting attack, are left untouched.
+type injector struct {
+	attribute []byte
+	// pending is the end of the previous data, which could be the start of a script tag.
+	pending []byte
+}
+
+func newInjector(nonce string) *injector {
+	return &injector{attribute: []byte(`nonce="` + nonce + `"`)}
+}
+
+// inject returns the data which can be written, with the placeholder attribute of the script tags replaced with the nonce attribute,
+// and keeps the end of the data which could be the start of a script tag until the next call.
+func (i *injector) inject(p []byte) []byte {
+	data := p
+	if len(i.pending) > 0 {
+		data = append(i.pending, p...)
+		i.pending = nil
+	}
+
+	out := make([]byte, 0, len(data)+len(i.attribute))
+
+	for {
+		idx := bytes.IndexByte(data, '<')
+		if idx < 0 {
+			return append(out, data...)
+		}
+
+		out = append(out, data[:idx]...)
+		data = data[idx:]
+
+		// The tag name is complete when followed by a character which ends it.
+		if len(data) <= len(scriptTag) {
+			if strings.EqualFold(scriptTag[:len(data)], string(data)) {
+				i.pending = append([]byte(nil), data...)
+				return out
+			}
+			return append(out, data...)
+		}
+
+		if !strings.EqualFold(string(data[:len(scriptTag)]), scriptTag) || !isTagNameEnd(data[len(scriptTag)]) {
+			out = append(out, '<')
+			data = data[1:]
+			continue
+		}
+
+		end := tagEnd(data)
+		if end < 0 {
+			if len(data) < maxTagLen {
+				i.pending = append([]byte(nil), data...)
+				return out
+			}
+
+			out = append(out, data[:len(scriptTag)]...)
+			data = data[len(scriptTag):]
+			continue
+		}
+
+		tag := data[:end]
+		if start, stop, ok := findPlaceholder(tag); ok {
+			out = append(out, tag[:start]...)
+			out = append(out, i.attribute...)
+			out = append(out, tag[stop:]...)
+		} else {
+			out = append(out, tag...)
+		}
+		data = data[end:]
+	}
+}
+
+// flush returns the data kept by the injector.
+func (i *injector) flush() []byte {
+	pending := i.pending
+	i.pending = nil
+	return pending
+}
+
+// tagEnd returns the index following the end of the tag starting the data, or -1 if the tag is incomplete.
+func tagEnd(data []byte) int {
+	var quote byte
+	for idx := len(scriptTag); idx < len(data); idx++ {
+		c := data[idx]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return idx + 1
+		}
+	}
+	return -1
+}
+
+// findPlaceholder returns the bounds of the placeholder attribute, with its value if any, in a complete script tag.
+func findPlaceholder(tag []byte) (int, int, bool) {
+	idx := len(scriptTag)
+	for idx < len(tag) {
+		c := tag[idx]
+		if isTagNameEnd(c) {
+			idx++
+			continue
+		}
+
+		start := idx
+		for idx < len(tag) && !isTagNameEnd(tag[idx]) && tag[idx] != '=' {
+			idx++
+		}
+		name := string(tag[start:idx])
+
+		if idx < len(tag) && tag[idx] == '=' {
+			idx = skipValue(tag, idx+1)
+		}
+
+		if strings.EqualFold(name, placeholderAttribute) {
+			return start, idx, true
+		}
+	}
+	return 0, 0, false
+}
+
+// skipValue returns the index following the attribute value starting at the given index.
+func skipValue(tag []byte, idx int) int {
+	if idx >= len(tag) {
+		return idx
+	}
+
+	if quote := tag[idx]; quote == '"' || quote == '\'' {
+		end := bytes.IndexByte(tag[idx+1:], quote)
+		if end < 0 {
+			return len(tag)
+		}
+		return idx + end + 2
+	}
+
+	for idx < len(tag) && !isSpace(tag[idx]) && tag[idx] != '>' {
+		idx++
+	}
+	return idx
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f':
+		return true
+	default:
+		return false
+	}
+}
+
+func isTagNameEnd(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f', '/', '>':
+		return true
+	default:
+		return false
+	}
+}
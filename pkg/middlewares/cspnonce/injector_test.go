@@ -0,0 +1,89 @@
+package cspnonce
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjector(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		chunks   []string
+		expected string
+	}{
+		{
+			desc:     "no script",
+			chunks:   []string{"<html><body><p>foo</p></body></html>"},
+			expected: "<html><body><p>foo</p></body></html>",
+		},
+		{
+			desc:     "scripts with the placeholder",
+			chunks:   []string{`<script data-csp-nonce>foo()</script><SCRIPT src="/app.js" DATA-CSP-NONCE></SCRIPT><script data-csp-nonce/>`},
+			expected: `<script nonce="bar">foo()</script><SCRIPT src="/app.js" nonce="bar"></SCRIPT><script nonce="bar"/>`,
+		},
+		{
+			desc:     "placeholder with a value",
+			chunks:   []string{`<script data-csp-nonce="" src=/app.js></script><script data-csp-nonce=x>`},
+			expected: `<script nonce="bar" src=/app.js></script><script nonce="bar">`,
+		},
+		{
+			desc:     "scripts without the placeholder",
+			chunks:   []string{`<script>foo()</script><script src="/app.js" data-csp="nonce"></script><script data-csp-nonces>`},
+			expected: `<script>foo()</script><script src="/app.js" data-csp="nonce"></script><script data-csp-nonces>`,
+		},
+		{
+			desc:     "placeholder in an attribute value",
+			chunks:   []string{`<script title="> data-csp-nonce" src="/app.js"></script>`},
+			expected: `<script title="> data-csp-nonce" src="/app.js"></script>`,
+		},
+		{
+			desc:     "other tags starting like script",
+			chunks:   []string{`<scripts data-csp-nonce><scripting data-csp-nonce>`},
+			expected: `<scripts data-csp-nonce><scripting data-csp-nonce>`,
+		},
+		{
+			desc:     "tag split across chunks",
+			chunks:   []string{`<p>foo</p><scr`, `ipt`, ` src="/app.js" data-csp`, `-nonce></script>`},
+			expected: `<p>foo</p><script src="/app.js" nonce="bar"></script>`,
+		},
+		{
+			desc:     "tag name split before its end",
+			chunks:   []string{`<script`, ` data-csp-nonce>foo()</script>`},
+			expected: `<script nonce="bar">foo()</script>`,
+		},
+		{
+			desc:     "partial tag at the end of the document",
+			chunks:   []string{`<p>foo</p><script data-csp`},
+			expected: `<p>foo</p><script data-csp`,
+		},
+		{
+			desc:     "tag too long",
+			chunks:   []string{`<script data-csp-nonce title="` + strings.Repeat("a", maxTagLen), `">`},
+			expected: `<script data-csp-nonce title="` + strings.Repeat("a", maxTagLen) + `">`,
+		},
+		{
+			desc:     "lone opening bracket",
+			chunks:   []string{`1 <`, ` 2 <b>`},
+			expected: `1 < 2 <b>`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			i := newInjector("bar")
+
+			var out []byte
+			for _, chunk := range test.chunks {
+				out = append(out, i.inject([]byte(chunk))...)
+			}
+			out = append(out, i.flush()...)
+
+			assert.Equal(t, test.expected, string(out))
+		})
+	}
+}
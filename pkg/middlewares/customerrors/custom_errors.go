@@ -233,6 +233,11 @@ func (cc *codeCatcher) WriteHeader(code int) {
 		return
 	}
 
+	if middlewares.IsInformational(code) {
+		middlewares.WriteInformational(cc.responseWriter, code, cc.Header())
+		return
+	}
+
 	cc.code = code
 	for _, block := range cc.httpCodeRanges {
 		if cc.code >= block[0] && cc.code <= block[1] {
@@ -345,7 +350,11 @@ func (r *responseRecorderWithoutCloseNotify) Write(buf []byte) (int, error) {
 }
 
 // WriteHeader sets rw.Code.
+// Interim responses from the error page service are dropped.
 func (r *responseRecorderWithoutCloseNotify) WriteHeader(code int) {
+	if middlewares.IsInformational(code) {
+		return
+	}
 	r.Code = code
 }
 
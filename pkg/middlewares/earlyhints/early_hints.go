@@ -0,0 +1,50 @@
+package earlyhints
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "EarlyHints"
+)
+
+// earlyHints is a middleware that sends a 103 Early Hints response,
+// built from the configured links, before forwarding the request.
+type earlyHints struct {
+	next  http.Handler
+	links []string
+	name  string
+}
+
+// New creates a new early hints middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.EarlyHints, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if len(config.Links) == 0 {
+		return nil, errors.New("links cannot be empty")
+	}
+
+	return &earlyHints{
+		next:  next,
+		links: config.Links,
+		name:  name,
+	}, nil
+}
+
+func (e *earlyHints) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return e.name, tracing.SpanKindNoneEnum
+}
+
+func (e *earlyHints) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	middlewares.SendInformational(rw, req, http.StatusEarlyHints, http.Header{"Link": e.links})
+
+	e.next.ServeHTTP(rw, req)
+}
@@ -0,0 +1,67 @@
+package earlyhints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEarlyHints(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.EarlyHints{}, "foo-early-hints")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.EarlyHints{Links: []string{"</style.css>; rel=preload; as=style"}}, "foo-early-hints")
+	assert.NoError(t, err)
+}
+
+func TestEarlyHints(t *testing.T) {
+	links := []string{"</style.css>; rel=preload; as=style", "</app.js>; rel=preload; as=script"}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Foo", "bar")
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	handler, err := New(context.Background(), next, dynamic.EarlyHints{Links: links}, "foo-early-hints")
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.ConnContext = middlewares.WithConn
+	server.Start()
+	defer server.Close()
+
+	var codes []int
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			codes = append(codes, code)
+			hints = append(hints, header["Link"]...)
+			return nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, []int{http.StatusEarlyHints}, codes)
+	assert.Equal(t, links, hints)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "bar", resp.Header.Get("X-Foo"))
+	assert.Empty(t, resp.Header.Values("Link"))
+}
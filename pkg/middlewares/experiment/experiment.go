@@ -0,0 +1,209 @@
+package experiment
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName                 = "Experiment"
+	defaultCookieName        = "traefik_experiment_id"
+	defaultVariantHeaderName = "X-Experiment-Variant"
+)
+
+// cookieMaxAge is how long the generated user IDs are kept by the clients,
+// so that the users keep seeing the same variants.
+const cookieMaxAge = 365 * 24 * time.Hour
+
+type variant struct {
+	name string
+	// upperBound is the cumulated weight of the variant and of the previous ones.
+	upperBound uint64
+}
+
+// experiment is a middleware assigning the users to the variants of an A/B testing experiment,
+// by hashing their ID, so that a user is always assigned to the same variant.
+type experiment struct {
+	next              http.Handler
+	name              string
+	experimentName    string
+	variants          []variant
+	totalWeight       uint64
+	headerName        string
+	cookieName        string
+	variantHeaderName string
+	exposeVariant     bool
+	exposures         gokitmetrics.Counter
+}
+
+// New creates a new experiment middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Experiment, name string, metricsRegistry metrics.Registry) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if len(config.Variants) == 0 {
+		return nil, errors.New("variants cannot be empty")
+	}
+
+	var variants []variant
+	var totalWeight uint64
+	seen := make(map[string]struct{})
+	for _, v := range config.Variants {
+		if v.Name == "" {
+			return nil, errors.New("variant name cannot be empty")
+		}
+
+		if _, ok := seen[v.Name]; ok {
+			return nil, fmt.Errorf("duplicate variant: %s", v.Name)
+		}
+		seen[v.Name] = struct{}{}
+
+		weight := 1
+		if v.Weight != nil {
+			weight = *v.Weight
+		}
+
+		if weight < 0 {
+			return nil, fmt.Errorf("negative weight for variant %s", v.Name)
+		}
+
+		// A variant without weight is disabled.
+		if weight == 0 {
+			continue
+		}
+
+		totalWeight += uint64(weight)
+		variants = append(variants, variant{name: v.Name, upperBound: totalWeight})
+	}
+
+	if totalWeight == 0 {
+		return nil, errors.New("at least one variant must have a positive weight")
+	}
+
+	experimentName := config.Name
+	if experimentName == "" {
+		experimentName = name
+	}
+
+	cookieName := config.CookieName
+	if cookieName == "" {
+		cookieName = defaultCookieName
+	}
+
+	variantHeaderName := config.VariantHeaderName
+	if variantHeaderName == "" {
+		variantHeaderName = defaultVariantHeaderName
+	}
+
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &experiment{
+		next:              next,
+		name:              name,
+		experimentName:    experimentName,
+		variants:          variants,
+		totalWeight:       totalWeight,
+		headerName:        config.HeaderName,
+		cookieName:        cookieName,
+		variantHeaderName: variantHeaderName,
+		exposeVariant:     config.ExposeVariant,
+		exposures:         metricsRegistry.ExperimentExposuresCounter(),
+	}, nil
+}
+
+func (e *experiment) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return e.name, tracing.SpanKindNoneEnum
+}
+
+func (e *experiment) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	userID, err := e.userID(rw, req)
+	if err != nil {
+		log.FromContext(middlewares.GetLoggerCtx(req.Context(), e.name, typeName)).Errorf("Error while generating the user ID: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	variantName := e.assign(userID)
+
+	req.Header.Set(e.variantHeaderName, variantName)
+	if e.exposeVariant {
+		rw.Header().Set(e.variantHeaderName, variantName)
+	}
+
+	e.exposures.With("experiment", e.experimentName, "variant", variantName).Add(1)
+
+	e.next.ServeHTTP(rw, req)
+}
+
+// userID returns the ID of the user from the header, or from the cookie,
+// which is set with a generated ID if the request has none.
+func (e *experiment) userID(rw http.ResponseWriter, req *http.Request) (string, error) {
+	if e.headerName != "" {
+		if userID := req.Header.Get(e.headerName); userID != "" {
+			return userID, nil
+		}
+	}
+
+	if cookie, err := req.Cookie(e.cookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, nil
+	}
+
+	userID, err := generateUserID()
+	if err != nil {
+		return "", err
+	}
+
+	http.SetCookie(rw, &http.Cookie{
+		Name:     e.cookieName,
+		Value:    userID,
+		Path:     "/",
+		MaxAge:   int(cookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   req.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return userID, nil
+}
+
+// assign returns the variant of the user, from the hash of the experiment name and of the user ID,
+// so that the users are bucketed independently in each experiment.
+// A cryptographic hash is used, as the low bits of the non-cryptographic ones are poorly distributed.
+func (e *experiment) assign(userID string) string {
+	sum := sha256.Sum256([]byte(e.experimentName + "\x00" + userID))
+
+	bucket := binary.BigEndian.Uint64(sum[:8]) % e.totalWeight
+	for _, v := range e.variants {
+		if bucket < v.upperBound {
+			return v.name
+		}
+	}
+
+	// Never reached, as the bucket is lower than the total weight.
+	return e.variants[len(e.variants)-1].name
+}
+
+func generateUserID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,205 @@
+package experiment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type exposuresRegistry struct {
+	metrics.Registry
+	exposures *testhelpers.CollectingCounter
+}
+
+func (r *exposuresRegistry) ExperimentExposuresCounter() gokitmetrics.Counter {
+	return r.exposures
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestNewExperiment(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.Experiment
+		expectedError bool
+	}{
+		{
+			desc:          "no variants",
+			config:        dynamic.Experiment{},
+			expectedError: true,
+		},
+		{
+			desc:          "variant without name",
+			config:        dynamic.Experiment{Variants: []dynamic.ExperimentVariant{{Weight: intPtr(1)}}},
+			expectedError: true,
+		},
+		{
+			desc:          "duplicate variants",
+			config:        dynamic.Experiment{Variants: []dynamic.ExperimentVariant{{Name: "a"}, {Name: "a"}}},
+			expectedError: true,
+		},
+		{
+			desc:          "negative weight",
+			config:        dynamic.Experiment{Variants: []dynamic.ExperimentVariant{{Name: "a", Weight: intPtr(-1)}, {Name: "b"}}},
+			expectedError: true,
+		},
+		{
+			desc:          "all variants disabled",
+			config:        dynamic.Experiment{Variants: []dynamic.ExperimentVariant{{Name: "a", Weight: intPtr(0)}}},
+			expectedError: true,
+		},
+		{
+			desc:   "valid",
+			config: dynamic.Experiment{Variants: []dynamic.ExperimentVariant{{Name: "a"}, {Name: "b", Weight: intPtr(0)}}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := New(context.Background(), next, test.config, "foo-experiment", nil)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExperiment_userIDHeader(t *testing.T) {
+	registry := &exposuresRegistry{Registry: metrics.NewVoidRegistry(), exposures: &testhelpers.CollectingCounter{}}
+
+	var variants []string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		variants = append(variants, req.Header.Get("X-Experiment-Variant"))
+	})
+
+	config := dynamic.Experiment{
+		Name:          "checkout",
+		Variants:      []dynamic.ExperimentVariant{{Name: "a"}, {Name: "b"}},
+		HeaderName:    "X-User-ID",
+		ExposeVariant: true,
+	}
+
+	handler, err := New(context.Background(), next, config, "foo-experiment", registry)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "http://foo.com", nil)
+		req.Header.Set("X-User-ID", "user-42")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, variants[0], recorder.Header().Get("X-Experiment-Variant"))
+		assert.Empty(t, recorder.Header().Get("Set-Cookie"))
+	}
+
+	require.Len(t, variants, 3)
+	assert.Contains(t, []string{"a", "b"}, variants[0])
+	assert.Equal(t, variants[0], variants[1])
+	assert.Equal(t, variants[0], variants[2])
+
+	assert.Equal(t, float64(3), registry.exposures.CounterValue)
+	assert.Equal(t, []string{"experiment", "checkout", "variant", variants[0]}, registry.exposures.LastLabelValues)
+}
+
+func TestExperiment_userIDCookie(t *testing.T) {
+	var variant string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		variant = req.Header.Get("X-Experiment-Variant")
+	})
+
+	config := dynamic.Experiment{Variants: []dynamic.ExperimentVariant{{Name: "a"}, {Name: "b"}, {Name: "c"}}}
+
+	handler, err := New(context.Background(), next, config, "foo-experiment", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com", nil))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "traefik_experiment_id", cookies[0].Name)
+	assert.NotEmpty(t, cookies[0].Value)
+	assert.Empty(t, recorder.Header().Get("X-Experiment-Variant"))
+
+	firstVariant := variant
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "http://foo.com", nil)
+		req.AddCookie(cookies[0])
+		recorder = httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, firstVariant, variant)
+		assert.Empty(t, recorder.Result().Cookies())
+	}
+}
+
+func TestExperiment_weights(t *testing.T) {
+	counts := make(map[string]int)
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		counts[req.Header.Get("X-Experiment-Variant")]++
+	})
+
+	config := dynamic.Experiment{
+		Variants: []dynamic.ExperimentVariant{
+			{Name: "control", Weight: intPtr(1)},
+			{Name: "treatment", Weight: intPtr(3)},
+			{Name: "disabled", Weight: intPtr(0)},
+		},
+		HeaderName: "X-User-ID",
+	}
+
+	handler, err := New(context.Background(), next, config, "foo-experiment", nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 10000; i++ {
+		req := httptest.NewRequest(http.MethodGet, "http://foo.com", nil)
+		req.Header.Set("X-User-ID", "user-"+strconv.Itoa(i))
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Zero(t, counts["disabled"])
+	assert.InDelta(t, 2500, counts["control"], 250)
+	assert.InDelta(t, 7500, counts["treatment"], 250)
+}
+
+func TestExperiment_independentBucketing(t *testing.T) {
+	variants := []dynamic.ExperimentVariant{{Name: "a"}, {Name: "b"}}
+
+	first, err := New(context.Background(), nil, dynamic.Experiment{Name: "first", Variants: variants}, "first", nil)
+	require.NoError(t, err)
+	second, err := New(context.Background(), nil, dynamic.Experiment{Name: "second", Variants: variants}, "second", nil)
+	require.NoError(t, err)
+
+	var same int
+	for i := 0; i < 1000; i++ {
+		userID := "user-" + strconv.Itoa(i)
+		if first.(*experiment).assign(userID) == second.(*experiment).assign(userID) {
+			same++
+		}
+	}
+
+	// The users are assigned to the same variant in both experiments only by chance.
+	assert.InDelta(t, 500, same, 100)
+}
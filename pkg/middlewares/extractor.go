@@ -8,6 +8,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares/apikey"
 	"github.com/vulcand/oxy/utils"
 )
 
@@ -25,11 +26,15 @@ func GetSourceExtractor(ctx context.Context, sourceMatcher *dynamic.SourceCriter
 		if sourceMatcher.RequestHeaderName != "" && sourceMatcher.RequestHost {
 			return nil, errors.New("requestHost and RequestHeaderName are mutually exclusive")
 		}
+		if sourceMatcher.APIKey != "" && (sourceMatcher.IPStrategy != nil || sourceMatcher.RequestHeaderName != "" || sourceMatcher.RequestHost) {
+			return nil, errors.New("apiKey is mutually exclusive with IPStrategy, RequestHeaderName, and RequestHost")
+		}
 	}
 
 	if sourceMatcher == nil ||
 		sourceMatcher.IPStrategy == nil &&
-			sourceMatcher.RequestHeaderName == "" && !sourceMatcher.RequestHost {
+			sourceMatcher.RequestHeaderName == "" && !sourceMatcher.RequestHost &&
+			sourceMatcher.APIKey == "" {
 		sourceMatcher = &dynamic.SourceCriterion{
 			IPStrategy: &dynamic.IPStrategy{},
 		}
@@ -58,5 +63,23 @@ func GetSourceExtractor(ctx context.Context, sourceMatcher *dynamic.SourceCriter
 		return utils.NewExtractor("request.host")
 	}
 
+	if sourceMatcher.APIKey != "" {
+		field := sourceMatcher.APIKey
+		if _, err := (&apikey.Key{}).Field(field); err != nil {
+			return nil, err
+		}
+
+		logger.Debug("Using APIKey")
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			key, ok := apikey.FromContext(req.Context())
+			if !ok {
+				return "", 0, errors.New("no API key authenticated by an APIKey middleware")
+			}
+
+			source, err := key.Field(field)
+			return source, 1, err
+		}), nil
+	}
+
 	return nil, errors.New("no SourceCriterion criterion defined")
 }
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares/apikey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSourceExtractor_apiKey(t *testing.T) {
+	extractor, err := GetSourceExtractor(context.Background(), &dynamic.SourceCriterion{APIKey: "tenant"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+
+	_, _, err = extractor.Extract(req)
+	assert.Error(t, err)
+
+	req = req.WithContext(apikey.WithKey(req.Context(), &apikey.Key{Value: "secret", Tenant: "acme"}))
+
+	source, amount, err := extractor.Extract(req)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", source)
+	assert.Equal(t, int64(1), amount)
+
+	_, err = GetSourceExtractor(context.Background(), &dynamic.SourceCriterion{APIKey: "foo"})
+	assert.Error(t, err)
+
+	_, err = GetSourceExtractor(context.Background(), &dynamic.SourceCriterion{APIKey: "tenant", RequestHost: true})
+	assert.Error(t, err)
+}
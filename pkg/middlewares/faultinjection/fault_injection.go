@@ -0,0 +1,157 @@
+package faultinjection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "FaultInjection"
+)
+
+// faultInjection is a middleware delaying, aborting, or resetting a share of the requests,
+// to test the resilience of the clients and of the other services.
+type faultInjection struct {
+	next http.Handler
+	name string
+
+	delay           time.Duration
+	delayPercentage float64
+
+	abortStatusCode int
+	abortPercentage float64
+
+	resetPercentage float64
+
+	// random returns a number in [0.0,1.0), and is replaced in the tests.
+	random func() float64
+}
+
+// New creates a new fault injection middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.FaultInjection, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if config.Delay == nil && config.Abort == nil && config.Reset == nil {
+		return nil, errors.New("at least one of delay, abort, or reset must be configured")
+	}
+
+	f := &faultInjection{
+		next:   next,
+		name:   name,
+		random: rand.Float64,
+	}
+
+	if config.Delay != nil {
+		if config.Delay.Duration <= 0 {
+			return nil, fmt.Errorf("invalid delay duration: %s", time.Duration(config.Delay.Duration))
+		}
+
+		if err := checkPercentage(config.Delay.Percentage); err != nil {
+			return nil, fmt.Errorf("invalid delay: %w", err)
+		}
+
+		f.delay = time.Duration(config.Delay.Duration)
+		f.delayPercentage = config.Delay.Percentage
+	}
+
+	if config.Abort != nil {
+		if config.Abort.StatusCode < 400 || config.Abort.StatusCode > 599 {
+			return nil, fmt.Errorf("invalid abort status code: %d, must be between 400 and 599", config.Abort.StatusCode)
+		}
+
+		if err := checkPercentage(config.Abort.Percentage); err != nil {
+			return nil, fmt.Errorf("invalid abort: %w", err)
+		}
+
+		f.abortStatusCode = config.Abort.StatusCode
+		f.abortPercentage = config.Abort.Percentage
+	}
+
+	if config.Reset != nil {
+		if err := checkPercentage(config.Reset.Percentage); err != nil {
+			return nil, fmt.Errorf("invalid reset: %w", err)
+		}
+
+		f.resetPercentage = config.Reset.Percentage
+	}
+
+	return f, nil
+}
+
+func checkPercentage(percentage float64) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("percentage %v is not between 0 and 100", percentage)
+	}
+	return nil
+}
+
+func (f *faultInjection) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return f.name, tracing.SpanKindNoneEnum
+}
+
+func (f *faultInjection) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), f.name, typeName))
+
+	if f.selected(f.delayPercentage) {
+		logger.Debugf("Delaying the request by %s", f.delay)
+
+		timer := time.NewTimer(f.delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	if f.selected(f.resetPercentage) {
+		logger.Debug("Resetting the connection")
+		reset(rw)
+		return
+	}
+
+	if f.selected(f.abortPercentage) {
+		logger.Debugf("Aborting the request with status %d", f.abortStatusCode)
+		http.Error(rw, http.StatusText(f.abortStatusCode), f.abortStatusCode)
+		return
+	}
+
+	f.next.ServeHTTP(rw, req)
+}
+
+func (f *faultInjection) selected(percentage float64) bool {
+	return percentage > 0 && f.random()*100 < percentage
+}
+
+// reset closes the connection of the request without response.
+// When the connection cannot be hijacked, as with HTTP/2, the request is aborted,
+// which resets the stream.
+func reset(rw http.ResponseWriter) {
+	hijacker, ok := rw.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+
+	// Discarding the unsent data makes the close send a TCP RST instead of a FIN.
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		_ = tcpConn.SetLinger(0)
+	}
+
+	_ = conn.Close()
+}
@@ -0,0 +1,195 @@
+package faultinjection
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.FaultInjection
+		expectedError bool
+	}{
+		{
+			desc:          "no faults",
+			config:        dynamic.FaultInjection{},
+			expectedError: true,
+		},
+		{
+			desc:          "delay without duration",
+			config:        dynamic.FaultInjection{Delay: &dynamic.FaultDelay{Percentage: 100}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid abort status code",
+			config:        dynamic.FaultInjection{Abort: &dynamic.FaultAbort{StatusCode: http.StatusOK, Percentage: 100}},
+			expectedError: true,
+		},
+		{
+			desc:          "percentage above 100",
+			config:        dynamic.FaultInjection{Reset: &dynamic.FaultReset{Percentage: 101}},
+			expectedError: true,
+		},
+		{
+			desc:          "negative percentage",
+			config:        dynamic.FaultInjection{Abort: &dynamic.FaultAbort{StatusCode: http.StatusBadGateway, Percentage: -1}},
+			expectedError: true,
+		},
+		{
+			desc: "valid",
+			config: dynamic.FaultInjection{
+				Delay: &dynamic.FaultDelay{Duration: types.Duration(time.Second), Percentage: 10},
+				Abort: &dynamic.FaultAbort{StatusCode: http.StatusBadGateway, Percentage: 10},
+				Reset: &dynamic.FaultReset{Percentage: 10},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := New(context.Background(), next, test.config, "foo-fault")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFaultInjection_abort(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		percentage     float64
+		random         float64
+		expectedStatus int
+	}{
+		{
+			desc:           "always aborted",
+			percentage:     100,
+			random:         0.99,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:           "selected request",
+			percentage:     30,
+			random:         0.2,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:           "not selected request",
+			percentage:     30,
+			random:         0.3,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "never aborted",
+			percentage:     0,
+			random:         0,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			config := dynamic.FaultInjection{
+				Abort: &dynamic.FaultAbort{StatusCode: http.StatusServiceUnavailable, Percentage: test.percentage},
+			}
+
+			handler, err := New(context.Background(), next, config, "foo-fault")
+			require.NoError(t, err)
+
+			handler.(*faultInjection).random = func() float64 { return test.random }
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com", nil))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestFaultInjection_delay(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	config := dynamic.FaultInjection{
+		Delay: &dynamic.FaultDelay{Duration: types.Duration(50 * time.Millisecond), Percentage: 100},
+	}
+
+	handler, err := New(context.Background(), next, config, "foo-fault")
+	require.NoError(t, err)
+
+	start := time.Now()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com", nil))
+
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestFaultInjection_delayCanceled(t *testing.T) {
+	var called bool
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		called = true
+	})
+
+	config := dynamic.FaultInjection{
+		Delay: &dynamic.FaultDelay{Duration: types.Duration(time.Hour), Percentage: 100},
+	}
+
+	handler, err := New(context.Background(), next, config, "foo-fault")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "http://foo.com", nil).WithContext(ctx)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.False(t, called)
+}
+
+func TestFaultInjection_reset(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	config := dynamic.FaultInjection{
+		Reset: &dynamic.FaultReset{Percentage: 100},
+	}
+
+	handler, err := New(context.Background(), next, config, "foo-fault")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+	assert.Error(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/tcp"
 )
 
 const (
@@ -19,6 +20,7 @@ const (
 	xForwardedMethod            = "X-Forwarded-Method"
 	xForwardedTLSClientCert     = "X-Forwarded-Tls-Client-Cert"
 	xForwardedTLSClientCertInfo = "X-Forwarded-Tls-Client-Cert-Info"
+	xForwardedTLSClientJA3      = "X-Forwarded-Tls-Client-Ja3"
 	xRealIP                     = "X-Real-Ip"
 	connection                  = "Connection"
 	upgrade                     = "Upgrade"
@@ -34,6 +36,7 @@ var xHeaders = []string{
 	xForwardedMethod,
 	xForwardedTLSClientCert,
 	xForwardedTLSClientCertInfo,
+	xForwardedTLSClientJA3,
 	xRealIP,
 }
 
@@ -159,6 +162,10 @@ func (x *XForwarded) rewrite(outreq *http.Request) {
 	if x.hostname != "" {
 		outreq.Header.Set(xForwardedServer, x.hostname)
 	}
+
+	if ja3 := tcp.GetClientJA3(outreq.Context()); ja3 != "" {
+		outreq.Header.Set(xForwardedTLSClientJA3, ja3)
+	}
 }
 
 // ServeHTTP implements http.Handler
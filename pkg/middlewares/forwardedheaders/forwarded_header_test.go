@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/containous/traefik/v2/pkg/tcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,6 +21,7 @@ func TestServeHTTP(t *testing.T) {
 		tls             bool
 		websocket       bool
 		host            string
+		ja3             string
 	}{
 		{
 			desc:            "all Empty",
@@ -242,6 +244,26 @@ func TestServeHTTP(t *testing.T) {
 				xForwardedServer: "foo.com:8080",
 			},
 		},
+		{
+			desc: "xForwardedTLSClientJA3 from the fingerprint of the connection",
+			tls:  true,
+			ja3:  "e7d705a3286e19ea42f587b344ee6865",
+			incomingHeaders: map[string]string{
+				xForwardedTLSClientJA3: "forged",
+			},
+			expectedHeaders: map[string]string{
+				xForwardedTLSClientJA3: "e7d705a3286e19ea42f587b344ee6865",
+			},
+		},
+		{
+			desc: "xForwardedTLSClientJA3 removed without the fingerprint of the connection",
+			incomingHeaders: map[string]string{
+				xForwardedTLSClientJA3: "forged",
+			},
+			expectedHeaders: map[string]string{
+				xForwardedTLSClientJA3: "",
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -267,6 +289,10 @@ func TestServeHTTP(t *testing.T) {
 				req.Host = test.host
 			}
 
+			if test.ja3 != "" {
+				req = req.WithContext(tcp.WithClientJA3(req.Context(), test.ja3))
+			}
+
 			for k, v := range test.incomingHeaders {
 				req.Header.Set(k, v)
 			}
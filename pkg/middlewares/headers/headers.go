@@ -42,6 +42,10 @@ func New(ctx context.Context, next http.Handler, cfg dynamic.Headers, name strin
 
 	handleDeprecation(mCtx, &cfg)
 
+	if err := ApplySecurityPreset(&cfg); err != nil {
+		return nil, err
+	}
+
 	hasSecureHeaders := cfg.HasSecureHeadersDefined()
 	hasCustomHeaders := cfg.HasCustomHeadersDefined()
 	hasCorsHeaders := cfg.HasCorsHeadersDefined()
@@ -0,0 +1,94 @@
+package headers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+// securityPreset is a curated set of security headers.
+type securityPreset struct {
+	stsSeconds              int64
+	stsIncludeSubdomains    bool
+	frameDeny               bool
+	customFrameOptionsValue string
+	contentTypeNosniff      bool
+	referrerPolicy          string
+	// responseHeaders are the headers without a dedicated option, added to the custom response headers.
+	responseHeaders map[string]string
+}
+
+var securityPresets = map[string]securityPreset{
+	"basic": {
+		stsSeconds:              31536000,
+		customFrameOptionsValue: "SAMEORIGIN",
+		contentTypeNosniff:      true,
+		referrerPolicy:          "strict-origin-when-cross-origin",
+		responseHeaders: map[string]string{
+			"Cross-Origin-Opener-Policy": "same-origin-allow-popups",
+			"Permissions-Policy":         "camera=(), geolocation=(), microphone=()",
+		},
+	},
+	"strict": {
+		stsSeconds:           63072000,
+		stsIncludeSubdomains: true,
+		frameDeny:            true,
+		contentTypeNosniff:   true,
+		referrerPolicy:       "no-referrer",
+		responseHeaders: map[string]string{
+			// The policy is only reported, as a starting point to tailor the policy of the application.
+			"Content-Security-Policy-Report-Only": "default-src 'self'; object-src 'none'; base-uri 'self'; frame-ancestors 'none'",
+			"Cross-Origin-Opener-Policy":          "same-origin",
+			"Cross-Origin-Embedder-Policy":        "require-corp",
+			"Cross-Origin-Resource-Policy":        "same-origin",
+			"Permissions-Policy":                  "accelerometer=(), camera=(), geolocation=(), gyroscope=(), magnetometer=(), microphone=(), payment=(), usb=()",
+		},
+	},
+}
+
+// ApplySecurityPreset sets the options of the security preset of the configuration which are not set in the configuration.
+// The custom response headers are copied before being modified, as they are shared with the runtime configuration.
+func ApplySecurityPreset(cfg *dynamic.Headers) error {
+	if cfg.SecurityPreset == "" {
+		return nil
+	}
+
+	preset, ok := securityPresets[cfg.SecurityPreset]
+	if !ok {
+		return fmt.Errorf("unknown security preset: %q", cfg.SecurityPreset)
+	}
+
+	if cfg.STSSeconds == 0 {
+		cfg.STSSeconds = preset.stsSeconds
+		cfg.STSIncludeSubdomains = cfg.STSIncludeSubdomains || preset.stsIncludeSubdomains
+	}
+
+	// The frameDeny option is ignored by the secure headers when customFrameOptionsValue is set.
+	if !cfg.FrameDeny && cfg.CustomFrameOptionsValue == "" {
+		cfg.FrameDeny = preset.frameDeny
+		cfg.CustomFrameOptionsValue = preset.customFrameOptionsValue
+	}
+
+	cfg.ContentTypeNosniff = cfg.ContentTypeNosniff || preset.contentTypeNosniff
+
+	if cfg.ReferrerPolicy == "" {
+		cfg.ReferrerPolicy = preset.referrerPolicy
+	}
+
+	responseHeaders := make(map[string]string, len(cfg.CustomResponseHeaders)+len(preset.responseHeaders))
+	for header, value := range cfg.CustomResponseHeaders {
+		responseHeaders[http.CanonicalHeaderKey(header)] = value
+	}
+
+	// An empty custom response header removes the preset one.
+	for header, value := range preset.responseHeaders {
+		if _, ok := responseHeaders[header]; !ok {
+			responseHeaders[header] = value
+		}
+	}
+
+	cfg.CustomResponseHeaders = responseHeaders
+
+	return nil
+}
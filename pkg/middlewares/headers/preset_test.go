@@ -0,0 +1,133 @@
+package headers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySecurityPreset(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		cfg      dynamic.Headers
+		expected dynamic.Headers
+	}{
+		{
+			desc: "no preset",
+			cfg: dynamic.Headers{
+				FrameDeny: true,
+			},
+			expected: dynamic.Headers{
+				FrameDeny: true,
+			},
+		},
+		{
+			desc: "basic preset",
+			cfg: dynamic.Headers{
+				SecurityPreset: "basic",
+			},
+			expected: dynamic.Headers{
+				SecurityPreset:          "basic",
+				STSSeconds:              31536000,
+				CustomFrameOptionsValue: "SAMEORIGIN",
+				ContentTypeNosniff:      true,
+				ReferrerPolicy:          "strict-origin-when-cross-origin",
+				CustomResponseHeaders: map[string]string{
+					"Cross-Origin-Opener-Policy": "same-origin-allow-popups",
+					"Permissions-Policy":         "camera=(), geolocation=(), microphone=()",
+				},
+			},
+		},
+		{
+			desc: "basic preset with frame deny",
+			cfg: dynamic.Headers{
+				SecurityPreset: "basic",
+				FrameDeny:      true,
+			},
+			expected: dynamic.Headers{
+				SecurityPreset:     "basic",
+				STSSeconds:         31536000,
+				FrameDeny:          true,
+				ContentTypeNosniff: true,
+				ReferrerPolicy:     "strict-origin-when-cross-origin",
+				CustomResponseHeaders: map[string]string{
+					"Cross-Origin-Opener-Policy": "same-origin-allow-popups",
+					"Permissions-Policy":         "camera=(), geolocation=(), microphone=()",
+				},
+			},
+		},
+		{
+			desc: "strict preset with overrides",
+			cfg: dynamic.Headers{
+				SecurityPreset:          "strict",
+				STSSeconds:              300,
+				CustomFrameOptionsValue: "SAMEORIGIN",
+				ReferrerPolicy:          "same-origin",
+				CustomResponseHeaders: map[string]string{
+					"X-Foo":                               "foo",
+					"content-security-policy-report-only": "default-src 'self'; report-uri /csp",
+					"Cross-Origin-Embedder-Policy":        "",
+				},
+			},
+			expected: dynamic.Headers{
+				SecurityPreset:          "strict",
+				STSSeconds:              300,
+				CustomFrameOptionsValue: "SAMEORIGIN",
+				ContentTypeNosniff:      true,
+				ReferrerPolicy:          "same-origin",
+				CustomResponseHeaders: map[string]string{
+					"X-Foo":                               "foo",
+					"Content-Security-Policy-Report-Only": "default-src 'self'; report-uri /csp",
+					"Cross-Origin-Opener-Policy":          "same-origin",
+					"Cross-Origin-Embedder-Policy":        "",
+					"Cross-Origin-Resource-Policy":        "same-origin",
+					"Permissions-Policy":                  "accelerometer=(), camera=(), geolocation=(), gyroscope=(), magnetometer=(), microphone=(), payment=(), usb=()",
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := test.cfg
+			err := ApplySecurityPreset(&cfg)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, cfg)
+		})
+	}
+}
+
+func TestApplySecurityPreset_sharedHeaders(t *testing.T) {
+	responseHeaders := map[string]string{"X-Foo": "foo"}
+
+	cfg := dynamic.Headers{SecurityPreset: "strict", CustomResponseHeaders: responseHeaders}
+	err := ApplySecurityPreset(&cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"X-Foo": "foo"}, responseHeaders)
+}
+
+func TestApplySecurityPreset_unknown(t *testing.T) {
+	cfg := dynamic.Headers{SecurityPreset: "foo"}
+
+	err := ApplySecurityPreset(&cfg)
+	assert.Error(t, err)
+}
+
+func TestSecurityPresetHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.Headers{SecurityPreset: "strict"}, "testing")
+	require.NoError(t, err)
+
+	_, err = New(context.Background(), next, dynamic.Headers{SecurityPreset: "foo"}, "testing")
+	assert.Error(t, err)
+}
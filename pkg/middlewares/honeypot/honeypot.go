@@ -0,0 +1,258 @@
+// Package honeypot implements a middleware tarpitting the requests to known exploit paths.
+package honeypot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/gossip"
+	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/mailgun/ttlmap"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName   = "Honeypot"
+	maxClients = 65536
+	// maxTarpits is how many requests all the honeypots hold at most at once,
+	// the requests caught beyond that are answered immediately.
+	maxTarpits = 1024
+)
+
+// defaultPatterns are the paths commonly probed by the vulnerability scanners.
+var defaultPatterns = []string{
+	`^/\.env`,
+	`^/\.git/`,
+	`^/\.aws/`,
+	`^/\.ssh/`,
+	`^/wp-login\.php`,
+	`^/wp-admin/`,
+	`^/xmlrpc\.php`,
+	`(?i)^/phpmyadmin`,
+	`(?i)^/pma/`,
+	`^/cgi-bin/`,
+	`^/vendor/phpunit/`,
+	`^/boaform/`,
+	`^/HNAP1`,
+	`^/solr/admin/`,
+	`/etc/passwd`,
+}
+
+var (
+	taggedMu sync.Mutex
+	// tagged holds the tagged clients of each honeypot, keyed by middleware name,
+	// so that they are shared by all the routers using the same honeypot, and survive the configuration reloads.
+	// The clients of a honeypot are dropped once all their tags have expired.
+	tagged = make(map[string]*taggedClients)
+
+	// tarpits is the number of requests currently held by the honeypots.
+	tarpits int64
+)
+
+// taggedClients are the clients tagged by a honeypot.
+type taggedClients struct {
+	*ttlmap.TtlMap
+	// expires is when the last tag expires.
+	expires time.Time
+}
+
+func getTagged(name string) (*taggedClients, error) {
+	taggedMu.Lock()
+	defer taggedMu.Unlock()
+
+	sweepTagged(time.Now())
+
+	if clients, ok := tagged[name]; ok {
+		return clients, nil
+	}
+
+	clients, err := ttlmap.NewConcurrent(maxClients)
+	if err != nil {
+		return nil, err
+	}
+
+	tagged[name] = &taggedClients{TtlMap: clients}
+	return tagged[name], nil
+}
+
+// sweepTagged drops the clients of the honeypots whose tags have all expired.
+// It must be called with taggedMu held.
+func sweepTagged(now time.Time) {
+	for name, clients := range tagged {
+		if !clients.expires.After(now) {
+			delete(tagged, name)
+		}
+	}
+}
+
+// honeypot is a middleware that holds, and answers with a fake response, the requests to known exploit paths.
+// The clients sending such requests are tagged, and all their subsequent requests are handled the same way for a while.
+type honeypot struct {
+	next          http.Handler
+	name          string
+	patterns      []*regexp.Regexp
+	delay         time.Duration
+	statusCode    int
+	body          []byte
+	blockDuration time.Duration
+	strategy      ip.Strategy
+	clients       *taggedClients
+	// shared is the state shared with the other instances, which ban the clients they tag.
+	shared *gossip.State
+}
+
+// New builds a new Honeypot middleware.
+// When the state is shared with the other instances, the clients tagged by any of them are tagged by all of them.
+func New(ctx context.Context, next http.Handler, config dynamic.Honeypot, name string, shared *gossip.State) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	patterns := config.Patterns
+	if len(patterns) == 0 {
+		patterns = defaultPatterns
+	}
+
+	var exps []*regexp.Regexp
+	for _, pattern := range patterns {
+		exp, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling regular expression %s: %v", pattern, err)
+		}
+		exps = append(exps, exp)
+	}
+
+	statusCode := config.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusNotFound
+	}
+	if statusCode < 200 || statusCode > 599 {
+		return nil, fmt.Errorf("invalid status code %d", statusCode)
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := getTagged(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &honeypot{
+		next:          next,
+		name:          name,
+		patterns:      exps,
+		delay:         time.Duration(config.Delay),
+		statusCode:    statusCode,
+		body:          []byte(config.Body),
+		blockDuration: time.Duration(config.BlockDuration),
+		strategy:      strategy,
+		clients:       clients,
+		shared:        shared,
+	}, nil
+}
+
+func (h *honeypot) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return h.name, tracing.SpanKindNoneEnum
+}
+
+func (h *honeypot) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), h.name, typeName)
+	logger := log.FromContext(ctx)
+
+	clientIP := h.strategy.GetIP(req)
+
+	if !h.tagged(clientIP) {
+		if !h.match(req.URL.Path) {
+			h.next.ServeHTTP(rw, req)
+			return
+		}
+
+		if h.blockDuration > 0 {
+			ttl := int(math.Ceil(h.blockDuration.Seconds()))
+			if err := h.clients.Set(clientIP, struct{}{}, ttl); err != nil {
+				logger.Errorf("could not tag client %s: %v", clientIP, err)
+			} else {
+				h.touch(time.Now().Add(h.blockDuration))
+			}
+
+			if h.shared != nil {
+				h.shared.Ban(h.name+"/"+clientIP, time.Now().Add(h.blockDuration))
+			}
+		}
+	}
+
+	logMessage := fmt.Sprintf("tarpitting request from %s %+v", clientIP, req)
+	logger.Debug(logMessage)
+	tracing.SetErrorWithEvent(req, logMessage)
+
+	h.tarpit(ctx, rw, req)
+}
+
+func (h *honeypot) tagged(clientIP string) bool {
+	if _, tagged := h.clients.Get(clientIP); tagged {
+		return true
+	}
+
+	return h.shared != nil && h.shared.Banned(h.name+"/"+clientIP)
+}
+
+// touch extends the lifetime of the tagged clients until the given expiry,
+// registering them again if they were swept meanwhile.
+func (h *honeypot) touch(expires time.Time) {
+	taggedMu.Lock()
+	defer taggedMu.Unlock()
+
+	if expires.After(h.clients.expires) {
+		h.clients.expires = expires
+	}
+
+	if _, ok := tagged[h.name]; !ok {
+		tagged[h.name] = h.clients
+	}
+}
+
+func (h *honeypot) match(path string) bool {
+	for _, exp := range h.patterns {
+		if exp.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *honeypot) tarpit(ctx context.Context, rw http.ResponseWriter, req *http.Request) {
+	if h.delay > 0 {
+		held := atomic.AddInt64(&tarpits, 1)
+		defer atomic.AddInt64(&tarpits, -1)
+
+		if held <= maxTarpits {
+			timer := time.NewTimer(h.delay)
+			defer timer.Stop()
+
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				return
+			}
+		} else {
+			log.FromContext(ctx).Debugf("Too many requests held, answering immediately")
+		}
+	}
+
+	rw.WriteHeader(h.statusCode)
+	if _, err := rw.Write(h.body); err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
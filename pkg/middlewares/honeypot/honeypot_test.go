@@ -0,0 +1,224 @@
+package honeypot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/gossip"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHoneypot(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.Honeypot{Patterns: []string{"("}}, "foo-honeypot", nil)
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.Honeypot{StatusCode: 42}, "foo-honeypot", nil)
+	assert.Error(t, err)
+
+	handler, err := New(context.Background(), next, dynamic.Honeypot{}, "foo-honeypot", nil)
+	require.NoError(t, err)
+	assert.Len(t, handler.(*honeypot).patterns, len(defaultPatterns))
+}
+
+func TestHoneypot(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             dynamic.Honeypot
+		path               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			desc:               "default patterns, legit path",
+			path:               "/index.html",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "backend",
+		},
+		{
+			desc:               "default patterns, exploit path",
+			path:               "/wp-login.php",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc: "custom patterns and response",
+			config: dynamic.Honeypot{
+				Patterns:   []string{`^/admin`},
+				StatusCode: http.StatusOK,
+				Body:       "<html></html>",
+			},
+			path:               "/admin/login",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "<html></html>",
+		},
+		{
+			desc: "custom patterns replace the default ones",
+			config: dynamic.Honeypot{
+				Patterns: []string{`^/admin`},
+			},
+			path:               "/wp-login.php",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "backend",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("backend"))
+			})
+
+			handler, err := New(context.Background(), next, test.config, "honeypot-"+test.desc, nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost"+test.path, nil)
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatusCode, rw.Code)
+			assert.Equal(t, test.expectedBody, rw.Body.String())
+		})
+	}
+}
+
+func TestHoneypotTagging(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	config := dynamic.Honeypot{BlockDuration: types.Duration(time.Minute)}
+
+	handler, err := New(context.Background(), next, config, "tagging-honeypot", nil)
+	require.NoError(t, err)
+
+	serve := func(handler http.Handler, remoteAddr, path string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost"+path, nil)
+		req.RemoteAddr = remoteAddr
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(handler, "10.0.0.1:1234", "/"))
+	assert.Equal(t, http.StatusNotFound, serve(handler, "10.0.0.1:1234", "/.env"))
+
+	// The client is now tagged, even for legit paths.
+	assert.Equal(t, http.StatusNotFound, serve(handler, "10.0.0.1:1234", "/"))
+
+	// Other clients are not affected.
+	assert.Equal(t, http.StatusOK, serve(handler, "10.0.0.2:1234", "/"))
+
+	// The tags are shared by the instances of the same middleware, e.g. after a configuration reload.
+	reloaded, err := New(context.Background(), next, config, "tagging-honeypot", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, serve(reloaded, "10.0.0.1:1234", "/"))
+
+	other, err := New(context.Background(), next, config, "other-honeypot", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serve(other, "10.0.0.1:1234", "/"))
+}
+
+func TestHoneypotTagging_sharedState(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	config := dynamic.Honeypot{BlockDuration: types.Duration(time.Minute)}
+	shared := gossip.NewState("node1")
+
+	handler, err := New(context.Background(), next, config, "shared-honeypot", shared)
+	require.NoError(t, err)
+
+	serve := func(remoteAddr, path string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost"+path, nil)
+		req.RemoteAddr = remoteAddr
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve("10.0.0.1:1234", "/.env"))
+	assert.True(t, shared.Banned("shared-honeypot/10.0.0.1"))
+
+	// The clients tagged by another instance are tagged too.
+	shared.Ban("shared-honeypot/10.0.0.2", time.Now().Add(time.Minute))
+	assert.Equal(t, http.StatusNotFound, serve("10.0.0.2:1234", "/"))
+
+	assert.Equal(t, http.StatusOK, serve("10.0.0.3:1234", "/"))
+}
+
+func TestHoneypotDelay(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, dynamic.Honeypot{Delay: types.Duration(100 * time.Millisecond)}, "delay-honeypot", nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/.git/config", nil)
+	rw := httptest.NewRecorder()
+
+	start := time.Now()
+	handler.ServeHTTP(rw, req)
+
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(100*time.Millisecond))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	// The client giving up stops the tarpit.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req = httptest.NewRequest(http.MethodGet, "http://localhost/.git/config", nil).WithContext(ctx)
+	rw = httptest.NewRecorder()
+
+	start = time.Now()
+	handler.ServeHTTP(rw, req)
+
+	assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+}
+
+func TestHoneypotDelay_maxTarpits(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, dynamic.Honeypot{Delay: types.Duration(time.Minute)}, "max-honeypot", nil)
+	require.NoError(t, err)
+
+	atomic.AddInt64(&tarpits, maxTarpits)
+	defer atomic.AddInt64(&tarpits, -maxTarpits)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/.git/config", nil)
+	rw := httptest.NewRecorder()
+
+	start := time.Now()
+	handler.ServeHTTP(rw, req)
+
+	assert.Less(t, int64(time.Since(start)), int64(time.Minute))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, int64(maxTarpits), atomic.LoadInt64(&tarpits))
+}
+
+func TestSweepTagged(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	config := dynamic.Honeypot{BlockDuration: types.Duration(time.Minute)}
+
+	handler, err := New(context.Background(), next, config, "sweep-honeypot", nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/.env", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	taggedMu.Lock()
+	defer taggedMu.Unlock()
+
+	sweepTagged(time.Now())
+	assert.Contains(t, tagged, "sweep-honeypot")
+
+	sweepTagged(time.Now().Add(2 * time.Minute))
+	assert.NotContains(t, tagged, "sweep-honeypot")
+}
@@ -30,7 +30,8 @@ func New(ctx context.Context, next http.Handler, config dynamic.InFlightReq, nam
 
 	if config.SourceCriterion == nil ||
 		config.SourceCriterion.IPStrategy == nil &&
-			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost {
+			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost &&
+			config.SourceCriterion.APIKey == "" {
 		config.SourceCriterion = &dynamic.SourceCriterion{
 			RequestHost: true,
 		}
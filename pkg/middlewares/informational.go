@@ -0,0 +1,102 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+type connKey struct{}
+
+// WithConn returns a context holding the client connection of the requests,
+// on which their interim responses are written over HTTP/1.1.
+func WithConn(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// IsInformational reports whether the given status code is a registered interim response code,
+// such as 103 Early Hints, which precedes the final response and must not be recorded as its status.
+// 101 Switching Protocols is not considered as such, since it is the last response sent over HTTP.
+func IsInformational(code int) bool {
+	switch code {
+	case http.StatusContinue, http.StatusProcessing, http.StatusEarlyHints:
+		return true
+	default:
+		return false
+	}
+}
+
+// SendInformational sends an interim response with the given code and headers to the client of the request,
+// before its final response is written, and reports whether it is sent.
+// Before Go 1.19, the ResponseWriter sends the first status code it is given as the final one:
+// over HTTP/1.1, the interim response is written on the client connection of the request instead,
+// and over HTTP/2, it is not sent.
+// The interim responses are never sent to the HTTP/1.0 clients, which do not understand them.
+func SendInformational(rw http.ResponseWriter, req *http.Request, code int, header http.Header) bool {
+	if req.ProtoMajor == 1 {
+		if req.ProtoMinor == 0 {
+			return false
+		}
+
+		if conn, ok := req.Context().Value(connKey{}).(net.Conn); ok {
+			if err := writeInterimResponse(conn, code, header); err != nil {
+				log.FromContext(req.Context()).Debugf("Unable to send the %d interim response: %v", code, err)
+				return false
+			}
+
+			return true
+		}
+	}
+
+	if !writeHeaderSendsInformational {
+		return false
+	}
+
+	WriteInformational(rw, code, header)
+
+	return true
+}
+
+// writeInterimResponse writes an HTTP/1.1 interim response on the connection.
+// It is written with a single write, which does not interleave with the writes of the server.
+func writeInterimResponse(conn net.Conn, code int, header http.Header) error {
+	var buf bytes.Buffer
+	_, _ = fmt.Fprintf(&buf, "HTTP/1.1 %03d %s\r\n", code, http.StatusText(code))
+
+	if err := header.Write(&buf); err != nil {
+		return err
+	}
+
+	_, _ = buf.WriteString("\r\n")
+
+	_, err := conn.Write(buf.Bytes())
+	return err
+}
+
+// WriteInformational sends an interim response with the given code and headers through rw,
+// leaving the headers already set on rw for the final response untouched.
+// The ResponseWriter of the server only sends it as an interim response from Go 1.19.
+func WriteInformational(rw http.ResponseWriter, code int, header http.Header) {
+	headers := rw.Header()
+	saved := headers.Clone()
+
+	for name := range headers {
+		delete(headers, name)
+	}
+	for name, values := range header {
+		headers[name] = values
+	}
+
+	rw.WriteHeader(code)
+
+	for name := range headers {
+		delete(headers, name)
+	}
+	for name, values := range saved {
+		headers[name] = values
+	}
+}
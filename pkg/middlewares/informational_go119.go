@@ -0,0 +1,6 @@
+// +build go1.19
+
+package middlewares
+
+// writeHeaderSendsInformational is whether the ResponseWriter of the server sends the 1xx status codes as interim responses.
+const writeHeaderSendsInformational = true
@@ -0,0 +1,162 @@
+// Package methodoverride implements a middleware honoring or stripping the method override headers,
+// and synthesizing the HEAD responses from the GET responses of the backend.
+package methodoverride
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "MethodOverride"
+)
+
+// Policies of the method override headers.
+const (
+	policyStrip = "strip"
+	policyHonor = "honor"
+)
+
+var (
+	defaultHeaders        = []string{"X-HTTP-Method-Override", "X-HTTP-Method", "X-Method-Override"}
+	defaultAllowedMethods = []string{http.MethodDelete, http.MethodPatch, http.MethodPut}
+)
+
+// methodOverride removes the method override headers of the requests, after overriding the method of the POST requests with their value,
+// and forwards the HEAD requests as GET requests.
+type methodOverride struct {
+	name           string
+	next           http.Handler
+	honor          bool
+	headers        []string
+	allowedMethods map[string]struct{}
+	synthesizeHead bool
+}
+
+// New creates a method override middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.MethodOverride, name string) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	m := &methodOverride{
+		name:           name,
+		next:           next,
+		headers:        config.Headers,
+		allowedMethods: make(map[string]struct{}),
+		synthesizeHead: config.SynthesizeHead,
+	}
+
+	switch config.Policy {
+	case "", policyStrip:
+	case policyHonor:
+		m.honor = true
+	default:
+		return nil, fmt.Errorf("invalid policy: %q", config.Policy)
+	}
+
+	if len(m.headers) == 0 {
+		m.headers = defaultHeaders
+	}
+
+	allowedMethods := config.AllowedMethods
+	if len(allowedMethods) == 0 {
+		allowedMethods = defaultAllowedMethods
+	}
+	for _, method := range allowedMethods {
+		m.allowedMethods[strings.ToUpper(method)] = struct{}{}
+	}
+
+	return m, nil
+}
+
+func (m *methodOverride) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return m.name, tracing.SpanKindNoneEnum
+}
+
+func (m *methodOverride) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	override := m.override(req)
+
+	for _, header := range m.headers {
+		req.Header.Del(header)
+	}
+
+	if override != "" {
+		req.Method = override
+	}
+
+	if m.synthesizeHead && req.Method == http.MethodHead {
+		req.Method = http.MethodGet
+		rw = &headResponseWriter{ResponseWriter: rw}
+	}
+
+	m.next.ServeHTTP(rw, req)
+}
+
+// override returns the method the request is overridden to, or the empty string.
+func (m *methodOverride) override(req *http.Request) string {
+	if !m.honor || req.Method != http.MethodPost {
+		return ""
+	}
+
+	for _, header := range m.headers {
+		value := req.Header.Get(header)
+		if value == "" {
+			continue
+		}
+
+		method := strings.ToUpper(strings.TrimSpace(value))
+		if _, ok := m.allowedMethods[method]; !ok {
+			log.FromContext(middlewares.GetLoggerCtx(req.Context(), m.name, typeName)).Debugf("Ignoring the override of the method to %q: not allowed", value)
+			return ""
+		}
+
+		return method
+	}
+
+	return ""
+}
+
+// headResponseWriter discards the body of the GET response answering a HEAD request,
+// while keeping its headers, Content-Length included.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Hijack hijacks the connection.
+func (w *headResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+	}
+	return h.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (w *headResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (w *headResponseWriter) CloseNotify() <-chan bool {
+	if c, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return c.CloseNotify()
+	}
+	return make(<-chan bool)
+}
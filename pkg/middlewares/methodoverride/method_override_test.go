@@ -0,0 +1,126 @@
+package methodoverride
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMethodOverride(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          dynamic.MethodOverride
+		method          string
+		headers         map[string]string
+		expectedMethod  string
+		expectedHeaders http.Header
+	}{
+		{
+			desc:            "stripped by default",
+			method:          http.MethodPost,
+			headers:         map[string]string{"X-HTTP-Method-Override": "DELETE", "X-Method-Override": "PUT", "X-Foo": "bar"},
+			expectedMethod:  http.MethodPost,
+			expectedHeaders: http.Header{"X-Foo": {"bar"}},
+		},
+		{
+			desc:           "honored",
+			config:         dynamic.MethodOverride{Policy: "honor"},
+			method:         http.MethodPost,
+			headers:        map[string]string{"X-HTTP-Method": "patch"},
+			expectedMethod: http.MethodPatch,
+		},
+		{
+			desc:           "honored only for POST requests",
+			config:         dynamic.MethodOverride{Policy: "honor"},
+			method:         http.MethodGet,
+			headers:        map[string]string{"X-HTTP-Method-Override": "DELETE"},
+			expectedMethod: http.MethodGet,
+		},
+		{
+			desc:           "method not allowed",
+			config:         dynamic.MethodOverride{Policy: "honor"},
+			method:         http.MethodPost,
+			headers:        map[string]string{"X-HTTP-Method-Override": "CONNECT"},
+			expectedMethod: http.MethodPost,
+		},
+		{
+			desc:            "custom headers and allowed methods",
+			config:          dynamic.MethodOverride{Policy: "honor", Headers: []string{"X-Foo"}, AllowedMethods: []string{"get"}},
+			method:          http.MethodPost,
+			headers:         map[string]string{"X-Foo": "GET", "X-HTTP-Method-Override": "DELETE"},
+			expectedMethod:  http.MethodGet,
+			expectedHeaders: http.Header{"X-Http-Method-Override": {"DELETE"}},
+		},
+		{
+			desc:           "HEAD forwarded as is",
+			method:         http.MethodHead,
+			expectedMethod: http.MethodHead,
+		},
+		{
+			desc:           "HEAD synthesized from GET",
+			config:         dynamic.MethodOverride{SynthesizeHead: true},
+			method:         http.MethodHead,
+			expectedMethod: http.MethodGet,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var method string
+			headers := http.Header{}
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				method = req.Method
+				headers = req.Header
+			})
+
+			handler, err := New(context.Background(), next, test.config, "test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(test.method, "http://localhost", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			expectedHeaders := test.expectedHeaders
+			if expectedHeaders == nil {
+				expectedHeaders = http.Header{}
+			}
+
+			assert.Equal(t, test.expectedMethod, method)
+			assert.Equal(t, expectedHeaders, headers)
+		})
+	}
+}
+
+func TestMethodOverride_synthesizedHead(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Length", "6")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte("foobar"))
+	})
+
+	handler, err := New(context.Background(), next, dynamic.MethodOverride{SynthesizeHead: true}, "test")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "http://localhost", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "6", recorder.Header().Get("Content-Length"))
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestNew_invalidPolicy(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.MethodOverride{Policy: "foo"}, "test")
+	assert.Error(t, err)
+}
@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	grpcStatusHeader = "Grpc-Status"
+
+	// grpcMessageHeaderLen is the length of the prefix of a gRPC message:
+	// a compression flag, and the length of the message, as a 4 bytes big-endian integer.
+	grpcMessageHeaderLen = 5
+
+	directionRequest  = "request"
+	directionResponse = "response"
+
+	// maxGRPCMethods is how many distinct gRPC methods a metrics middleware labels,
+	// the other methods being labeled as unknown.
+	maxGRPCMethods    = 1000
+	unknownGRPCMethod = "unknown"
+)
+
+// grpcMethodRegexp matches the paths of the gRPC methods, e.g. /helloworld.Greeter/SayHello.
+var grpcMethodRegexp = regexp.MustCompile(`^/[A-Za-z_][A-Za-z0-9_.]*/[A-Za-z_][A-Za-z0-9_]*$`)
+
+// isGRPCRequest determines if the specified HTTP request is a gRPC request.
+// The gRPC-Web requests are excluded, as their trailers are sent in the body of the response.
+func isGRPCRequest(req *http.Request) bool {
+	contentType := strings.ToLower(req.Header.Get("Content-Type"))
+	return strings.HasPrefix(contentType, "application/grpc") && !strings.HasPrefix(contentType, "application/grpc-web")
+}
+
+// serveGRPC serves a gRPC stream, and records the number of messages in each direction,
+// and the gRPC status of the stream, which is hidden behind an HTTP 200 status most of the time.
+func (m *metricsMiddleware) serveGRPC(rw recorder, req *http.Request) {
+	method := m.grpcMethods.label(req.URL.Path)
+
+	activeLabels := append(append([]string{}, m.baseLabels...), "grpc_method", method)
+	m.grpcActiveStreamsGauge.With(activeLabels...).Add(1)
+	defer m.grpcActiveStreamsGauge.With(activeLabels...).Add(-1)
+
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &grpcRequestBody{
+			ReadCloser: req.Body,
+			messages:   grpcMessageCounter{counter: m.grpcMessagesCounter.With(append(append([]string{}, activeLabels...), "direction", directionRequest)...)},
+		}
+	}
+
+	writer := &grpcResponseWriter{
+		recorder: rw,
+		messages: grpcMessageCounter{counter: m.grpcMessagesCounter.With(append(append([]string{}, activeLabels...), "direction", directionResponse)...)},
+	}
+
+	start := time.Now()
+
+	m.next.ServeHTTP(writer, req)
+
+	labels := append(append([]string{}, activeLabels...), "grpc_code", getGRPCCode(rw.Header(), rw.getCode()))
+
+	m.grpcStreamDurationHistogram.With(labels...).ObserveFromStart(start)
+	m.grpcStreamsCounter.With(labels...).Add(1)
+}
+
+// grpcMethods are the gRPC methods labeled by a metrics middleware.
+// As the path of a request is chosen by the client, only the paths shaped like gRPC methods are labeled,
+// up to maxGRPCMethods of them, so that the cardinality of the metrics is bounded.
+type grpcMethods struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func (g *grpcMethods) label(path string) string {
+	if len(path) > 256 || !grpcMethodRegexp.MatchString(path) {
+		return unknownGRPCMethod
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.seen[path]; ok {
+		return path
+	}
+
+	if len(g.seen) >= maxGRPCMethods {
+		return unknownGRPCMethod
+	}
+
+	if g.seen == nil {
+		g.seen = make(map[string]struct{})
+	}
+	g.seen[path] = struct{}{}
+
+	return path
+}
+
+// getGRPCCode returns the gRPC status code of a response, from its trailers,
+// or from its headers for a Trailers-Only response.
+// Without a gRPC status, e.g. when the backend could not be reached,
+// the code is derived from the HTTP status, as specified for the gRPC clients.
+func getGRPCCode(header http.Header, httpCode int) string {
+	status := header.Get(grpcStatusHeader)
+	if status == "" {
+		// The trailers not announced in the headers.
+		status = header.Get(http.TrailerPrefix + grpcStatusHeader)
+	}
+
+	if status != "" {
+		code, err := strconv.ParseUint(status, 10, 32)
+		if err != nil {
+			return codes.Unknown.String()
+		}
+		return codes.Code(code).String()
+	}
+
+	switch httpCode {
+	case http.StatusBadRequest:
+		return codes.Internal.String()
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated.String()
+	case http.StatusForbidden:
+		return codes.PermissionDenied.String()
+	case http.StatusNotFound:
+		return codes.Unimplemented.String()
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable.String()
+	default:
+		return codes.Unknown.String()
+	}
+}
+
+// grpcMessageCounter counts the length-prefixed messages of a gRPC stream, as its bytes go through.
+type grpcMessageCounter struct {
+	counter gokitmetrics.Counter
+
+	header    [grpcMessageHeaderLen]byte
+	headerLen int
+	remaining uint32
+}
+
+func (c *grpcMessageCounter) parse(data []byte) {
+	for len(data) > 0 {
+		if c.remaining > 0 {
+			n := uint32(len(data))
+			if n > c.remaining {
+				n = c.remaining
+			}
+
+			c.remaining -= n
+			data = data[n:]
+			continue
+		}
+
+		n := copy(c.header[c.headerLen:], data)
+		c.headerLen += n
+		data = data[n:]
+
+		if c.headerLen == grpcMessageHeaderLen {
+			c.counter.Add(1)
+			c.remaining = binary.BigEndian.Uint32(c.header[1:])
+			c.headerLen = 0
+		}
+	}
+}
+
+// grpcRequestBody counts the messages of a gRPC stream sent by the client.
+type grpcRequestBody struct {
+	io.ReadCloser
+	messages grpcMessageCounter
+}
+
+func (b *grpcRequestBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.messages.parse(p[:n])
+	return n, err
+}
+
+// grpcResponseWriter counts the messages of a gRPC stream sent by the server.
+type grpcResponseWriter struct {
+	recorder
+	messages grpcMessageCounter
+}
+
+func (w *grpcResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.recorder.Write(p)
+	w.messages.parse(p[:n])
+	return n, err
+}
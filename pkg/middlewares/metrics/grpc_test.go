@@ -0,0 +1,305 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/metrics"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceMiddleware_gRPC(t *testing.T) {
+	registry := newCollectingRegistry()
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, float64(1), registry.activeStreams.value("service", "grpc", "grpc_method", "/helloworld.Greeter/SayHello"))
+
+		rw.Header().Set("Content-Type", "application/grpc")
+		rw.WriteHeader(http.StatusOK)
+
+		for _, message := range [][]byte{[]byte("foo"), []byte("bar"), nil} {
+			_, err = rw.Write(grpcMessage(message))
+			require.NoError(t, err)
+		}
+
+		// Trailer not announced in the headers.
+		rw.Header().Set(http.TrailerPrefix+"Grpc-Status", "14")
+	})
+
+	handler := NewServiceMiddleware(context.Background(), next, registry, "grpc")
+
+	body := append(grpcMessage([]byte("hello")), grpcMessage([]byte("world"))...)
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/helloworld.Greeter/SayHello", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/grpc+proto")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	assert.Equal(t, float64(0), registry.activeStreams.value("service", "grpc", "grpc_method", "/helloworld.Greeter/SayHello"))
+	assert.Equal(t, float64(2), registry.messages.value("service", "grpc", "grpc_method", "/helloworld.Greeter/SayHello", "direction", "request"))
+	assert.Equal(t, float64(3), registry.messages.value("service", "grpc", "grpc_method", "/helloworld.Greeter/SayHello", "direction", "response"))
+	assert.Equal(t, float64(1), registry.streams.value("service", "grpc", "grpc_method", "/helloworld.Greeter/SayHello", "grpc_code", "Unavailable"))
+	assert.Equal(t, float64(1), registry.streamDurations.value("service", "grpc", "grpc_method", "/helloworld.Greeter/SayHello", "grpc_code", "Unavailable"))
+
+	assert.Equal(t, float64(1), registry.reqs.value("service", "grpc", "method", http.MethodPost, "protocol", "grpc", "code", "200"))
+}
+
+func TestServiceMiddleware_notGRPC(t *testing.T) {
+	registry := newCollectingRegistry()
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler := NewServiceMiddleware(context.Background(), next, registry, "grpc-web")
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/helloworld.Greeter/SayHello", nil)
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Empty(t, registry.streams.values)
+	assert.Equal(t, float64(1), registry.reqs.value("service", "grpc-web", "method", http.MethodPost, "protocol", "http", "code", "200"))
+}
+
+func TestGRPCMethods(t *testing.T) {
+	var methods grpcMethods
+
+	assert.Equal(t, "/helloworld.Greeter/SayHello", methods.label("/helloworld.Greeter/SayHello"))
+	assert.Equal(t, "unknown", methods.label("/"))
+	assert.Equal(t, "unknown", methods.label("/foo/bar/baz"))
+	assert.Equal(t, "unknown", methods.label("/foo.Bar/Baz?qux"))
+	assert.Equal(t, "unknown", methods.label("/"+strings.Repeat("a", 256)+"/Foo"))
+
+	for i := 1; i < maxGRPCMethods; i++ {
+		assert.Equal(t, fmt.Sprintf("/foo.Bar/Baz%d", i), methods.label(fmt.Sprintf("/foo.Bar/Baz%d", i)))
+	}
+
+	assert.Equal(t, "unknown", methods.label("/foo.Bar/Qux"))
+	assert.Equal(t, "/helloworld.Greeter/SayHello", methods.label("/helloworld.Greeter/SayHello"))
+}
+
+func TestGRPCMessageCounter(t *testing.T) {
+	counter := newCollectingMetric()
+
+	var stream []byte
+	for _, size := range []int{0, 1, 4, 300, 70000} {
+		stream = append(stream, grpcMessage(bytes.Repeat([]byte("a"), size))...)
+	}
+
+	// The stream is split at arbitrary positions, including in the middle of the message prefixes.
+	messages := grpcMessageCounter{counter: collectingCounter{counter}}
+	for len(stream) > 0 {
+		n := 3
+		if n > len(stream) {
+			n = len(stream)
+		}
+
+		messages.parse(stream[:n])
+		stream = stream[n:]
+	}
+
+	assert.Equal(t, float64(5), counter.value())
+}
+
+func TestGetGRPCCode(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		header   http.Header
+		httpCode int
+		expected string
+	}{
+		{
+			desc:     "announced trailer",
+			header:   http.Header{"Grpc-Status": []string{"0"}},
+			httpCode: http.StatusOK,
+			expected: "OK",
+		},
+		{
+			desc:     "not announced trailer",
+			header:   http.Header{http.TrailerPrefix + "Grpc-Status": []string{"5"}},
+			httpCode: http.StatusOK,
+			expected: "NotFound",
+		},
+		{
+			desc:     "invalid status",
+			header:   http.Header{"Grpc-Status": []string{"foo"}},
+			httpCode: http.StatusOK,
+			expected: "Unknown",
+		},
+		{
+			desc:     "bad gateway",
+			header:   http.Header{},
+			httpCode: http.StatusBadGateway,
+			expected: "Unavailable",
+		},
+		{
+			desc:     "not found",
+			header:   http.Header{},
+			httpCode: http.StatusNotFound,
+			expected: "Unimplemented",
+		},
+		{
+			desc:     "missing status",
+			header:   http.Header{},
+			httpCode: http.StatusOK,
+			expected: "Unknown",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, getGRPCCode(test.header, test.httpCode))
+		})
+	}
+}
+
+func grpcMessage(message []byte) []byte {
+	prefix := make([]byte, grpcMessageHeaderLen)
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(message)))
+	return append(prefix, message...)
+}
+
+// collectingRegistry is a metrics.Registry collecting the values of the service metrics, by labels.
+type collectingRegistry struct {
+	metrics.Registry
+
+	reqs            *collectingMetric
+	activeStreams   *collectingMetric
+	messages        *collectingMetric
+	streamDurations *collectingMetric
+	streams         *collectingMetric
+}
+
+func newCollectingRegistry() *collectingRegistry {
+	return &collectingRegistry{
+		Registry:        metrics.NewVoidRegistry(),
+		reqs:            newCollectingMetric(),
+		activeStreams:   newCollectingMetric(),
+		messages:        newCollectingMetric(),
+		streamDurations: newCollectingMetric(),
+		streams:         newCollectingMetric(),
+	}
+}
+
+func (r *collectingRegistry) ServiceReqsCounter() gokitmetrics.Counter {
+	return collectingCounter{r.reqs}
+}
+
+func (r *collectingRegistry) ServiceGRPCActiveStreamsGauge() gokitmetrics.Gauge {
+	return collectingGauge{r.activeStreams}
+}
+
+func (r *collectingRegistry) ServiceGRPCMessagesCounter() gokitmetrics.Counter {
+	return collectingCounter{r.messages}
+}
+
+func (r *collectingRegistry) ServiceGRPCStreamDurationHistogram() metrics.ScalableHistogram {
+	return collectingHistogram{r.streamDurations}
+}
+
+func (r *collectingRegistry) ServiceGRPCStreamsCounter() gokitmetrics.Counter {
+	return collectingCounter{r.streams}
+}
+
+// collectingMetric collects the values of a metric by labels.
+type collectingMetric struct {
+	mu     *sync.Mutex
+	values map[string]float64
+	labels []string
+}
+
+func newCollectingMetric() *collectingMetric {
+	return &collectingMetric{mu: &sync.Mutex{}, values: make(map[string]float64)}
+}
+
+func (c *collectingMetric) with(labelValues ...string) *collectingMetric {
+	return &collectingMetric{
+		mu:     c.mu,
+		values: c.values,
+		labels: append(append([]string{}, c.labels...), labelValues...),
+	}
+}
+
+func (c *collectingMetric) add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[strings.Join(c.labels, ",")] += delta
+}
+
+func (c *collectingMetric) set(value float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[strings.Join(c.labels, ",")] = value
+}
+
+func (c *collectingMetric) value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.values[strings.Join(labelValues, ",")]
+}
+
+type collectingCounter struct {
+	*collectingMetric
+}
+
+func (c collectingCounter) With(labelValues ...string) gokitmetrics.Counter {
+	return collectingCounter{c.with(labelValues...)}
+}
+
+func (c collectingCounter) Add(delta float64) {
+	c.add(delta)
+}
+
+type collectingGauge struct {
+	*collectingMetric
+}
+
+func (g collectingGauge) With(labelValues ...string) gokitmetrics.Gauge {
+	return collectingGauge{g.with(labelValues...)}
+}
+
+func (g collectingGauge) Set(value float64) {
+	g.set(value)
+}
+
+func (g collectingGauge) Add(delta float64) {
+	g.add(delta)
+}
+
+// collectingHistogram collects the number of observations.
+type collectingHistogram struct {
+	*collectingMetric
+}
+
+func (h collectingHistogram) With(labelValues ...string) metrics.ScalableHistogram {
+	return collectingHistogram{h.with(labelValues...)}
+}
+
+func (h collectingHistogram) Observe(float64) {
+	h.add(1)
+}
+
+func (h collectingHistogram) ObserveFromStart(time.Time) {
+	h.add(1)
+}
@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -20,11 +21,13 @@ import (
 
 const (
 	protoHTTP      = "http"
+	protoGRPC      = "grpc"
 	protoSSE       = "sse"
 	protoWebsocket = "websocket"
 	typeName       = "Metrics"
 	nameEntrypoint = "metrics-entrypoint"
 	nameService    = "metrics-service"
+	nameRouter     = "metrics-router"
 )
 
 type metricsMiddleware struct {
@@ -34,6 +37,13 @@ type metricsMiddleware struct {
 	reqDurationHistogram metrics.ScalableHistogram
 	openConnsGauge       gokitmetrics.Gauge
 	baseLabels           []string
+
+	// gRPC metrics, only recorded on services.
+	grpcActiveStreamsGauge      gokitmetrics.Gauge
+	grpcMessagesCounter         gokitmetrics.Counter
+	grpcStreamDurationHistogram metrics.ScalableHistogram
+	grpcStreamsCounter          gokitmetrics.Counter
+	grpcMethods                 grpcMethods
 }
 
 // NewEntryPointMiddleware creates a new metrics middleware for an Entrypoint.
@@ -61,6 +71,24 @@ func NewServiceMiddleware(ctx context.Context, next http.Handler, registry metri
 		reqDurationHistogram: registry.ServiceReqDurationHistogram(),
 		openConnsGauge:       registry.ServiceOpenConnsGauge(),
 		baseLabels:           []string{"service", serviceName},
+
+		grpcActiveStreamsGauge:      registry.ServiceGRPCActiveStreamsGauge(),
+		grpcMessagesCounter:         registry.ServiceGRPCMessagesCounter(),
+		grpcStreamDurationHistogram: registry.ServiceGRPCStreamDurationHistogram(),
+		grpcStreamsCounter:          registry.ServiceGRPCStreamsCounter(),
+	}
+}
+
+// NewRouterMiddleware creates a new metrics middleware for a Router.
+// It only records the sizes of the request and response bodies.
+func NewRouterMiddleware(ctx context.Context, next http.Handler, registry metrics.Registry, routerName string) http.Handler {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, nameRouter, typeName)).Debug("Creating middleware")
+
+	return &routerMetricsMiddleware{
+		next:              next,
+		reqSizeHistogram:  registry.RouterReqSizeHistogram(),
+		respSizeHistogram: registry.RouterRespSizeHistogram(),
+		routerName:        routerName,
 	}
 }
 
@@ -71,6 +99,13 @@ func WrapEntryPointHandler(ctx context.Context, registry metrics.Registry, entry
 	}
 }
 
+// WrapRouterHandler Wraps metrics router to alice.Constructor.
+func WrapRouterHandler(ctx context.Context, registry metrics.Registry, routerName string) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		return NewRouterMiddleware(ctx, next, registry, routerName), nil
+	}
+}
+
 // WrapServiceHandler Wraps metrics service to alice.Constructor.
 func WrapServiceHandler(ctx context.Context, registry metrics.Registry, serviceName string) alice.Constructor {
 	return func(next http.Handler) (http.Handler, error) {
@@ -98,7 +133,11 @@ func (m *metricsMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 	recorder := newResponseRecorder(rw)
 	start := time.Now()
 
-	m.next.ServeHTTP(recorder, req)
+	if m.grpcStreamsCounter != nil && isGRPCRequest(req) {
+		m.serveGRPC(recorder, req)
+	} else {
+		m.next.ServeHTTP(recorder, req)
+	}
 
 	labels = append(labels, "code", strconv.Itoa(recorder.getCode()))
 
@@ -108,8 +147,43 @@ func (m *metricsMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 	m.reqsCounter.With(labels...).Add(1)
 }
 
+type routerMetricsMiddleware struct {
+	next              http.Handler
+	reqSizeHistogram  gokitmetrics.Histogram
+	respSizeHistogram gokitmetrics.Histogram
+	routerName        string
+}
+
+func (m *routerMetricsMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	body := &countingReadCloser{ReadCloser: req.Body}
+	if req.Body != nil {
+		req.Body = body
+	}
+
+	recorder := newResponseRecorder(rw)
+
+	m.next.ServeHTTP(recorder, req)
+
+	m.reqSizeHistogram.With("router", m.routerName).Observe(float64(body.size))
+	m.respSizeHistogram.With("router", m.routerName).Observe(float64(recorder.getSize()))
+}
+
+// countingReadCloser counts the bytes read from a request body.
+type countingReadCloser struct {
+	io.ReadCloser
+	size int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.size += int64(n)
+	return n, err
+}
+
 func getRequestProtocol(req *http.Request) string {
 	switch {
+	case isGRPCRequest(req):
+		return protoGRPC
 	case isWebsocketRequest(req):
 		return protoWebsocket
 	case isSSERequest(req):
@@ -1,11 +1,15 @@
 package metrics
 
 import (
+	"context"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
+	traefikmetrics "github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/go-kit/kit/metrics"
 	"github.com/stretchr/testify/assert"
 )
@@ -98,3 +102,55 @@ func TestCloseNotifier(t *testing.T) {
 		})
 	}
 }
+
+// sizeHistogram is a metrics.Histogram implementation that enables access to the observed values and LastLabelValues.
+type sizeHistogram struct {
+	Values          []float64
+	LastLabelValues []string
+}
+
+// With is there to satisfy the metrics.Histogram interface.
+func (h *sizeHistogram) With(labelValues ...string) metrics.Histogram {
+	h.LastLabelValues = labelValues
+	return h
+}
+
+// Observe is there to satisfy the metrics.Histogram interface.
+func (h *sizeHistogram) Observe(value float64) {
+	h.Values = append(h.Values, value)
+}
+
+type collectingRouterRegistry struct {
+	traefikmetrics.Registry
+	reqSizes  *sizeHistogram
+	respSizes *sizeHistogram
+}
+
+func (r *collectingRouterRegistry) RouterReqSizeHistogram() metrics.Histogram {
+	return r.reqSizes
+}
+
+func (r *collectingRouterRegistry) RouterRespSizeHistogram() metrics.Histogram {
+	return r.respSizes
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	registry := &collectingRouterRegistry{
+		Registry:  traefikmetrics.NewVoidRegistry(),
+		reqSizes:  &sizeHistogram{},
+		respSizes: &sizeHistogram{},
+	}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = ioutil.ReadAll(req.Body)
+		_, _ = rw.Write([]byte("response"))
+	})
+
+	handler := NewRouterMiddleware(context.Background(), next, registry, "foo@file")
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("request body")))
+
+	assert.Equal(t, []float64{12}, registry.reqSizes.Values)
+	assert.Equal(t, []string{"router", "foo@file"}, registry.reqSizes.LastLabelValues)
+	assert.Equal(t, []float64{8}, registry.respSizes.Values)
+	assert.Equal(t, []string{"router", "foo@file"}, registry.respSizes.LastLabelValues)
+}
@@ -4,12 +4,15 @@ import (
 	"bufio"
 	"net"
 	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/middlewares"
 )
 
 type recorder interface {
 	http.ResponseWriter
 	http.Flusher
 	getCode() int
+	getSize() int64
 }
 
 func newResponseRecorder(rw http.ResponseWriter) recorder {
@@ -28,6 +31,7 @@ func newResponseRecorder(rw http.ResponseWriter) recorder {
 type responseRecorder struct {
 	http.ResponseWriter
 	statusCode int
+	size       int64
 }
 
 type responseRecorderWithCloseNotify struct {
@@ -44,9 +48,23 @@ func (r *responseRecorder) getCode() int {
 	return r.statusCode
 }
 
+func (r *responseRecorder) getSize() int64 {
+	return r.size
+}
+
+// Write counts the bytes of the response body.
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
 // WriteHeader captures the status code for later retrieval.
 func (r *responseRecorder) WriteHeader(status int) {
 	r.ResponseWriter.WriteHeader(status)
+	if middlewares.IsInformational(status) {
+		return
+	}
 	r.statusCode = status
 }
 
@@ -0,0 +1,94 @@
+// Package oauth2clientcredentials implements a middleware injecting an OAuth2 access token,
+// obtained with the client credentials grant, into the requests forwarded to the backend.
+package oauth2clientcredentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "OAuth2ClientCredentials"
+
+	authStyleHeader = "header"
+	authStyleParams = "params"
+)
+
+type oauth2ClientCredentials struct {
+	next          http.Handler
+	name          string
+	source        *tokenSource
+	headerName    string
+	refreshBefore time.Duration
+}
+
+// New creates an OAuth2ClientCredentials middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.OAuth2ClientCredentials, name string) (http.Handler, error) {
+	log.FromContext(loggerCtx(ctx, name)).Debug("Creating middleware")
+
+	if config.TokenURL == "" || config.ClientID == "" {
+		return nil, errors.New("both the token URL and the client ID must be defined")
+	}
+
+	switch config.AuthStyle {
+	case "":
+		config.AuthStyle = authStyleHeader
+	case authStyleHeader, authStyleParams:
+	default:
+		return nil, fmt.Errorf("unknown auth style %q", config.AuthStyle)
+	}
+
+	source, err := tokenSources.get(config)
+	if err != nil {
+		return nil, err
+	}
+
+	headerName := config.HeaderName
+	if headerName == "" {
+		headerName = "Authorization"
+	}
+
+	return &oauth2ClientCredentials{
+		next:          next,
+		name:          name,
+		source:        source,
+		headerName:    http.CanonicalHeaderKey(headerName),
+		refreshBefore: time.Duration(config.RefreshBefore),
+	}, nil
+}
+
+func (o *oauth2ClientCredentials) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return o.name, tracing.SpanKindNoneEnum
+}
+
+func (o *oauth2ClientCredentials) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := loggerCtx(req.Context(), o.name)
+
+	tok, err := o.source.get(ctx, o.refreshBefore)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to get the OAuth2 token: %v", err)
+		tracing.SetErrorWithEvent(req, "Unable to get the OAuth2 token")
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+
+	if o.headerName == "Authorization" {
+		req.Header.Set(o.headerName, "Bearer "+tok.AccessToken)
+	} else {
+		req.Header.Set(o.headerName, tok.AccessToken)
+	}
+
+	o.next.ServeHTTP(rw, req)
+}
+
+func loggerCtx(ctx context.Context, name string) context.Context {
+	return log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
+}
@@ -0,0 +1,223 @@
+package oauth2clientcredentials
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTokenServer starts a token endpoint issuing numbered tokens expiring after expiresIn seconds,
+// or answering with the given status code when it is not zero.
+func startTokenServer(t *testing.T, expiresIn int, statusCode *int32) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+
+		if code := atomic.LoadInt32(statusCode); code != 0 {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(int(code))
+			_, _ = fmt.Fprint(rw, `{"error":"invalid_client","error_description":"unknown client"}`)
+			return
+		}
+
+		require.NoError(t, req.ParseForm())
+		assert.Equal(t, "client_credentials", req.PostForm.Get("grant_type"))
+
+		clientID, clientSecret, ok := req.BasicAuth()
+		if !ok {
+			clientID, clientSecret = req.PostForm.Get("client_id"), req.PostForm.Get("client_secret")
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(rw, `{"access_token":"%s-%s-%s-%d","token_type":"Bearer","expires_in":%d}`,
+			clientID, clientSecret, req.PostForm.Get("scope"), n, expiresIn)
+	})), &calls
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	var statusCode int32
+	server, calls := startTokenServer(t, 3600, &statusCode)
+	defer server.Close()
+
+	testCases := []struct {
+		desc           string
+		config         dynamic.OAuth2ClientCredentials
+		expectedHeader string
+		expectedValue  string
+	}{
+		{
+			desc: "basic authentication",
+			config: dynamic.OAuth2ClientCredentials{
+				ClientID:     "client",
+				ClientSecret: "secret",
+				Scopes:       []string{"read", "write"},
+			},
+			expectedHeader: "Authorization",
+			expectedValue:  "Bearer client-secret-read write-1",
+		},
+		{
+			desc: "parameters authentication",
+			config: dynamic.OAuth2ClientCredentials{
+				ClientID:     "client",
+				ClientSecret: "secret",
+				Scopes:       []string{"read"},
+				AuthStyle:    "params",
+			},
+			expectedHeader: "Authorization",
+			expectedValue:  "Bearer client-secret-read-2",
+		},
+		{
+			desc: "header name",
+			config: dynamic.OAuth2ClientCredentials{
+				ClientID:     "client",
+				ClientSecret: "secret",
+				Scopes:       []string{"admin"},
+				HeaderName:   "x-access-token",
+			},
+			expectedHeader: "X-Access-Token",
+			expectedValue:  "client-secret-admin-3",
+		},
+	}
+
+	// The test cases are not parallel, as the tokens are numbered.
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			test.config.TokenURL = server.URL
+
+			var value string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				value = req.Header.Get(test.expectedHeader)
+			})
+
+			handler, err := New(context.Background(), next, test.config, "oauth2")
+			require.NoError(t, err)
+
+			for i := 0; i < 2; i++ {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, test.expectedValue, value)
+			}
+		})
+	}
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestOAuth2ClientCredentials_sharedToken(t *testing.T) {
+	var statusCode int32
+	server, calls := startTokenServer(t, 3600, &statusCode)
+	defer server.Close()
+
+	config := dynamic.OAuth2ClientCredentials{
+		TokenURL: server.URL,
+		ClientID: "client",
+		Scopes:   []string{"read", "write"},
+	}
+
+	first, err := New(context.Background(), http.NotFoundHandler(), config, "oauth2")
+	require.NoError(t, err)
+
+	config.Scopes = []string{"write", "read"}
+	second, err := New(context.Background(), http.NotFoundHandler(), config, "oauth2")
+	require.NoError(t, err)
+
+	first.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	second.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestOAuth2ClientCredentials_refresh(t *testing.T) {
+	var statusCode int32
+	server, calls := startTokenServer(t, 60, &statusCode)
+	defer server.Close()
+
+	var value string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		value = req.Header.Get("Authorization")
+	})
+
+	handler, err := New(context.Background(), next, dynamic.OAuth2ClientCredentials{
+		TokenURL:      server.URL,
+		ClientID:      "client",
+		RefreshBefore: types.Duration(2 * time.Minute),
+	}, "oauth2")
+	require.NoError(t, err)
+
+	// The tokens expire within the refresh duration, so that a new token is requested each time.
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, "Bearer client---1", value)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, "Bearer client---2", value)
+
+	// The current token is used while it has not expired, when the renewal fails.
+	atomic.StoreInt32(&statusCode, http.StatusInternalServerError)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "Bearer client---2", value)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestOAuth2ClientCredentials_tokenError(t *testing.T) {
+	statusCode := int32(http.StatusUnauthorized)
+	server, _ := startTokenServer(t, 3600, &statusCode)
+	defer server.Close()
+
+	handler, err := New(context.Background(), http.NotFoundHandler(), dynamic.OAuth2ClientCredentials{
+		TokenURL: server.URL,
+		ClientID: "unknown",
+	}, "oauth2")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.OAuth2ClientCredentials
+	}{
+		{
+			desc:   "missing token URL",
+			config: dynamic.OAuth2ClientCredentials{ClientID: "client"},
+		},
+		{
+			desc:   "missing client ID",
+			config: dynamic.OAuth2ClientCredentials{TokenURL: "http://localhost/token"},
+		},
+		{
+			desc:   "unknown auth style",
+			config: dynamic.OAuth2ClientCredentials{TokenURL: "http://localhost/token", ClientID: "client", AuthStyle: "jwt"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "oauth2")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package oauth2clientcredentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+const tokenRequestTimeout = 30 * time.Second
+
+// token is an access token, which never expires when its expiry is zero.
+type token struct {
+	AccessToken string
+	Expiry      time.Time
+}
+
+// valid returns whether the token is valid for at least the given duration.
+func (t *token) valid(now time.Time, during time.Duration) bool {
+	return t != nil && (t.Expiry.IsZero() || now.Add(during).Before(t.Expiry))
+}
+
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// tokenSource fetches the access tokens of a client with the client credentials grant, and caches them.
+type tokenSource struct {
+	tokenURL       string
+	clientID       string
+	clientSecret   string
+	scopes         []string
+	endpointParams map[string]string
+	paramsAuth     bool
+	client         *http.Client
+
+	mu    sync.Mutex
+	token *token
+}
+
+// get returns the cached token, or a new one when the cached token expires within refreshBefore.
+// The cached token is still returned if it has not expired yet, when a new one cannot be fetched.
+func (s *tokenSource) get(ctx context.Context, refreshBefore time.Duration) (*token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.token.valid(now, refreshBefore) {
+		return s.token, nil
+	}
+
+	tok, err := s.fetch(ctx)
+	if err != nil {
+		if s.token.valid(now, 0) {
+			log.FromContext(ctx).Warnf("Unable to renew the OAuth2 token, using the current one until it expires: %v", err)
+			return s.token, nil
+		}
+		return nil, err
+	}
+
+	s.token = tok
+
+	return tok, nil
+}
+
+func (s *tokenSource) fetch(ctx context.Context) (*token, error) {
+	params := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.scopes) > 0 {
+		params.Set("scope", strings.Join(s.scopes, " "))
+	}
+	for name, value := range s.endpointParams {
+		params.Set(name, value)
+	}
+	if s.paramsAuth {
+		params.Set("client_id", s.clientID)
+		params.Set("client_secret", s.clientSecret)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tokenRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, s.tokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !s.paramsAuth {
+		req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to request a token from %s: %w", s.tokenURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the token response: %w", err)
+	}
+
+	tokenResp := tokenResponse{}
+	if err := json.Unmarshal(body, &tokenResp); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("unable to parse the token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || tokenResp.Error != "" {
+		if tokenResp.Error != "" {
+			return nil, fmt.Errorf("token request failed with the status code %d: %s: %s", resp.StatusCode, tokenResp.Error, tokenResp.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token request failed with the status code %d", resp.StatusCode)
+	}
+
+	if tokenResp.AccessToken == "" {
+		return nil, errors.New("no access token in the token response")
+	}
+
+	if tokenResp.TokenType != "" && !strings.EqualFold(tokenResp.TokenType, "bearer") {
+		return nil, fmt.Errorf("unsupported token type %q", tokenResp.TokenType)
+	}
+
+	tok := &token{AccessToken: tokenResp.AccessToken}
+	if tokenResp.ExpiresIn != "" {
+		expiresIn, err := tokenResp.ExpiresIn.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid token expiration %q: %w", tokenResp.ExpiresIn, err)
+		}
+		if expiresIn > 0 {
+			tok.Expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+		}
+	}
+
+	return tok, nil
+}
+
+var tokenSources = &tokenSourcePool{sources: make(map[string]*tokenSource)}
+
+// tokenSourcePool shares the token sources between the middlewares having the same client and scopes,
+// as the middlewares are created again on each configuration change, and for each router using them.
+type tokenSourcePool struct {
+	mu      sync.Mutex
+	sources map[string]*tokenSource
+}
+
+func (p *tokenSourcePool) get(config dynamic.OAuth2ClientCredentials) (*tokenSource, error) {
+	scopes := make([]string, len(config.Scopes))
+	copy(scopes, config.Scopes)
+	sort.Strings(scopes)
+
+	params := make([]string, 0, len(config.EndpointParams))
+	for name, value := range config.EndpointParams {
+		params = append(params, name+"="+value)
+	}
+	sort.Strings(params)
+
+	key := strings.Join([]string{config.TokenURL, config.ClientID, config.ClientSecret, strings.Join(scopes, " "), strings.Join(params, "&"), config.AuthStyle}, "|")
+	if config.TLS != nil {
+		key += fmt.Sprintf("|%+v", *config.TLS)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if source, ok := p.sources[key]; ok {
+		return source, nil
+	}
+
+	client := &http.Client{}
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.CreateTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
+
+	source := &tokenSource{
+		tokenURL:       config.TokenURL,
+		clientID:       config.ClientID,
+		clientSecret:   config.ClientSecret,
+		scopes:         config.Scopes,
+		endpointParams: config.EndpointParams,
+		paramsAuth:     config.AuthStyle == authStyleParams,
+		client:         client,
+	}
+	p.sources[key] = source
+
+	return source, nil
+}
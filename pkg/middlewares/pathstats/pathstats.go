@@ -0,0 +1,109 @@
+package pathstats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/containous/alice"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+)
+
+const (
+	typeName = "PathStatistics"
+	name     = "pathstats-router"
+)
+
+// pathStatistics is a middleware recording the bandwidth used by each path of a router.
+type pathStatistics struct {
+	next       http.Handler
+	statistics *Statistics
+	routerName string
+}
+
+// New creates a new path statistics middleware for a router.
+func New(ctx context.Context, next http.Handler, statistics *Statistics, routerName string) http.Handler {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	return &pathStatistics{
+		next:       next,
+		statistics: statistics,
+		routerName: routerName,
+	}
+}
+
+// WrapRouterHandler Wraps path statistics to alice.Constructor.
+func WrapRouterHandler(ctx context.Context, statistics *Statistics, routerName string) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		return New(ctx, next, statistics, routerName), nil
+	}
+}
+
+func (p *pathStatistics) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The path is read before the next handlers, which may rewrite it.
+	path := req.URL.Path
+
+	body := &countingReadCloser{ReadCloser: req.Body}
+	if req.Body != nil {
+		req.Body = body
+	}
+
+	recorder := &countingResponseWriter{ResponseWriter: rw}
+
+	p.next.ServeHTTP(recorder, req)
+
+	p.statistics.record(p.routerName, path, body.size, recorder.size)
+}
+
+// countingReadCloser counts the bytes read from a request body.
+type countingReadCloser struct {
+	io.ReadCloser
+	size int64
+}
+
+func (r *countingReadCloser) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.size += int64(n)
+	return n, err
+}
+
+// countingResponseWriter counts the bytes of a response body.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	size int64
+}
+
+func (w *countingResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Hijack hijacks the connection.
+func (w *countingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+	}
+	return h.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (w *countingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (w *countingResponseWriter) CloseNotify() <-chan bool {
+	if c, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return c.CloseNotify()
+	}
+	return make(<-chan bool)
+}
@@ -0,0 +1,38 @@
+package pathstats
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathStatistics(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = ioutil.ReadAll(req.Body)
+
+		// The next handlers may rewrite the path.
+		req.URL.Path = "/rewritten"
+
+		_, _ = rw.Write([]byte("response"))
+	})
+
+	statistics := NewStatistics(10)
+	handler := New(context.Background(), next, statistics, "foo@file")
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/upload?foo=bar", strings.NewReader("request body"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	top, ok := statistics.TopPaths("foo@file")
+	require.True(t, ok)
+
+	expected := []PathStats{
+		{Path: "/upload", Requests: 1, RequestBytes: 12, ResponseBytes: 8},
+	}
+	assert.Equal(t, expected, top)
+}
@@ -0,0 +1,189 @@
+package pathstats
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+// PathStats holds the traffic statistics of a path.
+// As the paths are tracked with the Space-Saving algorithm, the values are estimations,
+// which overestimate the real values by at most Error bytes.
+type PathStats struct {
+	Path          string `json:"path"`
+	Requests      int64  `json:"requests"`
+	RequestBytes  int64  `json:"requestBytes"`
+	ResponseBytes int64  `json:"responseBytes"`
+	Error         int64  `json:"error"`
+}
+
+// Statistics tracks the paths using the most bandwidth, for each router.
+type Statistics struct {
+	maxPaths int
+
+	mu       sync.RWMutex
+	trackers map[string]*tracker
+}
+
+// NewStatistics creates a new Statistics, tracking at most maxPaths paths per router.
+func NewStatistics(maxPaths int) *Statistics {
+	if maxPaths < 1 {
+		maxPaths = 1
+	}
+
+	return &Statistics{
+		maxPaths: maxPaths,
+		trackers: make(map[string]*tracker),
+	}
+}
+
+// TopPaths returns the statistics of the paths using the most bandwidth on the given router,
+// the most used first.
+func (s *Statistics) TopPaths(routerName string) ([]PathStats, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	s.mu.RLock()
+	t, ok := s.trackers[routerName]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	return t.top(), true
+}
+
+// Retain drops the statistics of the routers which are not in the given list.
+func (s *Statistics) Retain(routerNames []string) {
+	routers := make(map[string]struct{}, len(routerNames))
+	for _, name := range routerNames {
+		routers[name] = struct{}{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range s.trackers {
+		if _, ok := routers[name]; !ok {
+			delete(s.trackers, name)
+		}
+	}
+}
+
+func (s *Statistics) record(routerName, path string, requestBytes, responseBytes int64) {
+	s.mu.RLock()
+	t, ok := s.trackers[routerName]
+	s.mu.RUnlock()
+
+	if !ok {
+		s.mu.Lock()
+		t, ok = s.trackers[routerName]
+		if !ok {
+			t = newTracker(s.maxPaths)
+			s.trackers[routerName] = t
+		}
+		s.mu.Unlock()
+	}
+
+	t.record(path, requestBytes, responseBytes)
+}
+
+// tracker is a bounded top-N tracker of the paths, by bandwidth, using the Space-Saving algorithm:
+// when it is full, the least used path is replaced by the new one, which inherits its counts as error.
+type tracker struct {
+	mu      sync.Mutex
+	max     int
+	entries entryHeap
+	byPath  map[string]*entry
+}
+
+type entry struct {
+	PathStats
+	index int
+}
+
+func newTracker(max int) *tracker {
+	return &tracker{
+		max:    max,
+		byPath: make(map[string]*entry),
+	}
+}
+
+func (t *tracker) record(path string, requestBytes, responseBytes int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.byPath[path]
+	switch {
+	case ok:
+	case len(t.entries) < t.max:
+		e = &entry{PathStats: PathStats{Path: path}}
+		t.byPath[path] = e
+		heap.Push(&t.entries, e)
+	default:
+		// Replaces the least used path, keeping its counts as the error of the new one.
+		e = t.entries[0]
+		delete(t.byPath, e.Path)
+		e.Path = path
+		e.Error = e.bytes()
+		t.byPath[path] = e
+	}
+
+	e.Requests++
+	e.RequestBytes += requestBytes
+	e.ResponseBytes += responseBytes
+	heap.Fix(&t.entries, e.index)
+}
+
+func (t *tracker) top() []PathStats {
+	t.mu.Lock()
+	stats := make([]PathStats, 0, len(t.entries))
+	for _, e := range t.entries {
+		stats = append(stats, e.PathStats)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		bi := stats[i].RequestBytes + stats[i].ResponseBytes
+		bj := stats[j].RequestBytes + stats[j].ResponseBytes
+		if bi != bj {
+			return bi > bj
+		}
+		return stats[i].Path < stats[j].Path
+	})
+
+	return stats
+}
+
+func (e *entry) bytes() int64 {
+	return e.RequestBytes + e.ResponseBytes
+}
+
+// entryHeap is a min-heap of the entries, by bandwidth.
+type entryHeap []*entry
+
+func (h entryHeap) Len() int { return len(h) }
+
+func (h entryHeap) Less(i, j int) bool { return h[i].bytes() < h[j].bytes() }
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
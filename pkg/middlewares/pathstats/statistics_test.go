@@ -0,0 +1,72 @@
+package pathstats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatistics_TopPaths(t *testing.T) {
+	statistics := NewStatistics(2)
+
+	_, ok := statistics.TopPaths("foo@file")
+	assert.False(t, ok)
+
+	statistics.record("foo@file", "/small", 0, 10)
+	statistics.record("foo@file", "/large", 100, 1000)
+	statistics.record("foo@file", "/small", 5, 10)
+	statistics.record("bar@file", "/other", 0, 1)
+
+	top, ok := statistics.TopPaths("foo@file")
+	require.True(t, ok)
+
+	expected := []PathStats{
+		{Path: "/large", Requests: 1, RequestBytes: 100, ResponseBytes: 1000},
+		{Path: "/small", Requests: 2, RequestBytes: 5, ResponseBytes: 20},
+	}
+	assert.Equal(t, expected, top)
+
+	// The least used path is replaced, and its counts are kept as the error of the new one.
+	statistics.record("foo@file", "/new", 0, 50)
+
+	top, ok = statistics.TopPaths("foo@file")
+	require.True(t, ok)
+
+	expected = []PathStats{
+		{Path: "/large", Requests: 1, RequestBytes: 100, ResponseBytes: 1000},
+		{Path: "/new", Requests: 3, RequestBytes: 5, ResponseBytes: 70, Error: 25},
+	}
+	assert.Equal(t, expected, top)
+}
+
+func TestStatistics_heavyHitters(t *testing.T) {
+	statistics := NewStatistics(10)
+
+	for i := 0; i < 1000; i++ {
+		statistics.record("foo@file", "/heavy", 0, 1000)
+		statistics.record("foo@file", "/path/"+string(rune('a'+i%26))+string(rune('a'+i/26%26)), 0, 10)
+	}
+
+	top, ok := statistics.TopPaths("foo@file")
+	require.True(t, ok)
+	require.Len(t, top, 10)
+
+	assert.Equal(t, "/heavy", top[0].Path)
+	assert.Equal(t, int64(1000000), top[0].ResponseBytes)
+}
+
+func TestStatistics_Retain(t *testing.T) {
+	statistics := NewStatistics(10)
+
+	statistics.record("foo@file", "/", 0, 10)
+	statistics.record("bar@file", "/", 0, 10)
+
+	statistics.Retain([]string{"foo@file"})
+
+	_, ok := statistics.TopPaths("foo@file")
+	assert.True(t, ok)
+
+	_, ok = statistics.TopPaths("bar@file")
+	assert.False(t, ok)
+}
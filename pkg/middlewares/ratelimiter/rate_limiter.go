@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/gossip"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/tracing"
@@ -35,16 +37,29 @@ type rateLimiter struct {
 	next          http.Handler
 
 	buckets *ttlmap.TtlMap // actual buckets, keyed by source.
+
+	// shared is the state shared with the other instances, whose requests are taken from the buckets too.
+	shared *gossip.State
+}
+
+// bucket is the token bucket of a source, along with the requests of the source already counted from the other instances.
+type bucket struct {
+	*rate.Limiter
+
+	mu         sync.Mutex
+	remoteHits int64
 }
 
 // New returns a rate limiter middleware.
-func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name string) (http.Handler, error) {
+// When the state is shared with the other instances, their requests count toward the rate limit too.
+func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name string, shared *gossip.State) (http.Handler, error) {
 	ctxLog := log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
 	log.FromContext(ctxLog).Debug("Creating middleware")
 
 	if config.SourceCriterion == nil ||
 		config.SourceCriterion.IPStrategy == nil &&
-			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost {
+			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost &&
+			config.SourceCriterion.APIKey == "" {
 		config.SourceCriterion = &dynamic.SourceCriterion{
 			IPStrategy: &dynamic.IPStrategy{},
 		}
@@ -94,6 +109,7 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		next:          next,
 		sourceMatcher: sourceMatcher,
 		buckets:       buckets,
+		shared:        shared,
 	}, nil
 }
 
@@ -116,19 +132,24 @@ func (rl *rateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		logger.Infof("ignoring token bucket amount > 1: %d", amount)
 	}
 
-	var bucket *rate.Limiter
+	var bkt *bucket
 	if rlSource, exists := rl.buckets.Get(source); exists {
-		bucket = rlSource.(*rate.Limiter)
+		bkt = rlSource.(*bucket)
+		rl.takeRemoteHits(bkt, source)
 	} else {
-		bucket = rate.NewLimiter(rl.rate, int(rl.burst))
-		if err := rl.buckets.Set(source, bucket, int(rl.maxDelay)*10+1); err != nil {
+		bkt = &bucket{Limiter: rate.NewLimiter(rl.rate, int(rl.burst))}
+		// The requests counted before the bucket creation are not taken from it.
+		if rl.shared != nil {
+			bkt.remoteHits = rl.shared.RemoteHits(rl.sharedKey(source))
+		}
+		if err := rl.buckets.Set(source, bkt, int(rl.maxDelay)*10+1); err != nil {
 			logger.Errorf("could not insert bucket: %v", err)
 			http.Error(w, "could not insert bucket", http.StatusInternalServerError)
 			return
 		}
 	}
 
-	res := bucket.Reserve()
+	res := bkt.Reserve()
 	if !res.OK() {
 		http.Error(w, "No bursty traffic allowed", http.StatusTooManyRequests)
 		return
@@ -141,10 +162,43 @@ func (rl *rateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if rl.shared != nil {
+		rl.shared.AddHits(rl.sharedKey(source), 1)
+	}
+
 	time.Sleep(delay)
 	rl.next.ServeHTTP(w, r)
 }
 
+func (rl *rateLimiter) sharedKey(source string) string {
+	return rl.name + "/" + source
+}
+
+// takeRemoteHits takes from the bucket the tokens of the requests of the source counted by the other instances since the last request.
+// The bucket may end up in debt, which delays, and therefore rejects, the next requests.
+func (rl *rateLimiter) takeRemoteHits(bkt *bucket, source string) {
+	if rl.shared == nil {
+		return
+	}
+
+	remoteHits := rl.shared.RemoteHits(rl.sharedKey(source))
+
+	bkt.mu.Lock()
+	hits := remoteHits - bkt.remoteHits
+	bkt.remoteHits = remoteHits
+	bkt.mu.Unlock()
+
+	if hits <= 0 {
+		return
+	}
+
+	// A reservation of more tokens than the burst is never granted.
+	if hits > rl.burst {
+		hits = rl.burst
+	}
+	bkt.ReserveN(time.Now(), int(hits))
+}
+
 func (rl *rateLimiter) serveDelayError(ctx context.Context, w http.ResponseWriter, r *http.Request, delay time.Duration) {
 	w.Header().Set("Retry-After", fmt.Sprintf("%.0f", delay.Seconds()))
 	w.Header().Set("X-Retry-In", delay.String())
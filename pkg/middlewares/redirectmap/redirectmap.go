@@ -0,0 +1,147 @@
+// Package redirectmap implements a middleware redirecting the requests according to large host and path maps,
+// declared in the dynamic configuration, or loaded from a file or a KV store.
+package redirectmap
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares/requestdecorator"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const typeName = "RedirectMap"
+
+type redirectMap struct {
+	next       http.Handler
+	name       string
+	statusCode int
+	entries    *trie
+	table      *table
+	hits       gokitmetrics.Counter
+}
+
+// New creates a RedirectMap middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.RedirectMap, name string, metricsRegistry metrics.Registry) (http.Handler, error) {
+	log.FromContext(loggerCtx(ctx, name)).Debug("Creating middleware")
+
+	statusCode := config.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusMovedPermanently
+	}
+
+	if !validStatusCode(statusCode) {
+		return nil, fmt.Errorf("unsupported status code: %d", statusCode)
+	}
+
+	entries, err := newTrie(config.Entries)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := getTable(name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &redirectMap{
+		next:       next,
+		name:       name,
+		statusCode: statusCode,
+		entries:    entries,
+		table:      t,
+		hits:       metricsRegistry.RedirectMapHitsCounter(),
+	}, nil
+}
+
+func (r *redirectMap) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return r.name, tracing.SpanKindNoneEnum
+}
+
+func (r *redirectMap) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	host := requestdecorator.GetCanonizedHost(req.Context())
+	if host == "" {
+		host = hostWithoutPort(req.Host)
+	}
+	host = canonicalHost(host)
+
+	// The entries of the dynamic configuration win over the ones of the source.
+	entry, rest := r.entries.lookup(host, req.URL.Path)
+	if entry == nil && r.table != nil {
+		entry, rest = r.table.get().lookup(host, req.URL.Path)
+	}
+
+	if entry == nil {
+		r.next.ServeHTTP(rw, req)
+		return
+	}
+
+	statusCode := entry.StatusCode
+	if statusCode == 0 {
+		statusCode = r.statusCode
+	}
+
+	r.hits.With("middleware", r.name, "code", strconv.Itoa(statusCode)).Add(1)
+
+	if statusCode == http.StatusGone {
+		log.FromContext(loggerCtx(req.Context(), r.name)).Debugf("Gone: %s%s", host, req.URL.Path)
+		http.Error(rw, http.StatusText(statusCode), statusCode)
+		return
+	}
+
+	location := target(entry.Target, rest, req.URL.RawQuery)
+
+	log.FromContext(loggerCtx(req.Context(), r.name)).Debugf("Redirecting %s%s to %s", host, req.URL.Path, location)
+
+	rw.Header().Set("Location", location)
+	rw.WriteHeader(statusCode)
+	_, _ = rw.Write([]byte(http.StatusText(statusCode)))
+}
+
+// target returns the location of the redirection: the target of the entry,
+// followed by the remainder of the path under the prefix of the entry,
+// and by the query of the request, unless the target has its own query.
+func target(entryTarget, rest, rawQuery string) string {
+	location := entryTarget
+
+	if rest != "" {
+		var query string
+		if i := strings.IndexByte(location, '?'); i >= 0 {
+			location, query = location[:i], location[i:]
+		}
+
+		location = strings.TrimSuffix(location, "/") + (&url.URL{Path: rest}).EscapedPath() + query
+	}
+
+	if rawQuery != "" && !strings.Contains(location, "?") {
+		location += "?" + rawQuery
+	}
+
+	return location
+}
+
+func hostWithoutPort(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func loggerCtx(ctx context.Context, name string) context.Context {
+	return log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
+}
@@ -0,0 +1,283 @@
+package redirectmap
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hitsRegistry struct {
+	metrics.Registry
+	hits *testhelpers.CollectingCounter
+}
+
+func (r *hitsRegistry) RedirectMapHitsCounter() gokitmetrics.Counter {
+	return r.hits
+}
+
+func TestRedirectMap(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		config           dynamic.RedirectMap
+		url              string
+		expectedStatus   int
+		expectedLocation string
+		expectedLabels   []string
+	}{
+		{
+			desc: "default status code",
+			config: dynamic.RedirectMap{
+				Entries: []dynamic.RedirectMapEntry{{Host: "old.com", Path: "/about", Target: "https://new.com/company"}},
+			},
+			url:              "http://old.com/about",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://new.com/company",
+			expectedLabels:   []string{"middleware", "test", "code", "301"},
+		},
+		{
+			desc: "status code of the middleware",
+			config: dynamic.RedirectMap{
+				StatusCode: http.StatusFound,
+				Entries:    []dynamic.RedirectMapEntry{{Path: "/about", Target: "/company"}},
+			},
+			url:              "http://old.com:8080/about",
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "/company",
+			expectedLabels:   []string{"middleware", "test", "code", "302"},
+		},
+		{
+			desc: "status code of the entry",
+			config: dynamic.RedirectMap{
+				StatusCode: http.StatusFound,
+				Entries:    []dynamic.RedirectMapEntry{{Path: "/about", Target: "/company", StatusCode: http.StatusPermanentRedirect}},
+			},
+			url:              "http://old.com/about",
+			expectedStatus:   http.StatusPermanentRedirect,
+			expectedLocation: "/company",
+			expectedLabels:   []string{"middleware", "test", "code", "308"},
+		},
+		{
+			desc: "prefix with the query of the request",
+			config: dynamic.RedirectMap{
+				Entries: []dynamic.RedirectMapEntry{{Host: "old.com", Path: "/blog", Target: "https://blog.new.com/", Prefix: true}},
+			},
+			url:              "http://old.com/blog/2019/hello%20world?page=2",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://blog.new.com/2019/hello%20world?page=2",
+			expectedLabels:   []string{"middleware", "test", "code", "301"},
+		},
+		{
+			desc: "target with its own query",
+			config: dynamic.RedirectMap{
+				Entries: []dynamic.RedirectMapEntry{{Path: "/blog", Target: "https://new.com/posts?from=old", Prefix: true}},
+			},
+			url:              "http://old.com/blog/hello?page=2",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://new.com/posts/hello?from=old",
+			expectedLabels:   []string{"middleware", "test", "code", "301"},
+		},
+		{
+			desc: "gone",
+			config: dynamic.RedirectMap{
+				Entries: []dynamic.RedirectMapEntry{{Path: "/discontinued", StatusCode: http.StatusGone}},
+			},
+			url:            "http://old.com/discontinued",
+			expectedStatus: http.StatusGone,
+			expectedLabels: []string{"middleware", "test", "code", "410"},
+		},
+		{
+			desc: "no matching entry",
+			config: dynamic.RedirectMap{
+				Entries: []dynamic.RedirectMapEntry{{Host: "old.com", Path: "/about", Target: "https://new.com/company"}},
+			},
+			url:            "http://new.com/about",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			registry := &hitsRegistry{Registry: metrics.NewVoidRegistry(), hits: &testhelpers.CollectingCounter{}}
+
+			handler, err := New(context.Background(), next, test.config, "test", registry)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.url, nil))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedLocation, recorder.Header().Get("Location"))
+			assert.Equal(t, test.expectedLabels, registry.hits.LastLabelValues)
+		})
+	}
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.RedirectMap
+	}{
+		{
+			desc:   "unsupported status code",
+			config: dynamic.RedirectMap{StatusCode: http.StatusOK},
+		},
+		{
+			desc:   "invalid entry",
+			config: dynamic.RedirectMap{Entries: []dynamic.RedirectMapEntry{{Path: "/foo"}}},
+		},
+		{
+			desc: "file and kv",
+			config: dynamic.RedirectMap{
+				File: &dynamic.RedirectMapFile{Filename: "redirects.csv"},
+				KV:   &dynamic.RedirectMapKV{Backend: "consul"},
+			},
+		},
+		{
+			desc:   "missing file",
+			config: dynamic.RedirectMap{File: &dynamic.RedirectMapFile{Filename: "/does/not/exist.csv"}},
+		},
+		{
+			desc:   "unsupported KV backend",
+			config: dynamic.RedirectMap{KV: &dynamic.RedirectMapKV{Backend: "boltdb"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "test-invalid-"+test.desc, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRedirectMap_file(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filename string
+		content  string
+		modified string
+	}{
+		{
+			desc:     "CSV",
+			filename: "redirects.csv",
+			content:  "# host,path,target,statusCode,prefix\nold.com,/about,https://new.com/company\n,/blog,https://blog.new.com,302,true\n",
+			modified: "old.com,/about,https://new.com/team\n",
+		},
+		{
+			desc:     "JSON",
+			filename: "redirects.json",
+			content:  `[{"host":"old.com","path":"/about","target":"https://new.com/company"},{"path":"/blog","target":"https://blog.new.com","statusCode":302,"prefix":true}]`,
+			modified: `[{"host":"old.com","path":"/about","target":"https://new.com/team"}]`,
+		},
+		{
+			desc:     "YAML",
+			filename: "redirects.yaml",
+			content:  "- host: old.com\n  path: /about\n  target: https://new.com/company\n- path: /blog\n  target: https://blog.new.com\n  statusCode: 302\n  prefix: true\n",
+			modified: "- host: old.com\n  path: /about\n  target: https://new.com/team\n",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.Join(createTempDir(t), test.filename)
+			require.NoError(t, ioutil.WriteFile(filename, []byte(test.content), 0o600))
+
+			config := dynamic.RedirectMap{
+				Entries: []dynamic.RedirectMapEntry{{Path: "/blog/featured", Target: "https://new.com/featured"}},
+				File:    &dynamic.RedirectMapFile{Filename: filename},
+			}
+
+			handler, err := New(context.Background(), http.NotFoundHandler(), config, "test-file-"+test.desc, nil)
+			require.NoError(t, err)
+
+			assertRedirect(t, handler, "http://old.com/about", http.StatusMovedPermanently, "https://new.com/company")
+			assertRedirect(t, handler, "http://other.com/blog/hello", http.StatusFound, "https://blog.new.com/hello")
+			assertRedirect(t, handler, "http://other.com/blog/featured", http.StatusMovedPermanently, "https://new.com/featured")
+
+			// The table is kept across the configuration reloads.
+			table, err := getTable("test-file-"+test.desc, config)
+			require.NoError(t, err)
+			assert.Same(t, handler.(*redirectMap).table, table)
+
+			require.NoError(t, ioutil.WriteFile(filename, []byte(test.modified), 0o600))
+			modTime := time.Now().Add(time.Minute)
+			require.NoError(t, os.Chtimes(filename, modTime, modTime))
+
+			table.mu.Lock()
+			table.checkTime = time.Time{}
+			table.mu.Unlock()
+
+			assert.Eventually(t, func() bool {
+				entry, _ := table.get().lookup("old.com", "/about")
+				return entry != nil && entry.Target == "https://new.com/team"
+			}, 5*time.Second, 10*time.Millisecond)
+
+			assertRedirect(t, handler, "http://old.com/about", http.StatusMovedPermanently, "https://new.com/team")
+			assertRedirect(t, handler, "http://other.com/blog/hello", http.StatusNotFound, "")
+
+			// An invalid file does not replace the previous entries.
+			require.NoError(t, ioutil.WriteFile(filename, []byte("{"), 0o600))
+			modTime = modTime.Add(time.Minute)
+			require.NoError(t, os.Chtimes(filename, modTime, modTime))
+
+			table.mu.Lock()
+			table.checkTime = time.Time{}
+			table.mu.Unlock()
+
+			table.get()
+			assert.Eventually(t, func() bool {
+				table.mu.Lock()
+				defer table.mu.Unlock()
+				return !table.loading
+			}, 5*time.Second, 10*time.Millisecond)
+
+			assertRedirect(t, handler, "http://old.com/about", http.StatusMovedPermanently, "https://new.com/team")
+		})
+	}
+}
+
+func assertRedirect(t *testing.T, handler http.Handler, url string, expectedStatus int, expectedLocation string) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+	assert.Equal(t, expectedStatus, recorder.Code)
+	assert.Equal(t, expectedLocation, recorder.Header().Get("Location"))
+}
+
+func createTempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "traefik_redirectmap")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return dir
+}
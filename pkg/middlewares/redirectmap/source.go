@@ -0,0 +1,159 @@
+package redirectmap
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// source holds the entries of a redirect map outside of the dynamic configuration.
+type source interface {
+	// modified reports whether the entries may have changed since the last load.
+	modified() (bool, error)
+	// load returns all the entries.
+	load() ([]dynamic.RedirectMapEntry, error)
+}
+
+// table holds the trie of the entries of a source, reloaded in the background when the source changes.
+type table struct {
+	name          string
+	source        source
+	checkInterval time.Duration
+
+	trie atomic.Value // *trie
+
+	mu        sync.Mutex
+	checkTime time.Time
+	loading   bool
+}
+
+func newTable(name string, src source, checkInterval time.Duration) (*table, error) {
+	t := &table{name: name, source: src, checkInterval: checkInterval, checkTime: time.Now()}
+
+	entries, err := src.load()
+	if err != nil {
+		return nil, err
+	}
+
+	tr, err := newTrie(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	t.trie.Store(tr)
+
+	return t, nil
+}
+
+// get returns the current trie, and triggers the reload of the source if it is due.
+// The requests are never blocked by a reload: they get the previous trie until the reload is complete.
+func (t *table) get() *trie {
+	t.mu.Lock()
+	due := !t.loading && time.Since(t.checkTime) >= t.checkInterval
+	if due {
+		t.loading = true
+		t.checkTime = time.Now()
+	}
+	t.mu.Unlock()
+
+	if due {
+		go t.reload()
+	}
+
+	return t.trie.Load().(*trie)
+}
+
+// reload loads the source again if it has been modified.
+// If the source cannot be loaded, the previous entries are kept.
+func (t *table) reload() {
+	defer func() {
+		t.mu.Lock()
+		t.loading = false
+		t.mu.Unlock()
+	}()
+
+	logger := log.FromContext(loggerCtx(context.Background(), t.name))
+
+	modified, err := t.source.modified()
+	if err != nil {
+		logger.Errorf("Error while checking the redirect map: %v", err)
+		return
+	}
+
+	if !modified {
+		return
+	}
+
+	entries, err := t.source.load()
+	if err != nil {
+		logger.Errorf("Error while loading the redirect map, keeping the previous entries: %v", err)
+		return
+	}
+
+	tr, err := newTrie(entries)
+	if err != nil {
+		logger.Errorf("Invalid redirect map, keeping the previous entries: %v", err)
+		return
+	}
+
+	t.trie.Store(tr)
+
+	logger.Debugf("Redirect map reloaded with %d entries", tr.size)
+}
+
+// tables holds the tables of the RedirectMap middlewares, by middleware name,
+// so that the large maps are not loaded again on each configuration reload.
+var tables = tableRegistry{tables: make(map[string]*registeredTable)}
+
+type tableRegistry struct {
+	mu     sync.Mutex
+	tables map[string]*registeredTable
+}
+
+type registeredTable struct {
+	config dynamic.RedirectMap
+	table  *table
+}
+
+// getTable returns the table of the source of a RedirectMap middleware, or nil if it has no source.
+// The table is created on the first call, and when the source configuration of the middleware changes.
+func getTable(middlewareName string, config dynamic.RedirectMap) (*table, error) {
+	// Only the source configuration matters.
+	config = dynamic.RedirectMap{File: config.File, KV: config.KV}
+
+	if config.File == nil && config.KV == nil {
+		return nil, nil
+	}
+
+	if config.File != nil && config.KV != nil {
+		return nil, errors.New("file and kv are mutually exclusive")
+	}
+
+	tables.mu.Lock()
+	defer tables.mu.Unlock()
+
+	if registered, ok := tables.tables[middlewareName]; ok && reflect.DeepEqual(registered.config, config) {
+		return registered.table, nil
+	}
+
+	var t *table
+	var err error
+	if config.File != nil {
+		t, err = newTable(middlewareName, newFileSource(config.File.Filename), fileCheckInterval)
+	} else {
+		t, err = newKVTable(middlewareName, *config.KV)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tables.tables[middlewareName] = &registeredTable{config: *config.DeepCopy(), table: t}
+
+	return t, nil
+}
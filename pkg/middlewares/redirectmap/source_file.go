@@ -0,0 +1,116 @@
+package redirectmap
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"gopkg.in/yaml.v2"
+)
+
+// fileCheckInterval is the minimum duration between two checks of the modification of the file.
+const fileCheckInterval = time.Second
+
+// fileSource holds the entries in a JSON, YAML, or CSV file, depending on its extension.
+type fileSource struct {
+	filename string
+	modTime  time.Time
+}
+
+func newFileSource(filename string) *fileSource {
+	return &fileSource{filename: filename}
+}
+
+func (s *fileSource) modified() (bool, error) {
+	info, err := os.Stat(s.filename)
+	if err != nil {
+		return false, err
+	}
+
+	return !info.ModTime().Equal(s.modTime), nil
+}
+
+func (s *fileSource) load() ([]dynamic.RedirectMapEntry, error) {
+	info, err := os.Stat(s.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(s.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []dynamic.RedirectMapEntry
+	switch strings.ToLower(filepath.Ext(s.filename)) {
+	case ".json":
+		err = json.Unmarshal(content, &entries)
+	case ".csv":
+		entries, err = parseCSV(content)
+	default:
+		err = yaml.Unmarshal(content, &entries)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.modTime = info.ModTime()
+
+	return entries, nil
+}
+
+// parseCSV parses the entries of a CSV file, whose records are: host,path,target[,statusCode[,prefix]].
+// The empty lines, and the lines starting with #, are ignored.
+func parseCSV(content []byte) ([]dynamic.RedirectMapEntry, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	var entries []dynamic.RedirectMapEntry
+	for i := 1; ; i++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) < 3 || len(record) > 5 {
+			return nil, fmt.Errorf("record %d: expected 3 to 5 fields, got %d", i, len(record))
+		}
+
+		entry := dynamic.RedirectMapEntry{
+			Host:   record[0],
+			Path:   record[1],
+			Target: record[2],
+		}
+
+		if len(record) > 3 && record[3] != "" {
+			entry.StatusCode, err = strconv.Atoi(record[3])
+			if err != nil {
+				return nil, fmt.Errorf("record %d: invalid status code: %w", i, err)
+			}
+		}
+
+		if len(record) > 4 && record[4] != "" {
+			entry.Prefix, err = strconv.ParseBool(record[4])
+			if err != nil {
+				return nil, fmt.Errorf("record %d: invalid prefix: %w", i, err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+}
@@ -0,0 +1,94 @@
+package redirectmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/provider/kv"
+)
+
+const (
+	defaultRootKey         = "traefik/redirects"
+	defaultRefreshInterval = 30 * time.Second
+)
+
+// kvSource holds the entries in a KV store, as JSON values under the root key.
+type kvSource struct {
+	client  store.Store
+	rootKey string
+}
+
+func newKVTable(name string, config dynamic.RedirectMapKV) (*table, error) {
+	var backend store.Backend
+	switch config.Backend {
+	case "consul":
+		backend = store.CONSUL
+	case "etcd":
+		backend = store.ETCDV3
+	case "zookeeper":
+		backend = store.ZK
+	case "redis":
+		backend = store.REDIS
+	default:
+		return nil, fmt.Errorf("unsupported KV backend: %q", config.Backend)
+	}
+
+	tlsConfig, err := config.TLS.CreateTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kv.NewStore(backend, config.Endpoints, config.Username, config.Password, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	rootKey := defaultRootKey
+	if config.RootKey != "" {
+		rootKey = path.Clean(config.RootKey)
+	}
+
+	refreshInterval := time.Duration(config.RefreshInterval)
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+
+	return newTable(name, &kvSource{client: client, rootKey: rootKey}, refreshInterval)
+}
+
+// modified always reports the entries as modified, as they are loaded again on each refresh interval.
+func (s *kvSource) modified() (bool, error) {
+	return true, nil
+}
+
+func (s *kvSource) load() ([]dynamic.RedirectMapEntry, error) {
+	pairs, err := s.client.List(s.rootKey, nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]dynamic.RedirectMapEntry, 0, len(pairs))
+	for _, pair := range pairs {
+		// Some backends list the root key itself.
+		if path.Dir(pair.Key) != s.rootKey {
+			continue
+		}
+
+		var entry dynamic.RedirectMapEntry
+		if err := json.Unmarshal(pair.Value, &entry); err != nil {
+			return nil, fmt.Errorf("invalid redirect entry %s: %w", pair.Key, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
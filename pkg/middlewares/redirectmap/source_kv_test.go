@@ -0,0 +1,73 @@
+package redirectmap
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVSource(t *testing.T) {
+	client := newMemoryKV()
+	client.pairs["traefik/redirects/about"] = []byte(`{"host":"old.com","path":"/about","target":"https://new.com/company"}`)
+	client.pairs["traefik/redirects/blog"] = []byte(`{"path":"/blog","target":"https://blog.new.com","prefix":true}`)
+	client.pairs["traefik/other/foo"] = []byte(`{"path":"/foo","target":"/bar"}`)
+
+	src := &kvSource{client: client, rootKey: defaultRootKey}
+
+	entries, err := src.load()
+	require.NoError(t, err)
+
+	expected := []dynamic.RedirectMapEntry{
+		{Host: "old.com", Path: "/about", Target: "https://new.com/company"},
+		{Path: "/blog", Target: "https://blog.new.com", Prefix: true},
+	}
+	assert.ElementsMatch(t, expected, entries)
+
+	client.pairs["traefik/redirects/invalid"] = []byte(`{`)
+
+	_, err = src.load()
+	assert.Error(t, err)
+}
+
+// memoryKV is an in-memory KV store, behaving like the Consul one.
+type memoryKV struct {
+	store.Store
+
+	mu    sync.Mutex
+	pairs map[string][]byte
+}
+
+func newMemoryKV() *memoryKV {
+	return &memoryKV{pairs: make(map[string][]byte)}
+}
+
+func (m *memoryKV) List(directory string, _ *store.ReadOptions) ([]*store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pairs []*store.KVPair
+	for key, value := range m.pairs {
+		if strings.HasPrefix(key, directory+"/") {
+			pairs = append(pairs, &store.KVPair{Key: key, Value: value})
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil, store.ErrKeyNotFound
+	}
+
+	// The root key itself is listed, as with Consul.
+	pairs = append(pairs, &store.KVPair{Key: directory})
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Key < pairs[j].Key
+	})
+
+	return pairs, nil
+}
@@ -0,0 +1,158 @@
+package redirectmap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// trie holds the entries of a redirect map by host, then by path segment,
+// so that a lookup only depends on the length of the path, and not on the number of entries.
+type trie struct {
+	hosts map[string]*node
+	size  int
+}
+
+type node struct {
+	children map[string]*node
+	exact    *dynamic.RedirectMapEntry
+	prefix   *dynamic.RedirectMapEntry
+}
+
+// newTrie builds the trie of the entries.
+// When several entries match the same host and path, the last one wins.
+func newTrie(entries []dynamic.RedirectMapEntry) (*trie, error) {
+	t := &trie{hosts: make(map[string]*node)}
+
+	for i := range entries {
+		entry := entries[i]
+		if err := validEntry(entry); err != nil {
+			return nil, fmt.Errorf("invalid entry %s%s: %w", entry.Host, entry.Path, err)
+		}
+
+		host := canonicalHost(entry.Host)
+
+		current, ok := t.hosts[host]
+		if !ok {
+			current = &node{}
+			t.hosts[host] = current
+		}
+
+		for _, segment := range strings.FieldsFunc(entry.Path, isSlash) {
+			child, ok := current.children[segment]
+			if !ok {
+				if current.children == nil {
+					current.children = make(map[string]*node)
+				}
+				child = &node{}
+				current.children[segment] = child
+			}
+			current = child
+		}
+
+		if entry.Prefix {
+			current.prefix = &entry
+		} else {
+			current.exact = &entry
+		}
+
+		t.size++
+	}
+
+	return t, nil
+}
+
+// lookup returns the entry matching the host and the path, with the remainder of the path under the prefix of the entry.
+// The entries of the host win over the ones of any host, and the exact entries win over the longest matching prefix.
+func (t *trie) lookup(host, path string) (*dynamic.RedirectMapEntry, string) {
+	if root, ok := t.hosts[host]; ok {
+		if entry, rest := root.lookup(path); entry != nil {
+			return entry, rest
+		}
+	}
+
+	if root, ok := t.hosts[""]; ok {
+		return root.lookup(path)
+	}
+
+	return nil, ""
+}
+
+func (n *node) lookup(path string) (*dynamic.RedirectMapEntry, string) {
+	var match *dynamic.RedirectMapEntry
+	var rest string
+
+	current := n
+	var i int
+	for {
+		if current.prefix != nil {
+			match, rest = current.prefix, path[i:]
+		}
+
+		segment, end := nextSegment(path, i)
+		if segment == "" {
+			if current.exact != nil {
+				return current.exact, ""
+			}
+			return match, rest
+		}
+
+		child, ok := current.children[segment]
+		if !ok {
+			return match, rest
+		}
+
+		current, i = child, end
+	}
+}
+
+// nextSegment returns the path segment following the position, and the position of its end.
+func nextSegment(path string, i int) (string, int) {
+	for i < len(path) && path[i] == '/' {
+		i++
+	}
+
+	end := strings.IndexByte(path[i:], '/')
+	if end < 0 {
+		return path[i:], len(path)
+	}
+
+	return path[i : i+end], i + end
+}
+
+func validEntry(entry dynamic.RedirectMapEntry) error {
+	if !strings.HasPrefix(entry.Path, "/") {
+		return errors.New("the path must start with /")
+	}
+
+	if !validStatusCode(entry.StatusCode) && entry.StatusCode != 0 {
+		return fmt.Errorf("unsupported status code: %d", entry.StatusCode)
+	}
+
+	if entry.Target == "" && entry.StatusCode != http.StatusGone {
+		return errors.New("no target defined")
+	}
+
+	return nil
+}
+
+func validStatusCode(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect, http.StatusGone:
+		return true
+	default:
+		return false
+	}
+}
+
+func canonicalHost(host string) string {
+	return strings.TrimSuffix(types.CanonicalDomain(host), ".")
+}
+
+func isSlash(r rune) bool {
+	return r == '/'
+}
@@ -0,0 +1,153 @@
+package redirectmap
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrie_lookup(t *testing.T) {
+	entries := []dynamic.RedirectMapEntry{
+		{Host: "old.com", Path: "/", Target: "https://new.com/", Prefix: true},
+		{Host: "old.com", Path: "/about", Target: "https://new.com/company"},
+		{Host: "old.com", Path: "/blog", Target: "https://blog.new.com", Prefix: true},
+		{Host: "old.com", Path: "/blog/archives", StatusCode: 410},
+		{Host: "Other.COM", Path: "/foo/bar", Target: "/bar"},
+		{Path: "/legacy", Target: "/modern", Prefix: true},
+		{Path: "/legacy/exact", Target: "/exact"},
+	}
+
+	tr, err := newTrie(entries)
+	require.NoError(t, err)
+	assert.Equal(t, len(entries), tr.size)
+
+	testCases := []struct {
+		desc           string
+		host           string
+		path           string
+		expectedTarget string
+		expectedCode   int
+		expectedRest   string
+		expectedNil    bool
+	}{
+		{
+			desc:           "exact entry",
+			host:           "old.com",
+			path:           "/about",
+			expectedTarget: "https://new.com/company",
+		},
+		{
+			desc:           "exact entry with a trailing slash",
+			host:           "old.com",
+			path:           "/about/",
+			expectedTarget: "https://new.com/company",
+		},
+		{
+			desc:           "longest prefix",
+			host:           "old.com",
+			path:           "/blog/2019/hello",
+			expectedTarget: "https://blog.new.com",
+			expectedRest:   "/2019/hello",
+		},
+		{
+			desc:         "exact entry under a prefix",
+			host:         "old.com",
+			path:         "/blog/archives",
+			expectedCode: 410,
+		},
+		{
+			desc:           "prefix matching the path itself",
+			host:           "old.com",
+			path:           "/blog",
+			expectedTarget: "https://blog.new.com",
+		},
+		{
+			desc:           "root prefix",
+			host:           "old.com",
+			path:           "/about/team",
+			expectedTarget: "https://new.com/",
+			expectedRest:   "/about/team",
+		},
+		{
+			desc:           "canonical host",
+			host:           "other.com",
+			path:           "/foo//bar",
+			expectedTarget: "/bar",
+		},
+		{
+			desc:        "no matching path",
+			host:        "other.com",
+			path:        "/foo",
+			expectedNil: true,
+		},
+		{
+			desc:           "any host",
+			host:           "unknown.com",
+			path:           "/legacy/page",
+			expectedTarget: "/modern",
+			expectedRest:   "/page",
+		},
+		{
+			desc:           "any host when the entries of the host do not match",
+			host:           "other.com",
+			path:           "/legacy/exact",
+			expectedTarget: "/exact",
+		},
+		{
+			desc:        "no matching host",
+			host:        "unknown.com",
+			path:        "/about",
+			expectedNil: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			entry, rest := tr.lookup(test.host, test.path)
+			if test.expectedNil {
+				assert.Nil(t, entry)
+				return
+			}
+
+			require.NotNil(t, entry)
+			assert.Equal(t, test.expectedTarget, entry.Target)
+			assert.Equal(t, test.expectedCode, entry.StatusCode)
+			assert.Equal(t, test.expectedRest, rest)
+		})
+	}
+}
+
+func TestNewTrie_invalidEntries(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		entry dynamic.RedirectMapEntry
+	}{
+		{
+			desc:  "relative path",
+			entry: dynamic.RedirectMapEntry{Path: "foo", Target: "/bar"},
+		},
+		{
+			desc:  "no target",
+			entry: dynamic.RedirectMapEntry{Path: "/foo"},
+		},
+		{
+			desc:  "unsupported status code",
+			entry: dynamic.RedirectMapEntry{Path: "/foo", Target: "/bar", StatusCode: 200},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newTrie([]dynamic.RedirectMapEntry{test.entry})
+			assert.Error(t, err)
+		})
+	}
+}
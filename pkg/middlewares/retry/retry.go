@@ -173,6 +173,11 @@ func (r *responseWriterWithoutCloseNotify) WriteHeader(code int) {
 		return
 	}
 
+	if middlewares.IsInformational(code) {
+		middlewares.WriteInformational(r.responseWriter, code, r.headers)
+		return
+	}
+
 	// In that case retry case is set to false which means we at least managed
 	// to write headers to the backend : we are not going to perform any further retry.
 	// So it is now safe to alter current response headers with headers collected during
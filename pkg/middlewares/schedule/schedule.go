@@ -0,0 +1,125 @@
+// Package schedule implements a middleware allowing or denying the requests depending on the time of day.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "Schedule"
+)
+
+// schedule is a middleware that rejects the requests received outside of the allowed windows, or within a denied one.
+type schedule struct {
+	next     http.Handler
+	name     string
+	location *time.Location
+	allow    []window
+	deny     []window
+	now      func() time.Time
+}
+
+// New builds a new Schedule middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Schedule, name string) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	location := time.UTC
+	if config.Timezone != "" {
+		var err error
+		location, err = time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load timezone %q: %v", config.Timezone, err)
+		}
+	}
+
+	allow, err := parseWindows(config.Allow)
+	if err != nil {
+		return nil, err
+	}
+
+	deny, err := parseWindows(config.Deny)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule{
+		next:     next,
+		name:     name,
+		location: location,
+		allow:    allow,
+		deny:     deny,
+		now:      time.Now,
+	}, nil
+}
+
+func parseWindows(values []string) ([]window, error) {
+	var windows []window
+	for _, value := range values {
+		w, err := parseWindow(value)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func (s *schedule) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return s.name, tracing.SpanKindNoneEnum
+}
+
+func (s *schedule) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), s.name, typeName)
+	logger := log.FromContext(ctx)
+
+	now := s.now().In(s.location)
+	if !s.isAllowed(now) {
+		logMessage := fmt.Sprintf("rejecting request %+v: outside of the allowed schedule at %s", req, now.Format(time.RFC3339))
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+		reject(ctx, rw)
+		return
+	}
+
+	s.next.ServeHTTP(rw, req)
+}
+
+func (s *schedule) isAllowed(t time.Time) bool {
+	for _, w := range s.deny {
+		if w.contains(t) {
+			return false
+		}
+	}
+
+	if len(s.allow) == 0 {
+		return true
+	}
+
+	for _, w := range s.allow {
+		if w.contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func reject(ctx context.Context, rw http.ResponseWriter) {
+	statusCode := http.StatusForbidden
+
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
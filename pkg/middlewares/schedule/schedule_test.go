@@ -0,0 +1,172 @@
+package schedule
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchedule(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.Schedule
+		expectedError bool
+	}{
+		{
+			desc:   "empty",
+			config: dynamic.Schedule{},
+		},
+		{
+			desc: "valid windows",
+			config: dynamic.Schedule{
+				Timezone: "Europe/Paris",
+				Allow:    []string{"Mon-Fri 08:00-18:00", "Sat,Sun 22:00-02:00", "* 12:00-13:00", "09:00-10:00"},
+				Deny:     []string{"fri-mon 00:00-24:00"},
+			},
+		},
+		{
+			desc:          "unknown timezone",
+			config:        dynamic.Schedule{Timezone: "Foo/Bar"},
+			expectedError: true,
+		},
+		{
+			desc:          "unknown day",
+			config:        dynamic.Schedule{Allow: []string{"Mon-Fry 08:00-18:00"}},
+			expectedError: true,
+		},
+		{
+			desc:          "missing end",
+			config:        dynamic.Schedule{Allow: []string{"Mon 08:00"}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid time",
+			config:        dynamic.Schedule{Deny: []string{"Mon 08:00-25:00"}},
+			expectedError: true,
+		},
+		{
+			desc:          "too many fields",
+			config:        dynamic.Schedule{Deny: []string{"Mon Tue 08:00-10:00"}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := New(context.Background(), next, test.config, "foo-schedule")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	config := dynamic.Schedule{
+		Timezone: "America/New_York",
+		Allow:    []string{"Mon-Fri 08:00-18:00", "Sat 22:00-02:00"},
+		Deny:     []string{"Wed 12:00-13:00"},
+	}
+
+	testCases := []struct {
+		desc     string
+		time     time.Time
+		expected int
+	}{
+		{
+			desc:     "within weekday window",
+			time:     time.Date(2020, time.March, 2, 9, 30, 0, 0, time.UTC), // Monday 04:30 in New York
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "within weekday window in timezone",
+			time:     time.Date(2020, time.March, 2, 14, 30, 0, 0, time.UTC), // Monday 09:30 in New York
+			expected: http.StatusOK,
+		},
+		{
+			desc:     "end of window is excluded",
+			time:     time.Date(2020, time.March, 2, 23, 0, 0, 0, time.UTC), // Monday 18:00 in New York
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "denied window takes precedence",
+			time:     time.Date(2020, time.March, 4, 17, 15, 0, 0, time.UTC), // Wednesday 12:15 in New York
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "weekend outside of window",
+			time:     time.Date(2020, time.March, 7, 15, 0, 0, 0, time.UTC), // Saturday 10:00 in New York
+			expected: http.StatusForbidden,
+		},
+		{
+			desc:     "window spanning midnight, before midnight",
+			time:     time.Date(2020, time.March, 8, 3, 30, 0, 0, time.UTC), // Saturday 22:30 in New York
+			expected: http.StatusOK,
+		},
+		{
+			desc:     "window spanning midnight, after midnight",
+			time:     time.Date(2020, time.March, 8, 6, 30, 0, 0, time.UTC), // Sunday 01:30 in New York
+			expected: http.StatusOK,
+		},
+		{
+			desc:     "window spanning midnight, the day after",
+			time:     time.Date(2020, time.March, 9, 5, 30, 0, 0, time.UTC), // Monday 01:30 in New York
+			expected: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			handler, err := New(context.Background(), next, config, "foo-schedule")
+			require.NoError(t, err)
+
+			handler.(*schedule).now = func() time.Time { return test.time }
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expected, rw.Code)
+		})
+	}
+}
+
+func TestScheduleEmptyAllow(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, dynamic.Schedule{Deny: []string{"Sun 00:00-24:00"}}, "foo-schedule")
+	require.NoError(t, err)
+
+	for day := 1; day <= 7; day++ {
+		date := time.Date(2020, time.March, day, 12, 0, 0, 0, time.UTC)
+		handler.(*schedule).now = func() time.Time { return date }
+
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+
+		if date.Weekday() == time.Sunday {
+			assert.Equal(t, http.StatusForbidden, rw.Code, date.Weekday())
+		} else {
+			assert.Equal(t, http.StatusOK, rw.Code, date.Weekday())
+		}
+	}
+}
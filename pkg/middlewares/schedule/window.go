@@ -0,0 +1,136 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// window is a daily time range, restricted to a set of weekdays.
+// A range ending before it starts spans midnight, and belongs to the weekday it starts on.
+type window struct {
+	days  [7]bool
+	start time.Duration // since midnight
+	end   time.Duration // since midnight
+}
+
+// parseWindow parses a window of the form "[days] HH:MM-HH:MM",
+// where days is "*" or a comma separated list of weekdays or weekday ranges (e.g. "Mon-Fri,Sun").
+func parseWindow(value string) (window, error) {
+	var w window
+
+	fields := strings.Fields(value)
+
+	var days, hours string
+	switch len(fields) {
+	case 1:
+		days, hours = "*", fields[0]
+	case 2:
+		days, hours = fields[0], fields[1]
+	default:
+		return w, fmt.Errorf("invalid window %q", value)
+	}
+
+	if err := w.parseDays(days); err != nil {
+		return w, fmt.Errorf("invalid window %q: %v", value, err)
+	}
+
+	bounds := strings.SplitN(hours, "-", 2)
+	if len(bounds) != 2 {
+		return w, fmt.Errorf("invalid window %q: missing end of the time range", value)
+	}
+
+	var err error
+	w.start, err = parseTimeOfDay(bounds[0])
+	if err != nil {
+		return w, fmt.Errorf("invalid window %q: %v", value, err)
+	}
+
+	w.end, err = parseTimeOfDay(bounds[1])
+	if err != nil {
+		return w, fmt.Errorf("invalid window %q: %v", value, err)
+	}
+
+	return w, nil
+}
+
+func (w *window) parseDays(value string) error {
+	if value == "*" {
+		for i := range w.days {
+			w.days[i] = true
+		}
+		return nil
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		bounds := strings.SplitN(item, "-", 2)
+
+		first, ok := weekdays[strings.ToLower(bounds[0])]
+		if !ok {
+			return fmt.Errorf("unknown day %q", bounds[0])
+		}
+
+		last := first
+		if len(bounds) == 2 {
+			last, ok = weekdays[strings.ToLower(bounds[1])]
+			if !ok {
+				return fmt.Errorf("unknown day %q", bounds[1])
+			}
+		}
+
+		for day := first; ; day = (day + 1) % 7 {
+			w.days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 || hours == 24 && minutes != 0 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+// contains reports whether t, expressed in the time zone of the schedule, falls within the window.
+func (w window) contains(t time.Time) bool {
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	if w.start <= w.end {
+		return w.days[t.Weekday()] && sinceMidnight >= w.start && sinceMidnight < w.end
+	}
+
+	// The window spans midnight.
+	if sinceMidnight >= w.start {
+		return w.days[t.Weekday()]
+	}
+
+	return sinceMidnight < w.end && w.days[(t.Weekday()+6)%7]
+}
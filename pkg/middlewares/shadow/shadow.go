@@ -0,0 +1,263 @@
+package shadow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/alice"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "Shadow"
+
+	// maxEvaluations is how many requests a shadow middleware evaluates at once,
+	// the requests arriving beyond that are not evaluated.
+	maxEvaluations = 128
+	// evaluationTimeout bounds the duration of an evaluation.
+	evaluationTimeout = 30 * time.Second
+)
+
+const (
+	verdictAllowed = "allowed"
+	verdictBlocked = "blocked"
+	verdictError   = "error"
+	verdictSkipped = "skipped"
+)
+
+type chainBuilder interface {
+	BuildChain(ctx context.Context, middlewares []string) *alice.Chain
+}
+
+type evaluationKey struct{}
+
+// evaluation records whether a request went through all the shadowed middlewares.
+type evaluation struct {
+	allowed bool
+}
+
+// shadow is a middleware evaluating other middlewares on a copy of each request,
+// and reporting what they would have done, without affecting the request and its response.
+// The evaluations run in the background, so that they do not delay the requests.
+type shadow struct {
+	next        http.Handler
+	name        string
+	shadowed    http.Handler
+	maxBodySize int64
+	verdicts    gokitmetrics.Counter
+
+	// evaluations holds a token for each evaluation in progress.
+	evaluations chan struct{}
+	wg          sync.WaitGroup
+}
+
+// New creates a new shadow middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Shadow, builder chainBuilder, name string, metricsRegistry metrics.Registry) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if len(config.Middlewares) == 0 {
+		return nil, errors.New("middlewares cannot be empty")
+	}
+
+	shadowed, err := builder.BuildChain(ctx, config.Middlewares).Then(http.HandlerFunc(allow))
+	if err != nil {
+		return nil, err
+	}
+
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &shadow{
+		next:        next,
+		name:        name,
+		shadowed:    shadowed,
+		maxBodySize: config.MaxBodySize,
+		verdicts:    metricsRegistry.ShadowVerdictsCounter(),
+		evaluations: make(chan struct{}, maxEvaluations),
+	}, nil
+}
+
+// allow is the handler reached by the requests allowed by the shadowed middlewares.
+func allow(_ http.ResponseWriter, req *http.Request) {
+	if e, ok := req.Context().Value(evaluationKey{}).(*evaluation); ok {
+		e.allowed = true
+	}
+}
+
+func (s *shadow) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return s.name, tracing.SpanKindNoneEnum
+}
+
+func (s *shadow) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	select {
+	case s.evaluations <- struct{}{}:
+	default:
+		s.verdicts.With("middleware", s.name, "verdict", verdictSkipped).Add(1)
+		log.FromContext(middlewares.GetLoggerCtx(req.Context(), s.name, typeName)).
+			Debugf("Too many evaluations in progress, request %s %s not evaluated", req.Method, req.URL.RequestURI())
+
+		s.next.ServeHTTP(rw, req)
+		return
+	}
+
+	// The evaluation outlives the request, up to evaluationTimeout.
+	ctx, cancel := context.WithTimeout(detachedContext{parent: req.Context()}, evaluationTimeout)
+	shadowReq := s.copyRequest(ctx, req)
+
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			cancel()
+			<-s.evaluations
+			s.wg.Done()
+		}()
+
+		s.report(shadowReq)
+	}()
+
+	s.next.ServeHTTP(rw, req)
+}
+
+// report evaluates the request, and reports the verdict.
+func (s *shadow) report(req *http.Request) {
+	verdict, code := s.evaluate(req)
+	s.verdicts.With("middleware", s.name, "verdict", verdict).Add(1)
+
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), s.name, typeName))
+	switch {
+	case verdict == verdictBlocked && code == 0:
+		logger.Infof("Request %s %s would have been aborted", req.Method, req.URL.RequestURI())
+	case verdict == verdictBlocked:
+		logger.Infof("Request %s %s would have been blocked with status %d", req.Method, req.URL.RequestURI(), code)
+	case verdict == verdictAllowed:
+		logger.Debugf("Request %s %s would have been allowed", req.Method, req.URL.RequestURI())
+	}
+}
+
+// evaluate runs the shadowed middlewares, and returns their verdict,
+// with the status code of the response when the request is blocked, or zero when it is aborted.
+func (s *shadow) evaluate(req *http.Request) (verdict string, code int) {
+	defer func() {
+		if err := recover(); err != nil {
+			if err == http.ErrAbortHandler {
+				verdict, code = verdictBlocked, 0
+				return
+			}
+
+			log.FromContext(middlewares.GetLoggerCtx(req.Context(), s.name, typeName)).Errorf("Recovered from panic in shadowed middlewares: %v", err)
+			verdict, code = verdictError, 0
+		}
+	}()
+
+	e := &evaluation{}
+	recorder := newDiscardRecorder()
+
+	s.shadowed.ServeHTTP(recorder, req.WithContext(context.WithValue(req.Context(), evaluationKey{}, e)))
+
+	if e.allowed {
+		return verdictAllowed, 0
+	}
+
+	if recorder.code == 0 {
+		return verdictBlocked, http.StatusOK
+	}
+
+	return verdictBlocked, recorder.code
+}
+
+// copyRequest returns a copy of the request, with the given context, for the shadowed middlewares.
+// The body is copied only when its size is below maxBodySize,
+// and is otherwise left to the original request only.
+func (s *shadow) copyRequest(ctx context.Context, req *http.Request) *http.Request {
+	shadowReq := req.Clone(ctx)
+	shadowReq.Body = http.NoBody
+	shadowReq.GetBody = nil
+
+	if s.maxBodySize <= 0 || req.Body == nil || req.Body == http.NoBody {
+		return shadowReq
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, s.maxBodySize+1))
+
+	// The read part of the body is put back in front of the rest, for the original request.
+	req.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+
+	if err != nil || int64(len(body)) > s.maxBodySize {
+		return shadowReq
+	}
+
+	shadowReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	shadowReq.ContentLength = int64(len(body))
+
+	return shadowReq
+}
+
+// detachedContext holds the values of its parent context, but not its cancellation,
+// so that an evaluation is not interrupted by the end of the request.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// discardRecorder records the status code written by the shadowed middlewares, and discards the rest of the response.
+type discardRecorder struct {
+	header http.Header
+	code   int
+}
+
+func newDiscardRecorder() *discardRecorder {
+	return &discardRecorder{header: make(http.Header)}
+}
+
+func (r *discardRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *discardRecorder) Write(p []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return len(p), nil
+}
+
+func (r *discardRecorder) WriteHeader(code int) {
+	if r.code == 0 && !middlewares.IsInformational(code) {
+		r.code = code
+	}
+}
+
+// Flush is a no-op, for the middlewares requiring a http.Flusher.
+func (r *discardRecorder) Flush() {}
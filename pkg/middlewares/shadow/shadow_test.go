@@ -0,0 +1,269 @@
+package shadow
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/containous/alice"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type verdictsRegistry struct {
+	metrics.Registry
+	verdicts *testhelpers.CollectingCounter
+}
+
+func (r *verdictsRegistry) ShadowVerdictsCounter() gokitmetrics.Counter {
+	return r.verdicts
+}
+
+type lockedRegistry struct {
+	metrics.Registry
+	verdicts *lockedCounter
+}
+
+func (r *lockedRegistry) ShadowVerdictsCounter() gokitmetrics.Counter {
+	return r.verdicts
+}
+
+// lockedCounter is a counter recording its value and its last label values, safe for the concurrent evaluations.
+type lockedCounter struct {
+	mu          sync.Mutex
+	value       float64
+	labelValues []string
+}
+
+func (c *lockedCounter) With(labelValues ...string) gokitmetrics.Counter {
+	return &labeledCounter{parent: c, labelValues: labelValues}
+}
+
+func (c *lockedCounter) Add(delta float64) {
+	c.With().Add(delta)
+}
+
+func (c *lockedCounter) get() (float64, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value, c.labelValues
+}
+
+type labeledCounter struct {
+	parent      *lockedCounter
+	labelValues []string
+}
+
+func (c *labeledCounter) With(labelValues ...string) gokitmetrics.Counter {
+	return &labeledCounter{parent: c.parent, labelValues: append(append([]string{}, c.labelValues...), labelValues...)}
+}
+
+func (c *labeledCounter) Add(delta float64) {
+	c.parent.mu.Lock()
+	defer c.parent.mu.Unlock()
+	c.parent.value += delta
+	c.parent.labelValues = c.labelValues
+}
+
+type fakeBuilder map[string]alice.Constructor
+
+func (b fakeBuilder) BuildChain(_ context.Context, names []string) *alice.Chain {
+	chain := alice.New()
+	for _, name := range names {
+		chain = chain.Append(b[name])
+	}
+	return &chain
+}
+
+var builder = fakeBuilder{
+	"allow": func(next http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			req.Header.Set("X-Shadowed", "true")
+			rw.Header().Set("X-Shadowed", "true")
+			next.ServeHTTP(rw, req)
+		}), nil
+	},
+	"deny": func(next http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			http.Error(rw, "denied", http.StatusForbidden)
+		}), nil
+	},
+	"deny-body": func(next http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			body, _ := ioutil.ReadAll(req.Body)
+			if strings.Contains(string(body), "attack") {
+				http.Error(rw, "denied", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(rw, req)
+		}), nil
+	},
+	"panic": func(next http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			panic("boom")
+		}), nil
+	},
+}
+
+func TestNew(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.Shadow{}, builder, "foo-shadow", nil)
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.Shadow{Middlewares: []string{"deny"}}, builder, "foo-shadow", nil)
+	assert.NoError(t, err)
+}
+
+func TestShadow(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		middlewares     []string
+		maxBodySize     int64
+		body            string
+		expectedVerdict string
+	}{
+		{
+			desc:            "allowed",
+			middlewares:     []string{"allow"},
+			expectedVerdict: verdictAllowed,
+		},
+		{
+			desc:            "blocked",
+			middlewares:     []string{"allow", "deny"},
+			expectedVerdict: verdictBlocked,
+		},
+		{
+			desc:            "blocked by the body",
+			middlewares:     []string{"deny-body"},
+			maxBodySize:     1024,
+			body:            "an attack",
+			expectedVerdict: verdictBlocked,
+		},
+		{
+			desc:            "body larger than the limit",
+			middlewares:     []string{"deny-body"},
+			maxBodySize:     4,
+			body:            "an attack",
+			expectedVerdict: verdictAllowed,
+		},
+		{
+			desc:            "body not copied",
+			middlewares:     []string{"deny-body"},
+			body:            "an attack",
+			expectedVerdict: verdictAllowed,
+		},
+		{
+			desc:            "panic",
+			middlewares:     []string{"panic"},
+			expectedVerdict: verdictError,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var nextBody string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				nextBody = string(body)
+
+				assert.Empty(t, req.Header.Get("X-Shadowed"))
+
+				rw.WriteHeader(http.StatusAccepted)
+			})
+
+			registry := &verdictsRegistry{Registry: metrics.NewVoidRegistry(), verdicts: &testhelpers.CollectingCounter{}}
+
+			config := dynamic.Shadow{Middlewares: test.middlewares, MaxBodySize: test.maxBodySize}
+
+			handler, err := New(context.Background(), next, config, builder, "foo-shadow", registry)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "http://foo.com", strings.NewReader(test.body)))
+
+			assert.Equal(t, http.StatusAccepted, recorder.Code)
+			assert.Empty(t, recorder.Header().Get("X-Shadowed"))
+			assert.Equal(t, test.body, nextBody)
+
+			handler.(*shadow).wg.Wait()
+
+			assert.Equal(t, float64(1), registry.verdicts.CounterValue)
+			assert.Equal(t, []string{"middleware", "foo-shadow", "verdict", test.expectedVerdict}, registry.verdicts.LastLabelValues)
+		})
+	}
+}
+
+func TestShadow_evaluate(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, dynamic.Shadow{Middlewares: []string{"deny"}}, builder, "foo-shadow", nil)
+	require.NoError(t, err)
+
+	verdict, code := handler.(*shadow).evaluate(httptest.NewRequest(http.MethodGet, "http://foo.com", nil))
+
+	assert.Equal(t, verdictBlocked, verdict)
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+func TestShadow_async(t *testing.T) {
+	release := make(chan struct{})
+	slowBuilder := fakeBuilder{
+		"slow": func(next http.Handler) (http.Handler, error) {
+			return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				<-release
+				next.ServeHTTP(rw, req)
+			}), nil
+		},
+	}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	verdicts := &lockedCounter{}
+	registry := &lockedRegistry{Registry: metrics.NewVoidRegistry(), verdicts: verdicts}
+
+	handler, err := New(context.Background(), next, dynamic.Shadow{Middlewares: []string{"slow"}}, slowBuilder, "foo-shadow", registry)
+	require.NoError(t, err)
+
+	// The request is not delayed by the evaluation, which outlives it.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	cancel()
+
+	// The requests beyond the evaluations in progress are not evaluated.
+	for i := 1; i < maxEvaluations; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.com", nil))
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com", nil))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	value, labels := verdicts.get()
+	assert.Equal(t, float64(1), value)
+	assert.Equal(t, []string{"middleware", "foo-shadow", "verdict", verdictSkipped}, labels)
+
+	close(release)
+	handler.(*shadow).wg.Wait()
+
+	value, labels = verdicts.get()
+	assert.Equal(t, float64(maxEvaluations+1), value)
+	assert.Equal(t, []string{"middleware", "foo-shadow", "verdict", verdictAllowed}, labels)
+}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"net"
 	"net/http"
+
+	"github.com/containous/traefik/v2/pkg/middlewares"
 )
 
 type statusCodeRecoder interface {
@@ -18,7 +20,9 @@ type statusCodeWithoutCloseNotify struct {
 
 // WriteHeader captures the status code for later retrieval.
 func (s *statusCodeWithoutCloseNotify) WriteHeader(status int) {
-	s.status = status
+	if !middlewares.IsInformational(status) {
+		s.status = status
+	}
 	s.ResponseWriter.WriteHeader(status)
 }
 
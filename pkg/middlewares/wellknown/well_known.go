@@ -0,0 +1,102 @@
+// Package wellknown implements a middleware serving the robots.txt, security.txt, and other well-known files.
+package wellknown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "WellKnown"
+)
+
+type file struct {
+	content     []byte
+	contentType string
+}
+
+// wellKnown is a middleware that answers the requests to the configured files, without forwarding them.
+type wellKnown struct {
+	next  http.Handler
+	name  string
+	files map[string]file
+}
+
+// New builds a new WellKnown middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.WellKnown, name string) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	files := make(map[string]file)
+
+	if config.RobotsTxt != "" {
+		files["/robots.txt"] = file{content: []byte(config.RobotsTxt), contentType: "text/plain; charset=utf-8"}
+	}
+
+	if config.SecurityTxt != "" {
+		securityTxt := file{content: []byte(config.SecurityTxt), contentType: "text/plain; charset=utf-8"}
+		files["/.well-known/security.txt"] = securityTxt
+		files["/security.txt"] = securityTxt
+	}
+
+	for _, f := range config.Files {
+		if !strings.HasPrefix(f.Path, "/") {
+			return nil, fmt.Errorf("invalid path %q: must be absolute", f.Path)
+		}
+
+		contentType := f.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(path.Ext(f.Path))
+		}
+		if contentType == "" {
+			contentType = "text/plain; charset=utf-8"
+		}
+
+		files[f.Path] = file{content: []byte(f.Content), contentType: contentType}
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no file configured, WellKnown not created")
+	}
+
+	return &wellKnown{
+		next:  next,
+		name:  name,
+		files: files,
+	}, nil
+}
+
+func (w *wellKnown) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return w.name, tracing.SpanKindNoneEnum
+}
+
+func (w *wellKnown) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f, ok := w.files[req.URL.Path]
+	if !ok || req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.next.ServeHTTP(rw, req)
+		return
+	}
+
+	rw.Header().Set("Content-Type", f.contentType)
+	rw.Header().Set("Content-Length", fmt.Sprint(len(f.content)))
+	rw.WriteHeader(http.StatusOK)
+
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := rw.Write(f.content); err != nil {
+		log.FromContext(middlewares.GetLoggerCtx(req.Context(), w.name, typeName)).Error(err)
+	}
+}
@@ -0,0 +1,128 @@
+package wellknown
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWellKnown(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.WellKnown{}, "foo-well-known")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.WellKnown{Files: []dynamic.WellKnownFile{{Path: "foo.txt"}}}, "foo-well-known")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.WellKnown{RobotsTxt: "User-agent: *\nDisallow: /\n"}, "foo-well-known")
+	assert.NoError(t, err)
+}
+
+func TestWellKnown(t *testing.T) {
+	config := dynamic.WellKnown{
+		RobotsTxt:   "User-agent: *\nDisallow: /\n",
+		SecurityTxt: "Contact: mailto:security@example.com\n",
+		Files: []dynamic.WellKnownFile{
+			{Path: "/.well-known/assetlinks.json", Content: "[]"},
+			{Path: "/.well-known/apple-app-site-association", Content: "{}", ContentType: "application/json"},
+			{Path: "/.well-known/change-password", Content: "foo"},
+		},
+	}
+
+	testCases := []struct {
+		desc                string
+		method              string
+		path                string
+		expectedBody        string
+		expectedContentType string
+	}{
+		{
+			desc:                "robots.txt",
+			path:                "/robots.txt",
+			expectedBody:        "User-agent: *\nDisallow: /\n",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			desc:                "security.txt",
+			path:                "/.well-known/security.txt",
+			expectedBody:        "Contact: mailto:security@example.com\n",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			desc:                "legacy security.txt",
+			path:                "/security.txt",
+			expectedBody:        "Contact: mailto:security@example.com\n",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			desc:                "content type from the extension",
+			path:                "/.well-known/assetlinks.json",
+			expectedBody:        "[]",
+			expectedContentType: "application/json",
+		},
+		{
+			desc:                "explicit content type",
+			path:                "/.well-known/apple-app-site-association",
+			expectedBody:        "{}",
+			expectedContentType: "application/json",
+		},
+		{
+			desc:                "default content type",
+			path:                "/.well-known/change-password",
+			expectedBody:        "foo",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			desc:                "HEAD request",
+			method:              http.MethodHead,
+			path:                "/robots.txt",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			desc:         "other path",
+			path:         "/index.html",
+			expectedBody: "backend",
+		},
+		{
+			desc:         "other method",
+			method:       http.MethodPost,
+			path:         "/robots.txt",
+			expectedBody: "backend",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("backend"))
+			})
+
+			handler, err := New(context.Background(), next, config, "foo-well-known")
+			require.NoError(t, err)
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, "http://localhost"+test.path, nil)
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, http.StatusOK, rw.Code)
+			assert.Equal(t, test.expectedBody, rw.Body.String())
+			if test.expectedContentType != "" {
+				assert.Equal(t, test.expectedContentType, rw.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
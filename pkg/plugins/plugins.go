@@ -0,0 +1,249 @@
+// Package plugins loads the plugins of Traefik, interpreted by Yaegi from their Go sources.
+//
+// A DNS provider plugin solves the DNS-01 challenges of the ACME resolvers, like the DNS providers of lego:
+//
+//	// CreateConfig creates the configuration, with its default values, filled with the options of the resolver.
+//	func CreateConfig() *Config
+//
+//	// New creates the DNS provider.
+//	func New(config *Config) (*Provider, error)
+//
+//	func (p *Provider) Present(domain, token, keyAuth string) error
+//	func (p *Provider) CleanUp(domain, token, keyAuth string) error
+//
+//	// Timeout, optional, returns the timeout and the interval of the checks of the propagation of the records.
+//	func (p *Provider) Timeout() (timeout, interval time.Duration)
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/parser"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+// Configuration holds the configuration of the plugins.
+type Configuration struct {
+	GoPath string                `description:"GOPATH of the sources of the plugins, each plugin being in the src/<moduleName> directory." json:"goPath,omitempty" toml:"goPath,omitempty" yaml:"goPath,omitempty" export:"true"`
+	Local  map[string]Descriptor `description:"Plugins loaded from their local sources, by name." json:"local,omitempty" toml:"local,omitempty" yaml:"local,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Configuration) SetDefaults() {
+	c.GoPath = "./plugins-local"
+}
+
+// Descriptor holds the description of a plugin.
+type Descriptor struct {
+	ModuleName string `description:"Module name of the plugin, e.g. github.com/example/dnsplugin." json:"moduleName,omitempty" toml:"moduleName,omitempty" yaml:"moduleName,omitempty" export:"true"`
+}
+
+// newDNSProviderDecl declares, in the interpreter, the named function adapting the DNS provider of the plugin
+// to functions callable by Traefik, the methods of the interpreted types not being.
+// The function is evaluated by its name, the evaluation of a function literal not returning a callable function,
+// and returns no nil function, which the interpreter does not convert.
+// Its timeout function returns the given expression, zero values meaning the default timeout and interval.
+const newDNSProviderDecl = `func %[1]s(config *%[2]s.Config) (func(string, string, string) error, func(string, string, string) error, func() (time.Duration, time.Duration), error) {
+	provider, err := %[2]s.New(config)
+	if err != nil {
+		fail := func(string, string, string) error { return err }
+		return fail, fail, func() (time.Duration, time.Duration) { return 0, 0 }, err
+	}
+
+	present := func(domain, token, keyAuth string) error {
+		return provider.Present(domain, token, keyAuth)
+	}
+
+	cleanUp := func(domain, token, keyAuth string) error {
+		return provider.CleanUp(domain, token, keyAuth)
+	}
+
+	timeout := func() (time.Duration, time.Duration) {
+		return %[3]s
+	}
+
+	return present, cleanUp, timeout, nil
+}`
+
+// Builder creates the instances of the plugins.
+type Builder struct {
+	plugins map[string]*plugin
+}
+
+type plugin struct {
+	moduleName string
+	basePkg    string
+
+	// mu serializes the evaluations of the interpreter.
+	mu     sync.Mutex
+	interp *interp.Interpreter
+	// dnsProviderFunc is the function declared by newDNSProviderDecl, once evaluated.
+	dnsProviderFunc reflect.Value
+}
+
+// NewBuilder loads the sources of the plugins of the configuration.
+func NewBuilder(config *Configuration) (*Builder, error) {
+	builder := &Builder{plugins: make(map[string]*plugin)}
+
+	for name, desc := range config.Local {
+		p, err := loadPlugin(config.GoPath, desc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the plugin %q: %w", name, err)
+		}
+		builder.plugins[name] = p
+	}
+
+	return builder, nil
+}
+
+func loadPlugin(goPath string, desc Descriptor) (*plugin, error) {
+	if desc.ModuleName == "" {
+		return nil, errors.New("no module name")
+	}
+
+	src := filepath.Join(goPath, "src", filepath.FromSlash(desc.ModuleName))
+	if _, err := os.Stat(src); err != nil {
+		return nil, fmt.Errorf("unable to find the sources of the module %s: %w", desc.ModuleName, err)
+	}
+
+	i := interp.New(interp.Options{GoPath: goPath})
+	i.Use(stdlib.Symbols)
+
+	if _, err := i.Eval(fmt.Sprintf(`import %q`, desc.ModuleName)); err != nil {
+		return nil, fmt.Errorf("unable to import the module %s: %w", desc.ModuleName, err)
+	}
+
+	// The adapters of the plugins use the durations.
+	if _, err := i.Eval(`import "time"`); err != nil {
+		return nil, err
+	}
+
+	return &plugin{
+		moduleName: desc.ModuleName,
+		basePkg:    strings.ReplaceAll(path.Base(desc.ModuleName), "-", "_"),
+		interp:     i,
+	}, nil
+}
+
+// NewDNSProvider creates the DNS provider of the plugin, with the given options.
+func (b *Builder) NewDNSProvider(name string, options map[string]string) (challenge.Provider, error) {
+	p, ok := b.plugins[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown plugin %q", name)
+	}
+
+	provider, err := p.newDNSProvider(options)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the DNS provider of the plugin %q: %w", name, err)
+	}
+
+	return provider, nil
+}
+
+func (p *plugin) newDNSProvider(options map[string]string) (provider *dnsProvider, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("plugin panic: %v", r)
+		}
+	}()
+
+	config, err := p.interp.Eval(p.basePkg + ".CreateConfig()")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the configuration: %w", err)
+	}
+
+	if len(options) > 0 {
+		labels := make(map[string]string, len(options))
+		for key, value := range options {
+			labels[parser.DefaultRootName+"."+key] = value
+		}
+
+		if err = parser.Decode(labels, config.Interface(), parser.DefaultRootName); err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+
+	if !p.dnsProviderFunc.IsValid() {
+		p.dnsProviderFunc, err = p.declareDNSProviderFunc()
+		if err != nil {
+			return nil, fmt.Errorf("the module %s is not a DNS provider: %w", p.moduleName, err)
+		}
+	}
+
+	results := p.dnsProviderFunc.Call([]reflect.Value{config})
+	if err, ok := results[3].Interface().(error); ok && err != nil {
+		return nil, err
+	}
+
+	provider = &dnsProvider{
+		present:  results[0].Interface().(func(string, string, string) error),
+		cleanUp:  results[1].Interface().(func(string, string, string) error),
+		timeout:  dns01.DefaultPropagationTimeout,
+		interval: dns01.DefaultPollingInterval,
+	}
+
+	if timeout, interval := results[2].Interface().(func() (time.Duration, time.Duration))(); timeout > 0 && interval > 0 {
+		provider.timeout, provider.interval = timeout, interval
+	}
+
+	return provider, nil
+}
+
+// declareDNSProviderFunc declares the function adapting the DNS provider of the plugin, and returns it.
+// The interpreter not asserting the methods of the interpreted types,
+// the function calling the optional Timeout method is declared first, and the one without it if it does not compile.
+func (p *plugin) declareDNSProviderFunc() (reflect.Value, error) {
+	name := "traefikNewDNSProvider"
+	_, err := p.interp.Eval(fmt.Sprintf(newDNSProviderDecl, name, p.basePkg, "provider.Timeout()"))
+	if err != nil {
+		name = "traefikNewDNSProviderWithoutTimeout"
+		if _, err = p.interp.Eval(fmt.Sprintf(newDNSProviderDecl, name, p.basePkg, "0, 0")); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return p.interp.Eval(name)
+}
+
+// dnsProvider is the DNS provider of a plugin.
+type dnsProvider struct {
+	present, cleanUp  func(domain, token, keyAuth string) error
+	timeout, interval time.Duration
+}
+
+func (d *dnsProvider) Present(domain, token, keyAuth string) error {
+	return call(d.present, domain, token, keyAuth)
+}
+
+func (d *dnsProvider) CleanUp(domain, token, keyAuth string) error {
+	return call(d.cleanUp, domain, token, keyAuth)
+}
+
+func (d *dnsProvider) Timeout() (timeout, interval time.Duration) {
+	return d.timeout, d.interval
+}
+
+// call calls the function of the plugin, recovering from its panics.
+func call(fn func(domain, token, keyAuth string) error, domain, token, keyAuth string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("plugin panic: %v", r)
+		}
+	}()
+
+	return fn(domain, token, keyAuth)
+}
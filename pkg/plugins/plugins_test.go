@@ -0,0 +1,98 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBuilder(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      *Configuration
+		expectedErr bool
+	}{
+		{
+			desc: "local plugin",
+			config: &Configuration{
+				GoPath: "./testdata",
+				Local:  map[string]Descriptor{"example": {ModuleName: "github.com/example/dnsplugin"}},
+			},
+		},
+		{
+			desc: "no module name",
+			config: &Configuration{
+				GoPath: "./testdata",
+				Local:  map[string]Descriptor{"example": {}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "missing sources",
+			config: &Configuration{
+				GoPath: "./testdata",
+				Local:  map[string]Descriptor{"example": {ModuleName: "github.com/example/missing"}},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewBuilder(test.config)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestBuilder_NewDNSProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-plugins")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	builder, err := NewBuilder(&Configuration{
+		GoPath: "./testdata",
+		Local:  map[string]Descriptor{"example": {ModuleName: "github.com/example/dnsplugin"}},
+	})
+	require.NoError(t, err)
+
+	_, err = builder.NewDNSProvider("unknown", nil)
+	assert.Error(t, err)
+
+	// The plugin requires a file.
+	_, err = builder.NewDNSProvider("example", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no file")
+
+	file := filepath.Join(dir, "records")
+	provider, err := builder.NewDNSProvider("example", map[string]string{"file": file, "ttl": "120"})
+	require.NoError(t, err)
+
+	err = provider.Present("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	err = provider.CleanUp("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	records, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "present example.com keyAuth 120\ncleanup example.com keyAuth\n", string(records))
+
+	require.Implements(t, (*challenge.ProviderTimeout)(nil), provider)
+	timeout, interval := provider.(challenge.ProviderTimeout).Timeout()
+	assert.Equal(t, 2*time.Minute, timeout)
+	assert.Equal(t, 5*time.Second, interval)
+}
@@ -0,0 +1,61 @@
+// Package dnsplugin is a DNS provider plugin recording the challenges in a file.
+package dnsplugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config holds the configuration of the plugin.
+type Config struct {
+	File string
+	TTL  int
+}
+
+// CreateConfig creates the configuration.
+func CreateConfig() *Config {
+	return &Config{TTL: 60}
+}
+
+// Provider records the challenges in a file.
+type Provider struct {
+	file string
+	ttl  int
+}
+
+// New creates the provider.
+func New(config *Config) (*Provider, error) {
+	if config.File == "" {
+		return nil, errors.New("no file")
+	}
+
+	return &Provider{file: config.File, ttl: config.TTL}, nil
+}
+
+// Present records the challenge.
+func (p *Provider) Present(domain, token, keyAuth string) error {
+	return p.record(fmt.Sprintf("present %s %s %d\n", domain, keyAuth, p.ttl))
+}
+
+// CleanUp records the clean up of the challenge.
+func (p *Provider) CleanUp(domain, token, keyAuth string) error {
+	return p.record(fmt.Sprintf("cleanup %s %s\n", domain, keyAuth))
+}
+
+// Timeout returns the timeout and the interval of the propagation checks.
+func (p *Provider) Timeout() (timeout, interval time.Duration) {
+	return 2 * time.Minute, 5 * time.Second
+}
+
+func (p *Provider) record(line string) error {
+	f, err := os.OpenFile(p.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(line)
+	return err
+}
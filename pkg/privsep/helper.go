@@ -0,0 +1,153 @@
+package privsep
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// serve serves the requests of the worker process, until its socket is closed.
+func serve(conn net.Conn, allowedPaths []string) {
+	logger := log.WithoutContext()
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+
+		resp := handle(req, allowedPaths)
+		if resp.Error != "" {
+			logger.Warnf("Privilege separation: %s", resp.Error)
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			logger.Errorf("Unable to answer the worker process: %v", err)
+			return
+		}
+	}
+}
+
+func handle(req request, allowedPaths []string) response {
+	switch req.Op {
+	case opStat:
+		// The existence of a file is not sensitive, so that the worker can tell the paths from the contents.
+		_, err := os.Stat(req.Path)
+		return response{Exists: err == nil}
+
+	case opRead:
+		path, err := allowedPath(req.Path, allowedPaths)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		if !info.Mode().IsRegular() {
+			return response{Error: fmt.Sprintf("%s is not a regular file", req.Path)}
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+
+		return response{Content: content}
+
+	default:
+		return response{Error: fmt.Sprintf("unknown operation %q", req.Op)}
+	}
+}
+
+// allowedPath returns the path, with its symbolic links resolved, if it is one of the allowed paths or in one of them.
+func allowedPath(path string, allowedPaths []string) (string, error) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, allowed := range allowedPaths {
+		if resolved == allowed || strings.HasPrefix(resolved, allowed+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+
+	return "", fmt.Errorf("reading %s is not allowed", path)
+}
+
+// resolveAllowedPaths resolves the symbolic links of the allowed paths.
+func resolveAllowedPaths(paths []string) ([]string, error) {
+	var resolved []string
+	for _, path := range paths {
+		p, err := resolvePath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed path: %w", err)
+		}
+		resolved = append(resolved, p)
+	}
+
+	return resolved, nil
+}
+
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(abs)
+}
+
+// lookupCredential returns the user and group IDs of the user and group, given by their names or IDs.
+func lookupCredential(userName, groupName string) (uint32, uint32, error) {
+	if userName == "" {
+		return 0, 0, fmt.Errorf("the user of the worker process must be defined")
+	}
+
+	u, err := user.Lookup(userName)
+	if err != nil {
+		if u, err = user.LookupId(userName); err != nil {
+			return 0, 0, fmt.Errorf("unknown user %s: %w", userName, err)
+		}
+	}
+
+	gid := u.Gid
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			if g, err = user.LookupGroupId(groupName); err != nil {
+				return 0, 0, fmt.Errorf("unknown group %s: %w", groupName, err)
+			}
+		}
+		gid = g.Gid
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid user ID %s: %w", u.Uid, err)
+	}
+
+	g, err := strconv.ParseUint(gid, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid group ID %s: %w", gid, err)
+	}
+
+	if uid == 0 {
+		return 0, 0, fmt.Errorf("the worker process cannot run as root")
+	}
+
+	return uint32(uid), uint32(g), nil
+}
@@ -0,0 +1,116 @@
+package privsep
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "privsep")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	certsDir := filepath.Join(dir, "certs")
+	require.NoError(t, os.Mkdir(certsDir, 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(certsDir, "tls.key"), []byte("key"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "secret"), []byte("secret"), 0600))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "secret"), filepath.Join(certsDir, "escape")))
+
+	allowedPaths, err := resolveAllowedPaths([]string{certsDir})
+	require.NoError(t, err)
+
+	helperConn, workerConn := net.Pipe()
+	defer func() { _ = workerConn.Close() }()
+
+	go serve(helperConn, allowedPaths)
+
+	client := newHelperClient(workerConn)
+
+	testCases := []struct {
+		desc            string
+		req             request
+		expectedExists  bool
+		expectedContent string
+		wantErr         bool
+	}{
+		{
+			desc:            "read an allowed file",
+			req:             request{Op: opRead, Path: filepath.Join(certsDir, "tls.key")},
+			expectedContent: "key",
+		},
+		{
+			desc:            "read an allowed file through a relative path",
+			req:             request{Op: opRead, Path: filepath.Join(certsDir, "..", "certs", "tls.key")},
+			expectedContent: "key",
+		},
+		{
+			desc:    "read a file which is not allowed",
+			req:     request{Op: opRead, Path: filepath.Join(dir, "secret")},
+			wantErr: true,
+		},
+		{
+			desc:    "read a file out of the allowed paths through a symbolic link",
+			req:     request{Op: opRead, Path: filepath.Join(certsDir, "escape")},
+			wantErr: true,
+		},
+		{
+			desc:    "read a directory",
+			req:     request{Op: opRead, Path: certsDir},
+			wantErr: true,
+		},
+		{
+			desc:    "read a missing file",
+			req:     request{Op: opRead, Path: filepath.Join(certsDir, "missing")},
+			wantErr: true,
+		},
+		{
+			desc:           "stat an existing file",
+			req:            request{Op: opStat, Path: filepath.Join(dir, "secret")},
+			expectedExists: true,
+		},
+		{
+			desc: "stat a missing file",
+			req:  request{Op: opStat, Path: "-----BEGIN CERTIFICATE-----"},
+		},
+		{
+			desc:    "unknown operation",
+			req:     request{Op: "write", Path: filepath.Join(certsDir, "tls.key")},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			resp, err := client.do(test.req)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedExists, resp.Exists)
+			assert.Equal(t, test.expectedContent, string(resp.Content))
+		})
+	}
+}
+
+func TestLookupCredential(t *testing.T) {
+	_, _, err := lookupCredential("", "")
+	assert.Error(t, err)
+
+	_, _, err = lookupCredential("root", "")
+	assert.Error(t, err)
+
+	_, _, err = lookupCredential("0", "")
+	assert.Error(t, err)
+
+	_, _, err = lookupCredential("traefik-unknown-user", "")
+	assert.Error(t, err)
+}
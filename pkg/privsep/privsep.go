@@ -0,0 +1,154 @@
+// Package privsep implements the privilege separation of the reading of the sensitive files.
+//
+// When it is enabled, the traefik command, started as a privileged user, runs as a small helper process:
+// it starts the traefik command again as an unprivileged worker process, which handles all the traffic,
+// and reads on its behalf the allowed files, such as the certificates and their keys, that the worker cannot read itself.
+// The two processes communicate through a pair of connected sockets.
+package privsep
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+)
+
+const (
+	// workerEnv is the environment variable marking the worker process.
+	workerEnv = "_TRAEFIK_PRIVSEP_WORKER"
+	// workerFD is the file descriptor of the socket of the worker process, the first of its extra files.
+	workerFD = 3
+)
+
+const (
+	opStat = "stat"
+	opRead = "read"
+)
+
+// Configuration holds the configuration of the privilege separation.
+type Configuration struct {
+	User         string   `description:"User running the unprivileged worker process." json:"user,omitempty" toml:"user,omitempty" yaml:"user,omitempty" export:"true"`
+	Group        string   `description:"Group running the unprivileged worker process. Defaults to the primary group of the user." json:"group,omitempty" toml:"group,omitempty" yaml:"group,omitempty" export:"true"`
+	AllowedPaths []string `description:"Files and directories that the worker process reads through the privileged helper process, such as the certificates and their keys." json:"allowedPaths,omitempty" toml:"allowedPaths,omitempty" yaml:"allowedPaths,omitempty" export:"true"`
+}
+
+type request struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+}
+
+type response struct {
+	Exists  bool   `json:"exists,omitempty"`
+	Content []byte `json:"content,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// helper is the client of the privileged helper process, set in the worker process.
+var (
+	helperMu sync.Mutex
+	helper   *helperClient
+)
+
+// IsWorker returns whether the process is the worker process started by the privileged helper process.
+func IsWorker() bool {
+	return os.Getenv(workerEnv) != ""
+}
+
+// InitWorker connects the worker process to the privileged helper process,
+// through which the files are read from then on.
+func InitWorker() error {
+	file := os.NewFile(workerFD, "privsep")
+	if file == nil {
+		return errors.New("no socket to the privileged helper process")
+	}
+	defer func() { _ = file.Close() }()
+
+	conn, err := net.FileConn(file)
+	if err != nil {
+		return fmt.Errorf("invalid socket to the privileged helper process: %w", err)
+	}
+
+	// The processes started by the worker process are not worker processes.
+	if err := os.Unsetenv(workerEnv); err != nil {
+		return err
+	}
+
+	helperMu.Lock()
+	helper = newHelperClient(conn)
+	helperMu.Unlock()
+
+	return nil
+}
+
+func getHelper() *helperClient {
+	helperMu.Lock()
+	defer helperMu.Unlock()
+
+	return helper
+}
+
+// ReadFile reads the file, through the privileged helper process in the worker process.
+func ReadFile(path string) ([]byte, error) {
+	h := getHelper()
+	if h == nil {
+		return ioutil.ReadFile(path)
+	}
+
+	resp, err := h.do(request{Op: opRead, Path: path})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Content, nil
+}
+
+// FileExists returns whether the file exists, through the privileged helper process in the worker process.
+func FileExists(path string) bool {
+	h := getHelper()
+	if h == nil {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	resp, err := h.do(request{Op: opStat, Path: path})
+	return err == nil && resp.Exists
+}
+
+// helperClient sends the requests of the worker process to the privileged helper process, one at a time.
+type helperClient struct {
+	mu   sync.Mutex
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+func newHelperClient(conn net.Conn) *helperClient {
+	return &helperClient{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(conn),
+	}
+}
+
+func (c *helperClient) do(req request) (*response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.enc.Encode(req); err != nil {
+		return nil, fmt.Errorf("unable to send the request to the privileged helper process: %w", err)
+	}
+
+	var resp response
+	if err := c.dec.Decode(&resp); err != nil {
+		return nil, fmt.Errorf("unable to read the response of the privileged helper process: %w", err)
+	}
+
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,88 @@
+package privsep
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// capNetBindService is the capability to bind the ports below 1024, kept by the worker process.
+const capNetBindService = 10
+
+// RunHelper runs the privileged helper process: it starts the traefik command again as the unprivileged worker process,
+// forwards it the signals, serves its requests to read the allowed files, and returns once it exits.
+func RunHelper(config *Configuration) error {
+	uid, gid, err := lookupCredential(config.User, config.Group)
+	if err != nil {
+		return err
+	}
+
+	allowedPaths, err := resolveAllowedPaths(config.AllowedPaths)
+	if err != nil {
+		return err
+	}
+
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("unable to create the sockets: %w", err)
+	}
+
+	helperFile := os.NewFile(uintptr(fds[0]), "privsep-helper")
+	workerFile := os.NewFile(uintptr(fds[1]), "privsep-worker")
+
+	conn, err := net.FileConn(helperFile)
+	_ = helperFile.Close()
+	if err != nil {
+		_ = workerFile.Close()
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	executable, err := os.Executable()
+	if err != nil {
+		_ = workerFile.Close()
+		return err
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), workerEnv+"=1")
+	cmd.ExtraFiles = []*os.File{workerFile}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential:  &syscall.Credential{Uid: uid, Gid: gid, Groups: []uint32{}},
+		AmbientCaps: []uintptr{capNetBindService},
+		Pdeathsig:   syscall.SIGTERM,
+	}
+
+	err = cmd.Start()
+	_ = workerFile.Close()
+	if err != nil {
+		return fmt.Errorf("unable to start the worker process: %w", err)
+	}
+
+	log.WithoutContext().Infof("Started the unprivileged worker process %d, as the user %d and the group %d", cmd.Process.Pid, uid, gid)
+
+	go serve(conn, allowedPaths)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1)
+	defer func() {
+		signal.Stop(signals)
+		close(signals)
+	}()
+
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	return cmd.Wait()
+}
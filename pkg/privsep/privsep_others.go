@@ -0,0 +1,13 @@
+// +build !linux
+
+package privsep
+
+import "errors"
+
+// errUnsupported is returned when the privilege separation is not supported by the platform.
+var errUnsupported = errors.New("the privilege separation is not supported on this platform")
+
+// RunHelper runs the privileged helper process, which is only supported on Linux.
+func RunHelper(_ *Configuration) error {
+	return errUnsupported
+}
@@ -18,6 +18,8 @@ type Account struct {
 	Registration *registration.Resource
 	PrivateKey   []byte
 	KeyType      certcrypto.KeyType
+	// FallbackRegistrations holds the registrations of the account on the fallback CA servers, by CA server.
+	FallbackRegistrations map[string]*registration.Resource `json:",omitempty"`
 }
 
 const (
@@ -52,6 +54,13 @@ func (a *Account) GetRegistration() *registration.Resource {
 	return a.Registration
 }
 
+func (a *Account) setFallbackRegistration(caServer string, reg *registration.Resource) {
+	if a.FallbackRegistrations == nil {
+		a.FallbackRegistrations = make(map[string]*registration.Resource)
+	}
+	a.FallbackRegistrations[caServer] = reg
+}
+
 // GetPrivateKey returns private key
 func (a *Account) GetPrivateKey() crypto.PrivateKey {
 	privateKey, err := x509.ParsePKCS1PrivateKey(a.PrivateKey)
@@ -87,3 +96,15 @@ func GetKeyType(ctx context.Context, value string) certcrypto.KeyType {
 		return certcrypto.RSA4096
 	}
 }
+
+// fallbackAccount is the account used on a fallback CA server:
+// it shares the key of the account, with the registration on the fallback CA server.
+type fallbackAccount struct {
+	*Account
+	caServer string
+}
+
+// GetRegistration returns the registration resource on the fallback CA server.
+func (a *fallbackAccount) GetRegistration() *registration.Resource {
+	return a.FallbackRegistrations[a.caServer]
+}
@@ -57,6 +57,8 @@ func (p *Provider) CreateHandler(notFoundHandler http.Handler) http.Handler {
 
 				tokenValue := getTokenValue(ctx, token, domain, p.ChallengeStore)
 				if len(tokenValue) > 0 {
+					p.domainStatuses.challengeServed(challenge.HTTP01, domain)
+
 					rw.WriteHeader(http.StatusOK)
 					_, err = rw.Write(tokenValue)
 					if err != nil {
@@ -0,0 +1,240 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// restoredChallengesTTL is how long the challenges restored from the Store are served,
+// which leaves the time for the ACME server to complete the validations started before the restart.
+const restoredChallengesTTL = 10 * time.Minute
+
+var _ ChallengeStore = (*persistentChallengeStore)(nil)
+
+// persistentChallengeStore is a ChallengeStore which keeps the pending challenges of a resolver in its Store,
+// in addition to the (shared and in memory) ChallengeStore, so that they survive a restart.
+type persistentChallengeStore struct {
+	ChallengeStore
+
+	store        Store
+	resolverName string
+
+	lock    sync.Mutex
+	pending *StoredChallengeData
+}
+
+// newPersistentChallengeStore creates a persistentChallengeStore,
+// and restores into the challengeStore the challenges which were pending at the last shutdown.
+func newPersistentChallengeStore(ctx context.Context, store Store, resolverName string, challengeStore ChallengeStore) (*persistentChallengeStore, error) {
+	s := &persistentChallengeStore{
+		ChallengeStore: challengeStore,
+		store:          store,
+		resolverName:   resolverName,
+		pending: &StoredChallengeData{
+			HTTPChallenges: make(map[string]map[string][]byte),
+			TLSChallenges:  make(map[string]*Certificate),
+		},
+	}
+
+	restored, err := store.GetChallenges(resolverName)
+	if err != nil {
+		return nil, err
+	}
+
+	if restored == nil || len(restored.HTTPChallenges) == 0 && len(restored.TLSChallenges) == 0 {
+		return s, nil
+	}
+
+	log.FromContext(ctx).Infof("Restoring %d HTTP and %d TLS pending challenges", len(restored.HTTPChallenges), len(restored.TLSChallenges))
+
+	for token, domains := range restored.HTTPChallenges {
+		for domain, keyAuth := range domains {
+			if err := s.SetHTTPChallengeToken(token, domain, keyAuth); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for domain, cert := range restored.TLSChallenges {
+		if err := s.AddTLSChallenge(domain, cert); err != nil {
+			return nil, err
+		}
+	}
+
+	time.AfterFunc(restoredChallengesTTL, func() {
+		s.removeRestored(ctx, restored)
+	})
+
+	return s, nil
+}
+
+// SetHTTPChallengeToken sets the http challenge token in the store, and persists it.
+func (s *persistentChallengeStore) SetHTTPChallengeToken(token, domain string, keyAuth []byte) error {
+	if err := s.ChallengeStore.SetHTTPChallengeToken(token, domain, keyAuth); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.pending.HTTPChallenges[token]; !ok {
+		s.pending.HTTPChallenges[token] = make(map[string][]byte)
+	}
+	s.pending.HTTPChallenges[token][domain] = keyAuth
+
+	return s.save()
+}
+
+// GetHTTPChallengeToken returns the http challenge token from the store,
+// or, when the Store is shared, from the challenges pending on the other instances.
+func (s *persistentChallengeStore) GetHTTPChallengeToken(token, domain string) ([]byte, error) {
+	keyAuth, err := s.ChallengeStore.GetHTTPChallengeToken(token, domain)
+	if err == nil {
+		return keyAuth, nil
+	}
+
+	if shared := s.sharedChallenges(); shared != nil {
+		if keyAuth, ok := shared.HTTPChallenges[token][domain]; ok {
+			return keyAuth, nil
+		}
+	}
+
+	return nil, err
+}
+
+// RemoveHTTPChallengeToken removes the http challenge token from the store, and persists the removal.
+func (s *persistentChallengeStore) RemoveHTTPChallengeToken(token, domain string) error {
+	if err := s.ChallengeStore.RemoveHTTPChallengeToken(token, domain); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.pending.HTTPChallenges[token]; ok {
+		delete(s.pending.HTTPChallenges[token], domain)
+		if len(s.pending.HTTPChallenges[token]) == 0 {
+			delete(s.pending.HTTPChallenges, token)
+		}
+	}
+
+	return s.save()
+}
+
+// AddTLSChallenge adds a TLS-ALPN-01 certificate to the store, and persists it.
+func (s *persistentChallengeStore) AddTLSChallenge(domain string, cert *Certificate) error {
+	if err := s.ChallengeStore.AddTLSChallenge(domain, cert); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.pending.TLSChallenges[domain] = cert
+
+	return s.save()
+}
+
+// GetTLSChallenge returns the TLS-ALPN-01 certificate from the store,
+// or, when the Store is shared, from the challenges pending on the other instances.
+func (s *persistentChallengeStore) GetTLSChallenge(domain string) (*Certificate, error) {
+	cert, err := s.ChallengeStore.GetTLSChallenge(domain)
+	if err == nil && cert != nil {
+		return cert, nil
+	}
+
+	if shared := s.sharedChallenges(); shared != nil && shared.TLSChallenges[domain] != nil {
+		return shared.TLSChallenges[domain], nil
+	}
+
+	return cert, err
+}
+
+// RemoveTLSChallenge removes a TLS-ALPN-01 certificate from the store, and persists the removal.
+func (s *persistentChallengeStore) RemoveTLSChallenge(domain string) error {
+	if err := s.ChallengeStore.RemoveTLSChallenge(domain); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.pending.TLSChallenges, domain)
+
+	return s.save()
+}
+
+// removeRestored removes the restored challenges which have not been replaced since.
+func (s *persistentChallengeStore) removeRestored(ctx context.Context, restored *StoredChallengeData) {
+	logger := log.FromContext(ctx)
+
+	for token, domains := range restored.HTTPChallenges {
+		for domain, keyAuth := range domains {
+			s.lock.Lock()
+			current, ok := s.pending.HTTPChallenges[token][domain]
+			s.lock.Unlock()
+
+			if !ok || !bytes.Equal(current, keyAuth) {
+				continue
+			}
+
+			if err := s.RemoveHTTPChallengeToken(token, domain); err != nil {
+				logger.Errorf("Unable to remove the restored HTTP challenge for %s: %v", domain, err)
+			}
+		}
+	}
+
+	for domain, cert := range restored.TLSChallenges {
+		s.lock.Lock()
+		current := s.pending.TLSChallenges[domain]
+		s.lock.Unlock()
+
+		if current != cert {
+			continue
+		}
+
+		if err := s.RemoveTLSChallenge(domain); err != nil {
+			logger.Errorf("Unable to remove the restored TLS challenge for %s: %v", domain, err)
+		}
+	}
+}
+
+// sharedChallenges returns the challenges stored by all the instances, when the Store is shared.
+func (s *persistentChallengeStore) sharedChallenges() *StoredChallengeData {
+	if _, ok := s.store.(SharedStore); !ok {
+		return nil
+	}
+
+	challenges, err := s.store.GetChallenges(s.resolverName)
+	if err != nil {
+		log.WithoutContext().WithField(log.ProviderName, s.resolverName+".acme").Errorf("Unable to get the shared ACME challenges: %v", err)
+		return nil
+	}
+
+	return challenges
+}
+
+// save persists a copy of the pending challenges. It must be called with the lock held.
+func (s *persistentChallengeStore) save() error {
+	data := &StoredChallengeData{
+		HTTPChallenges: make(map[string]map[string][]byte, len(s.pending.HTTPChallenges)),
+		TLSChallenges:  make(map[string]*Certificate, len(s.pending.TLSChallenges)),
+	}
+
+	for token, domains := range s.pending.HTTPChallenges {
+		data.HTTPChallenges[token] = make(map[string][]byte, len(domains))
+		for domain, keyAuth := range domains {
+			data.HTTPChallenges[token][domain] = keyAuth
+		}
+	}
+
+	for domain, cert := range s.pending.TLSChallenges {
+		data.TLSChallenges[domain] = cert
+	}
+
+	return s.store.SaveChallenges(s.resolverName, data)
+}
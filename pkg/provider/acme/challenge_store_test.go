@@ -0,0 +1,92 @@
+package acme
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersistentChallengeStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acme")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	store := NewLocalStore(filepath.Join(dir, "acme.json"), nil)
+
+	challengeStore, err := newPersistentChallengeStore(context.Background(), store, "foo", NewLocalChallengeStore())
+	require.NoError(t, err)
+
+	cert := &Certificate{Domain: types.Domain{Main: "TEMP-bar.com"}, Certificate: []byte("cert"), Key: []byte("key")}
+
+	require.NoError(t, challengeStore.SetHTTPChallengeToken("token", "foo.com", []byte("keyAuth")))
+	require.NoError(t, challengeStore.SetHTTPChallengeToken("done", "foo.com", []byte("keyAuth")))
+	require.NoError(t, challengeStore.RemoveHTTPChallengeToken("done", "foo.com"))
+	require.NoError(t, challengeStore.AddTLSChallenge("bar.com", cert))
+
+	pending, err := store.GetChallenges("foo")
+	require.NoError(t, err)
+	assert.Equal(t, &StoredChallengeData{
+		HTTPChallenges: map[string]map[string][]byte{"token": {"foo.com": []byte("keyAuth")}},
+		TLSChallenges:  map[string]*Certificate{"bar.com": cert},
+	}, pending)
+
+	// After a restart, the pending challenges are served again.
+	restarted := NewLocalChallengeStore()
+	_, err = newPersistentChallengeStore(context.Background(), store, "foo", restarted)
+	require.NoError(t, err)
+
+	keyAuth, err := restarted.GetHTTPChallengeToken("token", "foo.com")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("keyAuth"), keyAuth)
+
+	_, err = restarted.GetHTTPChallengeToken("done", "foo.com")
+	assert.Error(t, err)
+
+	tlsCert, err := restarted.GetTLSChallenge("bar.com")
+	require.NoError(t, err)
+	assert.Equal(t, cert, tlsCert)
+
+	// Once all the challenges are cleaned up, nothing is kept in the Store.
+	require.NoError(t, challengeStore.RemoveHTTPChallengeToken("token", "foo.com"))
+	require.NoError(t, challengeStore.RemoveTLSChallenge("bar.com"))
+
+	pending, err = store.GetChallenges("foo")
+	require.NoError(t, err)
+	assert.Nil(t, pending)
+}
+
+func TestPersistentChallengeStoreRemoveRestored(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acme")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	store := NewLocalStore(filepath.Join(dir, "acme.json"), nil)
+
+	restored := &StoredChallengeData{
+		HTTPChallenges: map[string]map[string][]byte{"token": {"foo.com": []byte("keyAuth")}},
+		TLSChallenges:  map[string]*Certificate{"bar.com": {Certificate: []byte("old")}},
+	}
+	require.NoError(t, store.SaveChallenges("foo", restored))
+
+	challengeStore, err := newPersistentChallengeStore(context.Background(), store, "foo", NewLocalChallengeStore())
+	require.NoError(t, err)
+
+	// A new TLS challenge for the same domain replaces the restored one.
+	newCert := &Certificate{Certificate: []byte("new")}
+	require.NoError(t, challengeStore.AddTLSChallenge("bar.com", newCert))
+
+	challengeStore.removeRestored(context.Background(), restored)
+
+	_, err = challengeStore.GetHTTPChallengeToken("token", "foo.com")
+	assert.Error(t, err)
+
+	tlsCert, err := challengeStore.GetTLSChallenge("bar.com")
+	require.NoError(t, err)
+	assert.Equal(t, newCert, tlsCert)
+}
@@ -35,6 +35,19 @@ func (c *challengeTLSALPN) CleanUp(domain, token, keyAuth string) error {
 	return c.Store.RemoveTLSChallenge(domain)
 }
 
+// ResolveCertificate returns the temp certificate for ACME TLS-ALPN-01 challenge of the ClientHello server name, if any.
+// Only the validation handshakes, offering the acme-tls/1 protocol, are resolved,
+// as the challenges pending on the other instances sharing the store are looked up in the store.
+func (p *Provider) ResolveCertificate(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	for _, proto := range clientHello.SupportedProtos {
+		if proto == tlsalpn01.ACMETLS1Protocol {
+			return p.GetTLSALPNCertificate(types.CanonicalDomain(clientHello.ServerName))
+		}
+	}
+
+	return nil, nil
+}
+
 // GetTLSALPNCertificate Get the temp certificate for ACME TLS-ALPN-O1 challenge.
 func (p *Provider) GetTLSALPNCertificate(domain string) (*tls.Certificate, error) {
 	cert, err := p.ChallengeStore.GetTLSChallenge(domain)
@@ -51,5 +64,7 @@ func (p *Provider) GetTLSALPNCertificate(domain string) (*tls.Certificate, error
 		return nil, err
 	}
 
+	p.domainStatuses.challengeServed(challenge.TLSALPN01, domain)
+
 	return &certificate, nil
 }
@@ -0,0 +1,57 @@
+package acme
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+)
+
+// aliasDNSProvider writes the TXT records of the challenges of all the domains on the _acme-challenge name of the alias zone,
+// to which the _acme-challenge name of each domain is delegated by a CNAME record.
+type aliasDNSProvider struct {
+	provider challenge.Provider
+	alias    string
+}
+
+func newAliasDNSProvider(provider challenge.Provider, alias string) *aliasDNSProvider {
+	return &aliasDNSProvider{
+		provider: provider,
+		alias:    strings.ToLower(dns01.UnFqdn(alias)),
+	}
+}
+
+func (a *aliasDNSProvider) Present(_, token, keyAuth string) error {
+	return a.provider.Present(a.alias, token, keyAuth)
+}
+
+func (a *aliasDNSProvider) CleanUp(_, token, keyAuth string) error {
+	return a.provider.CleanUp(a.alias, token, keyAuth)
+}
+
+// Timeout returns the timeout and the interval of the propagation checks of the wrapped provider.
+func (a *aliasDNSProvider) Timeout() (timeout, interval time.Duration) {
+	if p, ok := a.provider.(challenge.ProviderTimeout); ok {
+		return p.Timeout()
+	}
+	return dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+}
+
+// Sequential makes the challenges solved one after the other,
+// as the TXT records of all the domains share the same name, which not every DNS provider can hold several values for.
+func (a *aliasDNSProvider) Sequential() time.Duration {
+	if p, ok := a.provider.(interface{ Sequential() time.Duration }); ok {
+		return p.Sequential()
+	}
+	return 0
+}
+
+// aliasFqdn returns the name of the TXT record in the alias zone, if any, instead of the name of the challenge.
+func (d *DNSChallenge) aliasFqdn(fqdn string) string {
+	if d.Alias == "" {
+		return fqdn
+	}
+	return fmt.Sprintf("_acme-challenge.%s.", strings.ToLower(dns01.UnFqdn(d.Alias)))
+}
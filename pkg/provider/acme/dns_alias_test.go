@@ -0,0 +1,94 @@
+package acme
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingDNSProvider struct {
+	presented []string
+	cleaned   []string
+}
+
+func (r *recordingDNSProvider) Present(domain, _, keyAuth string) error {
+	r.presented = append(r.presented, domain+" "+keyAuth)
+	return nil
+}
+
+func (r *recordingDNSProvider) CleanUp(domain, _, keyAuth string) error {
+	r.cleaned = append(r.cleaned, domain+" "+keyAuth)
+	return nil
+}
+
+type timeoutDNSProvider struct {
+	recordingDNSProvider
+}
+
+func (t *timeoutDNSProvider) Timeout() (timeout, interval time.Duration) {
+	return 5 * time.Minute, 10 * time.Second
+}
+
+func (t *timeoutDNSProvider) Sequential() time.Duration {
+	return time.Minute
+}
+
+func TestAliasDNSProvider(t *testing.T) {
+	recorder := &recordingDNSProvider{}
+	provider := newAliasDNSProvider(recorder, "ACME.Example.net.")
+
+	require.NoError(t, provider.Present("example.com", "token", "keyAuth1"))
+	require.NoError(t, provider.Present("*.example.org", "token", "keyAuth2"))
+	require.NoError(t, provider.CleanUp("example.com", "token", "keyAuth1"))
+
+	assert.Equal(t, []string{"acme.example.net keyAuth1", "acme.example.net keyAuth2"}, recorder.presented)
+	assert.Equal(t, []string{"acme.example.net keyAuth1"}, recorder.cleaned)
+
+	timeout, interval := provider.Timeout()
+	assert.Equal(t, dns01.DefaultPropagationTimeout, timeout)
+	assert.Equal(t, dns01.DefaultPollingInterval, interval)
+	assert.Equal(t, time.Duration(0), provider.Sequential())
+
+	provider = newAliasDNSProvider(&timeoutDNSProvider{}, "acme.example.net")
+
+	timeout, interval = provider.Timeout()
+	assert.Equal(t, 5*time.Minute, timeout)
+	assert.Equal(t, 10*time.Second, interval)
+	assert.Equal(t, time.Minute, provider.Sequential())
+}
+
+func TestDNSChallenge_aliasFqdn(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		alias    string
+		expected string
+	}{
+		{
+			desc:     "no alias",
+			expected: "_acme-challenge.example.com.",
+		},
+		{
+			desc:     "alias",
+			alias:    "acme.example.net",
+			expected: "_acme-challenge.acme.example.net.",
+		},
+		{
+			desc:     "fully qualified alias",
+			alias:    "ACME.example.net.",
+			expected: "_acme-challenge.acme.example.net.",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			d := &DNSChallenge{Alias: test.alias}
+			assert.Equal(t, test.expected, d.aliasFqdn("_acme-challenge.example.com."))
+		})
+	}
+}
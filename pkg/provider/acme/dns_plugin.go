@@ -0,0 +1,57 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/providers/dns"
+)
+
+// DNSPlugin holds the configuration of the DNS provider plugin solving the DNS-01 challenge.
+type DNSPlugin struct {
+	Name   string            `description:"Name of the DNS provider plugin, among the plugins of the static configuration." json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
+	Config map[string]string `description:"Options of the DNS provider plugin." json:"config,omitempty" toml:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// DNSPlugins creates the DNS providers of the plugins.
+type DNSPlugins interface {
+	NewDNSProvider(name string, options map[string]string) (challenge.Provider, error)
+}
+
+func (d *DNSChallenge) enabled() bool {
+	return d != nil && (len(d.Provider) > 0 || d.Plugin != nil)
+}
+
+func (d *DNSChallenge) validatePlugin() error {
+	if len(d.Provider) > 0 {
+		return errors.New("a provider and a plugin cannot be both defined")
+	}
+
+	if d.Plugin.Name == "" {
+		return errors.New("no plugin name")
+	}
+
+	return nil
+}
+
+// newDNSProvider creates the DNS provider solving the DNS-01 challenge, from the plugin if any, or from lego.
+func (p *Provider) newDNSProvider(ctx context.Context) (challenge.Provider, error) {
+	logger := log.FromContext(ctx)
+
+	if p.DNSChallenge.Plugin == nil {
+		logger.Debugf("Using DNS Challenge provider: %s", p.DNSChallenge.Provider)
+		return dns.NewDNSChallengeProviderByName(p.DNSChallenge.Provider)
+	}
+
+	name := p.DNSChallenge.Plugin.Name
+	logger.Debugf("Using DNS Challenge provider plugin: %s", name)
+
+	if p.DNSPlugins == nil {
+		return nil, fmt.Errorf("unable to use the DNS provider plugin %q: no plugin is loaded", name)
+	}
+
+	return p.DNSPlugins.NewDNSProvider(name, p.DNSChallenge.Plugin.Config)
+}
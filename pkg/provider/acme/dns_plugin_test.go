@@ -0,0 +1,91 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDNSProvider struct {
+	name    string
+	options map[string]string
+}
+
+func (f *fakeDNSProvider) Present(_, _, _ string) error { return nil }
+
+func (f *fakeDNSProvider) CleanUp(_, _, _ string) error { return nil }
+
+type fakeDNSPlugins struct{}
+
+func (f fakeDNSPlugins) NewDNSProvider(name string, options map[string]string) (challenge.Provider, error) {
+	if name != "example" {
+		return nil, errors.New("unknown plugin")
+	}
+	return &fakeDNSProvider{name: name, options: options}, nil
+}
+
+func TestDNSChallenge_validatePlugin(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		challenge   *DNSChallenge
+		expectedErr bool
+	}{
+		{
+			desc:      "plugin",
+			challenge: &DNSChallenge{Plugin: &DNSPlugin{Name: "example"}},
+		},
+		{
+			desc:        "plugin and provider",
+			challenge:   &DNSChallenge{Provider: "manual", Plugin: &DNSPlugin{Name: "example"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "no plugin name",
+			challenge:   &DNSChallenge{Plugin: &DNSPlugin{}},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.challenge.validatePlugin()
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestProvider_newDNSProvider_plugin(t *testing.T) {
+	options := map[string]string{"apiKey": "secret"}
+
+	p := &Provider{
+		Configuration: &Configuration{
+			DNSChallenge: &DNSChallenge{Plugin: &DNSPlugin{Name: "example", Config: options}},
+		},
+	}
+
+	// No plugin is loaded.
+	_, err := p.newDNSProvider(context.Background())
+	assert.Error(t, err)
+
+	p.DNSPlugins = fakeDNSPlugins{}
+
+	provider, err := p.newDNSProvider(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &fakeDNSProvider{name: "example", options: options}, provider)
+
+	p.DNSChallenge.Plugin.Name = "unknown"
+
+	_, err = p.newDNSProvider(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/miekg/dns"
+)
+
+// AuthoritativeCheck contains the configuration of the DNS propagation check on the authoritative nameservers.
+type AuthoritativeCheck struct {
+	Timeout  types.Duration `description:"Maximum duration to wait for the TXT record to be served by all the authoritative nameservers." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	Interval types.Duration `description:"Duration between two checks of the authoritative nameservers." json:"interval,omitempty" toml:"interval,omitempty" yaml:"interval,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (a *AuthoritativeCheck) SetDefaults() {
+	a.Timeout = types.Duration(2 * time.Minute)
+	a.Interval = types.Duration(2 * time.Second)
+}
+
+// preCheckAuthoritative waits until the TXT record of the challenge is served by all the authoritative nameservers of its zone,
+// queried directly, rather than through the recursive nameservers, whose caches may hold stale or split-horizon answers.
+func (d *DNSChallenge) preCheckAuthoritative(_, fqdn, value string, _ dns01.PreCheckFunc) (bool, error) {
+	if d.DelayBeforeCheck > 0 {
+		log.Debugf("Delaying %s before checking the authoritative nameservers.", time.Duration(d.DelayBeforeCheck))
+		time.Sleep(time.Duration(d.DelayBeforeCheck))
+	}
+
+	fqdn = d.aliasFqdn(fqdn)
+
+	nameservers, err := d.authoritativeNameservers(fqdn)
+	if err != nil {
+		return false, err
+	}
+
+	timeout := time.Duration(d.AuthoritativeCheck.Timeout)
+	interval := time.Duration(d.AuthoritativeCheck.Interval)
+
+	return waitForPropagation(fqdn, value, nameservers, timeout, interval)
+}
+
+// authoritativeNameservers returns the addresses of the authoritative nameservers of the zone of the fqdn,
+// looked up with the resolvers of the challenge, or with the system resolvers.
+func (d *DNSChallenge) authoritativeNameservers(fqdn string) ([]string, error) {
+	var hosts []string
+
+	if len(d.Resolvers) > 0 {
+		resolvers := dns01.ParseNameservers(d.Resolvers)
+
+		zone, err := dns01.FindZoneByFqdnCustom(fqdn, resolvers)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the zone of %s: %w", fqdn, err)
+		}
+
+		msg, err := queryDNS(zone, dns.TypeNS, resolvers, true)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rr := range msg.Answer {
+			if ns, ok := rr.(*dns.NS); ok {
+				hosts = append(hosts, ns.Ns)
+			}
+		}
+	} else {
+		zone, err := dns01.FindZoneByFqdn(fqdn)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the zone of %s: %w", fqdn, err)
+		}
+
+		nss, err := net.LookupNS(dns01.UnFqdn(zone))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ns := range nss {
+			hosts = append(hosts, ns.Host)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("could not determine the authoritative nameservers of %s", fqdn)
+	}
+
+	var nameservers []string
+	for _, host := range hosts {
+		nameservers = append(nameservers, net.JoinHostPort(strings.ToLower(dns01.UnFqdn(host)), "53"))
+	}
+
+	return nameservers, nil
+}
+
+// waitForPropagation checks the nameservers every interval, until they all serve the TXT record, or the timeout expires.
+func waitForPropagation(fqdn, value string, nameservers []string, timeout, interval time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := checkNameservers(fqdn, value, nameservers)
+		if err == nil {
+			return true, nil
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return false, fmt.Errorf("TXT record not propagated to the authoritative nameservers after %s: %w", timeout, err)
+		}
+
+		log.Debugf("Waiting for the propagation of the TXT record to the authoritative nameservers: %v", err)
+		time.Sleep(interval)
+	}
+}
+
+// checkNameservers checks that each nameserver serves the TXT record.
+func checkNameservers(fqdn, value string, nameservers []string) error {
+	for _, ns := range nameservers {
+		msg, err := queryDNS(fqdn, dns.TypeTXT, []string{ns}, false)
+		if err != nil {
+			return err
+		}
+
+		if msg.Rcode != dns.RcodeSuccess {
+			return fmt.Errorf("NS %s returned %s for %s", ns, dns.RcodeToString[msg.Rcode], fqdn)
+		}
+
+		var found bool
+		for _, rr := range msg.Answer {
+			if txt, ok := rr.(*dns.TXT); ok && strings.Join(txt.Txt, "") == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("NS %s did not return the TXT record of %s", ns, fqdn)
+		}
+	}
+
+	return nil
+}
+
+// queryDNS sends the query to the nameservers, in order, until one of them answers.
+func queryDNS(name string, rtype uint16, nameservers []string, recursive bool) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), rtype)
+	msg.RecursionDesired = recursive
+
+	client := &dns.Client{Timeout: 10 * time.Second}
+
+	err := errors.New("no nameserver")
+	for _, ns := range nameservers {
+		var resp *dns.Msg
+		resp, _, err = client.Exchange(msg, ns)
+		if err == nil {
+			return resp, nil
+		}
+	}
+
+	return nil, fmt.Errorf("DNS query for %s failed: %w", name, err)
+}
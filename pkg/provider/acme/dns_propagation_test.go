@@ -0,0 +1,94 @@
+package acme
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// txtServer is an authoritative nameserver serving the TXT record once it has been queried propagatedAfter times.
+type txtServer struct {
+	value           string
+	propagatedAfter int32
+	queries         int32
+	recursive       int32
+}
+
+func (s *txtServer) ServeDNS(rw dns.ResponseWriter, req *dns.Msg) {
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+	resp.Authoritative = true
+
+	if req.RecursionDesired {
+		atomic.AddInt32(&s.recursive, 1)
+	}
+
+	if atomic.AddInt32(&s.queries, 1) > s.propagatedAfter {
+		resp.Answer = append(resp.Answer, &dns.TXT{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 60},
+			Txt: []string{s.value},
+		})
+	}
+
+	_ = rw.WriteMsg(resp)
+}
+
+func startNameserver(t *testing.T, handler dns.Handler) (string, func()) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: conn, Handler: handler, NotifyStartedFunc: func() { close(started) }}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+
+	return conn.LocalAddr().String(), func() { _ = server.Shutdown() }
+}
+
+func TestCheckNameservers(t *testing.T) {
+	propagated := &txtServer{value: "token"}
+	propagatedAddr, shutdown := startNameserver(t, propagated)
+	defer shutdown()
+
+	stale := &txtServer{value: "token", propagatedAfter: 1000}
+	staleAddr, shutdownStale := startNameserver(t, stale)
+	defer shutdownStale()
+
+	err := checkNameservers("_acme-challenge.example.com.", "token", []string{propagatedAddr})
+	require.NoError(t, err)
+	assert.Zero(t, atomic.LoadInt32(&propagated.recursive))
+
+	err = checkNameservers("_acme-challenge.example.com.", "other", []string{propagatedAddr})
+	assert.Error(t, err)
+
+	err = checkNameservers("_acme-challenge.example.com.", "token", []string{propagatedAddr, staleAddr})
+	assert.Error(t, err)
+}
+
+func TestWaitForPropagation(t *testing.T) {
+	server := &txtServer{value: "token", propagatedAfter: 2}
+	address, shutdown := startNameserver(t, server)
+	defer shutdown()
+
+	ok, err := waitForPropagation("_acme-challenge.example.com.", "token", []string{address}, time.Second, 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&server.queries))
+}
+
+func TestWaitForPropagation_timeout(t *testing.T) {
+	server := &txtServer{value: "token", propagatedAfter: 1000}
+	address, shutdown := startNameserver(t, server)
+	defer shutdown()
+
+	ok, err := waitForPropagation("_acme-challenge.example.com.", "token", []string{address}, 50*time.Millisecond, 10*time.Millisecond)
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
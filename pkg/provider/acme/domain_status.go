@@ -0,0 +1,186 @@
+package acme
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-acme/lego/v3/challenge"
+)
+
+// Certificate statuses of the domains.
+const (
+	DomainStatusPending = "pending"
+	DomainStatusValid   = "valid"
+	DomainStatusError   = "error"
+)
+
+var domainStatuses = []string{DomainStatusPending, DomainStatusValid, DomainStatusError}
+
+// DomainStatus holds the state of the certificate of a domain managed by an ACME resolver.
+type DomainStatus struct {
+	Resolver string       `json:"resolver"`
+	Domain   types.Domain `json:"domain"`
+	Status   string       `json:"status"`
+	Error    string       `json:"error,omitempty"`
+	CAServer string       `json:"caServer,omitempty"`
+	// FallbackResolver is the resolver which obtained the certificate of the domain, when this resolver failed.
+	FallbackResolver string     `json:"fallbackResolver,omitempty"`
+	NotAfter         *time.Time `json:"notAfter,omitempty"`
+	NextRenewal      *time.Time `json:"nextRenewal,omitempty"`
+	LastAttempt      *time.Time `json:"lastAttempt,omitempty"`
+}
+
+// Name returns the name identifying the domain, made of its main domain followed by its SANs.
+func (d DomainStatus) Name() string {
+	return strings.Join(d.Domain.ToStrArray(), ",")
+}
+
+// domainStatusTracker keeps the status of the domains of a resolver, and reports it as metrics.
+type domainStatusTracker struct {
+	resolverName    string
+	metricsRegistry metrics.Registry
+
+	lock     sync.RWMutex
+	statuses map[string]*DomainStatus
+}
+
+func newDomainStatusTracker(resolverName string, metricsRegistry metrics.Registry) *domainStatusTracker {
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &domainStatusTracker{
+		resolverName:    resolverName,
+		metricsRegistry: metricsRegistry,
+		statuses:        make(map[string]*DomainStatus),
+	}
+}
+
+// pending marks the domain as being resolved.
+func (t *domainStatusTracker) pending(domain types.Domain) {
+	t.update(domain, func(status *DomainStatus) {
+		now := time.Now()
+		status.Status = DomainStatusPending
+		status.FallbackResolver = ""
+		status.LastAttempt = &now
+	})
+}
+
+// failed marks the resolution of the domain as failed,
+// the previous certificate of the domain, if any, being kept.
+func (t *domainStatusTracker) failed(domain types.Domain, err error) {
+	t.update(domain, func(status *DomainStatus) {
+		now := time.Now()
+		status.Status = DomainStatusError
+		status.Error = err.Error()
+		status.LastAttempt = &now
+	})
+}
+
+// valid marks the domain as having a certificate issued by caServer, valid until notAfter, and renewed from nextRenewal.
+func (t *domainStatusTracker) valid(domain types.Domain, notAfter, nextRenewal time.Time, caServer string) {
+	t.update(domain, func(status *DomainStatus) {
+		status.Status = DomainStatusValid
+		status.Error = ""
+		status.FallbackResolver = ""
+		status.CAServer = caServer
+		status.NotAfter = &notAfter
+		status.NextRenewal = &nextRenewal
+	})
+}
+
+// fellBack records that the certificate of the domain was obtained by the fallback resolver, after this resolver failed.
+func (t *domainStatusTracker) fellBack(domain types.Domain, resolverName string) {
+	t.update(domain, func(status *DomainStatus) {
+		status.FallbackResolver = resolverName
+	})
+}
+
+func (t *domainStatusTracker) update(domain types.Domain, apply func(status *DomainStatus)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	name := strings.Join(domain.ToStrArray(), ",")
+
+	status, ok := t.statuses[name]
+	if !ok {
+		status = &DomainStatus{Resolver: t.resolverName, Domain: domain}
+		t.statuses[name] = status
+	}
+
+	apply(status)
+
+	for _, s := range domainStatuses {
+		value := 0.
+		if s == status.Status {
+			value = 1
+		}
+		t.metricsRegistry.ACMECertificateStatusGauge().With("resolver", t.resolverName, "domain", name, "status", s).Set(value)
+	}
+
+	if status.NotAfter != nil {
+		t.metricsRegistry.ACMECertificateNotAfterGauge().With("resolver", t.resolverName, "domain", name).Set(float64(status.NotAfter.Unix()))
+	}
+}
+
+// challengeServed counts a challenge validation request of the ACME server.
+func (t *domainStatusTracker) challengeServed(challengeType challenge.Type, domain string) {
+	t.metricsRegistry.ACMEChallengeRequestsCounter().With("type", challengeType.String(), "domain", domain).Add(1)
+}
+
+// list returns a copy of the statuses, sorted by domain name.
+func (t *domainStatusTracker) list() []DomainStatus {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	statuses := make([]DomainStatus, 0, len(t.statuses))
+	for _, status := range t.statuses {
+		statuses = append(statuses, *status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name() < statuses[j].Name()
+	})
+
+	return statuses
+}
+
+// SetMetricsRegistry sets the metrics registry used to report the status of the certificates.
+func (p *Provider) SetMetricsRegistry(metricsRegistry metrics.Registry) {
+	p.metricsRegistry = metricsRegistry
+}
+
+// GetDomainStatuses returns the status of the certificates of the domains managed by the resolver.
+func (p *Provider) GetDomainStatuses() []DomainStatus {
+	return p.domainStatuses.list()
+}
+
+// initDomainStatuses sets the status of the domains from the certificates loaded from the Store.
+func (p *Provider) initDomainStatuses(ctx context.Context) {
+	p.domainStatuses = newDomainStatusTracker(p.ResolverName, p.metricsRegistry)
+
+	for _, cert := range p.certificates {
+		p.certificateObtained(ctx, &cert.Certificate)
+	}
+}
+
+// certificateObtained marks the domain of the certificate as valid.
+// The status of a domain is the status of its certificate of the keyType of the resolver, its additional certificate being left out.
+func (p *Provider) certificateObtained(ctx context.Context, cert *Certificate) {
+	if cert.KeyType != "" {
+		return
+	}
+
+	crt, err := getX509Certificate(ctx, cert)
+	if err != nil {
+		p.domainStatuses.failed(cert.Domain, err)
+		return
+	}
+
+	p.domainStatuses.valid(cert.Domain, crt.NotAfter, p.renewalTime(cert.Domain, crt.NotAfter), cert.CAServer)
+}
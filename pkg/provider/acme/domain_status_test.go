@@ -0,0 +1,62 @@
+package acme
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainStatusTracker(t *testing.T) {
+	tracker := newDomainStatusTracker("myresolver", nil)
+
+	foo := types.Domain{Main: "foo.example.com", SANs: []string{"www.foo.example.com"}}
+	bar := types.Domain{Main: "bar.example.com"}
+
+	tracker.pending(foo)
+	tracker.pending(bar)
+
+	statuses := tracker.list()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "bar.example.com", statuses[0].Name())
+	assert.Equal(t, "foo.example.com,www.foo.example.com", statuses[1].Name())
+
+	for _, status := range statuses {
+		assert.Equal(t, "myresolver", status.Resolver)
+		assert.Equal(t, DomainStatusPending, status.Status)
+		assert.NotNil(t, status.LastAttempt)
+		assert.Nil(t, status.NotAfter)
+	}
+
+	notAfter := time.Now().Add(90 * 24 * time.Hour)
+	nextRenewal := notAfter.Add(-30 * 24 * time.Hour)
+	tracker.valid(foo, notAfter, nextRenewal, "https://acme.example.com/directory")
+	tracker.failed(bar, errors.New("connection refused"))
+
+	statuses = tracker.list()
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, DomainStatusError, statuses[0].Status)
+	assert.Equal(t, "connection refused", statuses[0].Error)
+	assert.Nil(t, statuses[0].NotAfter)
+
+	assert.Equal(t, DomainStatusValid, statuses[1].Status)
+	assert.Empty(t, statuses[1].Error)
+	assert.Equal(t, "https://acme.example.com/directory", statuses[1].CAServer)
+	require.NotNil(t, statuses[1].NotAfter)
+	assert.Equal(t, notAfter, *statuses[1].NotAfter)
+	require.NotNil(t, statuses[1].NextRenewal)
+	assert.Equal(t, nextRenewal, *statuses[1].NextRenewal)
+
+	// A failed renewal keeps the information about the current certificate.
+	tracker.failed(foo, errors.New("rate limited"))
+
+	statuses = tracker.list()
+	assert.Equal(t, DomainStatusError, statuses[1].Status)
+	assert.Equal(t, "rate limited", statuses[1].Error)
+	require.NotNil(t, statuses[1].NotAfter)
+	assert.Equal(t, notAfter, *statuses[1].NotAfter)
+}
@@ -0,0 +1,105 @@
+package acme
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/go-acme/lego/v3/lego"
+)
+
+const (
+	rateLimitedErr    = "urn:ietf:params:acme:error:rateLimited"
+	serverInternalErr = "urn:ietf:params:acme:error:serverInternal"
+)
+
+// problemStatusRegexp matches the HTTP status of the ACME problems in the error messages,
+// as the errors are aggregated per domain by lego.
+var problemStatusRegexp = regexp.MustCompile(`acme: error: (\d{3})`)
+
+// caServers returns the CA servers of the resolver, in priority order.
+func (p *Provider) caServers() []FallbackCAServer {
+	caServer := lego.LEDirectoryProduction
+	if len(p.CAServer) > 0 {
+		caServer = p.CAServer
+	}
+
+	return append([]FallbackCAServer{{CAServer: caServer, EAB: p.EAB}}, p.FallbackCAServers...)
+}
+
+// withFallback calls the operation with the client of each CA server of the resolver, in priority order,
+// until the operation succeeds, or fails with an error which is not caused by the CA server.
+// It returns the CA server used by the successful call, and notifies the rate limits of the CA servers for the domains.
+func (p *Provider) withFallback(ctx context.Context, domains []string, operation func(client *lego.Client) error) (string, error) {
+	logger := log.FromContext(ctx)
+
+	caServers := p.caServers()
+
+	var err error
+	for i, caServer := range caServers {
+		var client *lego.Client
+		client, err = p.getClient(caServer)
+		if err != nil {
+			err = fmt.Errorf("cannot get ACME client %v", err)
+		} else {
+			err = operation(client)
+			if err == nil {
+				return caServer.CAServer, nil
+			}
+
+			if !isFallbackError(err) {
+				return "", err
+			}
+
+			if isRateLimitError(err) {
+				p.notify(ctx, Event{Type: EventRateLimited, Domains: domains, CAServer: caServer.CAServer, Error: err.Error()})
+			}
+		}
+
+		if i < len(caServers)-1 {
+			logger.Warnf("Unable to use the CA server %s, falling back to %s: %v", caServer.CAServer, caServers[i+1].CAServer, err)
+		}
+	}
+
+	return "", err
+}
+
+// isFallbackError returns whether the error is caused by the CA server (rate limit or server error),
+// in which case the next CA server is tried.
+func isFallbackError(err error) bool {
+	msg := err.Error()
+
+	if strings.Contains(msg, rateLimitedErr) || strings.Contains(msg, serverInternalErr) {
+		return true
+	}
+
+	for _, match := range problemStatusRegexp.FindAllStringSubmatch(msg, -1) {
+		status, errA := strconv.Atoi(match[1])
+		if errA == nil && (status == http.StatusTooManyRequests || status >= http.StatusInternalServerError) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRateLimitError returns whether the error is caused by a rate limit of the CA server.
+func isRateLimitError(err error) bool {
+	msg := err.Error()
+
+	if strings.Contains(msg, rateLimitedErr) {
+		return true
+	}
+
+	for _, match := range problemStatusRegexp.FindAllStringSubmatch(msg, -1) {
+		if match[1] == strconv.Itoa(http.StatusTooManyRequests) {
+			return true
+		}
+	}
+
+	return false
+}
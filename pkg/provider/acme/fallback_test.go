@@ -0,0 +1,231 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsFallbackError(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{
+			desc:     "rate limited",
+			err:      errors.New("acme: error: 429 :: POST :: https://acme.example.com/acme/new-order :: urn:ietf:params:acme:error:rateLimited :: Error creating new order :: too many certificates already issued"),
+			expected: true,
+		},
+		{
+			desc:     "server internal error",
+			err:      errors.New("acme: error: 500 :: POST :: https://acme.example.com/acme/finalize :: urn:ietf:params:acme:error:serverInternal :: Error finalizing order"),
+			expected: true,
+		},
+		{
+			desc:     "service unavailable",
+			err:      errors.New("acme: error: 503 :: GET :: https://acme.example.com/directory :: :: "),
+			expected: true,
+		},
+		{
+			desc:     "aggregated per domain",
+			err:      errors.New("acme: Error -> One or more domains had a problem:\n[foo.example.com] acme: error: 429 :: POST :: https://acme.example.com/acme/new-authz :: too many failed authorizations\n"),
+			expected: true,
+		},
+		{
+			desc:     "unauthorized",
+			err:      errors.New("acme: error: 403 :: urn:ietf:params:acme:error:unauthorized :: Invalid response from http://foo.example.com/.well-known/acme-challenge/token"),
+			expected: false,
+		},
+		{
+			desc:     "not an ACME problem",
+			err:      errors.New("unable to generate a certificate in ACME provider when no domain is given"),
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, isFallbackError(test.err))
+		})
+	}
+}
+
+func TestIsRateLimitError(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{
+			desc:     "rate limited",
+			err:      errors.New("acme: error: 429 :: POST :: https://acme.example.com/acme/new-order :: urn:ietf:params:acme:error:rateLimited :: Error creating new order :: too many certificates already issued"),
+			expected: true,
+		},
+		{
+			desc:     "aggregated per domain",
+			err:      errors.New("acme: Error -> One or more domains had a problem:\n[foo.example.com] acme: error: 429 :: POST :: https://acme.example.com/acme/new-authz :: too many failed authorizations\n"),
+			expected: true,
+		},
+		{
+			desc:     "server internal error",
+			err:      errors.New("acme: error: 500 :: POST :: https://acme.example.com/acme/finalize :: urn:ietf:params:acme:error:serverInternal :: Error finalizing order"),
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, isRateLimitError(test.err))
+		})
+	}
+}
+
+func TestWithFallback(t *testing.T) {
+	primary := &lego.Client{}
+	fallback1 := &lego.Client{}
+	fallback2 := &lego.Client{}
+
+	testCases := []struct {
+		desc             string
+		errors           map[*lego.Client]error
+		expectedCAServer string
+		expectedCalls    []*lego.Client
+		expectedErr      bool
+	}{
+		{
+			desc:             "primary succeeds",
+			expectedCAServer: "https://primary.example.com/directory",
+			expectedCalls:    []*lego.Client{primary},
+		},
+		{
+			desc: "primary rate limited",
+			errors: map[*lego.Client]error{
+				primary: errors.New("acme: error: 429 :: urn:ietf:params:acme:error:rateLimited :: too many certificates already issued"),
+			},
+			expectedCAServer: "https://fallback1.example.com/directory",
+			expectedCalls:    []*lego.Client{primary, fallback1},
+		},
+		{
+			desc: "primary and first fallback unavailable",
+			errors: map[*lego.Client]error{
+				primary:   errors.New("acme: error: 503 :: service unavailable"),
+				fallback1: errors.New("acme: error: 500 :: urn:ietf:params:acme:error:serverInternal :: internal error"),
+			},
+			expectedCAServer: "https://fallback2.example.com/directory",
+			expectedCalls:    []*lego.Client{primary, fallback1, fallback2},
+		},
+		{
+			desc: "primary fails with a validation error",
+			errors: map[*lego.Client]error{
+				primary: errors.New("acme: error: 403 :: urn:ietf:params:acme:error:unauthorized :: invalid response"),
+			},
+			expectedCalls: []*lego.Client{primary},
+			expectedErr:   true,
+		},
+		{
+			desc: "all CA servers fail",
+			errors: map[*lego.Client]error{
+				primary:   errors.New("acme: error: 503 :: service unavailable"),
+				fallback1: errors.New("acme: error: 503 :: service unavailable"),
+				fallback2: errors.New("acme: error: 429 :: urn:ietf:params:acme:error:rateLimited :: too many requests"),
+			},
+			expectedCalls: []*lego.Client{primary, fallback1, fallback2},
+			expectedErr:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Provider{
+				Configuration: &Configuration{
+					CAServer: "https://primary.example.com/directory",
+					FallbackCAServers: []FallbackCAServer{
+						{CAServer: "https://fallback1.example.com/directory"},
+						{CAServer: "https://fallback2.example.com/directory"},
+					},
+				},
+				clients: map[string]*lego.Client{
+					"https://primary.example.com/directory":   primary,
+					"https://fallback1.example.com/directory": fallback1,
+					"https://fallback2.example.com/directory": fallback2,
+				},
+			}
+
+			var calls []*lego.Client
+			caServer, err := p.withFallback(context.Background(), []string{"example.com"}, func(client *lego.Client) error {
+				calls = append(calls, client)
+				return test.errors[client]
+			})
+
+			assert.Equal(t, test.expectedCalls, calls)
+
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedCAServer, caServer)
+		})
+	}
+}
+
+func TestProvider_resolveCertificateWithFallback(t *testing.T) {
+	newProvider := func(name string, dnsChallenge *DNSChallenge, certificates ...*CertAndStore) *Provider {
+		p := &Provider{
+			Configuration:    &Configuration{DNSChallenge: dnsChallenge},
+			ResolverName:     name,
+			certificates:     certificates,
+			tlsManager:       traefiktls.NewManager(),
+			resolvingDomains: make(map[string]struct{}),
+		}
+		p.domainStatuses = newDomainStatusTracker(name, nil)
+		return p
+	}
+
+	wildcard := types.Domain{Main: "*.example.com"}
+
+	// Without DNS challenge, the resolvers fail to obtain wildcard certificates.
+	primary := newProvider("primary", nil)
+	failing := newProvider("failing", nil)
+	// The certificate is already obtained, the resolver succeeds without requesting the CA.
+	secondary := newProvider("secondary", &DNSChallenge{Provider: "manual"}, &CertAndStore{Certificate: Certificate{Domain: wildcard}, Store: "default"})
+
+	resolvers := map[string]*Provider{"primary": primary, "failing": failing, "secondary": secondary}
+	for _, p := range resolvers {
+		p.SetFallbackResolvers(resolvers)
+	}
+
+	err := primary.resolveCertificateWithFallback(context.Background(), wildcard, "default", []string{"unknown", "primary", "failing"})
+	require.Error(t, err)
+
+	statuses := primary.GetDomainStatuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, DomainStatusError, statuses[0].Status)
+	assert.Empty(t, statuses[0].FallbackResolver)
+	assert.Len(t, failing.GetDomainStatuses(), 1)
+
+	err = primary.resolveCertificateWithFallback(context.Background(), wildcard, "default", []string{"failing", "secondary"})
+	require.NoError(t, err)
+
+	statuses = primary.GetDomainStatuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, DomainStatusError, statuses[0].Status)
+	assert.Equal(t, "secondary", statuses[0].FallbackResolver)
+}
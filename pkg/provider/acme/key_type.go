@@ -0,0 +1,115 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+var keyTypes = []string{"EC256", "EC384", "RSA2048", "RSA4096", "RSA8192"}
+
+func (p *Provider) validateAdditionalKeyType() error {
+	if p.AdditionalKeyType == "" {
+		return nil
+	}
+
+	valid := false
+	for _, keyType := range keyTypes {
+		if p.AdditionalKeyType == keyType {
+			valid = true
+			break
+		}
+	}
+
+	if !valid {
+		return fmt.Errorf("invalid additionalKeyType %q: allowed values are %s", p.AdditionalKeyType, strings.Join(keyTypes, ", "))
+	}
+
+	// The TLS stores keep a single certificate per domains and public key algorithm.
+	if isECKeyType(p.AdditionalKeyType) == isECKeyType(p.KeyType) {
+		return errors.New("additionalKeyType must be an EC key type along with an RSA keyType, or the reverse")
+	}
+
+	return nil
+}
+
+func isECKeyType(keyType string) bool {
+	return strings.HasPrefix(keyType, "EC")
+}
+
+// sameCertificate returns whether both certificates are for the same domain and key type, i.e. one replaces the other.
+func sameCertificate(a, b *Certificate) bool {
+	return reflect.DeepEqual(a.Domain, b.Domain) && a.KeyType == b.KeyType
+}
+
+// resolveAdditionalCertificate obtains the certificate of the additionalKeyType of the domain,
+// along with its certificate of the keyType of the resolver.
+func (p *Provider) resolveAdditionalCertificate(ctx context.Context, domain types.Domain, domains []string, tlsStore string) error {
+	obtained := false
+	cert, err := p.withStoreLock(func(stored []*CertAndStore) (*CertAndStore, error) {
+		if cert := findCertificate(ctx, stored, domain.ToStrArray(), tlsStore, p.AdditionalKeyType); cert != nil {
+			log.FromContext(ctx).Debugf("The %s certificate for the domains %v has been obtained by another instance", p.AdditionalKeyType, domain.ToStrArray())
+			return cert, nil
+		}
+
+		resource, caServer, err := p.obtainCertificate(ctx, domains, domain.ToStrArray(), p.AdditionalKeyType)
+		if err != nil {
+			return nil, err
+		}
+		obtained = true
+
+		return &CertAndStore{
+			Certificate: Certificate{Domain: domain, Certificate: resource.Certificate, Key: resource.PrivateKey, CAServer: caServer, KeyType: p.AdditionalKeyType},
+			Store:       tlsStore,
+		}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	p.addCertificate(cert)
+
+	if obtained {
+		p.notifyCertificate(ctx, EventObtained, &cert.Certificate)
+	}
+
+	return nil
+}
+
+// resolveMissingAdditionalCertificates obtains the certificates of the additionalKeyType missing for the certificates of the keyType,
+// e.g. obtained before additionalKeyType was set.
+func (p *Provider) resolveMissingAdditionalCertificates(ctx context.Context) {
+	if p.AdditionalKeyType == "" {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+
+	certificates := p.certificates
+	for _, cert := range certificates {
+		if cert.KeyType != "" || hasAdditionalCertificate(certificates, cert, p.AdditionalKeyType) {
+			continue
+		}
+
+		logger.Infof("Obtaining the missing %s certificate for %+v", p.AdditionalKeyType, cert.Domain)
+
+		if err := p.resolveAdditionalCertificate(ctx, cert.Domain, cert.Domain.ToStrArray(), cert.Store); err != nil {
+			logger.Errorf("Unable to obtain the %s ACME certificate for domains %q: %v", p.AdditionalKeyType, strings.Join(cert.Domain.ToStrArray(), ","), err)
+		}
+	}
+}
+
+func hasAdditionalCertificate(certificates []*CertAndStore, cert *CertAndStore, keyType string) bool {
+	for _, c := range certificates {
+		if c.Store == cert.Store && c.KeyType == keyType && reflect.DeepEqual(c.Domain, cert.Domain) {
+			return true
+		}
+	}
+
+	return false
+}
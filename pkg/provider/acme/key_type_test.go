@@ -0,0 +1,101 @@
+package acme
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_validateAdditionalKeyType(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		keyType           string
+		additionalKeyType string
+		expectedErr       string
+	}{
+		{
+			desc:    "no additional key type",
+			keyType: "RSA4096",
+		},
+		{
+			desc:              "EC along with RSA",
+			keyType:           "RSA4096",
+			additionalKeyType: "EC256",
+		},
+		{
+			desc:              "RSA along with EC",
+			keyType:           "EC384",
+			additionalKeyType: "RSA2048",
+		},
+		{
+			desc:              "EC along with the default key type",
+			additionalKeyType: "EC256",
+		},
+		{
+			desc:              "same algorithm",
+			keyType:           "RSA4096",
+			additionalKeyType: "RSA2048",
+			expectedErr:       "additionalKeyType must be an EC key type along with an RSA keyType, or the reverse",
+		},
+		{
+			desc:              "invalid key type",
+			keyType:           "RSA4096",
+			additionalKeyType: "EC521",
+			expectedErr:       `invalid additionalKeyType "EC521": allowed values are EC256, EC384, RSA2048, RSA4096, RSA8192`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Provider{Configuration: &Configuration{KeyType: test.keyType, AdditionalKeyType: test.additionalKeyType}}
+
+			err := p.validateAdditionalKeyType()
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMergeCertificate_keyType(t *testing.T) {
+	domain := types.Domain{Main: "example.com"}
+
+	rsa := &CertAndStore{Certificate: Certificate{Domain: domain, Certificate: []byte("rsa")}, Store: "default"}
+	ec := &CertAndStore{Certificate: Certificate{Domain: domain, Certificate: []byte("ec"), KeyType: "EC256"}, Store: "default"}
+	renewedEC := &CertAndStore{Certificate: Certificate{Domain: domain, Certificate: []byte("renewed"), KeyType: "EC256"}, Store: "default"}
+
+	certificates := mergeCertificate([]*CertAndStore{rsa}, ec)
+	assert.Equal(t, []*CertAndStore{rsa, ec}, certificates)
+
+	certificates = mergeCertificate(certificates, renewedEC)
+	assert.Equal(t, []*CertAndStore{rsa, renewedEC}, certificates)
+
+	assert.True(t, hasAdditionalCertificate(certificates, rsa, "EC256"))
+	assert.False(t, hasAdditionalCertificate(certificates, rsa, "EC384"))
+}
+
+func TestFindCertificate_keyType(t *testing.T) {
+	domain := types.Domain{Main: "example.com", SANs: []string{"www.example.com"}}
+
+	rsa := &CertAndStore{Certificate: Certificate{Domain: domain, Certificate: []byte("rsa")}, Store: "default"}
+	ec := &CertAndStore{Certificate: Certificate{Domain: domain, Certificate: []byte("ec"), KeyType: "EC256"}, Store: "default"}
+
+	cert := findCertificate(context.Background(), []*CertAndStore{ec, rsa}, domain.ToStrArray(), "default", "")
+	require.NotNil(t, cert)
+	assert.Equal(t, rsa, cert)
+
+	cert = findCertificate(context.Background(), []*CertAndStore{ec, rsa}, domain.ToStrArray(), "default", "EC256")
+	require.NotNil(t, cert)
+	assert.Equal(t, ec, cert)
+
+	assert.Nil(t, findCertificate(context.Background(), []*CertAndStore{rsa}, domain.ToStrArray(), "default", "EC256"))
+}
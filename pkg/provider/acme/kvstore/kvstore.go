@@ -0,0 +1,156 @@
+package kvstore
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/encryption"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider/acme"
+	"github.com/containous/traefik/v2/pkg/provider/kv"
+)
+
+var _ acme.SharedStore = (*Store)(nil)
+
+// Store is a shared ACME store keeping the data of each resolver in a KV store,
+// under the <rootKey>/<resolver>/account, certificates, and challenges keys, locked by the <rootKey>/<resolver>/lock key.
+type Store struct {
+	client  store.Store
+	rootKey string
+	cipher  *encryption.Cipher
+}
+
+// New creates a Store in the KV store of the backend, encrypted with the cipher when it is not nil.
+func New(backend store.Backend, config *acme.KVStorage, cipher *encryption.Cipher) (*Store, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, errors.New("no KV store endpoint")
+	}
+
+	var tlsConfig *tls.Config
+	if config.TLS != nil {
+		var err error
+		tlsConfig, err = config.TLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("unable to create the TLS configuration: %w", err)
+		}
+	}
+
+	client, err := kv.NewStore(backend, config.Endpoints, config.Username, config.Password, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the KV store: %w", err)
+	}
+
+	return newStore(client, config.RootKey, cipher), nil
+}
+
+func newStore(client store.Store, rootKey string, cipher *encryption.Cipher) *Store {
+	return &Store{client: client, rootKey: rootKey, cipher: cipher}
+}
+
+// GetAccount returns the ACME account of the resolver.
+func (s *Store) GetAccount(resolverName string) (*acme.Account, error) {
+	var account *acme.Account
+	if err := s.get(resolverName, "account", &account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// SaveAccount stores the ACME account of the resolver.
+func (s *Store) SaveAccount(resolverName string, account *acme.Account) error {
+	return s.put(resolverName, "account", account)
+}
+
+// GetCertificates returns the ACME certificates of the resolver.
+func (s *Store) GetCertificates(resolverName string) ([]*acme.CertAndStore, error) {
+	var certificates []*acme.CertAndStore
+	if err := s.get(resolverName, "certificates", &certificates); err != nil {
+		return nil, err
+	}
+
+	return certificates, nil
+}
+
+// SaveCertificates stores the ACME certificates of the resolver.
+func (s *Store) SaveCertificates(resolverName string, certificates []*acme.CertAndStore) error {
+	return s.put(resolverName, "certificates", certificates)
+}
+
+// GetChallenges returns the pending ACME challenges of the resolver.
+func (s *Store) GetChallenges(resolverName string) (*acme.StoredChallengeData, error) {
+	var challenges *acme.StoredChallengeData
+	if err := s.get(resolverName, "challenges", &challenges); err != nil {
+		return nil, err
+	}
+
+	return challenges, nil
+}
+
+// SaveChallenges stores the pending ACME challenges of the resolver.
+func (s *Store) SaveChallenges(resolverName string, challenges *acme.StoredChallengeData) error {
+	return s.put(resolverName, "challenges", challenges)
+}
+
+// Lock blocks until the lock of the resolver is acquired, and returns the function releasing it.
+func (s *Store) Lock(resolverName string) (func(), error) {
+	locker, err := s.client.NewLock(s.key(resolverName, "lock"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := locker.Lock(nil); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if err := locker.Unlock(); err != nil {
+			log.WithoutContext().WithField(log.ProviderName, resolverName+".acme").Errorf("Unable to unlock the ACME storage: %v", err)
+		}
+	}, nil
+}
+
+func (s *Store) key(resolverName, name string) string {
+	return path.Join(s.rootKey, resolverName, name)
+}
+
+// get decodes the value of the key into the value pointed to by v, which is left untouched when the key does not exist.
+func (s *Store) get(resolverName, name string, v interface{}) error {
+	pair, err := s.client.Get(s.key(resolverName, name), nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get the %s of the ACME storage: %w", name, err)
+	}
+
+	data, _, err := s.cipher.Decrypt(pair.Value)
+	if err != nil {
+		return fmt.Errorf("unable to read the %s of the ACME storage: %w", name, err)
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func (s *Store) put(resolverName, name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	data, err = s.cipher.Encrypt(data)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt the %s of the ACME storage: %w", name, err)
+	}
+
+	return s.client.Put(s.key(resolverName, name), data, nil)
+}
@@ -0,0 +1,164 @@
+package kvstore
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/encryption"
+	"github.com/containous/traefik/v2/pkg/provider/acme"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	client := newMemoryKV()
+	kvStore := newStore(client, "traefik/acme", nil)
+
+	account, err := kvStore.GetAccount("le")
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	certificates, err := kvStore.GetCertificates("le")
+	require.NoError(t, err)
+	assert.Empty(t, certificates)
+
+	require.NoError(t, kvStore.SaveAccount("le", &acme.Account{Email: "foo@example.com"}))
+
+	certificates = []*acme.CertAndStore{
+		{Certificate: acme.Certificate{Domain: types.Domain{Main: "example.com"}, Certificate: []byte("cert"), Key: []byte("key")}, Store: "default"},
+	}
+	require.NoError(t, kvStore.SaveCertificates("le", certificates))
+
+	challenges := &acme.StoredChallengeData{HTTPChallenges: map[string]map[string][]byte{"token": {"example.com": []byte("keyAuth")}}}
+	require.NoError(t, kvStore.SaveChallenges("le", challenges))
+
+	assert.Contains(t, client.pairs, "traefik/acme/le/account")
+	assert.Contains(t, client.pairs, "traefik/acme/le/certificates")
+	assert.Contains(t, client.pairs, "traefik/acme/le/challenges")
+
+	account, err = kvStore.GetAccount("le")
+	require.NoError(t, err)
+	assert.Equal(t, "foo@example.com", account.Email)
+
+	storedCertificates, err := kvStore.GetCertificates("le")
+	require.NoError(t, err)
+	assert.Equal(t, certificates, storedCertificates)
+
+	storedChallenges, err := kvStore.GetChallenges("le")
+	require.NoError(t, err)
+	assert.Equal(t, challenges, storedChallenges)
+
+	// The resolvers do not share their data.
+	storedCertificates, err = kvStore.GetCertificates("other")
+	require.NoError(t, err)
+	assert.Empty(t, storedCertificates)
+}
+
+func TestStore_encrypted(t *testing.T) {
+	cipher, err := encryption.New(&encryption.Configuration{Key: "0123456789abcdef0123456789abcdef"})
+	require.NoError(t, err)
+
+	client := newMemoryKV()
+	kvStore := newStore(client, "traefik/acme", cipher)
+
+	require.NoError(t, kvStore.SaveAccount("le", &acme.Account{Email: "foo@example.com"}))
+	assert.True(t, encryption.IsEncrypted(client.pairs["traefik/acme/le/account"]))
+
+	account, err := kvStore.GetAccount("le")
+	require.NoError(t, err)
+	assert.Equal(t, "foo@example.com", account.Email)
+
+	_, err = newStore(client, "traefik/acme", nil).GetAccount("le")
+	assert.Error(t, err)
+}
+
+func TestStore_Lock(t *testing.T) {
+	client := newMemoryKV()
+	kvStore := newStore(client, "traefik/acme", nil)
+
+	unlock, err := kvStore.Lock("le")
+	require.NoError(t, err)
+
+	locked := make(chan struct{})
+	go func() {
+		unlockOther, errL := kvStore.Lock("le")
+		if errL == nil {
+			unlockOther()
+		}
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("the lock has been acquired twice")
+	default:
+	}
+
+	// The lock of another resolver is independent.
+	unlockOther, err := kvStore.Lock("other")
+	require.NoError(t, err)
+	unlockOther()
+
+	unlock()
+	<-locked
+}
+
+// memoryKV is an in-memory KV store, with in-process locks.
+type memoryKV struct {
+	store.Store
+
+	mu    sync.Mutex
+	pairs map[string][]byte
+	locks map[string]*sync.Mutex
+}
+
+func newMemoryKV() *memoryKV {
+	return &memoryKV{pairs: make(map[string][]byte), locks: make(map[string]*sync.Mutex)}
+}
+
+func (m *memoryKV) Get(key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.pairs[key]
+	if !ok {
+		return nil, store.ErrKeyNotFound
+	}
+
+	return &store.KVPair{Key: key, Value: value}, nil
+}
+
+func (m *memoryKV) Put(key string, value []byte, _ *store.WriteOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pairs[key] = value
+	return nil
+}
+
+func (m *memoryKV) NewLock(key string, _ *store.LockOptions) (store.Locker, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.locks[key]; !ok {
+		m.locks[key] = &sync.Mutex{}
+	}
+
+	return &memoryLock{mu: m.locks[key]}, nil
+}
+
+type memoryLock struct {
+	mu *sync.Mutex
+}
+
+func (l *memoryLock) Lock(_ chan struct{}) (<-chan struct{}, error) {
+	l.mu.Lock()
+	return make(chan struct{}), nil
+}
+
+func (l *memoryLock) Unlock() error {
+	l.mu.Unlock()
+	return nil
+}
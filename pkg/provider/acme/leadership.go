@@ -0,0 +1,100 @@
+package acme
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// Leadership tells whether the instance is the leader of its cluster, the only instance obtaining and renewing the certificates.
+type Leadership interface {
+	IsLeader() bool
+	// AddListener adds a listener called whenever the instance becomes, or stops being, the leader.
+	AddListener(listener func(leader bool))
+}
+
+type deferredDomain struct {
+	domain   types.Domain
+	tlsStore string
+}
+
+// deferredDomains holds the domains of which the certificates are left to the leader,
+// and are resolved by the instance if it is elected before they are obtained.
+type deferredDomains struct {
+	mu      sync.Mutex
+	domains map[string]deferredDomain
+}
+
+func (d *deferredDomains) add(domain types.Domain, tlsStore string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.domains == nil {
+		d.domains = make(map[string]deferredDomain)
+	}
+
+	d.domains[strings.Join(domain.ToStrArray(), ",")+"@"+tlsStore] = deferredDomain{domain: domain, tlsStore: tlsStore}
+}
+
+func (d *deferredDomains) flush() []deferredDomain {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	domains := make([]deferredDomain, 0, len(d.domains))
+	for _, domain := range d.domains {
+		domains = append(domains, domain)
+	}
+	d.domains = nil
+
+	return domains
+}
+
+// isLeader returns whether the instance obtains and renews the certificates, which every instance does when it is not part of a cluster.
+func (p *Provider) isLeader() bool {
+	return p.Leadership == nil || p.Leadership.IsLeader()
+}
+
+// watchLeadership resolves the deferred domains, and renews the certificates, whenever the instance is elected.
+func (p *Provider) watchLeadership(ctx context.Context) {
+	if p.Leadership == nil {
+		return
+	}
+
+	elected := make(chan struct{}, 1)
+	p.Leadership.AddListener(func(leader bool) {
+		if !leader {
+			return
+		}
+
+		select {
+		case elected <- struct{}{}:
+		default:
+		}
+	})
+
+	p.pool.GoCtx(func(ctxPool context.Context) {
+		for {
+			select {
+			case <-elected:
+				log.FromContext(ctx).Info("Elected leader of the cluster, obtaining and renewing the ACME certificates")
+
+				for _, deferred := range p.deferredDomains.flush() {
+					deferred := deferred
+					safe.Go(func() {
+						if err := p.resolveCertificate(ctx, deferred.domain, deferred.tlsStore); err != nil {
+							log.FromContext(ctx).Errorf("Unable to obtain ACME certificate for domains %q: %v", strings.Join(deferred.domain.ToStrArray(), ","), err)
+						}
+					})
+				}
+
+				p.renewCertificates(ctx)
+			case <-ctxPool.Done():
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package acme
+
+import (
+	"context"
+	"testing"
+
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLeadership struct {
+	leader bool
+}
+
+func (f *fakeLeadership) IsLeader() bool {
+	return f.leader
+}
+
+func (f *fakeLeadership) AddListener(func(leader bool)) {}
+
+func TestProvider_resolveCertificate_follower(t *testing.T) {
+	p := &Provider{
+		Configuration:    &Configuration{},
+		ResolverName:     "myresolver",
+		Leadership:       &fakeLeadership{},
+		tlsManager:       traefiktls.NewManager(),
+		resolvingDomains: make(map[string]struct{}),
+		domainStatuses:   newDomainStatusTracker("myresolver", nil),
+	}
+
+	domain := types.Domain{Main: "foo.com", SANs: []string{"bar.com"}}
+
+	// The follower neither requests the CA nor fails, leaving the certificate to the leader.
+	err := p.resolveCertificate(context.Background(), domain, "default")
+	require.NoError(t, err)
+
+	err = p.resolveCertificate(context.Background(), domain, "default")
+	require.NoError(t, err)
+
+	statuses := p.GetDomainStatuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, DomainStatusPending, statuses[0].Status)
+
+	assert.Empty(t, p.resolvingDomains)
+	assert.Equal(t, []deferredDomain{{domain: domain, tlsStore: "default"}}, p.deferredDomains.flush())
+	assert.Empty(t, p.deferredDomains.flush())
+}
+
+func TestProvider_renewCertificates_follower(t *testing.T) {
+	p := &Provider{
+		Configuration:  &Configuration{},
+		ResolverName:   "myresolver",
+		Leadership:     &fakeLeadership{},
+		certificates:   []*CertAndStore{{Certificate: Certificate{Domain: types.Domain{Main: "foo.com"}}, Store: "default"}},
+		domainStatuses: newDomainStatusTracker("myresolver", nil),
+	}
+
+	// The broken certificate is left to the leader, and no renewal is attempted.
+	p.renewCertificates(context.Background())
+
+	assert.Empty(t, p.GetDomainStatuses())
+}
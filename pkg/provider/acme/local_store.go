@@ -7,6 +7,7 @@ import (
 	"os"
 	"sync"
 
+	"github.com/containous/traefik/v2/pkg/encryption"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/safe"
 )
@@ -17,14 +18,15 @@ var _ Store = (*LocalStore)(nil)
 type LocalStore struct {
 	saveDataChan chan map[string]*StoredData
 	filename     string
+	cipher       *encryption.Cipher
 
 	lock       sync.RWMutex
 	storedData map[string]*StoredData
 }
 
-// NewLocalStore initializes a new LocalStore with a file name
-func NewLocalStore(filename string) *LocalStore {
-	store := &LocalStore{filename: filename, saveDataChan: make(chan map[string]*StoredData)}
+// NewLocalStore initializes a new LocalStore with a file name, encrypted with the cipher when it is not nil.
+func NewLocalStore(filename string, cipher *encryption.Cipher) *LocalStore {
+	store := &LocalStore{filename: filename, cipher: cipher, saveDataChan: make(chan map[string]*StoredData)}
 	store.listenSaveAction()
 	return store
 }
@@ -63,6 +65,13 @@ func (s *LocalStore) get(resolverName string) (*StoredData, error) {
 				return nil, err
 			}
 
+			file, rewrite, err := s.cipher.Decrypt(file)
+			if err != nil {
+				// The data is not loaded, so that the storage which cannot be decrypted is not overwritten.
+				s.storedData = nil
+				return nil, fmt.Errorf("unable to read the ACME storage %s: %w", s.filename, err)
+			}
+
 			if len(file) > 0 {
 				if err := json.Unmarshal(file, &s.storedData); err != nil {
 					return nil, err
@@ -84,6 +93,12 @@ func (s *LocalStore) get(resolverName string) (*StoredData, error) {
 					s.saveDataChan <- s.storedData
 				}
 			}
+
+			// The plaintext storage, or the storage encrypted with a previous key, is encrypted with the current key.
+			if rewrite {
+				logger.Infof("Encrypting the ACME storage %s with the current key", s.filename)
+				s.saveDataChan <- s.storedData
+			}
 		}
 	}
 
@@ -103,6 +118,12 @@ func (s *LocalStore) listenSaveAction() {
 				logger.Error(err)
 			}
 
+			data, err = s.cipher.Encrypt(data)
+			if err != nil {
+				logger.Errorf("Unable to encrypt the ACME storage: %v", err)
+				continue
+			}
+
 			err = ioutil.WriteFile(s.filename, data, 0600)
 			if err != nil {
 				logger.Error(err)
@@ -157,6 +178,33 @@ func (s *LocalStore) SaveCertificates(resolverName string, certificates []*CertA
 	return nil
 }
 
+// GetChallenges returns the pending ACME challenges
+func (s *LocalStore) GetChallenges(resolverName string) (*StoredChallengeData, error) {
+	storedData, err := s.get(resolverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return storedData.Challenges, nil
+}
+
+// SaveChallenges stores the pending ACME challenges
+func (s *LocalStore) SaveChallenges(resolverName string, challenges *StoredChallengeData) error {
+	storedData, err := s.get(resolverName)
+	if err != nil {
+		return err
+	}
+
+	if challenges != nil && len(challenges.HTTPChallenges) == 0 && len(challenges.TLSChallenges) == 0 {
+		challenges = nil
+	}
+
+	storedData.Challenges = challenges
+	s.save(resolverName, storedData)
+
+	return nil
+}
+
 // LocalChallengeStore is an implementation of the ChallengeStore in memory.
 type LocalChallengeStore struct {
 	storedData *StoredChallengeData
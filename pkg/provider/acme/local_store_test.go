@@ -0,0 +1,61 @@
+package acme
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/encryption"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_encryption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acme")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	filename := filepath.Join(dir, "acme.json")
+	plaintext := `{"default":{"Certificates":[{"domain":{"main":"foo.com"},"certificate":"Y2VydA==","key":"a2V5","Store":"default"}]}}`
+	require.NoError(t, ioutil.WriteFile(filename, []byte(plaintext), 0600))
+
+	cipher, err := encryption.New(&encryption.Configuration{Key: base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))})
+	require.NoError(t, err)
+
+	expected := []*CertAndStore{{
+		Certificate: Certificate{Domain: types.Domain{Main: "foo.com"}, Certificate: []byte("cert"), Key: []byte("key")},
+		Store:       "default",
+	}}
+
+	// The plaintext storage is read, and encrypted.
+	certificates, err := NewLocalStore(filename, cipher).GetCertificates("default")
+	require.NoError(t, err)
+	assert.Equal(t, expected, certificates)
+
+	assert.Eventually(t, func() bool {
+		content, err := ioutil.ReadFile(filename)
+		return err == nil && encryption.IsEncrypted(content)
+	}, time.Second, 10*time.Millisecond)
+
+	// The encrypted storage is read with the key.
+	certificates, err = NewLocalStore(filename, cipher).GetCertificates("default")
+	require.NoError(t, err)
+	assert.Equal(t, expected, certificates)
+
+	// The encrypted storage cannot be read without the key, and is not overwritten.
+	store := NewLocalStore(filename, nil)
+
+	_, err = store.GetCertificates("default")
+	assert.Error(t, err)
+
+	err = store.SaveCertificates("default", nil)
+	assert.Error(t, err)
+
+	content, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.True(t, encryption.IsEncrypted(content))
+}
@@ -0,0 +1,148 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/types"
+	"golang.org/x/time/rate"
+)
+
+// OnDemand holds the configuration of the certificates obtained on the first TLS handshake for their domain.
+type OnDemand struct {
+	Domains         []string       `description:"Domains of which the certificates are obtained on demand, '*.example.com' allowing the subdomains of example.com." json:"domains,omitempty" toml:"domains,omitempty" yaml:"domains,omitempty"`
+	DomainRegexps   []string       `description:"Regular expressions, matching whole domains, of the domains of which the certificates are obtained on demand." json:"domainRegexps,omitempty" toml:"domainRegexps,omitempty" yaml:"domainRegexps,omitempty"`
+	RateLimit       int            `description:"Maximum number of certificates requested on demand per rate limit period." json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+	RateLimitPeriod types.Duration `description:"Period of the rate limit, which is also the delay before the certificate of a domain is requested on demand again." json:"rateLimitPeriod,omitempty" toml:"rateLimitPeriod,omitempty" yaml:"rateLimitPeriod,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (o *OnDemand) SetDefaults() {
+	o.RateLimit = 10
+	o.RateLimitPeriod = types.Duration(time.Hour)
+}
+
+// onDemandIssuer decides which of the unknown server names of the TLS handshakes get a certificate requested.
+type onDemandIssuer struct {
+	domains       []string
+	domainRegexps []*regexp.Regexp
+	period        time.Duration
+	limiter       *rate.Limiter
+
+	mu sync.Mutex
+	// requested holds the time of the last request of the certificate of each domain, within the rate limit period.
+	requested map[string]time.Time
+}
+
+func newOnDemandIssuer(config *OnDemand) (*onDemandIssuer, error) {
+	if len(config.Domains) == 0 && len(config.DomainRegexps) == 0 {
+		return nil, errors.New("no allowed domain")
+	}
+
+	if config.RateLimit <= 0 {
+		return nil, errors.New("rateLimit must be positive")
+	}
+
+	if config.RateLimitPeriod <= 0 {
+		return nil, errors.New("rateLimitPeriod must be positive")
+	}
+
+	issuer := &onDemandIssuer{
+		period:    time.Duration(config.RateLimitPeriod),
+		requested: make(map[string]time.Time),
+	}
+
+	for _, domain := range config.Domains {
+		issuer.domains = append(issuer.domains, types.CanonicalDomain(domain))
+	}
+
+	for _, expr := range config.DomainRegexps {
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid domain regexp %q: %w", expr, err)
+		}
+		issuer.domainRegexps = append(issuer.domainRegexps, re)
+	}
+
+	issuer.limiter = rate.NewLimiter(rate.Every(issuer.period/time.Duration(config.RateLimit)), config.RateLimit)
+
+	return issuer, nil
+}
+
+func (o *onDemandIssuer) allowed(domain string) bool {
+	for _, allowed := range o.domains {
+		if types.MatchDomain(domain, allowed) {
+			return true
+		}
+	}
+
+	for _, re := range o.domainRegexps {
+		if re.MatchString(domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// request returns whether the certificate of the domain can be requested now,
+// i.e. when it was not requested during the rate limit period, and the rate limit is not reached.
+func (o *onDemandIssuer) request(domain string, now time.Time) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for d, requested := range o.requested {
+		if now.Sub(requested) >= o.period {
+			delete(o.requested, d)
+		}
+	}
+
+	if _, ok := o.requested[domain]; ok {
+		return false, nil
+	}
+
+	if !o.limiter.AllowN(now, 1) {
+		return false, errors.New("rate limit reached")
+	}
+
+	o.requested[domain] = now
+
+	return true, nil
+}
+
+// ObtainOnDemand requests the certificate of the server name of a TLS handshake for which no certificate is found,
+// when the resolver obtains the certificates of this domain on demand.
+// The certificate is obtained in the background, the default certificate being served until then.
+func (p *Provider) ObtainOnDemand(serverName string) {
+	if p.onDemand == nil || !p.onDemand.allowed(serverName) {
+		return
+	}
+
+	ctx := log.With(context.Background(), log.Str(log.ProviderName, p.ResolverName+".acme"))
+	logger := log.FromContext(ctx)
+
+	ok, err := p.onDemand.request(serverName, time.Now())
+	if err != nil {
+		logger.Debugf("Unable to request the ACME certificate for domain %q on demand: %v", serverName, err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	logger.Infof("Requesting the ACME certificate for domain %q on demand", serverName)
+
+	// The leader of the cluster does not receive the handshakes of the other instances,
+	// which request the certificates themselves, the lock of the shared storage preventing duplicate requests.
+	safe.Go(func() {
+		if err := p.resolveCertificateOf(ctx, types.Domain{Main: serverName}, "default", false); err != nil {
+			logger.Errorf("Unable to obtain ACME certificate for domain %q on demand: %v", serverName, err)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package acme
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOnDemandIssuer(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      OnDemand
+		expectedErr bool
+	}{
+		{
+			desc:   "domains",
+			config: OnDemand{Domains: []string{"*.example.com"}, RateLimit: 10, RateLimitPeriod: types.Duration(time.Hour)},
+		},
+		{
+			desc:   "domain regexps",
+			config: OnDemand{DomainRegexps: []string{`[a-z]+\.example\.com`}, RateLimit: 10, RateLimitPeriod: types.Duration(time.Hour)},
+		},
+		{
+			desc:        "no allowed domain",
+			config:      OnDemand{RateLimit: 10, RateLimitPeriod: types.Duration(time.Hour)},
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid domain regexp",
+			config:      OnDemand{DomainRegexps: []string{`(`}, RateLimit: 10, RateLimitPeriod: types.Duration(time.Hour)},
+			expectedErr: true,
+		},
+		{
+			desc:        "no rate limit",
+			config:      OnDemand{Domains: []string{"example.com"}, RateLimitPeriod: types.Duration(time.Hour)},
+			expectedErr: true,
+		},
+		{
+			desc:        "no rate limit period",
+			config:      OnDemand{Domains: []string{"example.com"}, RateLimit: 10},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newOnDemandIssuer(&test.config)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestOnDemandIssuer_allowed(t *testing.T) {
+	issuer, err := newOnDemandIssuer(&OnDemand{
+		Domains:         []string{"Example.com", "*.apps.example.com"},
+		DomainRegexps:   []string{`shop-[0-9]+\.example\.org`},
+		RateLimit:       10,
+		RateLimitPeriod: types.Duration(time.Hour),
+	})
+	require.NoError(t, err)
+
+	testCases := map[string]bool{
+		"example.com":              true,
+		"www.example.com":          false,
+		"foo.apps.example.com":     true,
+		"foo.bar.apps.example.com": false,
+		"shop-42.example.org":      true,
+		"shop-42.example.org.evil": false,
+		"www.shop-42.example.org":  false,
+	}
+
+	for domain, expected := range testCases {
+		assert.Equal(t, expected, issuer.allowed(domain), domain)
+	}
+}
+
+func TestOnDemandIssuer_request(t *testing.T) {
+	issuer, err := newOnDemandIssuer(&OnDemand{
+		Domains:         []string{"*.example.com"},
+		RateLimit:       2,
+		RateLimitPeriod: types.Duration(time.Hour),
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	ok, err := issuer.request("a.example.com", now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// The certificate of a domain is requested once per period.
+	ok, err = issuer.request("a.example.com", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = issuer.request("b.example.com", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// The rate limit is reached.
+	_, err = issuer.request("c.example.com", now.Add(2*time.Minute))
+	assert.Error(t, err)
+
+	// Half a period later, a request is allowed again.
+	ok, err = issuer.request("c.example.com", now.Add(31*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// A period later, the certificate of the domain can be requested again.
+	ok, err = issuer.request("a.example.com", now.Add(time.Hour+time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
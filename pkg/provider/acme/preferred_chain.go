@@ -0,0 +1,247 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/version"
+	legoacme "github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/go-acme/lego/v3/registration"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+// maxChainSize is the maximum size of a certificate chain downloaded from the CA server.
+const maxChainSize = 1024 * 1024
+
+var linkRegexp = regexp.MustCompile(`<(.+?)>;\s*rel="(.+?)"`)
+
+// withPreferredChain replaces the certificate chain by the alternate chain offered by the CA server
+// whose topmost certificate is issued by the preferred chain, if any.
+// The default chain is kept when no chain matches, or when the alternate chains cannot be retrieved.
+func (p *Provider) withPreferredChain(ctx context.Context, caServer string, cert *certificate.Resource) *certificate.Resource {
+	if p.PreferredChain == "" || cert.CertURL == "" || matchChain(cert.Certificate, p.PreferredChain) {
+		return cert
+	}
+
+	logger := log.FromContext(ctx)
+
+	fetcher, err := p.newChainFetcher(caServer)
+	if err != nil {
+		logger.Warnf("Unable to retrieve the alternate chains of the certificate for the domain %s: %v", cert.Domain, err)
+		return cert
+	}
+
+	_, alternates, err := fetcher.fetch(cert.CertURL)
+	if err != nil {
+		logger.Warnf("Unable to retrieve the alternate chains of the certificate for the domain %s: %v", cert.Domain, err)
+		return cert
+	}
+
+	for _, alternate := range alternates {
+		chain, _, err := fetcher.fetch(alternate)
+		if err != nil {
+			logger.Warnf("Unable to retrieve the alternate chain %s of the certificate for the domain %s: %v", alternate, cert.Domain, err)
+			continue
+		}
+
+		if matchChain(chain, p.PreferredChain) {
+			logger.Debugf("Using the alternate chain %s of the certificate for the domain %s", alternate, cert.Domain)
+
+			preferred := *cert
+			preferred.Certificate = chain
+			return &preferred
+		}
+	}
+
+	logger.Debugf("No chain of the certificate for the domain %s matches the preferred chain %q, using the default one", cert.Domain, p.PreferredChain)
+
+	return cert
+}
+
+// matchChain returns whether the topmost certificate of the PEM chain is issued by the given common name.
+func matchChain(chain []byte, issuer string) bool {
+	certs, err := certcrypto.ParsePEMBundle(chain)
+	if err != nil {
+		return false
+	}
+
+	return certs[len(certs)-1].Issuer.CommonName == issuer
+}
+
+func (p *Provider) newChainFetcher(caServer string) (*chainFetcher, error) {
+	account := p.account
+	if account == nil {
+		return nil, errors.New("no account")
+	}
+
+	var user registration.User = account
+	if caServer != p.caServers()[0].CAServer {
+		user = &fallbackAccount{Account: account, caServer: caServer}
+	}
+
+	if user.GetRegistration() == nil {
+		return nil, fmt.Errorf("no registration on the CA server %s", caServer)
+	}
+
+	return &chainFetcher{
+		client:    lego.NewConfig(user).HTTPClient,
+		caServer:  caServer,
+		key:       user.GetPrivateKey(),
+		keyID:     user.GetRegistration().URI,
+		userAgent: fmt.Sprintf("containous-traefik/%s", version.Version),
+	}, nil
+}
+
+// chainFetcher downloads the certificate chains with POST-as-GET requests signed by the key of the account,
+// as lego only returns the default chain, without the links to the alternate ones.
+type chainFetcher struct {
+	client    *http.Client
+	caServer  string
+	key       crypto.PrivateKey
+	keyID     string
+	userAgent string
+
+	nonces   []string
+	newNonce string
+}
+
+// fetch downloads the PEM chain, and returns it with the URLs of the alternate chains.
+func (f *chainFetcher) fetch(url string) ([]byte, []string, error) {
+	// The keys of the accounts are RSA keys.
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: f.key, KeyID: f.keyID}},
+		&jose.SignerOptions{NonceSource: f, ExtraHeaders: map[jose.HeaderKey]interface{}{"url": url}},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The payload of a POST-as-GET request is empty.
+	signed, err := signer.Sign([]byte{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(signed.FullSerialize()))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/jose+json")
+	req.Header.Set("Accept", "application/pem-certificate-chain")
+	req.Header.Set("User-Agent", f.userAgent)
+
+	resp, err := f.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxChainSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status %d from %s: %s", resp.StatusCode, url, string(body))
+	}
+
+	var alternates []string
+	for _, link := range resp.Header["Link"] {
+		for _, match := range linkRegexp.FindAllStringSubmatch(link, -1) {
+			if match[2] == "alternate" {
+				alternates = append(alternates, match[1])
+			}
+		}
+	}
+
+	return body, alternates, nil
+}
+
+// Nonce returns a nonce for the next request, from the previous responses, or from the CA server.
+func (f *chainFetcher) Nonce() (string, error) {
+	if len(f.nonces) > 0 {
+		nonce := f.nonces[len(f.nonces)-1]
+		f.nonces = f.nonces[:len(f.nonces)-1]
+		return nonce, nil
+	}
+
+	newNonce, err := f.newNonceURL()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodHead, newNonce, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+
+	nonce := resp.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		return "", fmt.Errorf("no nonce returned by %s", newNonce)
+	}
+
+	return nonce, nil
+}
+
+func (f *chainFetcher) newNonceURL() (string, error) {
+	if f.newNonce != "" {
+		return f.newNonce, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, f.caServer, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var dir legoacme.Directory
+	if err = json.NewDecoder(resp.Body).Decode(&dir); err != nil {
+		return "", fmt.Errorf("invalid directory %s: %w", f.caServer, err)
+	}
+
+	if dir.NewNonceURL == "" {
+		return "", fmt.Errorf("no new nonce URL in the directory %s", f.caServer)
+	}
+
+	f.newNonce = dir.NewNonceURL
+
+	return f.newNonce, nil
+}
+
+// do sends the request, keeping the nonce of the response for the next request.
+func (f *chainFetcher) do(req *http.Request) (*http.Response, error) {
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if nonce := resp.Header.Get("Replay-Nonce"); nonce != "" {
+		f.nonces = append(f.nonces, nonce)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,170 @@
+package acme
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/registration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+// pemChain creates the PEM chain of a leaf certificate and of its intermediate certificate, issued by the given root.
+func pemChain(t *testing.T, key *rsa.PrivateKey, root string) []byte {
+	t.Helper()
+
+	var chain []byte
+	for i, names := range [][2]string{{"example.com", "R3"}, {"R3", root}} {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 1)),
+			Subject:      pkix.Name{CommonName: names[0]},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		parent := &x509.Certificate{Subject: pkix.Name{CommonName: names[1]}}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, key)
+		require.NoError(t, err)
+
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	return chain
+}
+
+func TestMatchChain(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	chain := pemChain(t, key, "ISRG Root X1")
+
+	assert.True(t, matchChain(chain, "ISRG Root X1"))
+	assert.False(t, matchChain(chain, "R3"))
+	assert.False(t, matchChain(chain, "DST Root CA X3"))
+	assert.False(t, matchChain([]byte("invalid"), "ISRG Root X1"))
+}
+
+func TestProvider_withPreferredChain(t *testing.T) {
+	accountKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	certKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	chains := map[string][]byte{
+		"/cert/default": pemChain(t, certKey, "DST Root CA X3"),
+		"/cert/alt1":    pemChain(t, certKey, "Other Root"),
+		"/cert/alt2":    pemChain(t, certKey, "ISRG Root X1"),
+	}
+
+	var requests int32
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/dir", func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte(`{"newNonce": "` + server.URL + `/nonce"}`))
+	})
+	mux.HandleFunc("/nonce", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Replay-Nonce", "nonce")
+	})
+	mux.HandleFunc("/cert/", func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		body, errR := ioutil.ReadAll(req.Body)
+		require.NoError(t, errR)
+
+		jws, errR := jose.ParseSigned(string(body))
+		require.NoError(t, errR)
+
+		payload, errR := jws.Verify(&accountKey.PublicKey)
+		require.NoError(t, errR)
+		assert.Empty(t, payload)
+
+		header := jws.Signatures[0].Protected
+		assert.Equal(t, "https://acme.example.com/acct/1", header.KeyID)
+		assert.Equal(t, "nonce", header.Nonce)
+		assert.Equal(t, server.URL+req.URL.Path, header.ExtraHeaders["url"])
+
+		chain, ok := chains[req.URL.Path]
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if req.URL.Path == "/cert/default" {
+			rw.Header().Add("Link", "<"+server.URL+"/cert/alt1>;rel=\"alternate\"")
+			rw.Header().Add("Link", "<"+server.URL+"/cert/missing>;rel=\"alternate\"")
+			rw.Header().Add("Link", "<"+server.URL+"/cert/alt2>;rel=\"alternate\"")
+		}
+
+		rw.Header().Set("Replay-Nonce", "nonce")
+		_, _ = rw.Write(chain)
+	})
+
+	testCases := []struct {
+		desc             string
+		preferredChain   string
+		expectedChain    string
+		expectedRequests int32
+	}{
+		{
+			desc:          "no preferred chain",
+			expectedChain: "/cert/default",
+		},
+		{
+			desc:           "default chain",
+			preferredChain: "DST Root CA X3",
+			expectedChain:  "/cert/default",
+		},
+		{
+			desc:             "alternate chain",
+			preferredChain:   "ISRG Root X1",
+			expectedChain:    "/cert/alt2",
+			expectedRequests: 4,
+		},
+		{
+			desc:             "no matching chain",
+			preferredChain:   "Unknown Root",
+			expectedChain:    "/cert/default",
+			expectedRequests: 4,
+		},
+	}
+
+	for _, test := range testCases {
+		atomic.StoreInt32(&requests, 0)
+
+		p := &Provider{
+			Configuration: &Configuration{CAServer: server.URL + "/dir", PreferredChain: test.preferredChain},
+			account: &Account{
+				PrivateKey:   x509.MarshalPKCS1PrivateKey(accountKey),
+				Registration: &registration.Resource{URI: "https://acme.example.com/acct/1"},
+			},
+		}
+
+		cert := &certificate.Resource{
+			Domain:      "example.com",
+			CertURL:     server.URL + "/cert/default",
+			Certificate: chains["/cert/default"],
+		}
+
+		preferred := p.withPreferredChain(context.Background(), server.URL+"/dir", cert)
+
+		assert.Equal(t, chains[test.expectedChain], preferred.Certificate, test.desc)
+		assert.Equal(t, test.expectedRequests, atomic.LoadInt32(&requests), test.desc)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/url"
@@ -14,16 +15,17 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/rules"
 	"github.com/containous/traefik/v2/pkg/safe"
 	traefiktls "github.com/containous/traefik/v2/pkg/tls"
 	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/containous/traefik/v2/pkg/version"
+	"github.com/go-acme/lego/v3/certcrypto"
 	"github.com/go-acme/lego/v3/certificate"
 	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/challenge/dns01"
 	"github.com/go-acme/lego/v3/lego"
-	"github.com/go-acme/lego/v3/providers/dns"
 	"github.com/go-acme/lego/v3/registration"
 )
 
@@ -36,11 +38,28 @@ var (
 type Configuration struct {
 	Email         string         `description:"Email address used for registration." json:"email,omitempty" toml:"email,omitempty" yaml:"email,omitempty"`
 	CAServer      string         `description:"CA server to use." json:"caServer,omitempty" toml:"caServer,omitempty" yaml:"caServer,omitempty"`
+	EAB           *EAB           `description:"External Account Binding to use, required by some CA servers such as ZeroSSL and Sectigo." json:"eab,omitempty" toml:"eab,omitempty" yaml:"eab,omitempty"`
 	Storage       string         `description:"Storage to use." json:"storage,omitempty" toml:"storage,omitempty" yaml:"storage,omitempty"`
+	SharedStorage *SharedStorage `description:"Storage shared by several Traefik instances, replacing the storage file." json:"sharedStorage,omitempty" toml:"sharedStorage,omitempty" yaml:"sharedStorage,omitempty" export:"true"`
 	KeyType       string         `description:"KeyType used for generating certificate private key. Allow value 'EC256', 'EC384', 'RSA2048', 'RSA4096', 'RSA8192'." json:"keyType,omitempty" toml:"keyType,omitempty" yaml:"keyType,omitempty"`
-	DNSChallenge  *DNSChallenge  `description:"Activate DNS-01 Challenge." json:"dnsChallenge,omitempty" toml:"dnsChallenge,omitempty" yaml:"dnsChallenge,omitempty" label:"allowEmpty"`
-	HTTPChallenge *HTTPChallenge `description:"Activate HTTP-01 Challenge." json:"httpChallenge,omitempty" toml:"httpChallenge,omitempty" yaml:"httpChallenge,omitempty" label:"allowEmpty"`
-	TLSChallenge  *TLSChallenge  `description:"Activate TLS-ALPN-01 Challenge." json:"tlsChallenge,omitempty" toml:"tlsChallenge,omitempty" yaml:"tlsChallenge,omitempty" label:"allowEmpty"`
+	// AdditionalKeyType is an EC key type along with an RSA KeyType, or the reverse.
+	AdditionalKeyType string         `description:"KeyType of an additional certificate issued for the same domains, served to the clients supporting it. Allow value 'EC256', 'EC384', 'RSA2048', 'RSA4096', 'RSA8192'." json:"additionalKeyType,omitempty" toml:"additionalKeyType,omitempty" yaml:"additionalKeyType,omitempty" export:"true"`
+	DNSChallenge      *DNSChallenge  `description:"Activate DNS-01 Challenge." json:"dnsChallenge,omitempty" toml:"dnsChallenge,omitempty" yaml:"dnsChallenge,omitempty" label:"allowEmpty"`
+	HTTPChallenge     *HTTPChallenge `description:"Activate HTTP-01 Challenge." json:"httpChallenge,omitempty" toml:"httpChallenge,omitempty" yaml:"httpChallenge,omitempty" label:"allowEmpty"`
+	TLSChallenge      *TLSChallenge  `description:"Activate TLS-ALPN-01 Challenge." json:"tlsChallenge,omitempty" toml:"tlsChallenge,omitempty" yaml:"tlsChallenge,omitempty" label:"allowEmpty"`
+
+	FallbackCAServers []FallbackCAServer `description:"CA servers to fall back to, in priority order, when the certificate issuance fails with a rate-limit or server error." json:"fallbackCAServers,omitempty" toml:"fallbackCAServers,omitempty" yaml:"fallbackCAServers,omitempty"`
+
+	ReissueStagingCertificates bool `description:"Re-issue the certificates issued by a staging CA server, when the resolver uses a production CA server." json:"reissueStagingCertificates,omitempty" toml:"reissueStagingCertificates,omitempty" yaml:"reissueStagingCertificates,omitempty"`
+
+	RenewBeforeDays int            `description:"Number of days of remaining validity below which a certificate is renewed." json:"renewBeforeDays,omitempty" toml:"renewBeforeDays,omitempty" yaml:"renewBeforeDays,omitempty" export:"true"`
+	RenewalJitter   types.Duration `description:"Maximum duration by which the renewal of each certificate is randomly brought forward, to spread the renewals." json:"renewalJitter,omitempty" toml:"renewalJitter,omitempty" yaml:"renewalJitter,omitempty" export:"true"`
+
+	OnDemand *OnDemand `description:"Obtain the certificates of the allowed domains on the first TLS handshake for them, serving the default certificate until then." json:"onDemand,omitempty" toml:"onDemand,omitempty" yaml:"onDemand,omitempty" export:"true"`
+
+	PreferredChain string `description:"Preferred chain to use, among the chains offered by the CA server: the common name of the issuer of the topmost certificate of the chain." json:"preferredChain,omitempty" toml:"preferredChain,omitempty" yaml:"preferredChain,omitempty" export:"true"`
+
+	Webhook *Webhook `description:"Webhook notified of the certificates obtained and renewed, of the renewal failures, and of the rate limits of the CA servers." json:"webhook,omitempty" toml:"webhook,omitempty" yaml:"webhook,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -48,6 +67,7 @@ func (a *Configuration) SetDefaults() {
 	a.CAServer = lego.LEDirectoryProduction
 	a.Storage = "acme.json"
 	a.KeyType = "RSA4096"
+	a.RenewBeforeDays = defaultRenewBeforeDays
 }
 
 // CertAndStore allows mapping a TLS certificate to a TLS store.
@@ -61,6 +81,38 @@ type Certificate struct {
 	Domain      types.Domain `json:"domain,omitempty" toml:"domain,omitempty" yaml:"domain,omitempty"`
 	Certificate []byte       `json:"certificate,omitempty" toml:"certificate,omitempty" yaml:"certificate,omitempty"`
 	Key         []byte       `json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty"`
+	CAServer    string       `json:"caServer,omitempty" toml:"caServer,omitempty" yaml:"caServer,omitempty"`
+	// KeyType is the key type of the additional certificate of the domain, and is empty for the certificate of the keyType of the resolver.
+	KeyType string `json:"keyType,omitempty" toml:"keyType,omitempty" yaml:"keyType,omitempty"`
+}
+
+// FallbackCAServer contains the configuration of a CA server to fall back to.
+type FallbackCAServer struct {
+	CAServer string `description:"CA server to use." json:"caServer,omitempty" toml:"caServer,omitempty" yaml:"caServer,omitempty"`
+	EAB      *EAB   `description:"External Account Binding to use." json:"eab,omitempty" toml:"eab,omitempty" yaml:"eab,omitempty"`
+}
+
+// EAB contains the External Account Binding credentials required by some CA servers to register an account.
+type EAB struct {
+	Kid         string `description:"Key identifier from External CA." json:"kid,omitempty" toml:"kid,omitempty" yaml:"kid,omitempty"`
+	HmacEncoded string `description:"Base64 encoded HMAC key from External CA." json:"hmacEncoded,omitempty" toml:"hmacEncoded,omitempty" yaml:"hmacEncoded,omitempty" secret:"true"`
+}
+
+// validate checks that both credentials are set, and that the HMAC key is base64url encoded without padding, as the CA servers issue it.
+func (e *EAB) validate() error {
+	if e == nil {
+		return nil
+	}
+
+	if e.Kid == "" || e.HmacEncoded == "" {
+		return errors.New("both kid and hmacEncoded are required")
+	}
+
+	if _, err := base64.RawURLEncoding.DecodeString(e.HmacEncoded); err != nil {
+		return fmt.Errorf("hmacEncoded is not base64url encoded: %w", err)
+	}
+
+	return nil
 }
 
 // DNSChallenge contains DNS challenge Configuration
@@ -69,6 +121,12 @@ type DNSChallenge struct {
 	DelayBeforeCheck        types.Duration `description:"Assume DNS propagates after a delay in seconds rather than finding and querying nameservers." json:"delayBeforeCheck,omitempty" toml:"delayBeforeCheck,omitempty" yaml:"delayBeforeCheck,omitempty"`
 	Resolvers               []string       `description:"Use following DNS servers to resolve the FQDN authority." json:"resolvers,omitempty" toml:"resolvers,omitempty" yaml:"resolvers,omitempty"`
 	DisablePropagationCheck bool           `description:"Disable the DNS propagation checks before notifying ACME that the DNS challenge is ready. [not recommended]" json:"disablePropagationCheck,omitempty" toml:"disablePropagationCheck,omitempty" yaml:"disablePropagationCheck,omitempty"`
+
+	AuthoritativeCheck *AuthoritativeCheck `description:"Check the propagation of the TXT record on the authoritative nameservers of the zone, queried directly, instead of through the recursive nameservers." json:"authoritativeCheck,omitempty" toml:"authoritativeCheck,omitempty" yaml:"authoritativeCheck,omitempty" label:"allowEmpty"`
+
+	Plugin *DNSPlugin `description:"Use a DNS provider plugin, instead of a DNS provider of lego." json:"plugin,omitempty" toml:"plugin,omitempty" yaml:"plugin,omitempty"`
+
+	Alias string `description:"Zone the TXT records of the challenges are written to, the _acme-challenge name of each domain being delegated to the _acme-challenge name of the zone by a CNAME record." json:"alias,omitempty" toml:"alias,omitempty" yaml:"alias,omitempty"`
 }
 
 // HTTPChallenge contains HTTP challenge Configuration
@@ -85,10 +143,13 @@ type Provider struct {
 	ResolverName           string
 	Store                  Store `json:"store,omitempty" toml:"store,omitempty" yaml:"store,omitempty"`
 	ChallengeStore         ChallengeStore
+	Leadership             Leadership
+	DNSPlugins             DNSPlugins
 	certificates           []*CertAndStore
 	account                *Account
-	client                 *lego.Client
+	clients                map[string]*lego.Client
 	certsChan              chan *CertAndStore
+	syncChan               chan struct{}
 	configurationChan      chan<- dynamic.Message
 	tlsManager             *traefiktls.Manager
 	clientMutex            sync.Mutex
@@ -96,6 +157,12 @@ type Provider struct {
 	pool                   *safe.Pool
 	resolvingDomains       map[string]struct{}
 	resolvingDomainsMutex  sync.RWMutex
+	metricsRegistry        metrics.Registry
+	domainStatuses         *domainStatusTracker
+	fallbackResolvers      map[string]*Provider
+	deferredDomains        deferredDomains
+	onDemand               *onDemandIssuer
+	webhook                *webhookNotifier
 }
 
 // SetTLSManager sets the tls manager to use
@@ -103,6 +170,11 @@ func (p *Provider) SetTLSManager(tlsManager *traefiktls.Manager) {
 	p.tlsManager = tlsManager
 }
 
+// SetFallbackResolvers sets the ACME resolvers, by name, the routers can fall back to when this resolver fails.
+func (p *Provider) SetFallbackResolvers(resolvers map[string]*Provider) {
+	p.fallbackResolvers = resolvers
+}
+
 // SetConfigListenerChan initializes the configFromListenerChan
 func (p *Provider) SetConfigListenerChan(configFromListenerChan chan dynamic.Configuration) {
 	p.configFromListenerChan = configFromListenerChan
@@ -118,10 +190,56 @@ func (p *Provider) Init() error {
 	ctx := log.With(context.Background(), log.Str(log.ProviderName, p.ResolverName+".acme"))
 	logger := log.FromContext(ctx)
 
-	if len(p.Configuration.Storage) == 0 {
+	if len(p.Configuration.Storage) == 0 && p.SharedStorage == nil {
 		return errors.New("unable to initialize ACME provider with no storage location for the certificates")
 	}
 
+	if p.SharedStorage != nil {
+		if err := p.SharedStorage.validate(); err != nil {
+			return fmt.Errorf("invalid shared storage: %w", err)
+		}
+	}
+
+	if p.RenewBeforeDays < 0 {
+		return errors.New("renewBeforeDays must not be negative")
+	}
+
+	if p.RenewalJitter < 0 {
+		return errors.New("renewalJitter must not be negative")
+	}
+
+	if err := p.validateAdditionalKeyType(); err != nil {
+		return err
+	}
+
+	if p.DNSChallenge != nil && p.DNSChallenge.Plugin != nil {
+		if err := p.DNSChallenge.validatePlugin(); err != nil {
+			return fmt.Errorf("invalid DNS challenge: %w", err)
+		}
+	}
+
+	if p.OnDemand != nil {
+		onDemand, err := newOnDemandIssuer(p.OnDemand)
+		if err != nil {
+			return fmt.Errorf("invalid on demand configuration: %w", err)
+		}
+		p.onDemand = onDemand
+	}
+
+	if p.Webhook != nil {
+		webhook, err := newWebhookNotifier(p.Webhook)
+		if err != nil {
+			return fmt.Errorf("invalid webhook: %w", err)
+		}
+		p.webhook = webhook
+	}
+
+	for _, caServer := range p.caServers() {
+		if err := caServer.EAB.validate(); err != nil {
+			return fmt.Errorf("invalid external account binding of the CA server %s: %w", caServer.CAServer, err)
+		}
+	}
+
 	var err error
 	p.account, err = p.Store.GetAccount(p.ResolverName)
 	if err != nil {
@@ -139,6 +257,13 @@ func (p *Provider) Init() error {
 		return fmt.Errorf("unable to get ACME certificates : %v", err)
 	}
 
+	p.initDomainStatuses(ctx)
+
+	p.ChallengeStore, err = newPersistentChallengeStore(ctx, p.Store, p.ResolverName, p.ChallengeStore)
+	if err != nil {
+		return fmt.Errorf("unable to get ACME challenges: %v", err)
+	}
+
 	// Init the currently resolved domain map
 	p.resolvingDomains = make(map[string]struct{})
 
@@ -171,7 +296,9 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	p.pool = pool
 
 	p.watchCertificate(ctx)
+	p.watchSharedCertificates(ctx)
 	p.watchNewDomains(ctx)
+	p.watchLeadership(ctx)
 
 	p.configurationChan = configurationChan
 	p.refreshCertificates()
@@ -194,15 +321,15 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	return nil
 }
 
-func (p *Provider) getClient() (*lego.Client, error) {
+func (p *Provider) getClient(caServer FallbackCAServer) (*lego.Client, error) {
 	p.clientMutex.Lock()
 	defer p.clientMutex.Unlock()
 
 	ctx := log.With(context.Background(), log.Str(log.ProviderName, p.ResolverName+".acme"))
 	logger := log.FromContext(ctx)
 
-	if p.client != nil {
-		return p.client, nil
+	if client, ok := p.clients[caServer.CAServer]; ok {
+		return client, nil
 	}
 
 	account, err := p.initAccount(ctx)
@@ -212,14 +339,15 @@ func (p *Provider) getClient() (*lego.Client, error) {
 
 	logger.Debug("Building ACME client...")
 
-	caServer := lego.LEDirectoryProduction
-	if len(p.CAServer) > 0 {
-		caServer = p.CAServer
+	// The fallback CA servers use the key of the account, with their own registration.
+	primary := caServer.CAServer == p.caServers()[0].CAServer
+	var user registration.User = account
+	if !primary {
+		user = &fallbackAccount{Account: account, caServer: caServer.CAServer}
 	}
-	logger.Debug(caServer)
 
-	config := lego.NewConfig(account)
-	config.CADirURL = caServer
+	config := lego.NewConfig(user)
+	config.CADirURL = caServer.CAServer
 	config.Certificate.KeyType = account.KeyType
 	config.UserAgent = fmt.Sprintf("containous-traefik/%s", version.Version)
 
@@ -229,15 +357,19 @@ func (p *Provider) getClient() (*lego.Client, error) {
 	}
 
 	// New users will need to register; be sure to save it
-	if account.GetRegistration() == nil {
+	if user.GetRegistration() == nil {
 		logger.Info("Register...")
 
-		reg, errR := client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+		reg, errR := register(client, caServer.EAB)
 		if errR != nil {
 			return nil, errR
 		}
 
-		account.Registration = reg
+		if primary {
+			account.Registration = reg
+		} else {
+			account.setFallbackRegistration(caServer.CAServer, reg)
+		}
 	}
 
 	// Save the account once before all the certificates generation/storing
@@ -247,21 +379,24 @@ func (p *Provider) getClient() (*lego.Client, error) {
 		return nil, err
 	}
 
-	if (p.DNSChallenge == nil || len(p.DNSChallenge.Provider) == 0) &&
+	if !p.DNSChallenge.enabled() &&
 		(p.HTTPChallenge == nil || len(p.HTTPChallenge.EntryPoint) == 0) &&
 		p.TLSChallenge == nil {
 		return nil, errors.New("ACME challenge not specified, please select TLS or HTTP or DNS Challenge")
 	}
 
-	if p.DNSChallenge != nil && len(p.DNSChallenge.Provider) > 0 {
-		logger.Debugf("Using DNS Challenge provider: %s", p.DNSChallenge.Provider)
-
+	if p.DNSChallenge.enabled() {
 		var provider challenge.Provider
-		provider, err = dns.NewDNSChallengeProviderByName(p.DNSChallenge.Provider)
+		provider, err = p.newDNSProvider(ctx)
 		if err != nil {
 			return nil, err
 		}
 
+		if p.DNSChallenge.Alias != "" {
+			logger.Debugf("Using DNS Challenge alias zone: %s", p.DNSChallenge.Alias)
+			provider = newAliasDNSProvider(provider, p.DNSChallenge.Alias)
+		}
+
 		err = client.Challenge.SetDNS01Provider(provider,
 			dns01.CondOption(len(p.DNSChallenge.Resolvers) > 0, dns01.AddRecursiveNameservers(p.DNSChallenge.Resolvers)),
 			dns01.CondOption(p.DNSChallenge.DisablePropagationCheck || p.DNSChallenge.DelayBeforeCheck > 0,
@@ -272,6 +407,8 @@ func (p *Provider) getClient() (*lego.Client, error) {
 					}
 					return true, nil
 				})),
+			dns01.CondOption(p.DNSChallenge.AuthoritativeCheck != nil && !p.DNSChallenge.DisablePropagationCheck,
+				dns01.WrapPreCheck(p.DNSChallenge.preCheckAuthoritative)),
 		)
 		if err != nil {
 			return nil, err
@@ -296,8 +433,24 @@ func (p *Provider) getClient() (*lego.Client, error) {
 		}
 	}
 
-	p.client = client
-	return p.client, nil
+	if p.clients == nil {
+		p.clients = make(map[string]*lego.Client)
+	}
+	p.clients[caServer.CAServer] = client
+
+	return client, nil
+}
+
+func register(client *lego.Client, eab *EAB) (*registration.Resource, error) {
+	if eab == nil {
+		return client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	}
+
+	return client.Registration.RegisterWithExternalAccountBinding(registration.RegisterEABOptions{
+		TermsOfServiceAgreed: true,
+		Kid:                  eab.Kid,
+		HmacEncoded:          eab.HmacEncoded,
+	})
 }
 
 func (p *Provider) initAccount(ctx context.Context) (*Account, error) {
@@ -317,7 +470,7 @@ func (p *Provider) initAccount(ctx context.Context) (*Account, error) {
 	return p.account, nil
 }
 
-func (p *Provider) resolveDomains(ctx context.Context, domains []string, tlsStore string) {
+func (p *Provider) resolveDomains(ctx context.Context, domains []string, tlsStore string, fallbackResolvers []string) {
 	if len(domains) == 0 {
 		log.FromContext(ctx).Debug("No domain parsed in provider ACME")
 		return
@@ -333,7 +486,7 @@ func (p *Provider) resolveDomains(ctx context.Context, domains []string, tlsStor
 		}
 
 		safe.Go(func() {
-			if _, err := p.resolveCertificate(ctx, domain, tlsStore); err != nil {
+			if err := p.resolveCertificateWithFallback(ctx, domain, tlsStore, fallbackResolvers); err != nil {
 				log.FromContext(ctx).Errorf("Unable to obtain ACME certificate for domains %q: %v", strings.Join(domains, ","), err)
 			}
 		})
@@ -371,7 +524,7 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 							for i := 0; i < len(domains); i++ {
 								domain := domains[i]
 								safe.Go(func() {
-									if _, err := p.resolveCertificate(ctx, domain, tlsStore); err != nil {
+									if err := p.resolveCertificate(ctx, domain, tlsStore); err != nil {
 										log.WithoutContext().WithField(log.ProviderName, p.ResolverName+".acme").
 											Errorf("Unable to obtain ACME certificate for domains %q : %v", strings.Join(domain.ToStrArray(), ","), err)
 									}
@@ -383,7 +536,7 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 								logger.Errorf("Error parsing domains in provider ACME: %v", err)
 								continue
 							}
-							p.resolveDomains(ctxRouter, domains, tlsStore)
+							p.resolveDomains(ctxRouter, domains, tlsStore, nil)
 						}
 					}
 				}
@@ -394,13 +547,15 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 					}
 					ctxRouter := log.With(ctx, log.Str(log.RouterName, routerName), log.Str(log.Rule, route.Rule))
 
+					fallbackResolvers := route.TLS.FallbackCertResolvers
+
 					tlsStore := "default"
 					if len(route.TLS.Domains) > 0 {
 						domains := deleteUnnecessaryDomains(ctxRouter, route.TLS.Domains)
 						for i := 0; i < len(domains); i++ {
 							domain := domains[i]
 							safe.Go(func() {
-								if _, err := p.resolveCertificate(ctx, domain, tlsStore); err != nil {
+								if err := p.resolveCertificateWithFallback(ctx, domain, tlsStore, fallbackResolvers); err != nil {
 									log.WithoutContext().WithField(log.ProviderName, p.ResolverName+".acme").
 										Errorf("Unable to obtain ACME certificate for domains %q : %v", strings.Join(domain.ToStrArray(), ","), err)
 								}
@@ -412,7 +567,7 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 							log.FromContext(ctxRouter).Errorf("Error parsing domains in provider ACME: %v", err)
 							continue
 						}
-						p.resolveDomains(ctxRouter, domains, tlsStore)
+						p.resolveDomains(ctxRouter, domains, tlsStore, fallbackResolvers)
 					}
 				}
 			case <-ctxPool.Done():
@@ -422,56 +577,148 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 	})
 }
 
-func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain, tlsStore string) (*certificate.Resource, error) {
+// resolveCertificateWithFallback resolves the certificate of the domain,
+// and falls back to the fallback resolvers, in priority order, when this resolver fails.
+func (p *Provider) resolveCertificateWithFallback(ctx context.Context, domain types.Domain, tlsStore string, fallbackResolvers []string) error {
+	err := p.resolveCertificate(ctx, domain, tlsStore)
+	if err == nil {
+		return nil
+	}
+
+	logger := log.FromContext(ctx)
+
+	for _, name := range fallbackResolvers {
+		fallback, ok := p.fallbackResolvers[name]
+		if !ok || name == p.ResolverName {
+			logger.Errorf("Unable to fall back to the resolver %q: not another ACME resolver", name)
+			continue
+		}
+
+		logger.Warnf("Unable to obtain ACME certificate for domains %q, falling back to the resolver %s: %v", strings.Join(domain.ToStrArray(), ","), name, err)
+
+		ctxFallback := log.With(ctx, log.Str(log.ProviderName, name+".acme"))
+		if err = fallback.resolveCertificate(ctxFallback, domain, tlsStore); err == nil {
+			p.domainStatuses.fellBack(domain, name)
+			return nil
+		}
+	}
+
+	return err
+}
+
+func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain, tlsStore string) error {
+	return p.resolveCertificateOf(ctx, domain, tlsStore, !p.isLeader())
+}
+
+// resolveCertificateOf resolves the certificate of the domain, or leaves it to the leader of the cluster when deferToLeader is true.
+func (p *Provider) resolveCertificateOf(ctx context.Context, domain types.Domain, tlsStore string, deferToLeader bool) error {
 	domains, err := p.getValidDomains(ctx, domain)
 	if err != nil {
-		return nil, err
+		p.domainStatuses.failed(domain, err)
+		return err
 	}
 
 	// Check provided certificates
 	uncheckedDomains := p.getUncheckedDomains(ctx, domains, tlsStore)
 	if len(uncheckedDomains) == 0 {
-		return nil, nil
+		return nil
 	}
 
 	p.addResolvingDomains(uncheckedDomains)
 	defer p.removeResolvingDomains(uncheckedDomains)
 
-	logger := log.FromContext(ctx)
-	logger.Debugf("Loading ACME certificates %+v...", uncheckedDomains)
+	if len(uncheckedDomains) > 1 {
+		domain = types.Domain{Main: uncheckedDomains[0], SANs: uncheckedDomains[1:]}
+	} else {
+		domain = types.Domain{Main: uncheckedDomains[0]}
+	}
+
+	p.domainStatuses.pending(domain)
+
+	if deferToLeader {
+		log.FromContext(ctx).Debugf("Leaving the ACME certificate for the domains %v to the leader of the cluster", uncheckedDomains)
+		p.deferredDomains.add(domain, tlsStore)
+		return nil
+	}
+
+	obtained := false
+	cert, err := p.withStoreLock(func(stored []*CertAndStore) (*CertAndStore, error) {
+		if cert := findCertificate(ctx, stored, uncheckedDomains, tlsStore, ""); cert != nil {
+			log.FromContext(ctx).Debugf("The certificate for the domains %v has been obtained by another instance", uncheckedDomains)
+			return cert, nil
+		}
 
-	client, err := p.getClient()
+		resource, caServer, err := p.obtainCertificate(ctx, domains, uncheckedDomains, "")
+		if err != nil {
+			return nil, err
+		}
+		obtained = true
+
+		return &CertAndStore{
+			Certificate: Certificate{Domain: domain, Certificate: resource.Certificate, Key: resource.PrivateKey, CAServer: caServer},
+			Store:       tlsStore,
+		}, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("cannot get ACME client %v", err)
+		p.domainStatuses.failed(domain, err)
+		return err
+	}
+
+	p.addCertificate(cert)
+
+	if obtained {
+		p.notifyCertificate(ctx, EventObtained, &cert.Certificate)
+	}
+
+	if p.AdditionalKeyType != "" {
+		if err := p.resolveAdditionalCertificate(ctx, domain, domains, tlsStore); err != nil {
+			log.FromContext(ctx).Errorf("Unable to obtain the %s ACME certificate for domains %q: %v", p.AdditionalKeyType, strings.Join(domains, ","), err)
+		}
 	}
 
+	return nil
+}
+
+// obtainCertificate obtains a certificate for the domains, and returns it along with the CA server which issued it.
+// The private key of the certificate is of the keyType of the resolver, or of the given keyType, when not empty.
+func (p *Provider) obtainCertificate(ctx context.Context, domains []string, uncheckedDomains []string, keyType string) (*certificate.Resource, string, error) {
+	logger := log.FromContext(ctx)
+	logger.Debugf("Loading ACME certificates %+v...", uncheckedDomains)
+
 	request := certificate.ObtainRequest{
 		Domains:    domains,
 		Bundle:     true,
 		MustStaple: oscpMustStaple,
 	}
 
-	cert, err := client.Certificate.Obtain(request)
+	if keyType != "" {
+		privateKey, err := certcrypto.GeneratePrivateKey(GetKeyType(ctx, keyType))
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to generate a %s private key: %w", keyType, err)
+		}
+
+		request.PrivateKey = privateKey
+	}
+
+	var cert *certificate.Resource
+	caServer, err := p.withFallback(ctx, uncheckedDomains, func(client *lego.Client) error {
+		var errO error
+		cert, errO = client.Certificate.Obtain(request)
+		return errO
+	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to generate a certificate for the domains %v: %v", uncheckedDomains, err)
+		return nil, "", fmt.Errorf("unable to generate a certificate for the domains %v: %v", uncheckedDomains, err)
 	}
 	if cert == nil {
-		return nil, fmt.Errorf("domains %v do not generate a certificate", uncheckedDomains)
+		return nil, "", fmt.Errorf("domains %v do not generate a certificate", uncheckedDomains)
 	}
 	if len(cert.Certificate) == 0 || len(cert.PrivateKey) == 0 {
-		return nil, fmt.Errorf("domains %v generate certificate with no value: %v", uncheckedDomains, cert)
+		return nil, "", fmt.Errorf("domains %v generate certificate with no value: %v", uncheckedDomains, cert)
 	}
 
-	logger.Debugf("Certificates obtained for domains %+v", uncheckedDomains)
-
-	if len(uncheckedDomains) > 1 {
-		domain = types.Domain{Main: uncheckedDomains[0], SANs: uncheckedDomains[1:]}
-	} else {
-		domain = types.Domain{Main: uncheckedDomains[0]}
-	}
-	p.addCertificateForDomain(domain, cert.Certificate, cert.PrivateKey, tlsStore)
+	logger.Debugf("Certificates obtained for domains %+v from %s", uncheckedDomains, caServer)
 
-	return cert, nil
+	return p.withPreferredChain(ctx, caServer, cert), caServer, nil
 }
 
 func (p *Provider) removeResolvingDomains(resolvingDomains []string) {
@@ -492,8 +739,8 @@ func (p *Provider) addResolvingDomains(resolvingDomains []string) {
 	}
 }
 
-func (p *Provider) addCertificateForDomain(domain types.Domain, certificate []byte, key []byte, tlsStore string) {
-	p.certsChan <- &CertAndStore{Certificate: Certificate{Certificate: certificate, Key: key, Domain: domain}, Store: tlsStore}
+func (p *Provider) addCertificate(cert *CertAndStore) {
+	p.certsChan <- cert
 }
 
 // deleteUnnecessaryDomains deletes from the configuration :
@@ -555,14 +802,28 @@ func deleteUnnecessaryDomains(ctx context.Context, domains []types.Domain) []typ
 
 func (p *Provider) watchCertificate(ctx context.Context) {
 	p.certsChan = make(chan *CertAndStore)
+	p.syncChan = make(chan struct{})
 
 	p.pool.GoCtx(func(ctxPool context.Context) {
 		for {
 			select {
 			case cert := <-p.certsChan:
+				if store, ok := p.Store.(SharedStore); ok {
+					certificates, err := p.syncCertificates(store, cert)
+					if err != nil {
+						log.FromContext(ctx).Error(err)
+						certificates = mergeCertificate(p.certificates, cert)
+					}
+
+					p.certificates = certificates
+					p.refreshCertificates()
+					p.certificateObtained(ctx, &cert.Certificate)
+					continue
+				}
+
 				certUpdated := false
 				for _, domainsCertificate := range p.certificates {
-					if reflect.DeepEqual(cert.Domain, domainsCertificate.Certificate.Domain) {
+					if sameCertificate(&cert.Certificate, &domainsCertificate.Certificate) {
 						domainsCertificate.Certificate = cert.Certificate
 						certUpdated = true
 						break
@@ -576,6 +837,24 @@ func (p *Provider) watchCertificate(ctx context.Context) {
 				if err != nil {
 					log.FromContext(ctx).Error(err)
 				}
+
+				p.certificateObtained(ctx, &cert.Certificate)
+			case <-p.syncChan:
+				certificates, err := p.syncCertificates(p.Store.(SharedStore), nil)
+				if err != nil {
+					log.FromContext(ctx).Errorf("Unable to reload the shared ACME certificates: %v", err)
+					continue
+				}
+
+				if !reflect.DeepEqual(certificates, p.certificates) {
+					p.certificates = certificates
+					p.refreshCertificates()
+
+					// The certificates obtained by the other instances, e.g. by the leader of the cluster, are valid here too.
+					for _, cert := range certificates {
+						p.certificateObtained(ctx, &cert.Certificate)
+					}
+				}
 			case <-ctxPool.Done():
 				return
 			}
@@ -621,39 +900,84 @@ func (p *Provider) refreshCertificates() {
 func (p *Provider) renewCertificates(ctx context.Context) {
 	logger := log.FromContext(ctx)
 
+	if !p.isLeader() {
+		logger.Debug("Leaving the renewal of the certificates to the leader of the cluster")
+		return
+	}
+
 	logger.Info("Testing certificate renew...")
 	for _, cert := range p.certificates {
 		crt, err := getX509Certificate(ctx, &cert.Certificate)
 		// If there's an error, we assume the cert is broken, and needs update
-		// <= 30 days left, renew certificate
-		if err != nil || crt == nil || crt.NotAfter.Before(time.Now().Add(24*30*time.Hour)) {
-			client, err := p.getClient()
-			if err != nil {
-				logger.Infof("Error renewing certificate from LE : %+v, %v", cert.Domain, err)
-				continue
+		// Within the renewal window, renew certificate
+		// Issued by a staging CA server while the resolver uses a production one, re-issue certificate
+		staging := p.mustReissueStagingCertificate(&cert.Certificate, crt)
+		if err != nil || crt == nil || p.mustRenewCertificate(cert.Domain, crt) || staging {
+			if cert.KeyType == "" {
+				p.domainStatuses.pending(cert.Domain)
+			}
+
+			if staging {
+				logger.Infof("The certificate for %+v has been issued by a staging CA server, re-issuing it", cert.Domain)
 			}
 
 			logger.Infof("Renewing certificate from LE : %+v", cert.Domain)
 
-			renewedCert, err := client.Certificate.Renew(certificate.Resource{
-				Domain:      cert.Domain.Main,
-				PrivateKey:  cert.Key,
-				Certificate: cert.Certificate.Certificate,
-			}, true, oscpMustStaple)
+			renewedHere := false
+			renewed, err := p.withStoreLock(func(stored []*CertAndStore) (*CertAndStore, error) {
+				if renewed := p.renewedCertificate(ctx, stored, cert); renewed != nil {
+					logger.Debugf("The certificate for %+v has been renewed by another instance", cert.Domain)
+					return renewed, nil
+				}
+
+				var renewedCert *certificate.Resource
+				caServer, err := p.withFallback(ctx, cert.Domain.ToStrArray(), func(client *lego.Client) error {
+					var errR error
+					renewedCert, errR = client.Certificate.Renew(certificate.Resource{
+						Domain:      cert.Domain.Main,
+						PrivateKey:  cert.Key,
+						Certificate: cert.Certificate.Certificate,
+					}, true, oscpMustStaple)
+					return errR
+				})
+				if err != nil {
+					return nil, err
+				}
 
+				if len(renewedCert.Certificate) == 0 || len(renewedCert.PrivateKey) == 0 {
+					return nil, fmt.Errorf("domains %v renew certificate with no value", cert.Domain.ToStrArray())
+				}
+				renewedCert = p.withPreferredChain(ctx, caServer, renewedCert)
+				renewedHere = true
+
+				return &CertAndStore{
+					Certificate: Certificate{Domain: cert.Domain, Certificate: renewedCert.Certificate, Key: renewedCert.PrivateKey, CAServer: caServer, KeyType: cert.KeyType},
+					Store:       cert.Store,
+				}, nil
+			})
 			if err != nil {
 				logger.Errorf("Error renewing certificate from LE: %v, %v", cert.Domain, err)
-				continue
-			}
+				if cert.KeyType == "" {
+					p.domainStatuses.failed(cert.Domain, err)
+				}
 
-			if len(renewedCert.Certificate) == 0 || len(renewedCert.PrivateKey) == 0 {
-				logger.Errorf("domains %v renew certificate with no value: %v", cert.Domain.ToStrArray(), cert)
+				keyType := cert.KeyType
+				if keyType == "" {
+					keyType = p.KeyType
+				}
+				p.notify(ctx, Event{Type: EventRenewalFailed, Domains: cert.Domain.ToStrArray(), KeyType: keyType, CAServer: cert.CAServer, Error: err.Error()})
 				continue
 			}
 
-			p.addCertificateForDomain(cert.Domain, renewedCert.Certificate, renewedCert.PrivateKey, cert.Store)
+			p.addCertificate(renewed)
+
+			if renewedHere {
+				p.notifyCertificate(ctx, EventRenewed, &renewed.Certificate)
+			}
 		}
 	}
+
+	p.resolveMissingAdditionalCertificates(ctx)
 }
 
 // Get provided certificate which check a domains list (Main and SANs)
@@ -723,14 +1047,26 @@ func (p *Provider) getValidDomains(ctx context.Context, domain types.Domain) ([]
 		return nil, errors.New("unable to generate a certificate in ACME provider when no domain is given")
 	}
 
-	if strings.HasPrefix(domain.Main, "*") {
+	// The wildcard domains (either main or SANs) are validated with the DNS-01 challenge,
+	// while the other domains of the same certificate may be validated with the HTTP-01 or TLS-ALPN-01 challenges.
+	for _, name := range domains {
+		if !strings.HasPrefix(name, "*") {
+			continue
+		}
+
 		if p.DNSChallenge == nil {
 			return nil, fmt.Errorf("unable to generate a wildcard certificate in ACME provider for domain %q : ACME needs a DNSChallenge", strings.Join(domains, ","))
 		}
 
-		if strings.HasPrefix(domain.Main, "*.*") {
+		if strings.HasPrefix(name, "*.*") {
 			return nil, fmt.Errorf("unable to generate a wildcard certificate in ACME provider for domain %q : ACME does not allow '*.*' wildcard domain", strings.Join(domains, ","))
 		}
+
+		apex := strings.TrimPrefix(name, "*.")
+		if p.HTTPChallenge == nil && p.TLSChallenge == nil && containsDomain(domains, apex) {
+			log.FromContext(ctx).Warnf("The domains %q require two DNS-01 TXT records for %q, which are not supported by every DNS provider: add the HTTP-01 or TLS-ALPN-01 challenge to the resolver to validate the non-wildcard domains",
+				strings.Join(domains, ","), apex)
+		}
 	}
 
 	var cleanDomains []string
@@ -746,6 +1082,16 @@ func (p *Provider) getValidDomains(ctx context.Context, domain types.Domain) ([]
 	return cleanDomains, nil
 }
 
+// containsDomain returns whether the domain is one of the given domains.
+func containsDomain(domains []string, domain string) bool {
+	for _, d := range domains {
+		if strings.EqualFold(d, domain) {
+			return true
+		}
+	}
+	return false
+}
+
 func isDomainAlreadyChecked(domainToCheck string, existentDomains []string) bool {
 	for _, certDomains := range existentDomains {
 		for _, certDomain := range strings.Split(certDomains, ",") {
@@ -192,6 +192,7 @@ func TestGetValidDomain(t *testing.T) {
 		desc            string
 		domains         types.Domain
 		dnsChallenge    *DNSChallenge
+		httpChallenge   *HTTPChallenge
 		expectedErr     string
 		expectedDomains []string
 	}{
@@ -237,6 +238,28 @@ func TestGetValidDomain(t *testing.T) {
 			expectedErr:     "",
 			expectedDomains: []string{"*.traefik.wtf", "traefik.wtf"},
 		},
+		{
+			desc:            "SAN wildcard without DNSChallenge",
+			domains:         types.Domain{Main: "traefik.wtf", SANs: []string{"*.traefik.wtf"}},
+			httpChallenge:   &HTTPChallenge{EntryPoint: "web"},
+			expectedErr:     "unable to generate a wildcard certificate in ACME provider for domain \"traefik.wtf,*.traefik.wtf\" : ACME needs a DNSChallenge",
+			expectedDomains: nil,
+		},
+		{
+			desc:            "unauthorized SAN wildcard",
+			domains:         types.Domain{Main: "traefik.wtf", SANs: []string{"*.*.traefik.wtf"}},
+			dnsChallenge:    &DNSChallenge{},
+			expectedErr:     "unable to generate a wildcard certificate in ACME provider for domain \"traefik.wtf,*.*.traefik.wtf\" : ACME does not allow '*.*' wildcard domain",
+			expectedDomains: nil,
+		},
+		{
+			desc:            "apex with HTTPChallenge and wildcard SAN with DNSChallenge",
+			domains:         types.Domain{Main: "traefik.wtf", SANs: []string{"*.traefik.wtf"}},
+			dnsChallenge:    &DNSChallenge{},
+			httpChallenge:   &HTTPChallenge{EntryPoint: "web"},
+			expectedErr:     "",
+			expectedDomains: []string{"traefik.wtf", "*.traefik.wtf"},
+		},
 		{
 			desc:            "wildcard SANs",
 			domains:         types.Domain{Main: "*.traefik.wtf", SANs: []string{"*.acme.wtf"}},
@@ -251,7 +274,7 @@ func TestGetValidDomain(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			acmeProvider := Provider{Configuration: &Configuration{DNSChallenge: test.dnsChallenge}}
+			acmeProvider := Provider{Configuration: &Configuration{DNSChallenge: test.dnsChallenge, HTTPChallenge: test.httpChallenge}}
 
 			domains, err := acmeProvider.getValidDomains(context.Background(), test.domains)
 
@@ -514,6 +537,75 @@ func TestIsAccountMatchingCaServer(t *testing.T) {
 	}
 }
 
+func TestEAB_validate(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		eab           *EAB
+		expectedError bool
+	}{
+		{
+			desc: "no external account binding",
+		},
+		{
+			desc: "valid credentials",
+			eab:  &EAB{Kid: "kid-1", HmacEncoded: "dGhpcyBpcyBhIGtleS0_Zm9yLWhtYWM"},
+		},
+		{
+			desc:          "missing kid",
+			eab:           &EAB{HmacEncoded: "dGhpcyBpcyBhIGtleQ"},
+			expectedError: true,
+		},
+		{
+			desc:          "missing HMAC key",
+			eab:           &EAB{Kid: "kid-1"},
+			expectedError: true,
+		},
+		{
+			desc:          "padded HMAC key",
+			eab:           &EAB{Kid: "kid-1", HmacEncoded: "dGhpcyBpcyBhIGtleQ=="},
+			expectedError: true,
+		},
+		{
+			desc:          "standard base64 HMAC key",
+			eab:           &EAB{Kid: "kid-1", HmacEncoded: "dGhpcyBpcyBhIGtleS0/Zm9yLWhtYWM"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.eab.validate()
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProvider_caServers(t *testing.T) {
+	eab := &EAB{Kid: "kid-1", HmacEncoded: "dGhpcyBpcyBhIGtleQ"}
+	fallbackEAB := &EAB{Kid: "kid-2", HmacEncoded: "dGhpcyBpcyBhIGtleQ"}
+
+	provider := &Provider{Configuration: &Configuration{
+		CAServer: "https://acme.zerossl.com/v2/DV90",
+		EAB:      eab,
+		FallbackCAServers: []FallbackCAServer{
+			{CAServer: "https://acme.sectigo.com/v2/DV", EAB: fallbackEAB},
+		},
+	}}
+
+	expected := []FallbackCAServer{
+		{CAServer: "https://acme.zerossl.com/v2/DV90", EAB: eab},
+		{CAServer: "https://acme.sectigo.com/v2/DV", EAB: fallbackEAB},
+	}
+	assert.Equal(t, expected, provider.caServers())
+}
+
 func TestInitAccount(t *testing.T) {
 	testCases := []struct {
 		desc            string
@@ -0,0 +1,45 @@
+package acme
+
+import (
+	"crypto/x509"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// defaultRenewBeforeDays is the number of days of remaining validity below which a certificate is renewed by default.
+const defaultRenewBeforeDays = 30
+
+// renewBefore returns the remaining validity below which a certificate is renewed.
+func (p *Provider) renewBefore() time.Duration {
+	days := defaultRenewBeforeDays
+	if p.Configuration != nil && p.RenewBeforeDays > 0 {
+		days = p.RenewBeforeDays
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// renewalTime returns the time from which the certificate of the domain, valid until notAfter, is renewed.
+// The renewal is brought forward by a part of the renewal jitter derived from the domain,
+// so that the certificates obtained at the same time are not all renewed at the same time,
+// while the renewal time of a domain stays the same from one check, or one instance, to the other.
+func (p *Provider) renewalTime(domain types.Domain, notAfter time.Time) time.Time {
+	renewal := notAfter.Add(-p.renewBefore())
+
+	if p.Configuration == nil || p.RenewalJitter <= 0 {
+		return renewal
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(strings.Join(domain.ToStrArray(), ",")))
+
+	return renewal.Add(-time.Duration(hash.Sum64() % uint64(p.RenewalJitter)))
+}
+
+// mustRenewCertificate returns whether the certificate of the domain, parsed as crt, is due for renewal.
+func (p *Provider) mustRenewCertificate(domain types.Domain, crt *x509.Certificate) bool {
+	return !time.Now().Before(p.renewalTime(domain, crt.NotAfter))
+}
@@ -0,0 +1,68 @@
+package acme
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvider_renewalTime(t *testing.T) {
+	notAfter := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+	domain := types.Domain{Main: "foo.example.com", SANs: []string{"www.foo.example.com"}}
+
+	testCases := []struct {
+		desc          string
+		configuration *Configuration
+		expectedMin   time.Time
+		expectedMax   time.Time
+	}{
+		{
+			desc:          "default renewal window",
+			configuration: &Configuration{},
+			expectedMin:   notAfter.Add(-30 * 24 * time.Hour),
+			expectedMax:   notAfter.Add(-30 * 24 * time.Hour),
+		},
+		{
+			desc:          "custom renewal window",
+			configuration: &Configuration{RenewBeforeDays: 2},
+			expectedMin:   notAfter.Add(-2 * 24 * time.Hour),
+			expectedMax:   notAfter.Add(-2 * 24 * time.Hour),
+		},
+		{
+			desc:          "renewal jitter",
+			configuration: &Configuration{RenewBeforeDays: 30, RenewalJitter: types.Duration(7 * 24 * time.Hour)},
+			expectedMin:   notAfter.Add(-37 * 24 * time.Hour),
+			expectedMax:   notAfter.Add(-30 * 24 * time.Hour),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Provider{Configuration: test.configuration}
+
+			renewal := p.renewalTime(domain, notAfter)
+			assert.False(t, renewal.Before(test.expectedMin), "%s is before %s", renewal, test.expectedMin)
+			assert.False(t, renewal.After(test.expectedMax), "%s is after %s", renewal, test.expectedMax)
+
+			// The renewal time of a domain does not change from one check to the other.
+			assert.Equal(t, renewal, p.renewalTime(domain, notAfter))
+		})
+	}
+}
+
+func TestProvider_renewalTime_spread(t *testing.T) {
+	p := &Provider{Configuration: &Configuration{RenewalJitter: types.Duration(7 * 24 * time.Hour)}}
+	notAfter := time.Now().Add(90 * 24 * time.Hour)
+
+	renewals := make(map[time.Time]struct{})
+	for _, main := range []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com"} {
+		renewals[p.renewalTime(types.Domain{Main: main}, notAfter)] = struct{}{}
+	}
+
+	assert.Len(t, renewals, 4)
+}
@@ -0,0 +1,337 @@
+package secretstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/encryption"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider/acme"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// lockAnnotationPrefix is the prefix of the annotations of the Secret holding the locks of the resolvers.
+	lockAnnotationPrefix = "acme.traefik.io/lock-"
+
+	// lockTTL is how long a lock is held without being renewed, so that the lock of a stopped instance expires.
+	lockTTL = 30 * time.Second
+
+	lockRetryInterval = 2 * time.Second
+)
+
+var _ acme.SharedStore = (*Store)(nil)
+
+// Store is a shared ACME store keeping the data of each resolver in a Kubernetes Secret,
+// under the <resolver>.account, <resolver>.certificates, and <resolver>.challenges keys,
+// and locked by the acme.traefik.io/lock-<resolver> annotation of the Secret.
+type Store struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	cipher    *encryption.Cipher
+
+	// holder identifies the instance in the locks.
+	holder string
+}
+
+// New creates a Store in the Secret of the configuration, encrypted with the cipher when it is not nil.
+func New(config *acme.KubernetesStorage, cipher *encryption.Cipher) (*Store, error) {
+	restConfig, err := newRestConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStore(client, config.Namespace, config.SecretName, cipher), nil
+}
+
+func newStore(client kubernetes.Interface, namespace, name string, cipher *encryption.Cipher) *Store {
+	holder, err := os.Hostname()
+	if err != nil {
+		holder = "traefik"
+	}
+
+	return &Store{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+		cipher:    cipher,
+		holder:    fmt.Sprintf("%s-%s", holder, uuid.NewUUID()),
+	}
+}
+
+// newRestConfig returns the configuration of the in-cluster client, or of the cluster-external client,
+// as the Kubernetes providers do.
+func newRestConfig(config *acme.KubernetesStorage) (*rest.Config, error) {
+	switch {
+	case os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != "":
+		restConfig, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create in-cluster configuration: %w", err)
+		}
+
+		if config.Endpoint != "" {
+			restConfig.Host = config.Endpoint
+		}
+
+		return restConfig, nil
+
+	case os.Getenv("KUBECONFIG") != "":
+		return clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
+
+	default:
+		if config.Endpoint == "" {
+			return nil, errors.New("endpoint missing for external cluster client")
+		}
+
+		restConfig := &rest.Config{
+			Host:        config.Endpoint,
+			BearerToken: config.Token,
+		}
+
+		if config.CertAuthFilePath != "" {
+			caData, err := ioutil.ReadFile(config.CertAuthFilePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file %s: %w", config.CertAuthFilePath, err)
+			}
+
+			restConfig.TLSClientConfig = rest.TLSClientConfig{CAData: caData}
+		}
+
+		return restConfig, nil
+	}
+}
+
+// GetAccount returns the ACME account of the resolver.
+func (s *Store) GetAccount(resolverName string) (*acme.Account, error) {
+	var account *acme.Account
+	if err := s.get(resolverName, "account", &account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// SaveAccount stores the ACME account of the resolver.
+func (s *Store) SaveAccount(resolverName string, account *acme.Account) error {
+	return s.put(resolverName, "account", account)
+}
+
+// GetCertificates returns the ACME certificates of the resolver.
+func (s *Store) GetCertificates(resolverName string) ([]*acme.CertAndStore, error) {
+	var certificates []*acme.CertAndStore
+	if err := s.get(resolverName, "certificates", &certificates); err != nil {
+		return nil, err
+	}
+
+	return certificates, nil
+}
+
+// SaveCertificates stores the ACME certificates of the resolver.
+func (s *Store) SaveCertificates(resolverName string, certificates []*acme.CertAndStore) error {
+	return s.put(resolverName, "certificates", certificates)
+}
+
+// GetChallenges returns the pending ACME challenges of the resolver.
+func (s *Store) GetChallenges(resolverName string) (*acme.StoredChallengeData, error) {
+	var challenges *acme.StoredChallengeData
+	if err := s.get(resolverName, "challenges", &challenges); err != nil {
+		return nil, err
+	}
+
+	return challenges, nil
+}
+
+// SaveChallenges stores the pending ACME challenges of the resolver.
+func (s *Store) SaveChallenges(resolverName string, challenges *acme.StoredChallengeData) error {
+	return s.put(resolverName, "challenges", challenges)
+}
+
+// Lock blocks until the lock of the resolver is acquired, and returns the function releasing it.
+// The lock is renewed while it is held.
+func (s *Store) Lock(resolverName string) (func(), error) {
+	annotation := lockAnnotationPrefix + resolverName
+
+	for {
+		acquired, err := s.tryLock(annotation)
+		if err != nil {
+			return nil, fmt.Errorf("unable to lock the ACME storage: %w", err)
+		}
+
+		if acquired {
+			break
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+
+	logger := log.WithoutContext().WithField(log.ProviderName, resolverName+".acme")
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.tryLock(annotation); err != nil {
+					logger.Errorf("Unable to renew the lock of the ACME storage: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+
+		err := s.update(func(secret *corev1.Secret) bool {
+			if holder, _ := parseLock(secret.Annotations[annotation]); holder != s.holder {
+				return false
+			}
+
+			delete(secret.Annotations, annotation)
+			return true
+		})
+		if err != nil {
+			logger.Errorf("Unable to unlock the ACME storage: %v", err)
+		}
+	}, nil
+}
+
+// tryLock acquires, or renews, the lock held by the annotation, unless another instance holds it.
+func (s *Store) tryLock(annotation string) (bool, error) {
+	var acquired bool
+
+	err := s.update(func(secret *corev1.Secret) bool {
+		holder, expiry := parseLock(secret.Annotations[annotation])
+		if holder != "" && holder != s.holder && time.Now().Before(expiry) {
+			acquired = false
+			return false
+		}
+
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string)
+		}
+		secret.Annotations[annotation] = fmt.Sprintf("%s %s", s.holder, time.Now().Add(lockTTL).UTC().Format(time.RFC3339))
+
+		acquired = true
+		return true
+	})
+
+	return acquired, err
+}
+
+// parseLock returns the holder and the expiry of the lock held by the annotation value, in the "<holder> <expiry>" format.
+func parseLock(value string) (string, time.Time) {
+	parts := strings.Fields(value)
+	if len(parts) != 2 {
+		return "", time.Time{}
+	}
+
+	expiry, err := time.Parse(time.RFC3339, parts[1])
+	if err != nil {
+		return "", time.Time{}
+	}
+
+	return parts[0], expiry
+}
+
+// get decodes the value of the key of the resolver into the value pointed to by v,
+// which is left untouched when the Secret or the key does not exist.
+func (s *Store) get(resolverName, name string, v interface{}) error {
+	secret, err := s.client.CoreV1().Secrets(s.namespace).Get(s.name, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get the Secret %s/%s: %w", s.namespace, s.name, err)
+	}
+
+	data, _, err := s.cipher.Decrypt(secret.Data[resolverName+"."+name])
+	if err != nil {
+		return fmt.Errorf("unable to read the %s of the ACME storage: %w", name, err)
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func (s *Store) put(resolverName, name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	data, err = s.cipher.Encrypt(data)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt the %s of the ACME storage: %w", name, err)
+	}
+
+	return s.update(func(secret *corev1.Secret) bool {
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[resolverName+"."+name] = data
+
+		return true
+	})
+}
+
+// update applies the mutation to the Secret, which is created when it does not exist,
+// and retries on the conflicts with the updates of the other instances.
+// The mutation returns whether the Secret has to be updated.
+func (s *Store) update(mutate func(secret *corev1.Secret) bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := s.client.CoreV1().Secrets(s.namespace).Get(s.name, metav1.GetOptions{})
+		if kerror.IsNotFound(err) {
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace},
+				Type:       corev1.SecretTypeOpaque,
+			}
+
+			if !mutate(secret) {
+				return nil
+			}
+
+			_, err = s.client.CoreV1().Secrets(s.namespace).Create(secret)
+			if kerror.IsAlreadyExists(err) {
+				// Created by another instance in the meantime, the mutation is applied again to the created Secret.
+				return kerror.NewConflict(corev1.Resource("secrets"), s.name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if !mutate(secret) {
+			return nil
+		}
+
+		_, err = s.client.CoreV1().Secrets(s.namespace).Update(secret)
+		return err
+	})
+}
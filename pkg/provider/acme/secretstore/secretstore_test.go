@@ -0,0 +1,69 @@
+package secretstore
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/provider/acme"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStore(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	secretStore := newStore(client, "default", "traefik-acme", nil)
+
+	account, err := secretStore.GetAccount("le")
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	require.NoError(t, secretStore.SaveAccount("le", &acme.Account{Email: "foo@example.com"}))
+
+	certificates := []*acme.CertAndStore{
+		{Certificate: acme.Certificate{Domain: types.Domain{Main: "example.com"}, Certificate: []byte("cert"), Key: []byte("key")}, Store: "default"},
+	}
+	require.NoError(t, secretStore.SaveCertificates("le", certificates))
+
+	secret, err := client.CoreV1().Secrets("default").Get("traefik-acme", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, secret.Data, "le.account")
+	assert.Contains(t, secret.Data, "le.certificates")
+
+	account, err = secretStore.GetAccount("le")
+	require.NoError(t, err)
+	assert.Equal(t, "foo@example.com", account.Email)
+
+	storedCertificates, err := secretStore.GetCertificates("le")
+	require.NoError(t, err)
+	assert.Equal(t, certificates, storedCertificates)
+
+	challenges, err := secretStore.GetChallenges("le")
+	require.NoError(t, err)
+	assert.Nil(t, challenges)
+}
+
+func TestStore_Lock(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	secretStore := newStore(client, "default", "traefik-acme", nil)
+	otherStore := newStore(client, "default", "traefik-acme", nil)
+
+	unlock, err := secretStore.Lock("le")
+	require.NoError(t, err)
+
+	acquired, err := otherStore.tryLock(lockAnnotationPrefix + "le")
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// The lock of another resolver is independent.
+	acquired, err = otherStore.tryLock(lockAnnotationPrefix + "other")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	unlock()
+
+	acquired, err = otherStore.tryLock(lockAnnotationPrefix + "le")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
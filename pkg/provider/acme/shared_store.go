@@ -0,0 +1,222 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// SharedStore is a Store shared by several Traefik instances,
+// which serializes their certificate requests and updates with a lock per resolver.
+type SharedStore interface {
+	Store
+	// Lock blocks until the lock of the data of the resolver is acquired, and returns the function releasing it.
+	Lock(resolverName string) (func(), error)
+}
+
+// SharedStorage holds the configuration of a storage shared by several Traefik instances, replacing the storage file.
+type SharedStorage struct {
+	Consul     *KVStorage         `description:"Store the ACME data in Consul." json:"consul,omitempty" toml:"consul,omitempty" yaml:"consul,omitempty" export:"true"`
+	Etcd       *KVStorage         `description:"Store the ACME data in etcd." json:"etcd,omitempty" toml:"etcd,omitempty" yaml:"etcd,omitempty" export:"true"`
+	Redis      *KVStorage         `description:"Store the ACME data in Redis." json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" export:"true"`
+	Kubernetes *KubernetesStorage `description:"Store the ACME data in a Kubernetes Secret." json:"kubernetes,omitempty" toml:"kubernetes,omitempty" yaml:"kubernetes,omitempty" export:"true"`
+
+	RefreshInterval types.Duration `description:"Interval of the reload of the certificates obtained by the other instances." json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (s *SharedStorage) SetDefaults() {
+	s.RefreshInterval = types.Duration(time.Minute)
+}
+
+func (s *SharedStorage) validate() error {
+	var backends int
+	for _, backend := range []bool{s.Consul != nil, s.Etcd != nil, s.Redis != nil, s.Kubernetes != nil} {
+		if backend {
+			backends++
+		}
+	}
+
+	if backends != 1 {
+		return errors.New("exactly one of consul, etcd, redis, or kubernetes is required")
+	}
+
+	if s.RefreshInterval <= 0 {
+		return errors.New("refreshInterval must be positive")
+	}
+
+	return nil
+}
+
+// KVStorage holds the configuration of a KV store keeping the ACME data.
+type KVStorage struct {
+	Endpoints []string         `description:"KV store endpoints." json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	RootKey   string           `description:"Root key of the ACME data." json:"rootKey,omitempty" toml:"rootKey,omitempty" yaml:"rootKey,omitempty" export:"true"`
+	Username  string           `description:"KV Username." json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password  string           `description:"KV Password." json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" secret:"true"`
+	TLS       *types.ClientTLS `description:"Enable TLS support." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (s *KVStorage) SetDefaults() {
+	s.RootKey = "traefik/acme"
+}
+
+// KubernetesStorage holds the configuration of the Kubernetes Secret keeping the ACME data.
+type KubernetesStorage struct {
+	Endpoint         string `description:"Kubernetes server endpoint (required for external cluster client)." json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Token            string `description:"Kubernetes bearer token (not needed for in-cluster client)." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" secret:"true"`
+	CertAuthFilePath string `description:"Kubernetes certificate authority file path (not needed for in-cluster client)." json:"certAuthFilePath,omitempty" toml:"certAuthFilePath,omitempty" yaml:"certAuthFilePath,omitempty"`
+	Namespace        string `description:"Namespace of the Secret." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	SecretName       string `description:"Name of the Secret." json:"secretName,omitempty" toml:"secretName,omitempty" yaml:"secretName,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (s *KubernetesStorage) SetDefaults() {
+	s.Namespace = "default"
+	s.SecretName = "traefik-acme"
+}
+
+// withStoreLock obtains a certificate under the lock of the shared store, given the certificates stored by all the instances,
+// and stores the obtained certificate before releasing the lock, so that the other instances do not request it again.
+// When the store is not shared, the certificate is obtained without lock, and stored afterwards by watchCertificate.
+func (p *Provider) withStoreLock(obtain func(stored []*CertAndStore) (*CertAndStore, error)) (*CertAndStore, error) {
+	store, ok := p.Store.(SharedStore)
+	if !ok {
+		return obtain(nil)
+	}
+
+	unlock, err := store.Lock(p.ResolverName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to lock the ACME storage: %w", err)
+	}
+	defer unlock()
+
+	stored, err := store.GetCertificates(p.ResolverName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the stored ACME certificates: %w", err)
+	}
+
+	cert, err := obtain(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.SaveCertificates(p.ResolverName, mergeCertificate(stored, cert)); err != nil {
+		return nil, fmt.Errorf("unable to store the ACME certificate: %w", err)
+	}
+
+	return cert, nil
+}
+
+// syncCertificates returns the certificates stored by all the instances, along with the certificate,
+// which is stored under the lock of the shared store when it is not nil.
+func (p *Provider) syncCertificates(store SharedStore, cert *CertAndStore) ([]*CertAndStore, error) {
+	unlock, err := store.Lock(p.ResolverName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to lock the ACME storage: %w", err)
+	}
+	defer unlock()
+
+	stored, err := store.GetCertificates(p.ResolverName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the stored ACME certificates: %w", err)
+	}
+
+	if cert == nil {
+		return stored, nil
+	}
+
+	certificates := mergeCertificate(stored, cert)
+	if err := store.SaveCertificates(p.ResolverName, certificates); err != nil {
+		return nil, fmt.Errorf("unable to store the ACME certificate: %w", err)
+	}
+
+	return certificates, nil
+}
+
+// mergeCertificate returns a copy of the certificates, where the certificate replaces the certificate of the same domain and key type.
+func mergeCertificate(certificates []*CertAndStore, cert *CertAndStore) []*CertAndStore {
+	merged := make([]*CertAndStore, 0, len(certificates)+1)
+
+	updated := false
+	for _, c := range certificates {
+		if !updated && sameCertificate(&c.Certificate, &cert.Certificate) {
+			merged = append(merged, cert)
+			updated = true
+			continue
+		}
+		merged = append(merged, c)
+	}
+
+	if !updated {
+		merged = append(merged, cert)
+	}
+
+	return merged
+}
+
+// findCertificate returns the certificate of the TLS store and of the key type validating all the domains, if any.
+func findCertificate(ctx context.Context, certificates []*CertAndStore, domains []string, tlsStore, keyType string) *CertAndStore {
+	for _, cert := range certificates {
+		if cert.Store != tlsStore || cert.KeyType != keyType {
+			continue
+		}
+
+		if len(searchUncheckedDomains(ctx, domains, []string{strings.Join(cert.Domain.ToStrArray(), ",")})) == 0 {
+			return cert
+		}
+	}
+
+	return nil
+}
+
+// renewedCertificate returns the stored certificate of the same domain and key type as the certificate,
+// when it has been renewed by another instance since.
+func (p *Provider) renewedCertificate(ctx context.Context, certificates []*CertAndStore, cert *CertAndStore) *CertAndStore {
+	for _, c := range certificates {
+		if !sameCertificate(&c.Certificate, &cert.Certificate) || bytes.Equal(c.Certificate.Certificate, cert.Certificate.Certificate) {
+			continue
+		}
+
+		crt, err := getX509Certificate(ctx, &c.Certificate)
+		if err == nil && crt != nil && !p.mustRenewCertificate(c.Domain, crt) && !p.mustReissueStagingCertificate(&c.Certificate, crt) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// watchSharedCertificates reloads periodically the certificates obtained by the other instances.
+func (p *Provider) watchSharedCertificates(ctx context.Context) {
+	if _, ok := p.Store.(SharedStore); !ok || p.SharedStorage == nil {
+		return
+	}
+
+	p.pool.GoCtx(func(ctxPool context.Context) {
+		ticker := time.NewTicker(time.Duration(p.SharedStorage.RefreshInterval))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case p.syncChan <- struct{}{}:
+				case <-ctxPool.Done():
+					return
+				}
+			case <-ctxPool.Done():
+				return
+			}
+		}
+	})
+
+	log.FromContext(ctx).Debugf("Reloading the shared ACME certificates every %s", time.Duration(p.SharedStorage.RefreshInterval))
+}
@@ -0,0 +1,47 @@
+package acme
+
+import (
+	"crypto/x509"
+	"strings"
+)
+
+// stagingIssuerPrefixes are the prefixes of the names of the intermediates used by the Let's Encrypt staging environment,
+// to detect the staging certificates stored before the CA server of the certificates was recorded.
+var stagingIssuerPrefixes = []string{"(STAGING)", "Fake LE"}
+
+// isStagingCAServer returns whether the CA server is a staging environment.
+func isStagingCAServer(caServer string) bool {
+	return strings.Contains(caServer, "staging")
+}
+
+// isStagingCertificate returns whether the certificate has been issued by a staging environment.
+func isStagingCertificate(cert *Certificate, crt *x509.Certificate) bool {
+	if isStagingCAServer(cert.CAServer) {
+		return true
+	}
+
+	if crt == nil {
+		return false
+	}
+
+	names := append([]string{crt.Issuer.CommonName}, crt.Issuer.Organization...)
+	for _, name := range names {
+		for _, prefix := range stagingIssuerPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// mustReissueStagingCertificate returns whether the certificate, issued by a staging environment,
+// must be re-issued because the resolver now uses a production CA server.
+func (p *Provider) mustReissueStagingCertificate(cert *Certificate, crt *x509.Certificate) bool {
+	if !p.ReissueStagingCertificates || isStagingCAServer(p.caServers()[0].CAServer) {
+		return false
+	}
+
+	return isStagingCertificate(cert, crt)
+}
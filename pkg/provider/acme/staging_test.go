@@ -0,0 +1,104 @@
+package acme
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMustReissueStagingCertificate(t *testing.T) {
+	stagingIssuer := &x509.Certificate{Issuer: pkix.Name{CommonName: "Fake LE Intermediate X1"}}
+	newStagingIssuer := &x509.Certificate{Issuer: pkix.Name{CommonName: "(STAGING) Artificial Apricot R3", Organization: []string{"(STAGING) Let's Encrypt"}}}
+	productionIssuer := &x509.Certificate{Issuer: pkix.Name{CommonName: "Let's Encrypt Authority X3", Organization: []string{"Let's Encrypt"}}}
+
+	testCases := []struct {
+		desc     string
+		reissue  bool
+		caServer string
+		cert     *Certificate
+		crt      *x509.Certificate
+		expected bool
+	}{
+		{
+			desc:     "staging CA server recorded",
+			reissue:  true,
+			caServer: lego.LEDirectoryProduction,
+			cert:     &Certificate{CAServer: lego.LEDirectoryStaging},
+			crt:      productionIssuer,
+			expected: true,
+		},
+		{
+			desc:     "production CA server recorded",
+			reissue:  true,
+			caServer: lego.LEDirectoryProduction,
+			cert:     &Certificate{CAServer: lego.LEDirectoryProduction},
+			crt:      productionIssuer,
+			expected: false,
+		},
+		{
+			desc:     "no CA server recorded, staging issuer",
+			reissue:  true,
+			caServer: lego.LEDirectoryProduction,
+			cert:     &Certificate{},
+			crt:      stagingIssuer,
+			expected: true,
+		},
+		{
+			desc:     "no CA server recorded, new staging issuer",
+			reissue:  true,
+			caServer: lego.LEDirectoryProduction,
+			cert:     &Certificate{},
+			crt:      newStagingIssuer,
+			expected: true,
+		},
+		{
+			desc:     "no CA server recorded, production issuer",
+			reissue:  true,
+			caServer: lego.LEDirectoryProduction,
+			cert:     &Certificate{},
+			crt:      productionIssuer,
+			expected: false,
+		},
+		{
+			desc:     "no CA server recorded, broken certificate",
+			reissue:  true,
+			caServer: lego.LEDirectoryProduction,
+			cert:     &Certificate{},
+			expected: false,
+		},
+		{
+			desc:     "resolver still using the staging CA server",
+			reissue:  true,
+			caServer: lego.LEDirectoryStaging,
+			cert:     &Certificate{CAServer: lego.LEDirectoryStaging},
+			crt:      stagingIssuer,
+			expected: false,
+		},
+		{
+			desc:     "re-issuance disabled",
+			caServer: lego.LEDirectoryProduction,
+			cert:     &Certificate{CAServer: lego.LEDirectoryStaging},
+			crt:      stagingIssuer,
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Provider{
+				Configuration: &Configuration{
+					CAServer:                   test.caServer,
+					ReissueStagingCertificates: test.reissue,
+				},
+			}
+
+			assert.Equal(t, test.expected, p.mustReissueStagingCertificate(test.cert, test.crt))
+		})
+	}
+}
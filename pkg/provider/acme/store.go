@@ -4,6 +4,8 @@ package acme
 type StoredData struct {
 	Account      *Account
 	Certificates []*CertAndStore
+	// Challenges holds the pending challenges, so that a restart during a validation does not fail it.
+	Challenges *StoredChallengeData `json:",omitempty"`
 }
 
 // StoredChallengeData represents the data managed by ChallengeStore.
@@ -18,6 +20,8 @@ type Store interface {
 	SaveAccount(string, *Account) error
 	GetCertificates(string) ([]*CertAndStore, error)
 	SaveCertificates(string, []*CertAndStore) error
+	GetChallenges(string) (*StoredChallengeData, error)
+	SaveChallenges(string, *StoredChallengeData) error
 }
 
 // ChallengeStore is a generic interface that represents a store for challenge data.
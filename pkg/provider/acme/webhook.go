@@ -0,0 +1,184 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/containous/traefik/v2/pkg/version"
+)
+
+// Types of the events notified to the webhook.
+const (
+	EventObtained      = "obtained"
+	EventRenewed       = "renewed"
+	EventRenewalFailed = "renewalFailed"
+	EventRateLimited   = "rateLimited"
+)
+
+var eventTypes = []string{EventObtained, EventRenewed, EventRenewalFailed, EventRateLimited}
+
+// Webhook holds the configuration of the webhook notified of the certificate events of the resolver.
+type Webhook struct {
+	URL     string            `description:"URL receiving the events, as JSON POST requests." json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	Headers map[string]string `description:"Headers added to the requests, e.g. for their authentication." json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	Events  []string          `description:"Events notified, among 'obtained', 'renewed', 'renewalFailed', and 'rateLimited', defaults to all of them." json:"events,omitempty" toml:"events,omitempty" yaml:"events,omitempty" export:"true"`
+	Timeout types.Duration    `description:"Timeout of the requests." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (w *Webhook) SetDefaults() {
+	w.Timeout = types.Duration(10 * time.Second)
+}
+
+// Event is a certificate event notified to the webhook.
+type Event struct {
+	Type     string     `json:"type"`
+	Resolver string     `json:"resolver"`
+	Domains  []string   `json:"domains,omitempty"`
+	KeyType  string     `json:"keyType,omitempty"`
+	CAServer string     `json:"caServer,omitempty"`
+	NotAfter *time.Time `json:"notAfter,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Time     time.Time  `json:"time"`
+}
+
+// webhookNotifier posts the events to the webhook, in the background.
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+	events  map[string]struct{}
+	client  *http.Client
+}
+
+func newWebhookNotifier(config *Webhook) (*webhookNotifier, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: an http or https URL is required", config.URL)
+	}
+
+	if config.Timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	events := config.Events
+	if len(events) == 0 {
+		events = eventTypes
+	}
+
+	notifier := &webhookNotifier{
+		url:     config.URL,
+		headers: config.Headers,
+		events:  make(map[string]struct{}, len(events)),
+		client:  &http.Client{Timeout: time.Duration(config.Timeout)},
+	}
+
+	for _, event := range events {
+		if !containsEventType(event) {
+			return nil, fmt.Errorf("unknown event %q: use one of %s", event, strings.Join(eventTypes, ", "))
+		}
+		notifier.events[event] = struct{}{}
+	}
+
+	return notifier, nil
+}
+
+func containsEventType(eventType string) bool {
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *webhookNotifier) notify(ctx context.Context, event Event) {
+	if _, ok := w.events[event.Type]; !ok {
+		return
+	}
+
+	safe.Go(func() {
+		if err := w.post(event); err != nil {
+			log.FromContext(ctx).Errorf("Unable to notify the ACME %s event of domains %q to the webhook: %v", event.Type, strings.Join(event.Domains, ","), err)
+		}
+	})
+}
+
+func (w *webhookNotifier) post(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Traefik/"+version.Version)
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// notify notifies the event to the webhook of the resolver, if any.
+func (p *Provider) notify(ctx context.Context, event Event) {
+	if p.webhook == nil {
+		return
+	}
+
+	event.Resolver = p.ResolverName
+	event.Time = time.Now().UTC()
+
+	p.webhook.notify(ctx, event)
+}
+
+// notifyCertificate notifies the event of the certificate, along with its expiry.
+func (p *Provider) notifyCertificate(ctx context.Context, eventType string, cert *Certificate) {
+	event := Event{
+		Type:     eventType,
+		Domains:  cert.Domain.ToStrArray(),
+		KeyType:  cert.KeyType,
+		CAServer: cert.CAServer,
+	}
+
+	if event.KeyType == "" {
+		event.KeyType = p.KeyType
+	}
+
+	if crt, err := getX509Certificate(ctx, cert); err == nil && crt != nil {
+		event.NotAfter = &crt.NotAfter
+	}
+
+	p.notify(ctx, event)
+}
@@ -0,0 +1,121 @@
+package acme
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhookNotifier(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      Webhook
+		expectedErr bool
+	}{
+		{
+			desc:   "all events",
+			config: Webhook{URL: "https://hooks.example.com/acme", Timeout: types.Duration(time.Second)},
+		},
+		{
+			desc:   "some events",
+			config: Webhook{URL: "http://127.0.0.1:8080", Events: []string{EventRenewalFailed, EventRateLimited}, Timeout: types.Duration(time.Second)},
+		},
+		{
+			desc:        "unknown event",
+			config:      Webhook{URL: "https://hooks.example.com/acme", Events: []string{"revoked"}, Timeout: types.Duration(time.Second)},
+			expectedErr: true,
+		},
+		{
+			desc:        "no URL",
+			config:      Webhook{Timeout: types.Duration(time.Second)},
+			expectedErr: true,
+		},
+		{
+			desc:        "unsupported scheme",
+			config:      Webhook{URL: "ftp://hooks.example.com", Timeout: types.Duration(time.Second)},
+			expectedErr: true,
+		},
+		{
+			desc:        "no timeout",
+			config:      Webhook{URL: "https://hooks.example.com/acme"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newWebhookNotifier(&test.config)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestProvider_notify(t *testing.T) {
+	events := make(chan Event, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+		var event Event
+		err := json.NewDecoder(req.Body).Decode(&event)
+		require.NoError(t, err)
+
+		events <- event
+	}))
+	defer server.Close()
+
+	webhook, err := newWebhookNotifier(&Webhook{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Events:  []string{EventRenewalFailed, EventRateLimited},
+		Timeout: types.Duration(time.Second),
+	})
+	require.NoError(t, err)
+
+	p := &Provider{
+		Configuration: &Configuration{KeyType: "RSA4096"},
+		ResolverName:  "myresolver",
+		webhook:       webhook,
+	}
+
+	// The events which are not configured are not notified.
+	p.notifyCertificate(context.Background(), EventObtained, &Certificate{Domain: types.Domain{Main: "foo.com"}})
+
+	p.notify(context.Background(), Event{Type: EventRateLimited, Domains: []string{"foo.com", "bar.com"}, CAServer: "https://acme.example.com/directory", Error: "too many certificates"})
+
+	select {
+	case event := <-events:
+		assert.Equal(t, EventRateLimited, event.Type)
+		assert.Equal(t, "myresolver", event.Resolver)
+		assert.Equal(t, []string{"foo.com", "bar.com"}, event.Domains)
+		assert.Equal(t, "https://acme.example.com/directory", event.CAServer)
+		assert.Equal(t, "too many certificates", event.Error)
+		assert.Nil(t, event.NotAfter)
+		assert.False(t, event.Time.IsZero())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event was not notified")
+	}
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event: %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
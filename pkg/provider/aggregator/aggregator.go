@@ -1,6 +1,7 @@
 package aggregator
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
@@ -15,67 +16,78 @@ import (
 type ProviderAggregator struct {
 	fileProvider *file.Provider
 	providers    []provider.Provider
+	// resyncers are the providers able to resync, by provider name.
+	resyncers map[string]provider.Resyncer
 }
 
 // NewProviderAggregator returns an aggregate of all the providers configured in the static configuration.
 func NewProviderAggregator(conf static.Providers) ProviderAggregator {
-	p := ProviderAggregator{}
+	p := ProviderAggregator{resyncers: make(map[string]provider.Resyncer)}
 
 	if conf.File != nil {
-		p.quietAddProvider(conf.File)
+		p.quietAddProvider("file", conf.File)
 	}
 
 	if conf.Docker != nil {
-		p.quietAddProvider(conf.Docker)
+		p.quietAddProvider("docker", conf.Docker)
 	}
 
 	if conf.Marathon != nil {
-		p.quietAddProvider(conf.Marathon)
+		p.quietAddProvider("marathon", conf.Marathon)
 	}
 
 	if conf.Rest != nil {
-		p.quietAddProvider(conf.Rest)
+		p.quietAddProvider("rest", conf.Rest)
 	}
 
 	if conf.KubernetesIngress != nil {
-		p.quietAddProvider(conf.KubernetesIngress)
+		p.quietAddProvider("kubernetes", conf.KubernetesIngress)
 	}
 
 	if conf.KubernetesCRD != nil {
-		p.quietAddProvider(conf.KubernetesCRD)
+		p.quietAddProvider("kubernetescrd", conf.KubernetesCRD)
 	}
 
 	if conf.Rancher != nil {
-		p.quietAddProvider(conf.Rancher)
+		p.quietAddProvider("rancher", conf.Rancher)
 	}
 
 	if conf.ConsulCatalog != nil {
-		p.quietAddProvider(conf.ConsulCatalog)
+		p.quietAddProvider("consulcatalog", conf.ConsulCatalog)
 	}
 
 	if conf.Consul != nil {
-		p.quietAddProvider(conf.Consul)
+		p.quietAddProvider("consul", conf.Consul)
 	}
 
 	if conf.Etcd != nil {
-		p.quietAddProvider(conf.Etcd)
+		p.quietAddProvider("etcd", conf.Etcd)
 	}
 
 	if conf.ZooKeeper != nil {
-		p.quietAddProvider(conf.ZooKeeper)
+		p.quietAddProvider("zookeeper", conf.ZooKeeper)
 	}
 
 	if conf.Redis != nil {
-		p.quietAddProvider(conf.Redis)
+		p.quietAddProvider("redis", conf.Redis)
+	}
+
+	if conf.Vault != nil {
+		p.quietAddProvider("vault", conf.Vault)
 	}
 
 	return p
 }
 
-func (p *ProviderAggregator) quietAddProvider(provider provider.Provider) {
-	err := p.AddProvider(provider)
+func (p *ProviderAggregator) quietAddProvider(name string, prd provider.Provider) {
+	err := p.AddProvider(prd)
 	if err != nil {
-		log.WithoutContext().Errorf("Error while initializing provider %T: %v", provider, err)
+		log.WithoutContext().Errorf("Error while initializing provider %T: %v", prd, err)
+		return
+	}
+
+	if resyncer, ok := prd.(provider.Resyncer); ok {
+		p.resyncers[name] = resyncer
 	}
 }
 
@@ -114,6 +126,16 @@ func (p ProviderAggregator) Provide(configurationChan chan<- dynamic.Message, po
 	return nil
 }
 
+// Resync resyncs the whole configuration of the provider named providerName.
+func (p ProviderAggregator) Resync(ctx context.Context, providerName string) error {
+	resyncer, ok := p.resyncers[providerName]
+	if !ok {
+		return provider.ErrResyncNotSupported
+	}
+
+	return resyncer.Resync(ctx)
+}
+
 func launchProvider(configurationChan chan<- dynamic.Message, pool *safe.Pool, prd provider.Provider) {
 	jsonConf, err := json.Marshal(prd)
 	if err != nil {
@@ -0,0 +1,218 @@
+package constraints
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/vulcand/predicate"
+)
+
+// Object holds the attributes of a discovered object (container, service, Kubernetes resource, ...),
+// which are matched against the constraints of its provider.
+type Object struct {
+	// Labels are the labels of the object.
+	Labels map[string]string
+	// Tags are the tags of the object.
+	Tags []string
+	// Namespace is the namespace of the object.
+	Namespace string
+	// Node is the name of the node running the object.
+	Node string
+}
+
+type constraintFunc func(Object) bool
+
+// Constraints is a constraints expression, compiled once to be matched against all the objects of a provider.
+type Constraints struct {
+	expr     string
+	match    constraintFunc
+	usesNode bool
+}
+
+// Compile compiles the constraints expression, and returns the constraints matching all the objects if it is empty.
+// The expression must match any logical boolean combination (`&&`, `||`, `!`, and parentheses) of:
+// - `Label(name, value)`, and `LabelRegex(name, regex)`
+// - `Tag(value)`, and `TagRegex(regex)`
+// - `Namespace(name)`, and `NamespaceRegex(regex)`
+// - `Node(name)`, and `NodeRegex(regex)`
+// - `MarathonConstraint(value)`
+// The regular expressions are compiled along with the expression, and match any part of the values.
+func Compile(expr string) (*Constraints, error) {
+	if expr == "" {
+		return &Constraints{}, nil
+	}
+
+	var usesNode bool
+
+	p, err := predicate.NewParser(predicate.Def{
+		Operators: predicate.Operators{
+			AND: andFunc,
+			NOT: notFunc,
+			OR:  orFunc,
+		},
+		Functions: map[string]interface{}{
+			"Label":          labelFunc,
+			"LabelRegex":     labelRegexFunc,
+			"Tag":            tagFunc,
+			"TagRegex":       tagRegexFunc,
+			"Namespace":      namespaceFunc,
+			"NamespaceRegex": namespaceRegexFunc,
+			"Node": func(name string) constraintFunc {
+				usesNode = true
+				return nodeFunc(name)
+			},
+			"NodeRegex": func(expr string) (constraintFunc, error) {
+				usesNode = true
+				return nodeRegexFunc(expr)
+			},
+			"MarathonConstraint": marathonConstraintFunc,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parse, err := p.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	fn, ok := parse.(constraintFunc)
+	if !ok {
+		return nil, fmt.Errorf("invalid constraints expression: %q", expr)
+	}
+
+	return &Constraints{expr: expr, match: fn, usesNode: usesNode}, nil
+}
+
+// Match reports whether the object matches the constraints.
+func (c *Constraints) Match(object Object) bool {
+	if c == nil || c.match == nil {
+		return true
+	}
+
+	return c.match(object)
+}
+
+// UsesNode reports whether the expression refers to the node of the objects,
+// which must then be known to match them.
+func (c *Constraints) UsesNode() bool {
+	return c != nil && c.usesNode
+}
+
+// String returns the constraints expression.
+func (c *Constraints) String() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.expr
+}
+
+func labelFunc(name, value string) constraintFunc {
+	return func(object Object) bool {
+		return object.Labels[name] == value
+	}
+}
+
+func labelRegexFunc(name, expr string) (constraintFunc, error) {
+	exp, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(object Object) bool {
+		return exp.MatchString(object.Labels[name])
+	}, nil
+}
+
+func tagFunc(value string) constraintFunc {
+	return func(object Object) bool {
+		for _, tag := range object.Tags {
+			if tag == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func tagRegexFunc(expr string) (constraintFunc, error) {
+	exp, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(object Object) bool {
+		for _, tag := range object.Tags {
+			if exp.MatchString(tag) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func namespaceFunc(name string) constraintFunc {
+	return func(object Object) bool {
+		return object.Namespace == name
+	}
+}
+
+func namespaceRegexFunc(expr string) (constraintFunc, error) {
+	exp, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(object Object) bool {
+		return exp.MatchString(object.Namespace)
+	}, nil
+}
+
+func nodeFunc(name string) constraintFunc {
+	return func(object Object) bool {
+		return object.Node == name
+	}
+}
+
+func nodeRegexFunc(expr string) (constraintFunc, error) {
+	exp, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(object Object) bool {
+		return exp.MatchString(object.Node)
+	}, nil
+}
+
+func marathonConstraintFunc(value string) constraintFunc {
+	return func(object Object) bool {
+		for k, v := range object.Labels {
+			if strings.HasPrefix(k, MarathonConstraintPrefix) && v == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func andFunc(a, b constraintFunc) constraintFunc {
+	return func(object Object) bool {
+		return a(object) && b(object)
+	}
+}
+
+func orFunc(a, b constraintFunc) constraintFunc {
+	return func(object Object) bool {
+		return a(object) || b(object)
+	}
+}
+
+func notFunc(a constraintFunc) constraintFunc {
+	return func(object Object) bool {
+		return !a(object)
+	}
+}
@@ -0,0 +1,164 @@
+package constraints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraints_Match(t *testing.T) {
+	object := Object{
+		Labels: map[string]string{
+			"hello":                                 "world",
+			"foo":                                   "bar",
+			MarathonConstraintPrefix + "-rack_id-0": "rack_id:CLUSTER:rack-1",
+		},
+		Tags:      []string{"traefik.tags=foo", "public"},
+		Namespace: "production",
+		Node:      "worker-1",
+	}
+
+	testCases := []struct {
+		expr     string
+		expected bool
+	}{
+		{
+			expr:     ``,
+			expected: true,
+		},
+		{
+			expr:     `Label("hello", "world")`,
+			expected: true,
+		},
+		{
+			expr:     `LabelRegex("hello", "w\\w+")`,
+			expected: true,
+		},
+		{
+			expr:     `LabelRegex("hi", "w\\w+")`,
+			expected: false,
+		},
+		{
+			expr:     `Tag("public")`,
+			expected: true,
+		},
+		{
+			expr:     `TagRegex("^traefik\\.tags=")`,
+			expected: true,
+		},
+		{
+			expr:     `Namespace("production")`,
+			expected: true,
+		},
+		{
+			expr:     `NamespaceRegex("^prod")`,
+			expected: true,
+		},
+		{
+			expr:     `Namespace("staging")`,
+			expected: false,
+		},
+		{
+			expr:     `Node("worker-1")`,
+			expected: true,
+		},
+		{
+			expr:     `NodeRegex("^manager-")`,
+			expected: false,
+		},
+		{
+			expr:     `MarathonConstraint("rack_id:CLUSTER:rack-1")`,
+			expected: true,
+		},
+		{
+			expr:     `Namespace("production") && !Node("worker-1")`,
+			expected: false,
+		},
+		{
+			expr:     `Namespace("staging") || (Label("foo", "bar") && Tag("public"))`,
+			expected: true,
+		},
+		{
+			expr:     `!(Namespace("staging") || NodeRegex("^worker-"))`,
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.expr, func(t *testing.T) {
+			t.Parallel()
+
+			constraints, err := Compile(test.expr)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expr, constraints.String())
+			assert.Equal(t, test.expected, constraints.Match(object))
+		})
+	}
+}
+
+func TestCompile_invalid(t *testing.T) {
+	testCases := []string{
+		`Label("hello")`,
+		`Foo("hello")`,
+		`Tag()`,
+		`LabelRegex("hello", "w(\\w+")`,
+		`NamespaceRegex("(")`,
+		`Node("worker-1") &&`,
+		`"worker-1"`,
+	}
+
+	for _, expr := range testCases {
+		expr := expr
+		t.Run(expr, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Compile(expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConstraints_UsesNode(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected bool
+	}{
+		{
+			expr: ``,
+		},
+		{
+			expr: `Label("hello", "world") && !Namespace("staging")`,
+		},
+		{
+			expr:     `Tag("public") || Node("worker-1")`,
+			expected: true,
+		},
+		{
+			expr:     `!NodeRegex("^manager-")`,
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.expr, func(t *testing.T) {
+			t.Parallel()
+
+			constraints, err := Compile(test.expr)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, constraints.UsesNode())
+		})
+	}
+}
+
+func TestConstraints_Match_nil(t *testing.T) {
+	var constraints *Constraints
+
+	assert.True(t, constraints.Match(Object{}))
+	assert.False(t, constraints.UsesNode())
+	assert.Equal(t, "", constraints.String())
+}
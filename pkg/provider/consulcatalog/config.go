@@ -93,13 +93,9 @@ func (p *Provider) keepContainer(ctx context.Context, item itemData) bool {
 		return false
 	}
 
-	matches, err := constraints.MatchTags(item.Tags, p.Constraints)
-	if err != nil {
-		logger.Errorf("Error matching constraints expression: %v", err)
-		return false
-	}
-	if !matches {
-		logger.Debugf("Container pruned by constraint expression: %q", p.Constraints)
+	object := constraints.Object{Labels: item.Labels, Tags: item.Tags, Node: item.Node}
+	if !p.constraints.Match(object) {
+		logger.Debugf("Container pruned by constraint expression: %q", p.constraints)
 		return false
 	}
 
@@ -50,6 +50,7 @@ type Provider struct {
 
 	client         *api.Client
 	defaultRuleTpl *template.Template
+	constraints    *constraints.Constraints
 }
 
 // EndpointConfig holds configurations of the endpoint.
@@ -57,7 +58,7 @@ type EndpointConfig struct {
 	Address          string                  `description:"The address of the Consul server" json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty" export:"true"`
 	Scheme           string                  `description:"The URI scheme for the Consul server" json:"scheme,omitempty" toml:"scheme,omitempty" yaml:"scheme,omitempty" export:"true"`
 	DataCenter       string                  `description:"Data center to use. If not provided, the default agent data center is used" json:"datacenter,omitempty" toml:"datacenter,omitempty" yaml:"datacenter,omitempty" export:"true"`
-	Token            string                  `description:"Token is used to provide a per-request ACL token which overrides the agent's default token" json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" export:"true"`
+	Token            string                  `description:"Token is used to provide a per-request ACL token which overrides the agent's default token" json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" export:"true" secret:"true"`
 	TLS              *types.ClientTLS        `description:"Enable TLS support." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
 	HTTPAuth         *EndpointHTTPAuthConfig `description:"Auth info to use for http access" json:"httpAuth,omitempty" toml:"httpAuth,omitempty" yaml:"httpAuth,omitempty" export:"true"`
 	EndpointWaitTime types.Duration          `description:"WaitTime limits how long a Watch will block. If not provided, the agent default values will be used" json:"endpointWaitTime,omitempty" toml:"endpointWaitTime,omitempty" yaml:"endpointWaitTime,omitempty" export:"true"`
@@ -71,7 +72,7 @@ func (c *EndpointConfig) SetDefaults() {
 // EndpointHTTPAuthConfig holds configurations of the authentication.
 type EndpointHTTPAuthConfig struct {
 	Username string `description:"Basic Auth username" json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty" export:"true"`
-	Password string `description:"Basic Auth password" json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" export:"true"`
+	Password string `description:"Basic Auth password" json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" export:"true" secret:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -93,6 +94,12 @@ func (p *Provider) Init() error {
 	}
 
 	p.defaultRuleTpl = defaultRuleTpl
+
+	p.constraints, err = constraints.Compile(p.Constraints)
+	if err != nil {
+		return fmt.Errorf("error while parsing constraints: %w", err)
+	}
+
 	return nil
 }
 
@@ -231,14 +238,10 @@ func (p *Provider) fetchServices(ctx context.Context) ([]string, error) {
 			continue
 		}
 
-		matches, err := constraints.MatchTags(tags, p.Constraints)
-		if err != nil {
-			logger.Errorf("Error matching constraints expression: %v", err)
-			continue
-		}
-
-		if !matches {
-			logger.Debugf("Container pruned by constraint expression: %q", p.Constraints)
+		// The nodes of the service instances are not known yet, the constraints referring to them are matched on each instance.
+		object := constraints.Object{Labels: tagsToNeutralLabels(tags, p.Prefix), Tags: tags}
+		if !p.constraints.UsesNode() && !p.constraints.Match(object) {
+			logger.Debugf("Container pruned by constraint expression: %q", p.constraints)
 			continue
 		}
 
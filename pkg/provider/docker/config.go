@@ -164,13 +164,8 @@ func (p *Provider) keepContainer(ctx context.Context, container dockerData) bool
 		return false
 	}
 
-	matches, err := constraints.MatchLabels(container.Labels, p.Constraints)
-	if err != nil {
-		logger.Errorf("Error matching constraints expression: %v", err)
-		return false
-	}
-	if !matches {
-		logger.Debugf("Container pruned by constraint expression: %q", p.Constraints)
+	if !p.constraints.Match(constraintsObject(container)) {
+		logger.Debugf("Container pruned by constraint expression: %q", p.constraints)
 		return false
 	}
 
@@ -182,6 +177,25 @@ func (p *Provider) keepContainer(ctx context.Context, container dockerData) bool
 	return true
 }
 
+// constraintsObject returns the attributes of the container matched against the constraints,
+// whose namespace is the name of its Swarm stack, or of its Compose project.
+func constraintsObject(container dockerData) constraints.Object {
+	object := constraints.Object{
+		Labels:    container.Labels,
+		Namespace: container.Labels[labelDockerStackNamespace],
+	}
+
+	if object.Namespace == "" {
+		object.Namespace = container.Labels[labelDockerComposeProject]
+	}
+
+	if container.Node != nil {
+		object.Node = container.Node.Name
+	}
+
+	return object
+}
+
 func (p *Provider) addServerTCP(ctx context.Context, container dockerData, loadBalancer *dynamic.TCPServersLoadBalancer) error {
 	if loadBalancer == nil {
 		return errors.New("load-balancer is not defined")
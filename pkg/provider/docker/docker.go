@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -16,6 +17,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/job"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/containous/traefik/v2/pkg/provider/constraints"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/containous/traefik/v2/pkg/version"
@@ -42,7 +44,10 @@ const (
 // DefaultTemplateRule The default template for the default rule.
 const DefaultTemplateRule = "Host(`{{ normalize .Name }}`)"
 
-var _ provider.Provider = (*Provider)(nil)
+var (
+	_ provider.Provider = (*Provider)(nil)
+	_ provider.Resyncer = (*Provider)(nil)
+)
 
 // Provider holds configurations of the provider.
 type Provider struct {
@@ -57,6 +62,8 @@ type Provider struct {
 	Network                 string           `description:"Default Docker network used." json:"network,omitempty" toml:"network,omitempty" yaml:"network,omitempty" export:"true"`
 	SwarmModeRefreshSeconds types.Duration   `description:"Polling interval for swarm mode." json:"swarmModeRefreshSeconds,omitempty" toml:"swarmModeRefreshSeconds,omitempty" yaml:"swarmModeRefreshSeconds,omitempty" export:"true"`
 	defaultRuleTpl          *template.Template
+	constraints             *constraints.Constraints
+	configurationChan       chan<- dynamic.Message
 }
 
 // SetDefaults sets the default values.
@@ -77,6 +84,12 @@ func (p *Provider) Init() error {
 	}
 
 	p.defaultRuleTpl = defaultRuleTpl
+
+	p.constraints, err = constraints.Compile(p.Constraints)
+	if err != nil {
+		return fmt.Errorf("error while parsing constraints: %w", err)
+	}
+
 	return nil
 }
 
@@ -184,6 +197,8 @@ func (p *Provider) getClientOpts() ([]client.Opt, error) {
 
 // Provide allows the docker provider to provide configurations to traefik using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	p.configurationChan = configurationChan
+
 	pool.GoCtx(func(routineCtx context.Context) {
 		ctxLog := log.With(routineCtx, log.Str(log.ProviderName, "docker"))
 		logger := log.FromContext(ctxLog)
@@ -331,6 +346,43 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	return nil
 }
 
+// Resync lists again the containers, or the services in Swarm mode, and sends the resulting configuration.
+func (p *Provider) Resync(ctx context.Context) error {
+	if p.configurationChan == nil {
+		return errors.New("the provider is not started")
+	}
+
+	ctx = log.With(ctx, log.Str(log.ProviderName, "docker"))
+
+	dockerClient, err := p.createClient()
+	if err != nil {
+		return fmt.Errorf("failed to create a client for docker: %w", err)
+	}
+	defer func() { _ = dockerClient.Close() }()
+
+	var dockerDataList []dockerData
+	if p.SwarmMode {
+		dockerDataList, err = p.listServices(ctx, dockerClient)
+	} else {
+		dockerDataList, err = p.listContainers(ctx, dockerClient)
+	}
+	if err != nil {
+		return err
+	}
+
+	message := dynamic.Message{
+		ProviderName:  "docker",
+		Configuration: p.buildConfiguration(ctx, dockerDataList),
+	}
+
+	select {
+	case p.configurationChan <- message:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (p *Provider) listContainers(ctx context.Context, dockerClient client.ContainerAPIClient) ([]dockerData, error) {
 	containerList, err := dockerClient.ContainerList(ctx, dockertypes.ContainerListOptions{})
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 const (
 	labelDockerComposeProject = "com.docker.compose.project"
 	labelDockerComposeService = "com.docker.compose.service"
+	labelDockerStackNamespace = "com.docker.stack.namespace"
 )
 
 // configuration Contains information from the labels that are globals (not related to the dynamic configuration) or specific to the provider.
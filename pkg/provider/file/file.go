@@ -24,7 +24,10 @@ import (
 
 const providerName = "file"
 
-var _ provider.Provider = (*Provider)(nil)
+var (
+	_ provider.Provider = (*Provider)(nil)
+	_ provider.Resyncer = (*Provider)(nil)
+)
 
 // Provider holds configurations of the provider.
 type Provider struct {
@@ -32,6 +35,8 @@ type Provider struct {
 	Watch                     bool   `description:"Watch provider." json:"watch,omitempty" toml:"watch,omitempty" yaml:"watch,omitempty" export:"true"`
 	Filename                  string `description:"Load dynamic configuration from a file." json:"filename,omitempty" toml:"filename,omitempty" yaml:"filename,omitempty" export:"true"`
 	DebugLogGeneratedTemplate bool   `description:"Enable debug logging of generated configuration template." json:"debugLogGeneratedTemplate,omitempty" toml:"debugLogGeneratedTemplate,omitempty" yaml:"debugLogGeneratedTemplate,omitempty" export:"true"`
+
+	configurationChan chan<- dynamic.Message
 }
 
 // SetDefaults sets the default values.
@@ -48,6 +53,8 @@ func (p *Provider) Init() error {
 // Provide allows the file provider to provide configurations to traefik
 // using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	p.configurationChan = configurationChan
+
 	configuration, err := p.BuildConfiguration()
 
 	if err != nil {
@@ -75,6 +82,25 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	return nil
 }
 
+// Resync reads again the configuration files, and sends the resulting configuration.
+func (p *Provider) Resync(ctx context.Context) error {
+	if p.configurationChan == nil {
+		return errors.New("the provider is not started")
+	}
+
+	configuration, err := p.BuildConfiguration()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case p.configurationChan <- dynamic.Message{ProviderName: providerName, Configuration: configuration}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // BuildConfiguration loads configuration either from file or a directory specified by 'Filename'/'Directory'
 // and returns a 'Configuration' object
 func (p *Provider) BuildConfiguration() (*dynamic.Configuration, error) {
@@ -165,6 +165,49 @@ func TestProvideWithWatch(t *testing.T) {
 	}
 }
 
+func TestResync(t *testing.T) {
+	test := ProvideTestCase{
+		filePath:           "./fixtures/toml/simple_file_01.toml",
+		expectedNumRouter:  3,
+		expectedNumService: 6,
+	}
+
+	provider, clean := createProvider(t, test, true)
+	defer clean()
+	provider.Watch = false
+
+	err := provider.Resync(context.Background())
+	assert.Error(t, err)
+
+	configChan := make(chan dynamic.Message, 1)
+	err = provider.Provide(configChan, safe.NewPool(context.Background()))
+	require.NoError(t, err)
+
+	conf := <-configChan
+	assert.Empty(t, conf.Configuration.HTTP.Routers)
+
+	// The change of the file is not watched, but read on resync.
+	err = copyFile(test.filePath, provider.Filename)
+	require.NoError(t, err)
+
+	err = provider.Resync(context.Background())
+	require.NoError(t, err)
+
+	conf = <-configChan
+	assert.Equal(t, "file", conf.ProviderName)
+	assert.Len(t, conf.Configuration.HTTP.Routers, test.expectedNumRouter)
+	assert.Len(t, conf.Configuration.HTTP.Services, test.expectedNumService)
+
+	// The resync is canceled when the configuration cannot be sent.
+	configChan <- dynamic.Message{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = provider.Resync(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
 func getTestCases() []ProvideTestCase {
 	return []ProvideTestCase{
 		{
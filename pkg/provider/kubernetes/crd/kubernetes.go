@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"sort"
 	"strconv"
@@ -18,6 +19,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/job"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/containous/traefik/v2/pkg/provider/constraints"
 	"github.com/containous/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/tls"
@@ -37,17 +39,25 @@ const (
 	providerNamespaceSeparator = "@"
 )
 
+var _ provider.Resyncer = (*Provider)(nil)
+
+// errResync ends the watch of the Kubernetes objects, to start it again.
+var errResync = errors.New("resync")
+
 // Provider holds configurations of the provider.
 type Provider struct {
 	Endpoint               string         `description:"Kubernetes server endpoint (required for external cluster client)." json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty"`
-	Token                  string         `description:"Kubernetes bearer token (not needed for in-cluster client)." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty"`
+	Token                  string         `description:"Kubernetes bearer token (not needed for in-cluster client)." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" secret:"true"`
 	CertAuthFilePath       string         `description:"Kubernetes certificate authority file path (not needed for in-cluster client)." json:"certAuthFilePath,omitempty" toml:"certAuthFilePath,omitempty" yaml:"certAuthFilePath,omitempty"`
 	DisablePassHostHeaders bool           `description:"Kubernetes disable PassHost Headers." json:"disablePassHostHeaders,omitempty" toml:"disablePassHostHeaders,omitempty" yaml:"disablePassHostHeaders,omitempty" export:"true"`
 	Namespaces             []string       `description:"Kubernetes namespaces." json:"namespaces,omitempty" toml:"namespaces,omitempty" yaml:"namespaces,omitempty" export:"true"`
 	LabelSelector          string         `description:"Kubernetes label selector to use." json:"labelSelector,omitempty" toml:"labelSelector,omitempty" yaml:"labelSelector,omitempty" export:"true"`
+	Constraints            string         `description:"Constraints is an expression that Traefik matches against the labels and namespaces of the resources to determine whether to create any route for them." json:"constraints,omitempty" toml:"constraints,omitempty" yaml:"constraints,omitempty" export:"true"`
 	IngressClass           string         `description:"Value of kubernetes.io/ingress.class annotation to watch for." json:"ingressClass,omitempty" toml:"ingressClass,omitempty" yaml:"ingressClass,omitempty" export:"true"`
 	ThrottleDuration       types.Duration `description:"Ingress refresh throttle duration" json:"throttleDuration,omitempty" toml:"throttleDuration,omitempty" yaml:"throttleDuration,omitempty"`
 	lastConfiguration      safe.Safe
+	constraints            *constraints.Constraints
+	resyncChan             chan chan<- error
 }
 
 func (p *Provider) newK8sClient(ctx context.Context, labelSelector string) (*clientWrapper, error) {
@@ -84,6 +94,13 @@ func (p *Provider) newK8sClient(ctx context.Context, labelSelector string) (*cli
 
 // Init the provider.
 func (p *Provider) Init() error {
+	var err error
+	p.constraints, err = constraints.Compile(p.Constraints)
+	if err != nil {
+		return fmt.Errorf("error while parsing constraints: %w", err)
+	}
+
+	p.resyncChan = make(chan chan<- error)
 	return nil
 }
 
@@ -100,8 +117,24 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	}
 
 	pool.GoCtx(func(ctxPool context.Context) {
-		operation := func() error {
-			eventsChan, err := k8sClient.WatchAll(p.Namespaces, ctxPool.Done())
+		// resyncDone is the pending resync, reported once the objects are listed again.
+		var resyncDone chan<- error
+
+		watch := func() error {
+			// The informers are stopped when the watch ends, to list all the objects again on resync.
+			ctxWatch, cancel := context.WithCancel(ctxPool)
+			defer cancel()
+
+			eventsChan, err := k8sClient.WatchAll(p.Namespaces, ctxWatch.Done())
+			if resyncDone != nil {
+				if err == nil {
+					// The configuration is sent even if it did not change.
+					p.lastConfiguration.Set(nil)
+					p.sendConfiguration(ctxLog, k8sClient, configurationChan, nil)
+				}
+				resyncDone <- err
+				resyncDone = nil
+			}
 
 			if err != nil {
 				logger.Errorf("Error watching kubernetes events: %v", err)
@@ -115,7 +148,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 			}
 
 			throttleDuration := time.Duration(p.ThrottleDuration)
-			throttledChan := throttleEvents(ctxLog, throttleDuration, pool, eventsChan)
+			throttledChan := throttleEvents(log.With(ctxWatch, log.Str(log.ProviderName, providerName)), throttleDuration, pool, eventsChan)
 			if throttledChan != nil {
 				eventsChan = throttledChan
 			}
@@ -124,25 +157,14 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 				select {
 				case <-ctxPool.Done():
 					return nil
+				case resyncDone = <-p.resyncChan:
+					logger.Info("Resyncing the Kubernetes objects")
+					return errResync
 				case event := <-eventsChan:
 					// Note that event is the *first* event that came in during this throttling interval -- if we're hitting our throttle, we may have dropped events.
 					// This is fine, because we don't treat different event types differently.
 					// But if we do in the future, we'll need to track more information about the dropped events.
-					conf := p.loadConfigurationFromCRD(ctxLog, k8sClient)
-
-					confHash, err := hashstructure.Hash(conf, nil)
-					switch {
-					case err != nil:
-						logger.Error("Unable to hash the configuration")
-					case p.lastConfiguration.Get() == confHash:
-						logger.Debugf("Skipping Kubernetes event kind %T", event)
-					default:
-						p.lastConfiguration.Set(confHash)
-						configurationChan <- dynamic.Message{
-							ProviderName:  providerName,
-							Configuration: conf,
-						}
-					}
+					p.sendConfiguration(ctxLog, k8sClient, configurationChan, event)
 
 					// If we're throttling,
 					// we sleep here for the throttle duration to enforce that we don't refresh faster than our throttle.
@@ -152,6 +174,14 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 			}
 		}
 
+		operation := func() error {
+			for {
+				if err := watch(); !errors.Is(err, errResync) {
+					return err
+				}
+			}
+		}
+
 		notify := func(err error, time time.Duration) {
 			logger.Errorf("Provider connection error: %v; retrying in %s", err, time)
 		}
@@ -164,6 +194,43 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	return nil
 }
 
+// Resync starts again the watch of the Kubernetes objects, to list them all instead of relying on the informers caches,
+// and returns once the resulting configuration has been sent.
+func (p *Provider) Resync(ctx context.Context) error {
+	done := make(chan error, 1)
+	select {
+	case p.resyncChan <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendConfiguration loads the configuration from the Kubernetes objects, and sends it unless it did not change.
+func (p *Provider) sendConfiguration(ctx context.Context, client Client, configurationChan chan<- dynamic.Message, event interface{}) {
+	conf := p.loadConfigurationFromCRD(ctx, client)
+
+	confHash, err := hashstructure.Hash(conf, nil)
+	switch {
+	case err != nil:
+		log.FromContext(ctx).Error("Unable to hash the configuration")
+	case p.lastConfiguration.Get() == confHash:
+		log.FromContext(ctx).Debugf("Skipping Kubernetes event kind %T", event)
+	default:
+		p.lastConfiguration.Set(confHash)
+		configurationChan <- dynamic.Message{
+			ProviderName:  providerName,
+			Configuration: conf,
+		}
+	}
+}
+
 func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client) *dynamic.Configuration {
 	tlsConfigs := make(map[string]*tls.CertAndStores)
 	conf := &dynamic.Configuration{
@@ -212,27 +279,47 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 		}
 
 		conf.HTTP.Middlewares[id] = &dynamic.Middleware{
-			AddPrefix:         middleware.Spec.AddPrefix,
-			StripPrefix:       middleware.Spec.StripPrefix,
-			StripPrefixRegex:  middleware.Spec.StripPrefixRegex,
-			ReplacePath:       middleware.Spec.ReplacePath,
-			ReplacePathRegex:  middleware.Spec.ReplacePathRegex,
-			Chain:             createChainMiddleware(ctxMid, middleware.Namespace, middleware.Spec.Chain),
-			IPWhiteList:       middleware.Spec.IPWhiteList,
-			Headers:           middleware.Spec.Headers,
-			Errors:            errorPage,
-			RateLimit:         middleware.Spec.RateLimit,
-			RedirectRegex:     middleware.Spec.RedirectRegex,
-			RedirectScheme:    middleware.Spec.RedirectScheme,
-			BasicAuth:         basicAuth,
-			DigestAuth:        digestAuth,
-			ForwardAuth:       forwardAuth,
-			InFlightReq:       middleware.Spec.InFlightReq,
-			Buffering:         middleware.Spec.Buffering,
-			CircuitBreaker:    middleware.Spec.CircuitBreaker,
-			Compress:          middleware.Spec.Compress,
-			PassTLSClientCert: middleware.Spec.PassTLSClientCert,
-			Retry:             middleware.Spec.Retry,
+			AddPrefix:               middleware.Spec.AddPrefix,
+			StripPrefix:             middleware.Spec.StripPrefix,
+			StripPrefixRegex:        middleware.Spec.StripPrefixRegex,
+			ReplacePath:             middleware.Spec.ReplacePath,
+			ReplacePathRegex:        middleware.Spec.ReplacePathRegex,
+			Chain:                   createChainMiddleware(ctxMid, middleware.Namespace, middleware.Spec.Chain),
+			IPWhiteList:             middleware.Spec.IPWhiteList,
+			Headers:                 middleware.Spec.Headers,
+			Errors:                  errorPage,
+			RateLimit:               middleware.Spec.RateLimit,
+			RedirectRegex:           middleware.Spec.RedirectRegex,
+			RedirectScheme:          middleware.Spec.RedirectScheme,
+			BasicAuth:               basicAuth,
+			DigestAuth:              digestAuth,
+			ForwardAuth:             forwardAuth,
+			InFlightReq:             middleware.Spec.InFlightReq,
+			Buffering:               middleware.Spec.Buffering,
+			CircuitBreaker:          middleware.Spec.CircuitBreaker,
+			Compress:                middleware.Spec.Compress,
+			PassTLSClientCert:       middleware.Spec.PassTLSClientCert,
+			Retry:                   middleware.Spec.Retry,
+			EarlyHints:              middleware.Spec.EarlyHints,
+			AltSvc:                  middleware.Spec.AltSvc,
+			Anomaly:                 middleware.Spec.Anomaly,
+			Schedule:                middleware.Spec.Schedule,
+			Honeypot:                middleware.Spec.Honeypot,
+			BotManagement:           middleware.Spec.BotManagement,
+			WellKnown:               middleware.Spec.WellKnown,
+			AdaptiveConcurrency:     middleware.Spec.AdaptiveConcurrency,
+			AdmissionControl:        middleware.Spec.AdmissionControl,
+			APIKey:                  middleware.Spec.APIKey,
+			CSPNonce:                middleware.Spec.CSPNonce,
+			Experiment:              middleware.Spec.Experiment,
+			FaultInjection:          middleware.Spec.FaultInjection,
+			Shadow:                  createShadowMiddleware(ctxMid, middleware.Namespace, middleware.Spec.Shadow),
+			Authorization:           middleware.Spec.Authorization,
+			AWSSigV4:                middleware.Spec.AWSSigV4,
+			OAuth2ClientCredentials: middleware.Spec.OAuth2ClientCredentials,
+			RedirectMap:             middleware.Spec.RedirectMap,
+			BandwidthLimit:          middleware.Spec.BandwidthLimit,
+			MethodOverride:          middleware.Spec.MethodOverride,
 		}
 	}
 
@@ -249,6 +336,16 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 	return conf
 }
 
+// getNativeServiceAddress returns the address of the ClusterIP of the service, load-balanced by Kubernetes,
+// on the port of the service.
+func getNativeServiceAddress(svc corev1.Service, svcPort corev1.ServicePort) (string, error) {
+	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return "", fmt.Errorf("no clusterIP found for the service %s/%s", svc.Namespace, svc.Name)
+	}
+
+	return net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(int(svcPort.Port))), nil
+}
+
 func getServicePort(svc *corev1.Service, port int32) (*corev1.ServicePort, error) {
 	if svc == nil {
 		return nil, errors.New("service is not defined")
@@ -311,6 +408,12 @@ func createForwardAuthMiddleware(k8sClient Client, namespace string, auth *v1alp
 		Address:             auth.Address,
 		TrustForwardHeader:  auth.TrustForwardHeader,
 		AuthResponseHeaders: auth.AuthResponseHeaders,
+		Protocol:            auth.Protocol,
+		ContextExtensions:   auth.ContextExtensions,
+
+		ClientResponseHeaders:   auth.ClientResponseHeaders,
+		RequestHeaderTemplates:  auth.RequestHeaderTemplates,
+		ResponseHeaderTemplates: auth.ResponseHeaderTemplates,
 	}
 
 	if auth.TLS == nil {
@@ -473,8 +576,23 @@ func createChainMiddleware(ctx context.Context, namespace string, chain *v1alpha
 		return nil
 	}
 
+	return &dynamic.Chain{Middlewares: makeMiddlewareIDs(ctx, namespace, chain.Middlewares)}
+}
+
+func createShadowMiddleware(ctx context.Context, namespace string, shadow *v1alpha1.Shadow) *dynamic.Shadow {
+	if shadow == nil {
+		return nil
+	}
+
+	return &dynamic.Shadow{
+		Middlewares: makeMiddlewareIDs(ctx, namespace, shadow.Middlewares),
+		MaxBodySize: shadow.MaxBodySize,
+	}
+}
+
+func makeMiddlewareIDs(ctx context.Context, namespace string, refs []v1alpha1.MiddlewareRef) []string {
 	var mds []string
-	for _, mi := range chain.Middlewares {
+	for _, mi := range refs {
 		if strings.Contains(mi.Name, providerNamespaceSeparator) {
 			if len(mi.Namespace) > 0 {
 				log.FromContext(ctx).
@@ -490,7 +608,7 @@ func createChainMiddleware(ctx context.Context, namespace string, chain *v1alpha
 		}
 		mds = append(mds, makeID(ns, mi.Name))
 	}
-	return &dynamic.Chain{Middlewares: mds}
+	return mds
 }
 
 func buildTLSOptions(ctx context.Context, client Client) map[string]tls.Options {
@@ -740,6 +858,8 @@ func throttleEvents(ctx context.Context, throttleDuration time.Duration, pool *s
 			select {
 			case <-ctxPool.Done():
 				return
+			case <-ctx.Done():
+				return
 			case nextEvent := <-eventsChan:
 				select {
 				case eventsChanBuffered <- nextEvent:
@@ -9,6 +9,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/containous/traefik/v2/pkg/provider/constraints"
 	"github.com/containous/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
 	"github.com/containous/traefik/v2/pkg/tls"
 	corev1 "k8s.io/api/core/v1"
@@ -36,6 +37,11 @@ func (p *Provider) loadIngressRouteConfiguration(ctx context.Context, client Cli
 			continue
 		}
 
+		if !p.constraints.Match(constraints.Object{Labels: ingressRoute.Labels, Namespace: ingressRoute.Namespace}) {
+			logger.Debugf("IngressRoute pruned by constraint expression: %q", p.constraints)
+			continue
+		}
+
 		err := getTLSHTTP(ctx, ingressRoute, client, tlsConfigs)
 		if err != nil {
 			logger.Errorf("Error configuring TLS: %v", err)
@@ -118,17 +124,19 @@ func (p *Provider) loadIngressRouteConfiguration(ctx context.Context, client Cli
 			}
 
 			conf.Routers[normalized] = &dynamic.Router{
-				Middlewares: mds,
-				Priority:    route.Priority,
-				EntryPoints: ingressRoute.Spec.EntryPoints,
-				Rule:        route.Match,
-				Service:     serviceName,
+				Middlewares:       mds,
+				Priority:          route.Priority,
+				EntryPoints:       ingressRoute.Spec.EntryPoints,
+				Rule:              route.Match,
+				Service:           serviceName,
+				PathNormalization: route.PathNormalization,
 			}
 
 			if ingressRoute.Spec.TLS != nil {
 				tlsConf := &dynamic.RouterTLSConfig{
-					CertResolver: ingressRoute.Spec.TLS.CertResolver,
-					Domains:      ingressRoute.Spec.TLS.Domains,
+					CertResolver:          ingressRoute.Spec.TLS.CertResolver,
+					Domains:               ingressRoute.Spec.TLS.Domains,
+					FallbackCertResolvers: ingressRoute.Spec.TLS.FallbackCertResolvers,
 				}
 
 				if ingressRoute.Spec.TLS.Options != nil && len(ingressRoute.Spec.TLS.Options.Name) > 0 {
@@ -311,6 +319,22 @@ func (c configBuilder) loadServers(fallbackNamespace string, svc v1alpha1.LoadBa
 		}), nil
 	}
 
+	if svc.NativeLB {
+		address, err := getNativeServiceAddress(*service, *svcPort)
+		if err != nil {
+			return nil, err
+		}
+
+		protocol, err := parseServiceProtocol(svc.Scheme, svcPort.Name, svcPort.Port)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(servers, dynamic.Server{
+			URL: fmt.Sprintf("%s://%s", protocol, address),
+		}), nil
+	}
+
 	endpoints, endpointsExists, endpointsErr := c.client.GetEndpoints(namespace, sanitizedName)
 	if endpointsErr != nil {
 		return nil, endpointsErr
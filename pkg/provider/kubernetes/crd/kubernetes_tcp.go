@@ -8,6 +8,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider/constraints"
 	"github.com/containous/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
 	"github.com/containous/traefik/v2/pkg/tls"
 	corev1 "k8s.io/api/core/v1"
@@ -26,6 +27,11 @@ func (p *Provider) loadIngressRouteTCPConfiguration(ctx context.Context, client
 			continue
 		}
 
+		if !p.constraints.Match(constraints.Object{Labels: ingressRouteTCP.Labels, Namespace: ingressRouteTCP.Namespace}) {
+			logger.Debugf("IngressRouteTCP pruned by constraint expression: %q", p.constraints)
+			continue
+		}
+
 		if ingressRouteTCP.Spec.TLS != nil && !ingressRouteTCP.Spec.TLS.Passthrough {
 			err := getTLSTCP(ctx, ingressRouteTCP, client, tlsConfigs)
 			if err != nil {
@@ -172,6 +178,13 @@ func loadTCPServers(client Client, namespace string, svc v1alpha1.ServiceTCP) ([
 		servers = append(servers, dynamic.TCPServer{
 			Address: fmt.Sprintf("%s:%d", service.Spec.ExternalName, svcPort.Port),
 		})
+	} else if svc.NativeLB {
+		address, err := getNativeServiceAddress(*service, *svcPort)
+		if err != nil {
+			return nil, err
+		}
+
+		servers = append(servers, dynamic.TCPServer{Address: address})
 	} else {
 		endpoints, endpointsExists, endpointsErr := client.GetEndpoints(namespace, svc.Name)
 		if endpointsErr != nil {
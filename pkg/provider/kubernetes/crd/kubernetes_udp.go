@@ -7,6 +7,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider/constraints"
 	"github.com/containous/traefik/v2/pkg/provider/kubernetes/crd/traefik/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -24,6 +25,11 @@ func (p *Provider) loadIngressRouteUDPConfiguration(ctx context.Context, client
 			continue
 		}
 
+		if !p.constraints.Match(constraints.Object{Labels: ingressRouteUDP.Labels, Namespace: ingressRouteUDP.Namespace}) {
+			logger.Debugf("IngressRouteUDP pruned by constraint expression: %q", p.constraints)
+			continue
+		}
+
 		ingressName := ingressRouteUDP.Name
 		if len(ingressName) == 0 {
 			ingressName = ingressRouteUDP.GenerateName
@@ -122,6 +128,13 @@ func loadUDPServers(client Client, namespace string, svc v1alpha1.ServiceUDP) ([
 		servers = append(servers, dynamic.UDPServer{
 			Address: fmt.Sprintf("%s:%d", service.Spec.ExternalName, portSpec.Port),
 		})
+	} else if svc.NativeLB {
+		address, err := getNativeServiceAddress(*service, *portSpec)
+		if err != nil {
+			return nil, err
+		}
+
+		servers = append(servers, dynamic.UDPServer{Address: address})
 	} else {
 		endpoints, endpointsExists, endpointsErr := client.GetEndpoints(namespace, svc.Name)
 		if endpointsErr != nil {
@@ -15,21 +15,22 @@ type IngressRouteSpec struct {
 
 // Route contains the set of routes.
 type Route struct {
-	Match       string          `json:"match"`
-	Kind        string          `json:"kind"`
-	Priority    int             `json:"priority"`
-	Services    []Service       `json:"services,omitempty"`
-	Middlewares []MiddlewareRef `json:"middlewares"`
+	Match             string                     `json:"match"`
+	Kind              string                     `json:"kind"`
+	Priority          int                        `json:"priority"`
+	Services          []Service                  `json:"services,omitempty"`
+	Middlewares       []MiddlewareRef            `json:"middlewares"`
+	PathNormalization *dynamic.PathNormalization `json:"pathNormalization,omitempty"`
 }
 
 // TLS contains the TLS certificates configuration of the routes.
 // To enable Let's Encrypt, use an empty TLS struct,
 // e.g. in YAML:
 //
-//	 tls: {} # inline format
+//	tls: {} # inline format
 //
-//	 tls:
-//	   secretName: # block format
+//	tls:
+//	  secretName: # block format
 type TLS struct {
 	// SecretName is the name of the referenced Kubernetes Secret to specify the
 	// certificate details.
@@ -40,6 +41,8 @@ type TLS struct {
 	Store        *TLSStoreRef   `json:"store,omitempty"`
 	CertResolver string         `json:"certResolver,omitempty"`
 	Domains      []types.Domain `json:"domains,omitempty"`
+	// FallbackCertResolvers are the resolvers obtaining the certificates, in priority order, when the certResolver fails.
+	FallbackCertResolvers []string `json:"fallbackCertResolvers,omitempty"`
 }
 
 // TLSOptionRef is a ref to the TLSOption resources.
@@ -72,6 +75,9 @@ type LoadBalancerSpec struct {
 	Strategy           string                      `json:"strategy,omitempty"`
 	PassHostHeader     *bool                       `json:"passHostHeader,omitempty"`
 	ResponseForwarding *dynamic.ResponseForwarding `json:"responseForwarding,omitempty"`
+	// NativeLB forwards to the ClusterIP of the Kubernetes Service, load-balanced by Kubernetes,
+	// instead of load-balancing across the endpoints of the Service.
+	NativeLB bool `json:"nativeLB,omitempty"`
 
 	// Weight should only be specified when Name references a TraefikService object
 	// (and to be precise, one that embeds a Weighted Round Robin).
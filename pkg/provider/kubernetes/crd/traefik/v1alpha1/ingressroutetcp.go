@@ -22,10 +22,10 @@ type RouteTCP struct {
 // To enable Let's Encrypt, use an empty TLS struct,
 // e.g. in YAML:
 //
-//	 tls: {} # inline format
+//	tls: {} # inline format
 //
-//	 tls:
-//	   secretName: # block format
+//	tls:
+//	  secretName: # block format
 type TLSTCP struct {
 	// SecretName is the name of the referenced Kubernetes Secret to specify the
 	// certificate details.
@@ -58,6 +58,8 @@ type ServiceTCP struct {
 	Port             int32  `json:"port"`
 	Weight           *int   `json:"weight,omitempty"`
 	TerminationDelay *int   `json:"terminationDelay,omitempty"`
+	// NativeLB forwards to the ClusterIP of the Kubernetes Service, instead of load-balancing across its endpoints.
+	NativeLB bool `json:"nativeLB,omitempty"`
 }
 
 // +genclient
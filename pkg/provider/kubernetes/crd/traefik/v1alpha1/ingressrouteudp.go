@@ -27,6 +27,8 @@ type ServiceUDP struct {
 	Namespace string `json:"namespace"`
 	Port      int32  `json:"port"`
 	Weight    *int   `json:"weight,omitempty"`
+	// NativeLB forwards to the ClusterIP of the Kubernetes Service, instead of load-balancing across its endpoints.
+	NativeLB bool `json:"nativeLB,omitempty"`
 }
 
 // +genclient
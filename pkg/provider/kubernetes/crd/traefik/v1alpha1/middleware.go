@@ -20,28 +20,48 @@ type Middleware struct {
 
 // MiddlewareSpec holds the Middleware configuration.
 type MiddlewareSpec struct {
-	AddPrefix         *dynamic.AddPrefix         `json:"addPrefix,omitempty"`
-	StripPrefix       *dynamic.StripPrefix       `json:"stripPrefix,omitempty"`
-	StripPrefixRegex  *dynamic.StripPrefixRegex  `json:"stripPrefixRegex,omitempty"`
-	ReplacePath       *dynamic.ReplacePath       `json:"replacePath,omitempty"`
-	ReplacePathRegex  *dynamic.ReplacePathRegex  `json:"replacePathRegex,omitempty"`
-	Chain             *Chain                     `json:"chain,omitempty"`
-	IPWhiteList       *dynamic.IPWhiteList       `json:"ipWhiteList,omitempty"`
-	Headers           *dynamic.Headers           `json:"headers,omitempty"`
-	Errors            *ErrorPage                 `json:"errors,omitempty"`
-	RateLimit         *dynamic.RateLimit         `json:"rateLimit,omitempty"`
-	RedirectRegex     *dynamic.RedirectRegex     `json:"redirectRegex,omitempty"`
-	RedirectScheme    *dynamic.RedirectScheme    `json:"redirectScheme,omitempty"`
-	BasicAuth         *BasicAuth                 `json:"basicAuth,omitempty"`
-	DigestAuth        *DigestAuth                `json:"digestAuth,omitempty"`
-	ForwardAuth       *ForwardAuth               `json:"forwardAuth,omitempty"`
-	InFlightReq       *dynamic.InFlightReq       `json:"inFlightReq,omitempty"`
-	Buffering         *dynamic.Buffering         `json:"buffering,omitempty"`
-	CircuitBreaker    *dynamic.CircuitBreaker    `json:"circuitBreaker,omitempty"`
-	Compress          *dynamic.Compress          `json:"compress,omitempty"`
-	PassTLSClientCert *dynamic.PassTLSClientCert `json:"passTLSClientCert,omitempty"`
-	Retry             *dynamic.Retry             `json:"retry,omitempty"`
-	ContentType       *dynamic.ContentType       `json:"contentType,omitempty"`
+	AddPrefix               *dynamic.AddPrefix               `json:"addPrefix,omitempty"`
+	StripPrefix             *dynamic.StripPrefix             `json:"stripPrefix,omitempty"`
+	StripPrefixRegex        *dynamic.StripPrefixRegex        `json:"stripPrefixRegex,omitempty"`
+	ReplacePath             *dynamic.ReplacePath             `json:"replacePath,omitempty"`
+	ReplacePathRegex        *dynamic.ReplacePathRegex        `json:"replacePathRegex,omitempty"`
+	Chain                   *Chain                           `json:"chain,omitempty"`
+	IPWhiteList             *dynamic.IPWhiteList             `json:"ipWhiteList,omitempty"`
+	Headers                 *dynamic.Headers                 `json:"headers,omitempty"`
+	Errors                  *ErrorPage                       `json:"errors,omitempty"`
+	RateLimit               *dynamic.RateLimit               `json:"rateLimit,omitempty"`
+	RedirectRegex           *dynamic.RedirectRegex           `json:"redirectRegex,omitempty"`
+	RedirectScheme          *dynamic.RedirectScheme          `json:"redirectScheme,omitempty"`
+	BasicAuth               *BasicAuth                       `json:"basicAuth,omitempty"`
+	DigestAuth              *DigestAuth                      `json:"digestAuth,omitempty"`
+	ForwardAuth             *ForwardAuth                     `json:"forwardAuth,omitempty"`
+	InFlightReq             *dynamic.InFlightReq             `json:"inFlightReq,omitempty"`
+	Buffering               *dynamic.Buffering               `json:"buffering,omitempty"`
+	CircuitBreaker          *dynamic.CircuitBreaker          `json:"circuitBreaker,omitempty"`
+	Compress                *dynamic.Compress                `json:"compress,omitempty"`
+	PassTLSClientCert       *dynamic.PassTLSClientCert       `json:"passTLSClientCert,omitempty"`
+	Retry                   *dynamic.Retry                   `json:"retry,omitempty"`
+	ContentType             *dynamic.ContentType             `json:"contentType,omitempty"`
+	EarlyHints              *dynamic.EarlyHints              `json:"earlyHints,omitempty"`
+	AltSvc                  *dynamic.AltSvc                  `json:"altSvc,omitempty"`
+	Anomaly                 *dynamic.Anomaly                 `json:"anomaly,omitempty"`
+	Schedule                *dynamic.Schedule                `json:"schedule,omitempty"`
+	Honeypot                *dynamic.Honeypot                `json:"honeypot,omitempty"`
+	BotManagement           *dynamic.BotManagement           `json:"botManagement,omitempty"`
+	WellKnown               *dynamic.WellKnown               `json:"wellKnown,omitempty"`
+	AdaptiveConcurrency     *dynamic.AdaptiveConcurrency     `json:"adaptiveConcurrency,omitempty"`
+	AdmissionControl        *dynamic.AdmissionControl        `json:"admissionControl,omitempty"`
+	APIKey                  *dynamic.APIKey                  `json:"apiKey,omitempty"`
+	CSPNonce                *dynamic.CSPNonce                `json:"cspNonce,omitempty"`
+	Experiment              *dynamic.Experiment              `json:"experiment,omitempty"`
+	FaultInjection          *dynamic.FaultInjection          `json:"faultInjection,omitempty"`
+	Shadow                  *Shadow                          `json:"shadow,omitempty"`
+	Authorization           *dynamic.Authorization           `json:"authorization,omitempty"`
+	AWSSigV4                *dynamic.AWSSigV4                `json:"awsSigV4,omitempty"`
+	OAuth2ClientCredentials *dynamic.OAuth2ClientCredentials `json:"oauth2ClientCredentials,omitempty"`
+	RedirectMap             *dynamic.RedirectMap             `json:"redirectMap,omitempty"`
+	BandwidthLimit          *dynamic.BandwidthLimit          `json:"bandwidthLimit,omitempty"`
+	MethodOverride          *dynamic.MethodOverride          `json:"methodOverride,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -62,6 +82,14 @@ type Chain struct {
 
 // +k8s:deepcopy-gen=true
 
+// Shadow holds the shadow evaluation configuration.
+type Shadow struct {
+	Middlewares []MiddlewareRef `json:"middlewares,omitempty"`
+	MaxBodySize int64           `json:"maxBodySize,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // BasicAuth holds the HTTP basic authentication configuration.
 type BasicAuth struct {
 	Secret       string `json:"secret,omitempty"`
@@ -84,10 +112,16 @@ type DigestAuth struct {
 
 // ForwardAuth holds the http forward authentication configuration.
 type ForwardAuth struct {
-	Address             string     `json:"address,omitempty"`
-	TrustForwardHeader  bool       `json:"trustForwardHeader,omitempty"`
-	AuthResponseHeaders []string   `json:"authResponseHeaders,omitempty"`
-	TLS                 *ClientTLS `json:"tls,omitempty"`
+	Address             string            `json:"address,omitempty"`
+	TrustForwardHeader  bool              `json:"trustForwardHeader,omitempty"`
+	AuthResponseHeaders []string          `json:"authResponseHeaders,omitempty"`
+	TLS                 *ClientTLS        `json:"tls,omitempty"`
+	Protocol            string            `json:"protocol,omitempty"`
+	ContextExtensions   map[string]string `json:"contextExtensions,omitempty"`
+
+	ClientResponseHeaders   []string          `json:"clientResponseHeaders,omitempty"`
+	RequestHeaderTemplates  map[string]string `json:"requestHeaderTemplates,omitempty"`
+	ResponseHeaderTemplates map[string]string `json:"responseHeaderTemplates,omitempty"`
 }
 
 // ClientTLS holds TLS specific configurations as client.
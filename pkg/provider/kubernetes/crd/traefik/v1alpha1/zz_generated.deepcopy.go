@@ -175,6 +175,32 @@ func (in *ForwardAuth) DeepCopyInto(out *ForwardAuth) {
 		*out = new(ClientTLS)
 		**out = **in
 	}
+	if in.ContextExtensions != nil {
+		in, out := &in.ContextExtensions, &out.ContextExtensions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ClientResponseHeaders != nil {
+		in, out := &in.ClientResponseHeaders, &out.ClientResponseHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequestHeaderTemplates != nil {
+		in, out := &in.RequestHeaderTemplates, &out.RequestHeaderTemplates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResponseHeaderTemplates != nil {
+		in, out := &in.ResponseHeaderTemplates, &out.ResponseHeaderTemplates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -687,6 +713,106 @@ func (in *MiddlewareSpec) DeepCopyInto(out *MiddlewareSpec) {
 		*out = new(dynamic.ContentType)
 		**out = **in
 	}
+	if in.EarlyHints != nil {
+		in, out := &in.EarlyHints, &out.EarlyHints
+		*out = new(dynamic.EarlyHints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AltSvc != nil {
+		in, out := &in.AltSvc, &out.AltSvc
+		*out = new(dynamic.AltSvc)
+		**out = **in
+	}
+	if in.Anomaly != nil {
+		in, out := &in.Anomaly, &out.Anomaly
+		*out = new(dynamic.Anomaly)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(dynamic.Schedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Honeypot != nil {
+		in, out := &in.Honeypot, &out.Honeypot
+		*out = new(dynamic.Honeypot)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BotManagement != nil {
+		in, out := &in.BotManagement, &out.BotManagement
+		*out = new(dynamic.BotManagement)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WellKnown != nil {
+		in, out := &in.WellKnown, &out.WellKnown
+		*out = new(dynamic.WellKnown)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdaptiveConcurrency != nil {
+		in, out := &in.AdaptiveConcurrency, &out.AdaptiveConcurrency
+		*out = new(dynamic.AdaptiveConcurrency)
+		**out = **in
+	}
+	if in.AdmissionControl != nil {
+		in, out := &in.AdmissionControl, &out.AdmissionControl
+		*out = new(dynamic.AdmissionControl)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APIKey != nil {
+		in, out := &in.APIKey, &out.APIKey
+		*out = new(dynamic.APIKey)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CSPNonce != nil {
+		in, out := &in.CSPNonce, &out.CSPNonce
+		*out = new(dynamic.CSPNonce)
+		**out = **in
+	}
+	if in.Experiment != nil {
+		in, out := &in.Experiment, &out.Experiment
+		*out = new(dynamic.Experiment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FaultInjection != nil {
+		in, out := &in.FaultInjection, &out.FaultInjection
+		*out = new(dynamic.FaultInjection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shadow != nil {
+		in, out := &in.Shadow, &out.Shadow
+		*out = new(Shadow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(dynamic.Authorization)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSSigV4 != nil {
+		in, out := &in.AWSSigV4, &out.AWSSigV4
+		*out = new(dynamic.AWSSigV4)
+		**out = **in
+	}
+	if in.OAuth2ClientCredentials != nil {
+		in, out := &in.OAuth2ClientCredentials, &out.OAuth2ClientCredentials
+		*out = new(dynamic.OAuth2ClientCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RedirectMap != nil {
+		in, out := &in.RedirectMap, &out.RedirectMap
+		*out = new(dynamic.RedirectMap)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BandwidthLimit != nil {
+		in, out := &in.BandwidthLimit, &out.BandwidthLimit
+		*out = new(dynamic.BandwidthLimit)
+		**out = **in
+	}
+	if in.MethodOverride != nil {
+		in, out := &in.MethodOverride, &out.MethodOverride
+		*out = new(dynamic.MethodOverride)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -761,6 +887,11 @@ func (in *Route) DeepCopyInto(out *Route) {
 		*out = make([]MiddlewareRef, len(*in))
 		copy(*out, *in)
 	}
+	if in.PathNormalization != nil {
+		in, out := &in.PathNormalization, &out.PathNormalization
+		*out = new(dynamic.PathNormalization)
+		**out = **in
+	}
 	return
 }
 
@@ -910,6 +1041,27 @@ func (in *ServiceUDP) DeepCopy() *ServiceUDP {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Shadow) DeepCopyInto(out *Shadow) {
+	*out = *in
+	if in.Middlewares != nil {
+		in, out := &in.Middlewares, &out.Middlewares
+		*out = make([]MiddlewareRef, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Shadow.
+func (in *Shadow) DeepCopy() *Shadow {
+	if in == nil {
+		return nil
+	}
+	out := new(Shadow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
@@ -930,6 +1082,11 @@ func (in *TLS) DeepCopyInto(out *TLS) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.FallbackCertResolvers != nil {
+		in, out := &in.FallbackCertResolvers, &out.FallbackCertResolvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/label"
+	"github.com/containous/traefik/v2/pkg/config/schema"
 )
 
 const (
@@ -42,6 +43,8 @@ type ServiceIng struct {
 	ServersScheme  string          `json:"serversScheme,omitempty"`
 	PassHostHeader *bool           `json:"passHostHeader"`
 	Sticky         *dynamic.Sticky `json:"sticky,omitempty" label:"allowEmpty"`
+	// NativeLB forwards to the ClusterIP of the service, instead of load-balancing across its endpoints.
+	NativeLB bool `json:"nativeLB,omitempty"`
 }
 
 // SetDefaults sets the default values.
@@ -49,6 +52,19 @@ func (s *ServiceIng) SetDefaults() {
 	s.PassHostHeader = func(v bool) *bool { return &v }(true)
 }
 
+// AnnotationsSchema returns the JSON Schema of the annotations of the Ingresses and of their Services.
+func AnnotationsSchema() *schema.Schema {
+	element := &struct {
+		RouterConfig
+		ServiceConfig
+	}{}
+
+	return schema.GenerateLabels(element, "Traefik Kubernetes Ingress annotations", schema.LabelOpts{
+		Prefix:   annotationsPrefix,
+		DotIndex: true,
+	})
+}
+
 func parseRouterConfig(annotations map[string]string) (*RouterConfig, error) {
 	labels := convertAnnotations(annotations)
 	if len(labels) == 0 {
@@ -1,6 +1,7 @@
 package ingress
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
@@ -104,6 +105,7 @@ func Test_parseServiceConfig(t *testing.T) {
 				"ingress.kubernetes.io/foo":                                    "bar",
 				"traefik.ingress.kubernetes.io/foo":                            "bar",
 				"traefik.ingress.kubernetes.io/service.serversscheme":          "protocol",
+				"traefik.ingress.kubernetes.io/service.nativelb":               "true",
 				"traefik.ingress.kubernetes.io/service.passhostheader":         "true",
 				"traefik.ingress.kubernetes.io/service.sticky":                 "true",
 				"traefik.ingress.kubernetes.io/service.sticky.cookie.httponly": "true",
@@ -123,6 +125,7 @@ func Test_parseServiceConfig(t *testing.T) {
 					},
 					ServersScheme:  "protocol",
 					PassHostHeader: Bool(true),
+					NativeLB:       true,
 				},
 			},
 		},
@@ -243,3 +246,33 @@ func Test_convertAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestAnnotationsSchema(t *testing.T) {
+	schema := AnnotationsSchema()
+
+	annotations := []string{
+		"traefik.ingress.kubernetes.io/router.pathmatcher",
+		"traefik.ingress.kubernetes.io/router.entrypoints",
+		"traefik.ingress.kubernetes.io/router.priority",
+		"traefik.ingress.kubernetes.io/router.tls",
+		"traefik.ingress.kubernetes.io/router.tls.certresolver",
+		"traefik.ingress.kubernetes.io/router.tls.domains.0.main",
+		"traefik.ingress.kubernetes.io/router.tls.domains.1.sans",
+		"traefik.ingress.kubernetes.io/service.serversscheme",
+		"traefik.ingress.kubernetes.io/service.passhostheader",
+		"traefik.ingress.kubernetes.io/service.sticky.cookie.name",
+		"traefik.ingress.kubernetes.io/service.nativelb",
+	}
+
+	for _, annotation := range annotations {
+		var matched bool
+		for pattern := range schema.PatternProperties {
+			if regexp.MustCompile(pattern).MatchString(annotation) {
+				matched = true
+				break
+			}
+		}
+
+		assert.True(t, matched, annotation)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net"
 	"os"
 	"sort"
 	"strconv"
@@ -16,6 +17,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/job"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/containous/traefik/v2/pkg/provider/constraints"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/tls"
 	"github.com/containous/traefik/v2/pkg/types"
@@ -32,18 +34,26 @@ const (
 	defaultPathMatcher               = "PathPrefix"
 )
 
+var _ provider.Resyncer = (*Provider)(nil)
+
+// errResync ends the watch of the Kubernetes objects, to start it again.
+var errResync = errors.New("resync")
+
 // Provider holds configurations of the provider.
 type Provider struct {
 	Endpoint               string           `description:"Kubernetes server endpoint (required for external cluster client)." json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty"`
-	Token                  string           `description:"Kubernetes bearer token (not needed for in-cluster client)." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty"`
+	Token                  string           `description:"Kubernetes bearer token (not needed for in-cluster client)." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" secret:"true"`
 	CertAuthFilePath       string           `description:"Kubernetes certificate authority file path (not needed for in-cluster client)." json:"certAuthFilePath,omitempty" toml:"certAuthFilePath,omitempty" yaml:"certAuthFilePath,omitempty"`
 	DisablePassHostHeaders bool             `description:"Kubernetes disable PassHost Headers." json:"disablePassHostHeaders,omitempty" toml:"disablePassHostHeaders,omitempty" yaml:"disablePassHostHeaders,omitempty" export:"true"`
 	Namespaces             []string         `description:"Kubernetes namespaces." json:"namespaces,omitempty" toml:"namespaces,omitempty" yaml:"namespaces,omitempty" export:"true"`
 	LabelSelector          string           `description:"Kubernetes Ingress label selector to use." json:"labelSelector,omitempty" toml:"labelSelector,omitempty" yaml:"labelSelector,omitempty" export:"true"`
+	Constraints            string           `description:"Constraints is an expression that Traefik matches against the labels and namespaces of the resources to determine whether to create any route for them." json:"constraints,omitempty" toml:"constraints,omitempty" yaml:"constraints,omitempty" export:"true"`
 	IngressClass           string           `description:"Value of kubernetes.io/ingress.class annotation to watch for." json:"ingressClass,omitempty" toml:"ingressClass,omitempty" yaml:"ingressClass,omitempty" export:"true"`
 	IngressEndpoint        *EndpointIngress `description:"Kubernetes Ingress Endpoint." json:"ingressEndpoint,omitempty" toml:"ingressEndpoint,omitempty" yaml:"ingressEndpoint,omitempty"`
 	ThrottleDuration       types.Duration   `description:"Ingress refresh throttle duration" json:"throttleDuration,omitempty" toml:"throttleDuration,omitempty" yaml:"throttleDuration,omitempty"`
 	lastConfiguration      safe.Safe
+	constraints            *constraints.Constraints
+	resyncChan             chan chan<- error
 }
 
 // EndpointIngress holds the endpoint information for the Kubernetes provider
@@ -90,6 +100,13 @@ func (p *Provider) newK8sClient(ctx context.Context, ingressLabelSelector string
 
 // Init the provider.
 func (p *Provider) Init() error {
+	var err error
+	p.constraints, err = constraints.Compile(p.Constraints)
+	if err != nil {
+		return fmt.Errorf("error while parsing constraints: %w", err)
+	}
+
+	p.resyncChan = make(chan chan<- error)
 	return nil
 }
 
@@ -106,8 +123,25 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	}
 
 	pool.GoCtx(func(ctxPool context.Context) {
-		operation := func() error {
-			eventsChan, err := k8sClient.WatchAll(p.Namespaces, ctxPool.Done())
+		// resyncDone is the pending resync, reported once the objects are listed again.
+		var resyncDone chan<- error
+
+		watch := func() error {
+			// The informers are stopped when the watch ends, to list all the objects again on resync.
+			ctxWatch, cancel := context.WithCancel(ctxPool)
+			defer cancel()
+
+			eventsChan, err := k8sClient.WatchAll(p.Namespaces, ctxWatch.Done())
+			if resyncDone != nil {
+				if err == nil {
+					// The configuration is sent even if it did not change.
+					p.lastConfiguration.Set(nil)
+					p.sendConfiguration(ctxLog, k8sClient, configurationChan, nil)
+				}
+				resyncDone <- err
+				resyncDone = nil
+			}
+
 			if err != nil {
 				logger.Errorf("Error watching kubernetes events: %v", err)
 				timer := time.NewTimer(1 * time.Second)
@@ -120,7 +154,7 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 			}
 
 			throttleDuration := time.Duration(p.ThrottleDuration)
-			throttledChan := throttleEvents(ctxLog, throttleDuration, pool, eventsChan)
+			throttledChan := throttleEvents(log.With(ctxWatch, log.Str(log.ProviderName, "kubernetes")), throttleDuration, pool, eventsChan)
 			if throttledChan != nil {
 				eventsChan = throttledChan
 			}
@@ -129,27 +163,16 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 				select {
 				case <-ctxPool.Done():
 					return nil
+				case resyncDone = <-p.resyncChan:
+					logger.Info("Resyncing the Kubernetes objects")
+					return errResync
 				case event := <-eventsChan:
 					// Note that event is the *first* event that came in during this
 					// throttling interval -- if we're hitting our throttle, we may have
 					// dropped events. This is fine, because we don't treat different
 					// event types differently. But if we do in the future, we'll need to
 					// track more information about the dropped events.
-					conf := p.loadConfigurationFromIngresses(ctxLog, k8sClient)
-
-					confHash, err := hashstructure.Hash(conf, nil)
-					switch {
-					case err != nil:
-						logger.Error("Unable to hash the configuration")
-					case p.lastConfiguration.Get() == confHash:
-						logger.Debugf("Skipping Kubernetes event kind %T", event)
-					default:
-						p.lastConfiguration.Set(confHash)
-						configurationChan <- dynamic.Message{
-							ProviderName:  "kubernetes",
-							Configuration: conf,
-						}
-					}
+					p.sendConfiguration(ctxLog, k8sClient, configurationChan, event)
 
 					// If we're throttling, we sleep here for the throttle duration to
 					// enforce that we don't refresh faster than our throttle. time.Sleep
@@ -159,6 +182,14 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 			}
 		}
 
+		operation := func() error {
+			for {
+				if err := watch(); !errors.Is(err, errResync) {
+					return err
+				}
+			}
+		}
+
 		notify := func(err error, time time.Duration) {
 			logger.Errorf("Provider connection error: %s; retrying in %s", err, time)
 		}
@@ -172,6 +203,43 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	return nil
 }
 
+// Resync starts again the watch of the Kubernetes objects, to list them all instead of relying on the informers caches,
+// and returns once the resulting configuration has been sent.
+func (p *Provider) Resync(ctx context.Context) error {
+	done := make(chan error, 1)
+	select {
+	case p.resyncChan <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendConfiguration loads the configuration from the Kubernetes objects, and sends it unless it did not change.
+func (p *Provider) sendConfiguration(ctx context.Context, client Client, configurationChan chan<- dynamic.Message, event interface{}) {
+	conf := p.loadConfigurationFromIngresses(ctx, client)
+
+	confHash, err := hashstructure.Hash(conf, nil)
+	switch {
+	case err != nil:
+		log.FromContext(ctx).Error("Unable to hash the configuration")
+	case p.lastConfiguration.Get() == confHash:
+		log.FromContext(ctx).Debugf("Skipping Kubernetes event kind %T", event)
+	default:
+		p.lastConfiguration.Set(confHash)
+		configurationChan <- dynamic.Message{
+			ProviderName:  "kubernetes",
+			Configuration: conf,
+		}
+	}
+}
+
 func (p *Provider) loadConfigurationFromIngresses(ctx context.Context, client Client) *dynamic.Configuration {
 	conf := &dynamic.Configuration{
 		HTTP: &dynamic.HTTPConfiguration{
@@ -192,6 +260,11 @@ func (p *Provider) loadConfigurationFromIngresses(ctx context.Context, client Cl
 			continue
 		}
 
+		if !p.constraints.Match(constraints.Object{Labels: ingress.Labels, Namespace: ingress.Namespace}) {
+			log.FromContext(ctx).Debugf("Ingress pruned by constraint expression: %q", p.constraints)
+			continue
+		}
+
 		rtConfig, err := parseRouterConfig(ingress.Annotations)
 		if err != nil {
 			log.FromContext(ctx).Errorf("Failed to parse annotations: %v", err)
@@ -475,6 +548,21 @@ func loadService(client Client, namespace string, backend v1beta1.IngressBackend
 		return svc, nil
 	}
 
+	if svcConfig != nil && svcConfig.Service != nil && svcConfig.Service.NativeLB {
+		address, err := getNativeServiceAddress(*service, portSpec)
+		if err != nil {
+			return nil, err
+		}
+
+		protocol := getProtocol(portSpec, portSpec.Name, svcConfig)
+
+		svc.LoadBalancer.Servers = []dynamic.Server{
+			{URL: fmt.Sprintf("%s://%s", protocol, address)},
+		}
+
+		return svc, nil
+	}
+
 	endpoints, endpointsExists, endpointsErr := client.GetEndpoints(namespace, backend.ServiceName)
 	if endpointsErr != nil {
 		return nil, endpointsErr
@@ -513,6 +601,16 @@ func loadService(client Client, namespace string, backend v1beta1.IngressBackend
 	return svc, nil
 }
 
+// getNativeServiceAddress returns the address of the ClusterIP of the service, load-balanced by Kubernetes,
+// on the port of the service.
+func getNativeServiceAddress(service corev1.Service, portSpec corev1.ServicePort) (string, error) {
+	if service.Spec.ClusterIP == "" || service.Spec.ClusterIP == corev1.ClusterIPNone {
+		return "", fmt.Errorf("no clusterIP found for the service %s/%s", service.Namespace, service.Name)
+	}
+
+	return net.JoinHostPort(service.Spec.ClusterIP, strconv.Itoa(int(portSpec.Port))), nil
+}
+
 func getProtocol(portSpec corev1.ServicePort, portName string, svcConfig *ServiceConfig) string {
 	if svcConfig != nil && svcConfig.Service != nil && svcConfig.Service.ServersScheme != "" {
 		return svcConfig.Service.ServersScheme
@@ -580,6 +678,8 @@ func throttleEvents(ctx context.Context, throttleDuration time.Duration, pool *s
 			select {
 			case <-ctxPool.Done():
 				return
+			case <-ctx.Done():
+				return
 			case nextEvent := <-eventsChan:
 				select {
 				case eventsChanBuffered <- nextEvent:
@@ -2,6 +2,7 @@ package kv
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"path"
@@ -28,7 +29,7 @@ type Provider struct {
 
 	Endpoints []string         `description:"KV store endpoints" json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
 	Username  string           `description:"KV Username" json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
-	Password  string           `description:"KV Password" json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+	Password  string           `description:"KV Password" json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" secret:"true"`
 	TLS       *types.ClientTLS `description:"Enable TLS support" export:"true" json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
 
 	storeType store.Backend
@@ -159,22 +160,34 @@ func (p *Provider) buildConfiguration() (*dynamic.Configuration, error) {
 }
 
 func (p *Provider) createKVClient(ctx context.Context) (store.Store, error) {
-	storeConfig := &store.Config{
-		ConnectionTimeout: 3 * time.Second,
-		Bucket:            "traefik",
-		Username:          p.Username,
-		Password:          p.Password,
-	}
-
+	var tlsConfig *tls.Config
 	if p.TLS != nil {
 		var err error
-		storeConfig.TLS, err = p.TLS.CreateTLSConfig(ctx)
+		tlsConfig, err = p.TLS.CreateTLSConfig(ctx)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	switch p.storeType {
+	kvStore, err := NewStore(p.storeType, p.Endpoints, p.Username, p.Password, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storeWrapper{Store: kvStore}, nil
+}
+
+// NewStore creates a client of a KV store.
+func NewStore(storeType store.Backend, endpoints []string, username, password string, tlsConfig *tls.Config) (store.Store, error) {
+	storeConfig := &store.Config{
+		ConnectionTimeout: 3 * time.Second,
+		Bucket:            "traefik",
+		Username:          username,
+		Password:          password,
+		TLS:               tlsConfig,
+	}
+
+	switch storeType {
 	case store.CONSUL:
 		consul.Register()
 	case store.ETCDV3:
@@ -185,10 +198,5 @@ func (p *Provider) createKVClient(ctx context.Context) (store.Store, error) {
 		redis.Register()
 	}
 
-	kvStore, err := valkeyrie.NewStore(p.storeType, p.Endpoints, storeConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	return &storeWrapper{Store: kvStore}, nil
+	return valkeyrie.NewStore(storeType, endpoints, storeConfig)
 }
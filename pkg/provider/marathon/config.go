@@ -242,13 +242,8 @@ func (p *Provider) keepApplication(ctx context.Context, extraConf configuration,
 	}
 
 	// Filter by constraints.
-	matches, err := constraints.MatchLabels(labels, p.Constraints)
-	if err != nil {
-		logger.Errorf("Error matching constraints expression: %v", err)
-		return false
-	}
-	if !matches {
-		logger.Debugf("Marathon application filtered by constraint expression: %q", p.Constraints)
+	if !p.constraints.Match(constraints.Object{Labels: labels}) {
+		logger.Debugf("Marathon application filtered by constraint expression: %q", p.constraints)
 		return false
 	}
 
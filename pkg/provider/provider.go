@@ -1,10 +1,16 @@
 package provider
 
 import (
+	"context"
+	"errors"
+
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/safe"
 )
 
+// ErrResyncNotSupported is returned when resyncing a provider which does not support it.
+var ErrResyncNotSupported = errors.New("the provider does not support resync")
+
 // Provider defines methods of a provider.
 type Provider interface {
 	// Provide allows the provider to provide configurations to traefik
@@ -12,3 +18,11 @@ type Provider interface {
 	Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error
 	Init() error
 }
+
+// Resyncer is implemented by the providers able to resync their whole configuration on demand,
+// to recover from missed events without restarting.
+type Resyncer interface {
+	// Resync reads again all the sources of the provider, instead of relying on their events or caches,
+	// and returns once the resulting configuration has been sent.
+	Resync(ctx context.Context) error
+}
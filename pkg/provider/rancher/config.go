@@ -158,13 +158,8 @@ func (p *Provider) keepService(ctx context.Context, service rancherData) bool {
 		return false
 	}
 
-	matches, err := constraints.MatchLabels(service.Labels, p.Constraints)
-	if err != nil {
-		logger.Errorf("Error matching constraints expression: %v", err)
-		return false
-	}
-	if !matches {
-		logger.Debugf("Service pruned by constraint expression: %q", p.Constraints)
+	if !p.constraints.Match(constraints.Object{Labels: service.Labels}) {
+		logger.Debugf("Service pruned by constraint expression: %q", p.constraints)
 		return false
 	}
 
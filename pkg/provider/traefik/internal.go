@@ -7,6 +7,7 @@ import (
 	"net"
 	"regexp"
 
+	"github.com/containous/traefik/v2/pkg/acmeserver"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/log"
@@ -68,6 +69,8 @@ func (i *Provider) createConfiguration(ctx context.Context) *dynamic.Configurati
 	i.pingConfiguration(cfg)
 	i.restConfiguration(cfg)
 	i.prometheusConfiguration(cfg)
+	i.acmeServerConfiguration(cfg)
+	i.altSvc(ctx, cfg)
 	i.entryPointModels(cfg)
 	i.redirection(ctx, cfg)
 
@@ -133,27 +136,63 @@ func (i *Provider) getRedirectPort(name string, def *static.Redirections) (strin
 		return port, nil
 	}
 
-	return i.getEntryPointPort(name, def)
+	return i.getEntryPointPort(name, "to", def.EntryPoint.To)
 }
 
-func (i *Provider) getEntryPointPort(name string, def *static.Redirections) (string, error) {
-	dst, ok := i.staticCfg.EntryPoints[def.EntryPoint.To]
+func (i *Provider) getEntryPointPort(name, field, to string) (string, error) {
+	dst, ok := i.staticCfg.EntryPoints[to]
 	if !ok {
-		return "", fmt.Errorf("'to' entry point field references a non-existing entry point: %s", def.EntryPoint.To)
+		return "", fmt.Errorf("'%s' entry point field references a non-existing entry point: %s", field, to)
 	}
 
 	_, port, err := net.SplitHostPort(dst.Address)
 	if err != nil {
 		return "", fmt.Errorf("invalid entry point %q address %q: %v",
-			name, i.staticCfg.EntryPoints[def.EntryPoint.To].Address, err)
+			name, dst.Address, err)
 	}
 
 	return port, nil
 }
 
+func (i *Provider) altSvc(ctx context.Context, cfg *dynamic.Configuration) {
+	for name, ep := range i.staticCfg.EntryPoints {
+		if ep.HTTP.AltSvc == nil {
+			continue
+		}
+
+		logger := log.FromContext(log.With(ctx, log.Str(log.EntryPointName, name)))
+
+		def := ep.HTTP.AltSvc
+		if def.EntryPoint == "" {
+			logger.Error("Unable to create Alt-Svc advertisement: the entry point is missing")
+			continue
+		}
+
+		port, err := i.getEntryPointPort(name, "entryPoint", def.EntryPoint)
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+
+		cfg.HTTP.Middlewares[altSvcMiddlewareName(name)] = &dynamic.Middleware{
+			AltSvc: &dynamic.AltSvc{
+				Protocol: "h2",
+				Port:     port,
+				MaxAge:   def.MaxAge,
+			},
+		}
+	}
+}
+
+func altSvcMiddlewareName(entryPointName string) string {
+	return "altsvc-" + provider.Normalize(entryPointName)
+}
+
 func (i *Provider) entryPointModels(cfg *dynamic.Configuration) {
 	for name, ep := range i.staticCfg.EntryPoints {
-		if len(ep.HTTP.Middlewares) == 0 && ep.HTTP.TLS == nil {
+		_, withAltSvc := cfg.HTTP.Middlewares[altSvcMiddlewareName(name)]
+
+		if len(ep.HTTP.Middlewares) == 0 && ep.HTTP.TLS == nil && !withAltSvc {
 			continue
 		}
 
@@ -161,6 +200,11 @@ func (i *Provider) entryPointModels(cfg *dynamic.Configuration) {
 			Middlewares: ep.HTTP.Middlewares,
 		}
 
+		if withAltSvc {
+			// The advertisement comes first, so that the routers can override it with their own.
+			m.Middlewares = append([]string{altSvcMiddlewareName(name) + "@internal"}, m.Middlewares...)
+		}
+
 		if ep.HTTP.TLS != nil {
 			m.TLS = &dynamic.RouterTLSConfig{
 				Options:      ep.HTTP.TLS.Options,
@@ -274,3 +318,20 @@ func (i *Provider) prometheusConfiguration(cfg *dynamic.Configuration) {
 
 	cfg.HTTP.Services["prometheus"] = &dynamic.Service{}
 }
+
+func (i *Provider) acmeServerConfiguration(cfg *dynamic.Configuration) {
+	if i.staticCfg.ACMEServer == nil {
+		return
+	}
+
+	if !i.staticCfg.ACMEServer.ManualRouting {
+		cfg.HTTP.Routers["acmeserver"] = &dynamic.Router{
+			EntryPoints: []string{i.staticCfg.ACMEServer.EntryPoint},
+			Service:     "acmeserver@internal",
+			Priority:    math.MaxInt32,
+			Rule:        "PathPrefix(`" + acmeserver.PathPrefix + "/`)",
+		}
+	}
+
+	cfg.HTTP.Services["acmeserver"] = &dynamic.Service{}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/containous/traefik/v2/pkg/acmeserver"
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/ping"
 	"github.com/containous/traefik/v2/pkg/provider/rest"
@@ -126,6 +127,24 @@ func Test_createConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "acmeserver_simple.json",
+			staticCfg: static.Configuration{
+				ACMEServer: &acmeserver.Handler{
+					EntryPoint:    "test",
+					ManualRouting: false,
+				},
+			},
+		},
+		{
+			desc: "acmeserver_custom.json",
+			staticCfg: static.Configuration{
+				ACMEServer: &acmeserver.Handler{
+					EntryPoint:    "test",
+					ManualRouting: true,
+				},
+			},
+		},
 		{
 			desc: "rest_insecure.json",
 			staticCfg: static.Configuration{
@@ -188,6 +207,28 @@ func Test_createConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "altsvc.json",
+			staticCfg: static.Configuration{
+				EntryPoints: map[string]*static.EntryPoint{
+					"web": {
+						Address: ":80",
+						HTTP: static.HTTPConfig{
+							AltSvc: &static.AltSvc{
+								EntryPoint: "websecure",
+								MaxAge:     3600,
+							},
+						},
+					},
+					"websecure": {
+						Address: ":443",
+						HTTP: static.HTTPConfig{
+							Middlewares: []string{"test"},
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "redirection.json",
 			staticCfg: static.Configuration{
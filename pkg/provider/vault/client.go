@@ -0,0 +1,209 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/privsep"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+
+	// maxResponseSize limits the size of the responses read from the Vault server.
+	maxResponseSize = 1 << 20
+)
+
+// client issues the certificates of the PKI secrets engine, authenticating with a token,
+// or with a token obtained by logging in with the AppRole or Kubernetes authentication methods.
+type client struct {
+	address   string
+	namespace string
+	auth      *Auth
+	http      *http.Client
+
+	token          string
+	tokenExpiresAt time.Time
+}
+
+func newClient(address, namespace string, clientTLS *types.ClientTLS, auth *Auth) (*client, error) {
+	c := &client{
+		address:   strings.TrimSuffix(address, "/"),
+		namespace: namespace,
+		auth:      auth,
+		http:      &http.Client{Timeout: requestTimeout},
+	}
+
+	if clientTLS != nil {
+		tlsConfig, err := clientTLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration of the Vault server: %w", err)
+		}
+
+		c.http.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
+
+	return c, nil
+}
+
+// issue issues a certificate, and returns it, followed by the certificates of its issuers, and its private key, PEM encoded.
+func (c *client) issue(ctx context.Context, mount string, cert Certificate) ([]byte, []byte, error) {
+	token, err := c.getToken(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to authenticate to the Vault server: %w", err)
+	}
+
+	request := map[string]string{
+		"common_name": cert.CommonName,
+		"format":      "pem",
+	}
+	if len(cert.AltNames) > 0 {
+		request["alt_names"] = strings.Join(cert.AltNames, ",")
+	}
+	if cert.TTL > 0 {
+		request["ttl"] = time.Duration(cert.TTL).String()
+	}
+
+	var secret struct {
+		Data struct {
+			Certificate string   `json:"certificate"`
+			IssuingCA   string   `json:"issuing_ca"`
+			CAChain     []string `json:"ca_chain"`
+			PrivateKey  string   `json:"private_key"`
+		} `json:"data"`
+	}
+
+	path := fmt.Sprintf("%s/issue/%s", strings.Trim(mount, "/"), cert.Role)
+	if err := c.do(ctx, path, token, request, &secret); err != nil {
+		// The token obtained by logging in is renewed at the next attempt, as it may have been revoked.
+		c.tokenExpiresAt = time.Time{}
+		return nil, nil, err
+	}
+
+	if secret.Data.Certificate == "" || secret.Data.PrivateKey == "" {
+		return nil, nil, errors.New("no certificate or private key in the response of the Vault server")
+	}
+
+	chain := secret.Data.CAChain
+	if len(chain) == 0 && secret.Data.IssuingCA != "" {
+		chain = []string{secret.Data.IssuingCA}
+	}
+
+	certPEM := strings.TrimSpace(secret.Data.Certificate) + "\n"
+	for _, ca := range chain {
+		certPEM += strings.TrimSpace(ca) + "\n"
+	}
+
+	return []byte(certPEM), []byte(secret.Data.PrivateKey), nil
+}
+
+// getToken returns the configured token, or the token obtained by logging in, logging in again when it expired.
+func (c *client) getToken(ctx context.Context) (string, error) {
+	if c.auth.AppRole == nil && c.auth.Kubernetes == nil {
+		return c.auth.Token, nil
+	}
+
+	if c.token != "" && time.Now().Before(c.tokenExpiresAt) {
+		return c.token, nil
+	}
+
+	var mount string
+	var request map[string]string
+
+	switch {
+	case c.auth.AppRole != nil:
+		mount = c.auth.AppRole.Mount
+		request = map[string]string{
+			"role_id":   c.auth.AppRole.RoleID,
+			"secret_id": c.auth.AppRole.SecretID,
+		}
+
+	default:
+		jwt, err := privsep.ReadFile(c.auth.Kubernetes.TokenPath)
+		if err != nil {
+			return "", fmt.Errorf("unable to read the token of the service account: %w", err)
+		}
+
+		mount = c.auth.Kubernetes.Mount
+		request = map[string]string{
+			"role": c.auth.Kubernetes.Role,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		}
+	}
+
+	var secret struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+
+	if err := c.do(ctx, fmt.Sprintf("auth/%s/login", strings.Trim(mount, "/")), "", request, &secret); err != nil {
+		return "", err
+	}
+
+	if secret.Auth.ClientToken == "" {
+		return "", errors.New("no token in the response of the Vault server")
+	}
+
+	c.token = secret.Auth.ClientToken
+	// The token is renewed before the end of its lease, and for each issuance when it has no lease.
+	c.tokenExpiresAt = time.Now().Add(time.Duration(secret.Auth.LeaseDuration) * time.Second * 9 / 10)
+
+	return c.token, nil
+}
+
+func (c *client) do(ctx context.Context, path, token string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.address+"/v1/"+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(content, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.Join(vaultErr.Errors, ", "))
+		}
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return json.Unmarshal(content, response)
+}
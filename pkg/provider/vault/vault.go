@@ -0,0 +1,260 @@
+// Package vault provides the certificates issued by the PKI secrets engine of a Vault server,
+// and renews them before they expire.
+package vault
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/provider"
+	"github.com/containous/traefik/v2/pkg/safe"
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+const (
+	providerName = "vault"
+
+	// retryInterval is the interval at which the issuance of a certificate is attempted again after a failure.
+	retryInterval = time.Minute
+
+	defaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+var _ provider.Provider = (*Provider)(nil)
+
+// Provider holds configurations of the provider.
+type Provider struct {
+	Address       string           `description:"Address of the Vault server. If empty, the VAULT_ADDR environment variable is used." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty" export:"true"`
+	Namespace     string           `description:"Vault namespace (Vault Enterprise)." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	TLS           *types.ClientTLS `description:"TLS configuration of the connections to the Vault server." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	Auth          *Auth            `description:"Authentication to the Vault server. If empty, the VAULT_TOKEN environment variable is used." json:"auth,omitempty" toml:"auth,omitempty" yaml:"auth,omitempty" export:"true"`
+	Mount         string           `description:"Path of the PKI secrets engine." json:"mount,omitempty" toml:"mount,omitempty" yaml:"mount,omitempty" export:"true"`
+	RenewFraction float64          `description:"Fraction of the lifetime of the certificates after which they are renewed." json:"renewFraction,omitempty" toml:"renewFraction,omitempty" yaml:"renewFraction,omitempty" export:"true"`
+	Certificates  []Certificate    `description:"Certificates to issue, with the role issuing them, and the stores they are added to." json:"certificates,omitempty" toml:"certificates,omitempty" yaml:"certificates,omitempty" export:"true"`
+
+	client            *client
+	issued            []*issuedCertificate
+	configurationChan chan<- dynamic.Message
+}
+
+// Auth holds the configuration of the authentication to the Vault server.
+type Auth struct {
+	Token      string          `description:"Vault token." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" secret:"true"`
+	AppRole    *AppRole        `description:"AppRole authentication." json:"appRole,omitempty" toml:"appRole,omitempty" yaml:"appRole,omitempty" export:"true"`
+	Kubernetes *KubernetesAuth `description:"Kubernetes authentication, with the token of the service account." json:"kubernetes,omitempty" toml:"kubernetes,omitempty" yaml:"kubernetes,omitempty" export:"true"`
+}
+
+// AppRole holds the configuration of the AppRole authentication.
+type AppRole struct {
+	Mount    string `description:"Path of the AppRole authentication method." json:"mount,omitempty" toml:"mount,omitempty" yaml:"mount,omitempty" export:"true"`
+	RoleID   string `description:"Role ID." json:"roleID,omitempty" toml:"roleID,omitempty" yaml:"roleID,omitempty" export:"true"`
+	SecretID string `description:"Secret ID." json:"secretID,omitempty" toml:"secretID,omitempty" yaml:"secretID,omitempty" secret:"true"`
+}
+
+// SetDefaults sets the default values.
+func (a *AppRole) SetDefaults() {
+	a.Mount = "approle"
+}
+
+// KubernetesAuth holds the configuration of the Kubernetes authentication.
+type KubernetesAuth struct {
+	Mount     string `description:"Path of the Kubernetes authentication method." json:"mount,omitempty" toml:"mount,omitempty" yaml:"mount,omitempty" export:"true"`
+	Role      string `description:"Vault role bound to the service account." json:"role,omitempty" toml:"role,omitempty" yaml:"role,omitempty" export:"true"`
+	TokenPath string `description:"Path of the token of the service account." json:"tokenPath,omitempty" toml:"tokenPath,omitempty" yaml:"tokenPath,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (k *KubernetesAuth) SetDefaults() {
+	k.Mount = "kubernetes"
+	k.TokenPath = defaultKubernetesTokenPath
+}
+
+// Certificate holds the configuration of a certificate issued by the PKI secrets engine.
+type Certificate struct {
+	Role       string         `description:"Role of the PKI secrets engine issuing the certificate." json:"role,omitempty" toml:"role,omitempty" yaml:"role,omitempty" export:"true"`
+	CommonName string         `description:"Common name of the certificate." json:"commonName,omitempty" toml:"commonName,omitempty" yaml:"commonName,omitempty" export:"true"`
+	AltNames   []string       `description:"Subject alternative names of the certificate." json:"altNames,omitempty" toml:"altNames,omitempty" yaml:"altNames,omitempty" export:"true"`
+	TTL        types.Duration `description:"Requested lifetime of the certificate. If empty, the default of the role is used." json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty" export:"true"`
+	Stores     []string       `description:"TLS stores the certificate is added to. If empty, the certificate is added to the default store." json:"stores,omitempty" toml:"stores,omitempty" yaml:"stores,omitempty" export:"true"`
+}
+
+// issuedCertificate is a certificate issued by the PKI secrets engine.
+type issuedCertificate struct {
+	certificate []byte
+	key         []byte
+	renewAt     time.Time
+}
+
+// SetDefaults sets the default values.
+func (p *Provider) SetDefaults() {
+	p.Mount = "pki"
+	p.RenewFraction = 0.7
+}
+
+// Init the provider.
+func (p *Provider) Init() error {
+	if p.RenewFraction <= 0 || p.RenewFraction >= 1 {
+		return fmt.Errorf("the renew fraction must be between 0 and 1, got %v", p.RenewFraction)
+	}
+
+	if len(p.Certificates) == 0 {
+		return errors.New("no certificate to issue")
+	}
+
+	for i, cert := range p.Certificates {
+		if cert.Role == "" || cert.CommonName == "" {
+			return fmt.Errorf("the certificate %d must have a role and a common name", i)
+		}
+	}
+
+	address := p.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return errors.New("no Vault server address")
+	}
+
+	auth := p.Auth
+	if auth == nil {
+		auth = &Auth{Token: os.Getenv("VAULT_TOKEN")}
+	}
+
+	var err error
+	p.client, err = newClient(address, p.Namespace, p.TLS, auth)
+	if err != nil {
+		return err
+	}
+
+	p.issued = make([]*issuedCertificate, len(p.Certificates))
+
+	return nil
+}
+
+// Provide allows the provider to provide configurations to traefik using the given configuration channel.
+func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	p.configurationChan = configurationChan
+
+	pool.GoCtx(func(ctxPool context.Context) {
+		ctx := log.With(ctxPool, log.Str(log.ProviderName, providerName))
+
+		for {
+			next := p.refresh(ctx)
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	})
+
+	return nil
+}
+
+// refresh issues the certificates which are not issued yet, or are due for renewal,
+// sends the configuration when at least one of them changed, and returns when the next certificate is due for renewal.
+// A certificate which cannot be renewed is kept until its issuance succeeds.
+func (p *Provider) refresh(ctx context.Context) time.Time {
+	logger := log.FromContext(ctx)
+
+	now := time.Now()
+
+	var next time.Time
+	schedule := func(at time.Time) {
+		if next.IsZero() || at.Before(next) {
+			next = at
+		}
+	}
+
+	var changed bool
+	for i, cert := range p.Certificates {
+		if p.issued[i] != nil && now.Before(p.issued[i].renewAt) {
+			schedule(p.issued[i].renewAt)
+			continue
+		}
+
+		issued, err := p.issue(ctx, cert)
+		if err != nil {
+			logger.Errorf("Unable to issue the certificate %q with the role %q: %v", cert.CommonName, cert.Role, err)
+			schedule(now.Add(retryInterval))
+			continue
+		}
+
+		logger.Infof("Certificate %q issued with the role %q, renewed at %s", cert.CommonName, cert.Role, issued.renewAt.Format(time.RFC3339))
+
+		p.issued[i] = issued
+		changed = true
+
+		schedule(issued.renewAt)
+	}
+
+	if changed {
+		p.configurationChan <- p.buildConfiguration()
+	}
+
+	return next
+}
+
+func (p *Provider) issue(ctx context.Context, cert Certificate) (*issuedCertificate, error) {
+	certPEM, keyPEM, err := p.client.issue(ctx, p.Mount, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("no PEM data in the issued certificate")
+	}
+
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issued certificate: %w", err)
+	}
+
+	lifetime := leaf.NotAfter.Sub(leaf.NotBefore)
+
+	return &issuedCertificate{
+		certificate: certPEM,
+		key:         keyPEM,
+		renewAt:     leaf.NotBefore.Add(time.Duration(float64(lifetime) * p.RenewFraction)),
+	}, nil
+}
+
+func (p *Provider) buildConfiguration() dynamic.Message {
+	conf := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers:     map[string]*dynamic.Router{},
+			Middlewares: map[string]*dynamic.Middleware{},
+			Services:    map[string]*dynamic.Service{},
+		},
+		TLS: &dynamic.TLSConfiguration{},
+	}
+
+	for i, cert := range p.Certificates {
+		issued := p.issued[i]
+		if issued == nil {
+			continue
+		}
+
+		conf.TLS.Certificates = append(conf.TLS.Certificates, &traefiktls.CertAndStores{
+			Certificate: traefiktls.Certificate{
+				CertFile: traefiktls.FileOrContent(issued.certificate),
+				KeyFile:  traefiktls.FileOrContent(issued.key),
+			},
+			Stores: cert.Stores,
+		})
+	}
+
+	return dynamic.Message{ProviderName: providerName, Configuration: conf}
+}
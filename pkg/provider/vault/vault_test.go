@@ -0,0 +1,294 @@
+package vault
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault is a Vault server issuing certificates with its PKI secrets engine, after an AppRole or Kubernetes login.
+type fakeVault struct {
+	t      *testing.T
+	caCert *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	caPEM  string
+
+	mu       sync.Mutex
+	logins   []map[string]string
+	issued   []map[string]string
+	lifetime time.Duration
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &fakeVault{
+		t:        t,
+		caCert:   cert,
+		caKey:    key,
+		caPEM:    string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		lifetime: time.Hour,
+	}
+}
+
+func (v *fakeVault) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var request map[string]string
+	require.NoError(v.t, json.NewDecoder(req.Body).Decode(&request))
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	switch req.URL.Path {
+	case "/v1/auth/approle/login", "/v1/auth/kubernetes/login":
+		v.logins = append(v.logins, request)
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": "login-token", "lease_duration": 3600},
+		})
+
+	case "/v1/pki/issue/web":
+		if req.Header.Get("X-Vault-Token") != "login-token" {
+			rw.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(rw).Encode(map[string]interface{}{"errors": []string{"permission denied"}})
+			return
+		}
+
+		v.issued = append(v.issued, request)
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(v.t, err)
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(len(v.issued) + 1)),
+			Subject:      pkix.Name{CommonName: request["common_name"]},
+			DNSNames:     []string{request["common_name"]},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(v.lifetime),
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, v.caCert, &key.PublicKey, v.caKey)
+		require.NoError(v.t, err)
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(v.t, err)
+
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"certificate": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+				"issuing_ca":  v.caPEM,
+				"ca_chain":    []string{v.caPEM},
+				"private_key": string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+			},
+		})
+
+	default:
+		rw.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestProvider_Init(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		provider    Provider
+		expectedErr bool
+	}{
+		{
+			desc: "valid",
+			provider: Provider{
+				Address:       "http://127.0.0.1:8200",
+				RenewFraction: 0.7,
+				Certificates:  []Certificate{{Role: "web", CommonName: "example.com"}},
+			},
+		},
+		{
+			desc: "no certificate",
+			provider: Provider{
+				Address:       "http://127.0.0.1:8200",
+				RenewFraction: 0.7,
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "no role",
+			provider: Provider{
+				Address:       "http://127.0.0.1:8200",
+				RenewFraction: 0.7,
+				Certificates:  []Certificate{{CommonName: "example.com"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "invalid renew fraction",
+			provider: Provider{
+				Address:       "http://127.0.0.1:8200",
+				RenewFraction: 1,
+				Certificates:  []Certificate{{Role: "web", CommonName: "example.com"}},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.provider.Init()
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProvider_refresh(t *testing.T) {
+	vault := newFakeVault(t)
+
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	p := &Provider{
+		Address: server.URL,
+		Auth: &Auth{
+			AppRole: &AppRole{Mount: "approle", RoleID: "role", SecretID: "secret"},
+		},
+		Mount:         "pki",
+		RenewFraction: 0.5,
+		Certificates: []Certificate{
+			{Role: "web", CommonName: "example.com", AltNames: []string{"www.example.com"}, TTL: types.Duration(time.Hour), Stores: []string{"web"}},
+			{Role: "unknown", CommonName: "example.org"},
+		},
+	}
+	require.NoError(t, p.Init())
+
+	configurationChan := make(chan dynamic.Message, 10)
+	p.configurationChan = configurationChan
+
+	start := time.Now()
+	next := p.refresh(context.Background())
+
+	// The issuance of the certificate which cannot be issued is attempted again first.
+	assert.WithinDuration(t, start.Add(retryInterval), next, 5*time.Second)
+
+	require.Len(t, configurationChan, 1)
+	msg := <-configurationChan
+	assert.Equal(t, "vault", msg.ProviderName)
+
+	// The certificate which cannot be issued is not provided.
+	require.Len(t, msg.Configuration.TLS.Certificates, 1)
+	cert := msg.Configuration.TLS.Certificates[0]
+	assert.Equal(t, []string{"web"}, cert.Stores)
+
+	// The certificate is followed by the certificate of its issuer.
+	rest := []byte(cert.Certificate.CertFile)
+	var blocks int
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		blocks++
+	}
+	assert.Equal(t, 2, blocks)
+
+	vault.mu.Lock()
+	assert.Equal(t, []map[string]string{{"role_id": "role", "secret_id": "secret"}}, vault.logins)
+	assert.Equal(t, []map[string]string{{"common_name": "example.com", "alt_names": "www.example.com", "format": "pem", "ttl": "1h0m0s"}}, vault.issued)
+	vault.mu.Unlock()
+
+	// The certificate is not issued again until it is due for renewal.
+	p.refresh(context.Background())
+	assert.Len(t, configurationChan, 0)
+
+	// The certificate is renewed at the half of its lifetime.
+	assert.WithinDuration(t, start.Add(30*time.Minute), p.issued[0].renewAt, 5*time.Second)
+
+	p.issued[0].renewAt = time.Now().Add(-time.Second)
+	p.refresh(context.Background())
+	assert.Len(t, configurationChan, 1)
+
+	vault.mu.Lock()
+	assert.Len(t, vault.issued, 2)
+	vault.mu.Unlock()
+}
+
+func TestClient_kubernetesAuth(t *testing.T) {
+	vault := newFakeVault(t)
+
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "vault")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tokenPath := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenPath, []byte("jwt\n"), 0600))
+
+	c, err := newClient(server.URL, "", nil, &Auth{
+		Kubernetes: &KubernetesAuth{Mount: "kubernetes", Role: "traefik", TokenPath: tokenPath},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, _, err = c.issue(context.Background(), "pki", Certificate{Role: "web", CommonName: "example.com"})
+		require.NoError(t, err)
+	}
+
+	// The token obtained by logging in is reused until its lease expires.
+	vault.mu.Lock()
+	assert.Equal(t, []map[string]string{{"role": "traefik", "jwt": "jwt"}}, vault.logins)
+	vault.mu.Unlock()
+}
+
+func TestClient_invalidToken(t *testing.T) {
+	server := httptest.NewServer(newFakeVault(t))
+	defer server.Close()
+
+	c, err := newClient(server.URL, "", nil, &Auth{Token: "invalid"})
+	require.NoError(t, err)
+
+	_, _, err = c.issue(context.Background(), "pki", Certificate{Role: "web", CommonName: "example.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/middlewares/headers"
 	"github.com/containous/traefik/v2/pkg/server/provider"
 )
 
@@ -34,9 +35,16 @@ func (f *Builder) Build(ctx context.Context, names []string) func(*http.Response
 		}
 
 		if conf.Headers != nil {
-			getLogger(ctx, middleName, "Headers").Debug("Creating Middleware (ResponseModifier)")
+			logger := getLogger(ctx, middleName, "Headers")
+			logger.Debug("Creating Middleware (ResponseModifier)")
 
-			modifiers = append(modifiers, buildHeaders(conf.Headers))
+			hdrs := conf.Headers.DeepCopy()
+			if err := headers.ApplySecurityPreset(hdrs); err != nil {
+				logger.Error(err)
+				continue
+			}
+
+			modifiers = append(modifiers, buildHeaders(hdrs))
 		} else if conf.Chain != nil {
 			chainCtx := provider.AddInContext(ctx, middleName)
 			getLogger(chainCtx, middleName, "Chain").Debug("Creating Middleware (ResponseModifier)")
@@ -88,6 +88,46 @@ func TestBuilderBuild(t *testing.T) {
 				assert.Equal(t, "no-referrer", resp.Header.Get("Referrer-Policy"))
 			},
 		},
+		{
+			desc:        "secure: security preset",
+			middlewares: []string{"foo"},
+			buildResponse: func(middlewares map[string]*dynamic.Middleware) *http.Response {
+				ctx := context.Background()
+
+				var request *http.Request
+				next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					request = req
+				})
+
+				headerM := *middlewares["foo"].Headers
+				handler, err := headers.New(ctx, next, headerM, "secure")
+				require.NoError(t, err)
+
+				handler.ServeHTTP(httptest.NewRecorder(),
+					httptest.NewRequest(http.MethodGet, "http://foo.com", nil))
+
+				return &http.Response{Header: make(http.Header), Request: request}
+			},
+			conf: map[string]*dynamic.Middleware{
+				"foo": {
+					Headers: &dynamic.Headers{
+						SecurityPreset:        "strict",
+						ReferrerPolicy:        "same-origin",
+						CustomResponseHeaders: map[string]string{"cross-origin-embedder-policy": ""},
+					},
+				},
+			},
+			assertResponse: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, "same-origin", resp.Header.Get("Referrer-Policy"))
+				assert.Equal(t, "DENY", resp.Header.Get("X-Frame-Options"))
+				assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+				assert.Equal(t, "same-origin", resp.Header.Get("Cross-Origin-Opener-Policy"))
+				assert.NotEmpty(t, resp.Header.Get("Content-Security-Policy-Report-Only"))
+				assert.Empty(t, resp.Header.Get("Cross-Origin-Embedder-Policy"))
+			},
+		},
 		{
 			desc:          "two modifiers",
 			middlewares:   []string{"foo", "bar"},
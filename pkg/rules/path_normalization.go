@@ -0,0 +1,178 @@
+package rules
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// Policies of the encoded slashes (%2F) of the request paths.
+const (
+	encodedSlashesKeep   = "keep"
+	encodedSlashesDecode = "decode"
+	encodedSlashesReject = "reject"
+)
+
+// pathNormalizer normalizes the request paths of a route, before matching its rule, and before forwarding the requests.
+type pathNormalizer struct {
+	mergeSlashes       bool
+	decodeSlashes      bool
+	rejectSlashes      bool
+	resolveDotSegments bool
+	lowercase          bool
+	strict             bool
+}
+
+func newPathNormalizer(config dynamic.PathNormalization) (*pathNormalizer, error) {
+	n := &pathNormalizer{
+		mergeSlashes:       config.MergeSlashes,
+		resolveDotSegments: config.ResolveDotSegments,
+		lowercase:          config.Lowercase,
+		strict:             config.Strict,
+	}
+
+	switch config.EncodedSlashes {
+	case "", encodedSlashesKeep:
+	case encodedSlashesDecode:
+		n.decodeSlashes = true
+	case encodedSlashesReject:
+		n.rejectSlashes = true
+	default:
+		return nil, fmt.Errorf("invalid encodedSlashes policy: %q", config.EncodedSlashes)
+	}
+
+	return n, nil
+}
+
+// handler rejects the requests whose paths are rejected by the normalization,
+// and forwards the other requests with their normalized paths.
+func (n *pathNormalizer) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if reason := n.rejected(req.URL.EscapedPath()); reason != "" {
+			log.FromContext(req.Context()).Debugf("Rejecting the request path %s: %s", req.URL.EscapedPath(), reason)
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		next.ServeHTTP(rw, n.normalize(req))
+	})
+}
+
+// rejected returns why the escaped path is rejected, or the empty string.
+func (n *pathNormalizer) rejected(escaped string) string {
+	if n.rejectSlashes && strings.Contains(strings.ToLower(escaped), "%2f") {
+		return "encoded slash"
+	}
+
+	if n.strict {
+		return ambiguousPath(escaped)
+	}
+
+	return ""
+}
+
+// normalize returns a copy of the request with the normalized path,
+// or the request itself if its path is already normalized.
+func (n *pathNormalizer) normalize(req *http.Request) *http.Request {
+	escaped := req.URL.EscapedPath()
+
+	normalized := n.normalizePath(escaped)
+	if normalized == escaped {
+		return req
+	}
+
+	path, err := url.PathUnescape(normalized)
+	if err != nil {
+		return req
+	}
+
+	normalizedURL := *req.URL
+	normalizedURL.Path = path
+	normalizedURL.RawPath = ""
+	if (&url.URL{Path: path}).EscapedPath() != normalized {
+		normalizedURL.RawPath = normalized
+	}
+
+	normalizedReq := req.WithContext(req.Context())
+	normalizedReq.URL = &normalizedURL
+	normalizedReq.RequestURI = normalizedURL.RequestURI()
+
+	return normalizedReq
+}
+
+// normalizePath returns the normalized escaped path.
+func (n *pathNormalizer) normalizePath(escaped string) string {
+	if n.decodeSlashes {
+		escaped = strings.NewReplacer("%2F", "/", "%2f", "/").Replace(escaped)
+	}
+
+	if (n.mergeSlashes || n.resolveDotSegments) && strings.HasPrefix(escaped, "/") {
+		segments := strings.Split(escaped[1:], "/")
+
+		var normalized []string
+		for i, segment := range segments {
+			last := i == len(segments)-1
+
+			if n.mergeSlashes && segment == "" && !last {
+				continue
+			}
+
+			if n.resolveDotSegments {
+				switch unescaped, _ := url.PathUnescape(segment); unescaped {
+				case ".":
+					segment = ""
+					if !last {
+						continue
+					}
+				case "..":
+					if len(normalized) > 0 {
+						normalized = normalized[:len(normalized)-1]
+					}
+					segment = ""
+					if !last {
+						continue
+					}
+				}
+			}
+
+			normalized = append(normalized, segment)
+		}
+
+		escaped = "/" + strings.Join(normalized, "/")
+	}
+
+	if n.lowercase {
+		escaped = strings.ToLower(escaped)
+	}
+
+	return escaped
+}
+
+// ambiguousPath returns why the escaped path is ambiguous, i.e. could be interpreted differently by the backends,
+// or the empty string.
+func ambiguousPath(escaped string) string {
+	lower := strings.ToLower(escaped)
+
+	switch {
+	case strings.Contains(escaped, "//"):
+		return "empty segment"
+	case strings.Contains(lower, "%2f"), strings.Contains(lower, "%5c"), strings.Contains(escaped, `\`):
+		return "encoded slash, or backslash"
+	case strings.Contains(lower, "%25"):
+		return "encoded percent sign"
+	case strings.Contains(lower, "%00"):
+		return "encoded NUL character"
+	}
+
+	for _, segment := range strings.Split(escaped, "/") {
+		if unescaped, _ := url.PathUnescape(segment); unescaped == "." || unescaped == ".." {
+			return "dot segment"
+		}
+	}
+
+	return ""
+}
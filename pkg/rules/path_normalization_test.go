@@ -0,0 +1,197 @@
+package rules
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathNormalizer_normalizePath(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   dynamic.PathNormalization
+		path     string
+		expected string
+	}{
+		{
+			desc:     "no normalization",
+			path:     "/foo//bar/../%2Fbaz",
+			expected: "/foo//bar/../%2Fbaz",
+		},
+		{
+			desc:     "merged slashes",
+			config:   dynamic.PathNormalization{MergeSlashes: true},
+			path:     "//foo///bar//",
+			expected: "/foo/bar/",
+		},
+		{
+			desc:     "decoded slashes",
+			config:   dynamic.PathNormalization{EncodedSlashes: "decode"},
+			path:     "/foo%2Fbar%2fbaz",
+			expected: "/foo/bar/baz",
+		},
+		{
+			desc:     "resolved dot segments",
+			config:   dynamic.PathNormalization{ResolveDotSegments: true},
+			path:     "/foo/./bar/../baz",
+			expected: "/foo/baz",
+		},
+		{
+			desc:     "resolved encoded dot segments",
+			config:   dynamic.PathNormalization{ResolveDotSegments: true},
+			path:     "/foo/%2e%2E/bar/%2e",
+			expected: "/bar/",
+		},
+		{
+			desc:     "dot segments above the root",
+			config:   dynamic.PathNormalization{ResolveDotSegments: true},
+			path:     "/../../foo",
+			expected: "/foo",
+		},
+		{
+			desc:     "resolved dot segments of decoded slashes",
+			config:   dynamic.PathNormalization{EncodedSlashes: "decode", ResolveDotSegments: true},
+			path:     "/public/..%2Fadmin",
+			expected: "/admin",
+		},
+		{
+			desc:     "lowercased",
+			config:   dynamic.PathNormalization{Lowercase: true},
+			path:     "/Foo/BAR",
+			expected: "/foo/bar",
+		},
+		{
+			desc:     "asterisk",
+			config:   dynamic.PathNormalization{MergeSlashes: true, ResolveDotSegments: true},
+			path:     "*",
+			expected: "*",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			normalizer, err := newPathNormalizer(test.config)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, normalizer.normalizePath(test.path))
+		})
+	}
+}
+
+func TestAmbiguousPath(t *testing.T) {
+	testCases := []struct {
+		path      string
+		ambiguous bool
+	}{
+		{path: "/foo/bar"},
+		{path: "/foo/bar/"},
+		{path: "/foo%20bar"},
+		{path: "/foo...bar"},
+		{path: "/foo//bar", ambiguous: true},
+		{path: "/foo%2Fbar", ambiguous: true},
+		{path: "/foo%5cbar", ambiguous: true},
+		{path: "/foo%252Fbar", ambiguous: true},
+		{path: "/foo%00", ambiguous: true},
+		{path: "/foo/../bar", ambiguous: true},
+		{path: "/foo/%2e", ambiguous: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.path, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.ambiguous, ambiguousPath(test.path) != "")
+		})
+	}
+}
+
+func TestRouter_AddNormalizedRoute(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             *dynamic.PathNormalization
+		url                string
+		expectedStatusCode int
+		expectedRequestURI string
+	}{
+		{
+			desc:               "without normalization",
+			url:                "http://localhost/public/../admin",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:               "normalized before matching",
+			config:             &dynamic.PathNormalization{ResolveDotSegments: true},
+			url:                "http://localhost/public/../admin?foo=bar",
+			expectedStatusCode: http.StatusOK,
+			expectedRequestURI: "/admin?foo=bar",
+		},
+		{
+			desc:               "normalized encoded slashes",
+			config:             &dynamic.PathNormalization{MergeSlashes: true, EncodedSlashes: "decode"},
+			url:                "http://localhost//admin%2Fusers",
+			expectedStatusCode: http.StatusOK,
+			expectedRequestURI: "/admin/users",
+		},
+		{
+			desc:               "rejected encoded slashes",
+			config:             &dynamic.PathNormalization{EncodedSlashes: "reject"},
+			url:                "http://localhost/admin%2Fusers",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "rejected ambiguous path",
+			config:             &dynamic.PathNormalization{ResolveDotSegments: true, Strict: true},
+			url:                "http://localhost/public/../admin",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "unambiguous path",
+			config:             &dynamic.PathNormalization{Strict: true},
+			url:                "/admin/users",
+			expectedStatusCode: http.StatusOK,
+			expectedRequestURI: "/admin/users",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewRouter()
+			require.NoError(t, err)
+
+			var requestURI string
+			handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				requestURI = req.RequestURI
+			})
+
+			err = router.AddNormalizedRoute("PathPrefix(`/admin`)", 0, test.config, handler)
+			require.NoError(t, err)
+
+			router.SortRoutes()
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.url, nil))
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			assert.Equal(t, test.expectedRequestURI, requestURI)
+		})
+	}
+}
+
+func TestRouter_AddNormalizedRoute_invalidPolicy(t *testing.T) {
+	router, err := NewRouter()
+	require.NoError(t, err)
+
+	err = router.AddNormalizedRoute("PathPrefix(`/admin`)", 0, &dynamic.PathNormalization{EncodedSlashes: "foo"}, http.NotFoundHandler())
+	assert.Error(t, err)
+}
@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares/requestdecorator"
+	"github.com/containous/traefik/v2/pkg/tcp"
 	"github.com/gorilla/mux"
 	"github.com/vulcand/predicate"
 )
@@ -20,6 +22,7 @@ var funcs = map[string]func(*mux.Route, ...string) error{
 	"Headers":       headers,
 	"HeadersRegexp": headersRegexp,
 	"Query":         query,
+	"ClientTLSJA3":  clientTLSJA3,
 }
 
 // Router handle routing with rules
@@ -43,14 +46,9 @@ func NewRouter() (*Router, error) {
 
 // AddRoute add a new route to the router.
 func (r *Router) AddRoute(rule string, priority int, handler http.Handler) error {
-	parse, err := r.parser.Parse(rule)
+	buildTree, err := r.parseRule(rule)
 	if err != nil {
-		return fmt.Errorf("error while parsing rule %s: %v", rule, err)
-	}
-
-	buildTree, ok := parse.(treeBuilder)
-	if !ok {
-		return fmt.Errorf("error while parsing rule %s", rule)
+		return err
 	}
 
 	if priority == 0 {
@@ -61,6 +59,57 @@ func (r *Router) AddRoute(rule string, priority int, handler http.Handler) error
 	return addRuleOnRoute(route, buildTree())
 }
 
+// AddNormalizedRoute adds a new route to the router, whose rule is matched against the requests with normalized paths,
+// which are then given to the handler, unless their paths are rejected by the normalization.
+// Without normalization, it adds the route as AddRoute does.
+func (r *Router) AddNormalizedRoute(rule string, priority int, normalization *dynamic.PathNormalization, handler http.Handler) error {
+	if normalization == nil {
+		return r.AddRoute(rule, priority, handler)
+	}
+
+	normalizer, err := newPathNormalizer(*normalization)
+	if err != nil {
+		return err
+	}
+
+	buildTree, err := r.parseRule(rule)
+	if err != nil {
+		return err
+	}
+
+	if priority == 0 {
+		priority = len(rule)
+	}
+
+	normalizedHandler := normalizer.handler(handler)
+
+	// The rule is added on a route of a distinct router, which is matched against the normalized requests.
+	ruleRoute := mux.NewRouter().SkipClean(true).NewRoute().Handler(normalizedHandler)
+	err = addRuleOnRoute(ruleRoute, buildTree())
+	if err != nil {
+		return err
+	}
+
+	r.NewRoute().Handler(normalizedHandler).Priority(priority).MatcherFunc(func(req *http.Request, match *mux.RouteMatch) bool {
+		return ruleRoute.Match(normalizer.normalize(req), match)
+	})
+	return nil
+}
+
+func (r *Router) parseRule(rule string) (treeBuilder, error) {
+	parse, err := r.parser.Parse(rule)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing rule %s: %v", rule, err)
+	}
+
+	buildTree, ok := parse.(treeBuilder)
+	if !ok {
+		return nil, fmt.Errorf("error while parsing rule %s", rule)
+	}
+
+	return buildTree, nil
+}
+
 type tree struct {
 	matcher   string
 	value     []string
@@ -164,6 +213,28 @@ func headersRegexp(route *mux.Route, headers ...string) error {
 	return route.HeadersRegexp(headers...).GetError()
 }
 
+// clientTLSJA3 matches the requests whose TLS connection has one of the JA3 fingerprints.
+func clientTLSJA3(route *mux.Route, fingerprints ...string) error {
+	for i, fingerprint := range fingerprints {
+		fingerprints[i] = strings.ToLower(fingerprint)
+	}
+
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		ja3 := tcp.GetClientJA3(req.Context())
+		if ja3 == "" {
+			return false
+		}
+
+		for _, fingerprint := range fingerprints {
+			if ja3 == fingerprint {
+				return true
+			}
+		}
+		return false
+	})
+	return nil
+}
+
 func query(route *mux.Route, query ...string) error {
 	var queries []string
 	for _, elem := range query {
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/containous/traefik/v2/pkg/middlewares/requestdecorator"
+	"github.com/containous/traefik/v2/pkg/tcp"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -668,6 +669,50 @@ func TestHostRegexp(t *testing.T) {
 	}
 }
 
+func TestClientTLSJA3(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		ja3      string
+		expected bool
+	}{
+		{
+			desc:     "matching fingerprint",
+			ja3:      "e7d705a3286e19ea42f587b344ee6865",
+			expected: true,
+		},
+		{
+			desc:     "other fingerprint",
+			ja3:      "b32309a26951912be7dba376398abc3b",
+			expected: true,
+		},
+		{
+			desc: "not matching fingerprint",
+			ja3:  "6734f37431670b3ab4292b8f60f29984",
+		},
+		{
+			desc: "no fingerprint",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rt := &mux.Route{}
+			err := clientTLSJA3(rt, "E7D705A3286E19EA42F587B344EE6865", "b32309a26951912be7dba376398abc3b")
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "https://localhost/", nil)
+			if test.ja3 != "" {
+				req = req.WithContext(tcp.WithClientJA3(req.Context(), test.ja3))
+			}
+
+			assert.Equal(t, test.expected, rt.Match(req, &mux.RouteMatch{}))
+		})
+	}
+}
+
 func TestParseDomains(t *testing.T) {
 	testCases := []struct {
 		description   string
@@ -0,0 +1,95 @@
+// Package runas drops the privileges of the process once the entry points are listening,
+// by changing its root directory, its user and its group, and by dropping its Linux capabilities,
+// so that Traefik can be started as root to bind the privileged ports, without keeping the root privileges afterwards.
+package runas
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// Configuration holds the configuration of the privileges dropped once the entry points are listening.
+type Configuration struct {
+	User             string `description:"User running the process once the entry points are listening." json:"user,omitempty" toml:"user,omitempty" yaml:"user,omitempty" export:"true"`
+	Group            string `description:"Group running the process once the entry points are listening. Defaults to the primary group of the user." json:"group,omitempty" toml:"group,omitempty" yaml:"group,omitempty" export:"true"`
+	Chroot           string `description:"Directory becoming the root directory of the process once the entry points are listening." json:"chroot,omitempty" toml:"chroot,omitempty" yaml:"chroot,omitempty" export:"true"`
+	DropCapabilities bool   `description:"Drop all the Linux capabilities once the entry points are listening." json:"dropCapabilities,omitempty" toml:"dropCapabilities,omitempty" yaml:"dropCapabilities,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Configuration) SetDefaults() {
+	c.DropCapabilities = true
+}
+
+// Apply drops the privileges of the process, as defined by the configuration.
+func Apply(config *Configuration) error {
+	if config == nil {
+		return nil
+	}
+
+	// The user and the group are looked up before changing the root directory, which can hide the user database.
+	uid, gid, err := lookupCredential(config.User, config.Group)
+	if err != nil {
+		return err
+	}
+
+	if err := dropPrivileges(config, uid, gid); err != nil {
+		return err
+	}
+
+	logger := log.WithoutContext()
+	if config.Chroot != "" {
+		logger.Infof("The root directory is changed to %s", config.Chroot)
+	}
+	if uid >= 0 {
+		logger.Infof("Running as the user %d and the group %d", uid, gid)
+	} else if gid >= 0 {
+		logger.Infof("Running as the group %d", gid)
+	}
+	if config.DropCapabilities {
+		logger.Info("The capabilities are dropped")
+	}
+
+	return nil
+}
+
+// lookupCredential returns the user and group IDs, or -1 when they are not defined.
+// The group defaults to the primary group of the user.
+func lookupCredential(userName, groupName string) (int, int, error) {
+	uid, gid := -1, -1
+
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			if u, err = user.LookupId(userName); err != nil {
+				return 0, 0, fmt.Errorf("unknown user %s: %w", userName, err)
+			}
+		}
+
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return 0, 0, fmt.Errorf("invalid user ID %s: %w", u.Uid, err)
+		}
+
+		if gid, err = strconv.Atoi(u.Gid); err != nil {
+			return 0, 0, fmt.Errorf("invalid group ID %s: %w", u.Gid, err)
+		}
+	}
+
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			if g, err = user.LookupGroupId(groupName); err != nil {
+				return 0, 0, fmt.Errorf("unknown group %s: %w", groupName, err)
+			}
+		}
+
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return 0, 0, fmt.Errorf("invalid group ID %s: %w", g.Gid, err)
+		}
+	}
+
+	return uid, gid, nil
+}
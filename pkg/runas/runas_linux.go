@@ -0,0 +1,135 @@
+package runas
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	prCapBSetDrop   = 24
+	prSetNoNewPrivs = 38
+
+	linuxCapabilityVersion3 = 0x20080522
+
+	// lastCapFile holds the number of the last capability supported by the kernel.
+	lastCapFile = "/proc/sys/kernel/cap_last_cap"
+)
+
+// capHeader and capData are the arguments of the capset system call.
+type capHeader struct {
+	version uint32
+	pid     int32
+}
+
+type capData struct {
+	effective   uint32
+	permitted   uint32
+	inheritable uint32
+}
+
+// syscaller runs a system call, either on a single thread, or on all the threads of the process.
+type syscaller func(trap, a1, a2, a3 uintptr) error
+
+func dropPrivileges(config *Configuration, uid, gid int) error {
+	// The root directory is shared by the threads, unlike the credentials and the capabilities,
+	// which are changed on all the threads of the process.
+	return onAllThreads(func() error {
+		return changeRoot(config.Chroot)
+	}, func(sys syscaller) error {
+		return dropCredentials(sys, config.DropCapabilities, uid, gid)
+	})
+}
+
+func changeRoot(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	if err := syscall.Chroot(dir); err != nil {
+		return fmt.Errorf("unable to change the root directory to %s: %w", dir, err)
+	}
+
+	if err := syscall.Chdir("/"); err != nil {
+		return fmt.Errorf("unable to change the working directory: %w", err)
+	}
+
+	return nil
+}
+
+// dropCredentials changes the user and the group, and drops the capabilities, with the system calls run by the syscaller.
+func dropCredentials(sys syscaller, dropCapabilities bool, uid, gid int) error {
+	// The bounding set can only be dropped while the process still has the CAP_SETPCAP capability, before changing the user.
+	if dropCapabilities {
+		if err := dropBoundingSet(sys); err != nil {
+			return fmt.Errorf("unable to drop the capabilities bounding set: %w", err)
+		}
+	}
+
+	if gid >= 0 {
+		groups := [1]uint32{uint32(gid)}
+		err := sys(syscall.SYS_SETGROUPS, 1, uintptr(unsafe.Pointer(&groups[0])), 0)
+		runtime.KeepAlive(groups)
+		if err != nil {
+			return fmt.Errorf("unable to set the supplementary groups: %w", err)
+		}
+
+		if err := sys(syscall.SYS_SETGID, uintptr(gid), 0, 0); err != nil {
+			return fmt.Errorf("unable to set the group %d: %w", gid, err)
+		}
+	}
+
+	if uid >= 0 {
+		if err := sys(syscall.SYS_SETUID, uintptr(uid), 0, 0); err != nil {
+			return fmt.Errorf("unable to set the user %d: %w", uid, err)
+		}
+	}
+
+	if dropCapabilities {
+		if err := clearCapabilities(sys); err != nil {
+			return fmt.Errorf("unable to drop the capabilities: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// dropBoundingSet drops all the capabilities of the bounding set, and prevents the process from gaining privileges again.
+func dropBoundingSet(sys syscaller) error {
+	lastCap := 63
+	if content, err := ioutil.ReadFile(lastCapFile); err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(string(content))); err == nil {
+			lastCap = n
+		}
+	}
+
+	for c := 0; c <= lastCap; c++ {
+		if err := sys(syscall.SYS_PRCTL, prCapBSetDrop, uintptr(c), 0); err != nil {
+			// The capability is not supported by the kernel, or the process does not have the CAP_SETPCAP capability,
+			// such as an unprivileged process started with file capabilities, whose capabilities are still cleared afterwards.
+			if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.EPERM) {
+				break
+			}
+			return err
+		}
+	}
+
+	return sys(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0)
+}
+
+// clearCapabilities clears the effective, permitted, and inheritable capabilities.
+func clearCapabilities(sys syscaller) error {
+	header := &capHeader{version: linuxCapabilityVersion3}
+	data := &[2]capData{}
+
+	err := sys(syscall.SYS_CAPSET, uintptr(unsafe.Pointer(header)), uintptr(unsafe.Pointer(data)), 0)
+	runtime.KeepAlive(header)
+	runtime.KeepAlive(data)
+
+	return err
+}
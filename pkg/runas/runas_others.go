@@ -0,0 +1,9 @@
+// +build !linux
+
+package runas
+
+import "errors"
+
+func dropPrivileges(_ *Configuration, _, _ int) error {
+	return errors.New("dropping the privileges is not supported on this platform")
+}
@@ -0,0 +1,75 @@
+package runas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupCredential(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		user        string
+		group       string
+		expectedUID int
+		expectedGID int
+		expectedErr bool
+	}{
+		{
+			desc:        "nothing",
+			expectedUID: -1,
+			expectedGID: -1,
+		},
+		{
+			desc:        "user name",
+			user:        "root",
+			expectedUID: 0,
+			expectedGID: 0,
+		},
+		{
+			desc:        "user ID",
+			user:        "0",
+			expectedUID: 0,
+			expectedGID: 0,
+		},
+		{
+			desc:        "group only",
+			group:       "0",
+			expectedUID: -1,
+			expectedGID: 0,
+		},
+		{
+			desc:        "unknown user",
+			user:        "traefik-unknown-user",
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown group",
+			user:        "root",
+			group:       "traefik-unknown-group",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			uid, gid, err := lookupCredential(test.user, test.group)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedUID, uid)
+			assert.Equal(t, test.expectedGID, gid)
+		})
+	}
+}
+
+func TestApply_nil(t *testing.T) {
+	assert.NoError(t, Apply(nil))
+}
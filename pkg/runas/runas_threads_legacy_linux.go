@@ -0,0 +1,218 @@
+// +build !go1.16
+
+package runas
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// maxRounds bounds the rounds of goroutines started to reach all the threads of the process.
+const maxRounds = 10
+
+// credentialsFields are the fields of the status of a thread holding its credentials and capabilities.
+var credentialsFields = []string{"Uid:", "Gid:", "Groups:", "CapInh:", "CapPrm:", "CapEff:", "CapBnd:", "NoNewPrivs:"}
+
+// onAllThreads runs the prepare function once, then the system calls of the function on all the threads of the process.
+// Before Go 1.16, a system call cannot be run on all the threads by the runtime,
+// so the function is run on each thread by a goroutine locked on it, with per-thread system calls.
+// Once done, the credentials of all the threads are compared to the ones of the current thread,
+// and an error is returned if a thread could not be reached, such as an internal thread of the Go runtime,
+// so that the process never keeps running with privileged threads.
+func onAllThreads(prepare func() error, fn func(sys syscaller) error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	// The threads are listed from a directory opened before the preparation, which can change the root directory and hide the /proc file system.
+	tasks, err := syscall.Open("/proc/self/task", syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("unable to list the threads: %w", err)
+	}
+	defer func() { _ = syscall.Close(tasks) }()
+
+	if err = prepare(); err != nil {
+		return err
+	}
+
+	reached := map[int]struct{}{syscall.Gettid(): {}}
+	tids := make(chan int)
+	release := make(chan struct{})
+	errs := make(chan error, 1)
+
+	var wg sync.WaitGroup
+	for round := 0; round < maxRounds; round++ {
+		missing, err := unreachedThreads(tasks, reached)
+		if err != nil {
+			close(release)
+			wg.Wait()
+			return err
+		}
+
+		if missing == 0 {
+			break
+		}
+
+		// The goroutines stay locked on their threads until released, so that each of them reaches a different thread.
+		for i := 0; i < missing; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				runtime.LockOSThread()
+				tids <- syscall.Gettid()
+				<-release
+
+				if err := fn(threadSyscall); err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					// The thread is terminated with the goroutine still locked on it, as its privileges could not be dropped.
+					return
+				}
+
+				runtime.UnlockOSThread()
+			}()
+		}
+
+		for i := 0; i < missing; i++ {
+			reached[<-tids] = struct{}{}
+		}
+	}
+
+	err = fn(threadSyscall)
+	close(release)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err = <-errs:
+		return err
+	default:
+	}
+
+	return checkThreadsCredentials(tasks)
+}
+
+// threadSyscall runs the system call on the current thread only.
+func threadSyscall(trap, a1, a2, a3 uintptr) error {
+	_, _, errno := syscall.RawSyscall(trap, a1, a2, a3)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// unreachedThreads returns the number of threads of the process which have not been reached.
+func unreachedThreads(tasks int, reached map[int]struct{}) (int, error) {
+	tids, err := threads(tasks)
+	if err != nil {
+		return 0, err
+	}
+
+	var missing int
+	for _, tid := range tids {
+		if _, ok := reached[tid]; !ok {
+			missing++
+		}
+	}
+
+	return missing, nil
+}
+
+// checkThreadsCredentials returns an error if a thread of the process does not have the credentials of the current thread.
+func checkThreadsCredentials(tasks int) error {
+	expected, err := threadCredentials(tasks, syscall.Gettid())
+	if err != nil {
+		return err
+	}
+
+	tids, err := threads(tasks)
+	if err != nil {
+		return err
+	}
+
+	var privileged []string
+	for _, tid := range tids {
+		credentials, err := threadCredentials(tasks, tid)
+		if err != nil {
+			// The thread terminated meanwhile.
+			continue
+		}
+
+		if credentials != expected {
+			privileged = append(privileged, strconv.Itoa(tid))
+		}
+	}
+
+	if len(privileged) > 0 {
+		return fmt.Errorf("the privileges of the threads %s could not be dropped, which requires a build with Go 1.16 or later",
+			strings.Join(privileged, ", "))
+	}
+
+	return nil
+}
+
+// threads returns the IDs of the threads of the process, listed in the tasks directory.
+func threads(tasks int) ([]int, error) {
+	fd, err := syscall.Openat(tasks, ".", syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the threads: %w", err)
+	}
+
+	dir := os.NewFile(uintptr(fd), "task")
+	defer func() { _ = dir.Close() }()
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the threads: %w", err)
+	}
+
+	tids := make([]int, 0, len(names))
+	for _, name := range names {
+		tid, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		tids = append(tids, tid)
+	}
+
+	return tids, nil
+}
+
+// threadCredentials returns the credentials and capabilities of the thread, as given by its status in the tasks directory.
+func threadCredentials(tasks, tid int) (string, error) {
+	fd, err := syscall.Openat(tasks, strconv.Itoa(tid)+"/status", syscall.O_RDONLY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return "", err
+	}
+
+	file := os.NewFile(uintptr(fd), "status")
+	defer func() { _ = file.Close() }()
+
+	status, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	var credentials []string
+	for _, line := range strings.Split(string(status), "\n") {
+		for _, field := range credentialsFields {
+			if strings.HasPrefix(line, field) {
+				credentials = append(credentials, line)
+			}
+		}
+	}
+
+	return strings.Join(credentials, "\n"), nil
+}
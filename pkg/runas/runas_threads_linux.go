@@ -0,0 +1,30 @@
+// +build go1.16
+
+package runas
+
+import (
+	"errors"
+	"syscall"
+)
+
+// onAllThreads runs the prepare function once, then the system calls of the function on all the threads of the process.
+func onAllThreads(prepare func() error, fn func(sys syscaller) error) error {
+	if err := prepare(); err != nil {
+		return err
+	}
+
+	return fn(allThreadsSyscall)
+}
+
+// allThreadsSyscall runs the system call on all the threads of the process.
+func allThreadsSyscall(trap, a1, a2, a3 uintptr) error {
+	_, _, errno := syscall.AllThreadsSyscall(trap, a1, a2, a3)
+	if errno == syscall.ENOTSUP {
+		return errors.New("dropping the privileges requires a build without cgo")
+	}
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
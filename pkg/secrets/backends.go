@@ -0,0 +1,279 @@
+package secrets
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/privsep"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+
+	inClusterTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	inClusterCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// tokenFunc returns the token authenticating the requests of a backend.
+type tokenFunc func(ctx context.Context) (string, error)
+
+type envBackend struct{}
+
+func (envBackend) resolve(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s not defined", name)
+	}
+
+	return value, nil
+}
+
+type fileBackend struct{}
+
+func (fileBackend) resolve(_ context.Context, path string) (string, error) {
+	content, err := privsep.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r"), nil
+}
+
+// splitField splits a path#field reference.
+func splitField(ref string) (string, string, error) {
+	i := strings.LastIndex(ref, "#")
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", fmt.Errorf("%q is not in the path#field format", ref)
+	}
+
+	return ref[:i], ref[i+1:], nil
+}
+
+// vaultBackend reads the secrets of the KV secrets engines of a Vault server.
+type vaultBackend struct {
+	address   string
+	token     tokenFunc
+	namespace string
+	client    *http.Client
+}
+
+func newVaultBackend(config *Vault, resolveToken func(context.Context, string) (string, error)) (*vaultBackend, error) {
+	if config == nil {
+		config = &Vault{}
+	}
+
+	b := &vaultBackend{
+		address:   config.Address,
+		namespace: config.Namespace,
+		client:    &http.Client{Timeout: requestTimeout},
+	}
+	if b.address == "" {
+		b.address = os.Getenv("VAULT_ADDR")
+	}
+
+	token := config.Token
+	b.token = func(ctx context.Context) (string, error) {
+		if token == "" {
+			return os.Getenv("VAULT_TOKEN"), nil
+		}
+		return resolveToken(ctx, token)
+	}
+
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		b.client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
+
+	return b, nil
+}
+
+func (b *vaultBackend) resolve(ctx context.Context, ref string) (string, error) {
+	if b.address == "" {
+		return "", errors.New("no Vault server address")
+	}
+
+	path, field, err := splitField(ref)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := b.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	header := http.Header{}
+	header.Set("X-Vault-Token", token)
+	if b.namespace != "" {
+		header.Set("X-Vault-Namespace", b.namespace)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := getJSON(ctx, b.client, strings.TrimSuffix(b.address, "/")+"/v1/"+strings.TrimPrefix(path, "/"), header, &secret); err != nil {
+		return "", err
+	}
+
+	data := secret.Data
+	// The secrets of the KV version 2 engine are nested in the data field, along with their metadata.
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("no field %q in the secret %s", field, path)
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	return fmt.Sprint(value), nil
+}
+
+// kubernetesBackend reads the secrets of a Kubernetes cluster.
+type kubernetesBackend struct {
+	endpoint string
+	token    tokenFunc
+	client   *http.Client
+}
+
+func newKubernetesBackend(config *Kubernetes, resolveToken func(context.Context, string) (string, error)) (*kubernetesBackend, error) {
+	if config == nil {
+		config = &Kubernetes{}
+	}
+
+	b := &kubernetesBackend{
+		endpoint: config.Endpoint,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+
+	token := config.Token
+	caFile := config.CertAuthFilePath
+
+	if b.endpoint == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host != "" && port != "" {
+			b.endpoint = "https://" + net.JoinHostPort(host, port)
+			if token == "" {
+				token = SchemeFile + ":" + inClusterTokenFile
+			}
+			if caFile == "" {
+				caFile = inClusterCAFile
+			}
+		}
+	}
+
+	b.token = func(ctx context.Context) (string, error) {
+		return resolveToken(ctx, token)
+	}
+
+	if caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the certificate authority: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid certificate authority %s", caFile)
+		}
+
+		b.client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		}
+	}
+
+	return b, nil
+}
+
+func (b *kubernetesBackend) resolve(ctx context.Context, ref string) (string, error) {
+	if b.endpoint == "" {
+		return "", errors.New("no Kubernetes endpoint, and not running in a cluster")
+	}
+
+	path, key, err := splitField(ref)
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("%q is not in the namespace/name#key format", ref)
+	}
+
+	token, err := b.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	var secret struct {
+		Data map[string]string `json:"data"`
+	}
+	secretURL := fmt.Sprintf("%s/api/v1/namespaces/%s/secrets/%s", strings.TrimSuffix(b.endpoint, "/"), url.PathEscape(parts[0]), url.PathEscape(parts[1]))
+	if err := getJSON(ctx, b.client, secretURL, header, &secret); err != nil {
+		return "", err
+	}
+
+	encoded, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("no key %q in the secret %s", key, path)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid value of the key %q in the secret %s: %w", key, path, err)
+	}
+
+	return string(value), nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, target string, header http.Header, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header = header
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "root" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/v1/secret/traefik":
+			_, _ = fmt.Fprint(rw, `{"data":{"password":"v1-secret"}}`)
+		case "/v1/secret/data/traefik":
+			_, _ = fmt.Fprint(rw, `{"data":{"data":{"password":"v2-secret"},"metadata":{"version":3}}}`)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolver, err := NewResolver(&Configuration{
+		Vault: &Vault{Address: server.URL, Token: "root"},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		ref      string
+		expected string
+		wantErr  bool
+	}{
+		{
+			desc:     "KV version 1",
+			ref:      "vault:secret/traefik#password",
+			expected: "v1-secret",
+		},
+		{
+			desc:     "KV version 2",
+			ref:      "vault:secret/data/traefik#password",
+			expected: "v2-secret",
+		},
+		{
+			desc:    "unknown field",
+			ref:     "vault:secret/traefik#username",
+			wantErr: true,
+		},
+		{
+			desc:    "unknown secret",
+			ref:     "vault:secret/other#password",
+			wantErr: true,
+		},
+		{
+			desc:    "missing field",
+			ref:     "vault:secret/traefik",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			value, err := resolver.Resolve(context.Background(), test.ref)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestKubernetesBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if req.URL.Path != "/api/v1/namespaces/default/secrets/traefik" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(rw, `{"kind":"Secret","data":{"password":%q}}`, base64.StdEncoding.EncodeToString([]byte("k8s-secret")))
+	}))
+	defer server.Close()
+
+	resolver, err := NewResolver(&Configuration{
+		Kubernetes: &Kubernetes{Endpoint: server.URL, Token: "token"},
+	})
+	require.NoError(t, err)
+
+	value, err := resolver.Resolve(context.Background(), "k8s-secret:default/traefik#password")
+	require.NoError(t, err)
+	assert.Equal(t, "k8s-secret", value)
+
+	_, err = resolver.Resolve(context.Background(), "k8s-secret:default/traefik#username")
+	assert.Error(t, err)
+
+	_, err = resolver.Resolve(context.Background(), "k8s-secret:default/other#password")
+	assert.Error(t, err)
+
+	_, err = resolver.Resolve(context.Background(), "k8s-secret:traefik#password")
+	assert.Error(t, err)
+}
+
+func TestResolver_tokenReference(t *testing.T) {
+	resolver, err := NewResolver(&Configuration{
+		Vault: &Vault{Address: "http://127.0.0.1:8200", Token: "vault:secret/token#value"},
+	})
+	require.NoError(t, err)
+
+	_, err = resolver.Resolve(context.Background(), "vault:secret/traefik#password")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can only be an env: or file: secret reference")
+}
@@ -0,0 +1,212 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ResolveFields replaces the secret references of the fields tagged with secret:"true" of the element by their values.
+// The element must be a pointer, and the resolution stops at the first reference which cannot be resolved.
+func (r *Resolver) ResolveFields(ctx context.Context, element interface{}) error {
+	w := &fieldsWalker{ctx: ctx, resolver: r}
+	return w.walk(reflect.ValueOf(element), "", false)
+}
+
+// ResolveFieldsOrRemove replaces the secret references of the fields tagged with secret:"true" of the element by their values,
+// and removes from their maps and slices the structures holding references which cannot be resolved.
+// It returns the errors of the removed structures.
+func (r *Resolver) ResolveFieldsOrRemove(ctx context.Context, element interface{}) []error {
+	w := &fieldsWalker{ctx: ctx, resolver: r, remove: true}
+	if err := w.walk(reflect.ValueOf(element), "", false); err != nil {
+		w.errs = append(w.errs, err)
+	}
+
+	return w.errs
+}
+
+type fieldsWalker struct {
+	ctx      context.Context
+	resolver *Resolver
+	remove   bool
+	errs     []error
+}
+
+func (w *fieldsWalker) walk(v reflect.Value, path string, secret bool) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return w.walk(v.Elem(), path, secret)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			if err := w.walk(v.Field(i), joinPath(path, field.Name), field.Tag.Get("secret") == "true"); err != nil {
+				return err
+			}
+		}
+
+	case reflect.String:
+		if !secret || !IsReference(v.String()) {
+			return nil
+		}
+
+		value, err := w.resolver.Resolve(w.ctx, v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if !v.CanSet() {
+			return fmt.Errorf("%s: unable to set the secret", path)
+		}
+		v.SetString(value)
+
+	case reflect.Slice:
+		var kept []reflect.Value
+		var removed bool
+
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if err := w.walk(elem, fmt.Sprintf("%s[%d]", path, i), secret); err != nil {
+				if !w.remove || !isStructure(elem.Type()) {
+					return err
+				}
+
+				w.errs = append(w.errs, err)
+				removed = true
+				continue
+			}
+			kept = append(kept, elem)
+		}
+
+		if removed {
+			slice := reflect.MakeSlice(v.Type(), 0, len(kept))
+			v.Set(reflect.Append(slice, kept...))
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), secret); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// The values of a map are not addressable, so they are resolved in a copy, set back in the map.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+
+			if err := w.walk(elem, joinPath(path, fmt.Sprint(key.Interface())), secret); err != nil {
+				if !w.remove || !isStructure(elem.Type()) {
+					return err
+				}
+
+				w.errs = append(w.errs, err)
+				v.SetMapIndex(key, reflect.Value{})
+				continue
+			}
+
+			v.SetMapIndex(key, elem)
+		}
+	}
+
+	return nil
+}
+
+// isStructure returns whether the type is a structure, or a pointer to a structure.
+func isStructure(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ.Kind() == reflect.Struct
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return strings.Join([]string{path, name}, ".")
+}
+
+// References returns the secret references of the fields tagged with secret:"true" of the element.
+func References(element interface{}) []string {
+	var refs []string
+	walkSecrets(reflect.ValueOf(element), false, func(v reflect.Value) {
+		if IsReference(v.String()) {
+			refs = append(refs, v.String())
+		}
+	})
+
+	return refs
+}
+
+// Conceal replaces the values of the fields tagged with secret:"true" of the element,
+// which were resolved from the cached secret references, by these references.
+// The element must be a pointer, and is typically a copy of the configuration to expose.
+func (r *Resolver) Conceal(element interface{}) {
+	r.mu.RLock()
+	refs := make(map[string]string, len(r.cache))
+	for ref, value := range r.cache {
+		if value != "" {
+			refs[value] = ref
+		}
+	}
+	r.mu.RUnlock()
+
+	if len(refs) == 0 {
+		return
+	}
+
+	walkSecrets(reflect.ValueOf(element), false, func(v reflect.Value) {
+		if ref, ok := refs[v.String()]; ok && v.CanSet() {
+			v.SetString(ref)
+		}
+	})
+}
+
+// walkSecrets calls the function with the strings of the fields tagged with secret:"true" of the element.
+func walkSecrets(v reflect.Value, secret bool, fn func(v reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			walkSecrets(v.Elem(), secret, fn)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath == "" {
+				walkSecrets(v.Field(i), field.Tag.Get("secret") == "true", fn)
+			}
+		}
+
+	case reflect.String:
+		if secret {
+			fn(v)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkSecrets(v.Index(i), secret, fn)
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// The values of a map are not addressable, so they are walked in a copy, set back in the map.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			walkSecrets(elem, secret, fn)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_ResolveFields(t *testing.T) {
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_USER", "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"))
+	defer func() { _ = os.Unsetenv("TRAEFIK_TEST_USER") }()
+
+	resolver, err := NewResolver(nil)
+	require.NoError(t, err)
+
+	conf := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"auth": {
+					BasicAuth: &dynamic.BasicAuth{
+						Users: []string{"env:TRAEFIK_TEST_USER", "test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0"},
+						Realm: "env:TRAEFIK_TEST_USER",
+					},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, resolver.ResolveFields(context.Background(), conf))
+
+	basicAuth := conf.HTTP.Middlewares["auth"].BasicAuth
+	assert.Equal(t, dynamic.Users{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/", "test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0"}, basicAuth.Users)
+	// The fields which are not tagged as secrets are not resolved.
+	assert.Equal(t, "env:TRAEFIK_TEST_USER", basicAuth.Realm)
+
+	conf.HTTP.Middlewares["auth"].BasicAuth.Users = dynamic.Users{"env:TRAEFIK_TEST_UNDEFINED"}
+	err = resolver.ResolveFields(context.Background(), conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HTTP.Middlewares.auth.BasicAuth.Users[0]")
+}
+
+func TestResolver_ResolveFieldsOrRemove(t *testing.T) {
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_KEY", "key"))
+	defer func() { _ = os.Unsetenv("TRAEFIK_TEST_KEY") }()
+
+	resolver, err := NewResolver(nil)
+	require.NoError(t, err)
+
+	conf := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"resolved": {
+					BasicAuth: &dynamic.BasicAuth{Users: []string{"env:TRAEFIK_TEST_KEY"}},
+				},
+				"unresolved": {
+					BasicAuth: &dynamic.BasicAuth{Users: []string{"env:TRAEFIK_TEST_UNDEFINED"}},
+				},
+			},
+		},
+		TLS: &dynamic.TLSConfiguration{
+			Certificates: []*tls.CertAndStores{
+				{Certificate: tls.Certificate{CertFile: "cert.pem", KeyFile: "env:TRAEFIK_TEST_UNDEFINED"}},
+				{Certificate: tls.Certificate{CertFile: "other.pem", KeyFile: "env:TRAEFIK_TEST_KEY"}},
+			},
+		},
+	}
+
+	errs := resolver.ResolveFieldsOrRemove(context.Background(), conf)
+	assert.Len(t, errs, 2)
+
+	require.Contains(t, conf.HTTP.Middlewares, "resolved")
+	assert.NotContains(t, conf.HTTP.Middlewares, "unresolved")
+	assert.Equal(t, dynamic.Users{"key"}, conf.HTTP.Middlewares["resolved"].BasicAuth.Users)
+
+	require.Len(t, conf.TLS.Certificates, 1)
+	assert.Equal(t, tls.FileOrContent("other.pem"), conf.TLS.Certificates[0].CertFile)
+	assert.Equal(t, tls.FileOrContent("key"), conf.TLS.Certificates[0].KeyFile)
+}
+
+func TestReferences(t *testing.T) {
+	conf := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"auth": {
+					BasicAuth: &dynamic.BasicAuth{Users: []string{"env:TRAEFIK_TEST_KEY", "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"}},
+				},
+				"headers": {
+					Headers: &dynamic.Headers{CustomRequestHeaders: map[string]string{"X-Test": "env:TRAEFIK_TEST_OTHER"}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"env:TRAEFIK_TEST_KEY"}, References(conf))
+}
+
+func TestResolver_Conceal(t *testing.T) {
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_KEY", "key"))
+	defer func() { _ = os.Unsetenv("TRAEFIK_TEST_KEY") }()
+
+	resolver, err := NewResolver(nil)
+	require.NoError(t, err)
+
+	conf := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"auth": {
+					BasicAuth: &dynamic.BasicAuth{Users: []string{"env:TRAEFIK_TEST_KEY", "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"}},
+				},
+				"headers": {
+					Headers: &dynamic.Headers{CustomRequestHeaders: map[string]string{"X-Test": "key"}},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, resolver.ResolveFields(context.Background(), conf))
+	assert.Equal(t, dynamic.Users{"key", "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"}, conf.HTTP.Middlewares["auth"].BasicAuth.Users)
+
+	resolver.Conceal(conf)
+	assert.Equal(t, dynamic.Users{"env:TRAEFIK_TEST_KEY", "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"}, conf.HTTP.Middlewares["auth"].BasicAuth.Users)
+	// Only the secret fields are concealed.
+	assert.Equal(t, "key", conf.HTTP.Middlewares["headers"].Headers.CustomRequestHeaders["X-Test"])
+}
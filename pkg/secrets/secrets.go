@@ -0,0 +1,301 @@
+// Package secrets resolves the secret references of the configuration,
+// so that the passwords, keys, and tokens are read from the environment, files, Vault, or Kubernetes secrets,
+// instead of being written in the configuration.
+//
+// A secret reference is a value in one of these forms:
+//
+//	env:NAME                        the NAME environment variable
+//	file:/path/to/file              the content of the file, without its trailing newline
+//	vault:path/to/secret#field      the field of the secret of a Vault KV secrets engine (version 1 or 2)
+//	k8s-secret:namespace/name#key   the key of a Kubernetes secret
+//
+// Only the configuration fields tagged with secret:"true" are resolved,
+// and, in the dynamic configuration, only for the providers allowed to hold secret references.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// Schemes of the secret references.
+const (
+	SchemeEnv        = "env"
+	SchemeFile       = "file"
+	SchemeVault      = "vault"
+	SchemeKubernetes = "k8s-secret"
+)
+
+// Configuration holds the configuration of the resolution of the secret references.
+type Configuration struct {
+	RefreshInterval types.Duration `description:"Interval at which the secret references are resolved again, to apply their rotation to the dynamic configuration." json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+	Providers       []string       `description:"Providers whose dynamic configuration can hold secret references, the file provider only by default." json:"providers,omitempty" toml:"providers,omitempty" yaml:"providers,omitempty" export:"true"`
+	Environment     []string       `description:"Environment variables whose value is a secret reference, resolved at startup, such as the credentials of the ACME DNS challenge providers." json:"environment,omitempty" toml:"environment,omitempty" yaml:"environment,omitempty" export:"true"`
+	Vault           *Vault         `description:"Vault server of the vault: secret references." json:"vault,omitempty" toml:"vault,omitempty" yaml:"vault,omitempty" export:"true"`
+	Kubernetes      *Kubernetes    `description:"Kubernetes cluster of the k8s-secret: secret references." json:"kubernetes,omitempty" toml:"kubernetes,omitempty" yaml:"kubernetes,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Configuration) SetDefaults() {
+	c.RefreshInterval = types.Duration(time.Minute)
+	c.Providers = []string{"file"}
+}
+
+// Vault holds the configuration of the Vault server.
+type Vault struct {
+	Address   string           `description:"Address of the Vault server. If empty, the VAULT_ADDR environment variable is used." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty" export:"true"`
+	Token     string           `description:"Vault token, which can be an env: or file: secret reference. If empty, the VAULT_TOKEN environment variable is used." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty"`
+	Namespace string           `description:"Vault namespace (Vault Enterprise)." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	TLS       *types.ClientTLS `description:"TLS configuration of the connections to the Vault server." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+}
+
+// Kubernetes holds the configuration of the Kubernetes cluster.
+type Kubernetes struct {
+	Endpoint         string `description:"Kubernetes server endpoint (not needed for in-cluster client)." json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty" export:"true"`
+	Token            string `description:"Kubernetes bearer token (not needed for in-cluster client), which can be an env: or file: secret reference." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty"`
+	CertAuthFilePath string `description:"Kubernetes certificate authority file path (not needed for in-cluster client)." json:"certAuthFilePath,omitempty" toml:"certAuthFilePath,omitempty" yaml:"certAuthFilePath,omitempty" export:"true"`
+}
+
+type backend interface {
+	// resolve returns the value of the secret of the reference, without its scheme.
+	resolve(ctx context.Context, ref string) (string, error)
+}
+
+// IsReference returns whether the value is a secret reference.
+func IsReference(value string) bool {
+	scheme, _ := splitReference(value)
+	return scheme != ""
+}
+
+func splitReference(value string) (string, string) {
+	for _, scheme := range []string{SchemeEnv, SchemeFile, SchemeVault, SchemeKubernetes} {
+		if strings.HasPrefix(value, scheme+":") {
+			return scheme, strings.TrimPrefix(value, scheme+":")
+		}
+	}
+
+	return "", value
+}
+
+// Resolver resolves the secret references, and caches their values.
+// It resolves the cached references again periodically, and calls its listeners when their values change.
+type Resolver struct {
+	backends        map[string]backend
+	refreshInterval time.Duration
+	providers       map[string]struct{}
+
+	mu        sync.RWMutex
+	cache     map[string]string
+	listeners []func()
+}
+
+// NewResolver creates a resolver of the secret references.
+func NewResolver(config *Configuration) (*Resolver, error) {
+	if config == nil {
+		config = &Configuration{}
+		config.SetDefaults()
+	}
+
+	r := &Resolver{
+		backends: map[string]backend{
+			SchemeEnv:  envBackend{},
+			SchemeFile: fileBackend{},
+		},
+		refreshInterval: time.Duration(config.RefreshInterval),
+		providers:       make(map[string]struct{}, len(config.Providers)),
+		cache:           make(map[string]string),
+	}
+
+	for _, name := range config.Providers {
+		r.providers[name] = struct{}{}
+	}
+
+	// The tokens of the Vault and Kubernetes backends can only be resolved by the environment and file backends.
+	resolveToken := func(ctx context.Context, token string) (string, error) {
+		switch scheme, _ := splitReference(token); scheme {
+		case "":
+			return token, nil
+		case SchemeEnv, SchemeFile:
+			return r.Resolve(ctx, token)
+		default:
+			return "", fmt.Errorf("the token %q can only be an env: or file: secret reference", token)
+		}
+	}
+
+	vault, err := newVaultBackend(config.Vault, resolveToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Vault configuration: %w", err)
+	}
+
+	kubernetes, err := newKubernetesBackend(config.Kubernetes, resolveToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes configuration: %w", err)
+	}
+
+	r.backends[SchemeVault] = vault
+	r.backends[SchemeKubernetes] = kubernetes
+
+	return r, nil
+}
+
+// AddListener adds a listener called when the value of a cached secret reference changes.
+func (r *Resolver) AddListener(listener func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.listeners = append(r.listeners, listener)
+}
+
+// AllowsProvider returns whether the dynamic configuration of the provider can hold secret references.
+// The secret references of the other providers are not resolved,
+// so that the sources of configuration which can be written by the workloads, such as the container labels, cannot read the secrets.
+func (r *Resolver) AllowsProvider(name string) bool {
+	_, ok := r.providers[name]
+	return ok
+}
+
+// Resolve returns the value of the secret reference.
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	r.mu.RLock()
+	value, ok := r.cache[ref]
+	r.mu.RUnlock()
+
+	if ok {
+		return value, nil
+	}
+
+	value, err := r.resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.cache[ref] = value
+	r.mu.Unlock()
+
+	return value, nil
+}
+
+func (r *Resolver) resolve(ctx context.Context, ref string) (string, error) {
+	scheme, path := splitReference(ref)
+
+	b, ok := r.backends[scheme]
+	if !ok {
+		return "", fmt.Errorf("invalid secret reference %q", ref)
+	}
+
+	value, err := b.resolve(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve the secret %q: %w", ref, err)
+	}
+
+	return value, nil
+}
+
+// ResolveEnvironment replaces the secret references of the environment variables by their values.
+func (r *Resolver) ResolveEnvironment(ctx context.Context, names []string) error {
+	for _, name := range names {
+		ref, ok := os.LookupEnv(name)
+		if !ok || !IsReference(ref) {
+			continue
+		}
+
+		value, err := r.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("environment variable %s: %w", name, err)
+		}
+
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Retain removes from the cache the secret references which are not in the given ones,
+// so that the references no longer used by the configuration are not resolved again.
+func (r *Resolver) Retain(refs []string) {
+	retained := make(map[string]struct{}, len(refs))
+	for _, ref := range refs {
+		retained[ref] = struct{}{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for ref := range r.cache {
+		if _, ok := retained[ref]; !ok {
+			delete(r.cache, ref)
+		}
+	}
+}
+
+// Run resolves the cached secret references again at each refresh interval, until the context is done.
+func (r *Resolver) Run(ctx context.Context) {
+	if r.refreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.refresh(ctx)
+		}
+	}
+}
+
+// refresh resolves the cached secret references again, and calls the listeners when at least one of their values changed.
+// The cached value of a reference which cannot be resolved is kept.
+func (r *Resolver) refresh(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	r.mu.RLock()
+	refs := make([]string, 0, len(r.cache))
+	for ref := range r.cache {
+		refs = append(refs, ref)
+	}
+	r.mu.RUnlock()
+
+	var changed bool
+	for _, ref := range refs {
+		value, err := r.resolve(ctx, ref)
+		if err != nil {
+			logger.Errorf("Unable to refresh the secret, keeping its current value: %v", err)
+			continue
+		}
+
+		r.mu.Lock()
+		// The reference could have been removed from the cache while it was resolved again.
+		if current, ok := r.cache[ref]; ok && current != value {
+			logger.Infof("The secret %q changed", ref)
+			r.cache[ref] = value
+			changed = true
+		}
+		r.mu.Unlock()
+	}
+
+	if !changed {
+		return
+	}
+
+	r.mu.RLock()
+	listeners := r.listeners
+	r.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener()
+	}
+}
@@ -0,0 +1,191 @@
+package secrets
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsReference(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "env:PASSWORD", expected: true},
+		{value: "file:/run/secrets/password", expected: true},
+		{value: "vault:secret/data/traefik#password", expected: true},
+		{value: "k8s-secret:default/traefik#password", expected: true},
+		{value: "password", expected: false},
+		{value: "user:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/", expected: false},
+		{value: "", expected: false},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, IsReference(test.value))
+		})
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	secretFile := filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(secretFile, []byte("file-secret\n"), 0600))
+
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_SECRET", "env-secret"))
+	defer func() { _ = os.Unsetenv("TRAEFIK_TEST_SECRET") }()
+
+	resolver, err := NewResolver(nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		ref      string
+		expected string
+		wantErr  bool
+	}{
+		{
+			desc:     "environment variable",
+			ref:      "env:TRAEFIK_TEST_SECRET",
+			expected: "env-secret",
+		},
+		{
+			desc:     "file",
+			ref:      "file:" + secretFile,
+			expected: "file-secret",
+		},
+		{
+			desc:    "undefined environment variable",
+			ref:     "env:TRAEFIK_TEST_UNDEFINED",
+			wantErr: true,
+		},
+		{
+			desc:    "missing file",
+			ref:     "file:" + filepath.Join(dir, "missing"),
+			wantErr: true,
+		},
+		{
+			desc:    "not a reference",
+			ref:     "password",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			value, err := resolver.Resolve(context.Background(), test.ref)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestResolver_refresh(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	secretFile := filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(secretFile, []byte("first"), 0600))
+
+	resolver, err := NewResolver(nil)
+	require.NoError(t, err)
+
+	var calls int
+	resolver.AddListener(func() { calls++ })
+
+	value, err := resolver.Resolve(context.Background(), "file:"+secretFile)
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+
+	// The listeners are not called while the values do not change.
+	resolver.refresh(context.Background())
+	assert.Equal(t, 0, calls)
+
+	require.NoError(t, ioutil.WriteFile(secretFile, []byte("second"), 0600))
+	resolver.refresh(context.Background())
+	assert.Equal(t, 1, calls)
+
+	value, err = resolver.Resolve(context.Background(), "file:"+secretFile)
+	require.NoError(t, err)
+	assert.Equal(t, "second", value)
+
+	// The current value is kept when the reference cannot be resolved anymore.
+	require.NoError(t, os.Remove(secretFile))
+	resolver.refresh(context.Background())
+	assert.Equal(t, 1, calls)
+
+	value, err = resolver.Resolve(context.Background(), "file:"+secretFile)
+	require.NoError(t, err)
+	assert.Equal(t, "second", value)
+}
+
+func TestResolver_Retain(t *testing.T) {
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_KEY", "key"))
+	defer func() { _ = os.Unsetenv("TRAEFIK_TEST_KEY") }()
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_OTHER", "other"))
+	defer func() { _ = os.Unsetenv("TRAEFIK_TEST_OTHER") }()
+
+	resolver, err := NewResolver(nil)
+	require.NoError(t, err)
+
+	_, err = resolver.Resolve(context.Background(), "env:TRAEFIK_TEST_KEY")
+	require.NoError(t, err)
+	_, err = resolver.Resolve(context.Background(), "env:TRAEFIK_TEST_OTHER")
+	require.NoError(t, err)
+
+	resolver.Retain([]string{"env:TRAEFIK_TEST_KEY"})
+
+	assert.Equal(t, map[string]string{"env:TRAEFIK_TEST_KEY": "key"}, resolver.cache)
+}
+
+func TestResolver_AllowsProvider(t *testing.T) {
+	resolver, err := NewResolver(nil)
+	require.NoError(t, err)
+
+	assert.True(t, resolver.AllowsProvider("file"))
+	assert.False(t, resolver.AllowsProvider("docker"))
+
+	resolver, err = NewResolver(&Configuration{Providers: []string{"kubernetescrd"}})
+	require.NoError(t, err)
+
+	assert.True(t, resolver.AllowsProvider("kubernetescrd"))
+	assert.False(t, resolver.AllowsProvider("file"))
+}
+
+func TestResolver_ResolveEnvironment(t *testing.T) {
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_SECRET", "env-secret"))
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_DNS_TOKEN", "env:TRAEFIK_TEST_SECRET"))
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_DNS_EMAIL", "admin@example.com"))
+	defer func() {
+		_ = os.Unsetenv("TRAEFIK_TEST_SECRET")
+		_ = os.Unsetenv("TRAEFIK_TEST_DNS_TOKEN")
+		_ = os.Unsetenv("TRAEFIK_TEST_DNS_EMAIL")
+	}()
+
+	resolver, err := NewResolver(nil)
+	require.NoError(t, err)
+
+	err = resolver.ResolveEnvironment(context.Background(), []string{"TRAEFIK_TEST_DNS_TOKEN", "TRAEFIK_TEST_DNS_EMAIL", "TRAEFIK_TEST_UNDEFINED"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "env-secret", os.Getenv("TRAEFIK_TEST_DNS_TOKEN"))
+	assert.Equal(t, "admin@example.com", os.Getenv("TRAEFIK_TEST_DNS_EMAIL"))
+}
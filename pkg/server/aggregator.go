@@ -1,6 +1,9 @@
 package server
 
 import (
+	"context"
+	"strings"
+
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/server/provider"
@@ -32,6 +35,8 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 	var defaultTLSOptionProviders []string
 	var defaultTLSStoreProviders []string
 	for pvd, configuration := range configurations {
+		configuration = expandApplications(pvd, configuration)
+
 		if configuration.HTTP != nil {
 			for routerName, router := range configuration.HTTP.Routers {
 				if len(router.EntryPoints) == 0 {
@@ -91,7 +96,7 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 					defaultTLSOptionProviders = append(defaultTLSOptionProviders, pvd)
 				}
 
-				conf.TLS.Options[tlsOptionsName] = options
+				conf.TLS.Options[tlsOptionsName] = qualifySNIOptions(pvd, options)
 			}
 		}
 	}
@@ -156,3 +161,120 @@ func applyModel(cfg dynamic.Configuration) dynamic.Configuration {
 
 	return cfg
 }
+
+// applyEntryPointOverrides splits the routers having entry point overrides into one router per overridden entry point,
+// with the middlewares and the TLS options specific to this entry point.
+// The router keeps its name on its other entry points, and is named after the entry point otherwise.
+func applyEntryPointOverrides(cfg dynamic.Configuration) dynamic.Configuration {
+	if cfg.HTTP == nil {
+		return cfg
+	}
+
+	rts := make(map[string]*dynamic.Router)
+
+	for name, rt := range cfg.HTTP.Routers {
+		if len(rt.EntryPointOverrides) == 0 {
+			rts[name] = rt
+			continue
+		}
+
+		logger := log.WithoutContext().WithField(log.RouterName, name)
+
+		for epName := range rt.EntryPointOverrides {
+			if !containsString(rt.EntryPoints, epName) {
+				logger.Warnf("Ignoring the overrides of the entryPoint %q, which is not an entryPoint of the router", epName)
+			}
+		}
+
+		router := rt.DeepCopy()
+		router.EntryPoints = nil
+		router.EntryPointOverrides = nil
+
+		for _, epName := range rt.EntryPoints {
+			override := rt.EntryPointOverrides[epName]
+			if override == nil {
+				router.EntryPoints = append(router.EntryPoints, epName)
+				rts[name] = router
+				continue
+			}
+
+			cp := router.DeepCopy()
+			cp.EntryPoints = []string{epName}
+
+			if len(override.Middlewares) > 0 {
+				cp.Middlewares = override.Middlewares
+			}
+			cp.Middlewares = excludeMiddlewares(name, cp.Middlewares, override.ExcludedMiddlewares)
+
+			if override.TLSOptions != "" {
+				if cp.TLS != nil {
+					cp.TLS.Options = override.TLSOptions
+				} else {
+					logger.Warnf("Ignoring the TLS options of the entryPoint %q, the router does not use TLS", epName)
+				}
+			}
+
+			rtName := name
+			if len(rt.EntryPoints) > 1 {
+				rtName = epName + "-" + name
+			}
+			rts[rtName] = cp
+		}
+	}
+
+	cfg.HTTP.Routers = rts
+
+	return cfg
+}
+
+// excludeMiddlewares returns the middlewares of the router, without the excluded ones.
+// The names are compared once qualified with the provider of the router.
+func excludeMiddlewares(routerName string, middlewares, excluded []string) []string {
+	if len(excluded) == 0 {
+		return middlewares
+	}
+
+	ctx := provider.AddInContext(context.Background(), routerName)
+
+	var excludedNames []string
+	for _, name := range excluded {
+		excludedNames = append(excludedNames, provider.GetQualifiedName(ctx, name))
+	}
+
+	var kept []string
+	for _, name := range middlewares {
+		if !containsString(excludedNames, provider.GetQualifiedName(ctx, name)) {
+			kept = append(kept, name)
+		}
+	}
+
+	return kept
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifySNIOptions qualifies the names of the TLS options selected by server name with the provider name,
+// unless they are already qualified.
+func qualifySNIOptions(pvd string, options tls.Options) tls.Options {
+	if len(options.SNIOptions) == 0 {
+		return options
+	}
+
+	sniOptions := make([]tls.SNIOptions, len(options.SNIOptions))
+	for i, sni := range options.SNIOptions {
+		sniOptions[i] = sni
+		if sni.Options != "default" && !strings.Contains(sni.Options, "@") {
+			sniOptions[i].Options = provider.MakeQualifiedName(pvd, sni.Options)
+		}
+	}
+	options.SNIOptions = sniOptions
+
+	return options
+}
@@ -152,6 +152,37 @@ func Test_mergeConfiguration_tlsOptions(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "Qualifies the TLS options selected by server name",
+			given: dynamic.Configurations{
+				"provider-1": &dynamic.Configuration{
+					TLS: &dynamic.TLSConfiguration{
+						Options: map[string]tls.Options{
+							"default": {
+								SNIOptions: []tls.SNIOptions{
+									{Hosts: []string{"admin.*"}, Options: "mtls"},
+									{Hosts: []string{"api.*"}, Options: "foo@provider-2"},
+								},
+							},
+							"mtls": {
+								MinVersion: "VersionTLS13",
+							},
+						},
+					},
+				},
+			},
+			expected: map[string]tls.Options{
+				"default": {
+					SNIOptions: []tls.SNIOptions{
+						{Hosts: []string{"admin.*"}, Options: "mtls@provider-1"},
+						{Hosts: []string{"api.*"}, Options: "foo@provider-2"},
+					},
+				},
+				"mtls@provider-1": {
+					MinVersion: "VersionTLS13",
+				},
+			},
+		},
 		{
 			desc: "Returns fully qualified elements from a multi-provider configuration map",
 			given: dynamic.Configurations{
@@ -589,3 +620,115 @@ func Test_applyModel(t *testing.T) {
 		})
 	}
 }
+
+func Test_applyEntryPointOverrides(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		routers  map[string]*dynamic.Router
+		expected map[string]*dynamic.Router
+	}{
+		{
+			desc: "without overrides",
+			routers: map[string]*dynamic.Router{
+				"test@file": {EntryPoints: []string{"web", "internal"}, Middlewares: []string{"auth"}},
+			},
+			expected: map[string]*dynamic.Router{
+				"test@file": {EntryPoints: []string{"web", "internal"}, Middlewares: []string{"auth"}},
+			},
+		},
+		{
+			desc: "excluded middlewares",
+			routers: map[string]*dynamic.Router{
+				"test@file": {
+					EntryPoints: []string{"web", "internal"},
+					Middlewares: []string{"auth", "compress@file"},
+					EntryPointOverrides: map[string]*dynamic.RouterEntryPointOverride{
+						"internal": {ExcludedMiddlewares: []string{"auth@file", "compress"}},
+					},
+				},
+			},
+			expected: map[string]*dynamic.Router{
+				"test@file": {
+					EntryPoints: []string{"web"},
+					Middlewares: []string{"auth", "compress@file"},
+				},
+				"internal-test@file": {
+					EntryPoints: []string{"internal"},
+				},
+			},
+		},
+		{
+			desc: "replaced middlewares and TLS options",
+			routers: map[string]*dynamic.Router{
+				"test@file": {
+					EntryPoints: []string{"websecure", "internal"},
+					Middlewares: []string{"auth"},
+					TLS:         &dynamic.RouterTLSConfig{Options: "modern"},
+					EntryPointOverrides: map[string]*dynamic.RouterEntryPointOverride{
+						"internal": {Middlewares: []string{"headers", "auth"}, ExcludedMiddlewares: []string{"auth"}, TLSOptions: "mtls"},
+					},
+				},
+			},
+			expected: map[string]*dynamic.Router{
+				"test@file": {
+					EntryPoints: []string{"websecure"},
+					Middlewares: []string{"auth"},
+					TLS:         &dynamic.RouterTLSConfig{Options: "modern"},
+				},
+				"internal-test@file": {
+					EntryPoints: []string{"internal"},
+					Middlewares: []string{"headers"},
+					TLS:         &dynamic.RouterTLSConfig{Options: "mtls"},
+				},
+			},
+		},
+		{
+			desc: "single overridden entry point",
+			routers: map[string]*dynamic.Router{
+				"test@file": {
+					EntryPoints: []string{"internal"},
+					Middlewares: []string{"auth"},
+					EntryPointOverrides: map[string]*dynamic.RouterEntryPointOverride{
+						"internal": {Middlewares: []string{"headers"}},
+						"unknown":  {Middlewares: []string{"auth"}},
+					},
+				},
+			},
+			expected: map[string]*dynamic.Router{
+				"test@file": {
+					EntryPoints: []string{"internal"},
+					Middlewares: []string{"headers"},
+				},
+			},
+		},
+		{
+			desc: "TLS options of a router without TLS",
+			routers: map[string]*dynamic.Router{
+				"test@file": {
+					EntryPoints: []string{"internal"},
+					EntryPointOverrides: map[string]*dynamic.RouterEntryPointOverride{
+						"internal": {TLSOptions: "mtls"},
+					},
+				},
+			},
+			expected: map[string]*dynamic.Router{
+				"test@file": {
+					EntryPoints: []string{"internal"},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			actual := applyEntryPointOverrides(dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{Routers: test.routers},
+			})
+
+			assert.Equal(t, test.expected, actual.HTTP.Routers)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tls"
+)
+
+// applicationTLSProfiles are the TLS options of the TLS profiles of the applications,
+// declared as the TLS options named applicationTLSOptionsPrefix followed by the profile, unless already declared.
+var applicationTLSProfiles = map[string]tls.Options{
+	"intermediate": {
+		MinVersion: "VersionTLS12",
+		CipherSuites: []string{
+			"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+			"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+			"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+			"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+			"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305",
+			"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305",
+		},
+	},
+	"modern": {
+		MinVersion: "VersionTLS13",
+	},
+}
+
+const (
+	applicationTLSOptionsPrefix = "application-"
+	applicationAuthSuffix       = "-auth"
+)
+
+// expandApplications returns the configuration of the provider along with the routers, services, middlewares, and TLS options
+// derived from its applications.
+// An application is skipped if it is invalid, or if any of the elements derived from it is already declared.
+func expandApplications(pvd string, configuration *dynamic.Configuration) *dynamic.Configuration {
+	if configuration.HTTP == nil || len(configuration.HTTP.Applications) == 0 {
+		return configuration
+	}
+
+	expanded := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers:     make(map[string]*dynamic.Router),
+			Services:    make(map[string]*dynamic.Service),
+			Middlewares: make(map[string]*dynamic.Middleware),
+			Models:      configuration.HTTP.Models,
+		},
+		TCP: configuration.TCP,
+		UDP: configuration.UDP,
+		TLS: &dynamic.TLSConfiguration{
+			Options: make(map[string]tls.Options),
+		},
+	}
+
+	for name, router := range configuration.HTTP.Routers {
+		expanded.HTTP.Routers[name] = router
+	}
+	for name, service := range configuration.HTTP.Services {
+		expanded.HTTP.Services[name] = service
+	}
+	for name, middleware := range configuration.HTTP.Middlewares {
+		expanded.HTTP.Middlewares[name] = middleware
+	}
+
+	if configuration.TLS != nil {
+		expanded.TLS.Certificates = configuration.TLS.Certificates
+		expanded.TLS.Stores = configuration.TLS.Stores
+		for name, options := range configuration.TLS.Options {
+			expanded.TLS.Options[name] = options
+		}
+	}
+
+	names := make([]string, 0, len(configuration.HTTP.Applications))
+	for name := range configuration.HTTP.Applications {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := expandApplication(expanded, name, configuration.HTTP.Applications[name]); err != nil {
+			log.WithoutContext().
+				WithField(log.ProviderName, pvd).
+				WithField(log.RouterName, name).
+				Errorf("Skipping the application: %v", err)
+		}
+	}
+
+	return expanded
+}
+
+// expandApplication adds the router, the service, the authentication middleware, and the TLS options of the application to the configuration.
+func expandApplication(configuration *dynamic.Configuration, name string, app *dynamic.Application) error {
+	if app == nil {
+		return errors.New("empty application")
+	}
+
+	if len(app.Hosts) == 0 {
+		return errors.New("no hosts defined")
+	}
+
+	if len(app.Servers) == 0 {
+		return errors.New("no servers defined")
+	}
+
+	if _, ok := configuration.HTTP.Routers[name]; ok {
+		return fmt.Errorf("the router %s is already declared", name)
+	}
+
+	if _, ok := configuration.HTTP.Services[name]; ok {
+		return fmt.Errorf("the service %s is already declared", name)
+	}
+
+	router := &dynamic.Router{
+		EntryPoints: app.EntryPoints,
+		Service:     name,
+		Rule:        applicationRule(app),
+		Priority:    app.Priority,
+	}
+
+	var auth *dynamic.Middleware
+	if app.Auth != nil {
+		switch {
+		case app.Auth.BasicAuth != nil && app.Auth.ForwardAuth != nil:
+			return errors.New("basicAuth and forwardAuth are mutually exclusive")
+		case app.Auth.BasicAuth != nil:
+			auth = &dynamic.Middleware{BasicAuth: app.Auth.BasicAuth}
+		case app.Auth.ForwardAuth != nil:
+			auth = &dynamic.Middleware{ForwardAuth: app.Auth.ForwardAuth}
+		}
+	}
+
+	if auth != nil {
+		authName := name + applicationAuthSuffix
+		if _, ok := configuration.HTTP.Middlewares[authName]; ok {
+			return fmt.Errorf("the middleware %s is already declared", authName)
+		}
+
+		configuration.HTTP.Middlewares[authName] = auth
+		router.Middlewares = append(router.Middlewares, authName)
+	}
+
+	router.Middlewares = append(router.Middlewares, app.Middlewares...)
+
+	if app.TLS != nil {
+		router.TLS = &dynamic.RouterTLSConfig{CertResolver: app.TLS.CertResolver}
+
+		if app.TLS.Profile != "" {
+			options, ok := applicationTLSProfiles[app.TLS.Profile]
+			if !ok {
+				return fmt.Errorf("unknown TLS profile: %s", app.TLS.Profile)
+			}
+
+			optionsName := applicationTLSOptionsPrefix + app.TLS.Profile
+			if _, ok := configuration.TLS.Options[optionsName]; !ok {
+				configuration.TLS.Options[optionsName] = options
+			}
+
+			router.TLS.Options = optionsName
+		}
+	}
+
+	loadBalancer := &dynamic.ServersLoadBalancer{HealthCheck: app.HealthCheck}
+	loadBalancer.SetDefaults()
+	for _, url := range app.Servers {
+		loadBalancer.Servers = append(loadBalancer.Servers, dynamic.Server{URL: url})
+	}
+
+	configuration.HTTP.Routers[name] = router
+	configuration.HTTP.Services[name] = &dynamic.Service{LoadBalancer: loadBalancer}
+
+	return nil
+}
+
+// applicationRule returns the rule matching the hosts, and the path prefix, of the application.
+func applicationRule(app *dynamic.Application) string {
+	hosts := make([]string, 0, len(app.Hosts))
+	for _, host := range app.Hosts {
+		hosts = append(hosts, "`"+host+"`")
+	}
+
+	rule := fmt.Sprintf("Host(%s)", strings.Join(hosts, ", "))
+	if app.PathPrefix != "" {
+		rule += fmt.Sprintf(" && PathPrefix(`%s`)", app.PathPrefix)
+	}
+
+	return rule
+}
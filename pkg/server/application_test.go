@@ -0,0 +1,237 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_expandApplications(t *testing.T) {
+	passHostHeader := true
+
+	testCases := []struct {
+		desc     string
+		given    *dynamic.Configuration
+		expected *dynamic.Configuration
+	}{
+		{
+			desc: "no applications",
+			given: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{"foo": {}},
+				},
+			},
+			expected: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{"foo": {}},
+				},
+			},
+		},
+		{
+			desc: "minimal application",
+			given: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Applications: map[string]*dynamic.Application{
+						"app": {
+							Hosts:   []string{"foo.bar"},
+							Servers: []string{"http://10.0.0.1"},
+						},
+					},
+				},
+			},
+			expected: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"app": {
+							Service: "app",
+							Rule:    "Host(`foo.bar`)",
+						},
+					},
+					Services: map[string]*dynamic.Service{
+						"app": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								PassHostHeader: &passHostHeader,
+								Servers:        []dynamic.Server{{URL: "http://10.0.0.1"}},
+							},
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+				},
+				TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{},
+				},
+			},
+		},
+		{
+			desc: "full application",
+			given: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"foo": {Rule: "Host(`foo`)"},
+					},
+					Applications: map[string]*dynamic.Application{
+						"app": {
+							EntryPoints: []string{"websecure"},
+							Hosts:       []string{"foo.bar", "bar.foo"},
+							PathPrefix:  "/api",
+							Servers:     []string{"http://10.0.0.1", "http://10.0.0.2"},
+							HealthCheck: &dynamic.HealthCheck{Path: "/health"},
+							Auth: &dynamic.ApplicationAuth{
+								BasicAuth: &dynamic.BasicAuth{Users: []string{"test:hash"}},
+							},
+							TLS: &dynamic.ApplicationTLS{
+								Profile:      "modern",
+								CertResolver: "le",
+							},
+							Middlewares: []string{"compress"},
+							Priority:    42,
+						},
+					},
+				},
+			},
+			expected: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"foo": {Rule: "Host(`foo`)"},
+						"app": {
+							EntryPoints: []string{"websecure"},
+							Middlewares: []string{"app-auth", "compress"},
+							Service:     "app",
+							Rule:        "Host(`foo.bar`, `bar.foo`) && PathPrefix(`/api`)",
+							Priority:    42,
+							TLS: &dynamic.RouterTLSConfig{
+								Options:      "application-modern",
+								CertResolver: "le",
+							},
+						},
+					},
+					Services: map[string]*dynamic.Service{
+						"app": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								PassHostHeader: &passHostHeader,
+								HealthCheck:    &dynamic.HealthCheck{Path: "/health"},
+								Servers: []dynamic.Server{
+									{URL: "http://10.0.0.1"},
+									{URL: "http://10.0.0.2"},
+								},
+							},
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{
+						"app-auth": {
+							BasicAuth: &dynamic.BasicAuth{Users: []string{"test:hash"}},
+						},
+					},
+				},
+				TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{
+						"application-modern": {MinVersion: "VersionTLS13"},
+					},
+				},
+			},
+		},
+		{
+			desc: "declared TLS options of the profile are kept",
+			given: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Applications: map[string]*dynamic.Application{
+						"app": {
+							Hosts:   []string{"foo.bar"},
+							Servers: []string{"http://10.0.0.1"},
+							TLS:     &dynamic.ApplicationTLS{Profile: "intermediate"},
+						},
+					},
+				},
+				TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{
+						"application-intermediate": {MinVersion: "VersionTLS11"},
+					},
+				},
+			},
+			expected: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"app": {
+							Service: "app",
+							Rule:    "Host(`foo.bar`)",
+							TLS:     &dynamic.RouterTLSConfig{Options: "application-intermediate"},
+						},
+					},
+					Services: map[string]*dynamic.Service{
+						"app": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								PassHostHeader: &passHostHeader,
+								Servers:        []dynamic.Server{{URL: "http://10.0.0.1"}},
+							},
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+				},
+				TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{
+						"application-intermediate": {MinVersion: "VersionTLS11"},
+					},
+				},
+			},
+		},
+		{
+			desc: "invalid applications are skipped",
+			given: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"declared": {Rule: "Host(`foo`)"},
+					},
+					Applications: map[string]*dynamic.Application{
+						"declared": {
+							Hosts:   []string{"foo.bar"},
+							Servers: []string{"http://10.0.0.1"},
+						},
+						"no-hosts": {
+							Servers: []string{"http://10.0.0.1"},
+						},
+						"no-servers": {
+							Hosts: []string{"foo.bar"},
+						},
+						"two-auths": {
+							Hosts:   []string{"foo.bar"},
+							Servers: []string{"http://10.0.0.1"},
+							Auth: &dynamic.ApplicationAuth{
+								BasicAuth:   &dynamic.BasicAuth{},
+								ForwardAuth: &dynamic.ForwardAuth{},
+							},
+						},
+						"unknown-profile": {
+							Hosts:   []string{"foo.bar"},
+							Servers: []string{"http://10.0.0.1"},
+							TLS:     &dynamic.ApplicationTLS{Profile: "old"},
+						},
+					},
+				},
+			},
+			expected: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"declared": {Rule: "Host(`foo`)"},
+					},
+					Services:    map[string]*dynamic.Service{},
+					Middlewares: map[string]*dynamic.Middleware{},
+				},
+				TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			actual := expandApplications("provider", test.given)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -8,8 +8,10 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/provider"
 	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/secrets"
 	"github.com/eapache/channels"
 	"github.com/sirupsen/logrus"
 )
@@ -30,6 +32,13 @@ type ConfigurationWatcher struct {
 
 	configurationListeners []func(dynamic.Configuration)
 
+	secretsResolver    *secrets.Resolver
+	secretsRotatedChan chan struct{}
+
+	metricsRegistry metrics.Registry
+	// lagging is whether the configuration messages of the providers are queuing up, faster than they are processed.
+	lagging bool
+
 	routinesPool *safe.Pool
 }
 
@@ -45,6 +54,8 @@ func NewConfigurationWatcher(
 		configurationChan:          make(chan dynamic.Message, 100),
 		configurationValidatedChan: make(chan dynamic.Message, 100),
 		providerConfigUpdateMap:    make(map[string]chan dynamic.Message),
+		secretsRotatedChan:         make(chan struct{}, 1),
+		metricsRegistry:            metrics.NewVoidRegistry(),
 		providersThrottleDuration:  providersThrottleDuration,
 		routinesPool:               routinesPool,
 		defaultEntryPoints:         defaultEntryPoints,
@@ -77,6 +88,23 @@ func (c *ConfigurationWatcher) AddListener(listener func(dynamic.Configuration))
 	c.configurationListeners = append(c.configurationListeners, listener)
 }
 
+// SetSecretsResolver sets the resolver of the secret references of the dynamic configuration.
+// The configuration is applied again when the values of the references change.
+func (c *ConfigurationWatcher) SetSecretsResolver(resolver *secrets.Resolver) {
+	c.secretsResolver = resolver
+	resolver.AddListener(func() {
+		select {
+		case c.secretsRotatedChan <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// SetMetricsRegistry sets the registry of the metrics of the configuration messages queues and of their application.
+func (c *ConfigurationWatcher) SetMetricsRegistry(registry metrics.Registry) {
+	c.metricsRegistry = registry
+}
+
 func (c *ConfigurationWatcher) startProvider() {
 	logger := log.WithoutContext()
 
@@ -109,6 +137,8 @@ func (c *ConfigurationWatcher) listenProviders(ctx context.Context) {
 				return
 			}
 
+			c.observeProvidersQueue()
+
 			if configMsg.Configuration == nil {
 				log.WithoutContext().WithField(log.ProviderName, configMsg.ProviderName).
 					Debug("Received nil configuration from provider, skipping.")
@@ -129,7 +159,11 @@ func (c *ConfigurationWatcher) listenConfigurations(ctx context.Context) {
 			if !ok || configMsg.Configuration == nil {
 				return
 			}
+			c.metricsRegistry.ConfigQueueDepthGauge().With("queue", "validated").Set(float64(len(c.configurationValidatedChan)))
 			c.loadMessage(configMsg)
+		case <-c.secretsRotatedChan:
+			log.WithoutContext().Info("Applying the configuration again, as secrets changed")
+			c.applyConfigurations(c.currentConfigurations.Get().(dynamic.Configurations))
 		}
 	}
 }
@@ -143,7 +177,25 @@ func (c *ConfigurationWatcher) loadMessage(configMsg dynamic.Message) {
 
 	c.currentConfigurations.Set(newConfigurations)
 
-	conf := mergeConfiguration(newConfigurations, c.defaultEntryPoints)
+	c.applyConfigurations(newConfigurations)
+}
+
+func (c *ConfigurationWatcher) applyConfigurations(configurations dynamic.Configurations) {
+	if len(configurations) == 0 {
+		return
+	}
+
+	start := time.Now()
+	defer func() {
+		c.metricsRegistry.ConfigApplyDurationHistogram().Observe(time.Since(start).Seconds())
+	}()
+
+	if c.secretsResolver != nil {
+		configurations = c.resolveSecrets(configurations)
+	}
+
+	conf := mergeConfiguration(configurations, c.defaultEntryPoints)
+	conf = applyEntryPointOverrides(conf)
 	conf = applyModel(conf)
 
 	for _, listener := range c.configurationListeners {
@@ -151,6 +203,34 @@ func (c *ConfigurationWatcher) loadMessage(configMsg dynamic.Message) {
 	}
 }
 
+// resolveSecrets returns the configurations with the secret references of the allowed providers resolved,
+// and removes from the cache of the resolver the references which are no longer used.
+// The configurations of the providers are kept with their secret references, so that they can be resolved again.
+func (c *ConfigurationWatcher) resolveSecrets(configurations dynamic.Configurations) dynamic.Configurations {
+	resolved := make(dynamic.Configurations, len(configurations))
+
+	var refs []string
+	for providerName, configuration := range configurations {
+		if configuration == nil || !c.secretsResolver.AllowsProvider(providerName) {
+			resolved[providerName] = configuration
+			continue
+		}
+
+		refs = append(refs, secrets.References(configuration)...)
+
+		conf := configuration.DeepCopy()
+		for _, err := range c.secretsResolver.ResolveFieldsOrRemove(context.Background(), conf) {
+			log.WithoutContext().WithField(log.ProviderName, providerName).
+				Errorf("Unable to resolve the secrets of the configuration, removing the element holding them: %v", err)
+		}
+		resolved[providerName] = conf
+	}
+
+	c.secretsResolver.Retain(refs)
+
+	return resolved
+}
+
 func (c *ConfigurationWatcher) preLoadConfiguration(configMsg dynamic.Message) {
 	logger := log.WithoutContext().WithField(log.ProviderName, configMsg.ProviderName)
 	if log.GetLevel() == logrus.DebugLevel {
@@ -176,6 +256,7 @@ func (c *ConfigurationWatcher) preLoadConfiguration(configMsg dynamic.Message) {
 
 	if isEmptyConfiguration(configMsg.Configuration) {
 		logger.Infof("Skipping empty Configuration for provider %s", configMsg.ProviderName)
+		c.metricsRegistry.ConfigMessagesDroppedCounter().With("provider", configMsg.ProviderName, "reason", "empty").Add(1)
 		return
 	}
 
@@ -222,14 +303,39 @@ func (c *ConfigurationWatcher) throttleProviderConfigReload(ctx context.Context,
 			if reflect.DeepEqual(previousConfig, nextConfig) {
 				logger := log.WithoutContext().WithField(log.ProviderName, nextConfig.ProviderName)
 				logger.Info("Skipping same configuration")
+				c.metricsRegistry.ConfigMessagesDroppedCounter().With("provider", nextConfig.ProviderName, "reason", "unchanged").Add(1)
 				continue
 			}
 			previousConfig = nextConfig
+
+			// The pending configuration, not published yet because of the throttling, is replaced by the next one.
+			if ring.Len() > 0 {
+				c.metricsRegistry.ConfigMessagesDroppedCounter().With("provider", nextConfig.ProviderName, "reason", "merged").Add(1)
+			}
 			ring.In() <- nextConfig
 		}
 	}
 }
 
+// observeProvidersQueue updates the depth of the queue of the configuration messages of the providers,
+// and logs when the messages start, or stop, queuing up faster than they are processed.
+func (c *ConfigurationWatcher) observeProvidersQueue() {
+	depth := len(c.configurationChan)
+	c.metricsRegistry.ConfigQueueDepthGauge().With("queue", "providers").Set(float64(depth))
+
+	lagging := depth >= cap(c.configurationChan)/2
+	if lagging == c.lagging {
+		return
+	}
+	c.lagging = lagging
+
+	if lagging {
+		log.WithoutContext().Warnf("The configuration applies are lagging behind the providers events: %d messages are waiting to be processed", depth)
+	} else {
+		log.WithoutContext().Info("The configuration applies caught up with the providers events")
+	}
+}
+
 func isEmptyConfiguration(conf *dynamic.Configuration) bool {
 	if conf == nil {
 		return true
@@ -3,15 +3,24 @@ package server
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/secrets"
 	th "github.com/containous/traefik/v2/pkg/testhelpers"
 	"github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/types"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockProvider struct {
@@ -301,3 +310,189 @@ func TestListenProvidersPublishesConfigForEachProvider(t *testing.T) {
 
 	assert.Equal(t, expected, publishedProviderConfig)
 }
+
+func TestListenProvidersResolvesSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	usersFile := filepath.Join(dir, "users")
+	require.NoError(t, ioutil.WriteFile(usersFile, []byte("test:first"), 0600))
+
+	routinesPool := safe.NewPool(context.Background())
+	defer routinesPool.Stop()
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{
+			{
+				ProviderName: "mock",
+				Configuration: &dynamic.Configuration{
+					HTTP: th.BuildConfiguration(
+						th.WithMiddlewares(
+							th.WithMiddleware("resolved", th.WithBasicAuth(&dynamic.BasicAuth{Users: []string{"file:" + usersFile}})),
+							th.WithMiddleware("unresolved", th.WithBasicAuth(&dynamic.BasicAuth{Users: []string{"env:TRAEFIK_TEST_UNDEFINED"}})),
+						),
+					),
+				},
+			},
+			{
+				ProviderName: "untrusted",
+				Configuration: &dynamic.Configuration{
+					HTTP: th.BuildConfiguration(
+						th.WithMiddlewares(
+							th.WithMiddleware("stolen", th.WithBasicAuth(&dynamic.BasicAuth{Users: []string{"file:" + usersFile}})),
+						),
+					),
+				},
+			},
+		},
+	}
+
+	resolver, err := secrets.NewResolver(&secrets.Configuration{
+		RefreshInterval: types.Duration(20 * time.Millisecond),
+		Providers:       []string{"mock"},
+	})
+	require.NoError(t, err)
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, 0, []string{})
+	watcher.SetSecretsResolver(resolver)
+
+	published := make(chan dynamic.Configuration, 10)
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		published <- conf
+	})
+
+	watcher.Start()
+	defer watcher.Stop()
+
+	routinesPool.GoCtx(resolver.Run)
+
+	conf := <-published
+	for len(conf.HTTP.Middlewares) < 2 {
+		conf = <-published
+	}
+
+	assert.NotContains(t, conf.HTTP.Middlewares, "unresolved@mock")
+	require.Contains(t, conf.HTTP.Middlewares, "resolved@mock")
+	assert.Equal(t, dynamic.Users{"test:first"}, conf.HTTP.Middlewares["resolved@mock"].BasicAuth.Users)
+
+	// The secret references of the providers which are not allowed are not resolved.
+	require.Contains(t, conf.HTTP.Middlewares, "stolen@untrusted")
+	assert.Equal(t, dynamic.Users{"file:" + usersFile}, conf.HTTP.Middlewares["stolen@untrusted"].BasicAuth.Users)
+
+	// The configuration is applied again when the secret is rotated.
+	require.NoError(t, ioutil.WriteFile(usersFile, []byte("test:second"), 0600))
+
+	select {
+	case conf = <-published:
+		assert.Equal(t, dynamic.Users{"test:second"}, conf.HTTP.Middlewares["resolved@mock"].BasicAuth.Users)
+	case <-time.After(time.Second):
+		t.Fatal("The configuration was not applied again after the rotation of the secret")
+	}
+}
+
+// droppedMessagesRegistry counts the dropped configuration messages by reason.
+type droppedMessagesRegistry struct {
+	metrics.Registry
+
+	mu      sync.Mutex
+	dropped map[string]float64
+	applies int
+}
+
+func (r *droppedMessagesRegistry) ConfigMessagesDroppedCounter() gokitmetrics.Counter {
+	return &droppedMessagesCounter{registry: r}
+}
+
+func (r *droppedMessagesRegistry) ConfigApplyDurationHistogram() gokitmetrics.Histogram {
+	return &applyDurationHistogram{registry: r}
+}
+
+type droppedMessagesCounter struct {
+	registry *droppedMessagesRegistry
+	reason   string
+}
+
+func (c *droppedMessagesCounter) With(labelValues ...string) gokitmetrics.Counter {
+	for i := 0; i+1 < len(labelValues); i += 2 {
+		if labelValues[i] == "reason" {
+			return &droppedMessagesCounter{registry: c.registry, reason: labelValues[i+1]}
+		}
+	}
+	return c
+}
+
+func (c *droppedMessagesCounter) Add(delta float64) {
+	c.registry.mu.Lock()
+	defer c.registry.mu.Unlock()
+
+	c.registry.dropped[c.reason] += delta
+}
+
+type applyDurationHistogram struct {
+	registry *droppedMessagesRegistry
+}
+
+func (h *applyDurationHistogram) With(_ ...string) gokitmetrics.Histogram {
+	return h
+}
+
+func (h *applyDurationHistogram) Observe(_ float64) {
+	h.registry.mu.Lock()
+	defer h.registry.mu.Unlock()
+
+	h.registry.applies++
+}
+
+func TestListenProvidersMetrics(t *testing.T) {
+	routinesPool := safe.NewPool(context.Background())
+
+	newMessage := func(router string) dynamic.Message {
+		return dynamic.Message{
+			ProviderName: "mock",
+			Configuration: &dynamic.Configuration{
+				HTTP: th.BuildConfiguration(
+					th.WithRouters(th.WithRouter(router)),
+					th.WithLoadBalancerServices(th.WithService("bar")),
+				),
+			},
+		}
+	}
+
+	message := newMessage("foo")
+
+	pvd := &mockProvider{
+		wait: time.Millisecond,
+		messages: []dynamic.Message{
+			message,
+			// Skipped, as it is the same as the previous one.
+			message,
+			{ProviderName: "mock", Configuration: &dynamic.Configuration{}},
+			// Throttled, then replaced by the next one before being published.
+			newMessage("foo1"),
+			newMessage("foo2"),
+		},
+	}
+
+	registry := &droppedMessagesRegistry{
+		Registry: metrics.NewVoidRegistry(),
+		dropped:  make(map[string]float64),
+	}
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, 200*time.Millisecond, []string{})
+	watcher.SetMetricsRegistry(registry)
+	watcher.Start()
+	defer watcher.Stop()
+
+	assert.Eventually(t, func() bool {
+		registry.mu.Lock()
+		defer registry.mu.Unlock()
+
+		return registry.applies == 2
+	}, time.Second, 10*time.Millisecond)
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	assert.Equal(t, map[string]float64{"unchanged": 1, "empty": 1, "merged": 1}, registry.dropped)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+)
+
+var (
+	errTooManyConnections = errors.New("too many connections")
+	errQueueTimeout       = errors.New("timeout while queued")
+)
+
+// connectionTable tracks the active connections of an entry point by client IP,
+// and enforces the connection limit of the entry point, if any.
+type connectionTable struct {
+	maxPerIP     int
+	queue        bool
+	queueTimeout time.Duration
+
+	lock sync.Mutex
+	ips  map[string]*ipConnections
+}
+
+type ipConnections struct {
+	count int
+	// waiters are the queued connections, in arrival order.
+	// A waiter is closed when a slot is handed over to it.
+	waiters []chan struct{}
+}
+
+func newConnectionTable(config *static.ConnectionLimit) (*connectionTable, error) {
+	table := &connectionTable{ips: make(map[string]*ipConnections)}
+	if config == nil {
+		return table, nil
+	}
+
+	if config.MaxPerIP < 0 {
+		return nil, fmt.Errorf("invalid maximum number of connections per IP: %d", config.MaxPerIP)
+	}
+
+	switch config.Overflow {
+	case "", static.OverflowReject:
+	case static.OverflowQueue:
+		table.queue = true
+	default:
+		return nil, fmt.Errorf("invalid overflow action: %s", config.Overflow)
+	}
+
+	table.maxPerIP = config.MaxPerIP
+	table.queueTimeout = time.Duration(config.QueueTimeout)
+
+	return table, nil
+}
+
+// acquire registers a connection of the client IP.
+// Over the limit, it fails right away, or waits for a connection of the client IP to be released,
+// depending on the overflow action.
+func (t *connectionTable) acquire(ip string) error {
+	t.lock.Lock()
+
+	conns, ok := t.ips[ip]
+	if !ok {
+		conns = &ipConnections{}
+		t.ips[ip] = conns
+	}
+
+	if t.maxPerIP == 0 || conns.count < t.maxPerIP {
+		conns.count++
+		t.lock.Unlock()
+		return nil
+	}
+
+	if !t.queue {
+		t.lock.Unlock()
+		return errTooManyConnections
+	}
+
+	ready := make(chan struct{})
+	conns.waiters = append(conns.waiters, ready)
+	t.lock.Unlock()
+
+	timer := time.NewTimer(t.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		return nil
+	case <-timer.C:
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// The slot may have been handed over while the lock was released.
+	select {
+	case <-ready:
+		return nil
+	default:
+	}
+
+	for i, waiter := range conns.waiters {
+		if waiter == ready {
+			conns.waiters = append(conns.waiters[:i], conns.waiters[i+1:]...)
+			break
+		}
+	}
+
+	return errQueueTimeout
+}
+
+// release unregisters a connection of the client IP,
+// and hands its slot over to the first queued connection of the client IP, if any.
+func (t *connectionTable) release(ip string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	conns, ok := t.ips[ip]
+	if !ok {
+		return
+	}
+
+	if len(conns.waiters) > 0 {
+		close(conns.waiters[0])
+		conns.waiters = conns.waiters[1:]
+		return
+	}
+
+	conns.count--
+	if conns.count <= 0 {
+		delete(t.ips, ip)
+	}
+}
+
+// connections returns the number of active connections of each client IP.
+func (t *connectionTable) connections() map[string]int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	connections := make(map[string]int, len(t.ips))
+	for ip, conns := range t.ips {
+		connections[ip] = conns.count
+	}
+
+	return connections
+}
+
+// clientIP returns the IP of a remote address, or the address itself if it has no port.
+func clientIP(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConnectionTable(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      *static.ConnectionLimit
+		expectedErr bool
+	}{
+		{
+			desc: "no limit",
+		},
+		{
+			desc:   "reject",
+			config: &static.ConnectionLimit{MaxPerIP: 1, Overflow: static.OverflowReject},
+		},
+		{
+			desc:   "queue",
+			config: &static.ConnectionLimit{MaxPerIP: 1, Overflow: static.OverflowQueue},
+		},
+		{
+			desc:        "invalid overflow action",
+			config:      &static.ConnectionLimit{MaxPerIP: 1, Overflow: "foo"},
+			expectedErr: true,
+		},
+		{
+			desc:        "negative maximum",
+			config:      &static.ConnectionLimit{MaxPerIP: -1},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newConnectionTable(test.config)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestConnectionTable_noLimit(t *testing.T) {
+	table, err := newConnectionTable(nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, table.acquire("10.0.0.1"))
+	}
+	require.NoError(t, table.acquire("10.0.0.2"))
+
+	assert.Equal(t, map[string]int{"10.0.0.1": 3, "10.0.0.2": 1}, table.connections())
+
+	table.release("10.0.0.1")
+	table.release("10.0.0.2")
+
+	assert.Equal(t, map[string]int{"10.0.0.1": 2}, table.connections())
+}
+
+func TestConnectionTable_reject(t *testing.T) {
+	table, err := newConnectionTable(&static.ConnectionLimit{MaxPerIP: 2, Overflow: static.OverflowReject})
+	require.NoError(t, err)
+
+	require.NoError(t, table.acquire("10.0.0.1"))
+	require.NoError(t, table.acquire("10.0.0.1"))
+	assert.Equal(t, errTooManyConnections, table.acquire("10.0.0.1"))
+
+	// The other client IPs are not affected.
+	require.NoError(t, table.acquire("10.0.0.2"))
+
+	table.release("10.0.0.1")
+	require.NoError(t, table.acquire("10.0.0.1"))
+
+	assert.Equal(t, map[string]int{"10.0.0.1": 2, "10.0.0.2": 1}, table.connections())
+}
+
+func TestConnectionTable_queue(t *testing.T) {
+	table, err := newConnectionTable(&static.ConnectionLimit{
+		MaxPerIP:     1,
+		Overflow:     static.OverflowQueue,
+		QueueTimeout: types.Duration(5 * time.Second),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, table.acquire("10.0.0.1"))
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- table.acquire("10.0.0.1") }()
+
+	select {
+	case <-acquired:
+		t.Fatal("connection acquired over the limit")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	table.release("10.0.0.1")
+
+	select {
+	case err := <-acquired:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued connection not acquired")
+	}
+
+	assert.Equal(t, map[string]int{"10.0.0.1": 1}, table.connections())
+
+	table.release("10.0.0.1")
+
+	assert.Empty(t, table.connections())
+}
+
+func TestConnectionTable_queueTimeout(t *testing.T) {
+	table, err := newConnectionTable(&static.ConnectionLimit{
+		MaxPerIP:     1,
+		Overflow:     static.OverflowQueue,
+		QueueTimeout: types.Duration(50 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, table.acquire("10.0.0.1"))
+	assert.Equal(t, errQueueTimeout, table.acquire("10.0.0.1"))
+
+	// The timed out connection does not get the released slot.
+	table.release("10.0.0.1")
+	assert.Empty(t, table.connections())
+}
+
+func TestClientIP(t *testing.T) {
+	assert.Equal(t, "10.0.0.1", clientIP(&net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8080}))
+	assert.Equal(t, "::1", clientIP(&net.TCPAddr{IP: net.ParseIP("::1"), Port: 8080}))
+	assert.Equal(t, "", clientIP(nil))
+}
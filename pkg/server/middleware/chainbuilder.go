@@ -9,6 +9,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
 	metricsmiddleware "github.com/containous/traefik/v2/pkg/middlewares/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares/pathstats"
 	"github.com/containous/traefik/v2/pkg/middlewares/requestdecorator"
 	mTracing "github.com/containous/traefik/v2/pkg/middlewares/tracing"
 	"github.com/containous/traefik/v2/pkg/tracing"
@@ -21,16 +22,23 @@ type ChainBuilder struct {
 	accessLoggerMiddleware *accesslog.Handler
 	tracer                 *tracing.Tracing
 	requestDecorator       *requestdecorator.RequestDecorator
+	pathStatistics         *pathstats.Statistics
 }
 
 // NewChainBuilder Creates a new ChainBuilder.
 func NewChainBuilder(staticConfiguration static.Configuration, metricsRegistry metrics.Registry, accessLoggerMiddleware *accesslog.Handler) *ChainBuilder {
-	return &ChainBuilder{
+	builder := &ChainBuilder{
 		metricsRegistry:        metricsRegistry,
 		accessLoggerMiddleware: accessLoggerMiddleware,
 		tracer:                 setupTracing(staticConfiguration.Tracing),
 		requestDecorator:       requestdecorator.New(staticConfiguration.HostResolver),
 	}
+
+	if staticConfiguration.API != nil && staticConfiguration.API.PathStatistics != nil {
+		builder.pathStatistics = pathstats.NewStatistics(staticConfiguration.API.PathStatistics.MaxPaths)
+	}
+
+	return builder
 }
 
 // Build a middleware chain by entry point.
@@ -52,6 +60,26 @@ func (c *ChainBuilder) Build(ctx context.Context, entryPointName string) alice.C
 	return chain.Append(requestdecorator.WrapHandler(c.requestDecorator))
 }
 
+// BuildRouter builds a middleware chain by router.
+func (c *ChainBuilder) BuildRouter(ctx context.Context, routerName string) alice.Chain {
+	chain := alice.New()
+
+	if c.metricsRegistry != nil && c.metricsRegistry.IsRouterEnabled() {
+		chain = chain.Append(metricsmiddleware.WrapRouterHandler(ctx, c.metricsRegistry, routerName))
+	}
+
+	if c.pathStatistics != nil {
+		chain = chain.Append(pathstats.WrapRouterHandler(ctx, c.pathStatistics, routerName))
+	}
+
+	return chain
+}
+
+// PathStatistics returns the path statistics of the routers, or nil when they are disabled.
+func (c *ChainBuilder) PathStatistics() *pathstats.Statistics {
+	return c.pathStatistics
+}
+
 // Close accessLogger and tracer.
 func (c *ChainBuilder) Close() {
 	if c.accessLoggerMiddleware != nil {
@@ -9,25 +9,47 @@ import (
 
 	"github.com/containous/alice"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/gossip"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares/adaptiveconcurrency"
 	"github.com/containous/traefik/v2/pkg/middlewares/addprefix"
+	"github.com/containous/traefik/v2/pkg/middlewares/admissioncontrol"
+	"github.com/containous/traefik/v2/pkg/middlewares/altsvc"
+	"github.com/containous/traefik/v2/pkg/middlewares/anomaly"
+	"github.com/containous/traefik/v2/pkg/middlewares/apikey"
 	"github.com/containous/traefik/v2/pkg/middlewares/auth"
+	"github.com/containous/traefik/v2/pkg/middlewares/authorization"
+	"github.com/containous/traefik/v2/pkg/middlewares/awssigv4"
+	"github.com/containous/traefik/v2/pkg/middlewares/bandwidthlimit"
+	"github.com/containous/traefik/v2/pkg/middlewares/botmanagement"
 	"github.com/containous/traefik/v2/pkg/middlewares/buffering"
 	"github.com/containous/traefik/v2/pkg/middlewares/chain"
 	"github.com/containous/traefik/v2/pkg/middlewares/circuitbreaker"
 	"github.com/containous/traefik/v2/pkg/middlewares/compress"
+	"github.com/containous/traefik/v2/pkg/middlewares/cspnonce"
 	"github.com/containous/traefik/v2/pkg/middlewares/customerrors"
+	"github.com/containous/traefik/v2/pkg/middlewares/earlyhints"
+	"github.com/containous/traefik/v2/pkg/middlewares/experiment"
+	"github.com/containous/traefik/v2/pkg/middlewares/faultinjection"
 	"github.com/containous/traefik/v2/pkg/middlewares/headers"
+	"github.com/containous/traefik/v2/pkg/middlewares/honeypot"
 	"github.com/containous/traefik/v2/pkg/middlewares/inflightreq"
 	"github.com/containous/traefik/v2/pkg/middlewares/ipwhitelist"
+	"github.com/containous/traefik/v2/pkg/middlewares/methodoverride"
+	"github.com/containous/traefik/v2/pkg/middlewares/oauth2clientcredentials"
 	"github.com/containous/traefik/v2/pkg/middlewares/passtlsclientcert"
 	"github.com/containous/traefik/v2/pkg/middlewares/ratelimiter"
 	"github.com/containous/traefik/v2/pkg/middlewares/redirect"
+	"github.com/containous/traefik/v2/pkg/middlewares/redirectmap"
 	"github.com/containous/traefik/v2/pkg/middlewares/replacepath"
 	"github.com/containous/traefik/v2/pkg/middlewares/replacepathregex"
 	"github.com/containous/traefik/v2/pkg/middlewares/retry"
+	"github.com/containous/traefik/v2/pkg/middlewares/schedule"
+	"github.com/containous/traefik/v2/pkg/middlewares/shadow"
 	"github.com/containous/traefik/v2/pkg/middlewares/stripprefix"
 	"github.com/containous/traefik/v2/pkg/middlewares/stripprefixregex"
 	"github.com/containous/traefik/v2/pkg/middlewares/tracing"
+	"github.com/containous/traefik/v2/pkg/middlewares/wellknown"
 	"github.com/containous/traefik/v2/pkg/server/provider"
 )
 
@@ -39,8 +61,12 @@ const (
 
 // Builder the middleware builder
 type Builder struct {
-	configs        map[string]*runtime.MiddlewareInfo
-	serviceBuilder serviceBuilder
+	configs         map[string]*runtime.MiddlewareInfo
+	serviceBuilder  serviceBuilder
+	metricsRegistry metrics.Registry
+
+	allowFaultInjection bool
+	sharedState         *gossip.State
 }
 
 type serviceBuilder interface {
@@ -48,8 +74,19 @@ type serviceBuilder interface {
 }
 
 // NewBuilder creates a new Builder
-func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder}
+func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, metricsRegistry metrics.Registry) *Builder {
+	return &Builder{configs: configs, serviceBuilder: serviceBuilder, metricsRegistry: metricsRegistry}
+}
+
+// SetFaultInjectionAllowed defines whether the faultInjection middlewares can be built.
+// They are rejected by default, so that faults are not injected in production by mistake.
+func (b *Builder) SetFaultInjectionAllowed(allowed bool) {
+	b.allowFaultInjection = allowed
+}
+
+// SetSharedState defines the state shared with the other instances by the rate limiter, honeypot, and circuit breaker middlewares.
+func (b *Builder) SetSharedState(state *gossip.State) {
+	b.sharedState = state
 }
 
 // BuildChain creates a middleware chain
@@ -109,6 +146,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 	var middleware alice.Constructor
 	badConf := errors.New("cannot create middleware: multi-types middleware not supported, consider declaring two different pieces of middleware instead")
 
+	// AdaptiveConcurrency
+	if config.AdaptiveConcurrency != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return adaptiveconcurrency.New(ctx, next, *config.AdaptiveConcurrency, middlewareName)
+		}
+	}
+
 	// AddPrefix
 	if config.AddPrefix != nil {
 		middleware = func(next http.Handler) (http.Handler, error) {
@@ -116,6 +163,76 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// AdmissionControl
+	if config.AdmissionControl != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return admissioncontrol.New(ctx, next, *config.AdmissionControl, middlewareName)
+		}
+	}
+
+	// AltSvc
+	if config.AltSvc != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return altsvc.New(ctx, next, *config.AltSvc, middlewareName)
+		}
+	}
+
+	// Anomaly
+	if config.Anomaly != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return anomaly.New(ctx, next, *config.Anomaly, middlewareName)
+		}
+	}
+
+	// APIKey
+	if config.APIKey != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return apikey.New(ctx, next, *config.APIKey, middlewareName)
+		}
+	}
+
+	// Authorization
+	if config.Authorization != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return authorization.New(ctx, next, *config.Authorization, middlewareName)
+		}
+	}
+
+	// AWSSigV4
+	if config.AWSSigV4 != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return awssigv4.New(ctx, next, *config.AWSSigV4, middlewareName)
+		}
+	}
+
+	// BandwidthLimit
+	if config.BandwidthLimit != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return bandwidthlimit.New(ctx, next, *config.BandwidthLimit, middlewareName)
+		}
+	}
+
 	// BasicAuth
 	if config.BasicAuth != nil {
 		if middleware != nil {
@@ -126,6 +243,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// BotManagement
+	if config.BotManagement != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return botmanagement.New(ctx, next, *config.BotManagement, middlewareName)
+		}
+	}
+
 	// Buffering
 	if config.Buffering != nil {
 		if middleware != nil {
@@ -158,7 +285,7 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return circuitbreaker.New(ctx, next, *config.CircuitBreaker, middlewareName)
+			return circuitbreaker.New(ctx, next, *config.CircuitBreaker, middlewareName, b.sharedState)
 		}
 	}
 
@@ -187,6 +314,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// CSPNonce
+	if config.CSPNonce != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return cspnonce.New(ctx, next, *config.CSPNonce, middlewareName)
+		}
+	}
+
 	// CustomErrors
 	if config.Errors != nil {
 		if middleware != nil {
@@ -207,6 +344,40 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// EarlyHints
+	if config.EarlyHints != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return earlyhints.New(ctx, next, *config.EarlyHints, middlewareName)
+		}
+	}
+
+	// Experiment
+	if config.Experiment != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return experiment.New(ctx, next, *config.Experiment, middlewareName, b.metricsRegistry)
+		}
+	}
+
+	// FaultInjection
+	if config.FaultInjection != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		if !b.allowFaultInjection {
+			return nil, errors.New("fault injection is not allowed, enable it with the global.allowFaultInjection static option")
+		}
+
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return faultinjection.New(ctx, next, *config.FaultInjection, middlewareName)
+		}
+	}
+
 	// ForwardAuth
 	if config.ForwardAuth != nil {
 		if middleware != nil {
@@ -227,6 +398,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// Honeypot
+	if config.Honeypot != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return honeypot.New(ctx, next, *config.Honeypot, middlewareName, b.sharedState)
+		}
+	}
+
 	// IPWhiteList
 	if config.IPWhiteList != nil {
 		if middleware != nil {
@@ -247,6 +428,26 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// MethodOverride
+	if config.MethodOverride != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return methodoverride.New(ctx, next, *config.MethodOverride, middlewareName)
+		}
+	}
+
+	// OAuth2ClientCredentials
+	if config.OAuth2ClientCredentials != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return oauth2clientcredentials.New(ctx, next, *config.OAuth2ClientCredentials, middlewareName)
+		}
+	}
+
 	// PassTLSClientCert
 	if config.PassTLSClientCert != nil {
 		if middleware != nil {
@@ -263,7 +464,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return ratelimiter.New(ctx, next, *config.RateLimit, middlewareName)
+			return ratelimiter.New(ctx, next, *config.RateLimit, middlewareName, b.sharedState)
+		}
+	}
+
+	// RedirectMap
+	if config.RedirectMap != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return redirectmap.New(ctx, next, *config.RedirectMap, middlewareName, b.metricsRegistry)
 		}
 	}
 
@@ -318,6 +529,26 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// Schedule
+	if config.Schedule != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return schedule.New(ctx, next, *config.Schedule, middlewareName)
+		}
+	}
+
+	// Shadow
+	if config.Shadow != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return shadow.New(ctx, next, *config.Shadow, b, middlewareName, b.metricsRegistry)
+		}
+	}
+
 	// StripPrefix
 	if config.StripPrefix != nil {
 		if middleware != nil {
@@ -338,6 +569,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// WellKnown
+	if config.WellKnown != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return wellknown.New(ctx, next, *config.WellKnown, middlewareName)
+		}
+	}
+
 	if middleware == nil {
 		return nil, fmt.Errorf("invalid middleware %q configuration: invalid middleware type or middleware does not exist", middlewareName)
 	}
@@ -18,7 +18,7 @@ func TestBuilder_BuildChainNilConfig(t *testing.T) {
 	testConfig := map[string]*runtime.MiddlewareInfo{
 		"empty": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -29,7 +29,7 @@ func TestBuilder_BuildChainNonExistentChain(t *testing.T) {
 	testConfig := map[string]*runtime.MiddlewareInfo{
 		"foobar": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -270,7 +270,7 @@ func TestBuilder_BuildChainWithContext(t *testing.T) {
 					Middlewares: test.configuration,
 				},
 			})
-			builder := NewBuilder(rtConf.Middlewares, nil)
+			builder := NewBuilder(rtConf.Middlewares, nil, nil)
 
 			result := builder.BuildChain(ctx, test.buildChain)
 
@@ -329,7 +329,7 @@ func TestBuilder_buildConstructor(t *testing.T) {
 			Middlewares: testConfig,
 		},
 	})
-	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil)
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, nil)
 
 	testCases := []struct {
 		desc          string
@@ -382,3 +382,31 @@ func TestBuilder_buildConstructor(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_buildConstructorFaultInjection(t *testing.T) {
+	rtConf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"fault": {
+					FaultInjection: &dynamic.FaultInjection{
+						Abort: &dynamic.FaultAbort{StatusCode: http.StatusServiceUnavailable, Percentage: 100},
+					},
+				},
+			},
+		},
+	})
+
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, nil)
+
+	_, err := middlewaresBuilder.buildConstructor(context.Background(), "fault")
+	require.Error(t, err)
+
+	middlewaresBuilder.SetFaultInjectionAllowed(true)
+
+	constructor, err := middlewaresBuilder.buildConstructor(context.Background(), "fault")
+	require.NoError(t, err)
+
+	middleware, err := constructor(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+	require.NoError(t, err)
+	require.NotNil(t, middleware)
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/containous/alice"
@@ -41,6 +42,9 @@ type Manager struct {
 	chainBuilder       *middleware.ChainBuilder
 	modifierBuilder    responseModifierBuilder
 	conf               *runtime.Configuration
+
+	guardInternalServices bool
+	internalEntryPoints   map[string]bool
 }
 
 // NewManager Creates a new Manager
@@ -60,6 +64,13 @@ func NewManager(conf *runtime.Configuration,
 	}
 }
 
+// SetInternalServicesGuard sets whether all the routers are guarded against exposing internal services,
+// and the entry points on which the internal services can be exposed.
+func (m *Manager) SetInternalServicesGuard(guarded bool, internalEntryPoints map[string]bool) {
+	m.guardInternalServices = guarded
+	m.internalEntryPoints = internalEntryPoints
+}
+
 func (m *Manager) getHTTPRouters(ctx context.Context, entryPoints []string, tls bool) map[string]map[string]*runtime.RouterInfo {
 	if m.conf != nil {
 		return m.conf.GetRoutersByEntryPoints(ctx, entryPoints, tls)
@@ -76,7 +87,7 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string, t
 		entryPointName := entryPointName
 		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
 
-		handler, err := m.buildEntryPointHandler(ctx, routers)
+		handler, err := m.buildEntryPointHandler(ctx, entryPointName, routers)
 		if err != nil {
 			log.FromContext(ctx).Error(err)
 			continue
@@ -112,7 +123,7 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string, t
 	return entryPointHandlers
 }
 
-func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*runtime.RouterInfo) (http.Handler, error) {
+func (m *Manager) buildEntryPointHandler(ctx context.Context, entryPointName string, configs map[string]*runtime.RouterInfo) (http.Handler, error) {
 	router, err := rules.NewRouter()
 	if err != nil {
 		return nil, err
@@ -122,6 +133,12 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 		ctxRouter := log.With(provider.AddInContext(ctx, routerName), log.Str(log.RouterName, routerName))
 		logger := log.FromContext(ctxRouter)
 
+		if err := m.checkInternalService(ctxRouter, entryPointName, routerConfig); err != nil {
+			routerConfig.AddError(err, true)
+			logger.Error(err)
+			continue
+		}
+
 		handler, err := m.buildRouterHandler(ctxRouter, routerName, routerConfig)
 		if err != nil {
 			routerConfig.AddError(err, true)
@@ -129,7 +146,7 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 			continue
 		}
 
-		err = router.AddRoute(routerConfig.Rule, routerConfig.Priority, handler)
+		err = router.AddNormalizedRoute(routerConfig.Rule, routerConfig.Priority, routerConfig.PathNormalization, handler)
 		if err != nil {
 			routerConfig.AddError(err, true)
 			logger.Error(err)
@@ -147,6 +164,24 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 	return chain.Then(router)
 }
 
+// checkInternalService returns an error if the router is guarded, and exposes an internal service on an entry point not marked as internal.
+func (m *Manager) checkInternalService(ctx context.Context, entryPointName string, router *runtime.RouterInfo) error {
+	if !m.guardInternalServices && !router.GuardInternalServices {
+		return nil
+	}
+
+	if m.internalEntryPoints[entryPointName] || router.Service == "" || m.conf == nil {
+		return nil
+	}
+
+	serviceName := provider.GetQualifiedName(ctx, router.Service)
+	if service, ok := m.conf.Services[serviceName]; ok && service.Service != nil && service.Internal {
+		return fmt.Errorf("the internal service %s cannot be exposed on the entry point %s, which is not internal", serviceName, entryPointName)
+	}
+
+	return nil
+}
+
 func (m *Manager) buildRouterHandler(ctx context.Context, routerName string, routerConfig *runtime.RouterInfo) (http.Handler, error) {
 	if handler, ok := m.routerHandlers[routerName]; ok {
 		return handler, nil
@@ -193,7 +228,7 @@ func (m *Manager) buildHTTPHandler(ctx context.Context, router *runtime.RouterIn
 		return tracing.NewForwarder(ctx, routerName, router.Service, next), nil
 	}
 
-	return alice.New().Extend(*mHandler).Append(tHandler).Then(sHandler)
+	return m.chainBuilder.BuildRouter(ctx, routerName).Extend(*mHandler).Append(tHandler).Then(sHandler)
 }
 
 // BuildDefaultHTTPRouter creates a default HTTP router.
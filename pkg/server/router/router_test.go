@@ -286,8 +286,8 @@ func TestRouterManager_Get(t *testing.T) {
 				},
 			})
 
-			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil)
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil, nil)
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
 			responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 			chainBuilder := middleware.NewChainBuilder(static.Configuration{}, nil, nil)
 
@@ -389,8 +389,8 @@ func TestAccessLog(t *testing.T) {
 				},
 			})
 
-			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil)
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil, nil)
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
 			responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 			chainBuilder := middleware.NewChainBuilder(static.Configuration{}, nil, nil)
 
@@ -677,8 +677,8 @@ func TestRuntimeConfiguration(t *testing.T) {
 				},
 			})
 
-			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil)
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil, nil)
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
 			responseModifierFactory := responsemodifiers.NewBuilder(map[string]*runtime.MiddlewareInfo{})
 			chainBuilder := middleware.NewChainBuilder(static.Configuration{}, nil, nil)
 
@@ -710,6 +710,94 @@ func TestRuntimeConfiguration(t *testing.T) {
 	}
 }
 
+func TestRouterManager_internalServicesGuard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	testCases := []struct {
+		desc                string
+		guarded             bool
+		routerGuarded       bool
+		internalService     bool
+		internalEntryPoints map[string]bool
+		expectedStatusCode  int
+		expectedErr         []string
+	}{
+		{
+			desc:               "not guarded",
+			internalService:    true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "guarded, service not internal",
+			guarded:            true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "guarded, internal service on a public entry point",
+			guarded:            true,
+			internalService:    true,
+			expectedStatusCode: http.StatusNotFound,
+			expectedErr:        []string{"the internal service foo-service cannot be exposed on the entry point web, which is not internal"},
+		},
+		{
+			desc:               "router guarded, internal service on a public entry point",
+			routerGuarded:      true,
+			internalService:    true,
+			expectedStatusCode: http.StatusNotFound,
+			expectedErr:        []string{"the internal service foo-service cannot be exposed on the entry point web, which is not internal"},
+		},
+		{
+			desc:                "guarded, internal service on an internal entry point",
+			guarded:             true,
+			internalService:     true,
+			internalEntryPoints: map[string]bool{"web": true},
+			expectedStatusCode:  http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			rtConf := runtime.NewConfig(dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Services: map[string]*dynamic.Service{
+						"foo-service": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{{URL: server.URL}},
+							},
+							Internal: test.internalService,
+						},
+					},
+					Routers: map[string]*dynamic.Router{
+						"foo": {
+							EntryPoints:           []string{"web"},
+							Service:               "foo-service",
+							Rule:                  "Host(`foo.bar`)",
+							GuardInternalServices: test.routerGuarded,
+						},
+					},
+				},
+			})
+
+			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil, nil)
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+			responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
+			chainBuilder := middleware.NewChainBuilder(static.Configuration{}, nil, nil)
+
+			routerManager := NewManager(rtConf, serviceManager, middlewaresBuilder, responseModifierFactory, chainBuilder)
+			routerManager.SetInternalServicesGuard(test.guarded, test.internalEntryPoints)
+
+			handlers := routerManager.BuildHandlers(context.Background(), []string{"web"}, false)
+
+			recorder := httptest.NewRecorder()
+			handlers["web"].ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.bar/", nil))
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			assert.Equal(t, test.expectedErr, rtConf.Routers["foo"].Err)
+		})
+	}
+}
+
 func TestProviderOnMiddlewares(t *testing.T) {
 	entryPoints := []string{"web"}
 
@@ -759,8 +847,8 @@ func TestProviderOnMiddlewares(t *testing.T) {
 		},
 	})
 
-	serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil)
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+	serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport, nil, nil, nil)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
 	responseModifierFactory := responsemodifiers.NewBuilder(map[string]*runtime.MiddlewareInfo{})
 	chainBuilder := middleware.NewChainBuilder(staticCfg, nil, nil)
 
@@ -818,8 +906,8 @@ func BenchmarkRouterServe(b *testing.B) {
 		},
 	})
 
-	serviceManager := service.NewManager(rtConf.Services, &staticTransport{res}, nil, nil)
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+	serviceManager := service.NewManager(rtConf.Services, &staticTransport{res}, nil, nil, nil)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
 	responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 	chainBuilder := middleware.NewChainBuilder(static.Configuration{}, nil, nil)
 
@@ -861,7 +949,7 @@ func BenchmarkService(b *testing.B) {
 		},
 	})
 
-	serviceManager := service.NewManager(rtConf.Services, &staticTransport{res}, nil, nil)
+	serviceManager := service.NewManager(rtConf.Services, &staticTransport{res}, nil, nil, nil)
 	w := httptest.NewRecorder()
 	req := testhelpers.MustNewRequest(http.MethodGet, "http://foo.bar/", nil)
 
@@ -93,6 +93,8 @@ type nameAndConfig struct {
 func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*runtime.TCPRouterInfo, configsHTTP map[string]*runtime.RouterInfo, handlerHTTP http.Handler, handlerHTTPS http.Handler) (*tcp.Router, error) {
 	router := &tcp.Router{}
 	router.HTTPHandler(handlerHTTP)
+	router.OnHandshakeError(m.tlsManager.ReportHandshakeError)
+	router.StrictSNIHosts(m.tlsManager.MatchesSNIOptions)
 
 	defaultTLSConf, err := m.tlsManager.Get(defaultTLSStoreName, defaultTLSConfigName)
 	if err != nil {
@@ -107,6 +109,8 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 
 	// Keyed by domain, then by options reference.
 	tlsOptionsForHostSNI := map[string]map[string]nameAndConfig{}
+	// Keyed by options name, so that the hosts sharing the same options share the same config.
+	tlsConfigs := map[string]*tls.Config{}
 	for routerHTTPName, routerHTTPConfig := range configsHTTP {
 		if len(routerHTTPConfig.TLS.Options) == 0 || routerHTTPConfig.TLS.Options == defaultTLSConfigName {
 			continue
@@ -134,11 +138,15 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 					tlsOptionsName = provider.GetQualifiedName(ctxRouter, routerHTTPConfig.TLS.Options)
 				}
 
-				tlsConf, err := m.tlsManager.Get(defaultTLSStoreName, tlsOptionsName)
-				if err != nil {
-					routerHTTPConfig.AddError(err, true)
-					logger.Debug(err)
-					continue
+				tlsConf, ok := tlsConfigs[tlsOptionsName]
+				if !ok {
+					tlsConf, err = m.tlsManager.Get(defaultTLSStoreName, tlsOptionsName)
+					if err != nil {
+						routerHTTPConfig.AddError(err, true)
+						logger.Debug(err)
+						continue
+					}
+					tlsConfigs[tlsOptionsName] = tlsConf
 				}
 
 				if tlsOptionsForHostSNI[domain] == nil {
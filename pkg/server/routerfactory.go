@@ -4,9 +4,12 @@ import (
 	"context"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/lint"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/gossip"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/responsemodifiers"
 	"github.com/containous/traefik/v2/pkg/server/middleware"
 	"github.com/containous/traefik/v2/pkg/server/router"
@@ -27,14 +30,34 @@ type RouterFactory struct {
 
 	managerFactory *service.ManagerFactory
 
-	chainBuilder *middleware.ChainBuilder
-	tlsManager   *tls.Manager
+	chainBuilder    *middleware.ChainBuilder
+	tlsManager      *tls.Manager
+	metricsRegistry metrics.Registry
+
+	allowFaultInjection bool
+	sharedState         *gossip.State
+
+	guardInternalServices bool
+	internalEntryPoints   map[string]bool
+
+	linter *lint.Linter
+	// warnings are the warnings of the current configuration, only logged when they first appear.
+	warnings map[runtime.Warning]struct{}
+
+	// tcpServiceManager is the manager of the TCP services of the current routers,
+	// whose connections are drained once their service configuration changes.
+	tcpServiceManager *tcp.Manager
 }
 
 // NewRouterFactory creates a new RouterFactory
-func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *service.ManagerFactory, tlsManager *tls.Manager, chainBuilder *middleware.ChainBuilder) *RouterFactory {
+func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *service.ManagerFactory, tlsManager *tls.Manager, chainBuilder *middleware.ChainBuilder, metricsRegistry metrics.Registry) *RouterFactory {
 	var entryPointsTCP, entryPointsUDP []string
+	internalEntryPoints := make(map[string]bool)
 	for name, cfg := range staticConfiguration.EntryPoints {
+		if cfg.Internal {
+			internalEntryPoints[name] = true
+		}
+
 		protocol, err := cfg.GetProtocol()
 		if err != nil {
 			// Should never happen because Traefik should not start if protocol is invalid.
@@ -49,14 +72,27 @@ func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *
 	}
 
 	return &RouterFactory{
-		entryPointsTCP: entryPointsTCP,
-		entryPointsUDP: entryPointsUDP,
-		managerFactory: managerFactory,
-		tlsManager:     tlsManager,
-		chainBuilder:   chainBuilder,
+		entryPointsTCP:      entryPointsTCP,
+		entryPointsUDP:      entryPointsUDP,
+		managerFactory:      managerFactory,
+		tlsManager:          tlsManager,
+		chainBuilder:        chainBuilder,
+		metricsRegistry:     metricsRegistry,
+		allowFaultInjection: staticConfiguration.Global != nil && staticConfiguration.Global.AllowFaultInjection,
+
+		guardInternalServices: staticConfiguration.Global != nil && staticConfiguration.Global.GuardInternalServices,
+		internalEntryPoints:   internalEntryPoints,
+
+		linter:   lint.NewLinter(staticConfiguration),
+		warnings: make(map[runtime.Warning]struct{}),
 	}
 }
 
+// SetSharedState defines the state shared with the other instances by the middlewares.
+func (f *RouterFactory) SetSharedState(state *gossip.State) {
+	f.sharedState = state
+}
+
 // CreateRouters creates new TCPRouters and UDPRouters
 func (f *RouterFactory) CreateRouters(conf dynamic.Configuration) (map[string]*tcpCore.Router, map[string]udpCore.Handler) {
 	ctx := context.Background()
@@ -66,10 +102,13 @@ func (f *RouterFactory) CreateRouters(conf dynamic.Configuration) (map[string]*t
 	// HTTP
 	serviceManager := f.managerFactory.Build(rtConf)
 
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.metricsRegistry)
+	middlewaresBuilder.SetFaultInjectionAllowed(f.allowFaultInjection)
+	middlewaresBuilder.SetSharedState(f.sharedState)
 	responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 
 	routerManager := router.NewManager(rtConf, serviceManager, middlewaresBuilder, responseModifierFactory, f.chainBuilder)
+	routerManager.SetInternalServicesGuard(f.guardInternalServices, f.internalEntryPoints)
 
 	handlersNonTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, false)
 	handlersTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, true)
@@ -78,10 +117,15 @@ func (f *RouterFactory) CreateRouters(conf dynamic.Configuration) (map[string]*t
 
 	// TCP
 	svcTCPManager := tcp.NewManager(rtConf)
+	svcTCPManager.Replace(f.tcpServiceManager)
+	svcTCPManager.SetMetricsRegistry(f.metricsRegistry)
 
 	rtTCPManager := routertcp.NewManager(rtConf, svcTCPManager, handlersNonTLS, handlersTLS, f.tlsManager)
 	routersTCP := rtTCPManager.BuildHandlers(ctx, f.entryPointsTCP)
 
+	svcTCPManager.DrainReplaced()
+	f.tcpServiceManager = svcTCPManager
+
 	// UDP
 	svcUDPManager := udp.NewManager(rtConf)
 	rtUDPManager := routerudp.NewManager(rtConf, svcUDPManager)
@@ -89,5 +133,25 @@ func (f *RouterFactory) CreateRouters(conf dynamic.Configuration) (map[string]*t
 
 	rtConf.PopulateUsedBy()
 
+	f.lint(rtConf)
+
 	return routersTCP, routersUDP
 }
+
+// lint sets the warnings of the configuration, counts them by class, and logs the new ones.
+func (f *RouterFactory) lint(rtConf *runtime.Configuration) {
+	rtConf.Warnings = f.linter.Lint(rtConf)
+
+	warnings := make(map[runtime.Warning]struct{}, len(rtConf.Warnings))
+	for _, warning := range rtConf.Warnings {
+		warnings[warning] = struct{}{}
+
+		f.metricsRegistry.ConfigWarningsCounter().With("class", warning.Class).Add(1)
+
+		if _, ok := f.warnings[warning]; !ok {
+			log.WithoutContext().Warnf("Configuration warning (%s) on %s %s, %s: %s", warning.Class, warning.Kind, warning.Name, warning.Field, warning.Message)
+		}
+	}
+
+	f.warnings = warnings
+}
@@ -47,10 +47,10 @@ func TestReuseService(t *testing.T) {
 		),
 	)
 
-	managerFactory := service.NewManagerFactory(staticConfig, nil, metrics.NewVoidRegistry())
+	managerFactory := service.NewManagerFactory(staticConfig, nil, metrics.NewVoidRegistry(), nil, nil, nil, nil, nil, nil, nil)
 	tlsManager := tls.NewManager()
 
-	factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil))
+	factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil), metrics.NewVoidRegistry())
 
 	entryPointsHandlers, _ := factory.CreateRouters(dynamic.Configuration{HTTP: dynamicConfigs})
 
@@ -181,10 +181,10 @@ func TestServerResponseEmptyBackend(t *testing.T) {
 				},
 			}
 
-			managerFactory := service.NewManagerFactory(staticConfig, nil, metrics.NewVoidRegistry())
+			managerFactory := service.NewManagerFactory(staticConfig, nil, metrics.NewVoidRegistry(), nil, nil, nil, nil, nil, nil, nil)
 			tlsManager := tls.NewManager()
 
-			factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil))
+			factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil), metrics.NewVoidRegistry())
 
 			entryPointsHandlers, _ := factory.CreateRouters(dynamic.Configuration{HTTP: test.config(testServer.URL)})
 
@@ -220,10 +220,10 @@ func TestInternalServices(t *testing.T) {
 		),
 	)
 
-	managerFactory := service.NewManagerFactory(staticConfig, nil, metrics.NewVoidRegistry())
+	managerFactory := service.NewManagerFactory(staticConfig, nil, metrics.NewVoidRegistry(), nil, nil, nil, nil, nil, nil, nil)
 	tlsManager := tls.NewManager()
 
-	factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil))
+	factory := NewRouterFactory(staticConfig, managerFactory, tlsManager, middleware.NewChainBuilder(staticConfig, metrics.NewVoidRegistry(), nil), metrics.NewVoidRegistry())
 
 	entryPointsHandlers, _ := factory.CreateRouters(dynamic.Configuration{HTTP: dynamicConfigs})
 
@@ -6,6 +6,7 @@ import (
 	stdlog "log"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,11 +14,13 @@ import (
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/ip"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/middlewares/forwardedheaders"
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/server/router"
 	"github.com/containous/traefik/v2/pkg/tcp"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
@@ -102,6 +105,27 @@ func (eps TCPEntryPoints) Stop() {
 	wg.Wait()
 }
 
+// GetConnectionsByIP returns the number of active connections of each client IP on an entry point.
+func (eps TCPEntryPoints) GetConnectionsByIP(entryPointName string) (map[string]int, bool) {
+	entryPoint, ok := eps[entryPointName]
+	if !ok {
+		return nil, false
+	}
+
+	return entryPoint.connections.connections(), true
+}
+
+// SetMetricsRegistry sets the metrics registry counting the failures to peek the TLS ClientHello on the entry points.
+func (eps TCPEntryPoints) SetMetricsRegistry(registry metrics.Registry) {
+	if !registry.IsEpEnabled() {
+		return
+	}
+
+	for entryPointName, entryPoint := range eps {
+		entryPoint.clientHelloFailures = registry.EntryPointClientHelloFailuresCounter().With("entrypoint", entryPointName)
+	}
+}
+
 // Switch the TCP routers.
 func (eps TCPEntryPoints) Switch(routersTCP map[string]*tcp.Router) {
 	for entryPointName, rt := range routersTCP {
@@ -115,14 +139,23 @@ type TCPEntryPoint struct {
 	switcher               *tcp.HandlerSwitcher
 	transportConfiguration *static.EntryPointsTransport
 	tracker                *connectionTracker
+	connections            *connectionTable
 	httpServer             *httpServer
 	httpsServer            *httpServer
+	rejectCoalescing       bool
+	clientHello            tcp.ClientHelloOptions
+	clientHelloFailures    gokitmetrics.Counter
 }
 
 // NewTCPEntryPoint creates a new TCPEntryPoint
 func NewTCPEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*TCPEntryPoint, error) {
 	tracker := newConnectionTracker()
 
+	connections, err := newConnectionTable(configuration.ConnectionLimit)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing connection limit: %v", err)
+	}
+
 	listener, err := buildListener(ctx, configuration)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing server: %v", err)
@@ -144,17 +177,79 @@ func NewTCPEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*T
 
 	router.HTTPSForwarder(httpsServer.Forwarder)
 
-	tcpSwitcher := &tcp.HandlerSwitcher{}
-	tcpSwitcher.Switch(router)
-
-	return &TCPEntryPoint{
+	entryPoint := &TCPEntryPoint{
 		listener:               listener,
-		switcher:               tcpSwitcher,
+		switcher:               &tcp.HandlerSwitcher{},
 		transportConfiguration: configuration.Transport,
 		tracker:                tracker,
+		connections:            connections,
 		httpServer:             httpServer,
 		httpsServer:            httpsServer,
-	}, nil
+		rejectCoalescing:       configuration.HTTP.RejectCoalescing,
+	}
+
+	entryPoint.clientHello, err = buildClientHelloOptions(configuration.ClientHello)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing ClientHello peeking: %v", err)
+	}
+	entryPoint.clientHello.OnFailure = entryPoint.countClientHelloFailure
+
+	router.ClientHello(entryPoint.clientHello)
+	entryPoint.switcher.Switch(router)
+
+	return entryPoint, nil
+}
+
+// buildClientHelloOptions returns the options of the peeking of the TLS ClientHello of the configuration.
+func buildClientHelloOptions(config *static.ClientHello) (tcp.ClientHelloOptions, error) {
+	if config == nil {
+		return tcp.ClientHelloOptions{}, nil
+	}
+
+	if config.MaxBytes < 0 {
+		return tcp.ClientHelloOptions{}, fmt.Errorf("invalid maximum size of the ClientHello: %d", config.MaxBytes)
+	}
+
+	opts := tcp.ClientHelloOptions{
+		Timeout:  time.Duration(config.Timeout),
+		MaxBytes: config.MaxBytes,
+	}
+
+	switch config.Fallback {
+	case "", static.ClientHelloFallbackClose:
+	case static.ClientHelloFallbackNoTLS:
+		opts.Fallback = true
+	default:
+		return tcp.ClientHelloOptions{}, fmt.Errorf("invalid fallback action: %s", config.Fallback)
+	}
+
+	for _, protocol := range config.StartTLS {
+		switch protocol {
+		case static.StartTLSPostgres:
+			opts.Postgres = true
+		default:
+			if !tcp.IsStartTLSProtocol(protocol) {
+				return tcp.ClientHelloOptions{}, fmt.Errorf("invalid startTLS protocol: %s", protocol)
+			}
+			if opts.StartTLS != "" {
+				return tcp.ClientHelloOptions{}, fmt.Errorf("invalid startTLS protocols: %s and %s both greet the clients", opts.StartTLS, protocol)
+			}
+			opts.StartTLS = protocol
+		}
+	}
+
+	if opts.Postgres && opts.StartTLS != "" {
+		return tcp.ClientHelloOptions{}, fmt.Errorf("invalid startTLS protocols: postgres and %s can not share an entry point", opts.StartTLS)
+	}
+
+	return opts, nil
+}
+
+// countClientHelloFailure counts the failures to peek the TLS ClientHello, if the entry point metrics are enabled.
+func (e *TCPEntryPoint) countClientHelloFailure(reason string) {
+	if e.clientHelloFailures != nil {
+		e.clientHelloFailures.With("reason", reason).Add(1)
+	}
 }
 
 // Start starts the TCP server.
@@ -196,7 +291,14 @@ func (e *TCPEntryPoint) Start(ctx context.Context) {
 				}
 			}
 
-			e.switcher.ServeTCP(newTrackedConnection(writeCloser, e.tracker))
+			ip := clientIP(writeCloser.RemoteAddr())
+			if err := e.connections.acquire(ip); err != nil {
+				logger.Debugf("Closing connection from %s: %v", ip, err)
+				_ = writeCloser.Close()
+				return
+			}
+
+			e.switcher.ServeTCP(newTrackedConnection(writeCloser, e.tracker, func() { e.connections.release(ip) }))
 		})
 	}
 }
@@ -283,8 +385,16 @@ func (e *TCPEntryPoint) SwitchRouter(rt *tcp.Router) {
 		httpsHandler = router.BuildDefaultHTTPRouter()
 	}
 
+	if e.rejectCoalescing {
+		httpsHandler = rejectCoalescedRequests(rt, httpsHandler)
+	}
+
+	httpsHandler = rejectMisdirectedRequests(rt, httpsHandler)
+
 	e.httpsServer.Switcher.UpdateHandler(httpsHandler)
 
+	rt.ClientHello(e.clientHello)
+
 	e.switcher.Switch(rt)
 }
 
@@ -460,6 +570,50 @@ type stoppableServer interface {
 	Serve(listener net.Listener) error
 }
 
+// rejectCoalescedRequests responds with a 421 Misdirected Request to the requests
+// whose host is served with other TLS options than the server name of the connection they were sent on,
+// so that a client reusing an HTTP/2 connection for another authority is made to open a dedicated one.
+func rejectCoalescedRequests(rt *tcp.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.TLS != nil && req.TLS.ServerName != "" {
+			host, _, err := net.SplitHostPort(req.Host)
+			if err != nil {
+				host = req.Host
+			}
+
+			if !strings.EqualFold(host, req.TLS.ServerName) && rt.GetHTTPSTLSConfig(host) != rt.GetHTTPSTLSConfig(req.TLS.ServerName) {
+				log.FromContext(req.Context()).Debugf("Rejecting request for host %s sent on a connection for %s", host, req.TLS.ServerName)
+				http.Error(rw, http.StatusText(http.StatusMisdirectedRequest), http.StatusMisdirectedRequest)
+				return
+			}
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// rejectMisdirectedRequests responds with a 421 Misdirected Request to the requests
+// whose host is not the server name of the connection they were sent on, if either of them is a strict SNI host,
+// so that the TLS options selected by server name, such as the client authentication, can not be bypassed.
+func rejectMisdirectedRequests(rt *tcp.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.TLS != nil {
+			host, _, err := net.SplitHostPort(req.Host)
+			if err != nil {
+				host = req.Host
+			}
+
+			if !strings.EqualFold(host, req.TLS.ServerName) && (rt.IsStrictSNIHost(host) || rt.IsStrictSNIHost(req.TLS.ServerName)) {
+				log.FromContext(req.Context()).Debugf("Rejecting request for host %s sent on a connection for %q", host, req.TLS.ServerName)
+				http.Error(rw, http.StatusText(http.StatusMisdirectedRequest), http.StatusMisdirectedRequest)
+				return
+			}
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
 type httpServer struct {
 	Server    stoppableServer
 	Forwarder *httpForwarder
@@ -490,6 +644,14 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 		ReadTimeout:  time.Duration(configuration.Transport.RespondingTimeouts.ReadTimeout),
 		WriteTimeout: time.Duration(configuration.Transport.RespondingTimeouts.WriteTimeout),
 		IdleTimeout:  time.Duration(configuration.Transport.RespondingTimeouts.IdleTimeout),
+		ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+			ctx = middlewares.WithConn(ctx, conn)
+
+			if ja3 := tcp.ConnClientJA3(conn); ja3 != "" {
+				return tcp.WithClientJA3(ctx, ja3)
+			}
+			return ctx
+		},
 	}
 
 	listener := newHTTPForwarder(ln)
@@ -506,20 +668,31 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 	}, nil
 }
 
-func newTrackedConnection(conn tcp.WriteCloser, tracker *connectionTracker) *trackedConnection {
+func newTrackedConnection(conn tcp.WriteCloser, tracker *connectionTracker, release func()) *trackedConnection {
 	tracker.AddConnection(conn)
 	return &trackedConnection{
 		WriteCloser: conn,
 		tracker:     tracker,
+		release:     release,
 	}
 }
 
 type trackedConnection struct {
 	tracker *connectionTracker
 	tcp.WriteCloser
+
+	// release is called once, when the connection is closed for the first time.
+	release     func()
+	releaseOnce sync.Once
 }
 
 func (t *trackedConnection) Close() error {
 	t.tracker.RemoveConnection(t.WriteCloser)
+	t.releaseOnce.Do(t.release)
 	return t.WriteCloser.Close()
 }
+
+// Underlying returns the tracked connection.
+func (t *trackedConnection) Underlying() tcp.WriteCloser {
+	return t.WriteCloser
+}
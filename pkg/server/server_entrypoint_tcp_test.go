@@ -3,10 +3,12 @@ package server
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"errors"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -221,3 +223,271 @@ func TestReadTimeoutWithFirstByte(t *testing.T) {
 		t.Error("Timeout while read")
 	}
 }
+
+func TestConnectionLimit(t *testing.T) {
+	epConfig := &static.EntryPointsTransport{}
+	epConfig.SetDefaults()
+
+	entryPoint, err := NewTCPEntryPoint(context.Background(), &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        epConfig,
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		ConnectionLimit:  &static.ConnectionLimit{MaxPerIP: 1, Overflow: static.OverflowReject},
+	})
+	require.NoError(t, err)
+
+	served := make(chan tcp.WriteCloser, 1)
+
+	router := &tcp.Router{}
+	router.AddCatchAllNoTLS(tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		served <- conn
+	}))
+
+	conn, err := startEntrypoint(entryPoint, router)
+	require.NoError(t, err)
+
+	_, err = conn.Write([]byte("foo"))
+	require.NoError(t, err)
+
+	var servedConn tcp.WriteCloser
+	select {
+	case servedConn = <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not served")
+	}
+
+	eps := TCPEntryPoints{"tcp": entryPoint}
+
+	connections, ok := eps.GetConnectionsByIP("tcp")
+	require.True(t, ok)
+	assert.Equal(t, map[string]int{"127.0.0.1": 1}, connections)
+
+	// The connection over the limit is closed.
+	rejectedConn, err := net.Dial("tcp", entryPoint.listener.Addr().String())
+	require.NoError(t, err)
+
+	err = rejectedConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, err)
+
+	_, err = rejectedConn.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+
+	require.NoError(t, servedConn.Close())
+
+	connections, ok = eps.GetConnectionsByIP("tcp")
+	require.True(t, ok)
+	assert.Empty(t, connections)
+
+	_, ok = eps.GetConnectionsByIP("foo")
+	assert.False(t, ok)
+}
+
+func TestRejectCoalescedRequests(t *testing.T) {
+	defaultConfig := &tls.Config{}
+	strictConfig := &tls.Config{}
+
+	router := &tcp.Router{}
+	router.HTTPSHandler(nil, defaultConfig)
+	router.AddRouteHTTPTLS("foo.localhost", defaultConfig)
+	router.AddRouteHTTPTLS("bar.localhost", defaultConfig)
+	router.AddRouteHTTPTLS("strict.localhost", strictConfig)
+
+	handler := rejectCoalescedRequests(router, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		desc       string
+		serverName string
+		host       string
+		expected   int
+	}{
+		{
+			desc:       "same host",
+			serverName: "strict.localhost",
+			host:       "strict.localhost",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "same host with port",
+			serverName: "strict.localhost",
+			host:       "Strict.localhost:443",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "coalesced host with same TLS options",
+			serverName: "foo.localhost",
+			host:       "bar.localhost",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "coalesced host with other TLS options",
+			serverName: "foo.localhost",
+			host:       "strict.localhost",
+			expected:   http.StatusMisdirectedRequest,
+		},
+		{
+			desc:       "coalesced host with default TLS options",
+			serverName: "strict.localhost",
+			host:       "unknown.localhost",
+			expected:   http.StatusMisdirectedRequest,
+		},
+		{
+			desc:     "no server name",
+			host:     "strict.localhost",
+			expected: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "https://"+test.host, nil)
+			req.TLS.ServerName = test.serverName
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
+
+func TestRejectMisdirectedRequests(t *testing.T) {
+	router := &tcp.Router{}
+	router.StrictSNIHosts(func(serverName string) bool {
+		return strings.EqualFold(serverName, "admin.localhost")
+	})
+
+	handler := rejectMisdirectedRequests(router, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		desc       string
+		serverName string
+		host       string
+		expected   int
+	}{
+		{
+			desc:       "same strict host",
+			serverName: "admin.localhost",
+			host:       "Admin.localhost:443",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "other hosts",
+			serverName: "foo.localhost",
+			host:       "bar.localhost",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "strict host on a connection for another server name",
+			serverName: "foo.localhost",
+			host:       "admin.localhost",
+			expected:   http.StatusMisdirectedRequest,
+		},
+		{
+			desc:     "strict host on a connection without server name",
+			host:     "admin.localhost",
+			expected: http.StatusMisdirectedRequest,
+		},
+		{
+			desc:       "other host on a connection for a strict host",
+			serverName: "admin.localhost",
+			host:       "foo.localhost",
+			expected:   http.StatusMisdirectedRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "https://"+test.host, nil)
+			req.TLS.ServerName = test.serverName
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
+
+func TestBuildClientHelloOptions(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        *static.ClientHello
+		expected      tcp.ClientHelloOptions
+		expectedError bool
+	}{
+		{
+			desc: "no configuration",
+		},
+		{
+			desc:     "close",
+			config:   &static.ClientHello{Timeout: types.Duration(time.Second), MaxBytes: 1024, Fallback: static.ClientHelloFallbackClose},
+			expected: tcp.ClientHelloOptions{Timeout: time.Second, MaxBytes: 1024},
+		},
+		{
+			desc:     "noTLS",
+			config:   &static.ClientHello{Timeout: types.Duration(time.Second), Fallback: static.ClientHelloFallbackNoTLS},
+			expected: tcp.ClientHelloOptions{Timeout: time.Second, Fallback: true},
+		},
+		{
+			desc:     "postgres",
+			config:   &static.ClientHello{Timeout: types.Duration(time.Second), StartTLS: []string{static.StartTLSPostgres}},
+			expected: tcp.ClientHelloOptions{Timeout: time.Second, Postgres: true},
+		},
+		{
+			desc:     "smtp",
+			config:   &static.ClientHello{StartTLS: []string{"smtp"}},
+			expected: tcp.ClientHelloOptions{StartTLS: tcp.StartTLSSMTP},
+		},
+		{
+			desc:          "several mail protocols",
+			config:        &static.ClientHello{StartTLS: []string{"smtp", "imap"}},
+			expectedError: true,
+		},
+		{
+			desc:          "postgres and a mail protocol",
+			config:        &static.ClientHello{StartTLS: []string{"postgres", "pop3"}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid startTLS protocol",
+			config:        &static.ClientHello{StartTLS: []string{"foo"}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid fallback",
+			config:        &static.ClientHello{Fallback: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid maximum size",
+			config:        &static.ClientHello{MaxBytes: -1},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			opts, err := buildClientHelloOptions(test.config)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, opts)
+		})
+	}
+}
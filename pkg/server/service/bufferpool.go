@@ -2,13 +2,21 @@ package service
 
 import "sync"
 
-const bufferPoolSize = 32 * 1024
+const (
+	bufferPoolSize = 32 * 1024
+	// maxBufferSize is the largest buffer size a service can configure.
+	maxBufferSize = 1024 * 1024
+)
 
 func newBufferPool() *bufferPool {
+	return newSizedBufferPool(bufferPoolSize)
+}
+
+func newSizedBufferPool(size int) *bufferPool {
 	return &bufferPool{
 		pool: sync.Pool{
 			New: func() interface{} {
-				return make([]byte, bufferPoolSize)
+				return make([]byte, size)
 			},
 		},
 	}
@@ -25,3 +33,27 @@ func (b *bufferPool) Get() []byte {
 func (b *bufferPool) Put(bytes []byte) {
 	b.pool.Put(bytes)
 }
+
+// bufferPools holds the buffer pools of the services with a custom buffer size,
+// so that the services with the same buffer size share their buffers.
+type bufferPools struct {
+	mu    sync.Mutex
+	pools map[int]*bufferPool
+}
+
+func (p *bufferPools) get(size int) *bufferPool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pools == nil {
+		p.pools = make(map[int]*bufferPool)
+	}
+
+	pool, ok := p.pools[size]
+	if !ok {
+		pool = newSizedBufferPool(size)
+		p.pools[size] = pool
+	}
+
+	return pool
+}
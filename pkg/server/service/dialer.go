@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+)
+
+// defaultFallbackDelay is the default delay before dialing the fallback address family,
+// as recommended by RFC 6555, and used by the Go dialer.
+const defaultFallbackDelay = 300 * time.Millisecond
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// familyDialer resolves the host names of the servers, and dials their addresses according to an address family preference.
+// The addresses of the preferred family are dialed first,
+// and the addresses of the other family are also dialed if no connection is established after the fallback delay,
+// the first established connection being used (Happy Eyeballs, RFC 6555).
+// Without preference, the preferred family is the one of the first resolved address.
+type familyDialer struct {
+	dial          dialFunc
+	lookupIPAddr  lookupFunc
+	family        string
+	fallbackDelay time.Duration
+}
+
+// newDialContext returns the function dialing the servers with the given dialer,
+// according to the address family of the transport configuration.
+// The host names are resolved with lookupIPAddr, or with the system resolver if nil.
+func newDialContext(dialer *net.Dialer, conf *static.ServersTransport, lookupIPAddr lookupFunc) (dialFunc, error) {
+	if conf.FallbackDelay > 0 {
+		dialer.FallbackDelay = time.Duration(conf.FallbackDelay)
+	}
+
+	laddr, err := localAddr(conf)
+	if err != nil {
+		return nil, err
+	}
+	if laddr != nil {
+		dialer.LocalAddr = laddr
+	}
+
+	dial := dialFunc(dialer.DialContext)
+	switch conf.AddressFamily {
+	case "", static.AddressFamilyPreferIPv4, static.AddressFamilyPreferIPv6:
+	case static.AddressFamilyIPv4:
+		dial = withNetwork(dial, "4")
+	case static.AddressFamilyIPv6:
+		dial = withNetwork(dial, "6")
+	default:
+		return nil, fmt.Errorf("unknown address family %q", conf.AddressFamily)
+	}
+
+	if lookupIPAddr == nil {
+		if conf.AddressFamily != static.AddressFamilyPreferIPv4 && conf.AddressFamily != static.AddressFamilyPreferIPv6 {
+			// The Go dialer already applies the Happy Eyeballs in the order of the resolved addresses.
+			return dial, nil
+		}
+		lookupIPAddr = net.DefaultResolver.LookupIPAddr
+	}
+
+	d := &familyDialer{
+		dial:          dial,
+		lookupIPAddr:  lookupIPAddr,
+		family:        conf.AddressFamily,
+		fallbackDelay: defaultFallbackDelay,
+	}
+	if conf.FallbackDelay > 0 {
+		d.fallbackDelay = time.Duration(conf.FallbackDelay)
+	}
+
+	return d.DialContext, nil
+}
+
+// localAddr returns the source address of the connections to the servers,
+// or nil when it is chosen by the system.
+// The address of an interface is one of the preferred address family, if any.
+func localAddr(conf *static.ServersTransport) (*net.TCPAddr, error) {
+	if conf.LocalAddress != "" {
+		ip := net.ParseIP(conf.LocalAddress)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid local address %q", conf.LocalAddress)
+		}
+		return &net.TCPAddr{IP: ip}, nil
+	}
+
+	if conf.Interface == "" {
+		return nil, nil
+	}
+
+	iface, err := net.InterfaceByName(conf.Interface)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get interface %q: %w", conf.Interface, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the addresses of interface %q: %w", conf.Interface, err)
+	}
+
+	preferIPv6 := conf.AddressFamily == static.AddressFamilyIPv6 || conf.AddressFamily == static.AddressFamilyPreferIPv6
+
+	var candidate net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		// The link-local addresses cannot be used without their zone.
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+
+		if isIPv6 := ipNet.IP.To4() == nil; isIPv6 == preferIPv6 {
+			return &net.TCPAddr{IP: ipNet.IP}, nil
+		}
+
+		if candidate == nil {
+			candidate = ipNet.IP
+		}
+	}
+
+	if candidate == nil {
+		return nil, fmt.Errorf("no address found on interface %q", conf.Interface)
+	}
+
+	return &net.TCPAddr{IP: candidate}, nil
+}
+
+// withNetwork restricts the TCP dials to a single address family.
+func withNetwork(dial dialFunc, family string) dialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if network == "tcp" {
+			network += family
+		}
+		return dial(ctx, network, address)
+	}
+}
+
+// DialContext dials the address, preferring the addresses of the preferred family.
+func (d *familyDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if network != "tcp" {
+		return d.dial(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if net.ParseIP(host) != nil {
+		return d.dial(ctx, network, address)
+	}
+
+	addrs, err := d.lookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	preferIPv6 := d.family == static.AddressFamilyIPv6 || d.family == static.AddressFamilyPreferIPv6
+	if d.family == "" && len(addrs) > 0 {
+		preferIPv6 = addrs[0].IP.To4() == nil
+	}
+
+	var primaries, fallbacks []string
+	for _, addr := range addrs {
+		isIPv6 := addr.IP.To4() == nil
+		if isIPv6 == preferIPv6 {
+			primaries = append(primaries, net.JoinHostPort(addr.String(), port))
+		} else {
+			fallbacks = append(fallbacks, net.JoinHostPort(addr.String(), port))
+		}
+	}
+
+	if d.family == static.AddressFamilyIPv4 || d.family == static.AddressFamilyIPv6 {
+		if len(primaries) == 0 {
+			return nil, &net.DNSError{Err: "no suitable address found", Name: host}
+		}
+		return d.dialSerial(ctx, network, host, primaries)
+	}
+
+	if len(primaries) == 0 {
+		return d.dialSerial(ctx, network, host, fallbacks)
+	}
+
+	if len(fallbacks) == 0 {
+		return d.dialSerial(ctx, network, host, primaries)
+	}
+
+	return d.dialParallel(ctx, network, host, primaries, fallbacks)
+}
+
+// dialParallel races the dials of the primary addresses and the fallback addresses,
+// the fallback ones being started after the fallback delay, or as soon as the primary ones fail.
+func (d *familyDialer) dialParallel(ctx context.Context, network, host string, primaries, fallbacks []string) (net.Conn, error) {
+	type dialResult struct {
+		conn    net.Conn
+		err     error
+		primary bool
+		done    bool
+	}
+
+	results := make(chan dialResult)
+	returned := make(chan struct{})
+	defer close(returned)
+
+	startRacer := func(ctx context.Context, primary bool) {
+		addresses := primaries
+		if !primary {
+			addresses = fallbacks
+		}
+
+		conn, err := d.dialSerial(ctx, network, host, addresses)
+		select {
+		case results <- dialResult{conn: conn, err: err, primary: primary, done: true}:
+		case <-returned:
+			if conn != nil {
+				_ = conn.Close()
+			}
+		}
+	}
+
+	primaryCtx, primaryCancel := context.WithCancel(ctx)
+	defer primaryCancel()
+	go startRacer(primaryCtx, true)
+
+	fallbackTimer := time.NewTimer(d.fallbackDelay)
+	defer fallbackTimer.Stop()
+
+	var primary, fallback dialResult
+	for {
+		select {
+		case <-fallbackTimer.C:
+			fallbackCtx, fallbackCancel := context.WithCancel(ctx)
+			defer fallbackCancel()
+			go startRacer(fallbackCtx, false)
+
+		case res := <-results:
+			if res.err == nil {
+				return res.conn, nil
+			}
+
+			if res.primary {
+				primary = res
+			} else {
+				fallback = res
+			}
+
+			if primary.done && fallback.done {
+				return nil, primary.err
+			}
+
+			if res.primary && fallbackTimer.Stop() {
+				// The primary addresses failed before the fallback delay, the fallback ones are dialed right away.
+				fallbackTimer.Reset(0)
+			}
+		}
+	}
+}
+
+// dialSerial dials the addresses in order, and returns the first established connection.
+func (d *familyDialer) dialSerial(ctx context.Context, network, host string, addresses []string) (net.Conn, error) {
+	var lastErr error
+	for _, address := range addresses {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		conn, err := d.dial(ctx, network, address)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no address found for %s", host)
+	}
+
+	return nil, lastErr
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"golang.org/x/net/proxy"
+)
+
+// newProxyDialContext returns the function dialing the servers through the given proxy,
+// the proxy itself being dialed with the given function.
+func newProxyDialContext(conf *static.ServersProxy, dial dialFunc) (dialFunc, error) {
+	proxyURL, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https":
+		d := &connectDialer{
+			dial:    dial,
+			address: proxyAddress(proxyURL, "80"),
+		}
+
+		if proxyURL.Scheme == "https" {
+			d.address = proxyAddress(proxyURL, "443")
+			d.tlsConfig = &tls.Config{ServerName: proxyURL.Hostname()}
+		}
+
+		if conf.Username != "" {
+			d.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(conf.Username+":"+conf.Password))
+		}
+
+		return d.DialContext, nil
+
+	case "socks5":
+		var auth *proxy.Auth
+		if conf.Username != "" {
+			auth = &proxy.Auth{User: conf.Username, Password: conf.Password}
+		}
+
+		d, err := proxy.SOCKS5("tcp", proxyAddress(proxyURL, "1080"), auth, dial)
+		if err != nil {
+			return nil, err
+		}
+
+		contextDialer, ok := d.(proxy.ContextDialer)
+		if !ok {
+			return nil, fmt.Errorf("the SOCKS5 dialer does not support contexts")
+		}
+
+		return contextDialer.DialContext, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+}
+
+func proxyAddress(proxyURL *url.URL, defaultPort string) string {
+	if proxyURL.Port() != "" {
+		return proxyURL.Host
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), defaultPort)
+}
+
+// Dial implements the proxy.Dialer interface, to dial the SOCKS5 proxy.
+func (f dialFunc) Dial(network, address string) (net.Conn, error) {
+	return f(context.Background(), network, address)
+}
+
+// DialContext implements the proxy.ContextDialer interface, to dial the SOCKS5 proxy.
+func (f dialFunc) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return f(ctx, network, address)
+}
+
+// connectDialer opens tunnels to the servers with HTTP CONNECT requests to a proxy.
+type connectDialer struct {
+	dial          dialFunc
+	address       string
+	tlsConfig     *tls.Config
+	authorization string
+}
+
+// DialContext opens a tunnel to the given address through the proxy.
+func (d *connectDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dial(ctx, network, d.address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to dial the proxy: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	conn, err = d.connect(conn, address)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
+
+func (d *connectDialer) connect(conn net.Conn, address string) (net.Conn, error) {
+	if d.tlsConfig != nil {
+		tlsConn := tls.Client(conn, d.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("unable to establish TLS with the proxy: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if d.authorization != "" {
+		req.Header.Set("Proxy-Authorization", d.authorization)
+	}
+
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unable to send the CONNECT request to the proxy: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unable to read the CONNECT response of the proxy: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("the proxy refused to connect to %s: %s", address, resp.Status)
+	}
+
+	if br.Buffered() > 0 {
+		// The server spoke first, and its data was read along with the response.
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn is a connection whose first bytes were already read in a buffer.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startConnectProxy starts an HTTP CONNECT proxy requiring the given Proxy-Authorization header.
+func startConnectProxy(t *testing.T, authorization string) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				req, err := http.ReadRequest(bufio.NewReader(conn))
+				if err != nil {
+					return
+				}
+
+				if req.Method != http.MethodConnect || req.Header.Get("Proxy-Authorization") != authorization {
+					_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+					return
+				}
+
+				backend, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+					return
+				}
+				defer backend.Close()
+
+				_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+				pipe(conn, backend)
+			}()
+		}
+	}()
+
+	return listener
+}
+
+// startSOCKS5Proxy starts a SOCKS5 proxy requiring the given username and password.
+func startSOCKS5Proxy(t *testing.T, username, password string) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				address, err := socks5Handshake(conn, username, password)
+				if err != nil {
+					return
+				}
+
+				backend, err := net.Dial("tcp", address)
+				if err != nil {
+					_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer backend.Close()
+
+				_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				pipe(conn, backend)
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func socks5Handshake(conn net.Conn, username, password string) (string, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+		return "", err
+	}
+
+	// Username/password authentication.
+	if _, err := conn.Write([]byte{5, 2}); err != nil {
+		return "", err
+	}
+
+	readString := func() (string, error) {
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", err
+		}
+		value := make([]byte, length[0])
+		_, err := io.ReadFull(conn, value)
+		return string(value), err
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, 1)); err != nil {
+		return "", err
+	}
+	user, err := readString()
+	if err != nil {
+		return "", err
+	}
+	pass, err := readString()
+	if err != nil {
+		return "", err
+	}
+	if user != username || pass != password {
+		_, _ = conn.Write([]byte{1, 1})
+		return "", fmt.Errorf("invalid credentials")
+	}
+	if _, err = conn.Write([]byte{1, 0}); err != nil {
+		return "", err
+	}
+
+	// Connect request, with a domain name or an IPv4 address.
+	request := make([]byte, 4)
+	if _, err = io.ReadFull(conn, request); err != nil {
+		return "", err
+	}
+
+	var host string
+	switch request[3] {
+	case 1:
+		ip := make([]byte, 4)
+		if _, err = io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+		host = net.IP(ip).String()
+	case 3:
+		host, err = readString()
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported address type %d", request[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err = io.ReadFull(conn, port); err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+func pipe(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(b, a)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func TestProxyDialContext(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("backend"))
+	}))
+	defer backend.Close()
+
+	connectProxy := startConnectProxy(t, "Basic dXNlcjpzZWNyZXQ=")
+	defer connectProxy.Close()
+
+	socks5Proxy := startSOCKS5Proxy(t, "user", "secret")
+	defer socks5Proxy.Close()
+
+	testCases := []struct {
+		desc        string
+		proxy       *static.ServersProxy
+		expectedErr bool
+	}{
+		{
+			desc:  "HTTP CONNECT proxy",
+			proxy: &static.ServersProxy{URL: "http://" + connectProxy.Addr().String(), Username: "user", Password: "secret"},
+		},
+		{
+			desc:        "HTTP CONNECT proxy with invalid credentials",
+			proxy:       &static.ServersProxy{URL: "http://" + connectProxy.Addr().String(), Username: "user", Password: "wrong"},
+			expectedErr: true,
+		},
+		{
+			desc:  "SOCKS5 proxy",
+			proxy: &static.ServersProxy{URL: "socks5://" + socks5Proxy.Addr().String(), Username: "user", Password: "secret"},
+		},
+		{
+			desc:        "SOCKS5 proxy with invalid credentials",
+			proxy:       &static.ServersProxy{URL: "socks5://" + socks5Proxy.Addr().String(), Username: "user", Password: "wrong"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			roundTripper, err := createRoundtripper(&static.ServersTransport{Proxy: test.proxy}, nil)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, backend.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := roundTripper.RoundTrip(req)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "backend", string(body))
+		})
+	}
+}
+
+func TestNewProxyDialContext_unsupportedScheme(t *testing.T) {
+	_, err := newProxyDialContext(&static.ServersProxy{URL: "ftp://127.0.0.1"}, (&net.Dialer{}).DialContext)
+	assert.Error(t, err)
+
+	_, err = newProxyDialContext(&static.ServersProxy{URL: "socks5://127.0.0.1"}, func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, fmt.Errorf("unreachable")
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDials records the dialed addresses, and dials them according to their behavior:
+// "ok" connects right away, "fail" fails right away, and "hang" blocks until the dial is canceled.
+type fakeDials struct {
+	mu        sync.Mutex
+	behaviors map[string]string
+	dialed    []string
+}
+
+func (f *fakeDials) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	f.mu.Lock()
+	f.dialed = append(f.dialed, address)
+	behavior := f.behaviors[address]
+	f.mu.Unlock()
+
+	switch behavior {
+	case "ok":
+		conn, _ := net.Pipe()
+		return &addrConn{Conn: conn, address: address}, nil
+	case "hang":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	default:
+		return nil, errors.New("connection refused")
+	}
+}
+
+func (f *fakeDials) getDialed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.dialed...)
+}
+
+type addrConn struct {
+	net.Conn
+	address string
+}
+
+func lookupIPAddr(context.Context, string) ([]net.IPAddr, error) {
+	return []net.IPAddr{
+		{IP: net.ParseIP("10.0.0.1")},
+		{IP: net.ParseIP("fd00::1")},
+		{IP: net.ParseIP("10.0.0.2")},
+	}, nil
+}
+
+func TestFamilyDialer(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		family    string
+		behaviors map[string]string
+		expected  string
+		dialed    []string
+	}{
+		{
+			desc:      "prefer IPv6",
+			family:    static.AddressFamilyPreferIPv6,
+			behaviors: map[string]string{"[fd00::1]:80": "ok", "10.0.0.1:80": "ok"},
+			expected:  "[fd00::1]:80",
+			dialed:    []string{"[fd00::1]:80"},
+		},
+		{
+			desc:      "prefer IPv4",
+			family:    static.AddressFamilyPreferIPv4,
+			behaviors: map[string]string{"[fd00::1]:80": "ok", "10.0.0.1:80": "ok"},
+			expected:  "10.0.0.1:80",
+			dialed:    []string{"10.0.0.1:80"},
+		},
+		{
+			desc:      "prefer IPv4, first address failing",
+			family:    static.AddressFamilyPreferIPv4,
+			behaviors: map[string]string{"[fd00::1]:80": "ok", "10.0.0.2:80": "ok"},
+			expected:  "10.0.0.2:80",
+			dialed:    []string{"10.0.0.1:80", "10.0.0.2:80"},
+		},
+		{
+			desc:      "prefer IPv6, IPv6 failing",
+			family:    static.AddressFamilyPreferIPv6,
+			behaviors: map[string]string{"10.0.0.1:80": "ok"},
+			expected:  "10.0.0.1:80",
+			dialed:    []string{"[fd00::1]:80", "10.0.0.1:80"},
+		},
+		{
+			desc:      "prefer IPv6, IPv6 hanging",
+			family:    static.AddressFamilyPreferIPv6,
+			behaviors: map[string]string{"[fd00::1]:80": "hang", "10.0.0.1:80": "ok"},
+			expected:  "10.0.0.1:80",
+			dialed:    []string{"[fd00::1]:80", "10.0.0.1:80"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dials := &fakeDials{behaviors: test.behaviors}
+			d := &familyDialer{
+				dial:          dials.dial,
+				lookupIPAddr:  lookupIPAddr,
+				family:        test.family,
+				fallbackDelay: 10 * time.Millisecond,
+			}
+
+			conn, err := d.DialContext(context.Background(), "tcp", "backend:80")
+			require.NoError(t, err)
+			defer conn.Close()
+
+			assert.Equal(t, test.expected, conn.(*addrConn).address)
+			assert.Equal(t, test.dialed, dials.getDialed())
+		})
+	}
+}
+
+func TestFamilyDialer_allFailing(t *testing.T) {
+	dials := &fakeDials{}
+	d := &familyDialer{
+		dial:          dials.dial,
+		lookupIPAddr:  lookupIPAddr,
+		family:        static.AddressFamilyPreferIPv6,
+		fallbackDelay: time.Hour,
+	}
+
+	_, err := d.DialContext(context.Background(), "tcp", "backend:80")
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"[fd00::1]:80", "10.0.0.1:80", "10.0.0.2:80"}, dials.getDialed())
+}
+
+func TestFamilyDialer_IPAddress(t *testing.T) {
+	dials := &fakeDials{behaviors: map[string]string{"10.0.0.3:80": "ok"}}
+	d := &familyDialer{
+		dial:          dials.dial,
+		lookupIPAddr:  lookupIPAddr,
+		family:        static.AddressFamilyPreferIPv6,
+		fallbackDelay: time.Hour,
+	}
+
+	conn, err := d.DialContext(context.Background(), "tcp", "10.0.0.3:80")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Equal(t, []string{"10.0.0.3:80"}, dials.getDialed())
+}
+
+func TestNewDialContext(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		addressFamily string
+		expectedErr   bool
+	}{
+		{desc: "resolver order"},
+		{desc: "IPv4 only", addressFamily: static.AddressFamilyIPv4},
+		{desc: "IPv6 only", addressFamily: static.AddressFamilyIPv6},
+		{desc: "prefer IPv4", addressFamily: static.AddressFamilyPreferIPv4},
+		{desc: "prefer IPv6", addressFamily: static.AddressFamilyPreferIPv6},
+		{desc: "unknown family", addressFamily: "ipv5", expectedErr: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			listener, err := net.Listen("tcp4", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer listener.Close()
+
+			dialer := &net.Dialer{}
+			dialContext, err := newDialContext(dialer, &static.ServersTransport{
+				AddressFamily: test.addressFamily,
+				FallbackDelay: types.Duration(time.Second),
+			}, nil)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, time.Second, dialer.FallbackDelay)
+
+			conn, err := dialContext(context.Background(), "tcp", listener.Addr().String())
+			if test.addressFamily == static.AddressFamilyIPv6 {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			_ = conn.Close()
+		})
+	}
+}
+
+func TestLocalAddr(t *testing.T) {
+	laddr, err := localAddr(&static.ServersTransport{})
+	require.NoError(t, err)
+	assert.Nil(t, laddr)
+
+	laddr, err = localAddr(&static.ServersTransport{LocalAddress: "127.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, &net.TCPAddr{IP: net.ParseIP("127.0.0.1")}, laddr)
+
+	_, err = localAddr(&static.ServersTransport{LocalAddress: "localhost"})
+	assert.Error(t, err)
+
+	_, err = localAddr(&static.ServersTransport{Interface: "missing0"})
+	assert.Error(t, err)
+}
+
+func TestLocalAddr_interface(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	require.NoError(t, err)
+
+	var loopback string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 && iface.Flags&net.FlagUp != 0 {
+			loopback = iface.Name
+			break
+		}
+	}
+
+	if loopback == "" {
+		t.Skip("no loopback interface")
+	}
+
+	laddr, err := localAddr(&static.ServersTransport{Interface: loopback})
+	require.NoError(t, err)
+	require.NotNil(t, laddr)
+	assert.True(t, laddr.IP.IsLoopback())
+}
+
+func TestNewDialContext_localAddress(t *testing.T) {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	accepted := make(chan net.Addr, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- conn.RemoteAddr()
+		_ = conn.Close()
+	}()
+
+	dialContext, err := newDialContext(&net.Dialer{}, &static.ServersTransport{LocalAddress: "127.0.0.1"}, nil)
+	require.NoError(t, err)
+
+	conn, err := dialContext(context.Background(), "tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	remoteAddr := <-accepted
+	assert.Equal(t, "127.0.0.1", remoteAddr.(*net.TCPAddr).IP.String())
+}
@@ -22,11 +22,12 @@ type InternalHandlers struct {
 	rest       http.Handler
 	prometheus http.Handler
 	ping       http.Handler
+	acmeServer http.Handler
 	serviceManager
 }
 
 // NewInternalHandlers creates a new InternalHandlers.
-func NewInternalHandlers(api func(configuration *runtime.Configuration) http.Handler, configuration *runtime.Configuration, rest http.Handler, metricsHandler http.Handler, pingHandler http.Handler, dashboard http.Handler, next serviceManager) *InternalHandlers {
+func NewInternalHandlers(api func(configuration *runtime.Configuration) http.Handler, configuration *runtime.Configuration, rest http.Handler, metricsHandler http.Handler, pingHandler http.Handler, acmeServerHandler http.Handler, dashboard http.Handler, next serviceManager) *InternalHandlers {
 	var apiHandler http.Handler
 	if api != nil {
 		apiHandler = api(configuration)
@@ -38,6 +39,7 @@ func NewInternalHandlers(api func(configuration *runtime.Configuration) http.Han
 		rest:           rest,
 		prometheus:     metricsHandler,
 		ping:           pingHandler,
+		acmeServer:     acmeServerHandler,
 		serviceManager: next,
 	}
 }
@@ -82,6 +84,12 @@ func (m *InternalHandlers) get(serviceName string) (http.Handler, error) {
 		}
 		return m.ping, nil
 
+	case "acmeserver@internal":
+		if m.acmeServer == nil {
+			return nil, errors.New("ACME server is not enabled")
+		}
+		return m.acmeServer, nil
+
 	case "prometheus@internal":
 		if m.prometheus == nil {
 			return nil, errors.New("prometheus is not enabled")
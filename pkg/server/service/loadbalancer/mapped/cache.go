@@ -0,0 +1,209 @@
+package mapped
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+const (
+	defaultCacheTTL         = time.Minute
+	defaultNegativeCacheTTL = 10 * time.Second
+	defaultCacheSize        = 100000
+)
+
+// cache holds the servers of the hosts looked up in the source, evicting the least recently used hosts.
+// Concurrent lookups of the same host are merged,
+// and the server of a host is kept while the source is unavailable.
+type cache struct {
+	name        string
+	source      source
+	ttl         time.Duration
+	negativeTTL time.Duration
+	size        int
+
+	mu      sync.Mutex
+	hosts   map[string]*list.Element
+	lru     *list.List // *cacheEntry, most recently used first
+	pending map[string]*pendingLookup
+}
+
+type cacheEntry struct {
+	host    string
+	server  *url.URL // nil when the host is not mapped
+	expires time.Time
+}
+
+type pendingLookup struct {
+	done   chan struct{}
+	server *url.URL
+	err    error
+}
+
+func newCache(name string, src source, ttl, negativeTTL time.Duration, size int) *cache {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	if negativeTTL <= 0 {
+		negativeTTL = defaultNegativeCacheTTL
+	}
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+
+	return &cache{
+		name:        name,
+		source:      src,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		size:        size,
+		hosts:       make(map[string]*list.Element),
+		lru:         list.New(),
+		pending:     make(map[string]*pendingLookup),
+	}
+}
+
+// get returns the server of the host, or nil if the host is not mapped.
+func (c *cache) get(host string) (*url.URL, error) {
+	c.mu.Lock()
+
+	var stale *cacheEntry
+	if elt, ok := c.hosts[host]; ok {
+		entry := elt.Value.(*cacheEntry)
+		if time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(elt)
+			c.mu.Unlock()
+			return entry.server, nil
+		}
+		stale = entry
+	}
+
+	if p, ok := c.pending[host]; ok {
+		c.mu.Unlock()
+		<-p.done
+		return p.server, p.err
+	}
+
+	p := &pendingLookup{done: make(chan struct{})}
+	c.pending[host] = p
+	c.mu.Unlock()
+
+	p.server, p.err = c.lookup(host)
+
+	c.mu.Lock()
+	delete(c.pending, host)
+	switch {
+	case p.err == nil:
+		ttl := c.ttl
+		if p.server == nil {
+			ttl = c.negativeTTL
+		}
+		c.add(host, p.server, ttl)
+	case stale != nil:
+		log.FromContext(log.With(context.Background(), log.Str(log.ServiceName, c.name))).
+			Warnf("Unable to look up the server of the host %s, keeping the previous one: %v", host, p.err)
+
+		// The source is not queried again for this host until the negative TTL expires.
+		p.server, p.err = stale.server, nil
+		c.add(host, p.server, c.negativeTTL)
+	}
+	c.mu.Unlock()
+
+	close(p.done)
+
+	return p.server, p.err
+}
+
+func (c *cache) lookup(host string) (*url.URL, error) {
+	rawURL, err := c.source.lookup(host)
+	if err != nil || rawURL == "" {
+		return nil, err
+	}
+
+	return parseServer(rawURL)
+}
+
+// add caches the server of the host, evicting the least recently used host if the cache is full.
+func (c *cache) add(host string, server *url.URL, ttl time.Duration) {
+	entry := &cacheEntry{host: host, server: server, expires: time.Now().Add(ttl)}
+
+	if elt, ok := c.hosts[host]; ok {
+		elt.Value = entry
+		c.lru.MoveToFront(elt)
+		return
+	}
+
+	c.hosts[host] = c.lru.PushFront(entry)
+
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.hosts, oldest.Value.(*cacheEntry).host)
+	}
+}
+
+// caches holds the caches of the Mapped services, by service name,
+// so that the lookups are not lost on each configuration reload.
+var caches = cacheRegistry{caches: make(map[string]*registeredCache)}
+
+type cacheRegistry struct {
+	mu     sync.Mutex
+	caches map[string]*registeredCache
+}
+
+type registeredCache struct {
+	config dynamic.Mapped
+	cache  *cache
+}
+
+// getCache returns the cache of the source of a Mapped service, or nil if it has no source.
+// The cache is created on the first call, and when the source configuration of the service changes.
+func getCache(serviceName string, config dynamic.Mapped) (*cache, error) {
+	// Only the source configuration matters.
+	config = dynamic.Mapped{
+		KV:               config.KV,
+		HTTP:             config.HTTP,
+		CacheTTL:         config.CacheTTL,
+		NegativeCacheTTL: config.NegativeCacheTTL,
+		CacheSize:        config.CacheSize,
+	}
+
+	if config.KV == nil && config.HTTP == nil {
+		return nil, nil
+	}
+
+	if config.KV != nil && config.HTTP != nil {
+		return nil, errors.New("kv and http are mutually exclusive")
+	}
+
+	caches.mu.Lock()
+	defer caches.mu.Unlock()
+
+	if registered, ok := caches.caches[serviceName]; ok && reflect.DeepEqual(registered.config, config) {
+		return registered.cache, nil
+	}
+
+	var src source
+	var err error
+	if config.KV != nil {
+		src, err = newKVSource(*config.KV)
+	} else {
+		src, err = newHTTPSource(*config.HTTP)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := newCache(serviceName, src, time.Duration(config.CacheTTL), time.Duration(config.NegativeCacheTTL), config.CacheSize)
+
+	caches.caches[serviceName] = &registeredCache{config: *config.DeepCopy(), cache: c}
+
+	return c, nil
+}
@@ -0,0 +1,180 @@
+package mapped
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapSource is a source backed by a map, failing when err is set.
+type mapSource struct {
+	mu      sync.Mutex
+	servers map[string]string
+	err     error
+	lookups int32
+	delay   time.Duration
+}
+
+func (m *mapSource) lookup(host string) (string, error) {
+	atomic.AddInt32(&m.lookups, 1)
+	time.Sleep(m.delay)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.servers[host], m.err
+}
+
+func TestCache_get(t *testing.T) {
+	src := &mapSource{servers: map[string]string{"a.com": "http://10.0.0.1", "b.com": "http://10.0.0.2"}}
+	c := newCache("test", src, time.Hour, time.Hour, 0)
+
+	server, err := c.get("a.com")
+	require.NoError(t, err)
+	assert.Equal(t, "http://10.0.0.1", server.String())
+
+	server, err = c.get("unknown.com")
+	require.NoError(t, err)
+	assert.Nil(t, server)
+
+	_, _ = c.get("a.com")
+	_, _ = c.get("unknown.com")
+	assert.Equal(t, int32(2), src.lookups)
+}
+
+func TestCache_get_eviction(t *testing.T) {
+	src := &mapSource{servers: map[string]string{"a.com": "http://10.0.0.1", "b.com": "http://10.0.0.2", "c.com": "http://10.0.0.3"}}
+	c := newCache("test", src, time.Hour, time.Hour, 2)
+
+	_, _ = c.get("a.com")
+	_, _ = c.get("b.com")
+	// a.com becomes the most recently used host, b.com is evicted.
+	_, _ = c.get("a.com")
+	_, _ = c.get("c.com")
+
+	assert.Len(t, c.hosts, 2)
+	assert.Contains(t, c.hosts, "a.com")
+	assert.Contains(t, c.hosts, "c.com")
+	assert.Equal(t, int32(3), src.lookups)
+}
+
+func TestCache_get_expiration(t *testing.T) {
+	src := &mapSource{servers: map[string]string{"a.com": "http://10.0.0.1"}}
+	c := newCache("test", src, time.Hour, time.Nanosecond, 0)
+
+	_, _ = c.get("unknown.com")
+	_, _ = c.get("unknown.com")
+	assert.Equal(t, int32(2), src.lookups)
+
+	src.mu.Lock()
+	src.servers["unknown.com"] = "http://10.0.0.2"
+	src.mu.Unlock()
+
+	server, err := c.get("unknown.com")
+	require.NoError(t, err)
+	assert.Equal(t, "http://10.0.0.2", server.String())
+}
+
+func TestCache_get_staleOnError(t *testing.T) {
+	src := &mapSource{servers: map[string]string{"a.com": "http://10.0.0.1"}}
+	c := newCache("test", src, time.Nanosecond, time.Hour, 0)
+
+	_, err := c.get("a.com")
+	require.NoError(t, err)
+
+	src.mu.Lock()
+	src.err = errors.New("unavailable")
+	src.mu.Unlock()
+
+	// The previous server is kept, and the source is not queried again until the negative TTL expires.
+	server, err := c.get("a.com")
+	require.NoError(t, err)
+	assert.Equal(t, "http://10.0.0.1", server.String())
+
+	_, _ = c.get("a.com")
+	assert.Equal(t, int32(2), src.lookups)
+
+	// Without a previous server, the error is returned.
+	_, err = c.get("b.com")
+	assert.Error(t, err)
+}
+
+func TestCache_get_concurrent(t *testing.T) {
+	src := &mapSource{servers: map[string]string{"a.com": "http://10.0.0.1"}, delay: 50 * time.Millisecond}
+	c := newCache("test", src, time.Hour, time.Hour, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			server, err := c.get("a.com")
+			assert.NoError(t, err)
+			assert.Equal(t, "http://10.0.0.1", server.String())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&src.lookups))
+}
+
+func TestGetCache(t *testing.T) {
+	config := dynamic.Mapped{
+		HTTP:    &dynamic.MappedHTTP{Endpoint: "http://localhost/lookup"},
+		Entries: []dynamic.MappedEntry{{Host: "a.com", URL: "http://10.0.0.1"}},
+	}
+
+	c, err := getCache("TestGetCache", config)
+	require.NoError(t, err)
+
+	// The cache is kept when only the entries change.
+	config.Entries = nil
+	same, err := getCache("TestGetCache", config)
+	require.NoError(t, err)
+	assert.Same(t, c, same)
+
+	config.CacheSize = 10
+	other, err := getCache("TestGetCache", config)
+	require.NoError(t, err)
+	assert.NotSame(t, c, other)
+}
+
+func TestKVSource_lookup(t *testing.T) {
+	src := &kvSource{client: &memoryKV{pairs: map[string][]byte{"traefik/hosts/a.com": []byte("http://10.0.0.1\n")}}, rootKey: defaultRootKey}
+
+	rawURL, err := src.lookup("a.com")
+	require.NoError(t, err)
+	assert.Equal(t, "http://10.0.0.1", rawURL)
+
+	rawURL, err = src.lookup("b.com")
+	require.NoError(t, err)
+	assert.Empty(t, rawURL)
+
+	rawURL, err = src.lookup("../a.com")
+	require.NoError(t, err)
+	assert.Empty(t, rawURL)
+}
+
+// memoryKV is an in-memory KV store.
+type memoryKV struct {
+	store.Store
+
+	pairs map[string][]byte
+}
+
+func (m *memoryKV) Get(key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	value, ok := m.pairs[key]
+	if !ok {
+		return nil, store.ErrKeyNotFound
+	}
+
+	return &store.KVPair{Key: key, Value: value}, nil
+}
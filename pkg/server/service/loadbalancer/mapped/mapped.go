@@ -0,0 +1,126 @@
+// Package mapped implements a service forwarding the requests to the server mapped to their host,
+// declared in the dynamic configuration, or looked up at request time in a KV store or from an HTTP endpoint.
+package mapped
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares/requestdecorator"
+)
+
+// Mapped is an http.Handler forwarding the requests to the server mapped to their host,
+// and the requests whose host is not mapped to the fallback handler.
+type Mapped struct {
+	name     string
+	entries  map[string]*url.URL
+	cache    *cache
+	fwd      http.Handler
+	fallback http.Handler
+}
+
+// New creates a Mapped handler.
+// The requests are forwarded with fwd, and the ones whose host is not mapped to fallback, or answered with a 404 status code if it is nil.
+func New(ctx context.Context, name string, config dynamic.Mapped, fwd, fallback http.Handler) (*Mapped, error) {
+	entries := make(map[string]*url.URL, len(config.Entries))
+	for _, entry := range config.Entries {
+		if entry.Host == "" {
+			return nil, fmt.Errorf("no host for the server %s", entry.URL)
+		}
+
+		server, err := parseServer(entry.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server of the host %s: %w", entry.Host, err)
+		}
+
+		entries[strings.ToLower(entry.Host)] = server
+	}
+
+	c, err := getCache(name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if fallback == nil {
+		fallback = http.NotFoundHandler()
+	}
+
+	log.FromContext(ctx).Debugf("Creating mapped service with %d entries", len(entries))
+
+	return &Mapped{
+		name:     name,
+		entries:  entries,
+		cache:    c,
+		fwd:      fwd,
+		fallback: fallback,
+	}, nil
+}
+
+func (m *Mapped) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	host := requestdecorator.GetCanonizedHost(req.Context())
+	if host == "" {
+		host = hostWithoutPort(req.Host)
+	}
+	host = strings.ToLower(host)
+
+	server, err := m.lookup(host)
+	if err != nil {
+		log.FromContext(req.Context()).Errorf("Unable to look up the server of the host %s: %v", host, err)
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	if server == nil {
+		m.fallback.ServeHTTP(rw, req)
+		return
+	}
+
+	u := *req.URL
+	u.Scheme = server.Scheme
+	u.Host = server.Host
+
+	newReq := *req
+	newReq.URL = &u
+	m.fwd.ServeHTTP(rw, &newReq)
+}
+
+// lookup returns the server of the host, from the entries, then from the source, or nil if the host is not mapped.
+func (m *Mapped) lookup(host string) (*url.URL, error) {
+	if server, ok := m.entries[host]; ok {
+		return server, nil
+	}
+
+	if m.cache == nil {
+		return nil, nil
+	}
+
+	return m.cache.get(host)
+}
+
+// parseServer parses the URL of a server, which must have a scheme and a host.
+func parseServer(rawURL string) (*url.URL, error) {
+	server, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+
+	if server.Scheme == "" || server.Host == "" {
+		return nil, fmt.Errorf("the URL %q must have a scheme and a host", rawURL)
+	}
+
+	return server, nil
+}
+
+func hostWithoutPort(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
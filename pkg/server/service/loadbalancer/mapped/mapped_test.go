@@ -0,0 +1,175 @@
+package mapped
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.Mapped
+		expectedErr bool
+	}{
+		{
+			desc: "entries",
+			config: dynamic.Mapped{
+				Entries: []dynamic.MappedEntry{{Host: "example.com", URL: "http://10.0.0.1:8080"}},
+			},
+		},
+		{
+			desc: "entry without host",
+			config: dynamic.Mapped{
+				Entries: []dynamic.MappedEntry{{URL: "http://10.0.0.1:8080"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "entry without scheme",
+			config: dynamic.Mapped{
+				Entries: []dynamic.MappedEntry{{Host: "example.com", URL: "10.0.0.1:8080"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "kv and http",
+			config: dynamic.Mapped{
+				KV:   &dynamic.MappedKV{Backend: "redis", Endpoints: []string{"localhost:6379"}},
+				HTTP: &dynamic.MappedHTTP{Endpoint: "http://localhost/lookup"},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "unsupported kv backend",
+			config: dynamic.Mapped{
+				KV: &dynamic.MappedKV{Backend: "foo"},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "invalid http endpoint",
+			config: dynamic.Mapped{
+				HTTP: &dynamic.MappedHTTP{Endpoint: "/lookup"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), "mapped-"+test.desc, test.config, http.NotFoundHandler(), nil)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMapped_ServeHTTP(t *testing.T) {
+	var lookups int
+	lookup := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		lookups++
+		assert.Equal(t, "secret", req.Header.Get("Authorization"))
+
+		switch req.URL.Query().Get("host") {
+		case "customer.com":
+			_, _ = rw.Write([]byte("http://10.0.0.2:8080\n"))
+		case "broken.com":
+			rw.WriteHeader(http.StatusInternalServerError)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer lookup.Close()
+
+	fwd := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Server", req.URL.Scheme+"://"+req.URL.Host)
+		rw.Header().Set("X-Path", req.URL.RequestURI())
+	})
+
+	fallback := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	config := dynamic.Mapped{
+		Entries: []dynamic.MappedEntry{{Host: "Static.com", URL: "https://10.0.0.1"}},
+		HTTP: &dynamic.MappedHTTP{
+			Endpoint: lookup.URL + "/lookup",
+			Headers:  map[string]string{"Authorization": "secret"},
+		},
+	}
+
+	handler, err := New(context.Background(), "TestMapped_ServeHTTP", config, fwd, fallback)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		host           string
+		expectedStatus int
+		expectedServer string
+	}{
+		{
+			desc:           "entry",
+			host:           "static.com",
+			expectedStatus: http.StatusOK,
+			expectedServer: "https://10.0.0.1",
+		},
+		{
+			desc:           "looked up host",
+			host:           "Customer.com:443",
+			expectedStatus: http.StatusOK,
+			expectedServer: "http://10.0.0.2:8080",
+		},
+		{
+			desc:           "unknown host",
+			host:           "unknown.com",
+			expectedStatus: http.StatusTeapot,
+		},
+		{
+			desc:           "source error",
+			host:           "broken.com",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "http://"+test.host+"/foo?bar=baz", nil)
+		rw := httptest.NewRecorder()
+
+		handler.ServeHTTP(rw, req)
+
+		assert.Equal(t, test.expectedStatus, rw.Code, test.desc)
+		assert.Equal(t, test.expectedServer, rw.Header().Get("X-Server"), test.desc)
+		if test.expectedServer != "" {
+			assert.Equal(t, "/foo?bar=baz", rw.Header().Get("X-Path"), test.desc)
+		}
+	}
+
+	// The lookups of the known and unknown hosts are cached, not the errors.
+	lookups = 0
+	for _, host := range []string{"customer.com", "unknown.com", "broken.com"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://"+host, nil))
+	}
+	assert.Equal(t, 1, lookups)
+}
+
+func TestMapped_ServeHTTP_noFallback(t *testing.T) {
+	handler, err := New(context.Background(), "TestMapped_ServeHTTP_noFallback", dynamic.Mapped{}, http.NotFoundHandler(), nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+}
@@ -0,0 +1,158 @@
+package mapped
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/provider/kv"
+)
+
+const (
+	defaultRootKey     = "traefik/hosts"
+	defaultHTTPTimeout = 5 * time.Second
+	maxResponseSize    = 4096
+)
+
+// source looks up the servers of the hosts outside of the dynamic configuration.
+type source interface {
+	// lookup returns the URL of the server of the host, or an empty string if the host is not mapped.
+	lookup(host string) (string, error)
+}
+
+// kvSource holds the URL of the server of each host under the root key of a KV store.
+type kvSource struct {
+	client  store.Store
+	rootKey string
+}
+
+func newKVSource(config dynamic.MappedKV) (*kvSource, error) {
+	var backend store.Backend
+	switch config.Backend {
+	case "consul":
+		backend = store.CONSUL
+	case "etcd":
+		backend = store.ETCDV3
+	case "zookeeper":
+		backend = store.ZK
+	case "redis":
+		backend = store.REDIS
+	default:
+		return nil, fmt.Errorf("unsupported KV backend: %q", config.Backend)
+	}
+
+	tlsConfig, err := config.TLS.CreateTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kv.NewStore(backend, config.Endpoints, config.Username, config.Password, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	rootKey := defaultRootKey
+	if config.RootKey != "" {
+		rootKey = path.Clean(config.RootKey)
+	}
+
+	return &kvSource{client: client, rootKey: rootKey}, nil
+}
+
+func (s *kvSource) lookup(host string) (string, error) {
+	// The host is a single key under the root key.
+	if host == "" || strings.Contains(host, "/") {
+		return "", nil
+	}
+
+	pair, err := s.client.Get(path.Join(s.rootKey, host), nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(pair.Value)), nil
+}
+
+// httpSource looks up the server of each host from an HTTP endpoint,
+// answering the URL of the server of the host given in the host query parameter, or a 404 status code for an unknown host.
+type httpSource struct {
+	client   *http.Client
+	endpoint *url.URL
+	headers  map[string]string
+}
+
+func newHTTPSource(config dynamic.MappedHTTP) (*httpSource, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q: the URL must have a scheme and a host", config.Endpoint)
+	}
+
+	tlsConfig, err := config.TLS.CreateTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &httpSource{
+		client:   &http.Client{Timeout: timeout, Transport: transport},
+		endpoint: endpoint,
+		headers:  config.Headers,
+	}, nil
+}
+
+func (s *httpSource) lookup(host string) (string, error) {
+	u := *s.endpoint
+	query := u.Query()
+	query.Set("host", host)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxResponseSize))
+	if err != nil {
+		return "", err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return strings.TrimSpace(string(body)), nil
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected status %d from %s", resp.StatusCode, s.endpoint)
+	}
+}
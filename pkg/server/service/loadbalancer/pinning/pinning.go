@@ -0,0 +1,139 @@
+package pinning
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// Balancer is a load-balancer listing its servers.
+type Balancer interface {
+	Servers() []*url.URL
+}
+
+// Pinning is an http.Handler forwarding the requests with a valid pinning header to the server they are pinned to,
+// and the others to the load-balancer.
+// The pinning header is always removed before forwarding the request.
+type Pinning struct {
+	next       http.Handler
+	balancer   Balancer
+	fwd        http.Handler
+	headerName string
+	secret     []byte
+	checker    *ip.Checker
+}
+
+// New creates a new pinning handler.
+// The requests are forwarded to next, unless they are pinned to a server of the balancer, then they are forwarded with fwd.
+func New(ctx context.Context, next http.Handler, balancer Balancer, fwd http.Handler, config dynamic.Pinning) (*Pinning, error) {
+	if config.Secret == "" {
+		return nil, errors.New("the pinning secret is required")
+	}
+
+	checker, err := ip.NewChecker(config.SourceRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pinning source range: %w", err)
+	}
+
+	headerName := config.HeaderName
+	if headerName == "" {
+		headerName = "X-Traefik-Pin"
+	}
+
+	log.FromContext(ctx).Debugf("Pinning header name: %s", headerName)
+
+	return &Pinning{
+		next:       next,
+		balancer:   balancer,
+		fwd:        fwd,
+		headerName: headerName,
+		secret:     []byte(config.Secret),
+		checker:    checker,
+	}, nil
+}
+
+func (p *Pinning) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	value := req.Header.Get(p.headerName)
+	if value == "" {
+		p.next.ServeHTTP(rw, req)
+		return
+	}
+
+	req.Header.Del(p.headerName)
+
+	server, err := p.getServer(req, value)
+	if err != nil {
+		log.FromContext(req.Context()).Debugf("Ignoring the pinning header: %v", err)
+		p.next.ServeHTTP(rw, req)
+		return
+	}
+
+	newReq := *req
+	newReq.URL = server
+	p.fwd.ServeHTTP(rw, &newReq)
+}
+
+// getServer returns the URL of the server the request is pinned to, with the path and query of the request.
+func (p *Pinning) getServer(req *http.Request, value string) (*url.URL, error) {
+	if err := p.checker.IsAuthorized(req.RemoteAddr); err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(value, ";")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed value %q", value)
+	}
+
+	serverURL, rawExpires, signature := parts[0], parts[1], parts[2]
+
+	expectedSignature := sign(p.secret, serverURL, rawExpires)
+	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+		return nil, errors.New("invalid signature")
+	}
+
+	expires, err := strconv.ParseInt(rawExpires, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiration %q: %w", rawExpires, err)
+	}
+
+	if time.Now().Unix() > expires {
+		return nil, fmt.Errorf("expired since %s", time.Unix(expires, 0).UTC().Format(time.RFC3339))
+	}
+
+	for _, server := range p.balancer.Servers() {
+		if server.String() != serverURL {
+			continue
+		}
+
+		u := *req.URL
+		u.Scheme = server.Scheme
+		u.Host = server.Host
+		return &u, nil
+	}
+
+	return nil, fmt.Errorf("unknown or unavailable server %s", serverURL)
+}
+
+// Value returns the value of the pinning header, pinning the requests to the given server until the expiration.
+func Value(secret, serverURL string, expires time.Time) string {
+	rawExpires := strconv.FormatInt(expires.Unix(), 10)
+	return serverURL + ";" + rawExpires + ";" + sign([]byte(secret), serverURL, rawExpires)
+}
+
+func sign(secret []byte, serverURL, rawExpires string) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(serverURL + ";" + rawExpires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,120 @@
+package pinning
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type balancer []*url.URL
+
+func (b balancer) Servers() []*url.URL {
+	return b
+}
+
+func TestPinning(t *testing.T) {
+	servers := balancer{testhelpers.MustParseURL("http://10.0.0.1:80"), testhelpers.MustParseURL("http://10.0.0.2:80")}
+
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+		value      string
+		expected   string
+	}{
+		{
+			desc:       "no header",
+			remoteAddr: "192.168.1.1:1234",
+			expected:   "lb",
+		},
+		{
+			desc:       "pinned",
+			remoteAddr: "192.168.1.1:1234",
+			value:      Value("secret", "http://10.0.0.2:80", time.Now().Add(time.Minute)),
+			expected:   "http://10.0.0.2:80/foo?bar=baz",
+		},
+		{
+			desc:       "untrusted source",
+			remoteAddr: "10.10.10.10:1234",
+			value:      Value("secret", "http://10.0.0.2:80", time.Now().Add(time.Minute)),
+			expected:   "lb",
+		},
+		{
+			desc:       "invalid signature",
+			remoteAddr: "192.168.1.1:1234",
+			value:      Value("other", "http://10.0.0.2:80", time.Now().Add(time.Minute)),
+			expected:   "lb",
+		},
+		{
+			desc:       "expired",
+			remoteAddr: "192.168.1.1:1234",
+			value:      Value("secret", "http://10.0.0.2:80", time.Now().Add(-time.Minute)),
+			expected:   "lb",
+		},
+		{
+			desc:       "unknown server",
+			remoteAddr: "192.168.1.1:1234",
+			value:      Value("secret", "http://10.0.0.3:80", time.Now().Add(time.Minute)),
+			expected:   "lb",
+		},
+		{
+			desc:       "malformed value",
+			remoteAddr: "192.168.1.1:1234",
+			value:      "http://10.0.0.2:80",
+			expected:   "lb",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var header string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				header = req.Header.Get("X-Pin")
+				_, _ = rw.Write([]byte("lb"))
+			})
+			fwd := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				header = req.Header.Get("X-Pin")
+				_, _ = rw.Write([]byte(req.URL.String()))
+			})
+
+			handler, err := New(context.Background(), next, servers, fwd, dynamic.Pinning{
+				HeaderName:  "X-Pin",
+				Secret:      "secret",
+				SourceRange: []string{"192.168.1.0/24"},
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo.localhost/foo?bar=baz", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.value != "" {
+				req.Header.Set("X-Pin", test.value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Body.String())
+			assert.Empty(t, header)
+		})
+	}
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	next := http.NotFoundHandler()
+
+	_, err := New(context.Background(), next, balancer{}, next, dynamic.Pinning{SourceRange: []string{"192.168.1.0/24"}})
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, balancer{}, next, dynamic.Pinning{Secret: "secret"})
+	assert.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/api"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/config/schema"
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/safe"
@@ -14,27 +15,30 @@ import (
 type ManagerFactory struct {
 	metricsRegistry metrics.Registry
 
-	defaultRoundTripper http.RoundTripper
+	defaultRoundTripper   http.RoundTripper
+	resolverRoundTrippers map[string]http.RoundTripper
 
 	api              func(configuration *runtime.Configuration) http.Handler
 	restHandler      http.Handler
 	dashboardHandler http.Handler
 	metricsHandler   http.Handler
 	pingHandler      http.Handler
+	acmeServer       http.Handler
 
 	routinesPool *safe.Pool
 }
 
 // NewManagerFactory creates a new ManagerFactory.
-func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *safe.Pool, metricsRegistry metrics.Registry) *ManagerFactory {
+func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *safe.Pool, metricsRegistry metrics.Registry, acmeResolvers []api.ACMEResolver, connectionTables api.ConnectionTables, pathStatistics api.PathStatistics, tlsStores api.TLSStores, providerResyncer api.ProviderResyncer, providerSchemas map[string]*schema.Schema, secrets api.SecretsConcealer) *ManagerFactory {
 	factory := &ManagerFactory{
-		metricsRegistry:     metricsRegistry,
-		defaultRoundTripper: setupDefaultRoundTripper(staticConfiguration.ServersTransport),
-		routinesPool:        routinesPool,
+		metricsRegistry: metricsRegistry,
+		routinesPool:    routinesPool,
 	}
 
+	factory.defaultRoundTripper, factory.resolverRoundTrippers = setupRoundTrippers(staticConfiguration.ServersTransport)
+
 	if staticConfiguration.API != nil {
-		factory.api = api.NewBuilder(staticConfiguration)
+		factory.api = api.NewBuilder(staticConfiguration, acmeResolvers, connectionTables, pathStatistics, tlsStores, providerResyncer, providerSchemas, secrets)
 
 		if staticConfiguration.API.Dashboard {
 			factory.dashboardHandler = http.FileServer(staticConfiguration.API.DashboardAssets)
@@ -56,11 +60,16 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 		factory.pingHandler = staticConfiguration.Ping
 	}
 
+	// Same as above, the check prevents the affectation of a typed nil.
+	if staticConfiguration.ACMEServer != nil {
+		factory.acmeServer = staticConfiguration.ACMEServer
+	}
+
 	return factory
 }
 
 // Build creates a service manager.
 func (f *ManagerFactory) Build(configuration *runtime.Configuration) *InternalHandlers {
-	svcManager := NewManager(configuration.Services, f.defaultRoundTripper, f.metricsRegistry, f.routinesPool)
-	return NewInternalHandlers(f.api, configuration, f.restHandler, f.metricsHandler, f.pingHandler, f.dashboardHandler, svcManager)
+	svcManager := NewManager(configuration.Services, f.defaultRoundTripper, f.resolverRoundTrippers, f.metricsRegistry, f.routinesPool)
+	return NewInternalHandlers(f.api, configuration, f.restHandler, f.metricsHandler, f.pingHandler, f.acmeServer, f.dashboardHandler, svcManager)
 }
@@ -6,12 +6,15 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/http/httputil"
+	"net/textproto"
 	"net/url"
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/types"
 )
 
@@ -23,7 +26,7 @@ const StatusClientClosedRequestText = "Client Closed Request"
 
 func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwarding, defaultRoundTripper http.RoundTripper, bufferPool httputil.BufferPool, responseModifier func(*http.Response) error) (http.Handler, error) {
 	var flushInterval types.Duration
-	if responseForwarding != nil {
+	if responseForwarding != nil && responseForwarding.FlushInterval != "" {
 		err := flushInterval.Set(responseForwarding.FlushInterval)
 		if err != nil {
 			return nil, fmt.Errorf("error creating flush interval: %v", err)
@@ -32,6 +35,10 @@ func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwar
 	if flushInterval == 0 {
 		flushInterval = types.Duration(100 * time.Millisecond)
 	}
+	if responseForwarding != nil && responseForwarding.DisableBuffering {
+		// A negative flush interval flushes immediately after each write.
+		flushInterval = -1
+	}
 
 	proxy := &httputil.ReverseProxy{
 		Director: func(outReq *http.Request) {
@@ -81,22 +88,7 @@ func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwar
 		ModifyResponse: responseModifier,
 		BufferPool:     bufferPool,
 		ErrorHandler: func(w http.ResponseWriter, request *http.Request, err error) {
-			statusCode := http.StatusInternalServerError
-
-			switch {
-			case err == io.EOF:
-				statusCode = http.StatusBadGateway
-			case err == context.Canceled:
-				statusCode = StatusClientClosedRequest
-			default:
-				if e, ok := err.(net.Error); ok {
-					if e.Timeout() {
-						statusCode = http.StatusGatewayTimeout
-					} else {
-						statusCode = http.StatusBadGateway
-					}
-				}
-			}
+			statusCode := errorStatusCode(err)
 
 			log.Debugf("'%d %s' caused by: %v", statusCode, statusText(statusCode), err)
 			w.WriteHeader(statusCode)
@@ -107,7 +99,59 @@ func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwar
 		},
 	}
 
-	return proxy, nil
+	var handler http.Handler = proxy
+	if responseForwarding != nil && responseForwarding.Strict {
+		handler = newStrictProxy(proxy)
+	}
+
+	return relayInformationalResponses(handler), nil
+}
+
+// informationalRelay relays the informational responses of the services, such as 103 Early Hints, to the clients.
+type informationalRelay struct {
+	next http.Handler
+}
+
+// relayInformationalResponses relays the informational responses of the services to the clients,
+// unless httputil.ReverseProxy already does, from Go 1.20.
+func relayInformationalResponses(next http.Handler) http.Handler {
+	if reverseProxyRelaysInformational {
+		return next
+	}
+
+	return &informationalRelay{next: next}
+}
+
+func (r *informationalRelay) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			// The server sends its own 100 Continue to the clients expecting it, when the request body is read.
+			if code != http.StatusContinue && middlewares.IsInformational(code) {
+				middlewares.SendInformational(rw, req, code, http.Header(header))
+			}
+			return nil
+		},
+	}
+
+	r.next.ServeHTTP(rw, req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
+
+// errorStatusCode returns the HTTP status reporting an error of the forward of a request.
+func errorStatusCode(err error) int {
+	switch {
+	case err == io.EOF:
+		return http.StatusBadGateway
+	case err == context.Canceled:
+		return StatusClientClosedRequest
+	default:
+		if e, ok := err.(net.Error); ok {
+			if e.Timeout() {
+				return http.StatusGatewayTimeout
+			}
+			return http.StatusBadGateway
+		}
+		return http.StatusInternalServerError
+	}
 }
 
 func statusText(statusCode int) string {
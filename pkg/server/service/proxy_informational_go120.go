@@ -0,0 +1,6 @@
+// +build go1.20
+
+package service
+
+// reverseProxyRelaysInformational is whether httputil.ReverseProxy relays the informational responses of the services.
+const reverseProxyRelaysInformational = true
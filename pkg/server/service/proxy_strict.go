@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	grpcStatusHeader  = "Grpc-Status"
+	grpcMessageHeader = "Grpc-Message"
+)
+
+// strictProxy forwards the gRPC streams strictly:
+// the messages are flushed to the client as soon as they are received,
+// the gRPC status of the stream always reaches the client in the trailers,
+// and the errors are reported with a gRPC status instead of an HTTP one.
+// The other requests are forwarded as usual.
+type strictProxy struct {
+	proxy     http.Handler
+	grpcProxy http.Handler
+}
+
+func newStrictProxy(proxy *httputil.ReverseProxy) http.Handler {
+	transport := proxy.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	grpcProxy := *proxy
+	grpcProxy.Transport = &grpcRoundTripper{next: transport}
+	// A negative flush interval flushes immediately after each write,
+	// as the bidirectional streams expect each message to be delivered before the next one is sent.
+	grpcProxy.FlushInterval = -1
+	grpcProxy.ErrorHandler = grpcErrorHandler
+
+	return &strictProxy{proxy: proxy, grpcProxy: &grpcProxy}
+}
+
+func (p *strictProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if isGRPCRequest(req) {
+		p.grpcProxy.ServeHTTP(rw, req)
+		return
+	}
+
+	p.proxy.ServeHTTP(rw, req)
+}
+
+// grpcRoundTripper makes sure that the trailers of the gRPC streams are requested from the servers,
+// and that an interrupted stream still ends with a gRPC status.
+type grpcRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (t *grpcRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The gRPC servers may reject the requests that do not announce the support of the trailers,
+	// and the TE header of the client is a hop-by-hop header, which is not forwarded.
+	outReq := req.Clone(req.Context())
+	outReq.Header.Set("Te", "trailers")
+
+	res, err := t.next.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if isGRPCContentType(res.Header.Get("Content-Type")) {
+		res.Body = &grpcResponseBody{ReadCloser: res.Body, res: res}
+	}
+
+	return res, nil
+}
+
+// grpcResponseBody ends the body of a gRPC response, instead of failing,
+// when the stream is interrupted on the server side,
+// and adds a gRPC status to the trailers of the response, if the server did not send one.
+// Otherwise, the stream with the client would be reset without a status.
+type grpcResponseBody struct {
+	io.ReadCloser
+	res *http.Response
+}
+
+func (b *grpcResponseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == nil || err == io.EOF {
+		return n, err
+	}
+
+	log.Debugf("gRPC stream interrupted: %v", err)
+
+	if b.res.Trailer.Get(grpcStatusHeader) == "" {
+		if b.res.Trailer == nil {
+			b.res.Trailer = make(http.Header)
+		}
+
+		code := codes.Unavailable
+		switch {
+		case errors.Is(err, context.Canceled):
+			code = codes.Canceled
+		case errors.Is(err, context.DeadlineExceeded):
+			code = codes.DeadlineExceeded
+		}
+
+		b.res.Trailer.Set(grpcStatusHeader, strconv.Itoa(int(code)))
+		b.res.Trailer.Set(grpcMessageHeader, encodeGRPCMessage(fmt.Sprintf("stream interrupted: %v", err)))
+	}
+
+	return n, io.EOF
+}
+
+// grpcErrorHandler reports the errors of the gRPC requests with a Trailers-Only response,
+// which gives the clients the gRPC status and message of the error,
+// instead of leaving them to derive one from the HTTP status.
+func grpcErrorHandler(rw http.ResponseWriter, req *http.Request, err error) {
+	statusCode := errorStatusCode(err)
+
+	log.Debugf("'%d %s' caused by: %v", statusCode, statusText(statusCode), err)
+
+	rw.Header().Set("Content-Type", "application/grpc")
+	rw.Header().Set(grpcStatusHeader, strconv.Itoa(int(grpcCode(statusCode))))
+	rw.Header().Set(grpcMessageHeader, encodeGRPCMessage(statusText(statusCode)))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// grpcCode returns the gRPC status code matching the HTTP status of a forward error.
+func grpcCode(statusCode int) codes.Code {
+	switch statusCode {
+	case StatusClientClosedRequest:
+		return codes.Canceled
+	case http.StatusBadGateway:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// isGRPCRequest determines if the specified HTTP request is a gRPC request.
+// The gRPC-Web requests are excluded, as their trailers are sent in the body of the response.
+func isGRPCRequest(req *http.Request) bool {
+	return req.ProtoMajor == 2 && isGRPCContentType(req.Header.Get("Content-Type"))
+}
+
+func isGRPCContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.HasPrefix(contentType, "application/grpc") && !strings.HasPrefix(contentType, "application/grpc-web")
+}
+
+// encodeGRPCMessage percent-encodes a gRPC status message,
+// as specified for the grpc-message header.
+func encodeGRPCMessage(msg string) string {
+	var sb strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			sb.WriteByte(c)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+
+	return sb.String()
+}
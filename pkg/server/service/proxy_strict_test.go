@@ -0,0 +1,197 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/interop"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+)
+
+// TestStrictProxy_interop runs the test cases of the gRPC interoperability suite
+// not requiring credentials through the strict proxy.
+func TestStrictProxy_interop(t *testing.T) {
+	logger := &interopLogger{LoggerV2: grpclog.NewLoggerV2(ioutil.Discard, ioutil.Discard, ioutil.Discard)}
+	grpclog.SetLoggerV2(logger)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	backend := grpc.NewServer()
+	testpb.RegisterTestServiceServer(backend, interop.NewTestServer())
+	go func() { _ = backend.Serve(listener) }()
+	defer backend.Stop()
+
+	roundTripper, err := createRoundtripper(&static.ServersTransport{MaxIdleConnsPerHost: 200}, nil)
+	require.NoError(t, err)
+
+	handler, err := buildProxy(Bool(true), &dynamic.ResponseForwarding{Strict: true}, roundTripper, newBufferPool(), nil)
+	require.NoError(t, err)
+
+	frontend := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		req.URL.Scheme = "h2c"
+		req.URL.Host = listener.Addr().String()
+		handler.ServeHTTP(rw, req)
+	}), &http2.Server{}))
+	defer frontend.Close()
+
+	conn, err := grpc.Dial(frontend.Listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	client := testpb.NewTestServiceClient(conn)
+
+	testCases := []struct {
+		desc string
+		run  func()
+	}{
+		{desc: "empty_unary", run: func() { interop.DoEmptyUnaryCall(client) }},
+		{desc: "large_unary", run: func() { interop.DoLargeUnaryCall(client) }},
+		{desc: "client_streaming", run: func() { interop.DoClientStreaming(client) }},
+		{desc: "server_streaming", run: func() { interop.DoServerStreaming(client) }},
+		{desc: "ping_pong", run: func() { interop.DoPingPong(client) }},
+		{desc: "empty_stream", run: func() { interop.DoEmptyStream(client) }},
+		{desc: "timeout_on_sleeping_server", run: func() { interop.DoTimeoutOnSleepingServer(client) }},
+		{desc: "cancel_after_begin", run: func() { interop.DoCancelAfterBegin(client) }},
+		{desc: "cancel_after_first_response", run: func() { interop.DoCancelAfterFirstResponse(client) }},
+		{desc: "custom_metadata", run: func() { interop.DoCustomMetadata(client) }},
+		{desc: "status_code_and_message", run: func() { interop.DoStatusCodeAndMessage(client) }},
+		{desc: "special_status_message", run: func() { interop.DoSpecialStatusMessage(client) }},
+		{desc: "unimplemented_method", run: func() { interop.DoUnimplementedMethod(conn) }},
+		{desc: "unimplemented_service", run: func() { interop.DoUnimplementedService(testpb.NewUnimplementedServiceClient(conn)) }},
+	}
+
+	// The test cases report their failures through the fatal logs of gRPC, so they cannot run in parallel.
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			logger.t = t
+			test.run()
+		})
+	}
+}
+
+func TestStrictProxy_unreachableServer(t *testing.T) {
+	handler, err := buildProxy(Bool(true), &dynamic.ResponseForwarding{Strict: true}, http.DefaultTransport, newBufferPool(), nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc               string
+		contentType        string
+		expectedStatus     int
+		expectedGRPCStatus string
+	}{
+		{
+			desc:               "gRPC request",
+			contentType:        "application/grpc",
+			expectedStatus:     http.StatusOK,
+			expectedGRPCStatus: "14",
+		},
+		{
+			desc:           "gRPC-Web request",
+			contentType:    "application/grpc-web",
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			desc:           "HTTP request",
+			contentType:    "text/plain",
+			expectedStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := newHTTP2Request(t, "http://127.0.0.1:1/helloworld.Greeter/SayHello")
+			req.Header.Set("Content-Type", test.contentType)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedGRPCStatus, recorder.Header().Get("Grpc-Status"))
+			if test.expectedGRPCStatus != "" {
+				assert.Equal(t, "Bad Gateway", recorder.Header().Get("Grpc-Message"))
+			}
+		})
+	}
+}
+
+func TestStrictProxy_interruptedStream(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "trailers", req.Header.Get("Te"))
+
+		rw.Header().Set("Content-Type", "application/grpc")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte{0, 0, 0, 0, 3, 'f', 'o', 'o'})
+		rw.(http.Flusher).Flush()
+
+		panic(http.ErrAbortHandler)
+	}))
+	defer backend.Close()
+
+	handler, err := buildProxy(Bool(true), &dynamic.ResponseForwarding{Strict: true}, http.DefaultTransport, newBufferPool(), nil)
+	require.NoError(t, err)
+
+	req := newHTTP2Request(t, backend.URL+"/helloworld.Greeter/SayHello")
+	req.Header.Set("Content-Type", "application/grpc")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "\x00\x00\x00\x00\x03foo", recorder.Body.String())
+	assert.Equal(t, "14", res.Trailer.Get("Grpc-Status"))
+	assert.True(t, strings.HasPrefix(res.Trailer.Get("Grpc-Message"), "stream interrupted: "))
+}
+
+func TestEncodeGRPCMessage(t *testing.T) {
+	assert.Equal(t, "Bad Gateway", encodeGRPCMessage("Bad Gateway"))
+	assert.Equal(t, "100%25 \\t%0A%E2%82%AC", encodeGRPCMessage("100% \\t\n€"))
+}
+
+func newHTTP2Request(t *testing.T, url string) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, url, nil)
+	req.RequestURI = ""
+	req.Proto = "HTTP/2.0"
+	req.ProtoMajor = 2
+	req.ProtoMinor = 0
+
+	return req
+}
+
+// interopLogger turns the fatal logs of the gRPC interoperability test cases into test failures.
+type interopLogger struct {
+	grpclog.LoggerV2
+	t *testing.T
+}
+
+func (l *interopLogger) Fatal(args ...interface{}) {
+	l.t.Fatal(args...)
+}
+
+func (l *interopLogger) Fatalf(format string, args ...interface{}) {
+	l.t.Fatalf(format, args...)
+}
+
+func (l *interopLogger) Fatalln(args ...interface{}) {
+	l.t.Fatal(fmt.Sprintln(args...))
+}
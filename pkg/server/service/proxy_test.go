@@ -4,10 +4,18 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/http/httputil"
+	"net/textproto"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type staticTransport struct {
@@ -35,3 +43,168 @@ func BenchmarkProxy(b *testing.B) {
 		handler.ServeHTTP(w, req)
 	}
 }
+
+func TestProxyInformationalResponses(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		middlewares.SendInformational(rw, req, http.StatusEarlyHints, http.Header{"Link": {"</style.css>; rel=preload; as=style"}})
+
+		rw.WriteHeader(http.StatusOK)
+	}))
+	backend.Config.ConnContext = middlewares.WithConn
+	backend.Start()
+	defer backend.Close()
+
+	handler, err := buildProxy(Bool(true), nil, http.DefaultTransport, newBufferPool(), nil)
+	require.NoError(t, err)
+
+	frontend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		req.URL.Scheme = "http"
+		req.URL.Host = strings.TrimPrefix(backend.URL, "http://")
+		handler.ServeHTTP(rw, req)
+	}))
+	frontend.Config.ConnContext = middlewares.WithConn
+	frontend.Start()
+	defer frontend.Close()
+
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = append(hints, header["Link"]...)
+			}
+			return nil
+		},
+	}
+
+	req := testhelpers.MustNewRequest(http.MethodGet, frontend.URL, nil)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, []string{"</style.css>; rel=preload; as=style"}, hints)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestInformationalRelay(t *testing.T) {
+	// The service is simulated by calling the client trace, as httputil.ReverseProxy relays the informational responses itself from Go 1.20.
+	handler := &informationalRelay{next: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		trace := httptrace.ContextClientTrace(req.Context())
+		require.NotNil(t, trace)
+
+		require.NoError(t, trace.Got1xxResponse(http.StatusContinue, nil))
+		require.NoError(t, trace.Got1xxResponse(http.StatusEarlyHints, textproto.MIMEHeader{"Link": {"</style.css>; rel=preload; as=style"}}))
+
+		rw.WriteHeader(http.StatusOK)
+	})}
+
+	frontend := httptest.NewUnstartedServer(handler)
+	frontend.Config.ConnContext = middlewares.WithConn
+	frontend.Start()
+	defer frontend.Close()
+
+	var codes []int
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			codes = append(codes, code)
+			hints = append(hints, header["Link"]...)
+			return nil
+		},
+	}
+
+	req := testhelpers.MustNewRequest(http.MethodGet, frontend.URL, nil)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, []int{http.StatusEarlyHints}, codes)
+	assert.Equal(t, []string{"</style.css>; rel=preload; as=style"}, hints)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestProxyBufferSize(t *testing.T) {
+	body := strings.Repeat("a", 100*1024)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(body))
+	}))
+	defer backend.Close()
+
+	pool := &recordingBufferPool{bufferPool: newSizedBufferPool(1024)}
+
+	handler, err := buildProxy(Bool(true), &dynamic.ResponseForwarding{BufferSize: 1024}, http.DefaultTransport, pool, nil)
+	require.NoError(t, err)
+
+	req := testhelpers.MustNewRequest(http.MethodGet, backend.URL, nil)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, body, recorder.Body.String())
+	assert.Equal(t, []int{1024}, pool.sizes)
+}
+
+func TestProxyDisableBuffering(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		responseForwarding *dynamic.ResponseForwarding
+		expected           time.Duration
+	}{
+		{
+			desc:     "default",
+			expected: 100 * time.Millisecond,
+		},
+		{
+			desc:               "flush interval",
+			responseForwarding: &dynamic.ResponseForwarding{FlushInterval: "1s"},
+			expected:           time.Second,
+		},
+		{
+			desc:               "buffering disabled",
+			responseForwarding: &dynamic.ResponseForwarding{FlushInterval: "1s", DisableBuffering: true},
+			expected:           -1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := buildProxy(Bool(true), test.responseForwarding, http.DefaultTransport, newBufferPool(), nil)
+			require.NoError(t, err)
+
+			if relay, ok := handler.(*informationalRelay); ok {
+				handler = relay.next
+			}
+
+			assert.Equal(t, test.expected, handler.(*httputil.ReverseProxy).FlushInterval)
+		})
+	}
+}
+
+func TestBufferPools(t *testing.T) {
+	var pools bufferPools
+
+	assert.Same(t, pools.get(1024), pools.get(1024))
+	assert.NotSame(t, pools.get(1024), pools.get(2048))
+	assert.Len(t, pools.get(2048).Get(), 2048)
+}
+
+// recordingBufferPool records the size of the buffers taken from the pool.
+type recordingBufferPool struct {
+	*bufferPool
+	sizes []int
+}
+
+func (b *recordingBufferPool) Get() []byte {
+	buf := b.bufferPool.Get()
+	b.sizes = append(b.sizes, len(buf))
+	return buf
+}
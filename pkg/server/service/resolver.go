@@ -0,0 +1,272 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/miekg/dns"
+)
+
+const (
+	defaultDNSTimeout = 5 * time.Second
+	dnsMessageType    = "application/dns-message"
+)
+
+type lookupFunc func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+// dnsServer is a DNS server, queried over UDP, TCP, TLS (tcp-tls), or HTTPS.
+type dnsServer struct {
+	network string
+	address string
+}
+
+// dnsResolver resolves the host names with its DNS servers, tried in order,
+// and caches the answers for the TTL of their records,
+// and the host names without address for the negative TTL.
+type dnsResolver struct {
+	servers     []dnsServer
+	timeout     time.Duration
+	negativeTTL time.Duration
+	httpClient  *http.Client
+
+	mu    sync.Mutex
+	cache map[string]dnsCacheEntry
+}
+
+type dnsCacheEntry struct {
+	addrs   []net.IPAddr
+	expires time.Time
+}
+
+func newDNSResolver(conf *static.ServersResolver) (*dnsResolver, error) {
+	if len(conf.Servers) == 0 {
+		return nil, errors.New("no DNS server defined")
+	}
+
+	r := &dnsResolver{
+		timeout:     defaultDNSTimeout,
+		negativeTTL: time.Duration(conf.NegativeTTL),
+		cache:       make(map[string]dnsCacheEntry),
+	}
+
+	if conf.Timeout > 0 {
+		r.timeout = time.Duration(conf.Timeout)
+	}
+
+	r.httpClient = &http.Client{Timeout: r.timeout}
+
+	for _, server := range conf.Servers {
+		s, err := parseDNSServer(server)
+		if err != nil {
+			return nil, err
+		}
+		r.servers = append(r.servers, s)
+	}
+
+	return r, nil
+}
+
+func parseDNSServer(server string) (dnsServer, error) {
+	u, err := url.Parse(server)
+	if err != nil || u.Host == "" {
+		// host or host:port, without scheme.
+		return dnsServer{network: "udp", address: withDefaultPort(server, "53")}, nil
+	}
+
+	switch u.Scheme {
+	case "udp", "tcp":
+		return dnsServer{network: u.Scheme, address: withDefaultPort(u.Host, "53")}, nil
+	case "tls":
+		return dnsServer{network: "tcp-tls", address: withDefaultPort(u.Host, "853")}, nil
+	case "https":
+		return dnsServer{network: "https", address: u.String()}, nil
+	default:
+		return dnsServer{}, fmt.Errorf("unsupported DNS server scheme %q", u.Scheme)
+	}
+}
+
+func withDefaultPort(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(address, port)
+}
+
+// LookupIPAddr returns the IPv4 and IPv6 addresses of the host, the IPv4 ones first.
+func (r *dnsResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IPAddr{{IP: ip}}, nil
+	}
+
+	name := dns.Fqdn(host)
+
+	r.mu.Lock()
+	entry, ok := r.cache[name]
+	r.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		if len(entry.addrs) == 0 {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return entry.addrs, nil
+	}
+
+	var addrs []net.IPAddr
+	var ttl uint32
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		answer, err := r.query(ctx, name, qtype)
+		if err != nil {
+			return nil, &net.DNSError{Err: err.Error(), Name: host}
+		}
+
+		for _, rr := range answer {
+			var ip net.IP
+			switch record := rr.(type) {
+			case *dns.A:
+				ip = record.A
+			case *dns.AAAA:
+				ip = record.AAAA
+			default:
+				continue
+			}
+
+			addrs = append(addrs, net.IPAddr{IP: ip})
+			if ttl == 0 || rr.Header().Ttl < ttl {
+				ttl = rr.Header().Ttl
+			}
+		}
+	}
+
+	if len(addrs) == 0 {
+		if r.negativeTTL > 0 {
+			r.store(name, nil, r.negativeTTL)
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	r.store(name, addrs, time.Duration(ttl)*time.Second)
+
+	return addrs, nil
+}
+
+func (r *dnsResolver) store(name string, addrs []net.IPAddr, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range r.cache {
+		if now.After(entry.expires) {
+			delete(r.cache, key)
+		}
+	}
+
+	r.cache[name] = dnsCacheEntry{addrs: addrs, expires: now.Add(ttl)}
+}
+
+// query returns the answer of the first DNS server answering the query.
+// A name which does not exist is an empty answer.
+func (r *dnsResolver) query(ctx context.Context, name string, qtype uint16) ([]dns.RR, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(name, qtype)
+
+	var lastErr error
+	for _, server := range r.servers {
+		resp, err := r.exchange(ctx, server, msg)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+			return resp.Answer, nil
+		case dns.RcodeNameError:
+			return nil, nil
+		default:
+			lastErr = fmt.Errorf("%s answered %s", server.address, dns.RcodeToString[resp.Rcode])
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (r *dnsResolver) exchange(ctx context.Context, server dnsServer, msg *dns.Msg) (*dns.Msg, error) {
+	if server.network == "https" {
+		return r.exchangeHTTPS(ctx, server.address, msg)
+	}
+
+	client := &dns.Client{Net: server.network, Timeout: r.timeout}
+	if server.network == "tcp-tls" {
+		host, _, _ := net.SplitHostPort(server.address)
+		client.TLSConfig = &tls.Config{ServerName: host}
+	}
+
+	resp, _, err := client.ExchangeContext(ctx, msg, server.address)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Truncated && server.network == "udp" {
+		client.Net = "tcp"
+		resp, _, err = client.ExchangeContext(ctx, msg, server.address)
+	}
+
+	return resp, err
+}
+
+// exchangeHTTPS sends the query to a DNS over HTTPS server (RFC 8484).
+func (r *dnsResolver) exchangeHTTPS(ctx context.Context, serverURL string, msg *dns.Msg) (*dns.Msg, error) {
+	query := msg.Copy()
+	// The ID is zero to make the HTTP responses cache friendly.
+	query.Id = 0
+
+	data, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, serverURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", dnsMessageType)
+	req.Header.Set("Accept", dnsMessageType)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", serverURL, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := new(dns.Msg)
+	if err := answer.Unpack(body); err != nil {
+		return nil, err
+	}
+
+	return answer, nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/static"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dnsRecords answers the queries for backend.local., and counts them.
+type dnsRecords struct {
+	queries int32
+}
+
+func (d *dnsRecords) answer(req *dns.Msg) *dns.Msg {
+	atomic.AddInt32(&d.queries, 1)
+
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+
+	question := req.Question[0]
+	if question.Name != "backend.local." {
+		resp.Rcode = dns.RcodeNameError
+		return resp
+	}
+
+	header := dns.RR_Header{Name: question.Name, Rrtype: question.Qtype, Class: dns.ClassINET, Ttl: 60}
+	switch question.Qtype {
+	case dns.TypeA:
+		resp.Answer = append(resp.Answer, &dns.A{Hdr: header, A: net.ParseIP("10.0.0.1")})
+	case dns.TypeAAAA:
+		resp.Answer = append(resp.Answer, &dns.AAAA{Hdr: header, AAAA: net.ParseIP("fd00::1")})
+	}
+
+	return resp
+}
+
+func (d *dnsRecords) ServeDNS(rw dns.ResponseWriter, req *dns.Msg) {
+	_ = rw.WriteMsg(d.answer(req))
+}
+
+func (d *dnsRecords) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil || req.Header.Get("Content-Type") != dnsMessageType {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	query := new(dns.Msg)
+	if err = query.Unpack(body); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	data, err := d.answer(query).Pack()
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", dnsMessageType)
+	_, _ = rw.Write(data)
+}
+
+func startDNSServer(t *testing.T, handler dns.Handler) (string, func()) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: conn, Handler: handler, NotifyStartedFunc: func() { close(started) }}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+
+	return conn.LocalAddr().String(), func() { _ = server.Shutdown() }
+}
+
+func TestDNSResolver(t *testing.T) {
+	records := &dnsRecords{}
+
+	address, shutdown := startDNSServer(t, records)
+	defer shutdown()
+
+	dohServer := httptest.NewTLSServer(records)
+	defer dohServer.Close()
+
+	testCases := []struct {
+		desc   string
+		server string
+		client *http.Client
+	}{
+		{desc: "DNS over UDP, without scheme", server: address},
+		{desc: "DNS over UDP", server: "udp://" + address},
+		{desc: "DNS over HTTPS", server: dohServer.URL, client: dohServer.Client()},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			resolver, err := newDNSResolver(&static.ServersResolver{Servers: []string{test.server}})
+			require.NoError(t, err)
+
+			if test.client != nil {
+				resolver.httpClient = test.client
+			}
+
+			addrs, err := resolver.LookupIPAddr(context.Background(), "backend.local")
+			require.NoError(t, err)
+
+			assert.Equal(t, []net.IPAddr{{IP: net.ParseIP("10.0.0.1").To4()}, {IP: net.ParseIP("fd00::1")}}, addrs)
+		})
+	}
+}
+
+func TestDNSResolver_cache(t *testing.T) {
+	records := &dnsRecords{}
+
+	address, shutdown := startDNSServer(t, records)
+	defer shutdown()
+
+	resolver, err := newDNSResolver(&static.ServersResolver{
+		Servers:     []string{"udp://127.0.0.1:1", address},
+		Timeout:     types.Duration(time.Second),
+		NegativeTTL: types.Duration(time.Minute),
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = resolver.LookupIPAddr(context.Background(), "backend.local")
+		require.NoError(t, err)
+	}
+
+	// The answers are cached for the TTL of the records.
+	assert.Equal(t, int32(2), atomic.LoadInt32(&records.queries))
+
+	for i := 0; i < 2; i++ {
+		_, err = resolver.LookupIPAddr(context.Background(), "missing.local")
+		require.Error(t, err)
+
+		dnsErr, ok := err.(*net.DNSError)
+		require.True(t, ok)
+		assert.True(t, dnsErr.IsNotFound)
+	}
+
+	// The host names without address are cached for the negative TTL.
+	assert.Equal(t, int32(4), atomic.LoadInt32(&records.queries))
+}
+
+func TestParseDNSServer(t *testing.T) {
+	testCases := []struct {
+		server      string
+		expected    dnsServer
+		expectedErr bool
+	}{
+		{server: "1.1.1.1", expected: dnsServer{network: "udp", address: "1.1.1.1:53"}},
+		{server: "1.1.1.1:5353", expected: dnsServer{network: "udp", address: "1.1.1.1:5353"}},
+		{server: "udp://1.1.1.1", expected: dnsServer{network: "udp", address: "1.1.1.1:53"}},
+		{server: "tcp://1.1.1.1", expected: dnsServer{network: "tcp", address: "1.1.1.1:53"}},
+		{server: "tls://one.one.one.one", expected: dnsServer{network: "tcp-tls", address: "one.one.one.one:853"}},
+		{server: "tls://[2606:4700:4700::1111]:8853", expected: dnsServer{network: "tcp-tls", address: "[2606:4700:4700::1111]:8853"}},
+		{server: "https://cloudflare-dns.com/dns-query", expected: dnsServer{network: "https", address: "https://cloudflare-dns.com/dns-query"}},
+		{server: "quic://1.1.1.1", expectedErr: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.server, func(t *testing.T) {
+			t.Parallel()
+
+			server, err := parseDNSServer(test.server)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, server)
+		})
+	}
+}
+
+func TestManager_getRoundTripper(t *testing.T) {
+	resolverRoundTripper := &http.Transport{}
+	manager := NewManager(nil, http.DefaultTransport, map[string]http.RoundTripper{"doh": resolverRoundTripper}, nil, nil)
+
+	roundTripper, err := manager.getRoundTripper("")
+	require.NoError(t, err)
+	assert.Equal(t, http.DefaultTransport, roundTripper)
+
+	roundTripper, err = manager.getRoundTripper("doh")
+	require.NoError(t, err)
+	assert.Equal(t, resolverRoundTripper, roundTripper)
+
+	_, err = manager.getRoundTripper("missing")
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -28,7 +29,8 @@ func (t *h2cTransportWrapper) RoundTrip(req *http.Request) (*http.Response, erro
 // An exception to this is the MaxIdleConns setting as we only provide the option MaxIdleConnsPerHost
 // in Traefik at this point in time. Setting this value to the default of 100 could lead to confusing
 // behavior and backwards compatibility issues.
-func createRoundtripper(transportConfiguration *static.ServersTransport) (http.RoundTripper, error) {
+// The host names of the servers are resolved with lookupIPAddr, or with the system resolver if nil.
+func createRoundtripper(transportConfiguration *static.ServersTransport, lookupIPAddr lookupFunc) (http.RoundTripper, error) {
 	if transportConfiguration == nil {
 		return nil, errors.New("no transport configuration given")
 	}
@@ -43,9 +45,24 @@ func createRoundtripper(transportConfiguration *static.ServersTransport) (http.R
 		dialer.Timeout = time.Duration(transportConfiguration.ForwardingTimeouts.DialTimeout)
 	}
 
+	dialContext, err := newDialContext(dialer, transportConfiguration, lookupIPAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyFunc := http.ProxyFromEnvironment
+	if transportConfiguration.Proxy != nil {
+		// The connections to the servers are tunneled through the proxy by the dialer.
+		proxyFunc = nil
+		dialContext, err = newProxyDialContext(transportConfiguration.Proxy, dialContext)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	transport := &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           dialer.DialContext,
+		Proxy:                 proxyFunc,
+		DialContext:           dialContext,
 		MaxIdleConnsPerHost:   transportConfiguration.MaxIdleConnsPerHost,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
@@ -55,7 +72,7 @@ func createRoundtripper(transportConfiguration *static.ServersTransport) (http.R
 	transport.RegisterProtocol("h2c", &h2cTransportWrapper{
 		Transport: &http2.Transport{
 			DialTLS: func(netw, addr string, cfg *tls.Config) (net.Conn, error) {
-				return net.Dial(netw, addr)
+				return dialContext(context.Background(), netw, addr)
 			},
 			AllowHTTP: true,
 		},
@@ -100,12 +117,45 @@ func createRootCACertPool(rootCAs []traefiktls.FileOrContent) *x509.CertPool {
 	return roots
 }
 
-func setupDefaultRoundTripper(conf *static.ServersTransport) http.RoundTripper {
-	transport, err := createRoundtripper(conf)
+// setupRoundTrippers creates the default round tripper,
+// and the round trippers resolving the host names of the servers with each of the resolvers, by resolver name.
+func setupRoundTrippers(conf *static.ServersTransport) (http.RoundTripper, map[string]http.RoundTripper) {
+	roundTrippers := make(map[string]http.RoundTripper)
+
+	if conf != nil {
+		for name, resolverConf := range conf.Resolvers {
+			if resolverConf == nil {
+				continue
+			}
+
+			resolver, err := newDNSResolver(resolverConf)
+			if err != nil {
+				log.WithoutContext().Errorf("Could not configure the resolver %s: %v", name, err)
+				continue
+			}
+
+			transport, err := createRoundtripper(conf, resolver.LookupIPAddr)
+			if err != nil {
+				log.WithoutContext().Errorf("Could not configure HTTP Transport for the resolver %s: %v", name, err)
+				continue
+			}
+
+			roundTrippers[name] = transport
+		}
+
+		if conf.Resolver != "" {
+			if transport, ok := roundTrippers[conf.Resolver]; ok {
+				return transport, roundTrippers
+			}
+			log.WithoutContext().Errorf("Unknown resolver %s, fallbacking on the system resolver", conf.Resolver)
+		}
+	}
+
+	transport, err := createRoundtripper(conf, nil)
 	if err != nil {
 		log.WithoutContext().Errorf("Could not configure HTTP Transport, fallbacking on default transport: %v", err)
-		return http.DefaultTransport
+		return http.DefaultTransport, roundTrippers
 	}
 
-	return transport
+	return transport, roundTrippers
 }
@@ -23,7 +23,9 @@ import (
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/server/cookie"
 	"github.com/containous/traefik/v2/pkg/server/provider"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/mapped"
 	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/mirror"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/pinning"
 	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/wrr"
 	"github.com/vulcand/oxy/roundrobin"
 )
@@ -36,14 +38,15 @@ const (
 const defaultMaxBodySize int64 = -1
 
 // NewManager creates a new Manager
-func NewManager(configs map[string]*runtime.ServiceInfo, defaultRoundTripper http.RoundTripper, metricsRegistry metrics.Registry, routinePool *safe.Pool) *Manager {
+func NewManager(configs map[string]*runtime.ServiceInfo, defaultRoundTripper http.RoundTripper, resolverRoundTrippers map[string]http.RoundTripper, metricsRegistry metrics.Registry, routinePool *safe.Pool) *Manager {
 	return &Manager{
-		routinePool:         routinePool,
-		metricsRegistry:     metricsRegistry,
-		bufferPool:          newBufferPool(),
-		defaultRoundTripper: defaultRoundTripper,
-		balancers:           make(map[string]healthcheck.Balancers),
-		configs:             configs,
+		routinePool:           routinePool,
+		metricsRegistry:       metricsRegistry,
+		bufferPool:            newBufferPool(),
+		defaultRoundTripper:   defaultRoundTripper,
+		resolverRoundTrippers: resolverRoundTrippers,
+		balancers:             make(map[string]healthcheck.Balancers),
+		configs:               configs,
 	}
 }
 
@@ -52,7 +55,10 @@ type Manager struct {
 	routinePool         *safe.Pool
 	metricsRegistry     metrics.Registry
 	bufferPool          httputil.BufferPool
+	bufferPools         bufferPools
 	defaultRoundTripper http.RoundTripper
+	// resolverRoundTrippers are the round trippers resolving the host names of the servers with a resolver, by resolver name.
+	resolverRoundTrippers map[string]http.RoundTripper
 	// balancers is the map of all Balancers, keyed by service name.
 	// There is one Balancer per service handler, and there is one service handler per reference to a service
 	// (e.g. if 2 routers refer to the same service name, 2 service handlers are created),
@@ -76,7 +82,7 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string, respons
 	value := reflect.ValueOf(*conf.Service)
 	var count int
 	for i := 0; i < value.NumField(); i++ {
-		if !value.Field(i).IsNil() {
+		if value.Field(i).Kind() == reflect.Ptr && !value.Field(i).IsNil() {
 			count++
 		}
 	}
@@ -110,6 +116,13 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string, respons
 			conf.AddError(err, true)
 			return nil, err
 		}
+	case conf.Mapped != nil:
+		var err error
+		lb, err = m.getMappedServiceHandler(ctx, serviceName, conf.Mapped, responseModifier)
+		if err != nil {
+			conf.AddError(err, true)
+			return nil, err
+		}
 	default:
 		sErr := fmt.Errorf("the service %q does not have any type defined", serviceName)
 		conf.AddError(sErr, true)
@@ -162,6 +175,41 @@ func (m *Manager) getWRRServiceHandler(ctx context.Context, serviceName string,
 	return balancer, nil
 }
 
+func (m *Manager) getMappedServiceHandler(ctx context.Context, serviceName string, config *dynamic.Mapped, responseModifier func(*http.Response) error) (http.Handler, error) {
+	if config.PassHostHeader == nil {
+		defaultPassHostHeader := true
+		config.PassHostHeader = &defaultPassHostHeader
+	}
+
+	fwd, err := buildProxy(config.PassHostHeader, nil, m.defaultRoundTripper, m.bufferPool, responseModifier)
+	if err != nil {
+		return nil, err
+	}
+
+	alHandler := func(next http.Handler) (http.Handler, error) {
+		return accesslog.NewFieldHandler(next, accesslog.ServiceName, serviceName, accesslog.AddServiceFields), nil
+	}
+	chain := alice.New()
+	if m.metricsRegistry != nil && m.metricsRegistry.IsSvcEnabled() {
+		chain = chain.Append(metricsMiddle.WrapServiceHandler(ctx, m.metricsRegistry, serviceName))
+	}
+
+	handler, err := chain.Append(alHandler).Then(pipelining.New(ctx, fwd, "pipelining"))
+	if err != nil {
+		return nil, err
+	}
+
+	var fallback http.Handler
+	if config.Fallback != "" {
+		fallback, err = m.BuildHTTP(ctx, config.Fallback, responseModifier)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mapped.New(ctx, serviceName, *config, handler, fallback)
+}
+
 func (m *Manager) getLoadBalancerServiceHandler(
 	ctx context.Context,
 	serviceName string,
@@ -173,7 +221,20 @@ func (m *Manager) getLoadBalancerServiceHandler(
 		service.PassHostHeader = &defaultPassHostHeader
 	}
 
-	fwd, err := buildProxy(service.PassHostHeader, service.ResponseForwarding, m.defaultRoundTripper, m.bufferPool, responseModifier)
+	bufferPool := m.bufferPool
+	if service.ResponseForwarding != nil && service.ResponseForwarding.BufferSize != 0 {
+		if service.ResponseForwarding.BufferSize < 0 || service.ResponseForwarding.BufferSize > maxBufferSize {
+			return nil, fmt.Errorf("invalid buffer size: %d", service.ResponseForwarding.BufferSize)
+		}
+		bufferPool = m.bufferPools.get(service.ResponseForwarding.BufferSize)
+	}
+
+	roundTripper, err := m.getRoundTripper(service.Resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	fwd, err := buildProxy(service.PassHostHeader, service.ResponseForwarding, roundTripper, bufferPool, responseModifier)
 	if err != nil {
 		return nil, err
 	}
@@ -199,10 +260,45 @@ func (m *Manager) getLoadBalancerServiceHandler(
 	// TODO rename and checks
 	m.balancers[serviceName] = append(m.balancers[serviceName], balancer)
 
+	if service.Pinning != nil {
+		pinned, err := pinning.New(ctx, balancer, balancer, handler, *service.Pinning)
+		if err != nil {
+			return nil, err
+		}
+
+		// Empty (backend with no servers)
+		return emptybackendhandler.New(&pinnedBalancer{BalancerHandler: balancer, handler: pinned}), nil
+	}
+
 	// Empty (backend with no servers)
 	return emptybackendhandler.New(balancer), nil
 }
 
+// pinnedBalancer is a load-balancer whose requests go through the pinning handler.
+type pinnedBalancer struct {
+	healthcheck.BalancerHandler
+	handler http.Handler
+}
+
+func (b *pinnedBalancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	b.handler.ServeHTTP(rw, req)
+}
+
+// getRoundTripper returns the round tripper resolving the host names of the servers with the given resolver,
+// or the default round tripper.
+func (m *Manager) getRoundTripper(resolverName string) (http.RoundTripper, error) {
+	if resolverName == "" {
+		return m.defaultRoundTripper, nil
+	}
+
+	roundTripper, ok := m.resolverRoundTrippers[resolverName]
+	if !ok {
+		return nil, fmt.Errorf("unknown resolver %q", resolverName)
+	}
+
+	return roundTripper, nil
+}
+
 // LaunchHealthCheck Launches the health checks.
 func (m *Manager) LaunchHealthCheck() {
 	backendConfigs := make(map[string]*healthcheck.BackendConfig)
@@ -218,7 +314,7 @@ func (m *Manager) LaunchHealthCheck() {
 		if hcOpts := buildHealthCheckOptions(ctx, balancers, serviceName, service.HealthCheck); hcOpts != nil {
 			log.FromContext(ctx).Debugf("Setting up healthcheck for service %s with %s", serviceName, *hcOpts)
 
-			hcOpts.Transport = m.defaultRoundTripper
+			hcOpts.Transport, _ = m.getRoundTripper(service.Resolver)
 			backendHealthCheck = healthcheck.NewBackendConfig(*hcOpts, serviceName)
 		}
 
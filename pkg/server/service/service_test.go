@@ -80,7 +80,7 @@ func TestGetLoadBalancer(t *testing.T) {
 }
 
 func TestGetLoadBalancerServiceHandler(t *testing.T) {
-	sm := NewManager(nil, http.DefaultTransport, nil, nil)
+	sm := NewManager(nil, http.DefaultTransport, nil, nil, nil)
 
 	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-From", "first")
@@ -254,6 +254,27 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:        "Custom buffer size and disabled buffering",
+			serviceName: "foobar",
+			service: &dynamic.ServersLoadBalancer{
+				Servers: []dynamic.Server{
+					{
+						URL: server1.URL,
+					},
+				},
+				ResponseForwarding: &dynamic.ResponseForwarding{
+					BufferSize:       1024,
+					DisableBuffering: true,
+				},
+			},
+			expected: []ExpectedResult{
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "first",
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -284,6 +305,19 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 	}
 }
 
+func TestGetLoadBalancerServiceHandler_invalidBufferSize(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil, nil, nil)
+
+	for _, size := range []int{-1, maxBufferSize + 1} {
+		service := &dynamic.ServersLoadBalancer{
+			ResponseForwarding: &dynamic.ResponseForwarding{BufferSize: size},
+		}
+
+		_, err := sm.getLoadBalancerServiceHandler(context.Background(), "foobar", service, nil)
+		assert.Error(t, err)
+	}
+}
+
 func TestManager_Build(t *testing.T) {
 	testCases := []struct {
 		desc         string
@@ -325,6 +359,25 @@ func TestManager_Build(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "Mapped service with fallback",
+			serviceName: "serviceName@provider-1",
+			configs: map[string]*runtime.ServiceInfo{
+				"serviceName@provider-1": {
+					Service: &dynamic.Service{
+						Mapped: &dynamic.Mapped{
+							Entries:  []dynamic.MappedEntry{{Host: "example.com", URL: "http://10.0.0.1"}},
+							Fallback: "fallback",
+						},
+					},
+				},
+				"fallback@provider-1": {
+					Service: &dynamic.Service{
+						LoadBalancer: &dynamic.ServersLoadBalancer{},
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -332,7 +385,7 @@ func TestManager_Build(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			manager := NewManager(test.configs, http.DefaultTransport, nil, nil)
+			manager := NewManager(test.configs, http.DefaultTransport, nil, nil, nil)
 
 			ctx := context.Background()
 			if len(test.providerName) > 0 {
@@ -355,7 +408,7 @@ func TestMultipleTypeOnBuildHTTP(t *testing.T) {
 		},
 	}
 
-	manager := NewManager(services, http.DefaultTransport, nil, nil)
+	manager := NewManager(services, http.DefaultTransport, nil, nil, nil)
 
 	_, err := manager.BuildHTTP(context.Background(), "test@file", nil)
 	assert.Error(t, err, "cannot create service: multi-types service not supported, consider declaring two different pieces of service instead")
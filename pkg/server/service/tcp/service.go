@@ -5,10 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"reflect"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/server/provider"
 	"github.com/containous/traefik/v2/pkg/tcp"
 )
@@ -16,13 +19,68 @@ import (
 // Manager is the TCPHandlers factory
 type Manager struct {
 	configs map[string]*runtime.TCPServiceInfo
+
+	// limited are the handlers of the services limiting their connections, by service name,
+	// reused from the previous manager when their configuration is unchanged.
+	limited  map[string]*limitedService
+	previous map[string]*limitedService
+
+	metricsRegistry metrics.Registry
+}
+
+// limitedService is the handler of a service limiting its connections, and the configuration it is built from.
+type limitedService struct {
+	config       *dynamic.TCPService
+	drainTimeout time.Duration
+	handler      *tcp.LimitedHandler
 }
 
 // NewManager creates a new manager
 func NewManager(conf *runtime.Configuration) *Manager {
 	return &Manager{
 		configs: conf.TCPServices,
+		limited: make(map[string]*limitedService),
+	}
+}
+
+// Replace makes the manager replace the previous one, whose handlers limiting their connections are reused
+// when their service configuration is unchanged, and drained otherwise.
+// It must be called before building the handlers.
+func (m *Manager) Replace(previous *Manager) {
+	if previous != nil {
+		m.previous = previous.limited
+	}
+}
+
+// SetMetricsRegistry sets the metrics registry counting the connections closed by the drains.
+func (m *Manager) SetMetricsRegistry(registry metrics.Registry) {
+	m.metricsRegistry = registry
+}
+
+// DrainReplaced drains the connections of the previous handlers which are not reused, once the handlers are built.
+func (m *Manager) DrainReplaced() {
+	for name, previous := range m.previous {
+		if current, ok := m.limited[name]; ok && current.handler == previous.handler {
+			continue
+		}
+
+		if previous.drainTimeout <= 0 {
+			continue
+		}
+
+		var onDrained func(result string)
+		if m.metricsRegistry != nil && m.metricsRegistry.IsSvcEnabled() {
+			counter := m.metricsRegistry.ServiceTCPDrainedConnsCounter()
+			serviceName := name
+			onDrained = func(result string) {
+				counter.With("service", serviceName, "result", result).Add(1)
+			}
+		}
+
+		previous.handler.Drain(previous.drainTimeout, onDrained)
 	}
+
+	m.previous = nil
 }
 
 // BuildTCP Creates a tcp.Handler for a service configuration.
@@ -68,7 +126,13 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 			loadBalancer.AddServer(handler)
 			logger.WithField(log.ServerName, name).Debugf("Creating TCP server %d at %s", name, server.Address)
 		}
-		return loadBalancer, nil
+		limits := tcp.Limits{
+			IdleTimeout:      time.Duration(conf.LoadBalancer.IdleTimeout),
+			MaxLifetime:      time.Duration(conf.LoadBalancer.MaxLifetime),
+			MaxConnections:   conf.LoadBalancer.MaxConnections,
+			DrainIdleTimeout: time.Duration(conf.LoadBalancer.DrainIdleTimeout),
+		}
+		return m.limit(serviceQualifiedName, conf.TCPService, limits, time.Duration(conf.LoadBalancer.DrainTimeout), loadBalancer), nil
 	case conf.Weighted != nil:
 		loadBalancer := tcp.NewWRRLoadBalancer()
 		for _, service := range conf.Weighted.Services {
@@ -79,10 +143,39 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 			}
 			loadBalancer.AddWeightServer(handler, service.Weight)
 		}
-		return loadBalancer, nil
+
+		limits := tcp.Limits{DrainIdleTimeout: time.Duration(conf.Weighted.DrainIdleTimeout)}
+		return m.limit(serviceQualifiedName, conf.TCPService, limits, time.Duration(conf.Weighted.DrainTimeout), loadBalancer), nil
 	default:
 		err := fmt.Errorf("the service %q does not have any type defined", serviceQualifiedName)
 		conf.AddError(err, true)
 		return nil, err
 	}
 }
+
+// limit returns the handler of the service limiting its connections, if its configuration defines limits or a drain,
+// which is shared by the routers of the service, and reused from the previous manager if the configuration is unchanged.
+func (m *Manager) limit(serviceName string, config *dynamic.TCPService, limits tcp.Limits, drainTimeout time.Duration, handler tcp.Handler) tcp.Handler {
+	if limits == (tcp.Limits{}) && drainTimeout <= 0 {
+		return handler
+	}
+
+	if limited, ok := m.limited[serviceName]; ok {
+		return limited.handler
+	}
+
+	if previous, ok := m.previous[serviceName]; ok && reflect.DeepEqual(previous.config, config) {
+		previous.handler.SetNext(handler)
+		m.limited[serviceName] = previous
+		return previous.handler
+	}
+
+	limited := &limitedService{
+		config:       config.DeepCopy(),
+		drainTimeout: drainTimeout,
+		handler:      tcp.NewLimitedHandler(handler, limits),
+	}
+	m.limited[serviceName] = limited
+
+	return limited.handler
+}
@@ -3,10 +3,13 @@ package tcp
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/server/provider"
+	"github.com/containous/traefik/v2/pkg/tcp"
+	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -199,3 +202,66 @@ func TestManager_BuildTCP(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_Replace(t *testing.T) {
+	newManager := func(server string, weight int) *Manager {
+		return NewManager(&runtime.Configuration{
+			TCPServices: map[string]*runtime.TCPServiceInfo{
+				"foo@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers:        []dynamic.TCPServer{{Address: server}},
+							MaxConnections: 10,
+							DrainTimeout:   types.Duration(time.Second),
+						},
+					},
+				},
+				"bar@provider-1": {
+					TCPService: &dynamic.TCPService{
+						Weighted: &dynamic.TCPWeightedRoundRobin{
+							Services:     []dynamic.TCPWRRService{{Name: "foo@provider-1", Weight: &weight}},
+							DrainTimeout: types.Duration(time.Second),
+						},
+					},
+				},
+			},
+		})
+	}
+
+	build := func(manager *Manager, previous *Manager) (tcp.Handler, tcp.Handler) {
+		manager.Replace(previous)
+
+		foo, err := manager.BuildTCP(context.Background(), "foo@provider-1")
+		require.NoError(t, err)
+
+		bar, err := manager.BuildTCP(context.Background(), "bar@provider-1")
+		require.NoError(t, err)
+
+		manager.DrainReplaced()
+
+		return foo, bar
+	}
+
+	previous := newManager("127.0.0.1:80", 1)
+	previousFoo, previousBar := build(previous, nil)
+
+	// The handler of a service is shared by its routers.
+	sharedFoo, err := previous.BuildTCP(context.Background(), "foo@provider-1")
+	require.NoError(t, err)
+	assert.Same(t, previousFoo, sharedFoo)
+
+	unchanged := newManager("127.0.0.1:80", 1)
+	unchangedFoo, unchangedBar := build(unchanged, previous)
+	assert.Same(t, previousFoo, unchangedFoo)
+	assert.Same(t, previousBar, unchangedBar)
+
+	reweighted := newManager("127.0.0.1:80", 2)
+	reweightedFoo, reweightedBar := build(reweighted, unchanged)
+	assert.Same(t, previousFoo, reweightedFoo)
+	assert.NotSame(t, previousBar, reweightedBar)
+
+	changed := newManager("127.0.0.1:81", 2)
+	changedFoo, changedBar := build(changed, reweighted)
+	assert.NotSame(t, previousFoo, changedFoo)
+	assert.Same(t, reweightedBar, changedBar)
+}
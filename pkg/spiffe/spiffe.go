@@ -0,0 +1,255 @@
+// Package spiffe obtains the X.509-SVID of Traefik, and the trust bundles of the SPIFFE trust domains,
+// from the SPIFFE Workload API, such as the one of a SPIRE agent.
+package spiffe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/containous/traefik/v2/pkg/job"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// endpointSocketEnv is the environment variable holding the address of the Workload API, by default.
+const endpointSocketEnv = "SPIFFE_ENDPOINT_SOCKET"
+
+// Configuration holds the configuration of the SPIFFE Workload API client.
+type Configuration struct {
+	WorkloadAPIAddr string `description:"Address of the SPIFFE Workload API (unix:///path/to/socket or tcp://ip:port), defaults to the SPIFFE_ENDPOINT_SOCKET environment variable." json:"workloadAPIAddr,omitempty" toml:"workloadAPIAddr,omitempty" yaml:"workloadAPIAddr,omitempty" export:"true"`
+}
+
+// Source holds the X.509-SVID and the trust bundles obtained from the Workload API, updated as they are rotated.
+type Source struct {
+	network string
+	address string
+
+	mu      sync.RWMutex
+	svid    *tls.Certificate
+	bundles map[string][]*x509.Certificate
+}
+
+// NewSource creates a source obtaining the X.509-SVID and the trust bundles from the Workload API of the configuration.
+func NewSource(config *Configuration) (*Source, error) {
+	addr := config.WorkloadAPIAddr
+	if addr == "" {
+		addr = os.Getenv(endpointSocketEnv)
+	}
+
+	if addr == "" {
+		return nil, fmt.Errorf("the address of the Workload API is required, in the configuration or in the %s environment variable", endpointSocketEnv)
+	}
+
+	network, address, err := parseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{network: network, address: address}, nil
+}
+
+// parseAddr parses the address of the Workload API, as specified by the SPIFFE Workload Endpoint.
+func parseAddr(addr string) (string, string, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid Workload API address %q: %w", addr, err)
+	}
+
+	switch u.Scheme {
+	case "unix":
+		if u.Host != "" || u.Path == "" {
+			return "", "", fmt.Errorf("invalid Workload API address %q: the path of the socket must be absolute (unix:///path/to/socket)", addr)
+		}
+		return "unix", u.Path, nil
+
+	case "tcp":
+		host, _, err := net.SplitHostPort(u.Host)
+		if err != nil || net.ParseIP(host) == nil {
+			return "", "", fmt.Errorf("invalid Workload API address %q: an IP address and a port are required (tcp://ip:port)", addr)
+		}
+		return "tcp", u.Host, nil
+
+	default:
+		return "", "", fmt.Errorf("invalid Workload API address %q: the scheme must be unix or tcp", addr)
+	}
+}
+
+// Run watches the X.509-SVIDs of the Workload API until the context is done, connecting again on failure.
+func (s *Source) Run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	operation := func() error {
+		return s.watch(ctx)
+	}
+
+	notify := func(err error, time time.Duration) {
+		logger.Errorf("Unable to watch the X.509-SVIDs of the SPIFFE Workload API: %v, retrying in %s", err, time)
+	}
+
+	err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctx), notify)
+	if err != nil {
+		logger.Errorf("Cannot watch the X.509-SVIDs of the SPIFFE Workload API: %v", err)
+	}
+}
+
+// watch receives the X.509-SVIDs sent by the Workload API, on each rotation, until the stream or the context ends.
+func (s *Source) watch(ctx context.Context) error {
+	conn, err := grpc.DialContext(ctx, s.address, grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, s.network, address)
+		}))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, workloadAPIHeader, "true")
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, fetchX509SVIDMethod)
+	if err != nil {
+		return err
+	}
+
+	if err = stream.SendMsg(&x509SVIDRequest{}); err != nil {
+		return err
+	}
+
+	if err = stream.CloseSend(); err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+
+	for {
+		resp := &x509SVIDResponse{}
+		if err := stream.RecvMsg(resp); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if err := s.update(resp); err != nil {
+			logger.Errorf("Invalid X.509-SVID received from the SPIFFE Workload API: %v", err)
+			continue
+		}
+
+		logger.Debugf("X.509-SVID %s received from the SPIFFE Workload API", resp.Svids[0].SpiffeID)
+	}
+}
+
+// update replaces the X.509-SVID and the trust bundles with the ones of the response.
+// The first X.509-SVID of the response is the default one, used by Traefik.
+func (s *Source) update(resp *x509SVIDResponse) error {
+	if len(resp.Svids) == 0 || resp.Svids[0] == nil {
+		return errors.New("no X.509-SVID")
+	}
+
+	svid := resp.Svids[0]
+
+	trustDomain, err := TrustDomain(svid.SpiffeID)
+	if err != nil {
+		return err
+	}
+
+	chain, err := x509.ParseCertificates(svid.X509SVID)
+	if err != nil {
+		return fmt.Errorf("unable to parse the certificates of %s: %w", svid.SpiffeID, err)
+	}
+
+	if len(chain) == 0 {
+		return fmt.Errorf("no certificate for %s", svid.SpiffeID)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(svid.X509SVIDKey)
+	if err != nil {
+		return fmt.Errorf("unable to parse the private key of %s: %w", svid.SpiffeID, err)
+	}
+
+	cert := &tls.Certificate{PrivateKey: key, Leaf: chain[0]}
+	for _, c := range chain {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+
+	bundles := map[string][]*x509.Certificate{}
+
+	bundles[trustDomain], err = x509.ParseCertificates(svid.Bundle)
+	if err != nil {
+		return fmt.Errorf("unable to parse the trust bundle of %s: %w", trustDomain, err)
+	}
+
+	for id, bundle := range resp.FederatedBundles {
+		federated, err := TrustDomain(id)
+		if err != nil {
+			// The federated bundles may be keyed by the name of their trust domain.
+			federated, err = TrustDomain("spiffe://" + id)
+			if err != nil {
+				return err
+			}
+		}
+
+		bundles[federated], err = x509.ParseCertificates(bundle)
+		if err != nil {
+			return fmt.Errorf("unable to parse the trust bundle of %s: %w", federated, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.svid = cert
+	s.bundles = bundles
+	s.mu.Unlock()
+
+	return nil
+}
+
+// ResolveCertificate returns the X.509-SVID to the TLS handshakes without server name,
+// and to those whose server name is one of the DNS names of the X.509-SVID.
+func (s *Source) ResolveCertificate(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.svid == nil {
+		return nil, nil
+	}
+
+	if clientHello.ServerName == "" || s.svid.Leaf.VerifyHostname(clientHello.ServerName) == nil {
+		return s.svid, nil
+	}
+
+	return nil, nil
+}
+
+// X509Bundle returns the certificates of the trust bundle of the trust domain, if any.
+func (s *Source) X509Bundle(trustDomain string) []*x509.Certificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.bundles[trustDomain]
+}
+
+// TrustDomain returns the trust domain of the SPIFFE ID.
+func TrustDomain(id string) (string, error) {
+	u, err := url.Parse(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid SPIFFE ID %q: %w", id, err)
+	}
+
+	if u.Scheme != "spiffe" || u.Host == "" || u.Port() != "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid SPIFFE ID %q", id)
+	}
+
+	return strings.ToLower(u.Host), nil
+}
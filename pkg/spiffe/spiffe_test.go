@@ -0,0 +1,280 @@
+package spiffe
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newSVID returns an X.509-SVID, issued by a new CA, for the SPIFFE ID and the DNS names.
+func newSVID(t *testing.T, id string, dnsNames ...string) *x509SVID {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	uri, err := url.Parse(id)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{uri},
+		DNSNames:     dnsNames,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return &x509SVID{
+		SpiffeID:    id,
+		X509SVID:    der,
+		X509SVIDKey: keyDER,
+		Bundle:      caDER,
+	}
+}
+
+// startWorkloadAPI starts a Workload API sending the responses, and returns its address and the function stopping it.
+func startWorkloadAPI(t *testing.T, responses ...*x509SVIDResponse) (string, func()) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "SpiffeWorkloadAPI",
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "FetchX509SVID",
+			ServerStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				md, _ := metadata.FromIncomingContext(stream.Context())
+				if len(md.Get(workloadAPIHeader)) != 1 || md.Get(workloadAPIHeader)[0] != "true" {
+					return status.Error(codes.InvalidArgument, "security header missing from request")
+				}
+
+				if err := stream.RecvMsg(&x509SVIDRequest{}); err != nil {
+					return err
+				}
+
+				for _, resp := range responses {
+					if err := stream.SendMsg(resp); err != nil {
+						return err
+					}
+				}
+
+				<-stream.Context().Done()
+				return nil
+			},
+		}},
+	}, struct{}{})
+
+	go func() { _ = server.Serve(listener) }()
+
+	return "tcp://" + listener.Addr().String(), server.Stop
+}
+
+func TestNewSource(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		addr            string
+		env             string
+		expectedNetwork string
+		expectedAddress string
+		expectedErr     bool
+	}{
+		{
+			desc:            "unix socket",
+			addr:            "unix:///run/spire/sockets/agent.sock",
+			expectedNetwork: "unix",
+			expectedAddress: "/run/spire/sockets/agent.sock",
+		},
+		{
+			desc:            "tcp",
+			addr:            "tcp://127.0.0.1:8081",
+			expectedNetwork: "tcp",
+			expectedAddress: "127.0.0.1:8081",
+		},
+		{
+			desc:            "environment variable",
+			env:             "unix:///tmp/agent.sock",
+			expectedNetwork: "unix",
+			expectedAddress: "/tmp/agent.sock",
+		},
+		{
+			desc:        "no address",
+			expectedErr: true,
+		},
+		{
+			desc:        "relative unix socket",
+			addr:        "unix://agent.sock",
+			expectedErr: true,
+		},
+		{
+			desc:        "tcp host name",
+			addr:        "tcp://localhost:8081",
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown scheme",
+			addr:        "http://127.0.0.1:8081",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			previous, ok := os.LookupEnv(endpointSocketEnv)
+			require.NoError(t, os.Setenv(endpointSocketEnv, test.env))
+			defer func() {
+				if ok {
+					_ = os.Setenv(endpointSocketEnv, previous)
+				} else {
+					_ = os.Unsetenv(endpointSocketEnv)
+				}
+			}()
+
+			source, err := NewSource(&Configuration{WorkloadAPIAddr: test.addr})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedNetwork, source.network)
+			assert.Equal(t, test.expectedAddress, source.address)
+		})
+	}
+}
+
+func TestSource_Run(t *testing.T) {
+	svid := newSVID(t, "spiffe://example.org/traefik", "traefik.example.org")
+	federated := newSVID(t, "spiffe://example.com/web")
+
+	addr, stop := startWorkloadAPI(t, &x509SVIDResponse{
+		Svids:            []*x509SVID{svid},
+		FederatedBundles: map[string][]byte{"spiffe://example.com": federated.Bundle},
+	})
+	defer stop()
+
+	source, err := NewSource(&Configuration{WorkloadAPIAddr: addr})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go source.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		return len(source.X509Bundle("example.org")) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, svid.Bundle, source.X509Bundle("example.org")[0].Raw)
+	assert.Equal(t, federated.Bundle, source.X509Bundle("example.com")[0].Raw)
+	assert.Empty(t, source.X509Bundle("example.net"))
+
+	testCases := []struct {
+		serverName string
+		expected   bool
+	}{
+		{serverName: "", expected: true},
+		{serverName: "traefik.example.org", expected: true},
+		{serverName: "other.example.org", expected: false},
+	}
+
+	for _, test := range testCases {
+		cert, err := source.ResolveCertificate(&tls.ClientHelloInfo{ServerName: test.serverName})
+		require.NoError(t, err)
+
+		if !test.expected {
+			assert.Nil(t, cert, test.serverName)
+			continue
+		}
+
+		require.NotNil(t, cert, test.serverName)
+		assert.Equal(t, svid.X509SVID, cert.Certificate[0])
+	}
+}
+
+func TestSource_update_invalid(t *testing.T) {
+	source := &Source{}
+
+	err := source.update(&x509SVIDResponse{})
+	assert.Error(t, err)
+
+	svid := newSVID(t, "spiffe://example.org/traefik")
+	svid.X509SVIDKey = []byte("invalid")
+
+	err = source.update(&x509SVIDResponse{Svids: []*x509SVID{svid}})
+	assert.Error(t, err)
+
+	// The invalid X.509-SVIDs are ignored.
+	cert, err := source.ResolveCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Nil(t, cert)
+}
+
+func TestTrustDomain(t *testing.T) {
+	testCases := []struct {
+		id          string
+		expected    string
+		expectedErr bool
+	}{
+		{id: "spiffe://example.org/ns/default/sa/web", expected: "example.org"},
+		{id: "spiffe://Example.ORG", expected: "example.org"},
+		{id: "https://example.org/web", expectedErr: true},
+		{id: "spiffe:///web", expectedErr: true},
+		{id: "spiffe://example.org:443/web", expectedErr: true},
+		{id: "spiffe://example.org/web?q=1", expectedErr: true},
+	}
+
+	for _, test := range testCases {
+		trustDomain, err := TrustDomain(test.id)
+		if test.expectedErr {
+			assert.Error(t, err, test.id)
+			continue
+		}
+
+		require.NoError(t, err, test.id)
+		assert.Equal(t, test.expected, trustDomain)
+	}
+}
@@ -0,0 +1,50 @@
+package spiffe
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// The messages of the SPIFFE Workload API (workload.proto), limited to the X.509-SVID profile.
+// They are wire compatible with the messages of the Workload API, whose other fields are ignored.
+
+const (
+	fetchX509SVIDMethod = "/SpiffeWorkloadAPI/FetchX509SVID"
+
+	// workloadAPIHeader is the metadata required by the Workload API on each request,
+	// preventing the requests forwarded by a confused deputy.
+	workloadAPIHeader = "workload.spiffe.io"
+)
+
+// x509SVIDRequest is X509SVIDRequest.
+type x509SVIDRequest struct{}
+
+func (m *x509SVIDRequest) Reset()         { *m = x509SVIDRequest{} }
+func (m *x509SVIDRequest) String() string { return proto.CompactTextString(m) }
+func (*x509SVIDRequest) ProtoMessage()    {}
+
+// x509SVIDResponse is X509SVIDResponse.
+type x509SVIDResponse struct {
+	Svids            []*x509SVID       `protobuf:"bytes,1,rep,name=svids,proto3"`
+	Crl              [][]byte          `protobuf:"bytes,2,rep,name=crl,proto3"`
+	FederatedBundles map[string][]byte `protobuf:"bytes,3,rep,name=federated_bundles,json=federatedBundles,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *x509SVIDResponse) Reset()         { *m = x509SVIDResponse{} }
+func (m *x509SVIDResponse) String() string { return proto.CompactTextString(m) }
+func (*x509SVIDResponse) ProtoMessage()    {}
+
+// x509SVID is X509SVID.
+type x509SVID struct {
+	// SpiffeID is the SPIFFE ID of the SVID.
+	SpiffeID string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId,proto3"`
+	// X509SVID is the ASN.1 DER encoded certificate chain, leaf first.
+	X509SVID []byte `protobuf:"bytes,2,opt,name=x509_svid,json=x509Svid,proto3"`
+	// X509SVIDKey is the ASN.1 DER encoded PKCS#8 private key.
+	X509SVIDKey []byte `protobuf:"bytes,3,opt,name=x509_svid_key,json=x509SvidKey,proto3"`
+	// Bundle is the ASN.1 DER encoded certificates of the trust domain of the SVID.
+	Bundle []byte `protobuf:"bytes,4,opt,name=bundle,proto3"`
+}
+
+func (m *x509SVID) Reset()         { *m = x509SVID{} }
+func (m *x509SVID) String() string { return proto.CompactTextString(m) }
+func (*x509SVID) ProtoMessage()    {}
@@ -0,0 +1,38 @@
+package tcp
+
+import "io"
+
+// copyConn copies the bytes read from src to dst, until EOF on src.
+// When both connections are TCP connections once unwrapped,
+// the copy is done with splice(2), without going through the user space.
+func copyConn(dst, src WriteCloser) (int64, error) {
+	var written int64
+
+	// The peeked bytes are not in the socket anymore, so they are written first.
+	if c, ok := src.(*Conn); ok && len(c.Peeked) > 0 {
+		n, err := dst.Write(c.Peeked)
+		written += int64(n)
+		c.Peeked = nil
+		if err != nil {
+			return written, err
+		}
+	}
+
+	n, err := io.Copy(rawConn(dst), rawConn(src))
+	return written + n, err
+}
+
+// rawConn returns the connection underlying the wrappers which do not alter the bytes read and written.
+// The peeked bytes of a Conn are ignored: they must have been consumed before reading from the returned connection.
+func rawConn(conn WriteCloser) WriteCloser {
+	for {
+		switch c := conn.(type) {
+		case *Conn:
+			conn = c.WriteCloser
+		case connWrapper:
+			conn = c.Underlying()
+		default:
+			return conn
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package tcp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawConn(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	tcpConn := conn.(*net.TCPConn)
+
+	testCases := []struct {
+		desc     string
+		conn     WriteCloser
+		expected WriteCloser
+	}{
+		{
+			desc:     "TCP connection",
+			conn:     tcpConn,
+			expected: tcpConn,
+		},
+		{
+			desc:     "wrapped connection",
+			conn:     &Conn{WriteCloser: &trackedConn{WriteCloser: tcpConn}},
+			expected: tcpConn,
+		},
+		{
+			desc:     "unknown wrapper",
+			conn:     &Conn{WriteCloser: &opaqueConn{WriteCloser: tcpConn}},
+			expected: &opaqueConn{WriteCloser: tcpConn},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, rawConn(test.conn))
+		})
+	}
+}
+
+// opaqueConn is a connection wrapper not giving its underlying connection.
+type opaqueConn struct {
+	WriteCloser
+}
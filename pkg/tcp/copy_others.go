@@ -0,0 +1,10 @@
+// +build !linux
+
+package tcp
+
+import "io"
+
+// copyConn copies the bytes read from src to dst, until EOF on src.
+func copyConn(dst, src WriteCloser) (int64, error) {
+	return io.Copy(dst, src)
+}
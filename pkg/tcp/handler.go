@@ -26,3 +26,9 @@ type WriteCloser interface {
 	// It corresponds to sending a FIN packet.
 	CloseWrite() error
 }
+
+// connWrapper is implemented by the connection wrappers which pass the bytes read and written through unaltered,
+// so that the bytes can be copied directly between the underlying connections.
+type connWrapper interface {
+	Underlying() WriteCloser
+}
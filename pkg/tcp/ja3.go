@@ -0,0 +1,199 @@
+package tcp
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type ja3Key struct{}
+
+// ja3Fingerprints holds the JA3 fingerprints of the open TLS connections terminated by the routers, keyed by *tls.Conn.
+var ja3Fingerprints sync.Map
+
+// WithClientJA3 returns a copy of the context holding the JA3 fingerprint of the client.
+func WithClientJA3(ctx context.Context, ja3 string) context.Context {
+	return context.WithValue(ctx, ja3Key{}, ja3)
+}
+
+// GetClientJA3 returns the JA3 fingerprint of the client held by the context, or the empty string.
+func GetClientJA3(ctx context.Context) string {
+	if ja3, ok := ctx.Value(ja3Key{}).(string); ok {
+		return ja3
+	}
+	return ""
+}
+
+// ConnClientJA3 returns the JA3 fingerprint of the ClientHello of the TLS connection,
+// if it was terminated by a router, and is still open.
+func ConnClientJA3(conn net.Conn) string {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return ""
+	}
+
+	if ja3, ok := ja3Fingerprints.Load(tlsConn); ok {
+		return ja3.(string)
+	}
+	return ""
+}
+
+// ja3Conn is the connection of a TLS connection whose JA3 fingerprint is known,
+// which forgets the fingerprint when closed.
+type ja3Conn struct {
+	WriteCloser
+	tlsConn *tls.Conn
+	ja3     string
+}
+
+// ClientJA3 returns the JA3 fingerprint of the ClientHello of the connection,
+// for the TLS configurations to look it up from the ClientHelloInfo.
+func (c *ja3Conn) ClientJA3() string {
+	return c.ja3
+}
+
+func (c *ja3Conn) Close() error {
+	ja3Fingerprints.Delete(c.tlsConn)
+	return c.WriteCloser.Close()
+}
+
+// clientHelloJA3 returns the JA3 fingerprint of the ClientHello at the start of the peeked bytes,
+// i.e. the MD5 hash of its version, cipher suites, extensions, elliptic curves, and elliptic curve point formats,
+// without the GREASE values.
+// The empty string is returned when the ClientHello is not entirely held by the first record.
+func clientHelloJA3(peeked string) string {
+	if len(peeked) < recordHeaderLen {
+		return ""
+	}
+
+	recLen := int(peeked[3])<<8 | int(peeked[4])
+	if len(peeked) < recordHeaderLen+recLen {
+		return ""
+	}
+
+	msg := &helloReader{b: []byte(peeked[recordHeaderLen : recordHeaderLen+recLen]), truncated: new(bool)}
+
+	// The handshake message header: type 1 (ClientHello), then the message length.
+	if msgType := msg.bytes(1); msgType == nil || msgType[0] != 1 {
+		return ""
+	}
+	body := msg.prefixed(3)
+
+	version := body.uint16()
+	body.bytes(32)   // Random.
+	body.prefixed(1) // Session ID.
+
+	var ciphers []string
+	cipherSuites := body.prefixed(2)
+	for !cipherSuites.empty() {
+		ciphers = appendNotGREASE(ciphers, cipherSuites.uint16())
+	}
+
+	body.prefixed(1) // Compression methods.
+
+	// The extensions are optional.
+	exts := &helloReader{truncated: body.truncated}
+	if !body.empty() {
+		exts = body.prefixed(2)
+	}
+
+	var extensions, curves, pointFormats []string
+	for !exts.empty() {
+		extType := exts.uint16()
+		data := exts.prefixed(2)
+		extensions = appendNotGREASE(extensions, extType)
+
+		switch extType {
+		case 10: // Supported groups.
+			groups := data.prefixed(2)
+			for !groups.empty() {
+				curves = appendNotGREASE(curves, groups.uint16())
+			}
+		case 11: // Elliptic curve point formats.
+			for _, format := range data.prefixed(1).b {
+				pointFormats = append(pointFormats, strconv.Itoa(int(format)))
+			}
+		}
+	}
+
+	if *msg.truncated {
+		return ""
+	}
+
+	ja3 := strings.Join([]string{
+		strconv.Itoa(int(version)),
+		strings.Join(ciphers, "-"),
+		strings.Join(extensions, "-"),
+		strings.Join(curves, "-"),
+		strings.Join(pointFormats, "-"),
+	}, ",")
+
+	hash := md5.Sum([]byte(ja3))
+	return hex.EncodeToString(hash[:])
+}
+
+// isGREASE reports whether the value is one of the reserved GREASE values (RFC 8701), ignored by JA3.
+func isGREASE(value uint16) bool {
+	return value&0x0f0f == 0x0a0a && value>>8 == value&0xff
+}
+
+func appendNotGREASE(values []string, value uint16) []string {
+	if isGREASE(value) {
+		return values
+	}
+	return append(values, strconv.Itoa(int(value)))
+}
+
+// helloReader reads the fields of a ClientHello, and records whether it was truncated,
+// in a flag shared with the readers of its fields.
+// Once truncated, it returns empty fields.
+type helloReader struct {
+	b         []byte
+	truncated *bool
+}
+
+func (r *helloReader) empty() bool {
+	return *r.truncated || len(r.b) == 0
+}
+
+func (r *helloReader) bytes(n int) []byte {
+	if *r.truncated || len(r.b) < n {
+		*r.truncated = true
+		return nil
+	}
+
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *helloReader) uint16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return uint16(b[0])<<8 | uint16(b[1])
+}
+
+// prefixed returns a reader of the field prefixed by its length, encoded on lenBytes bytes.
+func (r *helloReader) prefixed(lenBytes int) *helloReader {
+	field := &helloReader{truncated: r.truncated}
+
+	b := r.bytes(lenBytes)
+	if b == nil {
+		return field
+	}
+
+	var n int
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+
+	field.b = r.bytes(n)
+	return field
+}
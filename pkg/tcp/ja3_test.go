@@ -0,0 +1,133 @@
+package tcp
+
+import (
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handshakeRecord returns the TLS record holding the handshake message of the body.
+func handshakeRecord(msgType byte, body []byte) []byte {
+	msg := append([]byte{msgType, byte(len(body) >> 16), byte(len(body) >> 8), byte(len(body))}, body...)
+	return append([]byte{0x16, 0x03, 0x01, byte(len(msg) >> 8), byte(len(msg))}, msg...)
+}
+
+func md5Hex(s string) string {
+	hash := md5.Sum([]byte(s))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestClientHelloJA3(t *testing.T) {
+	body := []byte{
+		0x03, 0x03, // Version.
+	}
+	body = append(body, make([]byte, 32)...) // Random.
+	body = append(body,
+		0x00,                                           // Session ID.
+		0x00, 0x06, 0x3a, 0x3a, 0x13, 0x01, 0xc0, 0x2f, // Cipher suites, with a GREASE value.
+		0x01, 0x00, // Compression methods.
+		0x00, 0x1a, // Extensions.
+		0x1a, 0x1a, 0x00, 0x00, // GREASE extension.
+		0x00, 0x0a, 0x00, 0x08, 0x00, 0x06, 0x4a, 0x4a, 0x00, 0x1d, 0x00, 0x17, // Supported groups, with a GREASE value.
+		0x00, 0x0b, 0x00, 0x02, 0x01, 0x00, // Point formats.
+		0x00, 0x17, 0x00, 0x00, // Extended master secret.
+	)
+
+	testCases := []struct {
+		desc     string
+		peeked   []byte
+		expected string
+	}{
+		{
+			desc:     "ClientHello",
+			peeked:   handshakeRecord(1, body),
+			expected: md5Hex("771,4865-49199,10-11-23,29-23,0"),
+		},
+		{
+			desc:     "ClientHello followed by other bytes",
+			peeked:   append(handshakeRecord(1, body), 0x16, 0x03),
+			expected: md5Hex("771,4865-49199,10-11-23,29-23,0"),
+		},
+		{
+			desc:     "ClientHello without extensions",
+			peeked:   handshakeRecord(1, body[:len(body)-28]),
+			expected: md5Hex("771,4865-49199,,,"),
+		},
+		{
+			desc:   "truncated record",
+			peeked: handshakeRecord(1, body)[:40],
+		},
+		{
+			desc:   "truncated extension",
+			peeked: handshakeRecord(1, body[:len(body)-2]),
+		},
+		{
+			desc:   "not a ClientHello",
+			peeked: handshakeRecord(2, body),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, clientHelloJA3(string(test.peeked)))
+		})
+	}
+}
+
+func TestRouter_clientJA3(t *testing.T) {
+	expected := clientHelloJA3(string(clientHelloRecord(t, "foo.bar")))
+	require.NotEmpty(t, expected)
+
+	var tlsConn *tls.Conn
+	fingerprints := make(chan string, 1)
+
+	router := &Router{}
+	router.AddRouteTLS("foo.bar", HandlerFunc(func(conn WriteCloser) {
+		tlsConn = conn.(*tls.Conn)
+		fingerprints <- ConnClientJA3(tlsConn)
+		_, _ = conn.Write([]byte("hello"))
+		_ = conn.Close()
+	}), selfSignedTLSConfig(t, "foo.bar"))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		router.ServeTCP(conn.(*net.TCPConn))
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, err)
+
+	client := tls.Client(conn, &tls.Config{ServerName: "foo.bar", InsecureSkipVerify: true})
+	response, err := ioutil.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(response))
+
+	assert.Equal(t, expected, <-fingerprints)
+
+	<-done
+	assert.Empty(t, ConnClientJA3(tlsConn), "the fingerprint of a closed connection is forgotten")
+}
@@ -0,0 +1,271 @@
+package tcp
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// Results of the connections closed once their handler is drained.
+const (
+	// DrainResultDrained is the result of the connections ended, or closed once idle, before the drain timeout.
+	DrainResultDrained = "drained"
+	// DrainResultKilled is the result of the connections still active at the drain timeout.
+	DrainResultKilled = "killed"
+)
+
+// Limits are the limits of the connections of a TCP service.
+type Limits struct {
+	// IdleTimeout is the duration after which the connections without any bytes read or written are closed.
+	IdleTimeout time.Duration
+	// MaxLifetime is the duration after which the connections are closed, whatever their activity.
+	MaxLifetime time.Duration
+	// MaxConnections is the maximum number of concurrent connections, beyond which the new connections are closed.
+	MaxConnections int
+	// DrainIdleTimeout is the duration after which the idle connections are closed once the handler is drained,
+	// before the drain timeout.
+	DrainIdleTimeout time.Duration
+}
+
+// LimitedHandler is a handler limiting its connections, which can be drained once it is replaced.
+type LimitedHandler struct {
+	limits Limits
+
+	mu      sync.Mutex
+	next    Handler
+	conns   map[*limitedConn]struct{}
+	drained bool
+	// onDrained is called with the result of each connection closed once the handler is drained.
+	onDrained func(result string)
+}
+
+// limitedConn is a connection served by a LimitedHandler.
+type limitedConn struct {
+	WriteCloser
+	// activity is the connection noticing the bytes read and written, if the idle connections are closed.
+	activity *activityConn
+	// closed is whether the connection is closed by the drain.
+	closed bool
+}
+
+// NewLimitedHandler creates a new LimitedHandler.
+func NewLimitedHandler(next Handler, limits Limits) *LimitedHandler {
+	return &LimitedHandler{
+		next:   next,
+		limits: limits,
+		conns:  make(map[*limitedConn]struct{}),
+	}
+}
+
+// SetNext sets the handler of the new connections, when the handler is reused by a configuration of the same service.
+func (h *LimitedHandler) SetNext(next Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.next = next
+}
+
+// ServeTCP forwards the connection to the next handler, unless the maximum number of connections is reached.
+func (h *LimitedHandler) ServeTCP(conn WriteCloser) {
+	tracked := &limitedConn{WriteCloser: conn}
+	if h.limits.IdleTimeout > 0 || h.limits.DrainIdleTimeout > 0 {
+		tracked.activity = newActivityConn(conn, h.limits.IdleTimeout)
+		defer tracked.activity.stop()
+	}
+
+	next, ok := h.track(tracked)
+	if !ok {
+		log.WithoutContext().Debugf("Closing connection from %s: maximum number of connections reached, or service drained", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+	defer h.untrack(tracked)
+
+	if h.limits.MaxLifetime > 0 {
+		lifetime := time.AfterFunc(h.limits.MaxLifetime, func() {
+			log.WithoutContext().Debugf("Closing connection from %s: maximum lifetime reached", conn.RemoteAddr())
+			conn.Close()
+		})
+		defer lifetime.Stop()
+	}
+
+	if tracked.activity != nil {
+		next.ServeTCP(tracked.activity)
+		return
+	}
+
+	next.ServeTCP(conn)
+}
+
+// Drain closes the connections once they are idle, if a DrainIdleTimeout is set, and the connections still open after the timeout,
+// once the handler is replaced by a handler of another configuration.
+// The new connections, still routed to the handler by the previous routers, are closed right away.
+// The onDrained function, if any, is called with the result of each connection.
+func (h *LimitedHandler) Drain(timeout time.Duration, onDrained func(result string)) {
+	h.mu.Lock()
+	h.drained = true
+	h.onDrained = onDrained
+	h.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+
+	go func() {
+		if h.limits.DrainIdleTimeout > 0 {
+			ticker := time.NewTicker(drainCheckInterval(h.limits.DrainIdleTimeout))
+			defer ticker.Stop()
+
+			for now := range ticker.C {
+				if !now.Before(deadline) {
+					break
+				}
+
+				// No new connections are tracked once the handler is drained.
+				if h.closeIdle() {
+					return
+				}
+			}
+		}
+
+		time.Sleep(time.Until(deadline))
+		h.closeAll()
+	}()
+}
+
+// closeIdle closes the connections idle since the DrainIdleTimeout, and returns whether no connection is left.
+func (h *LimitedHandler) closeIdle() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for conn := range h.conns {
+		if conn.activity == nil || conn.activity.idle() < h.limits.DrainIdleTimeout {
+			continue
+		}
+
+		log.WithoutContext().Debugf("Closing idle connection from %s: its service configuration changed", conn.RemoteAddr())
+		h.closeDrained(conn, DrainResultDrained)
+	}
+
+	return len(h.conns) == 0
+}
+
+// closeAll closes the connections still open at the drain timeout.
+func (h *LimitedHandler) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for conn := range h.conns {
+		log.WithoutContext().Debugf("Closing connection from %s: its service configuration changed", conn.RemoteAddr())
+		h.closeDrained(conn, DrainResultKilled)
+	}
+}
+
+func (h *LimitedHandler) closeDrained(conn *limitedConn, result string) {
+	conn.closed = true
+	conn.Close()
+	delete(h.conns, conn)
+
+	if h.onDrained != nil {
+		h.onDrained(result)
+	}
+}
+
+func (h *LimitedHandler) track(conn *limitedConn) (Handler, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.drained {
+		return nil, false
+	}
+
+	if h.limits.MaxConnections > 0 && len(h.conns) >= h.limits.MaxConnections {
+		return nil, false
+	}
+
+	h.conns[conn] = struct{}{}
+
+	return h.next, true
+}
+
+func (h *LimitedHandler) untrack(conn *limitedConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if conn.closed {
+		return
+	}
+
+	delete(h.conns, conn)
+
+	// The connections ending on their own during the drain are drained as well.
+	if h.drained && h.onDrained != nil {
+		h.onDrained(DrainResultDrained)
+	}
+}
+
+// drainCheckInterval returns the interval at which the idle connections of a drained handler are looked for.
+func drainCheckInterval(drainIdleTimeout time.Duration) time.Duration {
+	interval := drainIdleTimeout / 2
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+
+	return interval
+}
+
+// activityConn is a connection noticing the bytes read and written,
+// and closed when no bytes are read or written for the idle timeout, if any.
+// It does not implement connWrapper, as the bytes copied with splice(2) would not be noticed.
+type activityConn struct {
+	WriteCloser
+	timeout time.Duration
+
+	// lastActivity is the Unix time, in nanoseconds, of the last read or write.
+	lastActivity int64
+	timer        *time.Timer
+}
+
+func newActivityConn(conn WriteCloser, timeout time.Duration) *activityConn {
+	c := &activityConn{WriteCloser: conn, timeout: timeout, lastActivity: time.Now().UnixNano()}
+	if timeout > 0 {
+		c.timer = time.AfterFunc(timeout, c.check)
+	}
+
+	return c
+}
+
+func (c *activityConn) Read(p []byte) (int, error) {
+	n, err := c.WriteCloser.Read(p)
+	atomic.StoreInt64(&c.lastActivity, time.Now().UnixNano())
+	return n, err
+}
+
+func (c *activityConn) Write(p []byte) (int, error) {
+	n, err := c.WriteCloser.Write(p)
+	atomic.StoreInt64(&c.lastActivity, time.Now().UnixNano())
+	return n, err
+}
+
+// idle returns the duration since the last read or write.
+func (c *activityConn) idle() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&c.lastActivity)))
+}
+
+// check closes the connection if it is idle since the timeout, and checks it again otherwise.
+func (c *activityConn) check() {
+	idle := c.idle()
+	if idle >= c.timeout {
+		log.WithoutContext().Debugf("Closing connection from %s: idle timeout reached", c.RemoteAddr())
+		c.WriteCloser.Close()
+		return
+	}
+
+	c.timer.Reset(c.timeout - idle)
+}
+
+func (c *activityConn) stop() {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
@@ -0,0 +1,188 @@
+package tcp
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tcpConnPair returns the server and client sides of a TCP connection.
+func tcpConnPair(t *testing.T) (*net.TCPConn, net.Conn) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	serverConn, ok := <-accepted
+	require.True(t, ok)
+
+	return serverConn.(*net.TCPConn), clientConn
+}
+
+// echoHandler returns a handler writing back the bytes read, until the connection is closed.
+func echoHandler() Handler {
+	return HandlerFunc(func(conn WriteCloser) {
+		_, _ = io.Copy(conn, conn)
+		_ = conn.Close()
+	})
+}
+
+// closedWithin returns whether the connection is closed by the server within the duration.
+func closedWithin(t *testing.T, conn net.Conn, d time.Duration) bool {
+	t.Helper()
+
+	err := conn.SetReadDeadline(time.Now().Add(d))
+	require.NoError(t, err)
+
+	_, err = ioutil.ReadAll(conn)
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return false
+	}
+
+	return true
+}
+
+func TestLimitedHandler_maxConnections(t *testing.T) {
+	handler := NewLimitedHandler(echoHandler(), Limits{MaxConnections: 1})
+
+	serverConn, clientConn := tcpConnPair(t)
+	defer func() { _ = clientConn.Close() }()
+	go handler.ServeTCP(serverConn)
+
+	// The first connection is served once it echoes, so that the second one is over the limit.
+	_, err := clientConn.Write([]byte("foo"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(clientConn, make([]byte, 3))
+	require.NoError(t, err)
+
+	rejectedServerConn, rejectedClientConn := tcpConnPair(t)
+	defer func() { _ = rejectedClientConn.Close() }()
+	go handler.ServeTCP(rejectedServerConn)
+
+	assert.True(t, closedWithin(t, rejectedClientConn, time.Second))
+	assert.False(t, closedWithin(t, clientConn, 100*time.Millisecond))
+}
+
+func TestLimitedHandler_maxLifetime(t *testing.T) {
+	handler := NewLimitedHandler(echoHandler(), Limits{MaxLifetime: 100 * time.Millisecond})
+
+	serverConn, clientConn := tcpConnPair(t)
+	defer func() { _ = clientConn.Close() }()
+	go handler.ServeTCP(serverConn)
+
+	assert.True(t, closedWithin(t, clientConn, time.Second))
+}
+
+func TestLimitedHandler_idleTimeout(t *testing.T) {
+	handler := NewLimitedHandler(echoHandler(), Limits{IdleTimeout: 200 * time.Millisecond})
+
+	serverConn, clientConn := tcpConnPair(t)
+	defer func() { _ = clientConn.Close() }()
+	go handler.ServeTCP(serverConn)
+
+	// The connection stays open while bytes are exchanged.
+	for i := 0; i < 5; i++ {
+		_, err := clientConn.Write([]byte("foo"))
+		require.NoError(t, err)
+		_, err = io.ReadFull(clientConn, make([]byte, 3))
+		require.NoError(t, err)
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	assert.True(t, closedWithin(t, clientConn, time.Second))
+}
+
+func TestLimitedHandler_drain(t *testing.T) {
+	handler := NewLimitedHandler(echoHandler(), Limits{})
+
+	serverConn, clientConn := tcpConnPair(t)
+	defer func() { _ = clientConn.Close() }()
+	go handler.ServeTCP(serverConn)
+
+	_, err := clientConn.Write([]byte("foo"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(clientConn, make([]byte, 3))
+	require.NoError(t, err)
+
+	results := make(chan string, 1)
+	handler.Drain(200*time.Millisecond, func(result string) {
+		results <- result
+	})
+
+	assert.False(t, closedWithin(t, clientConn, 50*time.Millisecond))
+	assert.True(t, closedWithin(t, clientConn, time.Second))
+	assert.Equal(t, DrainResultKilled, <-results)
+
+	newServerConn, newClientConn := tcpConnPair(t)
+	defer func() { _ = newClientConn.Close() }()
+	go handler.ServeTCP(newServerConn)
+
+	assert.True(t, closedWithin(t, newClientConn, time.Second))
+}
+
+func TestLimitedHandler_drainIdle(t *testing.T) {
+	handler := NewLimitedHandler(echoHandler(), Limits{DrainIdleTimeout: 100 * time.Millisecond})
+
+	idleServerConn, idleClientConn := tcpConnPair(t)
+	defer func() { _ = idleClientConn.Close() }()
+	go handler.ServeTCP(idleServerConn)
+
+	activeServerConn, activeClientConn := tcpConnPair(t)
+	defer func() { _ = activeClientConn.Close() }()
+	go handler.ServeTCP(activeServerConn)
+
+	// Both connections are served before the drain.
+	for _, conn := range []net.Conn{idleClientConn, activeClientConn} {
+		_, err := conn.Write([]byte("foo"))
+		require.NoError(t, err)
+		_, err = io.ReadFull(conn, make([]byte, 3))
+		require.NoError(t, err)
+	}
+
+	results := make(chan string, 2)
+	handler.Drain(time.Second, func(result string) {
+		results <- result
+	})
+
+	// The idle connection is drained, while the active connection stays until the drain timeout.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(20 * time.Millisecond):
+				if _, err := activeClientConn.Write([]byte("foo")); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	assert.True(t, closedWithin(t, idleClientConn, 500*time.Millisecond))
+	assert.Equal(t, DrainResultDrained, <-results)
+
+	assert.True(t, closedWithin(t, activeClientConn, 2*time.Second))
+	assert.Equal(t, DrainResultKilled, <-results)
+}
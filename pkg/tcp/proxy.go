@@ -1,7 +1,6 @@
 package tcp
 
 import (
-	"io"
 	"net"
 	"time"
 
@@ -53,7 +52,7 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 }
 
 func (p Proxy) connCopy(dst, src WriteCloser, errCh chan error) {
-	_, err := io.Copy(dst, src)
+	_, err := copyConn(dst, src)
 	errCh <- err
 
 	errClose := dst.CloseWrite()
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -79,3 +81,63 @@ func TestCloseWrite(t *testing.T) {
 	require.Equal(t, int64(4), n)
 	require.Equal(t, "PONG", buffer.String())
 }
+
+func TestProxy_peekedBytes(t *testing.T) {
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backendListener.Close()
+
+	go func() {
+		conn, err := backendListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = io.Copy(conn, conn)
+		_ = conn.(*net.TCPConn).CloseWrite()
+	}()
+
+	proxy, err := NewProxy(backendListener.Addr().String(), 10*time.Millisecond)
+	require.NoError(t, err)
+
+	proxyListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer proxyListener.Close()
+
+	go func() {
+		conn, err := proxyListener.Accept()
+		if err != nil {
+			return
+		}
+
+		proxy.ServeTCP(&Conn{
+			Peeked:      []byte("hello "),
+			WriteCloser: &trackedConn{WriteCloser: conn.(*net.TCPConn)},
+		})
+	}()
+
+	conn, err := net.Dial("tcp", proxyListener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write(bytes.Repeat([]byte("world"), 100000))
+	require.NoError(t, err)
+
+	err = conn.(*net.TCPConn).CloseWrite()
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+
+	require.Equal(t, "hello "+strings.Repeat("world", 100000), string(data))
+}
+
+// trackedConn is a connection wrapper giving its underlying connection.
+type trackedConn struct {
+	WriteCloser
+}
+
+func (c *trackedConn) Underlying() WriteCloser {
+	return c.WriteCloser
+}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
@@ -13,6 +15,34 @@ import (
 	"github.com/containous/traefik/v2/pkg/log"
 )
 
+// Reasons of the failures to peek the TLS ClientHello of a connection.
+const (
+	ClientHelloTimeout   = "timeout"
+	ClientHelloMalformed = "malformed"
+)
+
+// errMalformedClientHello is returned when the ClientHello can not be parsed, or exceeds the maximum size.
+var errMalformedClientHello = errors.New("malformed TLS ClientHello")
+
+// ClientHelloOptions configures the peeking of the TLS ClientHello of the connections, to route them by server name.
+type ClientHelloOptions struct {
+	// Timeout is the maximum duration to receive the ClientHello, 0 meaning no timeout.
+	Timeout time.Duration
+	// MaxBytes is the maximum size of the TLS record holding the ClientHello, 0 meaning the size of the default buffer.
+	MaxBytes int
+	// Fallback handles the connections whose ClientHello is malformed or not received in time as non-TLS connections,
+	// instead of closing them.
+	Fallback bool
+	// Postgres accepts the SSLRequest of the Postgres clients, which then send their ClientHello.
+	Postgres bool
+	// StartTLS, if not empty, is the mail protocol (StartTLSSMTP, StartTLSIMAP, or StartTLSPOP3) whose clients are greeted,
+	// and whose STARTTLS command is accepted, before peeking their ClientHello.
+	// Their connections are only routed to the handlers terminating TLS, which skip the greeting of the backends.
+	StartTLS string
+	// OnFailure, if not nil, is called with the reason of each malformed or timed out ClientHello.
+	OnFailure func(reason string)
+}
+
 // Router is a TCP router
 type Router struct {
 	routingTable      map[string]Handler
@@ -23,6 +53,10 @@ type Router struct {
 	httpsTLSConfig    *tls.Config // default TLS config
 	catchAllNoTLS     Handler
 	hostHTTPTLSConfig map[string]*tls.Config // TLS configs keyed by SNI
+	clientHello       ClientHelloOptions
+	onHandshakeError  func(serverName string, remoteAddr net.Addr, err error)
+	// strictSNIHost returns whether the requests to a host must be sent on a connection for the same server name.
+	strictSNIHost func(serverName string) bool
 }
 
 // ServeTCP forwards the connection to the right TCP/HTTP handler
@@ -34,11 +68,39 @@ func (r *Router) ServeTCP(conn WriteCloser) {
 		return
 	}
 
-	br := bufio.NewReader(conn)
-	serverName, tls, peeked, err := clientHelloServerName(br)
+	var br *bufio.Reader
+	if r.clientHello.MaxBytes > 0 {
+		br = bufio.NewReaderSize(conn, recordHeaderLen+r.clientHello.MaxBytes)
+	} else {
+		br = bufio.NewReader(conn)
+	}
+
+	if r.clientHello.Timeout > 0 {
+		if err := conn.SetReadDeadline(time.Now().Add(r.clientHello.Timeout)); err != nil {
+			log.WithoutContext().Errorf("Error while setting read deadline: %v", err)
+		}
+	}
+
+	serverName, tls, peeked, err := r.peekClientHello(br, conn)
 	if err != nil {
-		conn.Close()
-		return
+		reason := clientHelloFailureReason(err)
+		if reason == "" {
+			conn.Close()
+			return
+		}
+
+		if r.clientHello.OnFailure != nil {
+			r.clientHello.OnFailure(reason)
+		}
+
+		if !r.clientHello.Fallback || r.clientHello.StartTLS != "" {
+			log.WithoutContext().Debugf("Closing connection from %s: %v", conn.RemoteAddr(), err)
+			conn.Close()
+			return
+		}
+
+		log.WithoutContext().Debugf("Handling connection from %s as a non-TLS connection: %v", conn.RemoteAddr(), err)
+		tls = false
 	}
 
 	// Remove read/write deadline and delegate this to underlying tcp server (for now only handled by HTTP Server)
@@ -52,6 +114,12 @@ func (r *Router) ServeTCP(conn WriteCloser) {
 		log.WithoutContext().Errorf("Error while setting write deadline: %v", err)
 	}
 
+	if !tls && r.clientHello.StartTLS != "" {
+		log.WithoutContext().Debugf("Closing connection from %s: no TLS ClientHello after STARTTLS", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+
 	if !tls {
 		switch {
 		case r.catchAllNoTLS != nil:
@@ -68,24 +136,78 @@ func (r *Router) ServeTCP(conn WriteCloser) {
 	serverName = strings.ToLower(serverName)
 	if r.routingTable != nil && serverName != "" {
 		if target, ok := r.routingTable[serverName]; ok {
-			target.ServeTCP(r.GetConn(conn, peeked))
+			r.serveTLS(target, conn, peeked, serverName)
 			return
 		}
 	}
 
 	// FIXME Needs tests
 	if target, ok := r.routingTable["*"]; ok {
-		target.ServeTCP(r.GetConn(conn, peeked))
+		r.serveTLS(target, conn, peeked, serverName)
 		return
 	}
 
 	if r.httpsForwarder != nil {
-		r.httpsForwarder.ServeTCP(r.GetConn(conn, peeked))
+		r.serveTLS(r.httpsForwarder, conn, peeked, serverName)
 	} else {
 		conn.Close()
 	}
 }
 
+// serveTLS forwards the TLS connection to the handler,
+// which must terminate TLS, and skip the greeting of the backend, for the connections upgraded with STARTTLS.
+// The handshake errors of the connections terminated by the router are reported to its handshake errors handler,
+// along with the server name of their ClientHello,
+// and the JA3 fingerprint of their ClientHello is computed from the peeked bytes.
+func (r *Router) serveTLS(target Handler, conn WriteCloser, peeked, serverName string) {
+	tlsHandler, ok := target.(*TLSHandler)
+	if !ok && r.clientHello.StartTLS != "" {
+		log.WithoutContext().Debugf("Closing connection from %s: TLS passthrough is not supported after STARTTLS", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+
+	if !ok {
+		target.ServeTCP(r.GetConn(conn, peeked))
+		return
+	}
+
+	next := tlsHandler.Next
+	if r.clientHello.StartTLS != "" {
+		next = skipGreetingHandler(r.clientHello.StartTLS, next)
+	}
+
+	handler := &TLSHandler{
+		Next:      next,
+		Config:    tlsHandler.Config,
+		ClientJA3: clientHelloJA3(peeked),
+	}
+	if r.onHandshakeError != nil {
+		handler.OnHandshakeError = func(tlsConn *tls.Conn, err error) {
+			r.onHandshakeError(serverName, tlsConn.RemoteAddr(), err)
+		}
+	}
+	handler.ServeTCP(r.GetConn(conn, peeked))
+}
+
+// peekClientHello negotiates TLS with the Postgres clients, or with the clients of the STARTTLS mail protocol, if enabled,
+// and returns the SNI server name inside the TLS ClientHello, without consuming any bytes following the negotiation.
+func (r *Router) peekClientHello(br *bufio.Reader, conn WriteCloser) (string, bool, string, error) {
+	if r.clientHello.StartTLS != "" {
+		if err := negotiateStartTLS(r.clientHello.StartTLS, br, conn); err != nil {
+			return "", false, getPeeked(br), err
+		}
+	}
+
+	if r.clientHello.Postgres {
+		if err := negotiatePostgresSSL(br, conn); err != nil {
+			return "", false, getPeeked(br), err
+		}
+	}
+
+	return clientHelloServerName(br, r.clientHello.MaxBytes)
+}
+
 // AddRoute defines a handler for a given sniHost (* is the only valid option)
 func (r *Router) AddRoute(sniHost string, target Handler) {
 	if r.routingTable == nil {
@@ -107,7 +229,17 @@ func (r *Router) AddRouteHTTPTLS(sniHost string, config *tls.Config) {
 	if r.hostHTTPTLSConfig == nil {
 		r.hostHTTPTLSConfig = map[string]*tls.Config{}
 	}
-	r.hostHTTPTLSConfig[sniHost] = config
+	r.hostHTTPTLSConfig[strings.ToLower(sniHost)] = config
+}
+
+// ClientHello sets the options of the peeking of the TLS ClientHello
+func (r *Router) ClientHello(opts ClientHelloOptions) {
+	r.clientHello = opts
+}
+
+// OnHandshakeError sets the handler called with the error of each failed handshake of the TLS connections terminated by the router.
+func (r *Router) OnHandshakeError(handler func(serverName string, remoteAddr net.Addr, err error)) {
+	r.onHandshakeError = handler
 }
 
 // AddCatchAllNoTLS defines the fallback tcp handler
@@ -135,6 +267,24 @@ func (r *Router) GetHTTPSHandler() http.Handler {
 	return r.httpsHandler
 }
 
+// GetHTTPSTLSConfig gets the TLS config used by the https handler for the given server name
+func (r *Router) GetHTTPSTLSConfig(serverName string) *tls.Config {
+	if config, ok := r.hostHTTPTLSConfig[strings.ToLower(serverName)]; ok {
+		return config
+	}
+	return r.httpsTLSConfig
+}
+
+// StrictSNIHosts sets the function returning whether the requests to a host must be sent on a connection for the same server name.
+func (r *Router) StrictSNIHosts(strictSNIHost func(serverName string) bool) {
+	r.strictSNIHost = strictSNIHost
+}
+
+// IsStrictSNIHost returns whether the requests to the host must be sent on a connection for the same server name.
+func (r *Router) IsStrictSNIHost(host string) bool {
+	return r.strictSNIHost != nil && r.strictSNIHost(host)
+}
+
 // HTTPForwarder sets the tcp handler that will forward the connections to an http handler
 func (r *Router) HTTPForwarder(handler Handler) {
 	r.httpForwarder = handler
@@ -190,17 +340,67 @@ func (c *Conn) Read(p []byte) (n int, err error) {
 	return c.WriteCloser.Read(p)
 }
 
+const recordHeaderLen = 5
+
+// Requests of the Postgres clients before their startup message, made of their length and their code.
+var (
+	postgresSSLRequest    = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+	postgresGSSENCRequest = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x30}
+)
+
+// negotiatePostgresSSL accepts the SSLRequest of a Postgres client, which then sends its TLS ClientHello,
+// and consumes it from br.
+// The GSSENCRequest sent first by some clients is declined, as the client then falls back to the SSLRequest.
+// The connections of the other protocols, and the Postgres connections without TLS, are left untouched.
+func negotiatePostgresSSL(br *bufio.Reader, w io.Writer) error {
+	for {
+		hdr, err := br.Peek(1)
+		if err != nil || hdr[0] != 0 {
+			// The error, if any, is reported by the peeking of the ClientHello.
+			return nil
+		}
+
+		request, err := br.Peek(len(postgresSSLRequest))
+		if err != nil {
+			return peekError(err)
+		}
+
+		var response byte
+		switch {
+		case bytes.Equal(request, postgresSSLRequest):
+			response = 'S'
+		case bytes.Equal(request, postgresGSSENCRequest):
+			response = 'N'
+		default:
+			return nil
+		}
+
+		if _, err := br.Discard(len(request)); err != nil {
+			return err
+		}
+
+		if _, err := w.Write([]byte{response}); err != nil {
+			return err
+		}
+
+		if response == 'S' {
+			return nil
+		}
+	}
+}
+
 // clientHelloServerName returns the SNI server name inside the TLS ClientHello,
 // without consuming any bytes from br.
-// On any error, the empty string is returned.
-func clientHelloServerName(br *bufio.Reader) (string, bool, string, error) {
+// The record holding the ClientHello is rejected as malformed when it is larger than maxBytes, if not 0.
+// On any error, the empty string is returned, with the bytes peeked so far.
+func clientHelloServerName(br *bufio.Reader, maxBytes int) (string, bool, string, error) {
 	hdr, err := br.Peek(1)
 	if err != nil {
 		opErr, ok := err.(*net.OpError)
 		if err != io.EOF && (!ok || !opErr.Timeout()) {
 			log.WithoutContext().Debugf("Error while Peeking first byte: %s", err)
 		}
-		return "", false, "", err
+		return "", false, getPeeked(br), err
 	}
 
 	// No valid TLS record has a type of 0x80, however SSLv2 handshakes
@@ -217,32 +417,63 @@ func clientHelloServerName(br *bufio.Reader) (string, bool, string, error) {
 		return "", false, getPeeked(br), nil // Not TLS.
 	}
 
-	const recordHeaderLen = 5
 	hdr, err = br.Peek(recordHeaderLen)
 	if err != nil {
-		log.Errorf("Error while Peeking hello: %s", err)
-		return "", false, getPeeked(br), nil
+		return "", true, getPeeked(br), peekError(err)
 	}
 
 	recLen := int(hdr[3])<<8 | int(hdr[4]) // ignoring version in hdr[1:3]
+	if maxBytes > 0 && recLen > maxBytes {
+		return "", true, getPeeked(br), fmt.Errorf("%w: record of %d bytes, larger than %d bytes", errMalformedClientHello, recLen, maxBytes)
+	}
+
 	helloBytes, err := br.Peek(recordHeaderLen + recLen)
 	if err != nil {
-		log.Errorf("Error while Hello: %s", err)
-		return "", true, getPeeked(br), nil
+		return "", true, getPeeked(br), peekError(err)
 	}
 
 	sni := ""
+	parsed := false
 	server := tls.Server(sniSniffConn{r: bytes.NewReader(helloBytes)}, &tls.Config{
 		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
 			sni = hello.ServerName
+			parsed = true
 			return nil, nil
 		},
 	})
 	_ = server.Handshake()
 
+	if !parsed {
+		return "", true, getPeeked(br), errMalformedClientHello
+	}
+
 	return sni, true, getPeeked(br), nil
 }
 
+// peekError returns the error of the peeking of the ClientHello,
+// which is malformed if it is truncated, or larger than the buffer.
+func peekError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return err
+	}
+	return fmt.Errorf("%w: %v", errMalformedClientHello, err)
+}
+
+// clientHelloFailureReason returns the reason of the failure to peek the ClientHello,
+// or the empty string if the connection failed for another reason, such as being closed by the client.
+func clientHelloFailureReason(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errMalformedClientHello):
+		return ClientHelloMalformed
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ClientHelloTimeout
+	default:
+		return ""
+	}
+}
+
 func getPeeked(br *bufio.Reader) string {
 	peeked, err := br.Peek(br.Buffered())
 	if err != nil {
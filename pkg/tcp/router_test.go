@@ -0,0 +1,198 @@
+package tcp
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHandler returns a handler writing the message to the connections, and closing them.
+func writeHandler(msg string) Handler {
+	return HandlerFunc(func(conn WriteCloser) {
+		_, _ = conn.Write([]byte(msg))
+		_ = conn.Close()
+	})
+}
+
+// clientHelloRecord returns the TLS record holding a ClientHello for the server name.
+func clientHelloRecord(t *testing.T, serverName string) []byte {
+	t.Helper()
+
+	clientConn, serverConn := net.Pipe()
+	defer func() { _ = serverConn.Close() }()
+
+	go func() {
+		_ = tls.Client(clientConn, &tls.Config{ServerName: serverName}).Handshake()
+		_ = clientConn.Close()
+	}()
+
+	hdr := make([]byte, recordHeaderLen)
+	_, err := io.ReadFull(serverConn, hdr)
+	require.NoError(t, err)
+
+	record := make([]byte, recordHeaderLen+(int(hdr[3])<<8|int(hdr[4])))
+	copy(record, hdr)
+	_, err = io.ReadFull(serverConn, record[recordHeaderLen:])
+	require.NoError(t, err)
+
+	return record
+}
+
+func TestRouter_ClientHello(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		opts             ClientHelloOptions
+		payload          []byte
+		expectedResponse string
+		expectedReason   string
+	}{
+		{
+			desc:             "ClientHello with server name",
+			opts:             ClientHelloOptions{Timeout: time.Second, MaxBytes: 16384},
+			payload:          clientHelloRecord(t, "foo.bar"),
+			expectedResponse: "route",
+		},
+		{
+			desc:             "non-TLS connection",
+			opts:             ClientHelloOptions{Timeout: time.Second, MaxBytes: 16384},
+			payload:          []byte("foo"),
+			expectedResponse: "catchAll",
+		},
+		{
+			desc:           "timeout",
+			opts:           ClientHelloOptions{Timeout: 100 * time.Millisecond},
+			expectedReason: ClientHelloTimeout,
+		},
+		{
+			desc:           "timeout in the ClientHello",
+			opts:           ClientHelloOptions{Timeout: 100 * time.Millisecond},
+			payload:        clientHelloRecord(t, "foo.bar")[:20],
+			expectedReason: ClientHelloTimeout,
+		},
+		{
+			desc:             "timeout with fallback",
+			opts:             ClientHelloOptions{Timeout: 100 * time.Millisecond, Fallback: true},
+			expectedResponse: "catchAll",
+			expectedReason:   ClientHelloTimeout,
+		},
+		{
+			desc:           "malformed ClientHello",
+			opts:           ClientHelloOptions{Timeout: time.Second},
+			payload:        []byte("\x16\x03\x01\x00\x03foo"),
+			expectedReason: ClientHelloMalformed,
+		},
+		{
+			desc:           "ClientHello larger than the maximum size",
+			opts:           ClientHelloOptions{Timeout: time.Second, MaxBytes: 1024},
+			payload:        []byte("\x16\x03\x01\x40\x00"),
+			expectedReason: ClientHelloMalformed,
+		},
+		{
+			desc:             "Postgres SSLRequest",
+			opts:             ClientHelloOptions{Timeout: time.Second, Postgres: true},
+			payload:          bytes.Join([][]byte{postgresSSLRequest, clientHelloRecord(t, "foo.bar")}, nil),
+			expectedResponse: "Sroute",
+		},
+		{
+			desc:             "Postgres GSSENCRequest declined",
+			opts:             ClientHelloOptions{Timeout: time.Second, Postgres: true},
+			payload:          bytes.Join([][]byte{postgresGSSENCRequest, postgresSSLRequest, clientHelloRecord(t, "foo.bar")}, nil),
+			expectedResponse: "NSroute",
+		},
+		{
+			desc:             "Postgres connection without TLS",
+			opts:             ClientHelloOptions{Timeout: time.Second, Postgres: true},
+			payload:          []byte{0, 0, 0, 8, 0, 3, 0, 0},
+			expectedResponse: "catchAll",
+		},
+		{
+			desc:             "Postgres SSLRequest not accepted",
+			opts:             ClientHelloOptions{Timeout: time.Second},
+			payload:          bytes.Join([][]byte{postgresSSLRequest, clientHelloRecord(t, "foo.bar")}, nil),
+			expectedResponse: "catchAll",
+		},
+		{
+			desc:           "truncated Postgres SSLRequest",
+			opts:           ClientHelloOptions{Timeout: 100 * time.Millisecond, Postgres: true},
+			payload:        postgresSSLRequest[:4],
+			expectedReason: ClientHelloTimeout,
+		},
+		{
+			desc:             "malformed ClientHello with fallback",
+			opts:             ClientHelloOptions{Timeout: time.Second, Fallback: true},
+			payload:          []byte("\x16\x03\x01\x00\x03foo"),
+			expectedResponse: "catchAll",
+			expectedReason:   ClientHelloMalformed,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			reasons := make(chan string, 1)
+			test.opts.OnFailure = func(reason string) {
+				reasons <- reason
+			}
+
+			router := &Router{}
+			router.AddRoute("foo.bar", writeHandler("route"))
+			router.AddCatchAllNoTLS(writeHandler("catchAll"))
+			router.ClientHello(test.opts)
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer func() { _ = listener.Close() }()
+
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				router.ServeTCP(conn.(*net.TCPConn))
+			}()
+
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+
+			_, err = conn.Write(test.payload)
+			require.NoError(t, err)
+
+			err = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			require.NoError(t, err)
+
+			response, err := ioutil.ReadAll(conn)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedResponse, string(response))
+
+			var reason string
+			select {
+			case reason = <-reasons:
+			default:
+			}
+			assert.Equal(t, test.expectedReason, reason)
+		})
+	}
+}
+
+func TestRouter_GetHTTPSTLSConfig(t *testing.T) {
+	defaultConfig := &tls.Config{}
+	fooConfig := &tls.Config{}
+
+	router := &Router{}
+	router.HTTPSHandler(nil, defaultConfig)
+	router.AddRouteHTTPTLS("Foo.Bar", fooConfig)
+
+	assert.Same(t, fooConfig, router.GetHTTPSTLSConfig("foo.bar"))
+	assert.Same(t, fooConfig, router.GetHTTPSTLSConfig("FOO.BAR"))
+	assert.Same(t, defaultConfig, router.GetHTTPSTLSConfig("bar.foo"))
+}
@@ -0,0 +1,230 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Mail protocols whose connections are upgraded to TLS by the router, with their STARTTLS command,
+// before being routed by the server name of their TLS ClientHello.
+const (
+	StartTLSSMTP = "smtp"
+	StartTLSIMAP = "imap"
+	StartTLSPOP3 = "pop3"
+)
+
+const (
+	// maxStartTLSCommands is the maximum number of commands of a client before its STARTTLS command.
+	maxStartTLSCommands = 10
+	// maxStartTLSLineLength is the maximum length of the command lines before the STARTTLS command,
+	// and of the greeting of the backends.
+	maxStartTLSLineLength = 1024
+)
+
+// startTLSStep is the step of the STARTTLS negotiation following a command of the client.
+type startTLSStep int
+
+const (
+	startTLSContinue startTLSStep = iota
+	startTLSUpgrade
+	startTLSQuit
+)
+
+// errStartTLSAborted is returned when the client ends the connection before its STARTTLS command.
+var errStartTLSAborted = errors.New("connection ended before STARTTLS")
+
+// startTLSGreetings are the greetings sent by the router to the clients, by protocol.
+var startTLSGreetings = map[string]string{
+	StartTLSSMTP: "220 traefik ESMTP ready\r\n",
+	StartTLSIMAP: "* OK [CAPABILITY IMAP4rev1 STARTTLS LOGINDISABLED] traefik ready\r\n",
+	StartTLSPOP3: "+OK traefik ready\r\n",
+}
+
+// IsStartTLSProtocol returns whether the protocol is a mail protocol supported by the STARTTLS negotiation.
+func IsStartTLSProtocol(protocol string) bool {
+	_, ok := startTLSGreetings[protocol]
+	return ok
+}
+
+// negotiateStartTLS greets the client of the mail protocol, and answers its commands until its STARTTLS command,
+// after which the client sends its TLS ClientHello.
+// The other commands are refused, except the ones discovering the STARTTLS support, the no-ops, and the ones ending the connection.
+func negotiateStartTLS(protocol string, br *bufio.Reader, w io.Writer) error {
+	if _, err := w.Write([]byte(startTLSGreetings[protocol])); err != nil {
+		return err
+	}
+
+	for i := 0; i < maxStartTLSCommands; i++ {
+		line, err := readStartTLSLine(br)
+		if err != nil {
+			return err
+		}
+
+		var reply string
+		var step startTLSStep
+		switch protocol {
+		case StartTLSSMTP:
+			reply, step = smtpReply(line)
+		case StartTLSIMAP:
+			reply, step = imapReply(line)
+		case StartTLSPOP3:
+			reply, step = pop3Reply(line)
+		}
+
+		if _, err := w.Write([]byte(reply)); err != nil {
+			return err
+		}
+
+		switch step {
+		case startTLSUpgrade:
+			return nil
+		case startTLSQuit:
+			return errStartTLSAborted
+		}
+	}
+
+	return fmt.Errorf("no STARTTLS command after %d commands", maxStartTLSCommands)
+}
+
+// readStartTLSLine reads a command line, without its line ending.
+func readStartTLSLine(br *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := br.ReadLine()
+		if err != nil {
+			return "", err
+		}
+
+		line = append(line, chunk...)
+		if len(line) > maxStartTLSLineLength {
+			return "", fmt.Errorf("command line longer than %d bytes", maxStartTLSLineLength)
+		}
+
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
+
+// smtpReply returns the reply to the SMTP command, and the step of the negotiation.
+func smtpReply(line string) (string, startTLSStep) {
+	verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+	switch verb {
+	case "EHLO":
+		return "250-traefik\r\n250 STARTTLS\r\n", startTLSContinue
+	case "HELO":
+		return "250 traefik\r\n", startTLSContinue
+	case "NOOP", "RSET":
+		return "250 OK\r\n", startTLSContinue
+	case "STARTTLS":
+		return "220 Ready to start TLS\r\n", startTLSUpgrade
+	case "QUIT":
+		return "221 Bye\r\n", startTLSQuit
+	default:
+		return "530 Must issue a STARTTLS command first\r\n", startTLSContinue
+	}
+}
+
+// imapReply returns the reply to the tagged IMAP command, and the step of the negotiation.
+func imapReply(line string) (string, startTLSStep) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "* BAD Invalid command\r\n", startTLSContinue
+	}
+
+	tag, command := fields[0], strings.ToUpper(fields[1])
+	switch command {
+	case "CAPABILITY":
+		return "* CAPABILITY IMAP4rev1 STARTTLS LOGINDISABLED\r\n" + tag + " OK CAPABILITY completed\r\n", startTLSContinue
+	case "NOOP":
+		return tag + " OK NOOP completed\r\n", startTLSContinue
+	case "STARTTLS":
+		return tag + " OK Begin TLS negotiation now\r\n", startTLSUpgrade
+	case "LOGOUT":
+		return "* BYE traefik logging out\r\n" + tag + " OK LOGOUT completed\r\n", startTLSQuit
+	default:
+		return tag + " BAD STARTTLS required\r\n", startTLSContinue
+	}
+}
+
+// pop3Reply returns the reply to the POP3 command, and the step of the negotiation.
+func pop3Reply(line string) (string, startTLSStep) {
+	command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+	switch command {
+	case "CAPA":
+		return "+OK\r\nSTLS\r\n.\r\n", startTLSContinue
+	case "NOOP":
+		return "+OK\r\n", startTLSContinue
+	case "STLS":
+		return "+OK Begin TLS negotiation\r\n", startTLSUpgrade
+	case "QUIT":
+		return "+OK bye\r\n", startTLSQuit
+	default:
+		return "-ERR STLS required\r\n", startTLSContinue
+	}
+}
+
+// skipGreetingHandler returns the handler of the connections upgraded to TLS with STARTTLS,
+// which skips the greeting of the backend, already sent to the client by the router.
+func skipGreetingHandler(protocol string, next Handler) Handler {
+	return HandlerFunc(func(conn WriteCloser) {
+		next.ServeTCP(&greetingSkipperConn{WriteCloser: conn, protocol: protocol})
+	})
+}
+
+// greetingSkipperConn is a connection skipping the greeting of the backend written to it.
+type greetingSkipperConn struct {
+	WriteCloser
+	protocol string
+	greeting []byte
+	skipped  bool
+}
+
+func (c *greetingSkipperConn) Write(p []byte) (int, error) {
+	if c.skipped {
+		return c.WriteCloser.Write(p)
+	}
+
+	c.greeting = append(c.greeting, p...)
+
+	rest, ok := skipGreeting(c.protocol, c.greeting)
+	if !ok {
+		if len(c.greeting) > maxStartTLSCommands*maxStartTLSLineLength {
+			return 0, errors.New("backend greeting too long")
+		}
+		return len(p), nil
+	}
+
+	c.skipped = true
+	c.greeting = nil
+
+	if len(rest) > 0 {
+		if _, err := c.WriteCloser.Write(rest); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// skipGreeting returns the bytes following the greeting of the backend, and whether the greeting is complete.
+// The greeting of SMTP can span several lines, the last one having a space after the reply code.
+func skipGreeting(protocol string, data []byte) ([]byte, bool) {
+	for {
+		end := bytes.Index(data, []byte("\n"))
+		if end < 0 {
+			return nil, false
+		}
+
+		line := data[:end]
+		data = data[end+1:]
+
+		if protocol != StartTLSSMTP || len(line) < 4 || line[3] != '-' {
+			return data, true
+		}
+	}
+}
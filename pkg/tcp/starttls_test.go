@@ -0,0 +1,225 @@
+package tcp
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selfSignedTLSConfig returns a server TLS configuration with a self-signed certificate for the server name.
+func selfSignedTLSConfig(t *testing.T, serverName string) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: serverName},
+		DNSNames:     []string{serverName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+func TestRouter_StartTLS(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		protocol        string
+		backendGreeting string
+		commands        []string
+		expectedReplies []string
+	}{
+		{
+			desc:            "SMTP",
+			protocol:        StartTLSSMTP,
+			backendGreeting: "220-backend\r\n220 ESMTP ready\r\n",
+			commands:        []string{"EHLO client", "MAIL FROM:<foo@bar>", "STARTTLS"},
+			expectedReplies: []string{"250-traefik\r\n250 STARTTLS\r\n", "530 Must issue a STARTTLS command first\r\n", "220 Ready to start TLS\r\n"},
+		},
+		{
+			desc:            "IMAP",
+			protocol:        StartTLSIMAP,
+			backendGreeting: "* OK backend ready\r\n",
+			commands:        []string{"a1 CAPABILITY", "a2 LOGIN foo bar", "a3 STARTTLS"},
+			expectedReplies: []string{
+				"* CAPABILITY IMAP4rev1 STARTTLS LOGINDISABLED\r\na1 OK CAPABILITY completed\r\n",
+				"a2 BAD STARTTLS required\r\n",
+				"a3 OK Begin TLS negotiation now\r\n",
+			},
+		},
+		{
+			desc:            "POP3",
+			protocol:        StartTLSPOP3,
+			backendGreeting: "+OK backend ready\r\n",
+			commands:        []string{"CAPA", "USER foo", "STLS"},
+			expectedReplies: []string{"+OK\r\nSTLS\r\n.\r\n", "-ERR STLS required\r\n", "+OK Begin TLS negotiation\r\n"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backend := HandlerFunc(func(conn WriteCloser) {
+				_, _ = conn.Write([]byte(test.backendGreeting[:5]))
+				_, _ = conn.Write([]byte(test.backendGreeting[5:] + "hello\r\n"))
+				_ = conn.Close()
+			})
+
+			router := &Router{}
+			router.AddRouteTLS("foo.bar", backend, selfSignedTLSConfig(t, "foo.bar"))
+			router.ClientHello(ClientHelloOptions{Timeout: time.Second, MaxBytes: 16384, StartTLS: test.protocol})
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer func() { _ = listener.Close() }()
+
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				router.ServeTCP(conn.(*net.TCPConn))
+			}()
+
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+
+			err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+			require.NoError(t, err)
+
+			br := bufio.NewReader(conn)
+
+			greeting, err := br.ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, startTLSGreetings[test.protocol], greeting)
+
+			for i, command := range test.commands {
+				_, err = conn.Write([]byte(command + "\r\n"))
+				require.NoError(t, err)
+
+				reply := make([]byte, len(test.expectedReplies[i]))
+				_, err = io.ReadFull(br, reply)
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedReplies[i], string(reply))
+			}
+
+			client := tls.Client(conn, &tls.Config{ServerName: "foo.bar", InsecureSkipVerify: true})
+			response, err := ioutil.ReadAll(client)
+			require.NoError(t, err)
+			assert.Equal(t, "hello\r\n", string(response))
+		})
+	}
+}
+
+func TestRouter_StartTLS_passthrough(t *testing.T) {
+	router := &Router{}
+	router.AddRoute("foo.bar", writeHandler("route"))
+	router.ClientHello(ClientHelloOptions{Timeout: time.Second, MaxBytes: 16384, StartTLS: StartTLSPOP3})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		router.ServeTCP(conn.(*net.TCPConn))
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, err)
+
+	_, err = conn.Write(append([]byte("STLS\r\n"), clientHelloRecord(t, "foo.bar")...))
+	require.NoError(t, err)
+
+	response, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, startTLSGreetings[StartTLSPOP3]+"+OK Begin TLS negotiation\r\n", string(response))
+}
+
+func TestSkipGreeting(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		protocol     string
+		data         string
+		expected     string
+		expectedDone bool
+	}{
+		{
+			desc:         "SMTP greeting",
+			protocol:     StartTLSSMTP,
+			data:         "220 ready\r\nfoo",
+			expected:     "foo",
+			expectedDone: true,
+		},
+		{
+			desc:         "SMTP multi-line greeting",
+			protocol:     StartTLSSMTP,
+			data:         "220-foo\r\n220-bar\r\n220 ready\r\n",
+			expectedDone: true,
+		},
+		{
+			desc:     "incomplete SMTP multi-line greeting",
+			protocol: StartTLSSMTP,
+			data:     "220-foo\r\n220 rea",
+		},
+		{
+			desc:         "IMAP greeting",
+			protocol:     StartTLSIMAP,
+			data:         "* OK ready\r\n* BYE\r\n",
+			expected:     "* BYE\r\n",
+			expectedDone: true,
+		},
+		{
+			desc:     "incomplete POP3 greeting",
+			protocol: StartTLSPOP3,
+			data:     "+OK rea",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rest, done := skipGreeting(test.protocol, []byte(test.data))
+			assert.Equal(t, test.expectedDone, done)
+			assert.Equal(t, test.expected, string(rest))
+		})
+	}
+}
+
+func TestNegotiateStartTLS_tooManyCommands(t *testing.T) {
+	br := bufio.NewReader(strings.NewReader(strings.Repeat("NOOP\r\n", maxStartTLSCommands+1)))
+	err := negotiateStartTLS(StartTLSSMTP, br, ioutil.Discard)
+	assert.Error(t, err)
+}
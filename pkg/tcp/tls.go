@@ -2,15 +2,42 @@ package tcp
 
 import (
 	"crypto/tls"
+
+	"github.com/containous/traefik/v2/pkg/log"
 )
 
 // TLSHandler handles TLS connections
 type TLSHandler struct {
 	Next   Handler
 	Config *tls.Config
+	// OnHandshakeError, if not nil, makes the handshake completed before forwarding the connection,
+	// and is called with the error of each failed handshake, whose connection is closed.
+	OnHandshakeError func(conn *tls.Conn, err error)
+	// ClientJA3, if not empty, is the JA3 fingerprint of the ClientHello of the connection,
+	// which is then returned by ConnClientJA3 for its TLS connection, until closed.
+	ClientJA3 string
 }
 
 // ServeTCP terminates the TLS connection
 func (t *TLSHandler) ServeTCP(conn WriteCloser) {
-	t.Next.ServeTCP(tls.Server(conn, t.Config))
+	var tlsConn *tls.Conn
+	if t.ClientJA3 != "" {
+		wrapped := &ja3Conn{WriteCloser: conn, ja3: t.ClientJA3}
+		tlsConn = tls.Server(wrapped, t.Config)
+		wrapped.tlsConn = tlsConn
+		ja3Fingerprints.Store(tlsConn, t.ClientJA3)
+	} else {
+		tlsConn = tls.Server(conn, t.Config)
+	}
+
+	if t.OnHandshakeError != nil {
+		if err := tlsConn.Handshake(); err != nil {
+			log.WithoutContext().Debugf("TLS handshake error from %s: %v", conn.RemoteAddr(), err)
+			t.OnHandshakeError(tlsConn, err)
+			tlsConn.Close()
+			return
+		}
+	}
+
+	t.Next.ServeTCP(tlsConn)
 }
@@ -0,0 +1,90 @@
+package tcp
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_OnHandshakeError(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		clientMaxVersion uint16
+		expectedResponse string
+		expectedError    bool
+	}{
+		{
+			desc:             "successful handshake",
+			clientMaxVersion: tls.VersionTLS13,
+			expectedResponse: "hello",
+		},
+		{
+			desc:             "protocol mismatch",
+			clientMaxVersion: tls.VersionTLS12,
+			expectedError:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backend := HandlerFunc(func(conn WriteCloser) {
+				_, _ = conn.Write([]byte("hello"))
+				_ = conn.Close()
+			})
+
+			config := selfSignedTLSConfig(t, "foo.bar")
+			config.MinVersion = tls.VersionTLS13
+
+			handshakeErrors := make(chan error, 1)
+
+			router := &Router{}
+			router.AddRouteTLS("foo.bar", backend, config)
+			router.OnHandshakeError(func(serverName string, remoteAddr net.Addr, err error) {
+				assert.Equal(t, "foo.bar", serverName)
+				assert.NotNil(t, remoteAddr)
+				handshakeErrors <- err
+			})
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer func() { _ = listener.Close() }()
+
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				router.ServeTCP(conn.(*net.TCPConn))
+				close(handshakeErrors)
+			}()
+
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+
+			err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+			require.NoError(t, err)
+
+			client := tls.Client(conn, &tls.Config{ServerName: "foo.bar", InsecureSkipVerify: true, MaxVersion: test.clientMaxVersion})
+			response, err := ioutil.ReadAll(client)
+
+			if !test.expectedError {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedResponse, string(response))
+				assert.NoError(t, <-handshakeErrors)
+				return
+			}
+
+			assert.Error(t, err)
+			assert.Error(t, <-handshakeErrors)
+		})
+	}
+}
@@ -1,15 +1,18 @@
 package tls
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"sort"
 	"strings"
 
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/privsep"
 	"github.com/containous/traefik/v2/pkg/tls/certificate"
 	"github.com/containous/traefik/v2/pkg/tls/generate"
 )
@@ -50,7 +53,11 @@ var (
 // Certs and Key could be either a file path, or the file content itself
 type Certificate struct {
 	CertFile FileOrContent `json:"certFile,omitempty" toml:"certFile,omitempty" yaml:"certFile,omitempty"`
-	KeyFile  FileOrContent `json:"keyFile,omitempty" toml:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	KeyFile  FileOrContent `json:"keyFile,omitempty" toml:"keyFile,omitempty" yaml:"keyFile,omitempty" secret:"true"`
+	// KeyPassphrase defines the passphrase decrypting the KeyFile, when it is encrypted.
+	KeyPassphrase string `json:"keyPassphrase,omitempty" toml:"keyPassphrase,omitempty" yaml:"keyPassphrase,omitempty" secret:"true"`
+	// KeyPassphraseFile defines the file holding the passphrase decrypting the KeyFile, instead of the KeyPassphrase.
+	KeyPassphraseFile string `json:"keyPassphraseFile,omitempty" toml:"keyPassphraseFile,omitempty" yaml:"keyPassphraseFile,omitempty"`
 }
 
 // Certificates defines traefik certificates type
@@ -66,15 +73,14 @@ func (f FileOrContent) String() string {
 
 // IsPath returns true if the FileOrContent is a file path, otherwise returns false
 func (f FileOrContent) IsPath() bool {
-	_, err := os.Stat(f.String())
-	return err == nil
+	return privsep.FileExists(f.String())
 }
 
 func (f FileOrContent) Read() ([]byte, error) {
 	var content []byte
 	if f.IsPath() {
 		var err error
-		content, err = ioutil.ReadFile(f.String())
+		content, err = privsep.ReadFile(f.String())
 		if err != nil {
 			return nil, err
 		}
@@ -138,22 +144,94 @@ func (c *Certificates) isEmpty() bool {
 
 // AppendCertificate appends a Certificate to a certificates map keyed by entrypoint.
 func (c *Certificate) AppendCertificate(certs map[string]map[certificateKey]*tls.Certificate, ep string) error {
+	certContent, keyContent, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	certKey, tlsCert, err := parseCertificate(certContent, keyContent)
+	if err != nil {
+		return err
+	}
+
+	appendCertificate(certs, ep, certKey, tlsCert)
+
+	return nil
+}
+
+// read reads the contents of the certificate and of its key.
+func (c *Certificate) read() ([]byte, []byte, error) {
 	certContent, err := c.CertFile.Read()
 	if err != nil {
-		return fmt.Errorf("unable to read CertFile : %v", err)
+		return nil, nil, fmt.Errorf("unable to read CertFile : %v", err)
 	}
 
 	keyContent, err := c.KeyFile.Read()
 	if err != nil {
-		return fmt.Errorf("unable to read KeyFile : %v", err)
+		return nil, nil, fmt.Errorf("unable to read KeyFile : %v", err)
+	}
+
+	passphrase, err := c.keyPassphrase()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyContent, err = decryptKey(keyContent, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certContent, keyContent, nil
+}
+
+// keyPassphrase returns the passphrase decrypting the key, if any.
+func (c *Certificate) keyPassphrase() ([]byte, error) {
+	if c.KeyPassphraseFile == "" {
+		return []byte(c.KeyPassphrase), nil
 	}
+
+	if c.KeyPassphrase != "" {
+		return nil, errors.New("unable to read the key passphrase: both KeyPassphrase and KeyPassphraseFile are defined")
+	}
+
+	passphrase, err := privsep.ReadFile(c.KeyPassphraseFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read KeyPassphraseFile : %v", err)
+	}
+
+	// The passphrase files usually end with a line break.
+	return bytes.TrimRight(passphrase, "\r\n"), nil
+}
+
+// certificateFingerprint returns the fingerprint of the contents of a certificate and of its key.
+func certificateFingerprint(certContent, keyContent []byte) string {
+	hash := sha256.New()
+	_, _ = hash.Write(certContent)
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write(keyContent)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// parseCertificate parses a certificate and its key, and returns it along with the key of its domains.
+func parseCertificate(certContent, keyContent []byte) (certificateKey, *tls.Certificate, error) {
 	tlsCert, err := tls.X509KeyPair(certContent, keyContent)
 	if err != nil {
-		return fmt.Errorf("unable to generate TLS certificate : %v", err)
+		return certificateKey{}, nil, fmt.Errorf("unable to generate TLS certificate : %v", err)
 	}
 
 	parsedCert, _ := x509.ParseCertificate(tlsCert.Certificate[0])
 
+	certKey, err := getCertificateKey(parsedCert)
+	if err != nil {
+		return certificateKey{}, nil, err
+	}
+
+	return certKey, &tlsCert, nil
+}
+
+// getCertificateKey returns the key of the domains of a certificate.
+func getCertificateKey(parsedCert *x509.Certificate) (certificateKey, error) {
 	var SANs []string
 	if parsedCert.Subject.CommonName != "" {
 		SANs = append(SANs, strings.ToLower(parsedCert.Subject.CommonName))
@@ -179,13 +257,20 @@ func (c *Certificate) AppendCertificate(certs map[string]map[certificateKey]*tls
 	switch parsedCert.PublicKeyAlgorithm {
 	case x509.RSA:
 		certKey.certType = certificate.RSA
-	case x509.ECDSA,
-		x509.Ed25519:
+	case x509.ECDSA:
 		certKey.certType = certificate.EC
+	case x509.Ed25519:
+		certKey.certType = certificate.Ed25519
 	default:
-		return fmt.Errorf("Unsupported certificate public key algorithm %s", parsedCert.PublicKeyAlgorithm)
+		return certificateKey{}, fmt.Errorf("Unsupported certificate public key algorithm %s", parsedCert.PublicKeyAlgorithm)
 	}
 
+	return certKey, nil
+}
+
+// appendCertificate appends a parsed certificate to a certificates map keyed by entrypoint,
+// unless a certificate already exists for its domains.
+func appendCertificate(certs map[string]map[certificateKey]*tls.Certificate, ep string, certKey certificateKey, tlsCert *tls.Certificate) {
 	certExists := false
 	if certs[ep] == nil {
 		certs[ep] = make(map[certificateKey]*tls.Certificate)
@@ -201,10 +286,8 @@ func (c *Certificate) AppendCertificate(certs map[string]map[certificateKey]*tls
 		log.Debugf("Skipping addition of certificate for domain(s) %q, to EntryPoint %s, as it already exists for this Entrypoint.", certKey, ep)
 	} else {
 		log.Debugf("Adding certificate for domain(s) %s", certKey)
-		certs[ep][certKey] = &tlsCert
+		certs[ep][certKey] = tlsCert
 	}
-
-	return err
 }
 
 // GetTruncatedCertificateName truncates the certificate name
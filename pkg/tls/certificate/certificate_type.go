@@ -13,12 +13,14 @@ type CertificateType byte
 const (
 	RSA CertificateType = iota
 	EC
+	Ed25519
 )
 
 var (
 	certTypeToStringMap = map[CertificateType]string{
-		RSA: "RSA",
-		EC:  "EC",
+		RSA:     "RSA",
+		EC:      "EC",
+		Ed25519: "Ed25519",
 	}
 )
 
@@ -28,8 +30,10 @@ func (value CertificateType) String() string {
 
 func GetCertificateType(cert *tls.Certificate) (CertificateType, error) {
 	switch cert.PrivateKey.(type) {
-	case *ecdsa.PrivateKey, *ed25519.PrivateKey:
+	case *ecdsa.PrivateKey:
 		return EC, nil
+	case ed25519.PrivateKey, *ed25519.PrivateKey:
+		return Ed25519, nil
 	case *rsa.PrivateKey:
 		return RSA, nil
 	}
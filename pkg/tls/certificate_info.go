@@ -0,0 +1,216 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tls/certificate"
+	"github.com/containous/traefik/v2/pkg/tls/generate"
+)
+
+// CertificateInfo describes a certificate of a TLS store.
+type CertificateInfo struct {
+	Store string
+	// SANs holds the comma separated common name and subject alternative names of the certificate.
+	SANs     string
+	Serial   string
+	NotAfter time.Time
+	// SCT describes the Signed Certificate Timestamps of the certificate, when they are checked.
+	SCT *SCTStatus
+}
+
+// CertificatesEvent describes the certificates added to, and removed from, the stores by an update.
+// A certificate replaced by its renewal is removed, and its renewal is added.
+type CertificatesEvent struct {
+	Added   []CertificateInfo
+	Removed []CertificateInfo
+}
+
+// ServedCertificate describes the certificate a store serves to a server name.
+type ServedCertificate struct {
+	CertificateInfo
+	// MatchedDomain is the domain of the certificate matching the server name, empty for a default certificate.
+	MatchedDomain string
+	Default       bool
+}
+
+// CertificatesInfo describes the certificates of all the stores, sorted by store and SANs,
+// except the default certificates generated by Traefik.
+func (m *Manager) CertificatesInfo() []CertificateInfo {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]CertificateInfo{}, m.certsInfo...)
+}
+
+// ServedCertificate describes the certificate of the store served to the clients requesting the server name,
+// and preferring the certificate type, as chosen by the match strategy of the store.
+// The certificates of the certificate resolvers are not considered.
+// It returns false if the store does not exist, or if it has no certificate for the certificate type.
+func (m *Manager) ServedCertificate(storeName, serverName string, certType certificate.CertificateType) (ServedCertificate, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	store, ok := m.stores[storeName]
+	if !ok {
+		return ServedCertificate{}, false
+	}
+
+	served := ServedCertificate{CertificateInfo: CertificateInfo{Store: storeName}}
+
+	serverName = strings.ToLower(strings.TrimSpace(serverName))
+
+	var cert *tls.Certificate
+	if match := store.bestMatch(serverName, certType); match != nil {
+		cert = match.cert
+		served.MatchedDomain = match.domain
+	} else {
+		cert = store.defaultCertificateFor(serverName, certType)
+		served.Default = true
+	}
+
+	if cert == nil {
+		return ServedCertificate{}, false
+	}
+
+	desc, ok := m.certsDescriptions[cert]
+	if !ok || desc == nil {
+		desc = describeCertificate(cert, m.sctChecks)
+	}
+
+	if desc != nil {
+		served.SANs = desc.sans
+		served.Serial = desc.serial
+		served.NotAfter = desc.notAfter
+		served.SCT = desc.sct
+	}
+
+	return served, true
+}
+
+// certificateDescription describes a certificate, whatever its stores.
+type certificateDescription struct {
+	sans     string
+	serial   string
+	notAfter time.Time
+	sct      *SCTStatus
+	// generated is whether the certificate is a default certificate generated by Traefik.
+	generated bool
+}
+
+// describeCertificates describes the certificates of all the stores, sorted by store, SANs and serial number.
+// The descriptions of the certificates which were already in the stores are reused,
+// and the new certificates not complying with the certificate transparency policy are logged, when the SCTs are checked.
+func (m *Manager) describeCertificates(ctx context.Context) []CertificateInfo {
+	descriptions := make(map[*tls.Certificate]*certificateDescription, len(m.certsDescriptions))
+
+	var infos []CertificateInfo
+	for storeName, store := range m.stores {
+		seen := map[string]bool{}
+		for _, cert := range storeCertificates(store) {
+			desc, ok := descriptions[cert]
+			if !ok {
+				if desc, ok = m.certsDescriptions[cert]; !ok {
+					desc = describeCertificate(cert, m.sctChecks)
+					if desc != nil && desc.sct != nil && !desc.sct.Compliant() {
+						log.FromContext(ctx).Warnf("Certificate %s (serial %s) does not comply with the certificate transparency policy of the browsers: %s", desc.sans, desc.serial, desc.sct.Error)
+					}
+				}
+				descriptions[cert] = desc
+			}
+
+			// The default certificate of the store can also be one of its dynamic certificates.
+			if desc == nil || desc.generated || seen[desc.serial] {
+				continue
+			}
+			seen[desc.serial] = true
+
+			infos = append(infos, CertificateInfo{
+				Store:    storeName,
+				SANs:     desc.sans,
+				Serial:   desc.serial,
+				NotAfter: desc.notAfter,
+				SCT:      desc.sct,
+			})
+		}
+	}
+
+	m.certsDescriptions = descriptions
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Store != infos[j].Store {
+			return infos[i].Store < infos[j].Store
+		}
+		if infos[i].SANs != infos[j].SANs {
+			return infos[i].SANs < infos[j].SANs
+		}
+		return infos[i].Serial < infos[j].Serial
+	})
+
+	return infos
+}
+
+// describeCertificate describes the certificate, along with its SCTs if withSCTs is set,
+// or returns nil if it can not be parsed.
+func describeCertificate(cert *tls.Certificate, withSCTs bool) *certificateDescription {
+	// The leaf is parsed again, as getCertificateKey sorts its DNS names in place.
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil
+	}
+
+	certKey, err := getCertificateKey(leaf)
+	if err != nil {
+		return nil
+	}
+
+	desc := &certificateDescription{
+		sans:      certKey.hostname,
+		serial:    leaf.SerialNumber.Text(16),
+		notAfter:  leaf.NotAfter,
+		generated: leaf.Subject.CommonName == generate.DefaultDomain,
+	}
+
+	if withSCTs && !desc.generated {
+		desc.sct = checkSCTs(cert, leaf, time.Now())
+	}
+
+	return desc
+}
+
+// diffCertificatesInfo returns the certificates added and removed between the previous and the current descriptions.
+func diffCertificatesInfo(previous, current []CertificateInfo) CertificatesEvent {
+	key := func(info CertificateInfo) string {
+		return info.Store + "|" + info.Serial
+	}
+
+	previousKeys := make(map[string]bool, len(previous))
+	for _, info := range previous {
+		previousKeys[key(info)] = true
+	}
+
+	currentKeys := make(map[string]bool, len(current))
+	for _, info := range current {
+		currentKeys[key(info)] = true
+	}
+
+	var event CertificatesEvent
+	for _, info := range current {
+		if !previousKeys[key(info)] {
+			event.Added = append(event.Added, info)
+		}
+	}
+
+	for _, info := range previous {
+		if !currentKeys[key(info)] {
+			event.Removed = append(event.Removed, info)
+		}
+	}
+
+	return event
+}
@@ -0,0 +1,104 @@
+package tls
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/tls/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_CertificatesInfo(t *testing.T) {
+	localhost := &Certificate{CertFile: localhostCert, KeyFile: localhostKey}
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), map[string]Store{
+		"default": {DefaultCertificate: localhost},
+	}, nil, []*CertAndStores{
+		{Certificate: *localhost, Stores: []string{"default", "other"}},
+	})
+
+	// The certificate which is both the default and a dynamic certificate of a store is described once,
+	// and the default certificate generated for the other store is not described.
+	expected := CertificateInfo{
+		SANs:     "example.com,127.0.0.1,::1",
+		Serial:   "30830284c2c6ad1f90be642fa70014eb",
+		NotAfter: time.Date(2084, time.January, 29, 16, 0, 0, 0, time.UTC),
+	}
+
+	infos := tlsManager.CertificatesInfo()
+	require.Len(t, infos, 2)
+
+	for i, store := range []string{"default", "other"} {
+		expected.Store = store
+		assert.Equal(t, expected, infos[i])
+	}
+}
+
+func TestManager_AddCertificatesListener(t *testing.T) {
+	localhost := Certificate{CertFile: localhostCert, KeyFile: localhostKey}
+	snitest := Certificate{
+		CertFile: "../../integration/fixtures/https/snitest.com.cert",
+		KeyFile:  "../../integration/fixtures/https/snitest.com.key",
+	}
+
+	tlsManager := NewManager()
+
+	var events []CertificatesEvent
+	tlsManager.AddCertificatesListener(func(event CertificatesEvent) {
+		// The update is applied when the listeners are notified.
+		assert.Subset(t, tlsManager.CertificatesInfo(), event.Added)
+		events = append(events, event)
+	})
+
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, []*CertAndStores{{Certificate: localhost}})
+
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Added, 1)
+	assert.Equal(t, "default", events[0].Added[0].Store)
+	assert.Equal(t, "example.com,127.0.0.1,::1", events[0].Added[0].SANs)
+	assert.Empty(t, events[0].Removed)
+
+	// The listeners are not notified of the updates which do not change the certificates.
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, []*CertAndStores{{Certificate: localhost}})
+	require.Len(t, events, 1)
+
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, []*CertAndStores{{Certificate: snitest}})
+
+	require.Len(t, events, 2)
+	require.Len(t, events[1].Added, 1)
+	assert.Equal(t, "snitest.com", events[1].Added[0].SANs)
+	assert.Equal(t, events[0].Added, events[1].Removed)
+	assert.Equal(t, events[1].Added, tlsManager.CertificatesInfo())
+}
+
+func TestManager_ServedCertificate(t *testing.T) {
+	localhost := Certificate{CertFile: localhostCert, KeyFile: localhostKey}
+	snitest := Certificate{
+		CertFile: "../../integration/fixtures/https/snitest.com.cert",
+		KeyFile:  "../../integration/fixtures/https/snitest.com.key",
+	}
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), map[string]Store{
+		"default": {DefaultCertificate: &localhost, MatchStrategy: MatchStrategyExactFirst},
+	}, nil, []*CertAndStores{{Certificate: snitest}})
+
+	served, ok := tlsManager.ServedCertificate("default", "SNItest.com", certificate.RSA)
+	require.True(t, ok)
+	assert.Equal(t, "default", served.Store)
+	assert.Equal(t, "snitest.com", served.SANs)
+	assert.Equal(t, "snitest.com", served.MatchedDomain)
+	assert.False(t, served.Default)
+
+	served, ok = tlsManager.ServedCertificate("default", "foo.bar", certificate.RSA)
+	require.True(t, ok)
+	assert.Equal(t, "example.com,127.0.0.1,::1", served.SANs)
+	assert.Empty(t, served.MatchedDomain)
+	assert.True(t, served.Default)
+
+	_, ok = tlsManager.ServedCertificate("foo", "snitest.com", certificate.RSA)
+	assert.False(t, ok)
+}
@@ -19,6 +19,16 @@ const (
 	certTypeDelimiter = "/"
 )
 
+// Strategies choosing the certificate of a store served to a server name, among the certificates matching it.
+// The certificate expiring last is chosen among the equally ranked ones.
+const (
+	// MatchStrategyLongestMatch ranks the certificates by the specificity of their domain matching the server name:
+	// an exact domain first, then the wildcard domains with the fewest wildcard labels.
+	MatchStrategyLongestMatch = "longestMatch"
+	// MatchStrategyExactFirst ranks the certificates of an exact domain first, and all the wildcard domains equally.
+	MatchStrategyExactFirst = "exactFirst"
+)
+
 // certificateKey contains information by which certificates are tracked in certificate cache
 type certificateKey struct {
 	hostname string
@@ -34,6 +44,20 @@ type CertificateStore struct {
 	DynamicCerts        *safe.Safe
 	DefaultCertificates []*tls.Certificate
 	CertCache           *cache.Cache
+	// MatchStrategy chooses among the certificates matching a server name, MatchStrategyLongestMatch if empty.
+	MatchStrategy string
+
+	// defaultCA, if not nil, mints the default certificates served to the server names without certificate.
+	defaultCA *mintingCA
+}
+
+// certificateMatch is a certificate of a store matching a server name.
+type certificateMatch struct {
+	cert *tls.Certificate
+	key  certificateKey
+	// domain is the domain of the certificate matching the server name.
+	domain   string
+	notAfter time.Time
 }
 
 // NewCertificateStore create a store for dynamic and static certificates
@@ -86,14 +110,45 @@ func (c CertificateStore) GetAllDomains() []string {
 
 	// Get dynamic certificates
 	if c.DynamicCerts != nil && c.DynamicCerts.Get() != nil {
-		for domains := range c.DynamicCerts.Get().(map[string]*tls.Certificate) {
-			allCerts = append(allCerts, domains)
+		for key := range c.DynamicCerts.Get().(map[certificateKey]*tls.Certificate) {
+			allCerts = append(allCerts, key.hostname)
 		}
 	}
 	return allCerts
 }
 
-func getCertTypeForClientHello(hello *tls.ClientHelloInfo) (retval certificate.CertificateType) {
+// getCertTypeForClientHello returns the certificate type preferred by the client:
+// Ed25519 if the client supports the EC certificates and the Ed25519 signatures, then EC, then RSA.
+func getCertTypeForClientHello(hello *tls.ClientHelloInfo) certificate.CertificateType {
+	certType := getKeyExchangeCertType(hello)
+	if certType != certificate.EC {
+		return certType
+	}
+
+	for _, scheme := range hello.SignatureSchemes {
+		if scheme == tls.Ed25519 {
+			return certificate.Ed25519
+		}
+	}
+
+	return certType
+}
+
+// certTypePreferences returns the certificate types served to a client preferring the certificate type, in order of preference.
+// The clients supporting Ed25519 support EC as well, and all the clients support RSA.
+func certTypePreferences(preferredType certificate.CertificateType) []certificate.CertificateType {
+	switch preferredType {
+	case certificate.Ed25519:
+		return []certificate.CertificateType{certificate.Ed25519, certificate.EC, certificate.RSA}
+	case certificate.EC:
+		return []certificate.CertificateType{certificate.EC, certificate.RSA}
+	default:
+		return []certificate.CertificateType{certificate.RSA}
+	}
+}
+
+// getKeyExchangeCertType returns EC if the client supports the EC certificates, and RSA otherwise.
+func getKeyExchangeCertType(hello *tls.ClientHelloInfo) (retval certificate.CertificateType) {
 	retval = certificate.RSA
 
 	// The "signature_algorithms" extension, if present, limits the key exchange
@@ -149,6 +204,11 @@ func getCertTypeForClientHello(hello *tls.ClientHelloInfo) (retval certificate.C
 	return
 }
 
+// ResolveCertificate returns the best match certificate of the store, making the store a CertificateResolver.
+func (c CertificateStore) ResolveCertificate(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.GetBestCertificate(clientHello), nil
+}
+
 // GetBestCertificate returns the best match certificate, and caches the response
 func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *tls.Certificate {
 	domainToCheck := strings.ToLower(strings.TrimSpace(clientHello.ServerName))
@@ -161,7 +221,7 @@ func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *
 		domainToCheck = strings.TrimSpace(host)
 	}
 
-	// Append compatibility with EC to key before checking cache
+	// Append compatibility with EC, or Ed25519, to key before checking cache
 	preferredCertType := getCertTypeForClientHello(clientHello)
 	keyToCheck := domainToCheck
 	if preferredCertType != certificate.RSA {
@@ -171,13 +231,25 @@ func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *
 		return cert.(*tls.Certificate)
 	}
 
+	match := c.bestMatch(domainToCheck, preferredCertType)
+	if match == nil {
+		return nil
+	}
+
+	// cache best match
+	c.CertCache.SetDefault(keyToCheck, match.cert)
+	return match.cert
+}
+
+// bestMatch returns the certificate matching the domain chosen by the match strategy of the store,
+// among the certificates of the preferred type, then among the certificates of the types also supported by the client.
+func (c CertificateStore) bestMatch(domainToCheck string, preferredCertType certificate.CertificateType) *certificateMatch {
 	// Build list of certificate types allowed for this client in order of preference
-	// (EC would come before RSA for clients compatible with it)
-	certTypePreferences := []certificate.CertificateType{certificate.RSA}
-	matchedCerts := map[certificate.CertificateType]map[string]*tls.Certificate{certificate.RSA: {}}
-	if preferredCertType != certificate.RSA {
-		matchedCerts[preferredCertType] = map[string]*tls.Certificate{}
-		certTypePreferences = append([]certificate.CertificateType{preferredCertType}, certTypePreferences...)
+	// (Ed25519 would come before EC, and EC before RSA, for clients compatible with them)
+	certTypes := certTypePreferences(preferredCertType)
+	matchedCerts := make(map[certificate.CertificateType][]certificateMatch, len(certTypes))
+	for _, certType := range certTypes {
+		matchedCerts[certType] = nil
 	}
 
 	if c.DynamicCerts != nil && c.DynamicCerts.Get() != nil {
@@ -192,25 +264,99 @@ func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *
 			// Requested domain found in certificate?
 			for _, certDomain := range strings.Split(domains, ",") {
 				if MatchDomain(domainToCheck, certDomain) {
-					matchedCerts[key.certType][certDomain] = cert
+					matchedCerts[key.certType] = append(matchedCerts[key.certType], certificateMatch{
+						cert:     cert,
+						key:      key,
+						domain:   certDomain,
+						notAfter: certificateNotAfter(cert),
+					})
 				}
 			}
 		}
 	}
 
-	for _, currentCertType := range certTypePreferences {
-		matchedCertsForCurrentCertType := matchedCerts[currentCertType]
-		if len(matchedCertsForCurrentCertType) > 0 {
-			// sort map by keys
-			keys := []string{}
-			for key := range matchedCertsForCurrentCertType {
-				keys = append(keys, key)
-			}
-			sort.Strings(keys)
+	for _, currentCertType := range certTypes {
+		matches := matchedCerts[currentCertType]
+		if len(matches) == 0 {
+			continue
+		}
+
+		sort.Slice(matches, func(i, j int) bool {
+			return c.preferredMatch(matches[i], matches[j])
+		})
+
+		return &matches[0]
+	}
+
+	return nil
+}
+
+// preferredMatch returns whether the match a is preferred over the match b, according to the match strategy of the store.
+func (c CertificateStore) preferredMatch(a, b certificateMatch) bool {
+	rankA, rankB := matchRank(c.MatchStrategy, a.domain), matchRank(c.MatchStrategy, b.domain)
+	if rankA != rankB {
+		return rankA > rankB
+	}
+
+	if !a.notAfter.Equal(b.notAfter) {
+		return a.notAfter.After(b.notAfter)
+	}
+
+	if a.domain != b.domain {
+		return a.domain > b.domain
+	}
+
+	return a.key.hostname > b.key.hostname
+}
+
+// matchRank returns the rank of the domain of a certificate matching a server name, the highest rank being preferred.
+func matchRank(strategy, certDomain string) int {
+	wildcards := strings.Count(certDomain, "*")
+
+	if strategy == MatchStrategyExactFirst && wildcards > 0 {
+		return -1
+	}
+
+	return -wildcards
+}
+
+// certificateNotAfter returns the expiration date of the certificate, or the zero time if it can not be parsed.
+func certificateNotAfter(cert *tls.Certificate) time.Time {
+	if cert.Leaf != nil {
+		return cert.Leaf.NotAfter
+	}
+
+	if len(cert.Certificate) == 0 {
+		return time.Time{}
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return time.Time{}
+	}
+
+	return leaf.NotAfter
+}
+
+// defaultCertificate returns the default certificate of the store served to the clients preferring the certificate type,
+// among the default certificates of the preferred type, then of the types also supported by the clients.
+func (c CertificateStore) defaultCertificate(preferredType certificate.CertificateType) *tls.Certificate {
+	certs := make(map[certificate.CertificateType]*tls.Certificate)
+	for _, cert := range c.DefaultCertificates {
+		certType, err := certificate.GetCertificateType(cert)
+		if err != nil {
+			log.WithoutContext().Debug("Ignoring certificate of which the type can not be detected")
+			continue
+		}
+
+		if _, ok := certs[certType]; !ok {
+			certs[certType] = cert
+		}
+	}
 
-			// cache best match
-			c.CertCache.SetDefault(keyToCheck, matchedCertsForCurrentCertType[keys[len(keys)-1]])
-			return matchedCertsForCurrentCertType[keys[len(keys)-1]]
+	for _, certType := range certTypePreferences(preferredType) {
+		if cert, ok := certs[certType]; ok {
+			return cert
 		}
 	}
 
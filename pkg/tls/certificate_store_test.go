@@ -1,10 +1,8 @@
 package tls
 
 import (
-	"crypto/ecdsa"
-	"crypto/ed25519"
-	"crypto/rsa"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"strings"
 	"testing"
@@ -12,6 +10,7 @@ import (
 
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/tls/certificate"
+	"github.com/containous/traefik/v2/pkg/tls/generate"
 	"github.com/patrickmn/go-cache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -115,12 +114,8 @@ func TestGetBestCertificate(t *testing.T) {
 					hostname: strings.Split(strings.ToLower(test.dynamicCert), "/")[0],
 				}
 
-				switch cert.PrivateKey.(type) {
-				case *rsa.PrivateKey:
-					key.certType = certificate.RSA
-				case *ecdsa.PrivateKey, *ed25519.PrivateKey:
-					key.certType = certificate.EC
-				}
+				key.certType, err = certificate.GetCertificateType(cert)
+				require.NoError(t, err)
 
 				dynamicMap[key] = cert
 			}
@@ -150,6 +145,194 @@ func TestGetBestCertificate(t *testing.T) {
 	}
 }
 
+func TestGetCertTypeForClientHello(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		cipherSuites     []uint16
+		signatureSchemes []tls.SignatureScheme
+		supportedCurves  []tls.CurveID
+		expected         certificate.CertificateType
+	}{
+		{
+			desc:         "RSA",
+			cipherSuites: rsaCipherSuites,
+			expected:     certificate.RSA,
+		},
+		{
+			desc:             "EC",
+			cipherSuites:     ecCipherSuites,
+			signatureSchemes: ecSignatureSchemes,
+			supportedCurves:  ecSupportedCurves,
+			expected:         certificate.EC,
+		},
+		{
+			desc:             "Ed25519",
+			cipherSuites:     ecCipherSuites,
+			signatureSchemes: append([]tls.SignatureScheme{tls.Ed25519}, ecSignatureSchemes...),
+			supportedCurves:  ecSupportedCurves,
+			expected:         certificate.Ed25519,
+		},
+		{
+			desc:             "Ed25519 signatures without EC cipher suites",
+			cipherSuites:     rsaCipherSuites,
+			signatureSchemes: []tls.SignatureScheme{tls.Ed25519, tls.PSSWithSHA256},
+			supportedCurves:  ecSupportedCurves,
+			expected:         certificate.RSA,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			clientHello := &tls.ClientHelloInfo{
+				CipherSuites:     test.cipherSuites,
+				SupportedCurves:  test.supportedCurves,
+				SignatureSchemes: test.signatureSchemes,
+			}
+
+			assert.Equal(t, test.expected, getCertTypeForClientHello(clientHello))
+		})
+	}
+}
+
+func TestCertificateStore_defaultCertificate(t *testing.T) {
+	certs := make(map[certificate.CertificateType]*tls.Certificate)
+	for _, certType := range []certificate.CertificateType{certificate.RSA, certificate.EC, certificate.Ed25519} {
+		cert, err := generate.DefaultCertificate(certType)
+		require.NoError(t, err)
+
+		detected, err := certificate.GetCertificateType(cert)
+		require.NoError(t, err)
+		assert.Equal(t, certType, detected)
+
+		require.NoError(t, handshake(t, cert))
+
+		certs[certType] = cert
+	}
+
+	testCases := []struct {
+		desc          string
+		defaultCerts  []certificate.CertificateType
+		preferredType certificate.CertificateType
+		expected      certificate.CertificateType
+	}{
+		{
+			desc:          "Ed25519 preferred",
+			defaultCerts:  []certificate.CertificateType{certificate.RSA, certificate.EC, certificate.Ed25519},
+			preferredType: certificate.Ed25519,
+			expected:      certificate.Ed25519,
+		},
+		{
+			desc:          "Ed25519 preferred, without Ed25519 certificate",
+			defaultCerts:  []certificate.CertificateType{certificate.RSA, certificate.EC},
+			preferredType: certificate.Ed25519,
+			expected:      certificate.EC,
+		},
+		{
+			desc:          "EC preferred",
+			defaultCerts:  []certificate.CertificateType{certificate.Ed25519, certificate.RSA},
+			preferredType: certificate.EC,
+			expected:      certificate.RSA,
+		},
+		{
+			desc:          "RSA preferred",
+			defaultCerts:  []certificate.CertificateType{certificate.Ed25519, certificate.EC, certificate.RSA},
+			preferredType: certificate.RSA,
+			expected:      certificate.RSA,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			store := &CertificateStore{}
+			for _, certType := range test.defaultCerts {
+				store.DefaultCertificates = append(store.DefaultCertificates, certs[certType])
+			}
+
+			assert.Same(t, certs[test.expected], store.defaultCertificate(test.preferredType))
+		})
+	}
+}
+
+func TestGetBestCertificate_matchStrategy(t *testing.T) {
+	now := time.Now()
+
+	certs := map[string]*tls.Certificate{}
+	dynamicMap := map[certificateKey]*tls.Certificate{}
+	for name, hostname := range map[string]string{
+		"exact":           "foo.snitest.com",
+		"wildcard":        "*.snitest.com",
+		"renewedWildcard": "*.snitest.com,snitest.org",
+		"wildcards":       "*.*.com",
+	} {
+		notAfter := map[string]time.Time{
+			"exact":           now.Add(time.Hour),
+			"wildcard":        now.Add(2 * time.Hour),
+			"renewedWildcard": now.Add(3 * time.Hour),
+			"wildcards":       now.Add(4 * time.Hour),
+		}[name]
+
+		cert := &tls.Certificate{Leaf: &x509.Certificate{NotAfter: notAfter}}
+		certs[name] = cert
+		dynamicMap[certificateKey{hostname: hostname, certType: certificate.RSA}] = cert
+	}
+
+	testCases := []struct {
+		desc          string
+		strategy      string
+		domainToCheck string
+		expectedCert  string
+	}{
+		{
+			desc:          "longest match, exact domain",
+			domainToCheck: "foo.snitest.com",
+			expectedCert:  "exact",
+		},
+		{
+			desc:          "longest match, most specific wildcard expiring last",
+			domainToCheck: "bar.snitest.com",
+			expectedCert:  "renewedWildcard",
+		},
+		{
+			desc:          "exact first, exact domain",
+			strategy:      MatchStrategyExactFirst,
+			domainToCheck: "foo.snitest.com",
+			expectedCert:  "exact",
+		},
+		{
+			desc:          "exact first, wildcard expiring last",
+			strategy:      MatchStrategyExactFirst,
+			domainToCheck: "bar.snitest.com",
+			expectedCert:  "wildcards",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			store := &CertificateStore{
+				DynamicCerts:  safe.New(dynamicMap),
+				CertCache:     cache.New(1*time.Hour, 10*time.Minute),
+				MatchStrategy: test.strategy,
+			}
+
+			clientHello := &tls.ClientHelloInfo{
+				ServerName:   test.domainToCheck,
+				CipherSuites: rsaCipherSuites,
+			}
+
+			assert.Same(t, certs[test.expectedCert], store.GetBestCertificate(clientHello))
+		})
+	}
+}
+
 func loadTestCert(certName string, uppercase bool) (*tls.Certificate, error) {
 	replacement := "wildcard"
 	if uppercase {
@@ -0,0 +1,148 @@
+package tls
+
+import (
+	"crypto/tls"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Responses of the denied handshakes.
+const (
+	clientHelloDenyClose            = "close"
+	clientHelloDenyUnrecognizedName = "unrecognizedName"
+)
+
+// unrecognizedNameAlert is the TLS record of a fatal unrecognized_name alert,
+// with the record version of the records sent before the version is negotiated.
+var unrecognizedNameAlert = []byte{21, 0x03, 0x01, 0, 2, 2, 112}
+
+// ja3Conn is implemented by the connections whose JA3 fingerprint of the ClientHello is known,
+// such as the connections terminated by the TCP routers.
+type ja3Conn interface {
+	ClientJA3() string
+}
+
+// clientHelloDeniedError is the error of the handshakes whose ClientHello is denied.
+type clientHelloDeniedError struct {
+	rule string
+}
+
+func (e *clientHelloDeniedError) Error() string {
+	return fmt.Sprintf("tls: ClientHello denied: %s", e.rule)
+}
+
+// clientHelloDenier rejects the handshakes of the ClientHellos matching any of its rules.
+type clientHelloDenier struct {
+	serverNames              []string
+	noServerName             bool
+	insecureCipherSuitesOnly bool
+	ja3                      map[string]struct{}
+	unrecognizedName         bool
+}
+
+// newClientHelloDenier builds the denier of the rules, or returns nil without rules.
+func newClientHelloDenier(deny *ClientHelloDeny) (*clientHelloDenier, error) {
+	if deny == nil {
+		return nil, nil
+	}
+
+	d := &clientHelloDenier{
+		noServerName:             deny.NoServerName,
+		insecureCipherSuitesOnly: deny.InsecureCipherSuitesOnly,
+	}
+
+	for _, serverName := range deny.ServerNames {
+		pattern := strings.ToLower(serverName)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid clientHelloDeny server name pattern %q: %w", serverName, err)
+		}
+		d.serverNames = append(d.serverNames, pattern)
+	}
+
+	if len(deny.JA3) > 0 {
+		d.ja3 = make(map[string]struct{}, len(deny.JA3))
+		for _, fingerprint := range deny.JA3 {
+			d.ja3[strings.ToLower(fingerprint)] = struct{}{}
+		}
+	}
+
+	switch deny.Response {
+	case "", clientHelloDenyClose:
+	case clientHelloDenyUnrecognizedName:
+		d.unrecognizedName = true
+	default:
+		return nil, fmt.Errorf("invalid clientHelloDeny response: %q", deny.Response)
+	}
+
+	return d, nil
+}
+
+// check rejects the handshake if the ClientHello matches any of the rules,
+// closing its connection, after sending an unrecognized_name alert if enabled.
+func (d *clientHelloDenier) check(clientHello *tls.ClientHelloInfo) error {
+	if d == nil {
+		return nil
+	}
+
+	rule := d.match(clientHello)
+	if rule == "" {
+		return nil
+	}
+
+	if clientHello.Conn != nil {
+		if d.unrecognizedName {
+			_, _ = clientHello.Conn.Write(unrecognizedNameAlert)
+		}
+		_ = clientHello.Conn.Close()
+	}
+
+	return &clientHelloDeniedError{rule: rule}
+}
+
+// match returns the first rule matching the ClientHello, or the empty string.
+func (d *clientHelloDenier) match(clientHello *tls.ClientHelloInfo) string {
+	serverName := strings.ToLower(clientHello.ServerName)
+	if serverName == "" && d.noServerName {
+		return "no server name"
+	}
+
+	for _, pattern := range d.serverNames {
+		if ok, _ := path.Match(pattern, serverName); ok && serverName != "" {
+			return fmt.Sprintf("server name %q", clientHello.ServerName)
+		}
+	}
+
+	if d.insecureCipherSuitesOnly && !anySecureCipherSuite(clientHello.CipherSuites) {
+		return "insecure cipher suites only"
+	}
+
+	if len(d.ja3) > 0 {
+		if conn, ok := clientHello.Conn.(ja3Conn); ok {
+			if _, denied := d.ja3[conn.ClientJA3()]; denied {
+				return fmt.Sprintf("JA3 fingerprint %s", conn.ClientJA3())
+			}
+		}
+	}
+
+	return ""
+}
+
+// secureCipherSuites are the secure cipher suites of crypto/tls.
+var secureCipherSuites = func() map[uint16]struct{} {
+	cipherSuites := make(map[uint16]struct{})
+	for _, cipherSuite := range tls.CipherSuites() {
+		cipherSuites[cipherSuite.ID] = struct{}{}
+	}
+	return cipherSuites
+}()
+
+// anySecureCipherSuite reports whether any of the cipher suites is one of the secure cipher suites of crypto/tls.
+func anySecureCipherSuite(cipherSuites []uint16) bool {
+	for _, cipherSuite := range cipherSuites {
+		if _, ok := secureCipherSuites[cipherSuite]; ok {
+			return true
+		}
+	}
+	return false
+}
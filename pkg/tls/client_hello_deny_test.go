@@ -0,0 +1,169 @@
+package tls
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJA3Conn is a connection whose JA3 fingerprint is known, recording the bytes written, and whether it is closed.
+type fakeJA3Conn struct {
+	net.Conn
+	ja3     string
+	written bytes.Buffer
+	closed  bool
+}
+
+func (c *fakeJA3Conn) ClientJA3() string { return c.ja3 }
+
+func (c *fakeJA3Conn) Write(p []byte) (int, error) { return c.written.Write(p) }
+
+func (c *fakeJA3Conn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestManager_Get_clientHelloDeny(t *testing.T) {
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{
+		"default": {
+			ClientHelloDeny: &ClientHelloDeny{
+				ServerNames:              []string{"*.internal", "Admin.foo.bar"},
+				NoServerName:             true,
+				InsecureCipherSuitesOnly: true,
+				JA3:                      []string{"E7D705A3286E19EA42F587B344EE6865"},
+			},
+		},
+		"alert": {
+			ClientHelloDeny: &ClientHelloDeny{
+				NoServerName: true,
+				Response:     "unrecognizedName",
+			},
+		},
+	}, nil)
+
+	secure := []uint16{tls.TLS_RSA_WITH_RC4_128_SHA, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+
+	testCases := []struct {
+		desc            string
+		options         string
+		serverName      string
+		cipherSuites    []uint16
+		ja3             string
+		expectedDenied  bool
+		expectedWritten []byte
+	}{
+		{
+			desc:         "allowed",
+			options:      "default",
+			serverName:   "foo.bar",
+			cipherSuites: secure,
+			ja3:          "b32309a26951912be7dba376398abc3b",
+		},
+		{
+			desc:           "no server name",
+			options:        "default",
+			cipherSuites:   secure,
+			expectedDenied: true,
+		},
+		{
+			desc:           "denied server name pattern",
+			options:        "default",
+			serverName:     "db.internal",
+			cipherSuites:   secure,
+			expectedDenied: true,
+		},
+		{
+			desc:           "denied server name",
+			options:        "default",
+			serverName:     "admin.Foo.bar",
+			cipherSuites:   secure,
+			expectedDenied: true,
+		},
+		{
+			desc:           "insecure cipher suites only",
+			options:        "default",
+			serverName:     "foo.bar",
+			cipherSuites:   []uint16{tls.TLS_RSA_WITH_RC4_128_SHA, tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA},
+			expectedDenied: true,
+		},
+		{
+			desc:           "denied JA3 fingerprint",
+			options:        "default",
+			serverName:     "foo.bar",
+			cipherSuites:   secure,
+			ja3:            "e7d705a3286e19ea42f587b344ee6865",
+			expectedDenied: true,
+		},
+		{
+			desc:            "unrecognized_name alert",
+			options:         "alert",
+			expectedDenied:  true,
+			expectedWritten: unrecognizedNameAlert,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tlsConfig, err := tlsManager.Get("default", test.options)
+			require.NoError(t, err)
+
+			conn := &fakeJA3Conn{ja3: test.ja3}
+			_, err = tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{
+				ServerName:   test.serverName,
+				CipherSuites: test.cipherSuites,
+				Conn:         conn,
+			})
+
+			if !test.expectedDenied {
+				require.NoError(t, err)
+				assert.False(t, conn.closed)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, HandshakeErrorDenied, handshakeErrorReason(err))
+			assert.True(t, conn.closed)
+			assert.Equal(t, test.expectedWritten, conn.written.Bytes())
+		})
+	}
+}
+
+func TestManager_Get_invalidClientHelloDeny(t *testing.T) {
+	testCases := []struct {
+		desc string
+		deny ClientHelloDeny
+	}{
+		{
+			desc: "invalid server name pattern",
+			deny: ClientHelloDeny{ServerNames: []string{"[foo"}},
+		},
+		{
+			desc: "unknown response",
+			deny: ClientHelloDeny{Response: "reset"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tlsManager := NewManager()
+			tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{
+				"default": {ClientHelloDeny: &test.deny},
+			}, nil)
+
+			_, err := tlsManager.Get("default", "default")
+			assert.Error(t, err)
+		})
+	}
+}
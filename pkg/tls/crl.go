@@ -0,0 +1,322 @@
+package tls
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/privsep"
+)
+
+const (
+	defaultCRLRefreshInterval = time.Hour
+	crlDownloadTimeout        = 10 * time.Second
+)
+
+// crlPool holds the certificate revocation lists of the client authentication, keyed by their source,
+// so that a list shared by several TLS options is only loaded once.
+type crlPool struct {
+	// storage is the directory where the downloaded lists are saved, to be used after a restart.
+	storage string
+	client  *http.Client
+
+	mu    sync.Mutex
+	lists map[string]*crlList
+}
+
+func newCRLPool() *crlPool {
+	return &crlPool{
+		client: &http.Client{Timeout: crlDownloadTimeout},
+		lists:  make(map[string]*crlList),
+	}
+}
+
+func (p *crlPool) setStorage(storage string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.storage = storage
+}
+
+// get returns the list of the source, which is loaded when it is not in the pool yet.
+func (p *crlPool) get(source string, refreshInterval time.Duration) *crlList {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if refreshInterval <= 0 {
+		refreshInterval = defaultCRLRefreshInterval
+	}
+
+	if list, ok := p.lists[source]; ok {
+		list.setRefreshInterval(refreshInterval)
+		return list
+	}
+
+	list := &crlList{
+		source:          source,
+		client:          p.client,
+		refreshInterval: refreshInterval,
+		verified:        make(map[string]error),
+	}
+	if p.storage != "" && isCRLURL(source) {
+		sum := sha256.Sum256([]byte(source))
+		list.storage = filepath.Join(p.storage, hex.EncodeToString(sum[:])+".crl")
+	}
+
+	list.init()
+	p.lists[source] = list
+
+	return list
+}
+
+// verifyPeerCertificate returns the function rejecting the client certificates revoked by one of the lists.
+func (p *crlPool) verifyPeerCertificate(sources []string, refreshInterval time.Duration) func([][]byte, [][]*x509.Certificate) error {
+	var lists []*crlList
+	for _, source := range sources {
+		lists = append(lists, p.get(source, refreshInterval))
+	}
+
+	return func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+		for _, list := range lists {
+			list.refreshIfNeeded()
+		}
+
+		for _, chain := range verifiedChains {
+			// The root certificate of the chain, which is a trusted CA, is not checked.
+			for i := 0; i < len(chain)-1; i++ {
+				for _, list := range lists {
+					revoked, err := list.isRevoked(chain[i], chain[i+1])
+					if err != nil {
+						return fmt.Errorf("unable to check the revocation of the client certificate: %w", err)
+					}
+					if revoked {
+						return fmt.Errorf("the client certificate %q (serial %s) is revoked", chain[i].Subject, chain[i].SerialNumber)
+					}
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+// crlList is a certificate revocation list, read from a file, or downloaded from a URL,
+// which is loaded again when its refresh interval has elapsed.
+type crlList struct {
+	source  string
+	storage string
+	client  *http.Client
+
+	mu              sync.RWMutex
+	refreshInterval time.Duration
+	crl             *pkix.CertificateList
+	revoked         map[string]struct{}
+	// verified holds the result of the verification of the signature of the list, keyed by the issuer certificate.
+	verified   map[string]error
+	loadedAt   time.Time
+	refreshing int32
+}
+
+func (l *crlList) setRefreshInterval(refreshInterval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refreshInterval = refreshInterval
+}
+
+// init loads the list.
+// A downloaded list is loaded from its saved copy when there is one, and downloaded again in the background.
+func (l *crlList) init() {
+	logger := log.WithoutContext().WithField("crl", l.source)
+
+	if l.storage != "" {
+		data, err := ioutil.ReadFile(l.storage)
+		if err == nil {
+			err = l.set(data)
+		}
+
+		if err == nil {
+			logger.Debug("Certificate revocation list loaded from its saved copy")
+			l.startRefresh()
+			return
+		}
+
+		if !os.IsNotExist(err) {
+			logger.Errorf("Unable to load the saved copy of the certificate revocation list: %v", err)
+		}
+	}
+
+	if err := l.refresh(); err != nil {
+		logger.Errorf("Unable to load the certificate revocation list: %v", err)
+	}
+}
+
+// refreshIfNeeded loads the list again in the background, when its refresh interval has elapsed.
+func (l *crlList) refreshIfNeeded() {
+	l.mu.RLock()
+	elapsed := time.Since(l.loadedAt) >= l.refreshInterval
+	l.mu.RUnlock()
+
+	if elapsed {
+		l.startRefresh()
+	}
+}
+
+func (l *crlList) startRefresh() {
+	if !atomic.CompareAndSwapInt32(&l.refreshing, 0, 1) {
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&l.refreshing, 0)
+
+		if err := l.refresh(); err != nil {
+			log.WithoutContext().WithField("crl", l.source).
+				Errorf("Unable to refresh the certificate revocation list, keeping the current one: %v", err)
+		}
+	}()
+}
+
+// refresh loads the list again, and keeps the current one when it cannot be loaded.
+func (l *crlList) refresh() error {
+	// The load time is updated even when the list cannot be loaded, so that it is only attempted once per refresh interval.
+	l.mu.Lock()
+	l.loadedAt = time.Now()
+	l.mu.Unlock()
+
+	data, err := l.read()
+	if err != nil {
+		return err
+	}
+
+	if err := l.set(data); err != nil {
+		return err
+	}
+
+	if l.storage != "" {
+		if err := saveCRL(l.storage, data); err != nil {
+			log.WithoutContext().WithField("crl", l.source).
+				Errorf("Unable to save the certificate revocation list: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (l *crlList) read() ([]byte, error) {
+	if !isCRLURL(l.source) {
+		return privsep.ReadFile(l.source)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), crlDownloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodGet, l.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := l.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// set replaces the list by the parsed data.
+func (l *crlList) set(data []byte) error {
+	crl, err := x509.ParseCRL(data)
+	if err != nil {
+		return fmt.Errorf("invalid certificate revocation list: %w", err)
+	}
+
+	revoked := make(map[string]struct{}, len(crl.TBSCertList.RevokedCertificates))
+	for _, cert := range crl.TBSCertList.RevokedCertificates {
+		revoked[cert.SerialNumber.String()] = struct{}{}
+	}
+
+	if crl.HasExpired(time.Now()) {
+		log.WithoutContext().WithField("crl", l.source).Warn("The certificate revocation list has expired, its issuer did not publish a new one")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.crl = crl
+	l.revoked = revoked
+	l.verified = make(map[string]error)
+
+	return nil
+}
+
+// isRevoked returns whether the certificate, issued by the issuer, is revoked by the list.
+// The lists of the other issuers do not revoke the certificate,
+// and an error is returned when the list is not loaded, or when its signature is not valid.
+func (l *crlList) isRevoked(cert, issuer *x509.Certificate) (bool, error) {
+	l.mu.RLock()
+	crl, revoked := l.crl, l.revoked
+	verified, ok := l.verified[string(issuer.Raw)]
+	l.mu.RUnlock()
+
+	if crl == nil {
+		return false, fmt.Errorf("the certificate revocation list %s is not loaded", l.source)
+	}
+
+	if crl.TBSCertList.Issuer.String() != issuer.Subject.ToRDNSequence().String() {
+		return false, nil
+	}
+
+	if !ok {
+		verified = issuer.CheckCRLSignature(crl)
+
+		l.mu.Lock()
+		if l.crl == crl {
+			l.verified[string(issuer.Raw)] = verified
+		}
+		l.mu.Unlock()
+	}
+
+	if verified != nil {
+		return false, fmt.Errorf("invalid signature of the certificate revocation list %s: %w", l.source, verified)
+	}
+
+	_, ok = revoked[cert.SerialNumber.String()]
+	return ok, nil
+}
+
+func isCRLURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// saveCRL writes the data in the file, through a temporary file,
+// so that the saved copy is never partially written.
+func saveCRL(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filename)
+}
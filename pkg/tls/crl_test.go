@@ -0,0 +1,254 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) pem() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}))
+}
+
+func (ca *testCA) issue(t *testing.T, serial int64, ocspServers ...string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		OCSPServer:   ocspServers,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func (ca *testCA) crl(t *testing.T, serials ...int64) []byte {
+	t.Helper()
+
+	var revoked []pkix.RevokedCertificate
+	for _, serial := range serials {
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+
+	crl, err := ca.cert.CreateCRL(rand.Reader, ca.key, revoked, time.Now(), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl})
+}
+
+func TestCRLPool_verifyPeerCertificate(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	otherCA := newTestCA(t, "other")
+
+	dir, err := ioutil.TempDir("", "traefik-crl")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	crlFile := filepath.Join(dir, "ca.crl")
+	require.NoError(t, ioutil.WriteFile(crlFile, ca.crl(t, 2), 0600))
+
+	verify := newCRLPool().verifyPeerCertificate([]string{crlFile}, 0)
+
+	testCases := []struct {
+		desc     string
+		chain    []*x509.Certificate
+		expected bool
+	}{
+		{
+			desc:     "not revoked",
+			chain:    []*x509.Certificate{ca.issue(t, 3), ca.cert},
+			expected: true,
+		},
+		{
+			desc:  "revoked",
+			chain: []*x509.Certificate{ca.issue(t, 2), ca.cert},
+		},
+		{
+			desc:     "issued by another CA",
+			chain:    []*x509.Certificate{otherCA.issue(t, 2), otherCA.cert},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := verify(nil, [][]*x509.Certificate{test.chain})
+			if test.expected {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCRLPool_invalidSignature(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	// The forged CA has the same name, but another key.
+	forgedCA := newTestCA(t, "ca")
+
+	dir, err := ioutil.TempDir("", "traefik-crl")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	crlFile := filepath.Join(dir, "ca.crl")
+	require.NoError(t, ioutil.WriteFile(crlFile, forgedCA.crl(t, 3), 0600))
+
+	verify := newCRLPool().verifyPeerCertificate([]string{crlFile}, 0)
+
+	err = verify(nil, [][]*x509.Certificate{{ca.issue(t, 2), ca.cert}})
+	assert.Error(t, err)
+}
+
+func TestCRLPool_notLoaded(t *testing.T) {
+	ca := newTestCA(t, "ca")
+
+	verify := newCRLPool().verifyPeerCertificate([]string{"/does/not/exist.crl"}, 0)
+
+	err := verify(nil, [][]*x509.Certificate{{ca.issue(t, 2), ca.cert}})
+	assert.Error(t, err)
+}
+
+func TestCRLPool_storage(t *testing.T) {
+	ca := newTestCA(t, "ca")
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(ca.crl(t, 2))
+	}))
+
+	dir, err := ioutil.TempDir("", "traefik-crl")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	pool := newCRLPool()
+	pool.setStorage(dir)
+
+	verify := pool.verifyPeerCertificate([]string{server.URL}, 0)
+	assert.Error(t, verify(nil, [][]*x509.Certificate{{ca.issue(t, 2), ca.cert}}))
+
+	// The list is loaded from its saved copy after a restart, when it cannot be downloaded.
+	server.Close()
+
+	pool = newCRLPool()
+	pool.setStorage(dir)
+
+	verify = pool.verifyPeerCertificate([]string{server.URL}, 0)
+	assert.Error(t, verify(nil, [][]*x509.Certificate{{ca.issue(t, 2), ca.cert}}))
+	assert.NoError(t, verify(nil, [][]*x509.Certificate{{ca.issue(t, 3), ca.cert}}))
+}
+
+func TestCRLList_refresh(t *testing.T) {
+	ca := newTestCA(t, "ca")
+
+	dir, err := ioutil.TempDir("", "traefik-crl")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	crlFile := filepath.Join(dir, "ca.crl")
+	require.NoError(t, ioutil.WriteFile(crlFile, ca.crl(t), 0600))
+
+	verify := newCRLPool().verifyPeerCertificate([]string{crlFile}, 10*time.Millisecond)
+
+	cert := ca.issue(t, 2)
+	require.NoError(t, verify(nil, [][]*x509.Certificate{{cert, ca.cert}}))
+
+	require.NoError(t, ioutil.WriteFile(crlFile, ca.crl(t, 2), 0600))
+
+	assert.Eventually(t, func() bool {
+		return verify(nil, [][]*x509.Certificate{{cert, ca.cert}}) != nil
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestBuildTLSConfig_CRLs(t *testing.T) {
+	ca := newTestCA(t, "ca")
+
+	dir, err := ioutil.TempDir("", "traefik-crl")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	crlFile := filepath.Join(dir, "ca.crl")
+	require.NoError(t, ioutil.WriteFile(crlFile, ca.crl(t, 2), 0600))
+
+	_, err = buildTLSConfig(Options{ClientAuth: ClientAuth{CRLs: []string{crlFile}}}, newCRLPool(), newOCSPCache(), nil)
+	assert.Error(t, err)
+
+	// The client certificates are not verified, so there are no verified chains to check against the CRLs.
+	_, err = buildTLSConfig(Options{
+		ClientAuth: ClientAuth{
+			CAFiles:        []FileOrContent{FileOrContent(ca.pem())},
+			ClientAuthType: "RequireAnyClientCert",
+			CRLs:           []string{crlFile},
+		},
+	}, newCRLPool(), newOCSPCache(), nil)
+	assert.Error(t, err)
+
+	conf, err := buildTLSConfig(Options{
+		ClientAuth: ClientAuth{
+			CAFiles: []FileOrContent{FileOrContent(ca.pem())},
+			CRLs:    []string{crlFile},
+		},
+	}, newCRLPool(), newOCSPCache(), nil)
+	require.NoError(t, err)
+	require.NotNil(t, conf.VerifyPeerCertificate)
+
+	assert.Error(t, conf.VerifyPeerCertificate(nil, [][]*x509.Certificate{{ca.issue(t, 2), ca.cert}}))
+}
@@ -0,0 +1,288 @@
+package tls
+
+import (
+	"container/list"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/tls/certificate"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultCAValidity = 24 * time.Hour
+
+	// maxMintedCertificates is the maximum number of minted certificates cached by a store,
+	// beyond which the least recently used ones are evicted.
+	maxMintedCertificates = 10000
+	// mintingRate and mintingBurst limit the certificates minted by a store,
+	// beyond which the default certificate of the store is served.
+	mintingRate  = 10
+	mintingBurst = 100
+	// mintedCertificateBackdate is the duration the minted certificates are valid before being minted,
+	// for the clients whose clock is late.
+	mintedCertificateBackdate = time.Hour
+)
+
+var errTooManyMintedCertificates = errors.New("too many minted certificates")
+
+// mintingCA mints the default certificates of a store for the server names without certificate,
+// and caches them for half of their validity, evicting the least recently used ones.
+type mintingCA struct {
+	cert     *x509.Certificate
+	certDER  []byte
+	key      crypto.Signer
+	validity time.Duration
+
+	// keys are the private keys of the minted certificates, by certificate type,
+	// generated once for the store so that minting a certificate only requires signing it.
+	keys    map[certificate.CertificateType]crypto.Signer
+	limiter *rate.Limiter
+
+	mu    sync.Mutex
+	certs map[string]*list.Element
+	lru   *list.List // *mintedCertificate, most recently used first
+}
+
+type mintedCertificate struct {
+	key     string
+	cert    *tls.Certificate
+	expires time.Time
+}
+
+func newMintingCA(config DefaultCA) (*mintingCA, error) {
+	certContent, keyContent, err := (&Certificate{CertFile: config.CertFile, KeyFile: config.KeyFile}).read()
+	if err != nil {
+		return nil, err
+	}
+
+	caPair, err := tls.X509KeyPair(certContent, keyContent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default CA: %w", err)
+	}
+
+	caCert, err := x509.ParseCertificate(caPair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid default CA: %w", err)
+	}
+
+	if !caCert.IsCA || (caCert.KeyUsage != 0 && caCert.KeyUsage&x509.KeyUsageCertSign == 0) {
+		return nil, errors.New("invalid default CA: the certificate is not allowed to sign certificates")
+	}
+
+	caKey, ok := caPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("invalid default CA: unsupported private key")
+	}
+
+	validity := time.Duration(config.Validity)
+	if validity == 0 {
+		validity = defaultCAValidity
+	}
+	if validity < 0 {
+		return nil, fmt.Errorf("invalid default CA validity: %s", validity)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mintingCA{
+		cert:     caCert,
+		certDER:  caPair.Certificate[0],
+		key:      caKey,
+		validity: validity,
+		keys: map[certificate.CertificateType]crypto.Signer{
+			certificate.EC:  ecKey,
+			certificate.RSA: rsaKey,
+		},
+		limiter: rate.NewLimiter(mintingRate, mintingBurst),
+		certs:   make(map[string]*list.Element),
+		lru:     list.New(),
+	}, nil
+}
+
+// certificate returns the certificate of the server name and the certificate type, minted if it is not cached,
+// or an error if too many certificates are minted.
+func (ca *mintingCA) certificate(serverName string, certType certificate.CertificateType) (*tls.Certificate, error) {
+	// The minted certificates are EC or RSA ones.
+	var key crypto.Signer
+	for _, preferredType := range certTypePreferences(certType) {
+		if k, ok := ca.keys[preferredType]; ok {
+			key, certType = k, preferredType
+			break
+		}
+	}
+
+	cacheKey := serverName + certTypeDelimiter + certType.String()
+	if cert := ca.cached(cacheKey); cert != nil {
+		return cert, nil
+	}
+
+	if !ca.limiter.Allow() {
+		return nil, errTooManyMintedCertificates
+	}
+
+	cert, err := ca.mint(serverName, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ca.add(cacheKey, cert)
+
+	return cert, nil
+}
+
+// cached returns the cached certificate of the key, or nil if it is not cached or expired.
+func (ca *mintingCA) cached(key string) *tls.Certificate {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	elt, ok := ca.certs[key]
+	if !ok {
+		return nil
+	}
+
+	minted := elt.Value.(*mintedCertificate)
+	if !time.Now().Before(minted.expires) {
+		ca.lru.Remove(elt)
+		delete(ca.certs, key)
+		return nil
+	}
+
+	ca.lru.MoveToFront(elt)
+
+	return minted.cert
+}
+
+// add caches the certificate of the key, evicting the least recently used certificate if the cache is full.
+func (ca *mintingCA) add(key string, cert *tls.Certificate) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	minted := &mintedCertificate{key: key, cert: cert, expires: time.Now().Add(ca.validity / 2)}
+
+	if elt, ok := ca.certs[key]; ok {
+		elt.Value = minted
+		ca.lru.MoveToFront(elt)
+		return
+	}
+
+	ca.certs[key] = ca.lru.PushFront(minted)
+
+	if ca.lru.Len() > maxMintedCertificates {
+		oldest := ca.lru.Back()
+		ca.lru.Remove(oldest)
+		delete(ca.certs, oldest.Value.(*mintedCertificate).key)
+	}
+}
+
+// mint mints the certificate of the server name, valid until the end of the validity, or the expiration of the CA.
+func (ca *mintingCA) mint(serverName string, key crypto.Signer) (*tls.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	notAfter := now.Add(ca.validity)
+	if notAfter.After(ca.cert.NotAfter) {
+		notAfter = ca.cert.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: serverName},
+		NotBefore:             now.Add(-mintedCertificateBackdate),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	if ip := net.ParseIP(serverName); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{serverName}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der, ca.certDER},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// defaultCertificateFor returns the default certificate of the store served to the server name,
+// minted by the default CA of the store if any, and if the server name is valid.
+func (c CertificateStore) defaultCertificateFor(serverName string, preferredType certificate.CertificateType) *tls.Certificate {
+	if c.defaultCA == nil || !mintableServerName(serverName) {
+		return c.defaultCertificate(preferredType)
+	}
+
+	cert, err := c.defaultCA.certificate(serverName, preferredType)
+	if errors.Is(err, errTooManyMintedCertificates) {
+		log.WithoutContext().Debugf("Unable to mint the default certificate of %q: %v", serverName, err)
+		return c.defaultCertificate(preferredType)
+	}
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to mint the default certificate of %q: %v", serverName, err)
+		return c.defaultCertificate(preferredType)
+	}
+
+	return cert
+}
+
+// mintableServerName returns whether a certificate can be minted for the server name:
+// an IP address, or a domain name without wildcard.
+func mintableServerName(serverName string) bool {
+	if serverName == "" || len(serverName) > 253 || strings.ContainsAny(serverName, "* /\\") {
+		return false
+	}
+
+	if net.ParseIP(serverName) != nil {
+		return true
+	}
+
+	for _, label := range strings.Split(serverName, ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,182 @@
+package tls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/tls/certificate"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// defaultCAConfig returns the configuration of a default CA of the certificate and its key.
+func defaultCAConfig(t *testing.T, certDER []byte, key *ecdsa.PrivateKey, validity time.Duration) DefaultCA {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return DefaultCA{
+		CertFile: FileOrContent(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})),
+		KeyFile:  FileOrContent(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+		Validity: types.Duration(validity),
+	}
+}
+
+func TestManager_defaultCA(t *testing.T) {
+	ca, caKey := newSPIFFECA(t)
+	defaultCA := defaultCAConfig(t, ca.Raw, caKey, 10*time.Minute)
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), map[string]Store{
+		"default": {DefaultCA: &defaultCA},
+	}, map[string]Options{"default": {}}, nil)
+
+	tlsConfig, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	for _, serverName := range []string{"foo.bar", "Foo.Bar"} {
+		serverConn, clientConn := net.Pipe()
+
+		go func() {
+			_ = tls.Server(serverConn, tlsConfig).Handshake()
+			_ = serverConn.Close()
+		}()
+
+		client := tls.Client(clientConn, &tls.Config{ServerName: serverName, RootCAs: roots})
+		require.NoError(t, client.Handshake(), serverName)
+
+		leaf := client.ConnectionState().PeerCertificates[0]
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), leaf.NotAfter, time.Minute)
+
+		_ = clientConn.Close()
+	}
+
+	served, ok := tlsManager.ServedCertificate("default", "foo.bar", certificate.EC)
+	require.True(t, ok)
+	assert.Equal(t, "foo.bar", served.SANs)
+	assert.True(t, served.Default)
+
+	cached, ok := tlsManager.ServedCertificate("default", "foo.bar", certificate.EC)
+	require.True(t, ok)
+	assert.Equal(t, served.Serial, cached.Serial)
+
+	served, ok = tlsManager.ServedCertificate("default", "*.foo.bar", certificate.EC)
+	require.True(t, ok)
+	assert.Contains(t, served.SANs, "traefik default cert")
+}
+
+func TestMintingCA_cache(t *testing.T) {
+	ca, caKey := newSPIFFECA(t)
+
+	mintingCA, err := newMintingCA(defaultCAConfig(t, ca.Raw, caKey, time.Hour))
+	require.NoError(t, err)
+
+	first, err := mintingCA.certificate("foo.bar", certificate.EC)
+	require.NoError(t, err)
+
+	// The least recently used certificates are evicted once the cache is full.
+	for i := 0; i < maxMintedCertificates; i++ {
+		if i == maxMintedCertificates/2 {
+			cached, err := mintingCA.certificate("foo.bar", certificate.EC)
+			require.NoError(t, err)
+			assert.Same(t, first, cached)
+		}
+
+		mintingCA.add(fmt.Sprintf("%d.foo.bar", i), first)
+	}
+
+	assert.Equal(t, maxMintedCertificates, mintingCA.lru.Len())
+	assert.NotNil(t, mintingCA.cached("foo.bar"+certTypeDelimiter+certificate.EC.String()))
+	assert.Nil(t, mintingCA.cached("0.foo.bar"))
+
+	// The certificates are minted again once they expire.
+	mintingCA.certs["foo.bar"+certTypeDelimiter+certificate.EC.String()].Value.(*mintedCertificate).expires = time.Now()
+
+	minted, err := mintingCA.certificate("foo.bar", certificate.EC)
+	require.NoError(t, err)
+	assert.NotSame(t, first, minted)
+}
+
+func TestMintingCA_rateLimit(t *testing.T) {
+	ca, caKey := newSPIFFECA(t)
+
+	mintingCA, err := newMintingCA(defaultCAConfig(t, ca.Raw, caKey, time.Hour))
+	require.NoError(t, err)
+
+	mintingCA.limiter = rate.NewLimiter(rate.Every(time.Hour), 1)
+
+	_, err = mintingCA.certificate("foo.bar", certificate.EC)
+	require.NoError(t, err)
+
+	_, err = mintingCA.certificate("bar.foo", certificate.EC)
+	assert.Error(t, err)
+
+	// The cached certificates are still served.
+	_, err = mintingCA.certificate("foo.bar", certificate.EC)
+	assert.NoError(t, err)
+}
+
+func TestNewMintingCA_invalid(t *testing.T) {
+	ca, caKey := newSPIFFECA(t)
+	leaf := newClientSVID(t, "spiffe://example.org/foo", ca, caKey)
+
+	testCases := []struct {
+		desc   string
+		config DefaultCA
+	}{
+		{
+			desc:   "not a CA",
+			config: defaultCAConfig(t, leaf.Certificate[0], leaf.PrivateKey.(*ecdsa.PrivateKey), time.Hour),
+		},
+		{
+			desc:   "key not matching the certificate",
+			config: DefaultCA{CertFile: defaultCAConfig(t, ca.Raw, caKey, time.Hour).CertFile, KeyFile: localhostKey},
+		},
+		{
+			desc:   "negative validity",
+			config: defaultCAConfig(t, ca.Raw, caKey, -time.Hour),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newMintingCA(test.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMintableServerName(t *testing.T) {
+	testCases := []struct {
+		serverName string
+		expected   bool
+	}{
+		{serverName: "foo.bar", expected: true},
+		{serverName: "127.0.0.1", expected: true},
+		{serverName: "::1", expected: true},
+		{serverName: ""},
+		{serverName: "*.foo.bar"},
+		{serverName: "foo..bar"},
+		{serverName: "foo bar"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, mintableServerName(test.serverName), test.serverName)
+	}
+}
@@ -65,6 +65,17 @@ func KeyPair(domain string, expiration time.Time, certType certificate.Certifica
 		}
 		keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecdsaBytes})
 		privKey = ecdsaPrivKey
+	case certificate.Ed25519:
+		_, ed25519PrivKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		ed25519Bytes, err := x509.MarshalPKCS8PrivateKey(ed25519PrivKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ed25519Bytes})
+		privKey = ed25519PrivKey
 	case certificate.RSA:
 		rsaPrivKey, err := rsa.GenerateKey(rand.Reader, 2048)
 		if err != nil {
@@ -120,9 +131,10 @@ func derCert(privKey crypto.PrivateKey, expiration time.Time, domain string) ([]
 	switch v := privKey.(type) {
 	case *ecdsa.PrivateKey:
 		pubKey = &v.PublicKey
+	case ed25519.PrivateKey:
+		pubKey = v.Public()
 	case *ed25519.PrivateKey:
-		edPubKey := v.Public()
-		pubKey = &edPubKey
+		pubKey = v.Public()
 	case *rsa.PrivateKey:
 		pubKey = &v.PublicKey
 	default:
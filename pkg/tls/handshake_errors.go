@@ -0,0 +1,208 @@
+package tls
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reasons of the TLS handshake failures.
+const (
+	// HandshakeErrorNoCertificate is the reason of the handshakes without any certificate for the server name, with SniStrict.
+	HandshakeErrorNoCertificate = "no_certificate"
+	// HandshakeErrorClientCertificate is the reason of the handshakes whose client certificate is missing or rejected.
+	HandshakeErrorClientCertificate = "client_certificate"
+	// HandshakeErrorDenied is the reason of the handshakes whose ClientHello is denied by the clientHelloDeny rules.
+	HandshakeErrorDenied = "denied"
+	// HandshakeErrorProtocol is the reason of the handshakes without any TLS version, cipher suite, or curve supported by both sides.
+	HandshakeErrorProtocol = "protocol"
+	// HandshakeErrorOther is the reason of the other handshake failures.
+	HandshakeErrorOther = "other"
+)
+
+// maxHandshakeErrorSamples is the number of the most recent failures sampled by reason.
+const maxHandshakeErrorSamples = 10
+
+// clientCertificateErrors are the prefixes of the errors of crypto/tls rejecting the client certificate.
+var clientCertificateErrors = []string{
+	"tls: client didn't provide a certificate",
+	"tls: failed to parse client certificate",
+	"tls: failed to verify client's certificate",
+	"tls: client's certificate contains an unsupported public key",
+	"tls: client certificate used with invalid signature algorithm",
+	"tls: invalid signature by the client certificate",
+}
+
+// protocolErrors are the prefixes of the errors of crypto/tls failing to negotiate the protocol with the client.
+var protocolErrors = []string{
+	"tls: client offered only unsupported versions",
+	"tls: no cipher suite supported by both client and server",
+	"tls: no ECDHE curve supported by both client and server",
+	"tls: client does not support uncompressed connections",
+	"tls: unsupported SSLv2 handshake received",
+}
+
+// HandshakeErrorSample is a sampled TLS handshake failure.
+type HandshakeErrorSample struct {
+	Time       time.Time
+	ServerName string
+	RemoteAddr string
+	Error      string
+}
+
+// HandshakeErrorsInfo describes the TLS handshake failures of a reason.
+type HandshakeErrorsInfo struct {
+	Reason string
+	Count  uint64
+	// Samples are the most recent failures, from the oldest to the newest.
+	Samples []HandshakeErrorSample
+}
+
+// strictSNIError is the error of the handshakes without any certificate for the server name, with SniStrict.
+type strictSNIError struct {
+	domain string
+}
+
+func (e *strictSNIError) Error() string {
+	return fmt.Sprintf("strict SNI enabled - No certificate found for domain: %q, closing connection", e.domain)
+}
+
+// clientCertificateError is the error of the verifications of the client certificate, added to the ones of crypto/tls.
+type clientCertificateError struct {
+	err error
+}
+
+func (e *clientCertificateError) Error() string {
+	return e.err.Error()
+}
+
+func (e *clientCertificateError) Unwrap() error {
+	return e.err
+}
+
+// handshakeErrors counts, and samples, the TLS handshake failures by reason.
+type handshakeErrors struct {
+	lock      sync.Mutex
+	reasons   map[string]*HandshakeErrorsInfo
+	listeners []func(reason string)
+}
+
+func newHandshakeErrors() *handshakeErrors {
+	return &handshakeErrors{reasons: map[string]*HandshakeErrorsInfo{}}
+}
+
+func (h *handshakeErrors) addListener(listener func(reason string)) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.listeners = append(h.listeners, listener)
+}
+
+// record counts, and samples, the failure, and notifies the listeners of its reason.
+func (h *handshakeErrors) record(sample HandshakeErrorSample, err error) {
+	reason := handshakeErrorReason(err)
+
+	h.lock.Lock()
+	info, ok := h.reasons[reason]
+	if !ok {
+		info = &HandshakeErrorsInfo{Reason: reason}
+		h.reasons[reason] = info
+	}
+
+	info.Count++
+	if len(info.Samples) == maxHandshakeErrorSamples {
+		info.Samples = append(info.Samples[:0], info.Samples[1:]...)
+	}
+	info.Samples = append(info.Samples, sample)
+
+	listeners := h.listeners
+	h.lock.Unlock()
+
+	for _, listener := range listeners {
+		listener(reason)
+	}
+}
+
+// infos returns the failures by reason, sorted by reason.
+func (h *handshakeErrors) infos() []HandshakeErrorsInfo {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	infos := make([]HandshakeErrorsInfo, 0, len(h.reasons))
+	for _, info := range h.reasons {
+		infos = append(infos, HandshakeErrorsInfo{
+			Reason:  info.Reason,
+			Count:   info.Count,
+			Samples: append([]HandshakeErrorSample{}, info.Samples...),
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Reason < infos[j].Reason
+	})
+
+	return infos
+}
+
+// handshakeErrorReason returns the reason of the TLS handshake failure.
+func handshakeErrorReason(err error) string {
+	var sniErr *strictSNIError
+	if errors.As(err, &sniErr) {
+		return HandshakeErrorNoCertificate
+	}
+
+	var deniedErr *clientHelloDeniedError
+	if errors.As(err, &deniedErr) {
+		return HandshakeErrorDenied
+	}
+
+	var certErr *clientCertificateError
+	if errors.As(err, &certErr) || hasAnyPrefix(err.Error(), clientCertificateErrors) {
+		return HandshakeErrorClientCertificate
+	}
+
+	if hasAnyPrefix(err.Error(), protocolErrors) {
+		return HandshakeErrorProtocol
+	}
+
+	return HandshakeErrorOther
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportHandshakeError records the failure of the TLS handshake of the connection from the remote address,
+// counted, and sampled, by reason, and notifies the handshake errors listeners.
+func (m *Manager) ReportHandshakeError(serverName string, remoteAddr net.Addr, err error) {
+	sample := HandshakeErrorSample{
+		Time:       time.Now(),
+		ServerName: serverName,
+		Error:      err.Error(),
+	}
+	if remoteAddr != nil {
+		sample.RemoteAddr = remoteAddr.String()
+	}
+
+	m.handshakeErrors.record(sample, err)
+}
+
+// HandshakeErrors returns the TLS handshake failures reported since the start, by reason.
+func (m *Manager) HandshakeErrors() []HandshakeErrorsInfo {
+	return m.handshakeErrors.infos()
+}
+
+// AddHandshakeErrorsListener adds a listener notified of the reason of each reported TLS handshake failure.
+// The listeners are called sequentially, and must not block.
+func (m *Manager) AddHandshakeErrorsListener(listener func(reason string)) {
+	m.handshakeErrors.addListener(listener)
+}
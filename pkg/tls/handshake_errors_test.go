@@ -0,0 +1,124 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandshakeErrorReason(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected string
+	}{
+		{
+			desc:     "strict SNI",
+			err:      &strictSNIError{domain: "foo.bar"},
+			expected: HandshakeErrorNoCertificate,
+		},
+		{
+			desc:     "ClientHello denied",
+			err:      &clientHelloDeniedError{rule: "no server name"},
+			expected: HandshakeErrorDenied,
+		},
+		{
+			desc:     "client certificate rejected by a verifier",
+			err:      &clientCertificateError{err: errors.New("the certificate is revoked")},
+			expected: HandshakeErrorClientCertificate,
+		},
+		{
+			desc:     "client certificate not verified",
+			err:      errors.New("tls: failed to verify client's certificate: x509: certificate signed by unknown authority"),
+			expected: HandshakeErrorClientCertificate,
+		},
+		{
+			desc:     "no client certificate",
+			err:      errors.New("tls: client didn't provide a certificate"),
+			expected: HandshakeErrorClientCertificate,
+		},
+		{
+			desc:     "unsupported version",
+			err:      fmt.Errorf("tls: client offered only unsupported versions: %x", []uint16{tls.VersionTLS12}),
+			expected: HandshakeErrorProtocol,
+		},
+		{
+			desc:     "no common cipher suite",
+			err:      errors.New("tls: no cipher suite supported by both client and server"),
+			expected: HandshakeErrorProtocol,
+		},
+		{
+			desc:     "other",
+			err:      errors.New("EOF"),
+			expected: HandshakeErrorOther,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, handshakeErrorReason(test.err))
+		})
+	}
+}
+
+func TestManager_ReportHandshakeError(t *testing.T) {
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{"default": {SniStrict: true}}, nil)
+
+	var reasons []string
+	tlsManager.AddHandshakeErrorsListener(func(reason string) {
+		reasons = append(reasons, reason)
+	})
+
+	config, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	serverConn, clientConn := net.Pipe()
+	defer func() { _ = clientConn.Close() }()
+
+	server := tls.Server(serverConn, config)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Handshake()
+		_ = serverConn.Close()
+	}()
+
+	client := tls.Client(clientConn, &tls.Config{ServerName: "foo.bar", InsecureSkipVerify: true})
+	assert.Error(t, client.Handshake())
+
+	err = <-serverErr
+	require.Error(t, err)
+
+	tlsManager.ReportHandshakeError("foo.bar", server.RemoteAddr(), err)
+	for i := 0; i < maxHandshakeErrorSamples+2; i++ {
+		tlsManager.ReportHandshakeError("", server.RemoteAddr(), fmt.Errorf("tls: no cipher suite supported by both client and server %d", i))
+	}
+
+	infos := tlsManager.HandshakeErrors()
+	require.Len(t, infos, 2)
+
+	assert.Equal(t, HandshakeErrorNoCertificate, infos[0].Reason)
+	assert.Equal(t, uint64(1), infos[0].Count)
+	require.Len(t, infos[0].Samples, 1)
+	assert.Equal(t, "foo.bar", infos[0].Samples[0].ServerName)
+	assert.Equal(t, err.Error(), infos[0].Samples[0].Error)
+
+	assert.Equal(t, HandshakeErrorProtocol, infos[1].Reason)
+	assert.Equal(t, uint64(maxHandshakeErrorSamples+2), infos[1].Count)
+	require.Len(t, infos[1].Samples, maxHandshakeErrorSamples)
+	assert.Equal(t, "tls: no cipher suite supported by both client and server 2", infos[1].Samples[0].Error)
+	assert.Equal(t, "tls: no cipher suite supported by both client and server 11", infos[1].Samples[maxHandshakeErrorSamples-1].Error)
+
+	assert.Len(t, reasons, maxHandshakeErrorSamples+3)
+	assert.Equal(t, HandshakeErrorNoCertificate, reasons[0])
+}
@@ -0,0 +1,144 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/memprotect"
+)
+
+// parseLazyCertificate parses the certificate chain only, and returns the certificate along with the key of its domains.
+// Its private key is parsed, and checked against the certificate, on its first use during a TLS handshake.
+func parseLazyCertificate(certContent, keyContent []byte) (certificateKey, *tls.Certificate, error) {
+	var chain [][]byte
+	for rest := certContent; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type == "CERTIFICATE" {
+			chain = append(chain, block.Bytes)
+		}
+	}
+
+	if len(chain) == 0 {
+		return certificateKey{}, nil, errors.New("unable to generate TLS certificate : no certificate PEM data")
+	}
+
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return certificateKey{}, nil, fmt.Errorf("unable to generate TLS certificate : %v", err)
+	}
+
+	certKey, err := getCertificateKey(leaf)
+	if err != nil {
+		return certificateKey{}, nil, err
+	}
+
+	return certKey, &tls.Certificate{
+		Certificate: chain,
+		PrivateKey: &lazyKey{
+			name:        certKey.hostname,
+			public:      leaf.PublicKey,
+			certContent: certContent,
+			keyContent:  keyContent,
+		},
+	}, nil
+}
+
+// lazyKey is the private key of a certificate, parsed on its first use.
+type lazyKey struct {
+	name   string
+	public crypto.PublicKey
+
+	mu          sync.Mutex
+	certContent []byte
+	keyContent  []byte
+	key         crypto.PrivateKey
+	err         error
+}
+
+// Public returns the public key of the certificate.
+func (k *lazyKey) Public() crypto.PublicKey {
+	return k.public
+}
+
+// Sign signs the digest with the private key, parsing it first if needed.
+func (k *lazyKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	key, err := k.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("the private key cannot sign")
+	}
+
+	return signer.Sign(rand, digest, opts)
+}
+
+// Decrypt decrypts the message with the private key, parsing it first if needed.
+func (k *lazyKey) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	key, err := k.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	decrypter, ok := key.(crypto.Decrypter)
+	if !ok {
+		return nil, errors.New("the private key cannot decrypt")
+	}
+
+	return decrypter.Decrypt(rand, msg, opts)
+}
+
+// Zeroize zeroizes the private key, parsed or not, which cannot be used afterwards.
+func (k *lazyKey) Zeroize() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	memprotect.ZeroizeKey(k.key)
+	for i := range k.keyContent {
+		k.keyContent[i] = 0
+	}
+
+	k.certContent, k.keyContent, k.key = nil, nil, nil
+	if k.err == nil {
+		k.err = errors.New("the private key has been zeroized")
+	}
+}
+
+func (k *lazyKey) parse() (crypto.PrivateKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.key != nil || k.err != nil {
+		return k.key, k.err
+	}
+
+	tlsCert, err := tls.X509KeyPair(k.certContent, k.keyContent)
+	if err != nil {
+		k.err = fmt.Errorf("unable to generate TLS certificate : %v", err)
+		log.WithoutContext().Errorf("Unable to parse the private key of the certificate for domain(s) %q on its first use: %v", k.name, err)
+	} else {
+		k.key = tlsCert.PrivateKey
+	}
+
+	// The contents are not needed anymore.
+	for i := range k.keyContent {
+		k.keyContent[i] = 0
+	}
+	k.certContent, k.keyContent = nil, nil
+
+	return k.key, k.err
+}
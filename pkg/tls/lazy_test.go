@@ -0,0 +1,113 @@
+package tls
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handshake performs a TLS handshake with a server serving the certificate.
+func handshake(t *testing.T, cert *tls.Certificate) error {
+	t.Helper()
+
+	serverConn, clientConn := net.Pipe()
+	defer func() { _ = clientConn.Close() }()
+
+	server := tls.Server(serverConn, &tls.Config{Certificates: []tls.Certificate{*cert}})
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Handshake()
+		_ = serverConn.Close()
+	}()
+
+	client := tls.Client(clientConn, &tls.Config{InsecureSkipVerify: true})
+	err := client.Handshake()
+
+	if sErr := <-serverErr; err == nil {
+		err = sErr
+	}
+
+	return err
+}
+
+func TestParseLazyCertificate(t *testing.T) {
+	certKey, cert, err := parseLazyCertificate([]byte(localhostCert), []byte(localhostKey))
+	require.NoError(t, err)
+
+	expectedKey, _, err := parseCertificate([]byte(localhostCert), []byte(localhostKey))
+	require.NoError(t, err)
+	assert.Equal(t, expectedKey, certKey)
+
+	key, ok := cert.PrivateKey.(*lazyKey)
+	require.True(t, ok)
+	assert.Nil(t, key.key)
+
+	// The private key is parsed on its first use.
+	require.NoError(t, handshake(t, cert))
+	assert.IsType(t, &rsa.PrivateKey{}, key.key)
+	assert.Nil(t, key.keyContent)
+
+	require.NoError(t, handshake(t, cert))
+
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, key.Public().(*rsa.PublicKey), []byte("secret"))
+	require.NoError(t, err)
+
+	plaintext, err := key.Decrypt(rand.Reader, ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), plaintext)
+}
+
+func TestParseLazyCertificate_invalidKey(t *testing.T) {
+	_, _, err := parseLazyCertificate([]byte("invalid"), []byte(localhostKey))
+	assert.Error(t, err)
+
+	// The invalid private key is only reported on its first use.
+	_, cert, err := parseLazyCertificate([]byte(localhostCert), []byte("invalid"))
+	require.NoError(t, err)
+
+	assert.Error(t, handshake(t, cert))
+
+	_, err = cert.PrivateKey.(*lazyKey).Sign(rand.Reader, make([]byte, 32), nil)
+	assert.Error(t, err)
+}
+
+func TestLazyKey_Zeroize(t *testing.T) {
+	_, cert, err := parseLazyCertificate([]byte(localhostCert), []byte(localhostKey))
+	require.NoError(t, err)
+
+	require.NoError(t, handshake(t, cert))
+
+	key := cert.PrivateKey.(*lazyKey)
+	parsed := key.key.(*rsa.PrivateKey)
+
+	key.Zeroize()
+
+	assert.Equal(t, 0, parsed.D.Sign())
+	assert.Error(t, handshake(t, cert))
+}
+
+func TestManager_lazyCertificates(t *testing.T) {
+	tlsManager := NewManager()
+	tlsManager.EnableLazyCertificates()
+
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, []*CertAndStores{{
+		Certificate: Certificate{CertFile: localhostCert, KeyFile: localhostKey},
+	}})
+
+	cert := getDynamicCertificate(t, tlsManager)
+	assert.IsType(t, &lazyKey{}, cert.PrivateKey)
+
+	// The certificates which did not change are not parsed again.
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, []*CertAndStores{{
+		Certificate: Certificate{CertFile: localhostCert, KeyFile: localhostKey},
+	}})
+
+	assert.Same(t, cert, getDynamicCertificate(t, tlsManager))
+}
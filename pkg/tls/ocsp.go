@@ -0,0 +1,182 @@
+package tls
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	defaultOCSPCacheDuration = time.Hour
+	ocspRequestTimeout       = 5 * time.Second
+	// ocspMaxResponseSize limits the size of the responses read from the OCSP responders.
+	ocspMaxResponseSize = 1 << 20
+)
+
+// ocspCache holds the OCSP responses of the client certificates, until their next update.
+type ocspCache struct {
+	client *http.Client
+
+	mu        sync.Mutex
+	responses map[string]*ocsp.Response
+	expiresAt map[string]time.Time
+}
+
+func newOCSPCache() *ocspCache {
+	return &ocspCache{
+		client:    &http.Client{Timeout: ocspRequestTimeout},
+		responses: make(map[string]*ocsp.Response),
+		expiresAt: make(map[string]time.Time),
+	}
+}
+
+// verifyPeerCertificate returns the function rejecting the client certificates revoked according to their OCSP responder.
+// The client certificates whose status cannot be checked are rejected in the hard-fail mode only.
+func (c *ocspCache) verifyPeerCertificate(config OCSP) func([][]byte, [][]*x509.Certificate) error {
+	cacheDuration := time.Duration(config.CacheDuration)
+	if cacheDuration <= 0 {
+		cacheDuration = defaultOCSPCacheDuration
+	}
+
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		// No client certificate was given, as allowed by the VerifyClientCertIfGiven client authentication type.
+		if len(rawCerts) == 0 {
+			return nil
+		}
+
+		// The status of a client certificate which has not been verified cannot be checked, it is always rejected.
+		if len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+			return errors.New("the OCSP status of a client certificate which has not been verified cannot be checked")
+		}
+
+		cert := verifiedChains[0][0]
+
+		var resp *ocsp.Response
+		var err error
+		if len(verifiedChains[0]) < 2 {
+			// The client certificate is itself a trusted CA, with no issuer to check its status with.
+			err = errors.New("the certificate has no issuer")
+		} else {
+			resp, err = c.get(cert, verifiedChains[0][1], config.Responder, cacheDuration)
+		}
+		if err == nil && resp.Status == ocsp.Unknown {
+			err = errors.New("the certificate is unknown to the OCSP responder")
+		}
+
+		if err != nil {
+			if config.HardFail {
+				return fmt.Errorf("unable to check the OCSP status of the client certificate %q: %w", cert.Subject, err)
+			}
+
+			log.WithoutContext().Debugf("Unable to check the OCSP status of the client certificate %q, accepting it: %v", cert.Subject, err)
+			return nil
+		}
+
+		if resp.Status == ocsp.Revoked {
+			return fmt.Errorf("the client certificate %q (serial %s) is revoked", cert.Subject, cert.SerialNumber)
+		}
+
+		return nil
+	}
+}
+
+// get returns the OCSP response of the certificate, from the cache when it has not expired.
+func (c *ocspCache) get(cert, issuer *x509.Certificate, responder string, cacheDuration time.Duration) (*ocsp.Response, error) {
+	sum := sha256.Sum256(issuer.Raw)
+	key := hex.EncodeToString(sum[:]) + "/" + cert.SerialNumber.String()
+
+	c.mu.Lock()
+	resp, ok := c.responses[key]
+	if ok && time.Now().After(c.expiresAt[key]) {
+		delete(c.responses, key)
+		delete(c.expiresAt, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if ok {
+		return resp, nil
+	}
+
+	resp, err := c.request(cert, issuer, responder)
+	if err != nil {
+		return nil, err
+	}
+
+	// The response is cached until its next update, and at most for the cache duration.
+	expiresAt := time.Now().Add(cacheDuration)
+	if !resp.NextUpdate.IsZero() && resp.NextUpdate.Before(expiresAt) {
+		expiresAt = resp.NextUpdate
+	}
+
+	c.mu.Lock()
+	c.responses[key] = resp
+	c.expiresAt[key] = expiresAt
+	c.mu.Unlock()
+
+	return resp, nil
+}
+
+func (c *ocspCache) request(cert, issuer *x509.Certificate, responder string) (*ocsp.Response, error) {
+	if responder == "" {
+		if len(cert.OCSPServer) == 0 {
+			return nil, errors.New("no OCSP responder")
+		}
+		responder = cert.OCSPServer[0]
+	}
+
+	body, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ocspRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, responder, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	req.Header.Set("Accept", "application/ocsp-response")
+
+	httpResp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from the OCSP responder", httpResp.StatusCode)
+	}
+
+	raw, err := ioutil.ReadAll(io.LimitReader(httpResp.Body, ocspMaxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	// The signature of the response is verified with the issuer, or with a responder certificate delegated by the issuer.
+	resp, err := ocsp.ParseResponseForCert(raw, cert, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCSP response: %w", err)
+	}
+
+	if !resp.NextUpdate.IsZero() && time.Now().After(resp.NextUpdate) {
+		return nil, errors.New("the OCSP response has expired")
+	}
+
+	return resp, nil
+}
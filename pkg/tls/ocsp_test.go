@@ -0,0 +1,203 @@
+package tls
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+// startOCSPResponder starts an OCSP responder of the CA, answering that the certificates whose serial is revoked are revoked,
+// and that the other ones are good.
+func startOCSPResponder(t *testing.T, ca *testCA, revoked int64) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		ocspReq, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		template := ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: ocspReq.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}
+		if ocspReq.SerialNumber.Int64() == revoked {
+			template.Status = ocsp.Revoked
+			template.RevokedAt = time.Now().Add(-time.Minute)
+		}
+
+		resp, err := ocsp.CreateResponse(ca.cert, ca.cert, template, ca.key)
+		require.NoError(t, err)
+
+		rw.Header().Set("Content-Type", "application/ocsp-response")
+		_, _ = rw.Write(resp)
+	})), &calls
+}
+
+func TestOCSPCache_verifyPeerCertificate(t *testing.T) {
+	ca := newTestCA(t, "ca")
+
+	server, _ := startOCSPResponder(t, ca, 2)
+	defer server.Close()
+
+	testCases := []struct {
+		desc     string
+		config   OCSP
+		cert     *x509.Certificate
+		expected bool
+	}{
+		{
+			desc:     "good",
+			cert:     ca.issue(t, 3, server.URL),
+			expected: true,
+		},
+		{
+			desc: "revoked",
+			cert: ca.issue(t, 2, server.URL),
+		},
+		{
+			desc:   "revoked with the responder of the configuration",
+			config: OCSP{Responder: server.URL},
+			cert:   ca.issue(t, 2),
+		},
+		{
+			desc:     "no responder, soft-fail",
+			cert:     ca.issue(t, 3),
+			expected: true,
+		},
+		{
+			desc:   "no responder, hard-fail",
+			config: OCSP{HardFail: true},
+			cert:   ca.issue(t, 3),
+		},
+		{
+			desc:     "unavailable responder, soft-fail",
+			cert:     ca.issue(t, 3, "http://127.0.0.1:1"),
+			expected: true,
+		},
+		{
+			desc:   "unavailable responder, hard-fail",
+			config: OCSP{HardFail: true},
+			cert:   ca.issue(t, 3, "http://127.0.0.1:1"),
+		},
+	}
+
+	// The test cases are not parallel, as the responder is closed when the test returns.
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			verify := newOCSPCache().verifyPeerCertificate(test.config)
+
+			err := verify([][]byte{test.cert.Raw}, [][]*x509.Certificate{{test.cert, ca.cert}})
+			if test.expected {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestOCSPCache_invalidSignature(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	// The forged CA has the same name, but another key.
+	forgedCA := newTestCA(t, "ca")
+
+	server, _ := startOCSPResponder(t, forgedCA, 0)
+	defer server.Close()
+
+	verify := newOCSPCache().verifyPeerCertificate(OCSP{HardFail: true})
+
+	cert := ca.issue(t, 2, server.URL)
+	err := verify([][]byte{cert.Raw}, [][]*x509.Certificate{{cert, ca.cert}})
+	assert.Error(t, err)
+}
+
+func TestOCSPCache_cache(t *testing.T) {
+	ca := newTestCA(t, "ca")
+
+	server, calls := startOCSPResponder(t, ca, 2)
+	defer server.Close()
+
+	cache := newOCSPCache()
+
+	good := ca.issue(t, 3, server.URL)
+	revoked := ca.issue(t, 2, server.URL)
+
+	verify := cache.verifyPeerCertificate(OCSP{})
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, verify([][]byte{good.Raw}, [][]*x509.Certificate{{good, ca.cert}}))
+		assert.Error(t, verify([][]byte{revoked.Raw}, [][]*x509.Certificate{{revoked, ca.cert}}))
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+
+	// The responses are requested again once the cache duration has elapsed.
+	other := ca.issue(t, 4, server.URL)
+
+	verify = cache.verifyPeerCertificate(OCSP{CacheDuration: types.Duration(time.Millisecond)})
+	assert.NoError(t, verify([][]byte{other.Raw}, [][]*x509.Certificate{{other, ca.cert}}))
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, verify([][]byte{other.Raw}, [][]*x509.Certificate{{other, ca.cert}}))
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+}
+
+func TestOCSPCache_unverified(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	cert := ca.issue(t, 3)
+
+	for _, hardFail := range []bool{false, true} {
+		verify := newOCSPCache().verifyPeerCertificate(OCSP{HardFail: hardFail})
+
+		// No client certificate.
+		assert.NoError(t, verify(nil, nil))
+
+		// A client certificate which has not been verified.
+		assert.Error(t, verify([][]byte{cert.Raw}, nil))
+	}
+
+	// A client certificate which is itself a trusted CA.
+	assert.NoError(t, newOCSPCache().verifyPeerCertificate(OCSP{})([][]byte{ca.cert.Raw}, [][]*x509.Certificate{{ca.cert}}))
+	assert.Error(t, newOCSPCache().verifyPeerCertificate(OCSP{HardFail: true})([][]byte{ca.cert.Raw}, [][]*x509.Certificate{{ca.cert}}))
+}
+
+func TestBuildTLSConfig_OCSP(t *testing.T) {
+	_, err := buildTLSConfig(Options{ClientAuth: ClientAuth{OCSP: &OCSP{}}}, newCRLPool(), newOCSPCache(), nil)
+	assert.Error(t, err)
+
+	ca := newTestCA(t, "ca")
+
+	_, err = buildTLSConfig(Options{
+		ClientAuth: ClientAuth{
+			CAFiles:        []FileOrContent{FileOrContent(ca.pem())},
+			ClientAuthType: "RequestClientCert",
+			OCSP:           &OCSP{},
+		},
+	}, newCRLPool(), newOCSPCache(), nil)
+	assert.Error(t, err)
+
+	_, err = buildTLSConfig(Options{
+		ClientAuth: ClientAuth{
+			CAFiles:        []FileOrContent{FileOrContent(ca.pem())},
+			ClientAuthType: "VerifyClientCertIfGiven",
+			OCSP:           &OCSP{},
+		},
+	}, newCRLPool(), newOCSPCache(), nil)
+	assert.NoError(t, err)
+}
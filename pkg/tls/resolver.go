@@ -0,0 +1,35 @@
+package tls
+
+import "crypto/tls"
+
+// CertificateResolver resolves the certificate to serve during a TLS handshake.
+// It allows to plug certificate sources, such as the ACME TLS challenges, in front of the certificate stores.
+type CertificateResolver interface {
+	// ResolveCertificate returns the certificate to serve for the ClientHello,
+	// or nil when the resolver has no certificate for it.
+	ResolveCertificate(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error)
+}
+
+// CertificateResolverFunc is an adapter to use a function as a CertificateResolver.
+type CertificateResolverFunc func(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error)
+
+// ResolveCertificate calls f(clientHello).
+func (f CertificateResolverFunc) ResolveCertificate(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return f(clientHello)
+}
+
+// resolveCertificate returns the certificate of the first resolver having a certificate for the ClientHello.
+func resolveCertificate(resolvers []CertificateResolver, clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	for _, resolver := range resolvers {
+		cert, err := resolver.ResolveCertificate(clientHello)
+		if err != nil {
+			return nil, err
+		}
+
+		if cert != nil {
+			return cert, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,181 @@
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// sctListOID is the OID of the X.509 extension holding the SCTs embedded in a certificate (RFC 6962, section 3.3).
+var sctListOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// SCTStatus describes the Signed Certificate Timestamps (SCTs) of a certificate,
+// against the certificate transparency policy of the browsers.
+type SCTStatus struct {
+	// Count is the number of distinct logs of the valid SCTs of the certificate.
+	Count int
+	// Required is the number of distinct logs required by the policy.
+	Required int
+	// Error explains why the certificate does not comply with the policy, empty if it does.
+	Error string
+}
+
+// Compliant reports whether the certificate complies with the certificate transparency policy of the browsers.
+func (s SCTStatus) Compliant() bool {
+	return s.Error == ""
+}
+
+// checkSCTs checks the SCTs embedded in the leaf, and the SCTs delivered along with the certificate during the TLS handshakes,
+// against the certificate transparency policy of the browsers:
+// the SCTs embedded in a certificate valid for at most 180 days must come from 2 distinct logs, and from 3 otherwise,
+// while the SCTs delivered during the TLS handshakes must come from 2 distinct logs.
+// The format and the timestamps of the SCTs are checked, but not their signatures, which require the keys of the logs.
+func checkSCTs(cert *tls.Certificate, leaf *x509.Certificate, now time.Time) *SCTStatus {
+	embedded, embeddedErrs := embeddedSCTs(leaf, now)
+	delivered, deliveredErrs := validSCTs(cert.SignedCertificateTimestamps, now)
+	invalid := append(embeddedErrs, deliveredErrs...)
+
+	required := 3
+	if leaf.NotAfter.Sub(leaf.NotBefore) <= 180*24*time.Hour {
+		required = 2
+	}
+
+	embeddedLogs := distinctLogs(embedded)
+	deliveredLogs := distinctLogs(delivered)
+
+	status := &SCTStatus{Count: len(embeddedLogs), Required: required}
+	if len(deliveredLogs) >= 2 && len(embeddedLogs) < required {
+		status.Count = len(deliveredLogs)
+		status.Required = 2
+	}
+
+	if status.Count >= status.Required {
+		return status
+	}
+
+	if len(embedded) == 0 && len(delivered) == 0 && len(invalid) == 0 {
+		status.Error = "no SCT"
+	} else {
+		status.Error = fmt.Sprintf("valid SCTs from %d distinct logs, %d required", status.Count, status.Required)
+	}
+
+	if len(invalid) > 0 {
+		status.Error += fmt.Sprintf(" (%d invalid SCTs: %v)", len(invalid), invalid[0])
+	}
+
+	return status
+}
+
+// signedCertificateTimestamp holds the fields of an SCT used by the policy.
+type signedCertificateTimestamp struct {
+	logID     [32]byte
+	timestamp time.Time
+}
+
+// distinctLogs returns the distinct logs of the SCTs.
+func distinctLogs(scts []signedCertificateTimestamp) map[[32]byte]struct{} {
+	logs := make(map[[32]byte]struct{})
+	for _, sct := range scts {
+		logs[sct.logID] = struct{}{}
+	}
+	return logs
+}
+
+// embeddedSCTs parses the SCT list embedded in the certificate.
+func embeddedSCTs(leaf *x509.Certificate, now time.Time) ([]signedCertificateTimestamp, []error) {
+	for _, ext := range leaf.Extensions {
+		if !ext.Id.Equal(sctListOID) {
+			continue
+		}
+
+		var list []byte
+		rest, err := asn1.Unmarshal(ext.Value, &list)
+		if err != nil || len(rest) > 0 {
+			return nil, []error{errors.New("malformed SCT list extension")}
+		}
+
+		if len(list) < 2 || int(binary.BigEndian.Uint16(list)) != len(list)-2 {
+			return nil, []error{errors.New("malformed SCT list")}
+		}
+
+		var raw [][]byte
+		for list = list[2:]; len(list) > 0; {
+			if len(list) < 2 || len(list)-2 < int(binary.BigEndian.Uint16(list)) {
+				return nil, []error{errors.New("malformed SCT list")}
+			}
+
+			n := int(binary.BigEndian.Uint16(list))
+			raw = append(raw, list[2:2+n])
+			list = list[2+n:]
+		}
+
+		return validSCTs(raw, now)
+	}
+
+	return nil, nil
+}
+
+// validSCTs parses the serialized SCTs, and returns the valid ones, along with the errors of the invalid ones,
+// such as the SCTs issued in the future.
+func validSCTs(raw [][]byte, now time.Time) ([]signedCertificateTimestamp, []error) {
+	var scts []signedCertificateTimestamp
+	var errs []error
+	for _, b := range raw {
+		sct, err := parseSCT(b)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if sct.timestamp.After(now) {
+			errs = append(errs, fmt.Errorf("SCT issued in the future: %s", sct.timestamp.UTC().Format(time.RFC3339)))
+			continue
+		}
+
+		scts = append(scts, sct)
+	}
+	return scts, errs
+}
+
+// parseSCT parses a serialized SCT (RFC 6962, section 3.2).
+func parseSCT(b []byte) (signedCertificateTimestamp, error) {
+	var sct signedCertificateTimestamp
+
+	// The version, the log ID, the timestamp, and the length of the extensions.
+	if len(b) < 1+32+8+2 {
+		return sct, errors.New("truncated SCT")
+	}
+
+	if b[0] != 0 {
+		return sct, fmt.Errorf("unsupported SCT version %d", b[0])
+	}
+
+	copy(sct.logID[:], b[1:33])
+
+	millis := binary.BigEndian.Uint64(b[33:41])
+	sct.timestamp = time.Unix(int64(millis/1000), int64(millis%1000)*int64(time.Millisecond))
+
+	b = b[41:]
+	extLen := int(binary.BigEndian.Uint16(b))
+	b = b[2:]
+	if len(b) < extLen {
+		return sct, errors.New("truncated SCT extensions")
+	}
+	b = b[extLen:]
+
+	// The hash and signature algorithms, and the length of the signature.
+	if len(b) < 4 {
+		return sct, errors.New("truncated SCT signature")
+	}
+
+	sigLen := int(binary.BigEndian.Uint16(b[2:]))
+	if len(b)-4 != sigLen || sigLen == 0 {
+		return sct, errors.New("malformed SCT signature")
+	}
+
+	return sct, nil
+}
@@ -0,0 +1,162 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/tls/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serializeSCT returns a v1 SCT of the log, issued at the timestamp, with a dummy signature.
+func serializeSCT(logID byte, timestamp time.Time) []byte {
+	sct := []byte{0}
+	sct = append(sct, make([]byte, 32)...)
+	sct[1] = logID
+
+	millis := make([]byte, 8)
+	binary.BigEndian.PutUint64(millis, uint64(timestamp.UnixNano()/int64(time.Millisecond)))
+	sct = append(sct, millis...)
+
+	// No extensions, then the ECDSA-SHA256 signature.
+	return append(sct, 0, 0, 4, 3, 0, 2, 0xca, 0xfe)
+}
+
+// sctListExtension returns the X.509 extension embedding the SCTs.
+func sctListExtension(t *testing.T, scts ...[]byte) pkix.Extension {
+	t.Helper()
+
+	var list []byte
+	for _, sct := range scts {
+		list = append(list, byte(len(sct)>>8), byte(len(sct)))
+		list = append(list, sct...)
+	}
+	list = append([]byte{byte(len(list) >> 8), byte(len(list))}, list...)
+
+	value, err := asn1.Marshal(list)
+	require.NoError(t, err)
+
+	return pkix.Extension{Id: sctListOID, Value: value}
+}
+
+func TestCheckSCTs(t *testing.T) {
+	now := time.Date(2020, time.April, 1, 12, 0, 0, 0, time.UTC)
+	issued := now.Add(-time.Hour)
+
+	testCases := []struct {
+		desc       string
+		lifetime   time.Duration
+		extensions func(t *testing.T) []pkix.Extension
+		delivered  [][]byte
+		expected   SCTStatus
+	}{
+		{
+			desc:     "no SCT",
+			lifetime: 90 * 24 * time.Hour,
+			expected: SCTStatus{Required: 2, Error: "no SCT"},
+		},
+		{
+			desc:     "embedded SCTs of a short-lived certificate",
+			lifetime: 90 * 24 * time.Hour,
+			extensions: func(t *testing.T) []pkix.Extension {
+				return []pkix.Extension{sctListExtension(t, serializeSCT(1, issued), serializeSCT(2, issued))}
+			},
+			expected: SCTStatus{Count: 2, Required: 2},
+		},
+		{
+			desc:     "embedded SCTs of a long-lived certificate",
+			lifetime: 365 * 24 * time.Hour,
+			extensions: func(t *testing.T) []pkix.Extension {
+				return []pkix.Extension{sctListExtension(t, serializeSCT(1, issued), serializeSCT(2, issued))}
+			},
+			expected: SCTStatus{Count: 2, Required: 3, Error: "valid SCTs from 2 distinct logs, 3 required"},
+		},
+		{
+			desc:     "embedded SCTs of the same log",
+			lifetime: 90 * 24 * time.Hour,
+			extensions: func(t *testing.T) []pkix.Extension {
+				return []pkix.Extension{sctListExtension(t, serializeSCT(1, issued), serializeSCT(1, issued))}
+			},
+			expected: SCTStatus{Count: 1, Required: 2, Error: "valid SCTs from 1 distinct logs, 2 required"},
+		},
+		{
+			desc:     "embedded SCT issued in the future",
+			lifetime: 90 * 24 * time.Hour,
+			extensions: func(t *testing.T) []pkix.Extension {
+				return []pkix.Extension{sctListExtension(t, serializeSCT(1, issued), serializeSCT(2, now.Add(time.Hour)))}
+			},
+			expected: SCTStatus{Count: 1, Required: 2, Error: "valid SCTs from 1 distinct logs, 2 required (1 invalid SCTs: SCT issued in the future: 2020-04-01T13:00:00Z)"},
+		},
+		{
+			desc:     "truncated embedded SCT",
+			lifetime: 90 * 24 * time.Hour,
+			extensions: func(t *testing.T) []pkix.Extension {
+				return []pkix.Extension{sctListExtension(t, serializeSCT(1, issued), serializeSCT(2, issued)[:45])}
+			},
+			expected: SCTStatus{Count: 1, Required: 2, Error: "valid SCTs from 1 distinct logs, 2 required (1 invalid SCTs: truncated SCT signature)"},
+		},
+		{
+			desc:     "malformed SCT list",
+			lifetime: 90 * 24 * time.Hour,
+			extensions: func(t *testing.T) []pkix.Extension {
+				value, err := asn1.Marshal([]byte{0, 42, 0})
+				require.NoError(t, err)
+				return []pkix.Extension{{Id: sctListOID, Value: value}}
+			},
+			expected: SCTStatus{Required: 2, Error: "valid SCTs from 0 distinct logs, 2 required (1 invalid SCTs: malformed SCT list)"},
+		},
+		{
+			desc:      "SCTs delivered during the TLS handshakes",
+			lifetime:  365 * 24 * time.Hour,
+			delivered: [][]byte{serializeSCT(1, issued), serializeSCT(2, issued)},
+			expected:  SCTStatus{Count: 2, Required: 2},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			leaf := &x509.Certificate{
+				NotBefore: now.Add(-2 * time.Hour),
+				NotAfter:  now.Add(-2 * time.Hour).Add(test.lifetime),
+			}
+			if test.extensions != nil {
+				leaf.Extensions = test.extensions(t)
+			}
+
+			cert := &tls.Certificate{SignedCertificateTimestamps: test.delivered}
+
+			status := checkSCTs(cert, leaf, now)
+			require.NotNil(t, status)
+			assert.Equal(t, test.expected, *status)
+			assert.Equal(t, test.expected.Error == "", status.Compliant())
+		})
+	}
+}
+
+func TestManager_EnableSCTChecks(t *testing.T) {
+	localhost := &Certificate{CertFile: localhostCert, KeyFile: localhostKey}
+
+	tlsManager := NewManager()
+	tlsManager.EnableSCTChecks()
+	tlsManager.UpdateConfigs(context.Background(), map[string]Store{"default": {}}, nil, []*CertAndStores{
+		{Certificate: *localhost, Stores: []string{"default"}},
+	})
+
+	infos := tlsManager.CertificatesInfo()
+	require.Len(t, infos, 1)
+	assert.Equal(t, &SCTStatus{Required: 3, Error: "no SCT"}, infos[0].SCT)
+
+	served, ok := tlsManager.ServedCertificate("default", "example.com", certificate.RSA)
+	require.True(t, ok)
+	assert.Equal(t, infos[0].SCT, served.SCT)
+}
@@ -0,0 +1,315 @@
+package tls
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+const (
+	defaultSessionTicketsRotationInterval = 12 * time.Hour
+	defaultSessionTicketsRootKey          = "traefik/tls/sessiontickets"
+
+	// sessionTicketKeysCount is the number of keys kept: the key encrypting the session tickets,
+	// and the previous keys still decrypting the session tickets they encrypted.
+	sessionTicketKeysCount = 3
+	// sessionTicketKeysSyncInterval is the interval at which the keys shared in the KV store are read again,
+	// so that the keys rotated by the other Traefik instances are used.
+	sessionTicketKeysSyncInterval = time.Minute
+	sessionTicketKeysSyncAttempts = 3
+)
+
+// KVStoreFactory creates the client of a KV store sharing the session ticket keys.
+type KVStoreFactory func(backend store.Backend, endpoints []string, username, password string, tlsConfig *tls.Config) (store.Store, error)
+
+// sessionTicketKeysState holds the session ticket keys, from the most recent one, as they are shared in the KV store.
+type sessionTicketKeysState struct {
+	Keys      [][]byte  `json:"keys"`
+	RotatedAt time.Time `json:"rotatedAt"`
+}
+
+// sessionTicketKeys holds the keys encrypting the session tickets of TLS options, rotated on an interval,
+// and shared in a KV store if any.
+type sessionTicketKeys struct {
+	config   SessionTickets
+	interval time.Duration
+
+	// kvKey is the key holding the shared keys in the KV store, and newClient creates the client of the KV store.
+	kvKey     string
+	newClient func() (store.Store, error)
+
+	mu        sync.Mutex
+	keys      [][32]byte
+	rotatedAt time.Time
+	client    store.Store
+	syncedAt  time.Time
+	syncing   bool
+	closed    bool
+}
+
+func newSessionTicketKeys(optionsName string, config SessionTickets, kvStoreFactory KVStoreFactory) (*sessionTicketKeys, error) {
+	interval := time.Duration(config.RotationInterval)
+	if interval == 0 {
+		interval = defaultSessionTicketsRotationInterval
+	}
+	if interval < 0 {
+		return nil, fmt.Errorf("invalid rotationInterval: %s", interval)
+	}
+
+	keys := &sessionTicketKeys{config: config, interval: interval}
+
+	if config.KV == nil {
+		return keys, nil
+	}
+
+	var backend store.Backend
+	switch config.KV.Backend {
+	case "consul":
+		backend = store.CONSUL
+	case "etcd":
+		backend = store.ETCDV3
+	case "redis":
+		backend = store.REDIS
+	default:
+		return nil, fmt.Errorf("unsupported KV backend: %q", config.KV.Backend)
+	}
+
+	if kvStoreFactory == nil {
+		return nil, errors.New("the KV stores are not supported")
+	}
+
+	rootKey := config.KV.RootKey
+	if rootKey == "" {
+		rootKey = defaultSessionTicketsRootKey
+	}
+	keys.kvKey = path.Join(rootKey, optionsName)
+
+	kv := *config.KV
+	keys.newClient = func() (store.Store, error) {
+		var tlsConfig *tls.Config
+		if kv.TLS != nil {
+			var err error
+			tlsConfig, err = kv.TLS.CreateTLSConfig(context.Background())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return kvStoreFactory(backend, kv.Endpoints, kv.Username, kv.Password, tlsConfig)
+	}
+
+	return keys, nil
+}
+
+// get returns the session ticket keys, from the one encrypting the session tickets.
+// Without a KV store, a new key is generated when the interval is elapsed.
+// With a KV store, the keys are read again in the background when the sync interval is elapsed,
+// and a local key is used until they are read for the first time.
+// If the keys are still not rotated in the KV store a sync interval after the interval is elapsed,
+// e.g. while the KV store is unavailable, a new key is generated locally.
+func (k *sessionTicketKeys) get() [][32]byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+
+	if k.newClient != nil && !k.syncing && !k.closed && now.Sub(k.syncedAt) >= sessionTicketKeysSyncInterval {
+		k.syncing = true
+		go k.sync()
+	}
+
+	if len(k.keys) == 0 || now.Sub(k.rotatedAt) >= k.rotationDeadline() {
+		state, err := rotateSessionTicketKeys(k.state(), now)
+		if err != nil {
+			log.WithoutContext().Errorf("Unable to rotate the session ticket keys: %v", err)
+			return k.keys
+		}
+		k.set(state)
+	}
+
+	return k.keys
+}
+
+// rotationDeadline returns the age of the keys after which they are rotated locally.
+// With a KV store, the instances are given a sync interval to adopt the keys rotated in the KV store,
+// so that they do not rotate them locally to different keys.
+func (k *sessionTicketKeys) rotationDeadline() time.Duration {
+	if k.newClient == nil {
+		return k.interval
+	}
+
+	return k.interval + sessionTicketKeysSyncInterval
+}
+
+// sync adopts the keys shared in the KV store, rotating them first if the interval is elapsed.
+func (k *sessionTicketKeys) sync() {
+	state, err := k.syncState()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.syncing = false
+	k.syncedAt = time.Now()
+
+	if k.closed {
+		return
+	}
+
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to sync the session ticket keys with the KV store %s: %v", k.kvKey, err)
+		return
+	}
+
+	k.set(state)
+}
+
+// syncState reads the keys shared in the KV store, and rotates them if the interval is elapsed.
+// The keys are created, or rotated, only if no other Traefik instance did it concurrently,
+// in which case the keys are read again.
+func (k *sessionTicketKeys) syncState() (sessionTicketKeysState, error) {
+	client, err := k.kvClient()
+	if err != nil {
+		return sessionTicketKeysState{}, err
+	}
+
+	for attempt := 0; attempt < sessionTicketKeysSyncAttempts; attempt++ {
+		pair, err := client.Get(k.kvKey, nil)
+		if errors.Is(err, store.ErrKeyNotFound) {
+			pair = nil
+		} else if err != nil {
+			return sessionTicketKeysState{}, err
+		}
+
+		var state sessionTicketKeysState
+		if pair != nil {
+			if err := json.Unmarshal(pair.Value, &state); err != nil {
+				return sessionTicketKeysState{}, fmt.Errorf("invalid session ticket keys: %w", err)
+			}
+
+			if err := validSessionTicketKeys(state); err != nil {
+				return sessionTicketKeysState{}, err
+			}
+		}
+
+		now := time.Now()
+		if len(state.Keys) > 0 && now.Sub(state.RotatedAt) < k.interval {
+			return state, nil
+		}
+
+		state, err = rotateSessionTicketKeys(state, now)
+		if err != nil {
+			return sessionTicketKeysState{}, err
+		}
+
+		value, err := json.Marshal(state)
+		if err != nil {
+			return sessionTicketKeysState{}, err
+		}
+
+		_, _, err = client.AtomicPut(k.kvKey, value, pair, nil)
+		if err == nil {
+			return state, nil
+		}
+
+		if !errors.Is(err, store.ErrKeyModified) && !errors.Is(err, store.ErrKeyExists) {
+			return sessionTicketKeysState{}, err
+		}
+	}
+
+	return sessionTicketKeysState{}, errors.New("the keys are modified concurrently")
+}
+
+// kvClient returns the client of the KV store, created on the first sync.
+func (k *sessionTicketKeys) kvClient() (store.Store, error) {
+	k.mu.Lock()
+	client := k.client
+	k.mu.Unlock()
+
+	if client != nil {
+		return client, nil
+	}
+
+	client, err := k.newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.closed {
+		client.Close()
+		return nil, errors.New("the KV store is closed")
+	}
+
+	k.client = client
+
+	return client, nil
+}
+
+// close closes the client of the KV store, once the keys are not used anymore.
+func (k *sessionTicketKeys) close() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.closed = true
+	if k.client != nil {
+		k.client.Close()
+		k.client = nil
+	}
+}
+
+func (k *sessionTicketKeys) state() sessionTicketKeysState {
+	state := sessionTicketKeysState{RotatedAt: k.rotatedAt}
+	for _, key := range k.keys {
+		key := key
+		state.Keys = append(state.Keys, key[:])
+	}
+
+	return state
+}
+
+func (k *sessionTicketKeys) set(state sessionTicketKeysState) {
+	keys := make([][32]byte, len(state.Keys))
+	for i, key := range state.Keys {
+		copy(keys[i][:], key)
+	}
+
+	k.keys = keys
+	k.rotatedAt = state.RotatedAt
+}
+
+// rotateSessionTicketKeys returns the keys with a new key encrypting the session tickets,
+// followed by the most recent previous keys.
+func rotateSessionTicketKeys(state sessionTicketKeysState, now time.Time) (sessionTicketKeysState, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return sessionTicketKeysState{}, err
+	}
+
+	keys := append([][]byte{key}, state.Keys...)
+	if len(keys) > sessionTicketKeysCount {
+		keys = keys[:sessionTicketKeysCount]
+	}
+
+	return sessionTicketKeysState{Keys: keys, RotatedAt: now}, nil
+}
+
+func validSessionTicketKeys(state sessionTicketKeysState) error {
+	for _, key := range state.Keys {
+		if len(key) != 32 {
+			return fmt.Errorf("invalid session ticket key length: %d", len(key))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKVStore is a KV store holding the pairs in memory, supporting the atomic operations.
+type fakeKVStore struct {
+	store.Store
+
+	mu    sync.Mutex
+	index uint64
+	pairs map[string]*store.KVPair
+}
+
+func newFakeKVStore() *fakeKVStore {
+	return &fakeKVStore{pairs: make(map[string]*store.KVPair)}
+}
+
+func (s *fakeKVStore) factory(_ store.Backend, _ []string, _, _ string, _ *tls.Config) (store.Store, error) {
+	return s, nil
+}
+
+func (s *fakeKVStore) Get(key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pair, ok := s.pairs[key]
+	if !ok {
+		return nil, store.ErrKeyNotFound
+	}
+
+	return &store.KVPair{Key: pair.Key, Value: pair.Value, LastIndex: pair.LastIndex}, nil
+}
+
+func (s *fakeKVStore) AtomicPut(key string, value []byte, previous *store.KVPair, _ *store.WriteOptions) (bool, *store.KVPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.pairs[key]
+	if previous == nil && ok {
+		return false, nil, store.ErrKeyExists
+	}
+	if previous != nil && (!ok || current.LastIndex != previous.LastIndex) {
+		return false, nil, store.ErrKeyModified
+	}
+
+	s.index++
+	pair := &store.KVPair{Key: key, Value: value, LastIndex: s.index}
+	s.pairs[key] = pair
+
+	return true, pair, nil
+}
+
+func (s *fakeKVStore) Close() {}
+
+func TestSessionTicketKeys_rotation(t *testing.T) {
+	keys, err := newSessionTicketKeys("foo", SessionTickets{RotationInterval: types.Duration(time.Hour)}, nil)
+	require.NoError(t, err)
+
+	first := keys.get()
+	require.Len(t, first, 1)
+	assert.Equal(t, first, keys.get())
+
+	var previous [][32]byte
+	for i := 0; i < sessionTicketKeysCount; i++ {
+		previous = keys.get()
+		keys.rotatedAt = keys.rotatedAt.Add(-time.Hour)
+
+		current := keys.get()
+		assert.NotEqual(t, previous[0], current[0])
+		assert.Equal(t, previous[0], current[1])
+	}
+
+	assert.Len(t, keys.get(), sessionTicketKeysCount)
+	assert.NotContains(t, keys.get(), first[0])
+}
+
+func TestSessionTicketKeys_kv(t *testing.T) {
+	kvStore := newFakeKVStore()
+
+	config := SessionTickets{
+		RotationInterval: types.Duration(time.Hour),
+		KV:               &SessionTicketsKV{Backend: "consul", Endpoints: []string{"localhost:8500"}},
+	}
+
+	foo, err := newSessionTicketKeys("foo", config, kvStore.factory)
+	require.NoError(t, err)
+
+	bar, err := newSessionTicketKeys("foo", config, kvStore.factory)
+	require.NoError(t, err)
+
+	assert.Equal(t, "traefik/tls/sessiontickets/foo", foo.kvKey)
+
+	foo.sync()
+	bar.sync()
+
+	require.Len(t, foo.keys, 1)
+	assert.Equal(t, foo.keys, bar.keys)
+
+	// The shared keys are rotated by the first instance reading them once the interval is elapsed.
+	pair, err := kvStore.Get(foo.kvKey, nil)
+	require.NoError(t, err)
+
+	state := sessionTicketKeysState{Keys: [][]byte{foo.keys[0][:]}, RotatedAt: time.Now().Add(-2 * time.Hour)}
+	expired, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	_, _, err = kvStore.AtomicPut(foo.kvKey, expired, pair, nil)
+	require.NoError(t, err)
+
+	previous := foo.keys[0]
+
+	bar.sync()
+	foo.sync()
+
+	require.Len(t, foo.keys, 2)
+	assert.Equal(t, foo.keys, bar.keys)
+	assert.Equal(t, previous, foo.keys[1])
+}
+
+func TestSessionTicketKeys_kvUnavailable(t *testing.T) {
+	factory := func(_ store.Backend, _ []string, _, _ string, _ *tls.Config) (store.Store, error) {
+		return nil, errors.New("unavailable")
+	}
+
+	config := SessionTickets{
+		RotationInterval: types.Duration(time.Hour),
+		KV:               &SessionTicketsKV{Backend: "consul", Endpoints: []string{"localhost:8500"}},
+	}
+
+	keys, err := newSessionTicketKeys("foo", config, factory)
+	require.NoError(t, err)
+
+	// The failed sync is run synchronously, so that no other sync is started in the background for a sync interval.
+	keys.sync()
+	require.Empty(t, keys.keys)
+
+	first := keys.get()
+	require.Len(t, first, 1)
+
+	// The local keys are kept while the instances may still adopt the keys rotated in the KV store.
+	keys.rotatedAt = keys.rotatedAt.Add(-time.Hour)
+	assert.Equal(t, first, keys.get())
+
+	// The local keys are rotated once the KV store did not provide new keys in time.
+	keys.rotatedAt = keys.rotatedAt.Add(-sessionTicketKeysSyncInterval)
+
+	current := keys.get()
+	require.Len(t, current, 2)
+	assert.NotEqual(t, first[0], current[0])
+	assert.Equal(t, first[0], current[1])
+}
+
+func TestSessionTicketKeys_invalid(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         SessionTickets
+		kvStoreFactory KVStoreFactory
+	}{
+		{
+			desc:   "negative rotation interval",
+			config: SessionTickets{RotationInterval: types.Duration(-time.Hour)},
+		},
+		{
+			desc:           "unsupported KV backend",
+			config:         SessionTickets{KV: &SessionTicketsKV{Backend: "zookeeper"}},
+			kvStoreFactory: newFakeKVStore().factory,
+		},
+		{
+			desc:   "no KV store factory",
+			config: SessionTickets{KV: &SessionTicketsKV{Backend: "redis"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newSessionTicketKeys("foo", test.config, test.kvStoreFactory)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestManager_sessionTicketsShared(t *testing.T) {
+	kvStore := newFakeKVStore()
+
+	configs := map[string]Options{
+		"foo": {
+			SessionTickets: &SessionTickets{
+				RotationInterval: types.Duration(time.Hour),
+				KV:               &SessionTicketsKV{Backend: "redis", Endpoints: []string{"localhost:6379"}},
+			},
+		},
+	}
+
+	var tlsConfigs []*tls.Config
+	for i := 0; i < 2; i++ {
+		tlsManager := NewManager()
+		tlsManager.SetKVStoreFactory(kvStore.factory)
+		tlsManager.UpdateConfigs(context.Background(), nil, configs, nil)
+		tlsManager.sessionTickets["foo"].sync()
+
+		tlsConfig, err := tlsManager.Get("default", "foo")
+		require.NoError(t, err)
+
+		tlsConfigs = append(tlsConfigs, tlsConfig)
+	}
+
+	clientConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		MaxVersion:         tls.VersionTLS12,
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+
+	assert.False(t, resumedHandshake(t, tlsConfigs[0], clientConfig))
+	assert.True(t, resumedHandshake(t, tlsConfigs[1], clientConfig))
+}
+
+// resumedHandshake performs a TLS handshake with a server of the configuration,
+// and returns whether the client resumed its session.
+func resumedHandshake(t *testing.T, serverConfig, clientConfig *tls.Config) bool {
+	t.Helper()
+
+	serverConn, clientConn := net.Pipe()
+	defer func() { _ = clientConn.Close() }()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- tls.Server(serverConn, serverConfig).Handshake()
+		_ = serverConn.Close()
+	}()
+
+	client := tls.Client(clientConn, clientConfig)
+	require.NoError(t, client.Handshake())
+	require.NoError(t, <-serverErr)
+
+	return client.ConnectionState().DidResume
+}
@@ -0,0 +1,91 @@
+package tls
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// sniConfig is the TLS configuration selected for the server names matching its host patterns.
+type sniConfig struct {
+	hosts  []string
+	config *tls.Config
+}
+
+// buildSNIConfigs builds the TLS configurations of the options selected by server name, in the order of their selection.
+func (m *Manager) buildSNIConfigs(storeName string, sniOptions []SNIOptions) ([]sniConfig, error) {
+	var sniConfigs []sniConfig
+	for _, sni := range sniOptions {
+		if len(sni.Hosts) == 0 {
+			return nil, fmt.Errorf("invalid sniOptions: no hosts selecting the TLS options %s", sni.Options)
+		}
+
+		hosts := make([]string, len(sni.Hosts))
+		for i, host := range sni.Hosts {
+			hosts[i] = strings.ToLower(host)
+			if _, err := path.Match(hosts[i], ""); err != nil {
+				return nil, fmt.Errorf("invalid sniOptions host pattern %q: %w", host, err)
+			}
+		}
+
+		if sni.Options == "" {
+			return nil, errors.New("invalid sniOptions: no TLS options selected")
+		}
+
+		// The SNIOptions of the selected options are ignored, the server name being already matched.
+		config, err := m.get(storeName, sni.Options, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sniOptions: %w", err)
+		}
+
+		sniConfigs = append(sniConfigs, sniConfig{hosts: hosts, config: config})
+	}
+
+	return sniConfigs, nil
+}
+
+// matchSNIConfig returns the TLS configuration of the first host patterns matching the server name, if any.
+func matchSNIConfig(sniConfigs []sniConfig, serverName string) *tls.Config {
+	if len(sniConfigs) == 0 || serverName == "" {
+		return nil
+	}
+
+	serverName = strings.ToLower(serverName)
+	for _, sni := range sniConfigs {
+		for _, host := range sni.hosts {
+			if ok, _ := path.Match(host, serverName); ok {
+				return sni.config
+			}
+		}
+	}
+
+	return nil
+}
+
+// MatchesSNIOptions returns whether the server name matches the hosts of the sniOptions of any TLS options.
+// The requests to these hosts must be sent on a connection for the same server name,
+// as the TLS options they require are only selected during the TLS handshake.
+func (m *Manager) MatchesSNIOptions(serverName string) bool {
+	if serverName == "" {
+		return false
+	}
+
+	serverName = strings.ToLower(serverName)
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for _, options := range m.configs {
+		for _, sni := range options.SNIOptions {
+			for _, host := range sni.Hosts {
+				if ok, _ := path.Match(strings.ToLower(host), serverName); ok {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
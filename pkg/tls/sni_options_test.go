@@ -0,0 +1,112 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Get_sniOptions(t *testing.T) {
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{
+		"default": {
+			SNIOptions: []SNIOptions{
+				{Hosts: []string{"admin.*"}, Options: "strict"},
+				{Hosts: []string{"api.foo.bar", "*.api.foo.bar"}, Options: "modern"},
+			},
+		},
+		"strict": {
+			MinVersion: "VersionTLS13",
+			ClientAuth: ClientAuth{ClientAuthType: "RequestClientCert"},
+			SNIOptions: []SNIOptions{{Hosts: []string{"*"}, Options: "modern"}},
+		},
+		"modern": {
+			MinVersion: "VersionTLS12",
+		},
+	}, nil)
+
+	tlsConfig, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		serverName         string
+		expectedMinVersion uint16
+		expectedClientAuth tls.ClientAuthType
+	}{
+		{serverName: "foo.bar"},
+		{serverName: ""},
+		{serverName: "admin.foo.bar", expectedMinVersion: tls.VersionTLS13, expectedClientAuth: tls.RequestClientCert},
+		{serverName: "Admin.Foo.Bar", expectedMinVersion: tls.VersionTLS13, expectedClientAuth: tls.RequestClientCert},
+		{serverName: "api.foo.bar", expectedMinVersion: tls.VersionTLS12},
+		{serverName: "v1.api.foo.bar", expectedMinVersion: tls.VersionTLS12},
+		{serverName: "foo.admin.bar"},
+	}
+
+	for _, test := range testCases {
+		config, err := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{ServerName: test.serverName})
+		require.NoError(t, err)
+
+		assert.Equal(t, test.expectedMinVersion, config.MinVersion, test.serverName)
+		assert.Equal(t, test.expectedClientAuth, config.ClientAuth, test.serverName)
+	}
+}
+
+func TestManager_MatchesSNIOptions(t *testing.T) {
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{
+		"default": {
+			SNIOptions: []SNIOptions{{Hosts: []string{"admin.*"}, Options: "strict"}},
+		},
+		"strict": {
+			ClientAuth: ClientAuth{ClientAuthType: "RequestClientCert"},
+		},
+	}, nil)
+
+	assert.True(t, tlsManager.MatchesSNIOptions("admin.foo.bar"))
+	assert.True(t, tlsManager.MatchesSNIOptions("Admin.Foo.Bar"))
+	assert.False(t, tlsManager.MatchesSNIOptions("foo.bar"))
+	assert.False(t, tlsManager.MatchesSNIOptions(""))
+}
+
+func TestManager_Get_invalidSNIOptions(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		sniOptions SNIOptions
+	}{
+		{
+			desc:       "unknown TLS options",
+			sniOptions: SNIOptions{Hosts: []string{"admin.*"}, Options: "unknown"},
+		},
+		{
+			desc:       "invalid host pattern",
+			sniOptions: SNIOptions{Hosts: []string{"admin.["}, Options: "default"},
+		},
+		{
+			desc:       "no hosts",
+			sniOptions: SNIOptions{Options: "default"},
+		},
+		{
+			desc:       "no TLS options",
+			sniOptions: SNIOptions{Hosts: []string{"admin.*"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tlsManager := NewManager()
+			tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{
+				"default": {},
+				"foo":     {SNIOptions: []SNIOptions{test.sniOptions}},
+			}, nil)
+
+			_, err := tlsManager.Get("default", "foo")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/containous/traefik/v2/pkg/spiffe"
+)
+
+// SPIFFEBundleSource provides the trust bundles of the SPIFFE trust domains, as they are rotated.
+type SPIFFEBundleSource interface {
+	X509Bundle(trustDomain string) []*x509.Certificate
+}
+
+// spiffeClientAuth returns the client authentication type of the TLS configuration verifying the client X.509-SVIDs,
+// which are verified by the SPIFFE verifier instead of the TLS stack.
+func spiffeClientAuth(clientAuthType string) (tls.ClientAuthType, error) {
+	switch clientAuthType {
+	case "", "RequireAndVerifyClientCert":
+		return tls.RequireAnyClientCert, nil
+	case "VerifyClientCertIfGiven":
+		return tls.RequestClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("invalid clientAuthType: %s, the SPIFFE client authentication verifies the client certificates", clientAuthType)
+	}
+}
+
+// verifySPIFFEPeerCertificate returns the verifier of the client X.509-SVIDs,
+// checking them against the trust bundle of their trust domain, and checking that their SPIFFE ID is accepted.
+func verifySPIFFEPeerCertificate(config SPIFFE, source SPIFFEBundleSource) (func([][]byte, [][]*x509.Certificate) error, error) {
+	if len(config.IDs) == 0 && len(config.TrustDomains) == 0 {
+		return nil, errors.New("invalid clientAuth: the SPIFFE client authentication requires IDs or trust domains")
+	}
+
+	for _, pattern := range config.IDs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE ID pattern %q: %w", pattern, err)
+		}
+	}
+
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		// The client certificate is optional with VerifyClientCertIfGiven.
+		if len(rawCerts) == 0 {
+			return nil
+		}
+
+		chain := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			var err error
+			chain[i], err = x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("unable to parse the client certificate: %w", err)
+			}
+		}
+
+		leaf := chain[0]
+		if len(leaf.URIs) != 1 || leaf.IsCA {
+			return errors.New("the client certificate is not an X.509-SVID")
+		}
+
+		id := leaf.URIs[0].String()
+
+		trustDomain, err := spiffe.TrustDomain(id)
+		if err != nil {
+			return err
+		}
+
+		if !acceptsSPIFFEID(config, id, trustDomain) {
+			return fmt.Errorf("the SPIFFE ID %s is not accepted", id)
+		}
+
+		bundle := source.X509Bundle(trustDomain)
+		if len(bundle) == 0 {
+			return fmt.Errorf("no trust bundle for the trust domain %s", trustDomain)
+		}
+
+		opts := x509.VerifyOptions{
+			Roots:         x509.NewCertPool(),
+			Intermediates: x509.NewCertPool(),
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+
+		for _, cert := range bundle {
+			opts.Roots.AddCert(cert)
+		}
+
+		for _, cert := range chain[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+
+		if _, err := leaf.Verify(opts); err != nil {
+			return fmt.Errorf("unable to verify the X.509-SVID %s: %w", id, err)
+		}
+
+		return nil
+	}, nil
+}
+
+// acceptsSPIFFEID returns whether the SPIFFE ID matches one of the ID patterns, or belongs to one of the trust domains.
+func acceptsSPIFFEID(config SPIFFE, id, trustDomain string) bool {
+	for _, domain := range config.TrustDomains {
+		if domain == trustDomain {
+			return true
+		}
+	}
+
+	for _, pattern := range config.IDs {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,217 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spiffeBundles is a SPIFFEBundleSource holding the trust bundles by trust domain.
+type spiffeBundles map[string][]*x509.Certificate
+
+func (b spiffeBundles) X509Bundle(trustDomain string) []*x509.Certificate {
+	return b[trustDomain]
+}
+
+// newSPIFFECA returns a CA issuing X.509-SVIDs.
+func newSPIFFECA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "spiffe ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+// newClientSVID returns a client X.509-SVID of the SPIFFE ID, issued by the CA.
+func newClientSVID(t *testing.T, id string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	uri, err := url.Parse(id)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		URIs:         []*url.URL{uri},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// clientHandshake performs a TLS handshake with a server of the configuration, presenting the client certificates.
+func clientHandshake(t *testing.T, conf *tls.Config, clientCerts []tls.Certificate) error {
+	t.Helper()
+
+	cert, err := tls.X509KeyPair([]byte(localhostCert), []byte(localhostKey))
+	require.NoError(t, err)
+
+	conf.Certificates = []tls.Certificate{cert}
+
+	serverConn, clientConn := net.Pipe()
+	defer func() { _ = clientConn.Close() }()
+
+	server := tls.Server(serverConn, conf)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Handshake()
+		_ = serverConn.Close()
+	}()
+
+	client := tls.Client(clientConn, &tls.Config{InsecureSkipVerify: true, Certificates: clientCerts, MaxVersion: tls.VersionTLS12})
+	err = client.Handshake()
+
+	if sErr := <-serverErr; sErr != nil {
+		return sErr
+	}
+
+	return err
+}
+
+func TestBuildTLSConfig_spiffe(t *testing.T) {
+	ca, caKey := newSPIFFECA(t)
+	otherCA, otherCAKey := newSPIFFECA(t)
+
+	bundles := spiffeBundles{"example.org": {ca}, "example.com": {otherCA}}
+
+	testCases := []struct {
+		desc           string
+		clientAuth     ClientAuth
+		clientCerts    []tls.Certificate
+		expectedReject bool
+	}{
+		{
+			desc:        "accepted SPIFFE ID",
+			clientAuth:  ClientAuth{SPIFFE: &SPIFFE{IDs: []string{"spiffe://example.org/ns/*/sa/web"}}},
+			clientCerts: []tls.Certificate{newClientSVID(t, "spiffe://example.org/ns/default/sa/web", ca, caKey)},
+		},
+		{
+			desc:           "SPIFFE ID not accepted",
+			clientAuth:     ClientAuth{SPIFFE: &SPIFFE{IDs: []string{"spiffe://example.org/ns/*/sa/web"}}},
+			clientCerts:    []tls.Certificate{newClientSVID(t, "spiffe://example.org/ns/default/sa/api", ca, caKey)},
+			expectedReject: true,
+		},
+		{
+			desc:        "accepted trust domain",
+			clientAuth:  ClientAuth{SPIFFE: &SPIFFE{TrustDomains: []string{"example.com"}}},
+			clientCerts: []tls.Certificate{newClientSVID(t, "spiffe://example.com/api", otherCA, otherCAKey)},
+		},
+		{
+			desc:           "X.509-SVID not issued by the trust domain",
+			clientAuth:     ClientAuth{SPIFFE: &SPIFFE{TrustDomains: []string{"example.org"}}},
+			clientCerts:    []tls.Certificate{newClientSVID(t, "spiffe://example.org/api", otherCA, otherCAKey)},
+			expectedReject: true,
+		},
+		{
+			desc:           "unknown trust domain",
+			clientAuth:     ClientAuth{SPIFFE: &SPIFFE{TrustDomains: []string{"example.net"}}},
+			clientCerts:    []tls.Certificate{newClientSVID(t, "spiffe://example.net/api", ca, caKey)},
+			expectedReject: true,
+		},
+		{
+			desc:           "no client certificate",
+			clientAuth:     ClientAuth{SPIFFE: &SPIFFE{TrustDomains: []string{"example.org"}}},
+			expectedReject: true,
+		},
+		{
+			desc:       "no client certificate if given",
+			clientAuth: ClientAuth{ClientAuthType: "VerifyClientCertIfGiven", SPIFFE: &SPIFFE{TrustDomains: []string{"example.org"}}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := buildTLSConfig(Options{ClientAuth: test.clientAuth}, newCRLPool(), newOCSPCache(), bundles)
+			require.NoError(t, err)
+
+			err = clientHandshake(t, conf, test.clientCerts)
+			if test.expectedReject {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBuildTLSConfig_spiffeInvalid(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		clientAuth ClientAuth
+		bundles    SPIFFEBundleSource
+	}{
+		{
+			desc:       "no Workload API",
+			clientAuth: ClientAuth{SPIFFE: &SPIFFE{TrustDomains: []string{"example.org"}}},
+		},
+		{
+			desc:       "no IDs nor trust domains",
+			clientAuth: ClientAuth{SPIFFE: &SPIFFE{}},
+			bundles:    spiffeBundles{},
+		},
+		{
+			desc:       "invalid ID pattern",
+			clientAuth: ClientAuth{SPIFFE: &SPIFFE{IDs: []string{"spiffe://example.org/["}}},
+			bundles:    spiffeBundles{},
+		},
+		{
+			desc:       "CAFiles",
+			clientAuth: ClientAuth{CAFiles: []FileOrContent{FileOrContent(localhostCert)}, SPIFFE: &SPIFFE{TrustDomains: []string{"example.org"}}},
+			bundles:    spiffeBundles{},
+		},
+		{
+			desc:       "unverified client certificates",
+			clientAuth: ClientAuth{ClientAuthType: "RequireAnyClientCert", SPIFFE: &SPIFFE{TrustDomains: []string{"example.org"}}},
+			bundles:    spiffeBundles{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := buildTLSConfig(Options{ClientAuth: test.clientAuth}, newCRLPool(), newOCSPCache(), test.bundles)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package tls
 
+import "github.com/containous/traefik/v2/pkg/types"
+
 const certificateHeader = "-----BEGIN CERTIFICATE-----\n"
 
 // +k8s:deepcopy-gen=true
@@ -10,6 +12,36 @@ type ClientAuth struct {
 	// ClientAuthType defines the client authentication type to apply.
 	// The available values are: "NoClientCert", "RequestClientCert", "VerifyClientCertIfGiven" and "RequireAndVerifyClientCert".
 	ClientAuthType string `json:"clientAuthType,omitempty" toml:"clientAuthType,omitempty" yaml:"clientAuthType,omitempty"`
+	// CRLs defines the certificate revocation lists (files, or http(s) URLs) of the CAs, rejecting the revoked client certificates.
+	CRLs []string `json:"crls,omitempty" toml:"crls,omitempty" yaml:"crls,omitempty"`
+	// CRLRefreshInterval defines the interval at which the certificate revocation lists are loaded again (default: 1h).
+	CRLRefreshInterval types.Duration `json:"crlRefreshInterval,omitempty" toml:"crlRefreshInterval,omitempty" yaml:"crlRefreshInterval,omitempty"`
+	// OCSP defines the checks of the client certificates status against their OCSP responder.
+	OCSP *OCSP `json:"ocsp,omitempty" toml:"ocsp,omitempty" yaml:"ocsp,omitempty" label:"allowEmpty"`
+	// SPIFFE defines the verification of the client X.509-SVIDs against the trust bundles of the SPIFFE Workload API, instead of the CAFiles.
+	SPIFFE *SPIFFE `json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// SPIFFE defines the SPIFFE IDs of the accepted client X.509-SVIDs.
+type SPIFFE struct {
+	// IDs defines the accepted SPIFFE IDs, which can be patterns (such as spiffe://example.org/ns/*/sa/web).
+	IDs []string `json:"ids,omitempty" toml:"ids,omitempty" yaml:"ids,omitempty"`
+	// TrustDomains defines the trust domains whose SPIFFE IDs are all accepted.
+	TrustDomains []string `json:"trustDomains,omitempty" toml:"trustDomains,omitempty" yaml:"trustDomains,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// OCSP defines the checks of the client certificates status against their OCSP responder.
+type OCSP struct {
+	// HardFail rejects the client certificates whose status cannot be checked, which are accepted otherwise (soft-fail).
+	HardFail bool `json:"hardFail,omitempty" toml:"hardFail,omitempty" yaml:"hardFail,omitempty"`
+	// Responder overrides the OCSP responder URL of the client certificates.
+	Responder string `json:"responder,omitempty" toml:"responder,omitempty" yaml:"responder,omitempty"`
+	// CacheDuration defines how long the OCSP responses are cached at most, until their next update (default: 1h).
+	CacheDuration types.Duration `json:"cacheDuration,omitempty" toml:"cacheDuration,omitempty" yaml:"cacheDuration,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -23,6 +55,73 @@ type Options struct {
 	ClientAuth               ClientAuth `json:"clientAuth,omitempty" toml:"clientAuth,omitempty" yaml:"clientAuth,omitempty"`
 	SniStrict                bool       `json:"sniStrict,omitempty" toml:"sniStrict,omitempty" yaml:"sniStrict,omitempty" export:"true"`
 	PreferServerCipherSuites bool       `json:"preferServerCipherSuites,omitempty" toml:"preferServerCipherSuites,omitempty" yaml:"preferServerCipherSuites,omitempty" export:"true"`
+	// SessionTickets defines the keys encrypting the session tickets, which are otherwise generated by each Traefik instance.
+	SessionTickets *SessionTickets `json:"sessionTickets,omitempty" toml:"sessionTickets,omitempty" yaml:"sessionTickets,omitempty" label:"allowEmpty"`
+	// SNIOptions selects other TLS options for the server names matching their hosts, the first matching ones being selected.
+	SNIOptions []SNIOptions `json:"sniOptions,omitempty" toml:"sniOptions,omitempty" yaml:"sniOptions,omitempty"`
+	// ClientHelloDeny defines the rules rejecting the handshakes of the matching ClientHellos, before any certificate is selected.
+	ClientHelloDeny *ClientHelloDeny `json:"clientHelloDeny,omitempty" toml:"clientHelloDeny,omitempty" yaml:"clientHelloDeny,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ClientHelloDeny defines the rules rejecting the handshakes of the ClientHellos matching any of them.
+type ClientHelloDeny struct {
+	// ServerNames defines the denied server names, which can be patterns (such as *.internal).
+	ServerNames []string `json:"serverNames,omitempty" toml:"serverNames,omitempty" yaml:"serverNames,omitempty"`
+	// NoServerName denies the ClientHellos without server name.
+	NoServerName bool `json:"noServerName,omitempty" toml:"noServerName,omitempty" yaml:"noServerName,omitempty" export:"true"`
+	// InsecureCipherSuitesOnly denies the ClientHellos offering only insecure cipher suites (such as RC4, or 3DES ones).
+	InsecureCipherSuitesOnly bool `json:"insecureCipherSuitesOnly,omitempty" toml:"insecureCipherSuitesOnly,omitempty" yaml:"insecureCipherSuitesOnly,omitempty" export:"true"`
+	// JA3 defines the denied JA3 fingerprints of the ClientHellos.
+	JA3 []string `json:"ja3,omitempty" toml:"ja3,omitempty" yaml:"ja3,omitempty"`
+	// Response defines how the denied handshakes are rejected:
+	// "close" closes the connection (default), "unrecognizedName" sends an unrecognized_name alert first.
+	Response string `json:"response,omitempty" toml:"response,omitempty" yaml:"response,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// SNIOptions selects TLS options for the server names matching the hosts.
+type SNIOptions struct {
+	// Hosts defines the server names, which can be patterns (such as admin.*).
+	Hosts []string `json:"hosts,omitempty" toml:"hosts,omitempty" yaml:"hosts,omitempty"`
+	// Options is the name of the selected TLS options, whose own SNIOptions are ignored.
+	Options string `json:"options,omitempty" toml:"options,omitempty" yaml:"options,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// SessionTickets defines the rotation of the keys encrypting the session tickets, and the KV store sharing them.
+type SessionTickets struct {
+	// RotationInterval defines the interval at which a new key encrypts the session tickets (default: 12h).
+	// The session tickets encrypted by the previous keys are accepted for two more intervals.
+	RotationInterval types.Duration `json:"rotationInterval,omitempty" toml:"rotationInterval,omitempty" yaml:"rotationInterval,omitempty" export:"true"`
+	// KV defines the KV store sharing the keys between the Traefik instances, resuming the sessions across all of them.
+	KV *SessionTicketsKV `json:"kv,omitempty" toml:"kv,omitempty" yaml:"kv,omitempty"`
+}
+
+// SetDefaults sets the default values on a SessionTickets.
+func (s *SessionTickets) SetDefaults() {
+	s.RotationInterval = types.Duration(defaultSessionTicketsRotationInterval)
+}
+
+// +k8s:deepcopy-gen=true
+
+// SessionTicketsKV defines the KV store sharing the session ticket keys.
+type SessionTicketsKV struct {
+	// Backend is the type of KV store: consul, etcd, or redis.
+	Backend   string           `json:"backend,omitempty" toml:"backend,omitempty" yaml:"backend,omitempty" export:"true"`
+	Endpoints []string         `json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	RootKey   string           `json:"rootKey,omitempty" toml:"rootKey,omitempty" yaml:"rootKey,omitempty"`
+	Username  string           `json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty"`
+	Password  string           `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" secret:"true"`
+	TLS       *types.ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+// SetDefaults sets the default values on a SessionTicketsKV.
+func (s *SessionTicketsKV) SetDefaults() {
+	s.RootKey = defaultSessionTicketsRootKey
 }
 
 // +k8s:deepcopy-gen=true
@@ -31,6 +130,23 @@ type Options struct {
 type Store struct {
 	DefaultCertificate  *Certificate   `json:"defaultCertificate,omitempty" toml:"defaultCertificate,omitempty" yaml:"defaultCertificate,omitempty"`
 	DefaultCertificates []*Certificate `json:"defaultCertificates,omitempty" toml:"defaultCertificates,omitempty" yaml:"defaultCertificates,omitempty"`
+	MatchStrategy       string         `json:"matchStrategy,omitempty" toml:"matchStrategy,omitempty" yaml:"matchStrategy,omitempty" export:"true"`
+	// GenerateDefaultFallback defines whether a default RSA certificate is generated for the clients only supporting RSA,
+	// when none of the default certificates is an RSA certificate (default: true).
+	GenerateDefaultFallback *bool `json:"generateDefaultFallback,omitempty" toml:"generateDefaultFallback,omitempty" yaml:"generateDefaultFallback,omitempty" export:"true"`
+	// DefaultCA defines the CA minting the default certificates served to the server names without certificate,
+	// instead of the default certificates of the store.
+	DefaultCA *DefaultCA `json:"defaultCA,omitempty" toml:"defaultCA,omitempty" yaml:"defaultCA,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// DefaultCA defines the CA minting the default certificates of a store.
+type DefaultCA struct {
+	CertFile FileOrContent `json:"certFile,omitempty" toml:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile  FileOrContent `json:"keyFile,omitempty" toml:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	// Validity defines the validity duration of the minted certificates (default: 24h), which are cached for half of it.
+	Validity types.Duration `json:"validity,omitempty" toml:"validity,omitempty" yaml:"validity,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -2,13 +2,19 @@ package tls
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"reflect"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/memprotect"
 	"github.com/containous/traefik/v2/pkg/tls/certificate"
 	"github.com/containous/traefik/v2/pkg/tls/generate"
 	"github.com/containous/traefik/v2/pkg/types"
@@ -21,12 +27,58 @@ var DefaultTLSOptions = Options{}
 
 // Manager is the TLS option/store/configuration factory
 type Manager struct {
-	storesConfig  map[string]Store
-	stores        map[string]*CertificateStore
-	configs       map[string]Options
-	certs         []*CertAndStores
-	TLSAlpnGetter func(string) (*tls.Certificate, error)
-	lock          sync.RWMutex
+	storesConfig map[string]Store
+	stores       map[string]*CertificateStore
+	configs      map[string]Options
+	certs        []*CertAndStores
+	lock         sync.RWMutex
+
+	// resolvers are consulted in order, before the store, to resolve the certificate served during a TLS handshake.
+	resolvers []CertificateResolver
+	// unknownServerNameListeners are notified of the server names of the TLS handshakes for which no certificate is found.
+	unknownServerNameListeners []func(serverName string)
+
+	// parsedCerts holds the parsed dynamic certificates, keyed by the fingerprint of their contents,
+	// so that the certificates which did not change are not parsed again on each update.
+	parsedCerts map[string]parsedCertificate
+	// storesFingerprint holds the fingerprint of the configuration of the stores,
+	// so that the stores which did not change are not built again on each update.
+	storesFingerprint map[string]string
+
+	zeroizeKeys  bool
+	zeroizeDelay time.Duration
+
+	// lazyCertificates makes the private keys of the dynamic certificates parsed on their first use.
+	lazyCertificates bool
+
+	// sctChecks makes the descriptions of the certificates check their Signed Certificate Timestamps.
+	sctChecks bool
+
+	crls          *crlPool
+	ocspResponses *ocspCache
+
+	// certsInfo describes the certificates of the stores, as of the last update,
+	// and certsDescriptions caches the descriptions of these certificates, which are not parsed again on each update.
+	certsInfo         []CertificateInfo
+	certsDescriptions map[*tls.Certificate]*certificateDescription
+	certsListeners    []func(CertificatesEvent)
+
+	// spiffeBundles provides the trust bundles verifying the client X.509-SVIDs.
+	spiffeBundles SPIFFEBundleSource
+
+	// sessionTickets holds the session ticket keys of the TLS options defining them,
+	// shared in the KV stores created by kvStoreFactory.
+	sessionTickets map[string]*sessionTicketKeys
+	kvStoreFactory KVStoreFactory
+
+	// handshakeErrors counts, and samples, the TLS handshake failures reported by the routers.
+	handshakeErrors *handshakeErrors
+}
+
+// parsedCertificate is a parsed dynamic certificate.
+type parsedCertificate struct {
+	key  certificateKey
+	cert *tls.Certificate
 }
 
 // NewManager creates a new Manager
@@ -36,31 +88,232 @@ func NewManager() *Manager {
 		configs: map[string]Options{
 			"default": DefaultTLSOptions,
 		},
+		crls:            newCRLPool(),
+		ocspResponses:   newOCSPCache(),
+		handshakeErrors: newHandshakeErrors(),
 	}
 }
 
-// UpdateConfigs updates the TLS* configuration options
+// SetCRLStorage sets the directory where the downloaded certificate revocation lists are saved,
+// to be used after a restart, until they are downloaded again.
+func (m *Manager) SetCRLStorage(storage string) {
+	m.crls.setStorage(storage)
+}
+
+// AddCertificateResolver adds a resolver, consulted after the resolvers already added,
+// and before the store, to resolve the certificate served during a TLS handshake.
+// It applies to the TLS configurations returned by Get afterwards.
+func (m *Manager) AddCertificateResolver(resolver CertificateResolver) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.resolvers = append(m.resolvers, resolver)
+}
+
+// AddUnknownServerNameListener adds a listener notified of the server name of each TLS handshake for which no certificate is found,
+// whether the default certificate is served, or the handshake is refused by the strict SNI.
+// It applies to the TLS configurations returned by Get afterwards.
+// The listeners are called during the handshakes, and must not block.
+func (m *Manager) AddUnknownServerNameListener(listener func(serverName string)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.unknownServerNameListeners = append(m.unknownServerNameListeners, listener)
+}
+
+// SetSPIFFEBundleSource sets the source of the trust bundles verifying the client X.509-SVIDs,
+// required by the TLS options with the SPIFFE client authentication.
+func (m *Manager) SetSPIFFEBundleSource(source SPIFFEBundleSource) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.spiffeBundles = source
+}
+
+// SetKVStoreFactory sets the factory of the KV stores sharing the session ticket keys between the Traefik instances,
+// required by the TLS options with session tickets shared in a KV store.
+func (m *Manager) SetKVStoreFactory(factory KVStoreFactory) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.kvStoreFactory = factory
+}
+
+// AddCertificatesListener adds a listener notified of the certificates added to, and removed from, the stores by each update,
+// which are described by CertificatesInfo when the listener is added.
+// The listeners are called sequentially, once the update is applied, and must not block.
+func (m *Manager) AddCertificatesListener(listener func(CertificatesEvent)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.certsListeners = append(m.certsListeners, listener)
+}
+
+// EnableKeyZeroization makes the manager zeroize the private keys of the certificates it replaces,
+// after the delay letting the TLS handshakes in progress complete.
+func (m *Manager) EnableKeyZeroization(delay time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.zeroizeKeys = true
+	m.zeroizeDelay = delay
+}
+
+// EnableLazyCertificates makes the manager parse the private keys of the dynamic certificates on their first use,
+// so that the many certificates which are rarely used are not parsed on each update.
+func (m *Manager) EnableLazyCertificates() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.lazyCertificates = true
+}
+
+// EnableSCTChecks makes the manager check that the certificates of the stores carry the Signed Certificate Timestamps
+// required by the certificate transparency policy of the browsers, when they are added to the stores,
+// logging the certificates which do not, and describing the result in CertificatesInfo.
+func (m *Manager) EnableSCTChecks() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.sctChecks = true
+}
+
+// ZeroizeKeys zeroizes the private keys of all the certificates of the manager, which must not be used anymore.
+func (m *Manager) ZeroizeKeys() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	memprotect.ZeroizeCertificates(m.certificates())
+}
+
+// UpdateConfigs updates the TLS* configuration options.
+// The stores and the certificates which did not change are kept as they are,
+// so that only the changed certificates are added to, or removed from, the stores.
+// The certificates listeners are then notified of the certificates added to, and removed from, the stores.
 func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, configs map[string]Options, certs []*CertAndStores) {
+	event, listeners := m.updateConfigs(ctx, stores, configs, certs)
+	if len(event.Added) == 0 && len(event.Removed) == 0 {
+		return
+	}
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+func (m *Manager) updateConfigs(ctx context.Context, stores map[string]Store, configs map[string]Options, certs []*CertAndStores) (CertificatesEvent, []func(CertificatesEvent)) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
+	previousCerts := m.certificates()
+
 	m.configs = configs
 	m.storesConfig = stores
 	m.certs = certs
 
-	m.stores = make(map[string]*CertificateStore)
-	for storeName, storeConfig := range m.storesConfig {
+	m.updateSessionTickets(ctx, configs)
+
+	parser := m.newCertificateParser()
+
+	m.updateStores(ctx, stores, parser)
+	m.updateDynamicCerts(ctx, certs, parser)
+
+	m.parsedCerts = parser.parsedCertificates()
+
+	if m.zeroizeKeys {
+		removed := removedCertificates(previousCerts, m.certificates())
+		if len(removed) > 0 {
+			time.AfterFunc(m.zeroizeDelay, func() {
+				memprotect.ZeroizeCertificates(removed)
+			})
+		}
+	}
+
+	previousInfos := m.certsInfo
+	m.certsInfo = m.describeCertificates(ctx)
+
+	return diffCertificatesInfo(previousInfos, m.certsInfo), m.certsListeners
+}
+
+// updateSessionTickets creates the session ticket keys of the TLS options whose session tickets configuration changed,
+// and keeps the other ones, which are still used by the TLS connections resuming their sessions.
+func (m *Manager) updateSessionTickets(ctx context.Context, configs map[string]Options) {
+	sessionTickets := make(map[string]*sessionTicketKeys)
+
+	for name, config := range configs {
+		if config.SessionTickets == nil {
+			continue
+		}
+
+		if keys, ok := m.sessionTickets[name]; ok && reflect.DeepEqual(keys.config, *config.SessionTickets) {
+			sessionTickets[name] = keys
+			continue
+		}
+
+		keys, err := newSessionTicketKeys(name, *config.SessionTickets, m.kvStoreFactory)
+		if err != nil {
+			log.FromContext(ctx).Errorf("Invalid session tickets of the TLS options %s: %v", name, err)
+			continue
+		}
+		sessionTickets[name] = keys
+	}
+
+	for name, keys := range m.sessionTickets {
+		if sessionTickets[name] != keys {
+			keys.close()
+		}
+	}
+
+	m.sessionTickets = sessionTickets
+}
+
+// updateStores builds the stores whose configuration changed, and keeps the other ones.
+func (m *Manager) updateStores(ctx context.Context, storesConfig map[string]Store, parser *certificateParser) {
+	stores := make(map[string]*CertificateStore)
+	fingerprints := make(map[string]string)
+
+	// The stores created on demand, which are not configured, are kept.
+	for storeName, store := range m.stores {
+		_, configured := m.storesFingerprint[storeName]
+		if _, ok := storesConfig[storeName]; !ok && !configured {
+			stores[storeName] = store
+		}
+	}
+
+	for storeName, storeConfig := range storesConfig {
 		ctxStore := log.With(ctx, log.Str(log.TLSStoreName, storeName))
-		store, err := buildCertificateStore(ctxStore, storeConfig)
+
+		fingerprint := storeFingerprint(storeConfig)
+		if store, ok := m.stores[storeName]; ok && fingerprint != "" && m.storesFingerprint[storeName] == fingerprint {
+			// The default certificates of the kept store are still shared with the other stores.
+			for _, cert := range defaultCertificates(storeConfig) {
+				_, _ = parser.parseDefault(cert)
+			}
+
+			stores[storeName] = store
+			fingerprints[storeName] = fingerprint
+			continue
+		}
+
+		store, err := buildCertificateStore(ctxStore, storeConfig, parser)
 		if err != nil {
 			log.FromContext(ctxStore).Errorf("Error while creating certificate store: %v", err)
 			continue
 		}
-		m.stores[storeName] = store
+		stores[storeName] = store
+		fingerprints[storeName] = fingerprint
 	}
 
+	m.stores = stores
+	m.storesFingerprint = fingerprints
+}
+
+// updateDynamicCerts parses the dynamic certificates which changed, and updates the stores whose certificates changed.
+func (m *Manager) updateDynamicCerts(ctx context.Context, certs []*CertAndStores, parser *certificateParser) {
+	results := m.parseCertificates(certs, parser)
+
 	storesCertificates := make(map[string]map[certificateKey]*tls.Certificate)
-	for _, conf := range certs {
+	for i, conf := range certs {
 		if len(conf.Stores) == 0 {
 			if log.GetLevel() >= logrus.DebugLevel {
 				log.FromContext(ctx).Debugf("No store is defined to add the certificate %s, it will be added to the default store.",
@@ -68,17 +321,230 @@ func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, co
 			}
 			conf.Stores = []string{"default"}
 		}
+
+		parsed, err := results[i].parsed, results[i].err
 		for _, store := range conf.Stores {
-			ctxStore := log.With(ctx, log.Str(log.TLSStoreName, store))
-			if err := conf.Certificate.AppendCertificate(storesCertificates, store); err != nil {
+			if err != nil {
+				ctxStore := log.With(ctx, log.Str(log.TLSStoreName, store))
 				log.FromContext(ctxStore).Errorf("Unable to append certificate %s to store: %v", conf.Certificate.GetTruncatedCertificateName(), err)
+				continue
 			}
+
+			appendCertificate(storesCertificates, store, parsed.key, parsed.cert)
 		}
 	}
 
 	for storeName, certs := range storesCertificates {
-		m.getStore(storeName).DynamicCerts.Set(certs)
+		m.setDynamicCerts(m.getStore(storeName), certs)
+	}
+
+	for storeName, store := range m.stores {
+		if _, ok := storesCertificates[storeName]; !ok {
+			m.setDynamicCerts(store, make(map[certificateKey]*tls.Certificate))
+		}
+	}
+}
+
+// parseCertificates parses the dynamic certificates concurrently, with a bounded number of workers,
+// and returns the results in the order of the certificates.
+func (m *Manager) parseCertificates(certs []*CertAndStores, parser *certificateParser) []parseResult {
+	results := make([]parseResult, len(certs))
+
+	workers := runtime.NumCPU()
+	if workers > len(certs) {
+		workers = len(certs)
 	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				results[index].parsed, results[index].err = parser.parse(certs[index].Certificate)
+			}
+		}()
+	}
+
+	for index := range certs {
+		indexes <- index
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	return results
+}
+
+// parseResult is the result of the parsing of a dynamic certificate.
+type parseResult struct {
+	parsed parsedCertificate
+	err    error
+}
+
+// certificateParser parses the certificates of an update, keyed by the fingerprint of their contents.
+// The certificates with the same contents, whether they are dynamic certificates or default certificates of the stores,
+// are parsed once and shared by all the stores referencing them, and the ones which did not change are not parsed again.
+type certificateParser struct {
+	previous map[string]parsedCertificate
+	lazy     bool
+
+	mu    sync.Mutex
+	calls map[string]*parseCall
+}
+
+// parseCall is the parsing of the certificates with the same fingerprint, done once.
+type parseCall struct {
+	once   sync.Once
+	parsed parsedCertificate
+	err    error
+}
+
+func (m *Manager) newCertificateParser() *certificateParser {
+	return &certificateParser{
+		previous: m.parsedCerts,
+		lazy:     m.lazyCertificates,
+		calls:    make(map[string]*parseCall),
+	}
+}
+
+// parse returns the parsed certificate, parsing it only if it is not already parsed.
+func (p *certificateParser) parse(cert Certificate) (parsedCertificate, error) {
+	certContent, keyContent, err := cert.read()
+	if err != nil {
+		return parsedCertificate{}, err
+	}
+
+	fingerprint := certificateFingerprint(certContent, keyContent)
+
+	p.mu.Lock()
+	call, ok := p.calls[fingerprint]
+	if !ok {
+		call = &parseCall{}
+		p.calls[fingerprint] = call
+	}
+	p.mu.Unlock()
+
+	call.once.Do(func() {
+		if parsed, ok := p.previous[fingerprint]; ok {
+			call.parsed = parsed
+			return
+		}
+
+		if p.lazy {
+			call.parsed.key, call.parsed.cert, call.err = parseLazyCertificate(certContent, keyContent)
+			return
+		}
+
+		call.parsed.key, call.parsed.cert, call.err = parseCertificate(certContent, keyContent)
+	})
+
+	return call.parsed, call.err
+}
+
+// parseDefault returns the parsed default certificate of a store, whose private key is always parsed.
+func (p *certificateParser) parseDefault(cert *Certificate) (*tls.Certificate, error) {
+	parsed, err := p.parse(*cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load X509 key pair: %v", err)
+	}
+
+	if key, ok := parsed.cert.PrivateKey.(*lazyKey); ok {
+		if _, err := key.parse(); err != nil {
+			return nil, fmt.Errorf("failed to load X509 key pair: %v", err)
+		}
+	}
+
+	return parsed.cert, nil
+}
+
+// parsedCertificates returns the successfully parsed certificates, keyed by their fingerprint.
+func (p *certificateParser) parsedCertificates() map[string]parsedCertificate {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	parsedCerts := make(map[string]parsedCertificate, len(p.calls))
+	for fingerprint, call := range p.calls {
+		if call.err == nil {
+			parsedCerts[fingerprint] = call.parsed
+		}
+	}
+
+	return parsedCerts
+}
+
+// setDynamicCerts sets the dynamic certificates of the store, and resets its cache, if they changed.
+func (m *Manager) setDynamicCerts(store *CertificateStore, certs map[certificateKey]*tls.Certificate) {
+	current, _ := store.DynamicCerts.Get().(map[certificateKey]*tls.Certificate)
+	if sameCertificates(current, certs) {
+		return
+	}
+
+	store.DynamicCerts.Set(certs)
+	store.ResetCache()
+}
+
+func sameCertificates(a, b map[certificateKey]*tls.Certificate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for key, cert := range a {
+		if b[key] != cert {
+			return false
+		}
+	}
+
+	return true
+}
+
+// storeFingerprint returns the fingerprint of the configuration of a store, including the contents of its default certificates,
+// or an empty string if they cannot be read.
+func storeFingerprint(store Store) string {
+	defaultCerts := store.DefaultCertificates
+	if store.DefaultCertificate != nil {
+		defaultCerts = append([]*Certificate{store.DefaultCertificate}, defaultCerts...)
+	}
+
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%t,%d,%s,%t;", store.DefaultCertificate != nil, len(store.DefaultCertificates), store.MatchStrategy, generateDefaultFallback(store))
+	if store.DefaultCA != nil {
+		defaultCA := &Certificate{CertFile: store.DefaultCA.CertFile, KeyFile: store.DefaultCA.KeyFile}
+		certContent, keyContent, err := defaultCA.read()
+		if err != nil {
+			return ""
+		}
+		_, _ = fmt.Fprintf(hash, "ca:%s,%d;", certificateFingerprint(certContent, keyContent), store.DefaultCA.Validity)
+	}
+	for _, cert := range defaultCerts {
+		certContent, keyContent, err := cert.read()
+		if err != nil {
+			return ""
+		}
+		_, _ = fmt.Fprintf(hash, "%s;", certificateFingerprint(certContent, keyContent))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// removedCertificates returns the previous certificates which are not in the current certificates.
+func removedCertificates(previous, current []*tls.Certificate) []*tls.Certificate {
+	kept := make(map[*tls.Certificate]struct{}, len(current))
+	for _, cert := range current {
+		kept[cert] = struct{}{}
+	}
+
+	var removed []*tls.Certificate
+	for _, cert := range previous {
+		if _, ok := kept[cert]; !ok {
+			removed = append(removed, cert)
+		}
+	}
+
+	return removed
 }
 
 func isChaChaCipherSuite(cipherSuite uint16) bool {
@@ -92,6 +558,12 @@ func (m *Manager) Get(storeName string, configName string) (*tls.Config, error)
 	m.lock.RLock()
 	defer m.lock.RUnlock()
 
+	return m.get(storeName, configName, true)
+}
+
+// get builds the TLS configuration of the store and the configuration,
+// selecting the configurations of its SNIOptions by server name if withSNIOptions is true.
+func (m *Manager) get(storeName string, configName string, withSNIOptions bool) (*tls.Config, error) {
 	var tlsConfig *tls.Config
 	var err error
 
@@ -102,9 +574,10 @@ func (m *Manager) Get(storeName string, configName string) (*tls.Config, error)
 	}
 
 	store := m.getStore(storeName)
+	sessionTickets := m.sessionTickets[configName]
 
 	if err == nil {
-		tlsConfig, err = buildTLSConfig(config)
+		tlsConfig, err = buildTLSConfig(config, m.crls, m.ocspResponses, m.spiffeBundles)
 		if err != nil {
 			tlsConfig = &tls.Config{}
 		}
@@ -144,9 +617,31 @@ func (m *Manager) Get(storeName string, configName string) (*tls.Config, error)
 		return tlsConfig, nil
 	}
 
+	var denier *clientHelloDenier
+	if err == nil {
+		denier, err = newClientHelloDenier(config.ClientHelloDeny)
+	}
+
+	var sniConfigs []sniConfig
+	if err == nil && withSNIOptions {
+		sniConfigs, err = m.buildSNIConfigs(storeName, config.SNIOptions)
+	}
+
 	tlsConfig.GetConfigForClient = func(clientHello *tls.ClientHelloInfo) (*tls.Config, error) {
+		if err := denier.check(clientHello); err != nil {
+			return nil, err
+		}
+
+		if sniConfig := matchSNIConfig(sniConfigs, clientHello.ServerName); sniConfig != nil {
+			return sniConfig.GetConfigForClient(clientHello)
+		}
+
 		config := tlsConfig.Clone()
 
+		if sessionTickets != nil {
+			config.SetSessionTicketKeys(sessionTickets.get())
+		}
+
 		if tlsConfig.CipherSuites != nil && len(tlsConfig.CipherSuites) > 0 {
 			if clientHello.CipherSuites != nil && len(clientHello.CipherSuites) > 0 {
 				// does the client have hardware acceleration or does it prefer ChaCha?
@@ -178,59 +673,68 @@ func (m *Manager) Get(storeName string, configName string) (*tls.Config, error)
 		return config, nil
 	}
 
+	resolvers := make([]CertificateResolver, 0, len(m.resolvers)+1)
+	resolvers = append(resolvers, m.resolvers...)
+	resolvers = append(resolvers, store)
+
+	unknownServerNameListeners := append([]func(string){}, m.unknownServerNameListeners...)
+
 	tlsConfig.GetCertificate = func(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
 		domainToCheck := types.CanonicalDomain(clientHello.ServerName)
 
-		if m.TLSAlpnGetter != nil {
-			cert, err := m.TLSAlpnGetter(domainToCheck)
-			if err != nil {
-				return nil, err
-			}
+		resolvedCertificate, err := resolveCertificate(resolvers, clientHello)
+		if err != nil {
+			return nil, err
+		}
 
-			if cert != nil {
-				return cert, nil
-			}
+		if resolvedCertificate != nil {
+			return resolvedCertificate, nil
 		}
 
-		bestCertificate := store.GetBestCertificate(clientHello)
-		if bestCertificate != nil {
-			return bestCertificate, nil
+		if domainToCheck != "" {
+			for _, listener := range unknownServerNameListeners {
+				listener(domainToCheck)
+			}
 		}
 
 		if m.configs[configName].SniStrict {
-			return nil, fmt.Errorf("strict SNI enabled - No certificate found for domain: %q, closing connection", domainToCheck)
+			return nil, &strictSNIError{domain: domainToCheck}
 		}
 
 		log.WithoutContext().Debugf("Serving default certificate for request: %q", domainToCheck)
-		preferredType := getCertTypeForClientHello(clientHello)
-		var matchingCert *tls.Certificate
-		for _, cert := range store.DefaultCertificates {
-			certType, err := certificate.GetCertificateType(cert)
-			if err != nil {
-				log.WithoutContext().Debug("Ignoring certificate of which the type can not be detected")
-				continue
-			}
-			switch {
-			case certType == certificate.EC && preferredType == certificate.EC:
-				matchingCert = cert
-				return matchingCert, nil
-			case certType == certificate.RSA:
-				matchingCert = cert
-				if preferredType == certificate.RSA {
-					return matchingCert, nil
-				}
-			}
-		}
-		return matchingCert, nil
+		return store.defaultCertificateFor(domainToCheck, getCertTypeForClientHello(clientHello)), nil
 	}
 
 	return tlsConfig, err
 }
 
+// certificates returns the default and dynamic certificates of all the stores.
+func (m *Manager) certificates() []*tls.Certificate {
+	var certs []*tls.Certificate
+	for _, store := range m.stores {
+		certs = append(certs, storeCertificates(store)...)
+	}
+
+	return certs
+}
+
+// storeCertificates returns the default and dynamic certificates of a store.
+func storeCertificates(store *CertificateStore) []*tls.Certificate {
+	certs := append([]*tls.Certificate{}, store.DefaultCertificates...)
+
+	if dynamicCerts, ok := store.DynamicCerts.Get().(map[certificateKey]*tls.Certificate); ok {
+		for _, cert := range dynamicCerts {
+			certs = append(certs, cert)
+		}
+	}
+
+	return certs
+}
+
 func (m *Manager) getStore(storeName string) *CertificateStore {
 	_, ok := m.stores[storeName]
 	if !ok {
-		m.stores[storeName], _ = buildCertificateStore(context.Background(), Store{})
+		m.stores[storeName], _ = buildCertificateStore(context.Background(), Store{}, m.newCertificateParser())
 	}
 	return m.stores[storeName]
 }
@@ -243,24 +747,35 @@ func (m *Manager) GetStore(storeName string) *CertificateStore {
 	return m.getStore(storeName)
 }
 
-func buildCertificateStore(ctx context.Context, tlsStore Store) (*CertificateStore, error) {
+func buildCertificateStore(ctx context.Context, tlsStore Store, parser *certificateParser) (*CertificateStore, error) {
 	certificateStore := NewCertificateStore()
 	certificateStore.DynamicCerts.Set(make(map[certificateKey]*tls.Certificate))
 
-	hasRSACertificate := false
+	switch tlsStore.MatchStrategy {
+	case "", MatchStrategyLongestMatch, MatchStrategyExactFirst:
+		certificateStore.MatchStrategy = tlsStore.MatchStrategy
+	default:
+		return certificateStore, fmt.Errorf("invalid match strategy: %s", tlsStore.MatchStrategy)
+	}
 
-	if len(tlsStore.DefaultCertificates) > 0 {
-		cert, err := buildDefaultCertificates(tlsStore.DefaultCertificates)
+	if tlsStore.DefaultCA != nil {
+		defaultCA, err := newMintingCA(*tlsStore.DefaultCA)
 		if err != nil {
 			return certificateStore, err
 		}
-		certificateStore.DefaultCertificates = cert
-	} else if tlsStore.DefaultCertificate != nil {
-		cert, err := buildDefaultCertificate(tlsStore.DefaultCertificate)
-		if err != nil {
-			return certificateStore, err
+		certificateStore.defaultCA = defaultCA
+	}
+
+	hasRSACertificate := false
+
+	if certs := defaultCertificates(tlsStore); len(certs) > 0 {
+		for _, cert := range certs {
+			builtCert, err := parser.parseDefault(cert)
+			if err != nil {
+				return certificateStore, err
+			}
+			certificateStore.DefaultCertificates = append(certificateStore.DefaultCertificates, builtCert)
 		}
-		certificateStore.DefaultCertificates = []*tls.Certificate{cert}
 	} else {
 		log.FromContext(ctx).Debug("No default certificates configured, generating")
 		rsaCert, err := generate.DefaultCertificate(certificate.RSA)
@@ -294,7 +809,12 @@ func buildCertificateStore(ctx context.Context, tlsStore Store) (*CertificateSto
 	}
 
 	// if no RSA certificate was added or generated, generate one to avoid errors
-	// with clients only supporting RSA.
+	// with clients only supporting RSA, unless the store disables it.
+	if !hasRSACertificate && !generateDefaultFallback(tlsStore) {
+		log.FromContext(ctx).Info("No default RSA certificate configured, and generateDefaultFallback is disabled: the clients only supporting RSA are not served any default certificate")
+		return certificateStore, nil
+	}
+
 	if !hasRSACertificate {
 		log.FromContext(ctx).Debug("No default RSA certificate configured, generating")
 		cert, err := generate.DefaultCertificate(certificate.RSA)
@@ -308,7 +828,12 @@ func buildCertificateStore(ctx context.Context, tlsStore Store) (*CertificateSto
 }
 
 // creates a TLS config that allows terminating HTTPS for multiple domains using SNI
-func buildTLSConfig(tlsOption Options) (*tls.Config, error) {
+// verifiesClientCert returns whether the client authentication type verifies the client certificates.
+func verifiesClientCert(clientAuth tls.ClientAuthType) bool {
+	return clientAuth == tls.VerifyClientCertIfGiven || clientAuth == tls.RequireAndVerifyClientCert
+}
+
+func buildTLSConfig(tlsOption Options, crls *crlPool, ocspResponses *ocspCache, spiffeBundles SPIFFEBundleSource) (*tls.Config, error) {
 	conf := &tls.Config{}
 
 	// ensure http2 enabled
@@ -333,8 +858,31 @@ func buildTLSConfig(tlsOption Options) (*tls.Config, error) {
 		conf.ClientAuth = tls.RequireAndVerifyClientCert
 	}
 
+	var verifiers []func([][]byte, [][]*x509.Certificate) error
+
 	clientAuthType := tlsOption.ClientAuth.ClientAuthType
-	if len(clientAuthType) > 0 {
+	if tlsOption.ClientAuth.SPIFFE != nil {
+		if conf.ClientCAs != nil {
+			return nil, errors.New("invalid clientAuth: CAFiles and SPIFFE are mutually exclusive")
+		}
+
+		if spiffeBundles == nil {
+			return nil, errors.New("invalid clientAuth: SPIFFE requires the SPIFFE Workload API to be configured")
+		}
+
+		var err error
+		conf.ClientAuth, err = spiffeClientAuth(clientAuthType)
+		if err != nil {
+			return nil, err
+		}
+
+		verify, err := verifySPIFFEPeerCertificate(*tlsOption.ClientAuth.SPIFFE, spiffeBundles)
+		if err != nil {
+			return nil, err
+		}
+
+		verifiers = append(verifiers, verify)
+	} else if len(clientAuthType) > 0 {
 		if conf.ClientCAs == nil && (clientAuthType == "VerifyClientCertIfGiven" ||
 			clientAuthType == "RequireAndVerifyClientCert") {
 			return nil, fmt.Errorf("invalid clientAuthType: %s, CAFiles is required", clientAuthType)
@@ -356,6 +904,43 @@ func buildTLSConfig(tlsOption Options) (*tls.Config, error) {
 		}
 	}
 
+	if len(tlsOption.ClientAuth.CRLs) > 0 {
+		if conf.ClientCAs == nil {
+			return nil, errors.New("invalid clientAuth: CAFiles is required by the CRLs")
+		}
+
+		// The CRLs are checked against the verified chains, which are only built when the client certificates are verified.
+		if !verifiesClientCert(conf.ClientAuth) {
+			return nil, fmt.Errorf("invalid clientAuth: the CRLs require the VerifyClientCertIfGiven or RequireAndVerifyClientCert clientAuthType, not %s", clientAuthType)
+		}
+
+		verifiers = append(verifiers, crls.verifyPeerCertificate(tlsOption.ClientAuth.CRLs, time.Duration(tlsOption.ClientAuth.CRLRefreshInterval)))
+	}
+
+	if tlsOption.ClientAuth.OCSP != nil {
+		if conf.ClientCAs == nil {
+			return nil, errors.New("invalid clientAuth: CAFiles is required by the OCSP checks")
+		}
+
+		// The OCSP status is checked with the issuer of the verified chain, which is only built when the client certificates are verified.
+		if !verifiesClientCert(conf.ClientAuth) {
+			return nil, fmt.Errorf("invalid clientAuth: the OCSP checks require the VerifyClientCertIfGiven or RequireAndVerifyClientCert clientAuthType, not %s", clientAuthType)
+		}
+
+		verifiers = append(verifiers, ocspResponses.verifyPeerCertificate(*tlsOption.ClientAuth.OCSP))
+	}
+
+	if len(verifiers) > 0 {
+		conf.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			for _, verify := range verifiers {
+				if err := verify(rawCerts, verifiedChains); err != nil {
+					return &clientCertificateError{err: err}
+				}
+			}
+			return nil
+		}
+	}
+
 	// Set PreferServerCipherSuites.
 	conf.PreferServerCipherSuites = tlsOption.PreferServerCipherSuites
 
@@ -402,32 +987,20 @@ func buildTLSConfig(tlsOption Options) (*tls.Config, error) {
 	return conf, nil
 }
 
-func buildDefaultCertificate(defaultCertificate *Certificate) (*tls.Certificate, error) {
-	certFile, err := defaultCertificate.CertFile.Read()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get cert file content: %v", err)
-	}
+// generateDefaultFallback returns whether a default RSA certificate is generated for the store without any.
+func generateDefaultFallback(tlsStore Store) bool {
+	return tlsStore.GenerateDefaultFallback == nil || *tlsStore.GenerateDefaultFallback
+}
 
-	keyFile, err := defaultCertificate.KeyFile.Read()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get key file content: %v", err)
+// defaultCertificates returns the default certificates of a store.
+func defaultCertificates(tlsStore Store) []*Certificate {
+	if len(tlsStore.DefaultCertificates) > 0 {
+		return tlsStore.DefaultCertificates
 	}
 
-	cert, err := tls.X509KeyPair(certFile, keyFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load X509 key pair: %v", err)
+	if tlsStore.DefaultCertificate != nil {
+		return []*Certificate{tlsStore.DefaultCertificate}
 	}
-	return &cert, nil
-}
 
-func buildDefaultCertificates(defaultCertificates []*Certificate) ([]*tls.Certificate, error) {
-	certs := make([]*tls.Certificate, len(defaultCertificates))
-	for index, cert := range defaultCertificates {
-		builtCert, err := buildDefaultCertificate(cert)
-		if err != nil {
-			return nil, err
-		}
-		certs[index] = builtCert
-	}
-	return certs, nil
+	return nil
 }
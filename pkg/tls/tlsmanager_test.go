@@ -2,11 +2,15 @@ package tls
 
 import (
 	"context"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/containous/traefik/v2/pkg/tls/certificate"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -66,6 +70,198 @@ func TestTLSInStore(t *testing.T) {
 	}
 }
 
+func TestManager_keyZeroization(t *testing.T) {
+	dynamicConfigs := []*CertAndStores{{
+		Certificate: Certificate{
+			CertFile: localhostCert,
+			KeyFile:  localhostKey,
+		},
+	}}
+
+	tlsManager := NewManager()
+	tlsManager.EnableKeyZeroization(0)
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, dynamicConfigs)
+
+	removed := getDynamicCertificate(t, tlsManager)
+
+	// The keys of the certificates removed by an update are zeroized.
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, nil)
+
+	assert.Eventually(t, func() bool {
+		return removed.PrivateKey.(*rsa.PrivateKey).D.Sign() == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// The keys of the certificates kept by an update are not zeroized.
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, dynamicConfigs)
+	current := getDynamicCertificate(t, tlsManager)
+
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, dynamicConfigs)
+	assert.Same(t, current, getDynamicCertificate(t, tlsManager))
+
+	time.Sleep(50 * time.Millisecond)
+	assert.NotEqual(t, 0, current.PrivateKey.(*rsa.PrivateKey).D.Sign())
+
+	// The keys of the current certificates are zeroized when the manager is not used anymore.
+	tlsManager.ZeroizeKeys()
+	assert.Equal(t, 0, current.PrivateKey.(*rsa.PrivateKey).D.Sign())
+}
+
+func TestManager_UpdateConfigs_incremental(t *testing.T) {
+	localhost := &CertAndStores{
+		Certificate: Certificate{CertFile: localhostCert, KeyFile: localhostKey},
+	}
+	snitest := &CertAndStores{
+		Certificate: Certificate{
+			CertFile: "../../integration/fixtures/https/snitest.com.cert",
+			KeyFile:  "../../integration/fixtures/https/snitest.com.key",
+		},
+	}
+	stores := map[string]Store{"default": {}}
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), stores, nil, []*CertAndStores{localhost})
+
+	store := tlsManager.GetStore("default")
+	localhostTLSCert := getDynamicCertificate(t, tlsManager)
+
+	store.CertCache.SetDefault("cached", localhostTLSCert)
+
+	// The store and the certificate are kept when nothing changed, and the cache is not reset.
+	tlsManager.UpdateConfigs(context.Background(), stores, nil, []*CertAndStores{localhost})
+
+	assert.Same(t, store, tlsManager.GetStore("default"))
+	assert.Same(t, localhostTLSCert, getDynamicCertificate(t, tlsManager))
+	_, cached := store.CertCache.Get("cached")
+	assert.True(t, cached)
+
+	// The added certificate is added to the existing store, and the cache is reset.
+	tlsManager.UpdateConfigs(context.Background(), stores, nil, []*CertAndStores{localhost, snitest})
+
+	assert.Same(t, store, tlsManager.GetStore("default"))
+	certs := store.DynamicCerts.Get().(map[certificateKey]*tls.Certificate)
+	require.Len(t, certs, 2)
+
+	var snitestCert *tls.Certificate
+	for _, cert := range certs {
+		if cert != localhostTLSCert {
+			snitestCert = cert
+		}
+	}
+	require.NotNil(t, snitestCert)
+
+	_, cached = store.CertCache.Get("cached")
+	assert.False(t, cached)
+
+	// The removed certificate is removed from the existing store, and the other one is kept.
+	tlsManager.UpdateConfigs(context.Background(), stores, nil, []*CertAndStores{snitest})
+
+	assert.Same(t, store, tlsManager.GetStore("default"))
+	assert.Same(t, snitestCert, getDynamicCertificate(t, tlsManager))
+
+	// The store is built again when its configuration changed.
+	tlsManager.UpdateConfigs(context.Background(), map[string]Store{
+		"default": {DefaultCertificate: &Certificate{CertFile: localhostCert, KeyFile: localhostKey}},
+	}, nil, []*CertAndStores{snitest})
+
+	assert.NotSame(t, store, tlsManager.GetStore("default"))
+	assert.Same(t, snitestCert, getDynamicCertificate(t, tlsManager))
+}
+
+func TestManager_parseCertificates(t *testing.T) {
+	var certs []*CertAndStores
+	for i := 0; i < 50; i++ {
+		certs = append(certs,
+			&CertAndStores{Certificate: Certificate{CertFile: localhostCert, KeyFile: localhostKey}},
+			&CertAndStores{Certificate: Certificate{CertFile: "invalid", KeyFile: "invalid"}},
+		)
+	}
+
+	tlsManager := NewManager()
+
+	parser := tlsManager.newCertificateParser()
+
+	results := tlsManager.parseCertificates(certs, parser)
+	require.Len(t, results, len(certs))
+
+	parsedCerts := parser.parsedCertificates()
+	assert.Len(t, parsedCerts, 1)
+
+	// The certificates with the same contents are parsed once.
+	localhostTLSCert := results[0].parsed.cert
+	require.NotNil(t, localhostTLSCert)
+
+	for i, result := range results {
+		if i%2 == 0 {
+			require.NoError(t, result.err)
+			assert.Same(t, localhostTLSCert, result.parsed.cert)
+		} else {
+			assert.Error(t, result.err)
+		}
+	}
+
+	// The certificates which are already parsed are not parsed again.
+	tlsManager.parsedCerts = parsedCerts
+
+	results = tlsManager.parseCertificates(certs[:1], tlsManager.newCertificateParser())
+	require.NoError(t, results[0].err)
+	assert.Same(t, localhostTLSCert, results[0].parsed.cert)
+}
+
+func TestManager_UpdateConfigs_sharedCertificates(t *testing.T) {
+	localhost := &Certificate{CertFile: localhostCert, KeyFile: localhostKey}
+	snitest := &Certificate{
+		CertFile: "../../integration/fixtures/https/snitest.com.cert",
+		KeyFile:  "../../integration/fixtures/https/snitest.com.key",
+	}
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), map[string]Store{
+		"default": {DefaultCertificate: localhost},
+		"other":   {DefaultCertificate: localhost},
+	}, nil, []*CertAndStores{
+		{Certificate: *localhost, Stores: []string{"default", "other"}},
+	})
+
+	// The identical certificates are parsed once, and shared by the stores.
+	shared := getDynamicCertificate(t, tlsManager)
+	assert.Same(t, shared, tlsManager.GetStore("default").DefaultCertificates[0])
+	assert.Same(t, shared, tlsManager.GetStore("other").DefaultCertificates[0])
+	assert.Same(t, shared, getStoreDynamicCertificate(t, tlsManager, "other"))
+
+	// The rotated certificate is replaced in all the stores referencing it.
+	tlsManager.UpdateConfigs(context.Background(), map[string]Store{
+		"default": {DefaultCertificate: snitest},
+		"other":   {DefaultCertificate: snitest},
+	}, nil, []*CertAndStores{
+		{Certificate: *snitest, Stores: []string{"default", "other"}},
+	})
+
+	rotated := getDynamicCertificate(t, tlsManager)
+	assert.NotSame(t, shared, rotated)
+	assert.Same(t, rotated, tlsManager.GetStore("default").DefaultCertificates[0])
+	assert.Same(t, rotated, tlsManager.GetStore("other").DefaultCertificates[0])
+	assert.Same(t, rotated, getStoreDynamicCertificate(t, tlsManager, "other"))
+}
+
+func getDynamicCertificate(t *testing.T, tlsManager *Manager) *tls.Certificate {
+	t.Helper()
+
+	return getStoreDynamicCertificate(t, tlsManager, "default")
+}
+
+func getStoreDynamicCertificate(t *testing.T, tlsManager *Manager, storeName string) *tls.Certificate {
+	t.Helper()
+
+	certs := tlsManager.GetStore(storeName).DynamicCerts.Get().(map[certificateKey]*tls.Certificate)
+	require.Len(t, certs, 1)
+
+	for _, cert := range certs {
+		return cert
+	}
+
+	return nil
+}
+
 func TestTLSInvalidStore(t *testing.T) {
 	dynamicConfigs := []*CertAndStores{{
 		Certificate: Certificate{
@@ -91,6 +287,79 @@ func TestTLSInvalidStore(t *testing.T) {
 	}
 }
 
+func TestManager_Get_certificateResolvers(t *testing.T) {
+	resolved := &tls.Certificate{}
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{"default": {}}, []*CertAndStores{{
+		Certificate: Certificate{CertFile: localhostCert, KeyFile: localhostKey},
+	}})
+
+	var calls []string
+	tlsManager.AddCertificateResolver(CertificateResolverFunc(func(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		calls = append(calls, "first")
+		return nil, nil
+	}))
+	tlsManager.AddCertificateResolver(CertificateResolverFunc(func(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		calls = append(calls, "second")
+		switch clientHello.ServerName {
+		case "resolved.com":
+			return resolved, nil
+		case "error.com":
+			return nil, errors.New("resolver error")
+		default:
+			return nil, nil
+		}
+	}))
+
+	config, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	// The resolvers are consulted in order.
+	cert, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: "resolved.com"})
+	require.NoError(t, err)
+	assert.Same(t, resolved, cert)
+	assert.Equal(t, []string{"first", "second"}, calls)
+
+	_, err = config.GetCertificate(&tls.ClientHelloInfo{ServerName: "error.com"})
+	assert.Error(t, err)
+
+	// The store is consulted when no resolver has a certificate.
+	cert, err = config.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	require.NoError(t, err)
+	assert.Same(t, getDynamicCertificate(t, tlsManager), cert)
+}
+
+func TestManager_Get_unknownServerNameListeners(t *testing.T) {
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{"default": {}, "strict": {SniStrict: true}}, []*CertAndStores{{
+		Certificate: Certificate{CertFile: localhostCert, KeyFile: localhostKey},
+	}})
+
+	var unknown []string
+	tlsManager.AddUnknownServerNameListener(func(serverName string) {
+		unknown = append(unknown, serverName)
+	})
+
+	config, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	strictConfig, err := tlsManager.Get("default", "strict")
+	require.NoError(t, err)
+
+	_, err = config.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+
+	_, err = config.GetCertificate(&tls.ClientHelloInfo{ServerName: "Unknown.com"})
+	require.NoError(t, err)
+
+	_, err = strictConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "strict.unknown.com"})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"unknown.com", "strict.unknown.com"}, unknown)
+}
+
 func TestManager_Get(t *testing.T) {
 	dynamicConfigs := []*CertAndStores{{
 		Certificate: Certificate{
@@ -322,6 +591,7 @@ func TestChaCha20(t *testing.T) {
 	testCases := []struct {
 		desc                       string
 		tlsOptionsName             string
+		shouldNotChangeTLSConfig   bool
 		cipherSuites               []uint16
 		expectPreferredCipherSuite uint16
 	}{
@@ -354,6 +624,16 @@ func TestChaCha20(t *testing.T) {
 			},
 			expectPreferredCipherSuite: tls.TLS_AES_256_GCM_SHA384,
 		},
+		{
+			desc:           "Should not remove server-side preference for ChaCha",
+			tlsOptionsName: "tls13_polyprefer",
+			cipherSuites: []uint16{
+				tls.TLS_AES_128_GCM_SHA256,
+				tls.TLS_AES_256_GCM_SHA384,
+				tls.TLS_CHACHA20_POLY1305_SHA256,
+			},
+			expectPreferredCipherSuite: tls.TLS_CHACHA20_POLY1305_SHA256,
+		},
 	}
 
 	tlsManager := NewManager()
@@ -378,92 +658,58 @@ func TestChaCha20(t *testing.T) {
 	}
 }
 
-func TestChaCha20(t *testing.T) {
-	tlsConfigs := map[string]Options{
-		"tls13_polyprefer": {
-			MinVersion: "VersionTLS13",
-			CipherSuites: []string{
-				"TLS_CHACHA20_POLY1305_SHA256",
-				"TLS_AES_256_GCM_SHA384",
-				"TLS_AES_128_GCM_SHA256",
-			},
-		},
-		"tls13": {
-			MinVersion: "VersionTLS13",
-			CipherSuites: []string{
-				"TLS_AES_256_GCM_SHA384",
-				"TLS_AES_128_GCM_SHA256",
-				"TLS_CHACHA20_POLY1305_SHA256",
-			},
-		},
-	}
+func Bool(v bool) *bool { return &v }
+
+func TestBuildCertificateStore_generateDefaultFallback(t *testing.T) {
+	ca, caKey := newSPIFFECA(t)
+	ecCert := defaultCAConfig(t, ca.Raw, caKey, 0)
 
 	testCases := []struct {
-		desc                       string
-		tlsOptionsName             string
-		shouldNotChangeTLSConfig   bool
-		cipherSuites               []uint16
-		expectPreferredCipherSuite uint16
+		desc                    string
+		generateDefaultFallback *bool
+		expected                []certificate.CertificateType
 	}{
 		{
-			desc:           "Should use server-configured ChaCha cipher suite for ChaCha-preferring clients",
-			tlsOptionsName: "tls13",
-			cipherSuites: []uint16{
-				tls.TLS_CHACHA20_POLY1305_SHA256,
-				tls.TLS_AES_128_GCM_SHA256,
-				tls.TLS_AES_256_GCM_SHA384,
-			},
-			expectPreferredCipherSuite: tls.TLS_CHACHA20_POLY1305_SHA256,
+			desc:     "generated by default",
+			expected: []certificate.CertificateType{certificate.EC, certificate.RSA},
 		},
 		{
-			desc:           "Should not change order of cipher suites for non-ChaCha-supporting clients",
-			tlsOptionsName: "tls13",
-			cipherSuites: []uint16{
-				tls.TLS_AES_128_GCM_SHA256,
-				tls.TLS_AES_256_GCM_SHA384,
-			},
-			expectPreferredCipherSuite: tls.TLS_AES_256_GCM_SHA384,
-		},
-		{
-			desc:           "Should not change order of cipher suites for ChaCha-supporting clients",
-			tlsOptionsName: "tls13",
-			cipherSuites: []uint16{
-				tls.TLS_AES_128_GCM_SHA256,
-				tls.TLS_AES_256_GCM_SHA384,
-				tls.TLS_CHACHA20_POLY1305_SHA256,
-			},
-			expectPreferredCipherSuite: tls.TLS_AES_256_GCM_SHA384,
+			desc:                    "enabled",
+			generateDefaultFallback: Bool(true),
+			expected:                []certificate.CertificateType{certificate.EC, certificate.RSA},
 		},
 		{
-			desc:           "Should not remove server-side preference for ChaCha",
-			tlsOptionsName: "tls13_polyprefer",
-			cipherSuites: []uint16{
-				tls.TLS_AES_128_GCM_SHA256,
-				tls.TLS_AES_256_GCM_SHA384,
-				tls.TLS_CHACHA20_POLY1305_SHA256,
-			},
-			expectPreferredCipherSuite: tls.TLS_CHACHA20_POLY1305_SHA256,
+			desc:                    "disabled",
+			generateDefaultFallback: Bool(false),
+			expected:                []certificate.CertificateType{certificate.EC},
 		},
 	}
 
-	tlsManager := NewManager()
-	tlsManager.UpdateConfigs(context.Background(), nil, tlsConfigs, nil)
-
 	for _, test := range testCases {
 		test := test
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			config, err := tlsManager.Get("default", test.tlsOptionsName)
-			assert.NoError(t, err)
+			tlsStore := Store{
+				DefaultCertificate:      &Certificate{CertFile: ecCert.CertFile, KeyFile: ecCert.KeyFile},
+				GenerateDefaultFallback: test.generateDefaultFallback,
+			}
 
-			resultingConfig, err := config.GetConfigForClient(&tls.ClientHelloInfo{
-				CipherSuites: test.cipherSuites,
-			})
-			assert.NoError(t, err)
-			assert.NotNil(t, resultingConfig)
-			assert.NotEmpty(t, resultingConfig.CipherSuites)
-			assert.Equal(t, resultingConfig.CipherSuites[0], test.expectPreferredCipherSuite)
+			store, err := buildCertificateStore(context.Background(), tlsStore, NewManager().newCertificateParser())
+			require.NoError(t, err)
+
+			var certTypes []certificate.CertificateType
+			for _, cert := range store.DefaultCertificates {
+				certType, err := certificate.GetCertificateType(cert)
+				require.NoError(t, err)
+				certTypes = append(certTypes, certType)
+			}
+
+			assert.Equal(t, test.expected, certTypes)
 		})
 	}
 }
+
+func TestStoreFingerprint_generateDefaultFallback(t *testing.T) {
+	assert.NotEqual(t, storeFingerprint(Store{}), storeFingerprint(Store{GenerateDefaultFallback: Bool(false)}))
+}
@@ -28,6 +28,10 @@ THE SOFTWARE.
 
 package tls
 
+import (
+	types "github.com/containous/traefik/v2/pkg/types"
+)
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertAndStores) DeepCopyInto(out *CertAndStores) {
 	*out = *in
@@ -58,6 +62,21 @@ func (in *ClientAuth) DeepCopyInto(out *ClientAuth) {
 		*out = make([]FileOrContent, len(*in))
 		copy(*out, *in)
 	}
+	if in.CRLs != nil {
+		in, out := &in.CRLs, &out.CRLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OCSP != nil {
+		in, out := &in.OCSP, &out.OCSP
+		*out = new(OCSP)
+		**out = **in
+	}
+	if in.SPIFFE != nil {
+		in, out := &in.SPIFFE, &out.SPIFFE
+		*out = new(SPIFFE)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -71,6 +90,64 @@ func (in *ClientAuth) DeepCopy() *ClientAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientHelloDeny) DeepCopyInto(out *ClientHelloDeny) {
+	*out = *in
+	if in.ServerNames != nil {
+		in, out := &in.ServerNames, &out.ServerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.JA3 != nil {
+		in, out := &in.JA3, &out.JA3
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientHelloDeny.
+func (in *ClientHelloDeny) DeepCopy() *ClientHelloDeny {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientHelloDeny)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultCA) DeepCopyInto(out *DefaultCA) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultCA.
+func (in *DefaultCA) DeepCopy() *DefaultCA {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultCA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCSP) DeepCopyInto(out *OCSP) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCSP.
+func (in *OCSP) DeepCopy() *OCSP {
+	if in == nil {
+		return nil
+	}
+	out := new(OCSP)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Options) DeepCopyInto(out *Options) {
 	*out = *in
@@ -85,6 +162,23 @@ func (in *Options) DeepCopyInto(out *Options) {
 		copy(*out, *in)
 	}
 	in.ClientAuth.DeepCopyInto(&out.ClientAuth)
+	if in.SessionTickets != nil {
+		in, out := &in.SessionTickets, &out.SessionTickets
+		*out = new(SessionTickets)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SNIOptions != nil {
+		in, out := &in.SNIOptions, &out.SNIOptions
+		*out = make([]SNIOptions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClientHelloDeny != nil {
+		in, out := &in.ClientHelloDeny, &out.ClientHelloDeny
+		*out = new(ClientHelloDeny)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -98,6 +192,100 @@ func (in *Options) DeepCopy() *Options {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SNIOptions) DeepCopyInto(out *SNIOptions) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SNIOptions.
+func (in *SNIOptions) DeepCopy() *SNIOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SNIOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SPIFFE) DeepCopyInto(out *SPIFFE) {
+	*out = *in
+	if in.IDs != nil {
+		in, out := &in.IDs, &out.IDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TrustDomains != nil {
+		in, out := &in.TrustDomains, &out.TrustDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SPIFFE.
+func (in *SPIFFE) DeepCopy() *SPIFFE {
+	if in == nil {
+		return nil
+	}
+	out := new(SPIFFE)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionTickets) DeepCopyInto(out *SessionTickets) {
+	*out = *in
+	if in.KV != nil {
+		in, out := &in.KV, &out.KV
+		*out = new(SessionTicketsKV)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionTickets.
+func (in *SessionTickets) DeepCopy() *SessionTickets {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionTickets)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionTicketsKV) DeepCopyInto(out *SessionTicketsKV) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(types.ClientTLS)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionTicketsKV.
+func (in *SessionTicketsKV) DeepCopy() *SessionTicketsKV {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionTicketsKV)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Store) DeepCopyInto(out *Store) {
 	*out = *in
@@ -106,6 +294,27 @@ func (in *Store) DeepCopyInto(out *Store) {
 		*out = new(Certificate)
 		**out = **in
 	}
+	if in.DefaultCertificates != nil {
+		in, out := &in.DefaultCertificates, &out.DefaultCertificates
+		*out = make([]*Certificate, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Certificate)
+				**out = **in
+			}
+		}
+	}
+	if in.GenerateDefaultFallback != nil {
+		in, out := &in.GenerateDefaultFallback, &out.GenerateDefaultFallback
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DefaultCA != nil {
+		in, out := &in.DefaultCA, &out.DefaultCA
+		*out = new(DefaultCA)
+		**out = **in
+	}
 	return
 }
 